@@ -18,25 +18,40 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"log"
 	"net"
+	"os"
 
 	"github.com/containernetworking/cni/pkg/skel"
 	"github.com/containernetworking/cni/pkg/types"
 	"github.com/containernetworking/cni/pkg/version"
 	"google.golang.org/grpc"
 
+	"github.com/containernetworking/cni/pkg/types/020"
 	cnisb "github.com/containernetworking/cni/pkg/types/current"
 	cninb "github.com/contiv/vpp/plugins/contiv/model/cni"
 )
 
+const (
+	// checkSpecVersion is the first version of the CNI spec with the CHECK command
+	checkSpecVersion = "0.4.0"
+)
+
+// supportedVersions lists the versions of the CNI spec supported by this plugin.
+var supportedVersions = version.PluginSupports("0.1.0", "0.2.0", "0.3.0", "0.3.1", checkSpecVersion)
+
 // cniConfig represents the CNI configuration, usually located in the /etc/cni/net.d/
 // folder, automatically picked by the executor of the CNI plugin and passed in via the standard input.
 type cniConfig struct {
 	// common CNI config
 	types.NetConf
 
-	// PrevResult contains previous plugin's result, used only when called in the context of a chained plugin.
-	PrevResult *map[string]interface{} `json:"prevResult"`
+	// RawPrevResult contains previous plugin's result, used only when called in the context of a chained plugin.
+	RawPrevResult *map[string]interface{} `json:"prevResult"`
+
+	// PrevResult is the parsed RawPrevResult, nil if the plugin is not chained.
+	PrevResult *cnisb.Result `json:"-"`
 
 	// GrpcServer is a plugin-specific config, contains location of the gRPC server
 	// where the CNI requests are being forwarded to (server:port tuple, e.g. "localhost:9111").
@@ -51,9 +66,13 @@ func parseCNIConfig(bytes []byte) (*cniConfig, error) {
 		return nil, fmt.Errorf("failed to load plugin config: %v", err)
 	}
 
-	// CNI chaining is not supported by this plugin, print out an error in case it was chained
-	if conf.PrevResult != nil {
-		return nil, fmt.Errorf("CNI chaining is not supported by this plugin")
+	// parse the result of the previous plugin in case that the plugin was chained
+	if conf.RawPrevResult != nil {
+		prevResult, err := parsePrevResult(conf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse prevResult: %v", err)
+		}
+		conf.PrevResult = prevResult
 	}
 
 	// grpcServer is mandatory
@@ -64,6 +83,40 @@ func parseCNIConfig(bytes []byte) (*cniConfig, error) {
 	return conf, nil
 }
 
+// parsePrevResult converts the result of the previous plugin in the chain into the current version of the result.
+func parsePrevResult(conf *cniConfig) (*cnisb.Result, error) {
+	resultBytes, err := json.Marshal(conf.RawPrevResult)
+	if err != nil {
+		return nil, err
+	}
+	var prevResult types.Result
+	switch conf.CNIVersion {
+	case "", "0.1.0", "0.2.0":
+		prevResult, err = types020.NewResult(resultBytes)
+	default:
+		// the result format has not changed since 0.3.0
+		prevResult, err = cnisb.NewResult(resultBytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cnisb.NewResultFromResult(prevResult)
+}
+
+// printResult prints the result in the version of the CNI spec requested by the CNI config.
+func printResult(result *cnisb.Result, cniVersion string) error {
+	if cniVersion == checkSpecVersion {
+		// the result format has not changed since 0.3.0
+		result.CNIVersion = cniVersion
+		return result.Print()
+	}
+	versionedResult, err := result.GetAsVersion(cniVersion)
+	if err != nil {
+		return err
+	}
+	return versionedResult.Print()
+}
+
 // grpcConnect sets up a connection to the gRPC server specified in grpcServer argument
 // as a server:port tuple (e.g. "localhost:9111").
 func grpcConnect(grpcServer string) (*grpc.ClientConn, cninb.RemoteCNIClient, error) {
@@ -103,13 +156,28 @@ func cmdAdd(args *skel.CmdArgs) error {
 		return err
 	}
 
+	if r.Result != 0 {
+		return fmt.Errorf("%s", r.Error)
+	}
+
 	// process the reply from the remote CNI handler
 	result := &cnisb.Result{
 		CNIVersion: cfg.CNIVersion,
 	}
 
+	// pass on the result of the previous plugin in the chain, the interfaces of this plugin are appended
+	ifOffset := 0
+	if cfg.PrevResult != nil {
+		result.Interfaces = cfg.PrevResult.Interfaces
+		result.IPs = cfg.PrevResult.IPs
+		result.Routes = cfg.PrevResult.Routes
+		result.DNS = cfg.PrevResult.DNS
+		ifOffset = len(result.Interfaces)
+	}
+
 	// process interfaces
-	for ifidx, iface := range r.Interfaces {
+	for i, iface := range r.Interfaces {
+		ifidx := ifOffset + i
 		// append interface info
 		result.Interfaces = append(result.Interfaces, &cnisb.Interface{
 			Name:    iface.Name,
@@ -166,7 +234,7 @@ func cmdAdd(args *skel.CmdArgs) error {
 		result.DNS.Options = dns.Options
 	}
 
-	return result.Print()
+	return printResult(result, cfg.CNIVersion)
 }
 
 // cmdDel implements the CNI request to delete a container from network.
@@ -201,8 +269,97 @@ func cmdDel(args *skel.CmdArgs) error {
 	return nil
 }
 
+// cmdCheck implements the CNI request to check that a container is still connected to network as expected.
+// The remote gRPC server verifies the wiring of the container, the addresses it replies with
+// must match the result of the ADD request passed in prevResult.
+func cmdCheck(args *skel.CmdArgs) error {
+	// parse CNI config
+	cfg, err := parseCNIConfig(args.StdinData)
+	if err != nil {
+		return err
+	}
+	if cfg.CNIVersion != checkSpecVersion {
+		return fmt.Errorf("CHECK is not supported by CNI version %q", cfg.CNIVersion)
+	}
+	if cfg.PrevResult == nil {
+		return fmt.Errorf("CHECK requires prevResult")
+	}
+
+	// connect to the remote CNI handler over gRPC
+	conn, c, err := grpcConnect(cfg.GrpcServer)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// execute the CHECK request
+	r, err := c.Check(context.Background(), &cninb.CNIRequest{
+		Version:          cfg.CNIVersion,
+		ContainerId:      args.ContainerID,
+		InterfaceName:    args.IfName,
+		NetworkNamespace: args.Netns,
+		ExtraArguments:   args.Args,
+		ExtraNwConfig:    string(args.StdinData),
+	})
+	if err != nil {
+		return err
+	}
+	if r.Result != 0 {
+		return fmt.Errorf("%s", r.Error)
+	}
+
+	// compare the addresses of the container with the result of the ADD request
+	for _, iface := range r.Interfaces {
+		for _, ip := range iface.IpAddresses {
+			found := false
+			for _, prevIP := range cfg.PrevResult.IPs {
+				if prevIP.Address.String() == ip.Address {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("IP address %s of interface %s is missing in prevResult", ip.Address, iface.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// cmdArgsFromEnv collects the arguments of the CNI request from the environment and stdin.
+func cmdArgsFromEnv() (*skel.CmdArgs, error) {
+	stdinData, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, fmt.Errorf("error reading from stdin: %v", err)
+	}
+	return &skel.CmdArgs{
+		ContainerID: os.Getenv("CNI_CONTAINERID"),
+		Netns:       os.Getenv("CNI_NETNS"),
+		IfName:      os.Getenv("CNI_IFNAME"),
+		Args:        os.Getenv("CNI_ARGS"),
+		Path:        os.Getenv("CNI_PATH"),
+		StdinData:   stdinData,
+	}, nil
+}
+
 // main routine of the CNI plugin
 func main() {
+	// CHECK is not dispatched by the skel package (it implements the CNI spec prior to 0.4.0)
+	if os.Getenv("CNI_COMMAND") == "CHECK" {
+		args, err := cmdArgsFromEnv()
+		if err == nil {
+			err = cmdCheck(args)
+		}
+		if err != nil {
+			e := &types.Error{Code: 100, Msg: err.Error()}
+			if err := e.Print(); err != nil {
+				log.Print("Error writing error JSON to stdout: ", err)
+			}
+			os.Exit(1)
+		}
+		return
+	}
+
 	// execute the CNI plugin logic
-	skel.PluginMain(cmdAdd, cmdDel, version.All)
+	skel.PluginMain(cmdAdd, cmdDel, supportedVersions)
 }
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"sync"
 	"testing"
 
 	"github.com/containernetworking/cni/pkg/skel"
@@ -32,7 +33,7 @@ const (
 	testServerPort = 59111 // port where the testing gRPC server is running
 )
 
-// testCNIServer represents testing CNI gRPC server. Implements CNI Add and Delete operations.
+// testCNIServer represents testing CNI gRPC server. Implements CNI Add, Delete and Check operations.
 type testCNIServer struct{}
 
 // Add implements the CNI request to add a container to network.
@@ -89,8 +90,38 @@ func (s *testCNIServer) Delete(context.Context, *cni.CNIRequest) (*cni.CNIReply,
 	}, nil
 }
 
-// runTestGrpcServer starts a testing gRPC server with testCNIServer implementation.
-func runTestGrpcServer() *grpc.Server {
+// Check implements the CNI request to check that a container is connected to network as expected.
+func (s *testCNIServer) Check(context.Context, *cni.CNIRequest) (*cni.CNIReply, error) {
+	fmt.Println("CHECK called")
+
+	// return a mocked reply
+	return &cni.CNIReply{
+		Result: 0,
+		Error:  "",
+		Interfaces: []*cni.CNIReply_Interface{
+			{
+				Name: "eth0",
+				IpAddresses: []*cni.CNIReply_Interface_IP{
+					{
+						Address: "192.168.1.53/24",
+						Version: cni.CNIReply_Interface_IP_IPV4,
+						Gateway: "192.168.1.1",
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+var testGrpcServer sync.Once
+
+// runTestGrpcServer starts a testing gRPC server with testCNIServer implementation (once for all tests).
+func runTestGrpcServer() {
+	testGrpcServer.Do(startTestGrpcServer)
+}
+
+// startTestGrpcServer starts a testing gRPC server with testCNIServer implementation.
+func startTestGrpcServer() {
 	// initialize the gRPC server
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", testServerPort))
 	if err != nil {
@@ -105,8 +136,6 @@ func runTestGrpcServer() *grpc.Server {
 			log.Fatalf("failed to serve: %v", err)
 		}
 	}()
-
-	return s
 }
 
 // TestCNIAddDelete tests CNI Add and Delete operations of the CNI plugin.
@@ -134,3 +163,58 @@ func TestCNIAddDelete(t *testing.T) {
 	err = cmdDel(&skel.CmdArgs{StdinData: []byte(conf)})
 	Expect(err).ShouldNot(HaveOccurred())
 }
+
+// TestCNIChaining tests CNI Add and Check operations of the CNI plugin with prevResult.
+func TestCNIChaining(t *testing.T) {
+	RegisterTestingT(t)
+
+	// start testing gRPC server
+	runTestGrpcServer()
+
+	// prepare CNI config with the result of the previous plugin
+	conf := `{
+	"cniVersion": "%s",
+	"type": "contiv-cni",
+	"grpcServer": "localhost:%d",
+	"prevResult": {
+		"cniVersion": "%s",
+		"interfaces": [{"name": "%s"}],
+		"ips": [{"version": "4", "address": "%s", "interface": 0}]
+	}
+}`
+	chainedConf := func(version, ifName, ip string) []byte {
+		return []byte(fmt.Sprintf(conf, version, testServerPort, version, ifName, ip))
+	}
+
+	// the result of the previous plugin is passed on
+	cfg, err := parseCNIConfig(chainedConf("0.3.1", "other", "10.0.0.5/24"))
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(cfg.PrevResult.Interfaces).To(HaveLen(1))
+	Expect(cfg.PrevResult.IPs[0].Address.String()).To(BeEquivalentTo("10.0.0.5/24"))
+
+	err = cmdAdd(&skel.CmdArgs{StdinData: chainedConf("0.3.1", "other", "10.0.0.5/24")})
+	Expect(err).ShouldNot(HaveOccurred())
+
+	// the result is converted to the old format for the old versions of the spec
+	oldConf := fmt.Sprintf(`{
+	"cniVersion": "0.2.0",
+	"type": "contiv-cni",
+	"grpcServer": "localhost:%d",
+	"prevResult": {
+		"ip4": {"ip": "10.0.0.5/24"}
+	}
+}`, testServerPort)
+	err = cmdAdd(&skel.CmdArgs{StdinData: []byte(oldConf)})
+	Expect(err).ShouldNot(HaveOccurred())
+
+	// CHECK passes if the addresses reported by the server match prevResult
+	err = cmdCheck(&skel.CmdArgs{StdinData: chainedConf("0.4.0", "eth0", "192.168.1.53/24")})
+	Expect(err).ShouldNot(HaveOccurred())
+
+	err = cmdCheck(&skel.CmdArgs{StdinData: chainedConf("0.4.0", "eth0", "192.168.1.54/24")})
+	Expect(err).Should(HaveOccurred())
+
+	// CHECK is not supported before 0.4.0
+	err = cmdCheck(&skel.CmdArgs{StdinData: chainedConf("0.3.1", "eth0", "192.168.1.53/24")})
+	Expect(err).Should(HaveOccurred())
+}
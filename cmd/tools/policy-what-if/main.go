@@ -0,0 +1,120 @@
+// Package policy-what-if contains tool for asking contiv agent whether the installed
+// network policies would allow a given connection.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/contiv/vpp/plugins/policy/model/whatif"
+)
+
+var (
+	// command line flags
+	agent    = flag.String("a", "localhost:9999", "Address of the contiv agent HTTP server")
+	src      = flag.String("src", "", "Source pod (<namespace>/<name>) or IP address")
+	dst      = flag.String("dst", "", "Destination pod (<namespace>/<name>) or IP address")
+	protocol = flag.String("protocol", "TCP", "Protocol of the connection")
+	port     = flag.String("port", "", "Destination port or ICMP type")
+	code     = flag.String("code", "", "ICMP code")
+	help     = flag.Bool("h", false, "Switch to show help")
+)
+
+const helpContent = `policy-what-if asks contiv agent whether the installed network policies would allow a given connection.
+Usage:
+  policy-what-if -src [pod | IP] -dst [pod | IP] [-protocol TCP|UDP|SCTP|ICMP] [-port port] [-code code]
+
+Flags:
+  -a [host:port]            Address of the contiv agent HTTP server (default localhost:9999)
+  -src [namespace/name | IP] Source of the connection
+  -dst [namespace/name | IP] Destination of the connection
+  -protocol [protocol]      One of TCP (default), UDP, SCTP, ICMP
+  -port [port]              Destination port, or ICMP type for ICMP
+  -code [code]              ICMP code, used only with ICMP
+  -h                        Prints this help
+`
+
+// main is the main method for policy what-if tool
+func main() {
+	flag.Parse()
+	if *help {
+		fmt.Print(helpContent)
+		return
+	}
+	if *src == "" || *dst == "" {
+		fmt.Fprint(os.Stderr, "Both source and destination must be specified.\n"+helpContent)
+		os.Exit(1)
+	}
+
+	reply, err := whatIf()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Can't evaluate the connection: %v\n", err)
+		os.Exit(1)
+	}
+	printReply(reply)
+}
+
+// whatIf sends the what-if query to the agent and decodes the reply.
+func whatIf() (*whatif.Reply, error) {
+	params := url.Values{}
+	params.Set(whatif.SrcParam, *src)
+	params.Set(whatif.DstParam, *dst)
+	params.Set(whatif.ProtocolParam, *protocol)
+	if *port != "" {
+		params.Set(whatif.PortParam, *port)
+	}
+	if *code != "" {
+		params.Set(whatif.CodeParam, *code)
+	}
+
+	resp, err := http.Get("http://" + *agent + whatif.RestPath + "?" + params.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	reply := &whatif.Reply{}
+	if err := json.NewDecoder(resp.Body).Decode(reply); err != nil {
+		return nil, fmt.Errorf("invalid reply (HTTP status %s): %v", resp.Status, err)
+	}
+	if reply.Error != "" {
+		return nil, fmt.Errorf("%s", reply.Error)
+	}
+	return reply, nil
+}
+
+// printReply prints the verdict together with the rules and policies that decided it.
+func printReply(reply *whatif.Reply) {
+	fmt.Printf("Connection: %s\n", reply.Connection)
+	fmt.Printf("Verdict:    %s\n", reply.Verdict)
+	if reply.Rendered {
+		if reply.RenderedRule == "" {
+			fmt.Println("Rendered:   no rendered rule matches")
+		} else {
+			fmt.Printf("Rendered:   %s\n", reply.RenderedRule)
+		}
+	}
+	if reply.Warning != "" {
+		fmt.Printf("Warning:    %s\n", reply.Warning)
+	}
+	if len(reply.Pods) == 0 {
+		fmt.Println("Neither source nor destination is a pod known to the agent.")
+		return
+	}
+	for _, pod := range reply.Pods {
+		fmt.Printf("\nPod %s (%s): %s\n", pod.Pod, pod.Direction, pod.Verdict)
+		if pod.Rule == "" {
+			fmt.Println("  Rule:     none (pod is not isolated)")
+		} else {
+			fmt.Printf("  Rule:     %s\n", pod.Rule)
+		}
+		if len(pod.Policies) > 0 {
+			fmt.Printf("  Policies: %s\n", strings.Join(pod.Policies, ", "))
+		}
+	}
+}
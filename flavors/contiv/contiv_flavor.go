@@ -169,14 +169,18 @@ func (f *FlavorContiv) Inject() bool {
 	f.Contiv.Deps.Resync = &f.ResyncOrch
 	f.Contiv.Deps.ETCD = &f.ETCD
 	f.Contiv.Deps.Watcher = &f.NodeIDDataSync
+	f.Contiv.Deps.Prometheus = &f.Prometheus
+	f.Contiv.Deps.HTTP = &f.HTTP
 	f.Contiv.Deps.PluginConfig = config.ForPlugin("contiv", ContivConfigPath, ContivConfigPathUsage)
 
-	f.Policy.Deps.PluginInfraDeps = *f.FlavorLocal.InfraDeps("policy")
+	f.Policy.Deps.PluginInfraDeps = *f.FlavorLocal.InfraDeps("policy", local.WithConf())
 	f.Policy.Deps.Resync = &f.ResyncOrch
 	f.Policy.Deps.Watcher = &f.PolicyDataSync
 	f.Policy.Deps.Contiv = &f.Contiv
 	f.Policy.Deps.GoVPP = &f.GoVPP
 	f.Policy.Deps.VPP = &f.VPP
+	f.Policy.Deps.HTTP = &f.HTTP
+	f.Policy.Deps.Prometheus = &f.Prometheus
 
 	f.Service.Deps.PluginInfraDeps = *f.FlavorLocal.InfraDeps("service")
 	f.Service.Deps.Resync = &f.ResyncOrch
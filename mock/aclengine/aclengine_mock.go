@@ -35,11 +35,20 @@ const (
 	// maxPortNum is the maximum possible port number.
 	maxPortNum = uint32(^uint16(0))
 
-	// maxICMPCode is the maximum possible ICMP code.
-	maxICMPCode = 5
+	// sctpProtocolNumber is the IP protocol number of SCTP.
+	sctpProtocolNumber = 132
 
-	// maxICMPType is the maximum possible ICMP type.
-	maxICMPType = 16
+	// icmpEchoRequest is the ICMP type of the echo request.
+	icmpEchoRequest = 8
+
+	// icmpEchoReply is the ICMP type of the echo reply.
+	icmpEchoReply = 0
+
+	// icmpv6EchoRequest is the ICMPv6 type of the echo request.
+	icmpv6EchoRequest = 128
+
+	// icmpv6EchoReply is the ICMPv6 type of the echo reply.
+	icmpv6EchoReply = 129
 )
 
 // ConnectionAction is one of DENY-SYN, DENY-SYN-ACK, ALLOW, FAILURE.
@@ -76,7 +85,9 @@ const (
 	ACLActionFailure
 )
 
-// ProtocolType is one of TCP, UDP, ICMP.
+// ProtocolType is one of TCP, UDP, ICMP, SCTP.
+// For ICMP, the source and the destination port of the simulated connection
+// carry the ICMP type and code, respectively.
 type ProtocolType int
 
 const (
@@ -88,6 +99,9 @@ const (
 
 	// ICMP protocol
 	ICMP
+
+	// SCTP protocol.
+	SCTP
 )
 
 // MockACLEngine simulates ACL evaluation engine from the VPP/ACL plugin.
@@ -409,13 +423,25 @@ func (mae *MockACLEngine) testConnection(srcIfName string, srcIP net.IP,
 
 	var srcIfReflected, dstIfReflected bool
 
+	// Ports (ICMP type and code) of the packets sent in the reverse direction.
+	replySrcPort, replyDstPort := dstPort, srcPort
+	if protocol == ICMP {
+		replySrcPort, replyDstPort = srcPort, dstPort
+		if srcIP.To4() != nil && srcPort == icmpEchoRequest {
+			replySrcPort = icmpEchoReply
+		}
+		if srcIP.To4() == nil && srcPort == icmpv6EchoRequest {
+			replySrcPort = icmpv6EchoReply
+		}
+	}
+
 	// Get ACLs on the communication path.
 	srcACLs := mae.aclConfig.GetACLs(srcIfName)
 	dstACLs := mae.aclConfig.GetACLs(dstIfName)
 
 	// SYN packet:
 	//   -> test inbound ACL for source interface
-	srcInAction := mae.evalACL(srcACLs.inbound, srcIP, dstIP, protocol, dstPort)
+	srcInAction := mae.evalACL(srcACLs.inbound, srcIP, dstIP, protocol, srcPort, dstPort)
 	if srcInAction == ACLActionFailure {
 		return ConnActionFailure
 	}
@@ -430,7 +456,7 @@ func (mae *MockACLEngine) testConnection(srcIfName string, srcIP net.IP,
 	}
 	//   -> test outbound ACL for destination interface
 	if !dstIfReflected {
-		dstOutAction := mae.evalACL(dstACLs.outbound, srcIP, dstIP, protocol, dstPort)
+		dstOutAction := mae.evalACL(dstACLs.outbound, srcIP, dstIP, protocol, srcPort, dstPort)
 		if dstOutAction == ACLActionFailure {
 			return ConnActionFailure
 		}
@@ -448,7 +474,7 @@ func (mae *MockACLEngine) testConnection(srcIfName string, srcIP net.IP,
 	// SYN-ACK packet:
 	//   -> test inbound ACL for destination interface
 	if !dstIfReflected {
-		dstInAction := mae.evalACL(dstACLs.inbound, dstIP, srcIP, protocol, srcPort)
+		dstInAction := mae.evalACL(dstACLs.inbound, dstIP, srcIP, protocol, replySrcPort, replyDstPort)
 		if dstInAction == ACLActionFailure {
 			return ConnActionFailure
 		}
@@ -458,7 +484,7 @@ func (mae *MockACLEngine) testConnection(srcIfName string, srcIP net.IP,
 	}
 	//   -> test outbound ACL for source interface
 	if !srcIfReflected {
-		srcOutAction := mae.evalACL(srcACLs.outbound, dstIP, srcIP, protocol, srcPort)
+		srcOutAction := mae.evalACL(srcACLs.outbound, dstIP, srcIP, protocol, replySrcPort, replyDstPort)
 		if srcOutAction == ACLActionFailure {
 			return ConnActionFailure
 		}
@@ -471,7 +497,7 @@ func (mae *MockACLEngine) testConnection(srcIfName string, srcIP net.IP,
 }
 
 func (mae *MockACLEngine) evalACL(acl *vpp_acl.AccessLists_Acl, srcIP, dstIP net.IP,
-	protocol ProtocolType, dstPort uint16) ACLAction {
+	protocol ProtocolType, srcPort, dstPort uint16) ACLAction {
 
 	if acl == nil {
 		return ACLActionPermit
@@ -489,13 +515,14 @@ func (mae *MockACLEngine) evalACL(acl *vpp_acl.AccessLists_Acl, srcIP, dstIP net
 			return ACLActionFailure
 		}
 		ipRule := rule.Matches.IpRule
-		if ipRule.Other != nil || ipRule.Ip == nil {
-			// unsupported
-			mae.Log.WithField("acl", *acl).Error("Missing IP or found unsupported 'Other' section")
+		if ipRule.Ip == nil {
+			// invalid
+			mae.Log.WithField("acl", *acl).Error("Missing IP section")
 			return ACLActionFailure
 		}
 
 		// check source network
+		var ruleIsIPv6 bool
 		if ipRule.Ip.SourceNetwork != "" {
 			_, srcNetwork, err := net.ParseCIDR(ipRule.Ip.SourceNetwork)
 			if err != nil {
@@ -503,6 +530,7 @@ func (mae *MockACLEngine) evalACL(acl *vpp_acl.AccessLists_Acl, srcIP, dstIP net
 				mae.Log.WithField("acl", *acl).Error("Missing source network")
 				return ACLActionFailure
 			}
+			ruleIsIPv6 = srcNetwork.IP.To4() == nil
 			if !srcNetwork.Contains(srcIP) {
 				// not matching
 				continue
@@ -517,16 +545,36 @@ func (mae *MockACLEngine) evalACL(acl *vpp_acl.AccessLists_Acl, srcIP, dstIP net
 				mae.Log.WithField("acl", *acl).Error("Missing destination network")
 				return ACLActionFailure
 			}
+			if ipRule.Ip.SourceNetwork != "" && ruleIsIPv6 != (dstNetwork.IP.To4() == nil) {
+				// invalid
+				mae.Log.WithField("acl", *acl).Error("Source and destination network have different IP versions")
+				return ACLActionFailure
+			}
+			ruleIsIPv6 = dstNetwork.IP.To4() == nil
 			if !dstNetwork.Contains(dstIP) {
 				// not matching
 				continue
 			}
 		}
 
-		// check ICMP/TCP/UDP
+		// check IP version (rule without networks matches only IPv4 unless it is ICMPv6)
+		if ipRule.Icmp != nil && ipRule.Icmp.Icmpv6 {
+			if (ipRule.Ip.SourceNetwork != "" || ipRule.Ip.DestinationNetwork != "") && !ruleIsIPv6 {
+				// invalid
+				mae.Log.WithField("acl", *acl).Error("ICMPv6 rule with IPv4 network")
+				return ACLActionFailure
+			}
+			ruleIsIPv6 = true
+		}
+		if ruleIsIPv6 != (srcIP.To4() == nil) {
+			// not matching
+			continue
+		}
+
+		// check ICMP/TCP/UDP/SCTP
 		switch protocol {
 		case TCP:
-			if ipRule.Udp != nil || ipRule.Icmp != nil {
+			if ipRule.Udp != nil || ipRule.Icmp != nil || ipRule.Other != nil {
 				// not matching
 				continue
 			}
@@ -562,7 +610,7 @@ func (mae *MockACLEngine) evalACL(acl *vpp_acl.AccessLists_Acl, srcIP, dstIP net
 			}
 
 		case UDP:
-			if ipRule.Tcp != nil || ipRule.Icmp != nil {
+			if ipRule.Tcp != nil || ipRule.Icmp != nil || ipRule.Other != nil {
 				// not matching
 				continue
 			}
@@ -598,7 +646,7 @@ func (mae *MockACLEngine) evalACL(acl *vpp_acl.AccessLists_Acl, srcIP, dstIP net
 			}
 
 		case ICMP:
-			if ipRule.Tcp != nil || ipRule.Udp != nil {
+			if ipRule.Tcp != nil || ipRule.Udp != nil || ipRule.Other != nil {
 				// not matching
 				continue
 			}
@@ -608,38 +656,41 @@ func (mae *MockACLEngine) evalACL(acl *vpp_acl.AccessLists_Acl, srcIP, dstIP net
 				return ACLActionFailure
 			}
 
-			// check ICMP code range (should be ALL-CODES)
-			codeRange := ipRule.Icmp.IcmpCodeRange
-			if codeRange == nil {
-				// invalid
-				mae.Log.WithField("acl", *acl).Error("Missing ICMP code range")
-				return ACLActionFailure
-			}
-			if codeRange.First != 0 || codeRange.Last != maxICMPCode {
-				// invalid
-				mae.Log.WithField("acl", *acl).Error("ICMP code range does not cover all ports")
-				return ACLActionFailure
-			}
-
-			// check ICMP type range (should be ALL-TYPES)
+			// check ICMP type range
 			typeRange := ipRule.Icmp.IcmpTypeRange
 			if typeRange == nil {
 				// invalid
 				mae.Log.WithField("acl", *acl).Error("Missing ICMP type range")
 				return ACLActionFailure
 			}
-			if typeRange.First != 0 || typeRange.Last != maxICMPType {
-				// invalid
-				mae.Log.WithField("acl", *acl).Error("ICMP type range does not cover all ports")
-				return ACLActionFailure
+			if uint32(srcPort) < typeRange.First || uint32(srcPort) > typeRange.Last {
+				// not matching
+				continue
 			}
 
-			// Check IP version (should be IPv4 until IPv6 is supported)
-			if ipRule.Icmp.Icmpv6 != false {
+			// check ICMP code range
+			codeRange := ipRule.Icmp.IcmpCodeRange
+			if codeRange == nil {
 				// invalid
-				mae.Log.WithField("acl", *acl).Error("ICMPv6 is not yet supported")
+				mae.Log.WithField("acl", *acl).Error("Missing ICMP code range")
 				return ACLActionFailure
 			}
+			if uint32(dstPort) < codeRange.First || uint32(dstPort) > codeRange.Last {
+				// not matching
+				continue
+			}
+
+			// check ICMP version
+			if ipRule.Icmp.Icmpv6 != (srcIP.To4() == nil) {
+				// not matching
+				continue
+			}
+
+		case SCTP:
+			if ipRule.Other == nil || ipRule.Other.Protocol != sctpProtocolNumber {
+				// not matching
+				continue
+			}
 		}
 
 		// Rule matches the packet!
@@ -48,7 +48,15 @@ func (mb *MockBroker) Delete(key string, opts ...datasync.DelOption) (found bool
 }
 
 func (mb *MockBroker) GetValue(key string, val proto.Message) (found bool, rev int64, err error) {
-	return false, 0, nil
+	data, found := mb.Data[key]
+	if !found {
+		return false, 0, nil
+	}
+	encoded, err := proto.Marshal(data)
+	if err != nil {
+		return true, 0, err
+	}
+	return true, 0, proto.Unmarshal(encoded, val)
 }
 
 func (mb *MockBroker) NewTxn() keyval.ProtoTxn {
@@ -15,6 +15,7 @@
 package contiv
 
 import (
+	"fmt"
 	"net"
 	"sync"
 
@@ -31,16 +32,22 @@ type MockContiv struct {
 	podIf              map[podmodel.ID]string
 	podAppNs           map[podmodel.ID]uint32
 	podNetwork         *net.IPNet
+	extraPodNetworks   []*net.IPNet
 	tcpStackDisabled   bool
 	natExternalTraffic bool
 	nodeIP             string
 	nodeIPsubs         []chan string
+	nodeConfigSubs     []chan struct{}
 	podPreRemovalHooks []contiv.PodActionHook
 	mainPhysIf         string
 	otherPhysIfs       []string
 	hostInterconnect   string
 	vxlanBVIIfName     string
+	overlayTunnels     []string
+	overlayTunnelSubs  []chan struct{}
 	gwIP               net.IP
+	namespaceTenants   map[string]string
+	vppCLIOutput       map[string]string
 	containerIndex     *containeridx.ConfigIndex
 }
 
@@ -48,9 +55,11 @@ type MockContiv struct {
 func NewMockContiv() *MockContiv {
 	ci := containeridx.NewConfigIndex(logrus.DefaultLogger(), "test", "title", nil)
 	return &MockContiv{
-		podIf:          make(map[podmodel.ID]string),
-		podAppNs:       make(map[podmodel.ID]uint32),
-		containerIndex: ci,
+		podIf:            make(map[podmodel.ID]string),
+		podAppNs:         make(map[podmodel.ID]uint32),
+		namespaceTenants: make(map[string]string),
+		vppCLIOutput:     make(map[string]string),
+		containerIndex:   ci,
 	}
 }
 
@@ -71,6 +80,26 @@ func (mc *MockContiv) SetPodNetwork(podNetwork string) {
 	_, mc.podNetwork, _ = net.ParseCIDR(podNetwork)
 }
 
+// AddPodNetwork allows to add an extra pod subnet (dynamically allocated pod CIDR block)
+// for the current host node.
+func (mc *MockContiv) AddPodNetwork(podNetwork string) {
+	_, network, _ := net.ParseCIDR(podNetwork)
+	mc.extraPodNetworks = append(mc.extraPodNetworks, network)
+}
+
+// SetNamespaceTenant allows to set the tenant whose network connects the PODs of the namespace.
+func (mc *MockContiv) SetNamespaceTenant(namespace string, tenant string) {
+	mc.namespaceTenants[namespace] = tenant
+}
+
+// SetVppCLIOutput allows to set the output returned for the given VPP CLI command.
+func (mc *MockContiv) SetVppCLIOutput(cmd string, output string) {
+	mc.Lock()
+	defer mc.Unlock()
+
+	mc.vppCLIOutput[cmd] = output
+}
+
 // SetContainerIndex allows to set index that contains configured containers
 func (mc *MockContiv) SetContainerIndex(ci *containeridx.ConfigIndex) {
 	mc.containerIndex = ci
@@ -115,6 +144,23 @@ func (mc *MockContiv) SetHostInterconnectIfName(ifName string) {
 	mc.hostInterconnect = ifName
 }
 
+// SetOverlayTunnelIfNames allows to set what tests will assume the names of the overlay tunnels are.
+// The subscribers of WatchOverlayTunnels are notified.
+func (mc *MockContiv) SetOverlayTunnelIfNames(ifNames ...string) {
+	mc.Lock()
+	defer mc.Unlock()
+
+	mc.overlayTunnels = ifNames
+
+	for _, sub := range mc.overlayTunnelSubs {
+		select {
+		case sub <- struct{}{}:
+		default:
+			// skip subscribers who are not ready to receive notification
+		}
+	}
+}
+
 // SetVxlanBVIIfName allows to set what tests will assume the name of the VXLAN BVI interface is.
 func (mc *MockContiv) SetVxlanBVIIfName(ifName string) {
 	mc.vxlanBVIIfName = ifName
@@ -130,6 +176,21 @@ func (mc *MockContiv) SetNatExternalTraffic(natExternalTraffic bool) {
 	mc.natExternalTraffic = natExternalTraffic
 }
 
+// NodeConfigChanged allows to simulate the change of the node configuration at runtime - all subscribers
+// of WatchNodeConfig are notified.
+func (mc *MockContiv) NodeConfigChanged() {
+	mc.Lock()
+	defer mc.Unlock()
+
+	for _, sub := range mc.nodeConfigSubs {
+		select {
+		case sub <- struct{}{}:
+		default:
+			// skip subscribers who are not ready to receive notification
+		}
+	}
+}
+
 // DeletingPod allows to simulate event of deleting pod - all registered pre-removal hooks
 // are called.
 func (mc *MockContiv) DeletingPod(podID podmodel.ID) {
@@ -180,6 +241,19 @@ func (mc *MockContiv) GetPodNetwork() (podNetwork *net.IPNet) {
 	return mc.podNetwork
 }
 
+// IsLocalPodIP returns true if the given IP address belongs to the pod subnet or to any of the extra pod subnets.
+func (mc *MockContiv) IsLocalPodIP(ip net.IP) bool {
+	if mc.podNetwork != nil && mc.podNetwork.Contains(ip) {
+		return true
+	}
+	for _, network := range mc.extraPodNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
 // IsTCPstackDisabled returns true if the tcp stack is disabled and only veths are configured
 func (mc *MockContiv) IsTCPstackDisabled() bool {
 	return mc.tcpStackDisabled
@@ -209,6 +283,16 @@ func (mc *MockContiv) WatchNodeIP(subscriber chan string) {
 	mc.nodeIPsubs = append(mc.nodeIPsubs, subscriber)
 }
 
+// WatchNodeConfig adds given channel to the list of subscribers that are notified when the configuration
+// of this node is changed at runtime. If the channel is not ready to receive notification, the notification
+// is dropped.
+func (mc *MockContiv) WatchNodeConfig(subscriber chan struct{}) {
+	mc.Lock()
+	defer mc.Unlock()
+
+	mc.nodeConfigSubs = append(mc.nodeConfigSubs, subscriber)
+}
+
 // GetMainPhysicalIfName returns name of the "main" interface - i.e. physical interface connecting
 // the node with the rest of the cluster.
 func (mc *MockContiv) GetMainPhysicalIfName() string {
@@ -233,12 +317,48 @@ func (mc *MockContiv) GetVxlanBVIIfName() string {
 	return mc.vxlanBVIIfName
 }
 
+// GetOverlayTunnelIfNames returns the names of the tunnels towards the other nodes which terminate
+// the traffic of the overlay themselves (IP-in-IP).
+func (mc *MockContiv) GetOverlayTunnelIfNames() []string {
+	mc.Lock()
+	defer mc.Unlock()
+
+	return mc.overlayTunnels
+}
+
+// WatchOverlayTunnels adds given channel to the list of subscribers that are notified when the tunnels
+// returned by GetOverlayTunnelIfNames change.
+func (mc *MockContiv) WatchOverlayTunnels(subscriber chan struct{}) {
+	mc.Lock()
+	defer mc.Unlock()
+
+	mc.overlayTunnelSubs = append(mc.overlayTunnelSubs, subscriber)
+}
+
 // GetDefaultGatewayIP returns the IP address of the default gateway for external traffic.
 // If the default GW is not configured, the function returns nil.
 func (mc *MockContiv) GetDefaultGatewayIP() net.IP {
 	return mc.gwIP
 }
 
+// GetNamespaceTenant returns the name of the tenant whose network connects the PODs of the given namespace.
+// Returns an empty string for the namespaces connected to the pod network.
+func (mc *MockContiv) GetNamespaceTenant(namespace string) string {
+	return mc.namespaceTenants[namespace]
+}
+
+// ExecuteVppCLI returns the output set for the given command by SetVppCLIOutput.
+func (mc *MockContiv) ExecuteVppCLI(cmd string) (string, error) {
+	mc.Lock()
+	defer mc.Unlock()
+
+	output, found := mc.vppCLIOutput[cmd]
+	if !found {
+		return "", fmt.Errorf("unknown VPP CLI command '%s'", cmd)
+	}
+	return output, nil
+}
+
 // RegisterPodPreRemovalHook allows to register callback that will be run for each
 // pod immediately before its removal.
 func (mc *MockContiv) RegisterPodPreRemovalHook(hook contiv.PodActionHook) {
@@ -56,7 +56,9 @@ func (t *TxnTracker) NewLinuxDataChangeTxn() linux.DataChangeDSL {
 	txn := &Txn{}
 	dsl := linuxplugin.NewMockDataChangeDSL(func(Ops []dsl.TxnOp) error { return t.commit(txn, t.applyDataChangeTxnOps, Ops) })
 	txn.LinuxDataChangeTxn = dsl
+	t.lock.Lock()
 	t.PendingTxns[txn] = struct{}{}
+	t.lock.Unlock()
 	return dsl
 }
 
@@ -65,7 +67,9 @@ func (t *TxnTracker) NewDefaultPluginsDataChangeTxn() defaultplugins.DataChangeD
 	txn := &Txn{}
 	dsl := mockdefaultplugins.NewMockDataChangeDSL(func(Ops []dsl.TxnOp) error { return t.commit(txn, t.applyDataChangeTxnOps, Ops) })
 	txn.DefaultPluginsDataChangeTxn = dsl
+	t.lock.Lock()
 	t.PendingTxns[txn] = struct{}{}
+	t.lock.Unlock()
 	return dsl
 }
 
@@ -88,7 +92,9 @@ func (t *TxnTracker) NewLinuxDataResyncTxn() linux.DataResyncDSL {
 	txn := &Txn{}
 	dsl := linuxplugin.NewMockDataResyncDSL(func(Ops []dsl.TxnOp) error { return t.commit(txn, t.applyDataResyncTxnOps, Ops) })
 	txn.LinuxDataResyncTxn = dsl
+	t.lock.Lock()
 	t.PendingTxns[txn] = struct{}{}
+	t.lock.Unlock()
 	return dsl
 }
 
@@ -97,7 +103,9 @@ func (t *TxnTracker) NewDefaultPluginsDataResyncTxn() defaultplugins.DataResyncD
 	txn := &Txn{}
 	dsl := mockdefaultplugins.NewMockDataResyncDSL(func(Ops []dsl.TxnOp) error { return t.commit(txn, t.applyDataResyncTxnOps, Ops) })
 	txn.DefaultPluginsDataResyncTxn = dsl
+	t.lock.Lock()
 	t.PendingTxns[txn] = struct{}{}
+	t.lock.Unlock()
 	return dsl
 }
 
@@ -102,6 +102,7 @@ func (mr *MockRenderer) GetPodIP(pod podmodel.ID) (ip string, masklen int) {
 // TestTraffic allows to simulate a traffic and test what the outcome would
 // be with the rendered configuration.
 // The direction is from the vswitch point of view!
+// For ICMP, srcPort and destPort carry the ICMP type and code, respectively.
 func (mr *MockRenderer) TestTraffic(pod podmodel.ID, direction TrafficDirection, srcIP *net.IP,
 	destIP *net.IP, protocol renderer.ProtocolType, srcPort uint16, destPort uint16) TrafficAction {
 	mr.lock.Lock()
@@ -120,19 +121,7 @@ func (mr *MockRenderer) TestTraffic(pod podmodel.ID, direction TrafficDirection,
 	}
 
 	for _, rule := range rules {
-		if len(rule.SrcNetwork.IP) > 0 && !rule.SrcNetwork.Contains(*srcIP) {
-			continue
-		}
-		if len(rule.DestNetwork.IP) > 0 && !rule.DestNetwork.Contains(*destIP) {
-			continue
-		}
-		if rule.Protocol != protocol {
-			continue
-		}
-		if rule.SrcPort != 0 && rule.SrcPort != srcPort {
-			continue
-		}
-		if rule.DestPort != 0 && rule.DestPort != destPort {
+		if !rule.Matches(*srcIP, *destIP, protocol, srcPort, destPort) {
 			continue
 		}
 		// Match!
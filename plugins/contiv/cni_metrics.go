@@ -0,0 +1,250 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contiv
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	prometheusplugin "github.com/ligato/cn-infra/rpc/prometheus"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/unrolled/render"
+)
+
+const (
+	// CNIMetricsPath is the URL path of the Prometheus registry with the metrics of the CNI server.
+	CNIMetricsPath = "/metrics/cni"
+
+	// CNIRequestsPath is the URL path of the log of the recent CNI requests.
+	CNIRequestsPath = "/contiv/v1/cni-requests"
+
+	// recentCNIRequests is the number of the most recent CNI requests kept in the log
+	recentCNIRequests = 100
+
+	metricsNamespace = "contiv"
+	metricsSubsystem = "cni"
+	nodeLabel        = "node"
+	operationLabel   = "operation"
+	phaseLabel       = "phase"
+	causeLabel       = "cause"
+)
+
+// CNI operations distinguished by the metrics
+const (
+	cniAddOperation    = "add"
+	cniDeleteOperation = "delete"
+)
+
+// Phases of the CNI requests measured by the metrics. The phase of the request which failed
+// is the cause of the error, unless a more specific cause is known.
+const (
+	phaseIPAM      = "ipam"      // allocation / release of the IP addresses
+	phaseLinux     = "linux"     // configuration of the POD interface (inside the POD namespace)
+	phaseVPP       = "vpp"       // configuration of the VPP side of the POD connectivity
+	phaseSecondary = "secondary" // configuration of the interfaces in the secondary networks
+	phasePersist   = "persist"   // persisting of the POD configuration in ETCD and in the container index
+	phaseTotal     = "total"     // whole request, including the wait for the lock of the CNI server
+)
+
+// Causes of the CNI errors other than the failed phase
+const (
+	causeInvalidRequest  = "invalid_request"   // the request can not be satisfied (e.g. unsupported combination of arguments)
+	causeIPPoolExhausted = "ip_pool_exhausted" // no IP address is left in the pod network
+	causeOther           = "other"
+)
+
+// cniMetrics collects the metrics of the CNI requests processed by the CNI server and keeps the log
+// of the most recent requests. The metrics are exported via the Prometheus plugin, the log via the HTTP plugin.
+type cniMetrics struct {
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+
+	sync.Mutex
+	recent []*cniRequestRecord // the oldest request first
+}
+
+// cniRequestRecord is a single CNI request in the log of the recent requests.
+type cniRequestRecord struct {
+	Time         time.Time         `json:"time"`
+	Operation    string            `json:"operation"`
+	ContainerID  string            `json:"containerId"`
+	PodNamespace string            `json:"podNamespace,omitempty"`
+	PodName      string            `json:"podName,omitempty"`
+	Duration     string            `json:"duration"`
+	Phases       map[string]string `json:"phases,omitempty"` // duration of the phases the request went through
+	Error        string            `json:"error,omitempty"`
+	ErrorCause   string            `json:"errorCause,omitempty"`
+}
+
+// cniRequestTrace measures the phases of a single CNI request. The methods are safe to call on nil trace
+// (for the operations not reported as CNI requests, e.g. the removal of stale PODs).
+type cniRequestTrace struct {
+	metrics *cniMetrics
+	start   time.Time
+	record  *cniRequestRecord
+}
+
+// newCNIMetrics returns new instance of cniMetrics for the given node.
+func newCNIMetrics(nodeName string) *cniMetrics {
+	return &cniMetrics{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   metricsNamespace,
+			Subsystem:   metricsSubsystem,
+			Name:        "request_duration_seconds",
+			Help:        "Duration of the CNI requests and of their phases",
+			ConstLabels: prometheus.Labels{nodeLabel: nodeName},
+			Buckets:     prometheus.ExponentialBuckets(0.005, 2, 14),
+		}, []string{operationLabel, phaseLabel}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   metricsNamespace,
+			Subsystem:   metricsSubsystem,
+			Name:        "request_errors_total",
+			Help:        "Number of the failed CNI requests by the cause of the error",
+			ConstLabels: prometheus.Labels{nodeLabel: nodeName},
+		}, []string{operationLabel, causeLabel}),
+	}
+}
+
+// register registers the metrics of the CNI server into a new registry of the Prometheus plugin.
+// The gauges of the IP pool utilisation and of the number of configured PODs are evaluated on each scrape.
+func (m *cniMetrics) register(prom prometheusplugin.API, nodeName string, ipPoolUsage func() (used, size int),
+	configuredPods func() int) error {
+
+	err := prom.NewRegistry(CNIMetricsPath, promhttp.HandlerOpts{ErrorHandling: promhttp.ContinueOnError})
+	if err != nil {
+		return err
+	}
+	for _, collector := range []prometheus.Collector{m.duration, m.errors} {
+		err = prom.Register(CNIMetricsPath, collector)
+		if err != nil {
+			return err
+		}
+	}
+	labels := prometheus.Labels{nodeLabel: nodeName}
+	gauges := []struct {
+		name  string
+		help  string
+		value func() float64
+	}{
+		{"pod_ip_pool_used", "Number of IP addresses of the pod network assigned to PODs", func() float64 {
+			used, _ := ipPoolUsage()
+			return float64(used)
+		}},
+		{"pod_ip_pool_size", "Number of IP addresses of the pod network available for PODs", func() float64 {
+			_, size := ipPoolUsage()
+			return float64(size)
+		}},
+		{"configured_pods", "Number of containers connected by the CNI server", func() float64 {
+			return float64(configuredPods())
+		}},
+	}
+	for _, gauge := range gauges {
+		err = prom.RegisterGaugeFunc(CNIMetricsPath, metricsNamespace, metricsSubsystem, gauge.name, gauge.help,
+			labels, gauge.value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startRequest starts the trace of the CNI request for the given container.
+func (m *cniMetrics) startRequest(operation string, containerID string) *cniRequestTrace {
+	now := time.Now()
+	return &cniRequestTrace{
+		metrics: m,
+		start:   now,
+		record: &cniRequestRecord{
+			Time:        now,
+			Operation:   operation,
+			ContainerID: containerID,
+			Phases:      map[string]string{},
+		},
+	}
+}
+
+// recentRequests returns the log of the most recent CNI requests, the oldest request first.
+func (m *cniMetrics) recentRequests() []*cniRequestRecord {
+	m.Lock()
+	defer m.Unlock()
+
+	recent := make([]*cniRequestRecord, len(m.recent))
+	copy(recent, m.recent)
+	return recent
+}
+
+// recentRequestsHandler is the HTTP handler returning the log of the most recent CNI requests.
+func (m *cniMetrics) recentRequestsHandler(formatter *render.Render) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		formatter.JSON(w, http.StatusOK, m.recentRequests())
+	}
+}
+
+// setPod sets the POD of the traced request.
+func (t *cniRequestTrace) setPod(namespace, name string) {
+	if t == nil {
+		return
+	}
+	t.record.PodNamespace = namespace
+	t.record.PodName = name
+}
+
+// phase records the duration of the phase started at <start>. The failed phase becomes the cause
+// of the error of the request, unless the cause was already set.
+func (t *cniRequestTrace) phase(phase string, start time.Time, err error) {
+	if t == nil {
+		return
+	}
+	duration := time.Since(start)
+	t.metrics.duration.WithLabelValues(t.record.Operation, phase).Observe(duration.Seconds())
+	t.record.Phases[phase] = duration.String()
+	if err != nil {
+		t.fail(phase)
+	}
+}
+
+// fail sets the cause of the error of the request, unless the cause was already set.
+func (t *cniRequestTrace) fail(cause string) {
+	if t == nil || t.record.ErrorCause != "" {
+		return
+	}
+	t.record.ErrorCause = cause
+}
+
+// finish records the result of the request.
+func (t *cniRequestTrace) finish(err error) {
+	if t == nil {
+		return
+	}
+	duration := time.Since(t.start)
+	t.metrics.duration.WithLabelValues(t.record.Operation, phaseTotal).Observe(duration.Seconds())
+	t.record.Duration = duration.String()
+	if err != nil {
+		t.fail(causeOther)
+		t.record.Error = err.Error()
+		t.metrics.errors.WithLabelValues(t.record.Operation, t.record.ErrorCause).Inc()
+	} else {
+		t.record.ErrorCause = ""
+	}
+
+	t.metrics.Lock()
+	defer t.metrics.Unlock()
+	t.metrics.recent = append(t.metrics.recent, t.record)
+	if len(t.metrics.recent) > recentCNIRequests {
+		t.metrics.recent = t.metrics.recent[len(t.metrics.recent)-recentCNIRequests:]
+	}
+}
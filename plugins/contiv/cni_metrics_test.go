@@ -0,0 +1,109 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contiv
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/onsi/gomega"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/contiv/vpp/plugins/contiv/model/cni"
+)
+
+func TestCNIMetrics(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	// pod network with 2 addresses available for PODs
+	config := configVethL2NoTCP
+	config.IPAMConfig.PodNetworkPrefixLen = 30
+	server, _, _, conn := setupTestCNIServer(&config, nil)
+	defer conn.Disconnect()
+
+	// pretend that connectivity is configured to unblock CNI requests
+	server.vswitchConnectivityConfigured = true
+
+	podRequest := func(i int, extraArgs string) *cni.CNIRequest {
+		request := req
+		request.ContainerId = fmt.Sprintf("%s-%d", containerID, i)
+		request.ExtraArguments = fmt.Sprintf("K8S_POD_NAMESPACE=%s;K8S_POD_NAME=%s-%d%s", podNamespace, podName, i, extraArgs)
+		return &request
+	}
+	errorCount := func(operation, cause string) float64 {
+		metric := &dto.Metric{}
+		server.metrics.errors.WithLabelValues(operation, cause).Write(metric)
+		return metric.Counter.GetValue()
+	}
+	sampleCount := func(operation, phase string) uint64 {
+		metric := &dto.Metric{}
+		server.metrics.duration.WithLabelValues(operation, phase).(interface {
+			Write(*dto.Metric) error
+		}).Write(metric)
+		return metric.Histogram.GetSampleCount()
+	}
+
+	// successful Add
+	_, err := server.Add(context.Background(), podRequest(1, ""))
+	gomega.Expect(err).To(gomega.BeNil())
+	recent := server.metrics.recentRequests()
+	gomega.Expect(recent).To(gomega.HaveLen(1))
+	gomega.Expect(recent[0].Operation).To(gomega.Equal(cniAddOperation))
+	gomega.Expect(recent[0].PodName).To(gomega.Equal(podName + "-1"))
+	gomega.Expect(recent[0].Error).To(gomega.BeEmpty())
+	gomega.Expect(recent[0].Phases).To(gomega.HaveKey(phaseIPAM))
+	gomega.Expect(recent[0].Phases).To(gomega.HaveKey(phaseLinux))
+	gomega.Expect(recent[0].Phases).To(gomega.HaveKey(phaseVPP))
+	gomega.Expect(recent[0].Phases).To(gomega.HaveKey(phasePersist))
+	gomega.Expect(recent[0].Phases).NotTo(gomega.HaveKey(phaseSecondary))
+	gomega.Expect(sampleCount(cniAddOperation, phaseTotal)).To(gomega.BeEquivalentTo(1))
+
+	// invalid request
+	_, err = server.Add(context.Background(), podRequest(2, ";INTERFACE_TYPE=unknown"))
+	gomega.Expect(err).NotTo(gomega.BeNil())
+	gomega.Expect(errorCount(cniAddOperation, causeInvalidRequest)).To(gomega.BeEquivalentTo(1))
+
+	// IP pool utilisation
+	used, size := server.ipam.PodIPPoolUsage()
+	gomega.Expect(used).To(gomega.BeEquivalentTo(1))
+	gomega.Expect(size).To(gomega.BeEquivalentTo(2))
+	_, err = server.Add(context.Background(), podRequest(3, ""))
+	gomega.Expect(err).To(gomega.BeNil())
+	_, err = server.Add(context.Background(), podRequest(4, ""))
+	gomega.Expect(err).NotTo(gomega.BeNil())
+	gomega.Expect(errorCount(cniAddOperation, causeIPPoolExhausted)).To(gomega.BeEquivalentTo(1))
+	recent = server.metrics.recentRequests()
+	gomega.Expect(recent).To(gomega.HaveLen(4))
+	gomega.Expect(recent[3].ErrorCause).To(gomega.Equal(causeIPPoolExhausted))
+	gomega.Expect(recent[3].Error).NotTo(gomega.BeEmpty())
+
+	// successful Delete
+	_, err = server.Delete(context.Background(), podRequest(1, ""))
+	gomega.Expect(err).To(gomega.BeNil())
+	recent = server.metrics.recentRequests()
+	gomega.Expect(recent[4].Operation).To(gomega.Equal(cniDeleteOperation))
+	gomega.Expect(recent[4].Phases).To(gomega.HaveKey(phaseIPAM))
+	gomega.Expect(recent[4].Phases).To(gomega.HaveKey(phasePersist))
+	gomega.Expect(sampleCount(cniDeleteOperation, phaseVPP)).To(gomega.BeEquivalentTo(1))
+
+	// only the most recent requests are kept
+	for i := 0; i < recentCNIRequests; i++ {
+		server.metrics.startRequest(cniDeleteOperation, "unknown").finish(nil)
+	}
+	recent = server.metrics.recentRequests()
+	gomega.Expect(recent).To(gomega.HaveLen(recentCNIRequests))
+	gomega.Expect(recent[0].ContainerID).To(gomega.Equal("unknown"))
+}
@@ -155,7 +155,7 @@ func (ci *ConfigIndex) Watch(subscriber core.PluginName, callback func(ChangeEve
 }
 
 // IndexFunction creates secondary indexes. Currently podName, podNamespace,
-// and the associated interface/namespace are indexed.
+// and the associated interfaces (including interfaces in the secondary networks)/namespace are indexed.
 func IndexFunction(data interface{}) map[string][]string {
 	res := map[string][]string{}
 	if config, ok := data.(*container.Persisted); ok && config != nil {
@@ -167,6 +167,11 @@ func IndexFunction(data interface{}) map[string][]string {
 		if config.LoopbackName != "" {
 			res[podRelatedIfsKey] = append(res[podRelatedIfsKey], config.LoopbackName)
 		}
+		for _, secondaryIf := range config.SecondaryInterfaces {
+			if secondaryIf.VppIfName != "" {
+				res[podRelatedIfsKey] = append(res[podRelatedIfsKey], secondaryIf.VppIfName)
+			}
+		}
 		if config.AppNamespaceID != "" {
 			res[podRelatedAppNsKey] = []string{config.AppNamespaceID}
 		}
@@ -79,6 +79,9 @@ func TestSecondaryIndexLookup(t *testing.T) {
 		podB       = "456"
 		podAAppNs  = "appNsA"
 		podBAppNs  = "appNsB"
+
+		podBIf          = "tapBBB"
+		podBSecondaryIf = "tapBBB-1"
 	)
 
 	configA := &container.Persisted{
@@ -90,6 +93,10 @@ func TestSecondaryIndexLookup(t *testing.T) {
 		PodNamespace:   podNs,
 		PodName:        podB,
 		AppNamespaceID: podBAppNs,
+		VppIfName:      podBIf,
+		SecondaryInterfaces: []*container.SecondaryInterface{
+			{Network: "data", IfName: "net1", VppIfName: podBSecondaryIf},
+		},
 	}
 
 	idx.RegisterContainer(containerA, configA)
@@ -113,6 +120,12 @@ func TestSecondaryIndexLookup(t *testing.T) {
 	appNsMatch = idx.LookupPodAppNs(podBAppNs)
 	gomega.Expect(appNsMatch).To(gomega.HaveLen(1))
 	gomega.Expect(appNsMatch).To(gomega.ContainElement(containerB))
+
+	ifMatch := idx.LookupPodIf(podBIf)
+	gomega.Expect(ifMatch).To(gomega.ConsistOf(containerB))
+
+	ifMatch = idx.LookupPodIf(podBSecondaryIf)
+	gomega.Expect(ifMatch).To(gomega.ConsistOf(containerB))
 }
 
 func TestWatch(t *testing.T) {
@@ -9,6 +9,7 @@ It is generated from these files:
 
 It has these top-level messages:
 	Persisted
+	SecondaryInterface
 */
 package container
 
@@ -70,6 +71,26 @@ type Persisted struct {
 	PodLinkRouteName string `protobuf:"bytes,18,opt,name=PodLinkRouteName" json:"PodLinkRouteName,omitempty"`
 	// PodDefaultRoute is name of the default gateway for the pod.
 	PodDefaultRouteName string `protobuf:"bytes,19,opt,name=PodDefaultRouteName" json:"PodDefaultRouteName,omitempty"`
+	// VppARPEntryIPv6 is IPv6 address of the ND entry configured in VPP to route traffic from VPP to pod
+	// (interface is the same as for the IPv4 ARP entry). Empty if IPv6 is not enabled.
+	VppARPEntryIPv6 string `protobuf:"bytes,20,opt,name=VppARPEntryIPv6" json:"VppARPEntryIPv6,omitempty"`
+	// PodARPEntryIPv6Name is name of the IPv6 neighbor entry configured in the pod to route traffic from pod to VPP.
+	PodARPEntryIPv6Name string `protobuf:"bytes,21,opt,name=PodARPEntryIPv6Name" json:"PodARPEntryIPv6Name,omitempty"`
+	// VppRouteIPv6Dest is destination of the IPv6 route from VPP to the container
+	// (vrf and next hop are the same as for the IPv4 route).
+	VppRouteIPv6Dest string `protobuf:"bytes,22,opt,name=VppRouteIPv6Dest" json:"VppRouteIPv6Dest,omitempty"`
+	// PodLinkRouteIPv6Name is name of the IPv6 route from pod to the default gateway.
+	PodLinkRouteIPv6Name string `protobuf:"bytes,23,opt,name=PodLinkRouteIPv6Name" json:"PodLinkRouteIPv6Name,omitempty"`
+	// PodDefaultRouteIPv6Name is name of the IPv6 default gateway for the pod.
+	PodDefaultRouteIPv6Name string `protobuf:"bytes,24,opt,name=PodDefaultRouteIPv6Name" json:"PodDefaultRouteIPv6Name,omitempty"`
+	// SecondaryInterfaces are interfaces of the pod attached to the secondary networks.
+	SecondaryInterfaces []*SecondaryInterface `protobuf:"bytes,25,rep,name=SecondaryInterfaces" json:"SecondaryInterfaces,omitempty"`
+	// MemifSocket is path to the socket of the memif interface connecting the pod to VPP.
+	// Empty if the pod is not connected via memif.
+	MemifSocket string `protobuf:"bytes,26,opt,name=MemifSocket" json:"MemifSocket,omitempty"`
+	// Tenant is the name of the tenant the pod network belongs to.
+	// Empty if the pod is connected to the default pod network.
+	Tenant string `protobuf:"bytes,27,opt,name=Tenant" json:"Tenant,omitempty"`
 }
 
 func (m *Persisted) Reset()                    { *m = Persisted{} }
@@ -203,34 +224,220 @@ func (m *Persisted) GetPodDefaultRouteName() string {
 	return ""
 }
 
+func (m *Persisted) GetVppARPEntryIPv6() string {
+	if m != nil {
+		return m.VppARPEntryIPv6
+	}
+	return ""
+}
+
+func (m *Persisted) GetPodARPEntryIPv6Name() string {
+	if m != nil {
+		return m.PodARPEntryIPv6Name
+	}
+	return ""
+}
+
+func (m *Persisted) GetVppRouteIPv6Dest() string {
+	if m != nil {
+		return m.VppRouteIPv6Dest
+	}
+	return ""
+}
+
+func (m *Persisted) GetPodLinkRouteIPv6Name() string {
+	if m != nil {
+		return m.PodLinkRouteIPv6Name
+	}
+	return ""
+}
+
+func (m *Persisted) GetPodDefaultRouteIPv6Name() string {
+	if m != nil {
+		return m.PodDefaultRouteIPv6Name
+	}
+	return ""
+}
+
+func (m *Persisted) GetSecondaryInterfaces() []*SecondaryInterface {
+	if m != nil {
+		return m.SecondaryInterfaces
+	}
+	return nil
+}
+
+func (m *Persisted) GetMemifSocket() string {
+	if m != nil {
+		return m.MemifSocket
+	}
+	return ""
+}
+
+func (m *Persisted) GetTenant() string {
+	if m != nil {
+		return m.Tenant
+	}
+	return ""
+}
+
+// SecondaryInterface represents configured items for a pod interface attached to a secondary network.
+type SecondaryInterface struct {
+	// Network is the name of the secondary network.
+	Network string `protobuf:"bytes,1,opt,name=network" json:"network,omitempty"`
+	// IfName is the name of the interface inside the pod.
+	IfName string `protobuf:"bytes,2,opt,name=ifName" json:"ifName,omitempty"`
+	// IP is the IP address assigned to the pod in the network.
+	IP string `protobuf:"bytes,3,opt,name=IP" json:"IP,omitempty"`
+	// Veth1Name is name of the veth end in the pod namespace. Empty if TAPs are used instead.
+	Veth1Name string `protobuf:"bytes,4,opt,name=Veth1Name" json:"Veth1Name,omitempty"`
+	// Veth2Name is name of the veth end in the default namespace. Empty if TAPs are used instead.
+	Veth2Name string `protobuf:"bytes,5,opt,name=Veth2Name" json:"Veth2Name,omitempty"`
+	// VppIfName is name of the AF_PACKET/TAP interface connecting the pod to VPP.
+	VppIfName string `protobuf:"bytes,6,opt,name=VppIfName" json:"VppIfName,omitempty"`
+	// PodTapName is name of the host end of the tap connecting the pod to VPP. Empty if TAPs are not used.
+	PodTapName string `protobuf:"bytes,7,opt,name=PodTapName" json:"PodTapName,omitempty"`
+	// VppRouteVrf is vrf of the route from VPP to the pod (VRF of the network).
+	VppRouteVrf uint32 `protobuf:"varint,8,opt,name=VppRouteVrf" json:"VppRouteVrf,omitempty"`
+	// VppRouteDest is destination of the route from VPP to the pod.
+	VppRouteDest string `protobuf:"bytes,9,opt,name=VppRouteDest" json:"VppRouteDest,omitempty"`
+	// PodARPEntryName is name of the ARP entry configured in the pod for the network gateway.
+	PodARPEntryName string `protobuf:"bytes,10,opt,name=PodARPEntryName" json:"PodARPEntryName,omitempty"`
+	// PodLinkRouteName is name of the route from pod to the network gateway.
+	PodLinkRouteName string `protobuf:"bytes,11,opt,name=PodLinkRouteName" json:"PodLinkRouteName,omitempty"`
+	// PodNetworkRouteName is name of the route from pod to the subnet of the network via the gateway.
+	PodNetworkRouteName string `protobuf:"bytes,12,opt,name=PodNetworkRouteName" json:"PodNetworkRouteName,omitempty"`
+}
+
+func (m *SecondaryInterface) Reset()                    { *m = SecondaryInterface{} }
+func (m *SecondaryInterface) String() string            { return proto.CompactTextString(m) }
+func (*SecondaryInterface) ProtoMessage()               {}
+func (*SecondaryInterface) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *SecondaryInterface) GetNetwork() string {
+	if m != nil {
+		return m.Network
+	}
+	return ""
+}
+
+func (m *SecondaryInterface) GetIfName() string {
+	if m != nil {
+		return m.IfName
+	}
+	return ""
+}
+
+func (m *SecondaryInterface) GetIP() string {
+	if m != nil {
+		return m.IP
+	}
+	return ""
+}
+
+func (m *SecondaryInterface) GetVeth1Name() string {
+	if m != nil {
+		return m.Veth1Name
+	}
+	return ""
+}
+
+func (m *SecondaryInterface) GetVeth2Name() string {
+	if m != nil {
+		return m.Veth2Name
+	}
+	return ""
+}
+
+func (m *SecondaryInterface) GetVppIfName() string {
+	if m != nil {
+		return m.VppIfName
+	}
+	return ""
+}
+
+func (m *SecondaryInterface) GetPodTapName() string {
+	if m != nil {
+		return m.PodTapName
+	}
+	return ""
+}
+
+func (m *SecondaryInterface) GetVppRouteVrf() uint32 {
+	if m != nil {
+		return m.VppRouteVrf
+	}
+	return 0
+}
+
+func (m *SecondaryInterface) GetVppRouteDest() string {
+	if m != nil {
+		return m.VppRouteDest
+	}
+	return ""
+}
+
+func (m *SecondaryInterface) GetPodARPEntryName() string {
+	if m != nil {
+		return m.PodARPEntryName
+	}
+	return ""
+}
+
+func (m *SecondaryInterface) GetPodLinkRouteName() string {
+	if m != nil {
+		return m.PodLinkRouteName
+	}
+	return ""
+}
+
+func (m *SecondaryInterface) GetPodNetworkRouteName() string {
+	if m != nil {
+		return m.PodNetworkRouteName
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*Persisted)(nil), "container.Persisted")
+	proto.RegisterType((*SecondaryInterface)(nil), "container.SecondaryInterface")
 }
 
 func init() { proto.RegisterFile("container.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 342 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x92, 0xdd, 0x6a, 0xf2, 0x40,
-	0x10, 0x40, 0xd1, 0xef, 0x53, 0x9b, 0xf1, 0xb7, 0x63, 0x2f, 0xf6, 0xa2, 0x14, 0x91, 0x52, 0xa4,
-	0x17, 0xa5, 0xb5, 0x4f, 0x20, 0xa4, 0xd0, 0x80, 0xc8, 0x92, 0x96, 0xdc, 0x47, 0xb3, 0xa1, 0xa2,
-	0xcd, 0x0e, 0xc9, 0x0a, 0xed, 0x73, 0xf4, 0x85, 0x4b, 0x26, 0x46, 0xd7, 0xd4, 0x3b, 0xf7, 0x9c,
-	0x23, 0x3b, 0x3b, 0x04, 0xfa, 0x2b, 0x9d, 0x98, 0x70, 0x9d, 0xa8, 0xf4, 0x81, 0x52, 0x6d, 0x34,
-	0x3a, 0x07, 0x30, 0xfe, 0x69, 0x80, 0x23, 0x55, 0x9a, 0xad, 0x33, 0xa3, 0x22, 0xec, 0x41, 0xdd,
-	0x73, 0x45, 0x6d, 0x54, 0x9b, 0x38, 0x7e, 0xdd, 0x73, 0x51, 0x40, 0x8b, 0x74, 0xb4, 0x08, 0x3f,
-	0x95, 0xa8, 0x33, 0x2c, 0x8f, 0x38, 0x86, 0xce, 0xfe, 0x67, 0x46, 0xe1, 0x4a, 0x89, 0x7f, 0xac,
-	0x4f, 0x18, 0x5e, 0x83, 0x13, 0x28, 0xf3, 0xf1, 0xc4, 0xff, 0xff, 0xcf, 0xc1, 0x11, 0x94, 0x76,
-	0xca, 0xb6, 0x71, 0xb4, 0xd3, 0x83, 0x25, 0xf2, 0x62, 0xb6, 0xcd, 0xbd, 0x2d, 0x01, 0xde, 0x00,
-	0x48, 0x1d, 0xbd, 0x87, 0xc4, 0xba, 0xc5, 0xda, 0x22, 0xf9, 0x74, 0x73, 0xad, 0x69, 0x19, 0xae,
-	0x36, 0x5c, 0x5c, 0x14, 0xd3, 0xd9, 0x0c, 0x47, 0xd0, 0x7e, 0x33, 0x89, 0xbf, 0xdb, 0x2a, 0x4e,
-	0x1c, 0x4e, 0x6c, 0x84, 0x77, 0xd0, 0x9b, 0x11, 0x1d, 0xde, 0xe3, 0xb9, 0x02, 0x38, 0xaa, 0x50,
-	0x9c, 0xc2, 0x55, 0x40, 0x34, 0xf3, 0xe5, 0x4b, 0x62, 0xd2, 0x6f, 0x2f, 0x31, 0x2a, 0x8d, 0xf3,
-	0x9d, 0xb4, 0xb9, 0x3e, 0xeb, 0xf0, 0x16, 0xba, 0x36, 0x97, 0xa2, 0xc3, 0xf1, 0x29, 0xc4, 0x09,
-	0xf4, 0xa5, 0x8e, 0x4a, 0xc0, 0x73, 0x76, 0xb9, 0xab, 0xe2, 0xfc, 0x35, 0x01, 0x91, 0xaf, 0x77,
-	0x46, 0x05, 0x69, 0x2c, 0xfa, 0xa3, 0xda, 0xa4, 0xeb, 0xdb, 0x28, 0xdf, 0x49, 0x79, 0x74, 0x55,
-	0x66, 0xc4, 0xa0, 0xd8, 0x89, 0xcd, 0xf2, 0xfb, 0xca, 0xf3, 0x42, 0x7d, 0x99, 0x57, 0x4d, 0xe2,
-	0xb2, 0xb8, 0xaf, 0x82, 0xf1, 0x1e, 0x06, 0x52, 0x47, 0xf3, 0x75, 0xb2, 0x29, 0x70, 0x3e, 0x1a,
-	0x72, 0xfa, 0x87, 0xe3, 0x23, 0x0c, 0xa5, 0x8e, 0x5c, 0x15, 0x87, 0xbb, 0xad, 0x39, 0xe6, 0x43,
-	0xce, 0xcf, 0xa9, 0x65, 0x93, 0xbf, 0xd3, 0xe7, 0xdf, 0x00, 0x00, 0x00, 0xff, 0xff, 0x0a, 0xfe,
-	0xad, 0xb5, 0xba, 0x02, 0x00, 0x00,
+	// 530 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x94, 0x4b, 0x6f, 0xd3, 0x40,
+	0x10, 0xc7, 0x95, 0x04, 0xd2, 0x78, 0x92, 0x34, 0x65, 0x53, 0xda, 0xe5, 0xa9, 0x28, 0x42, 0x28,
+	0xe2, 0x50, 0x41, 0x90, 0x10, 0xd7, 0x4a, 0x46, 0xc2, 0x52, 0x09, 0x96, 0x53, 0xe5, 0xee, 0xda,
+	0x6b, 0x61, 0xa5, 0xdd, 0x5d, 0xd9, 0x1b, 0x1e, 0xdf, 0x83, 0x33, 0x9f, 0x15, 0xed, 0xf8, 0xb5,
+	0xd9, 0x18, 0x38, 0x72, 0xcb, 0xfc, 0xfe, 0xff, 0x9d, 0xcc, 0x8e, 0x77, 0x06, 0x26, 0x91, 0xe0,
+	0x2a, 0x4c, 0x39, 0xcb, 0x2e, 0x64, 0x26, 0x94, 0x20, 0x4e, 0x0d, 0xe6, 0x3f, 0x07, 0xe0, 0xf8,
+	0x2c, 0xcb, 0xd3, 0x5c, 0xb1, 0x98, 0x1c, 0x43, 0xd7, 0x73, 0x69, 0x67, 0xd6, 0x59, 0x38, 0x41,
+	0xd7, 0x73, 0x09, 0x85, 0x23, 0x29, 0xe2, 0x55, 0x78, 0xc7, 0x68, 0x17, 0x61, 0x15, 0x92, 0x39,
+	0x8c, 0xca, 0x9f, 0xb9, 0x0c, 0x23, 0x46, 0x7b, 0x28, 0xef, 0x31, 0xf2, 0x14, 0x9c, 0x0d, 0x53,
+	0x5f, 0xde, 0xe0, 0xf9, 0x7b, 0x68, 0x68, 0x40, 0xa5, 0x2e, 0x51, 0xbd, 0xdf, 0xa8, 0xcb, 0x5a,
+	0x95, 0xd2, 0x4b, 0x50, 0xed, 0x97, 0x6a, 0x05, 0xc8, 0x73, 0x00, 0x5f, 0xc4, 0xd7, 0xa1, 0x44,
+	0xf9, 0x08, 0x65, 0x83, 0xe8, 0xea, 0xae, 0x84, 0x90, 0x37, 0x61, 0xb4, 0x45, 0xc7, 0xa0, 0xa8,
+	0xce, 0x64, 0x64, 0x06, 0xc3, 0xb5, 0xe2, 0xc1, 0xee, 0x96, 0xa1, 0xc5, 0x41, 0x8b, 0x89, 0xc8,
+	0x4b, 0x38, 0xbe, 0x94, 0xb2, 0xbe, 0x8f, 0xe7, 0x52, 0x40, 0x93, 0x45, 0xc9, 0x12, 0x4e, 0x37,
+	0x52, 0x5e, 0x06, 0xfe, 0x07, 0xae, 0xb2, 0x1f, 0x1e, 0x57, 0x2c, 0x4b, 0x74, 0x4f, 0x86, 0xe8,
+	0x6e, 0xd5, 0xc8, 0x0b, 0x18, 0x9b, 0xdc, 0xa7, 0x23, 0x34, 0xef, 0x43, 0xb2, 0x80, 0x89, 0x2f,
+	0xe2, 0x0a, 0x60, 0x9d, 0x63, 0xf4, 0xd9, 0x58, 0xdf, 0x66, 0x23, 0x65, 0x20, 0x76, 0x8a, 0x6d,
+	0xb2, 0x84, 0x4e, 0x66, 0x9d, 0xc5, 0x38, 0x30, 0x91, 0xee, 0x49, 0x15, 0xba, 0x2c, 0x57, 0xf4,
+	0xa4, 0xe8, 0x89, 0xc9, 0xf4, 0xff, 0x55, 0xf1, 0x8a, 0x7d, 0x57, 0x1f, 0x85, 0xa4, 0x0f, 0x8a,
+	0xff, 0xb3, 0x30, 0x79, 0x05, 0x27, 0xbe, 0x88, 0xaf, 0x52, 0xbe, 0x2d, 0xb0, 0x2e, 0x8d, 0xa0,
+	0xf5, 0x80, 0x93, 0xd7, 0x30, 0xf5, 0x45, 0xec, 0xb2, 0x24, 0xdc, 0xdd, 0xaa, 0xc6, 0x3e, 0x45,
+	0x7b, 0x9b, 0x54, 0xd6, 0xd1, 0x34, 0xe2, 0xeb, 0x3b, 0x7a, 0x5a, 0xd7, 0x61, 0xe2, 0x32, 0xb7,
+	0x89, 0x30, 0xf7, 0xc3, 0x3a, 0xb7, 0x2d, 0xe9, 0xca, 0xab, 0xcb, 0x68, 0x86, 0xbd, 0x38, 0x2b,
+	0x2a, 0xb7, 0xb9, 0xfe, 0xb2, 0xe6, 0x6d, 0xea, 0xf4, 0xe7, 0xc5, 0x97, 0x6d, 0xd3, 0xc8, 0x7b,
+	0x38, 0xb7, 0xae, 0x54, 0x1f, 0xa3, 0x78, 0xec, 0x4f, 0x32, 0xf9, 0x0c, 0xd3, 0x35, 0x8b, 0x04,
+	0x8f, 0x43, 0xe3, 0xa5, 0xe4, 0xf4, 0xd1, 0xac, 0xb7, 0x18, 0x2e, 0x9f, 0x5d, 0x34, 0x53, 0x7c,
+	0xe8, 0x0a, 0xda, 0x4e, 0xea, 0x47, 0xf1, 0x89, 0xdd, 0xa5, 0xc9, 0x5a, 0x44, 0x5b, 0xa6, 0xe8,
+	0xe3, 0xe2, 0x89, 0x1b, 0x88, 0x9c, 0x41, 0xff, 0x9a, 0xf1, 0x90, 0x2b, 0xfa, 0x04, 0xc5, 0x32,
+	0x9a, 0xff, 0xea, 0x01, 0x39, 0xcc, 0xa8, 0xf7, 0x01, 0x67, 0xea, 0x9b, 0xc8, 0xb6, 0xe5, 0x92,
+	0xa8, 0x42, 0x9d, 0x28, 0x4d, 0x8c, 0x45, 0x51, 0x46, 0xb8, 0x51, 0xfc, 0x72, 0x3b, 0x74, 0x3d,
+	0xff, 0x3f, 0xee, 0x04, 0x6b, 0x42, 0x06, 0xff, 0x9e, 0x10, 0xa7, 0x7d, 0x42, 0xec, 0x89, 0x84,
+	0xf6, 0x89, 0x6c, 0x9b, 0x90, 0xe1, 0x5f, 0x27, 0x64, 0x55, 0xf4, 0xb2, 0xb1, 0x8f, 0xea, 0x57,
+	0x6c, 0x4b, 0x37, 0x7d, 0xdc, 0xe4, 0x6f, 0x7f, 0x0f, 0x00, 0x93, 0x26, 0x0c, 0x8e, 0xdc, 0x05,
+	0x00, 0x00,
 }
@@ -138,6 +138,203 @@
 // for the non-TCP/UDP communications, or not LD_PRELOAD-ed applications.
 //
 //
+// 4. memif-based pod-VPP connectivity
+//
+// PODs running their own user-space networking (e.g. DPDK or VPP based CNFs) can be connected to VPP
+// using a memif interface instead of veth/tap, requested by the POD annotation
+// "contiv.vpp/interface-type: memif" (or INTERFACE_TYPE=memif in the CNI arguments).
+// VPP is the memif master, the socket is created in <MemifSocketDir>/<pod namespace>/<pod name>/memif.sock
+// (default MemifSocketDir is /var/run/contiv/memif, a hostPath volume of the vswitch). The POD is expected
+// to mount its own directory <MemifSocketDir>/<pod namespace>/<pod name> into MemifPodDir (default
+// /run/contiv/memif), e.g. as a hostPath volume with subPathExpr $(POD_NAMESPACE)/$(POD_NAME).
+// The memif.json file in the same directory describes the socket path as seen inside the POD, the memif ID,
+// IP address, gateway and MAC address to be used by the POD end of the memif. Nothing is configured inside
+// the POD network namespace and the VPP TCP stack is not used.
+// The memif is routed the same way as the other pod interfaces, so policies and services apply to it as well.
+//
+//
+// Bandwidth limits
+//
+// The bandwidth of a POD can be limited using the standard kubernetes.io/egress-bandwidth and
+// kubernetes.io/ingress-bandwidth annotations (e.g. "10M" for 10 Mbit/s). The annotations are reflected
+// by KSR together with the other POD data and the plugin watches them, so a change of the annotations
+// takes effect without restarting the POD. Both limits are enforced by VPP policers for IPv4 and IPv6,
+// selected by classify tables on the input of the VPP interfaces (VPP supports only input policers).
+// The egress limit matches the source IP of the POD on the input of the POD-facing VPP interface,
+// the ingress limit matches the destination IP of the POD on the input of all the other VPP interfaces.
+// The traffic sent by a POD with an egress limit to another POD on the same node is policed only
+// by the egress limit of the sender.
+//
+// POD annotations
+//
+// Kubelet passes only the K8S_POD_* arguments to the CNI plugin. The other requests of a POD are therefore
+// made by its annotations, reflected by KSR together with the other POD data:
+//		- contiv.vpp/ip: static IPv4 address of the POD (CNI argument IP)
+//		- contiv.vpp/ip-reservation: name of the IPv4 address reservation from IPAMConfig
+//		  (CNI argument IP_RESERVATION)
+//		- contiv.vpp/sticky-ip: "true" to get the previous IP address back when the POD is re-created
+//		  (CNI argument STICKY_IP), implied for the PODs of StatefulSets
+//		- contiv.vpp/networks: comma-separated list of the secondary networks (CNI argument NETWORKS)
+//		- contiv.vpp/interface-type: type of the POD interface, i.e. "memif" (CNI argument INTERFACE_TYPE)
+// The annotations are applied when the POD is connected, the CNI arguments (if passed) take precedence.
+// The CNI Add request therefore waits for the POD to be reflected by KSR and fails if it is not reflected
+// within 10 seconds (kubelet retries the request later).
+// With StickyPodIPs enabled, the previous IP address of a sticky POD which is not connected is not assigned
+// to other PODs until there is no other free address. Once the POD is deleted from K8s, its address is held
+// for StickyPodIPHoldTime (for the POD to be re-created) and then released.
+//
+// Stale PODs cleanup
+//
+// A POD deleted while the vswitch (or kubelet) was down never receives the CNI Delete request and its wiring
+// would stay configured forever. The plugin therefore compares the connected containers and the IPAM
+// allocations with the PODs reflected by KSR, on each resync and periodically (StalePodCleanupInterval
+// in seconds, 5 minutes by default). Containers of the PODs which no longer exist are disconnected the same
+// way as by the CNI Delete request and IP addresses not used by any connected container are released.
+// Containers connected since the last restart of the vswitch are removed only if found stale twice in a row,
+// since KSR may not have reflected their PODs yet. The cleaned up items are logged.
+//
+// Concurrent CNI requests
+//
+// The CNI requests of different PODs are processed concurrently. The IP addresses are allocated and released
+// and the map of the configured containers is updated with the lock of the CNI server held, while the POD
+// interfaces are wired into VPP without the lock. The requests for the same container are serialized.
+// The base vswitch configuration is re-applied by resync only once the PODs being wired are finished.
+// The transactions of the PODs wired at the same time are merged into a single local client transaction
+// (txn_batcher.go), a POD wired alone still applies its configuration with its own transactions.
+//
+// CNI metrics
+//
+// With the Prometheus plugin available, the metrics of the CNI requests are exported in the registry /metrics/cni:
+// the latency of the Add/Delete requests broken down by phase (contiv_cni_request_duration_seconds, phases ipam,
+// linux, vpp, secondary, persist and total), the failed requests by cause (contiv_cni_request_errors_total, causes
+// invalid_request, ip_pool_exhausted, other or the failed phase), the utilisation of the pod IP pool
+// (contiv_cni_pod_ip_pool_used / _size) and the number of configured PODs (contiv_cni_configured_pods).
+// The log of the last 100 CNI requests, including the duration of their phases and the errors, is available
+// via the HTTP plugin at /contiv/v1/cni-requests.
+//
+// Query API
+//
+// The runtime state of the plugin can be queried read-only over the ContivQuery gRPC service (model/query),
+// served by the same GRPC server as RemoteCNI, and over the REST API of the HTTP plugin:
+//		- /contiv/v1/node: ID, name and the addresses and the interfaces of the node
+//		- /contiv/v1/ipam: IPAM pools of the node and the IP addresses allocated to PODs
+//		- /contiv/v1/pods: PODs connected by the node and their interfaces
+//		- /contiv/v1/remote-nodes: other nodes the node has routes to, with the next hops and their networks
+// The REST API returns the JSON form of the replies of the gRPC service.
+//
+// Node overlay
+//
+// The nodes are interconnected by the overlay selected by the Overlay option:
+//  - "vxlan" (default): full mesh of VXLAN tunnels in a bridge domain with BVI, which has the IP address
+//    of the node from VxlanCIDR and is the next hop of the routes from the other nodes,
+//  - "l2": no tunnels, the main VPP interfaces of the nodes must be in the same L2 network
+//    (UseL2Interconnect is an alias kept for backward compatibility),
+//  - "ipip": full mesh of routed IP-in-IP tunnels without any bridge domain; the IP address of the node
+//    from VxlanCIDR is configured on a loopback borrowed by the unnumbered tunnels and each tunnel has
+//    a host route to the overlay IP address of the other node, which is the next hop of the routes towards it.
+// The overlays implement the nodeOverlay interface (overlay.go). The vendored VPP agent does not support
+// IP-in-IP tunnels, therefore they are configured via VPP CLI and the NAT44 features applied by the service
+// plugin to the VXLAN BVI are applied to each tunnel directly. The tunnels are not part of the VPP agent
+// configuration and are kept in VPP over a restart of the agent: the tunnels found in VPP ("show ipip tunnel")
+// are re-used for the nodes with the same IP address, replaced for the nodes with another IP address
+// and removed for the nodes which no longer exist. The tunnels are registered in the interface index
+// of the VPP agent, the policy plugin applies the ACLs of the VXLAN BVI to them instead
+// (GetOverlayTunnelIfNames, WatchOverlayTunnels).
+//
+// IPsec overlay
+//
+// With IPSec.Enabled the VXLAN traffic between the nodes is protected by VPP IPsec (ESP with AES-CBC-128
+// and HMAC-SHA-256-128) in the transport (default) or tunnel mode (IPSec.Mode), only the VXLAN overlay is
+// supported. Each ordered pair of nodes uses its own security association, with the SPI composed of
+// the key generation and the IDs of both nodes (which limits the node IDs to 4095) and the keys derived
+// by HMAC-SHA256 from the cluster secret, so that both nodes derive the same association without any
+// negotiation. The cluster secret is stored in ETCD under the KSR prefix in "ipsecKeys/<generation>"
+// next to the node info; the first node with IPsec enabled generates the first generation.
+// The secret is rotated by writing a new generation (node.IPSecKey), one generation at a time:
+//  - each node accepts the inbound traffic with the two newest generations and announces the newest
+//    one in its node info (ipsec_key_generation) once the inbound security associations are configured,
+//  - the traffic towards a node is encrypted with the newest generation announced by the node;
+//    the new outbound policy is added before the old one is removed,
+// therefore the nodes switch to the new generation without dropping the traffic. The generations are encoded
+// into the SPI cyclically (255 slots): a new generation sharing the slot with a still accepted one is rejected
+// and an outbound association sharing the slot with the new generation is removed before the new one is added
+// (with a short drop). The VXLAN traffic towards the nodes without a security association is dropped,
+// the unprotected VXLAN traffic from the nodes which announced an accepted generation is dropped as well.
+// Since the secret is readable by anyone with access to ETCD, the ETCD access must be restricted accordingly.
+// The IPsec configuration is applied via VPP CLI, since the vendored VPP binary API does not contain
+// the IPsec messages.
+//
+// Tenants
+//
+// The PODs of the namespaces labeled with the tenant label (TenantLabel, "contiv.vpp/tenant" by default)
+// are connected into the network of the tenant named by the label value instead of the pod network.
+// Each tenant (Tenants) is routed in its own VRF, with its own address pool (configured as a secondary network
+// of the IPAM with the same name, the pools of different tenants may overlap) and its own mesh of VXLAN tunnels
+// with its own VNI, bridge domain and BVI. The VPP end of the POD interface is unnumbered with a loopback
+// holding the gateway IP of the tenant. The namespaces are reflected by KSR; until they are resynced,
+// no POD is connected if some tenant is configured, so that the PODs of a tenant never end up in the pod network.
+// A change of the tenant label applies only to the PODs connected after the change. The tenants are isolated
+// from each other and from the pod network, except for the SharedPrefixes of the tenant (e.g. the addresses
+// of the DNS endpoints), which are routed from the VRF of the tenant into the default VRF together with the route
+// back to the subnet of the tenant. The route back would make the PODs of the tenant reachable from the whole
+// default VRF, the PODs of a tenant sharing prefixes are therefore isolated by ACLs attached to their interfaces:
+// only the traffic from the subnet of the tenant and the replies to the traffic initiated by the PODs
+// (reflected by the input ACL) pass the output ACL. Services, policies and host ports are rendered for the pod
+// network only. A shared prefix must not overlap with the service network, since the cluster IPs are not
+// translated in the VRF of a tenant. The policy plugin reports an error for every NetworkPolicy defined
+// in a namespace of a tenant (GetNamespaceTenant), since it is not enforced. The leaking routes and the ACLs
+// are configured via VPP CLI, since the vendored VPP agent does not support routes resolved in another VRF.
+//
+// Egress gateways
+//
+// The external traffic of selected PODs can leave the cluster via a gateway node, source-NATed to a stable
+// egress IP allocated from Egress.IPPool (e.g. for partners whitelisting the traffic by the source IP).
+// The egress policies (egress.EgressPolicy) are stored in ETCD under the KSR prefix in
+// "egressPolicies/<namespace>/<name>" and select the PODs of one namespace by labels (PODs of tenants are
+// never selected). Each node running with the egress pool configured announces itself as a live gateway
+// in "egressGateways/<node>" with a TTL (Egress.GatewayTTL), the policy is served by the first live node
+// of its GatewayNodes. The egress IP (the requested one, or the first free address of the pool) is claimed
+// in "egressIPs/<address>" by the gateway node and preserved when the policy fails over to another gateway.
+// The traffic of the selected PODs towards the destinations outside of the cluster subnets is matched
+// by an ACL of an ACL-based forwarding (ABF) policy attached to the POD interfaces and forwarded via
+// the VXLAN overlay to the gateway node. On the gateway node the ABF policy is attached also to the VXLAN
+// BVI and steers the traffic into a hairpin loopback in the VRF of the policy (Egress.FirstVrfID + policy
+// index), where NAT44 translates it to the egress IP bound to that VRF and routes it out via the default VRF.
+// The egress IP must be routed to the gateway nodes by the external network. Only the VXLAN overlay is
+// supported; the whole configuration is applied via VPP CLI, since the vendored VPP agent does not support
+// ABF nor the NAT44 addresses bound to a VRF.
+//
+// Uplinks
+//
+// A node with several uplinks (OneNodeConfig.Uplinks, e.g. two NICs towards different ToR switches) has its node IP
+// configured on a loopback instead of the main VPP interface, each uplink has its own IP address and gateway.
+// The traffic leaving the node, including the tunnels of the overlay towards the other nodes, is balanced over
+// the uplinks by ECMP default routes, one via the gateway of each uplink. VPP itself ignores the paths via
+// the interfaces with the link down; the failures not visible on the link (e.g. of the gateway) are detected
+// by BFD sessions with the gateways (UplinkBFD). The states of the sessions are polled over the binary API
+// and the default route via the uplink with the session down is withdrawn (and removed from the persisted
+// configuration) until the session is up again; the route via the last live uplink is never withdrawn.
+// The fabric must route the node IP to the node via all its uplinks. Uplinks are supported with the VXLAN
+// and the IP-in-IP overlays only.
+//
+// Node configuration in ETCD
+//
+// The configuration of the node (nodeconfig.NodeConfig) may be stored in ETCD under the KSR prefix
+// in "nodeConfig/<node>", where it takes precedence over the configuration of the node from the configuration
+// file. Changes of the default gateway, of the other VPP interfaces and of NatExternalTraffic are applied
+// at runtime (the service plugin is notified via WatchNodeConfig to reconfigure the SNAT), the changes
+// of the main VPP interface, of the stolen interface and of the uplinks are applied after restart of the agent.
+// Once the key is removed, the configuration from the configuration file is applied again.
+//
+// Node decommissioning
+//
+// KSR releases the ID and the IPAM data of a node removed from the k8s cluster (see cmd/contiv-ksr/README.md).
+// Once the ID of another node is removed from ETCD, the tunnel and the routes towards the node are removed
+// (also during the resync, if the removal was not watched). If the ID of this node is released while the agent
+// is running (e.g. the node object was deleted while the node was only temporarily unreachable), the agent claims
+// the ID back, unless it was allocated to another node in the meantime, which requires restart of the agent.
+//
+//
 // Plugin Structure
 // ================
 //
@@ -157,5 +354,26 @@
 //		5. Helper functions:
 //			- host.go: provides host-related helper functions and VPP-Agent NB API builders
 //			- pod.go: provides POD-related helper functions and VPP-Agent NB API builders
+//			- pod_memif.go: provides helper functions for the PODs connected via memif
+//			- pod_networks.go: provides helper functions for the POD interfaces in the secondary networks
+//			- pod_bandwidth.go: applies the bandwidth limits requested by the POD annotations
+//			- pod_annotations.go: learns the static IPs, secondary networks and interface types requested
+//			  by the POD annotations and releases the sticky IPs of deleted PODs
+//			- vpp_policers.go: configures VPP policers limiting the bandwidth of PODs
+//			- overlay.go: node overlay interface with the VXLAN and L2 implementations
+//			- overlay_ipip.go: node overlay with routed IP-in-IP tunnels
+//			- vpp_cli.go: executes VPP CLI commands for the features missing in the VPP binary API
+//			- ipsec.go: distributes the IPsec keys and applies them to the other nodes
+//			- vpp_ipsec.go: configures VPP IPsec protecting the VXLAN traffic between the nodes
+//			- stale_pods.go: removes the wiring of PODs deleted without the CNI Delete request
+//			- tenants.go: connects the PODs of the tenant namespaces into the VRFs of the tenants
+//			- egress.go: selects the PODs and the gateway nodes of the egress policies
+//			- egress_store.go: persists the egress IPs and announces the gateway nodes in ETCD
+//			- vpp_egress.go: configures VPP ABF and NAT44 steering the egress traffic via the gateway nodes
+//			- uplinks.go: balances the traffic leaving the node over several uplinks, withdraws the failed ones
+//			- node_config.go: applies the changes of the node configuration stored in ETCD at runtime
+//			- txn_batcher.go: merges the transactions of the PODs wired concurrently
+//			- cni_metrics.go: exports the metrics of the CNI requests to Prometheus, keeps the log of the recent requests
+//			- query.go: read-only gRPC and REST API returning the runtime state of the plugin
 //
 package contiv
@@ -0,0 +1,520 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contiv
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sort"
+
+	"github.com/contiv/vpp/plugins/contiv/model/egress"
+	"github.com/contiv/vpp/plugins/contiv/model/node"
+	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
+)
+
+const (
+	// defaultEgressGatewayTTL is the default time in seconds after which a gateway node which stopped
+	// refreshing its announcement is considered dead
+	defaultEgressGatewayTTL = 10
+
+	// defaultEgressFirstVrfID is the default first VRF used for the source NAT of the egress policies
+	defaultEgressFirstVrfID = 1000
+
+	// maxEgressIPClaimAttempts is the maximum number of attempts to claim an egress IP (other nodes may claim
+	// the same address concurrently)
+	maxEgressIPClaimAttempts = 10
+)
+
+// EgressConfig is the configuration of the egress gateways.
+type EgressConfig struct {
+	IPPool     string // CIDR of the egress IP addresses, the egress policies are applied only if set
+	GatewayTTL uint32 // seconds after which a gateway node not refreshing its announcement is considered dead
+	FirstVrfID uint32 // first VRF used for the source NAT of the egress policies on the gateway nodes
+}
+
+// egressIPStore is a cluster-wide persistent storage of the egress IPs assigned to the egress policies.
+type egressIPStore interface {
+	// ListEgressIPs returns all assigned egress IPs.
+	ListEgressIPs() ([]*egress.EgressIP, error)
+
+	// ClaimEgressIP atomically assigns the address to the policy. Returns false if the address is already assigned.
+	ClaimEgressIP(ip *egress.EgressIP) (succeeded bool, err error)
+
+	// ReleaseEgressIP returns the address back to the pool.
+	ReleaseEgressIP(address string) error
+}
+
+// initEgress validates the configuration of the egress gateways.
+func (s *remoteCNIserver) initEgress() error {
+	if s.config.Egress.IPPool == "" {
+		return nil
+	}
+	_, pool, err := net.ParseCIDR(s.config.Egress.IPPool)
+	if err != nil || pool.IP.To4() == nil {
+		return fmt.Errorf("invalid egress IP pool %q", s.config.Egress.IPPool)
+	}
+	if _, isVxlan := s.overlay.(*vxlanOverlay); !isVxlan {
+		return fmt.Errorf("egress gateways are supported only with the VXLAN overlay")
+	}
+	firstVrfID := s.config.Egress.FirstVrfID
+	if firstVrfID == 0 {
+		firstVrfID = defaultEgressFirstVrfID
+	}
+	for _, network := range s.secondaryNetworks {
+		if network.VrfID >= firstVrfID {
+			return fmt.Errorf("VRF %v of the secondary network %v collides with the VRFs of the egress policies",
+				network.VrfID, network.Name)
+		}
+	}
+	for _, tenant := range s.tenants {
+		if tenant.VrfID >= firstVrfID {
+			return fmt.Errorf("VRF %v of the tenant %v collides with the VRFs of the egress policies",
+				tenant.VrfID, tenant.Name)
+		}
+	}
+	s.egress = newVppEgress(s.Logger, s.cli, firstVrfID)
+	s.egressPool = pool
+	s.egressPolicies = map[string]*egress.EgressPolicy{}
+	s.egressGateways = map[string]struct{}{}
+	s.egressIPs = map[string]string{}
+	return nil
+}
+
+// egressPolicyName returns the name (namespace/name) under which the egress policy is stored.
+func egressPolicyName(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+// updateEgressPolicy is called when an egress policy is created or updated.
+// The method must be called with the CNI server lock held.
+func (s *remoteCNIserver) updateEgressPolicy(policy *egress.EgressPolicy) error {
+	if s.egress == nil {
+		return nil
+	}
+	name := egressPolicyName(policy.Namespace, policy.Name)
+	if err := s.validateEgressPolicy(policy); err != nil {
+		s.Logger.Warnf("Egress policy %s is not applied: %v", name, err)
+		return s.deleteEgressPolicy(policy.Namespace, policy.Name)
+	}
+	if previous, exists := s.egressPolicies[name]; exists && previous.EgressIp != policy.EgressIp {
+		// the requested address has changed, the assigned one must be released
+		if err := s.releaseEgressIP(name); err != nil {
+			return err
+		}
+	}
+	s.egressPolicies[name] = policy
+	return s.renderEgressPolicy(name)
+}
+
+// deleteEgressPolicy is called when an egress policy is deleted.
+// The method must be called with the CNI server lock held.
+func (s *remoteCNIserver) deleteEgressPolicy(namespace, name string) error {
+	if s.egress == nil {
+		return nil
+	}
+	policyName := egressPolicyName(namespace, name)
+	delete(s.egressPolicies, policyName)
+	if err := s.renderEgressPolicy(policyName); err != nil {
+		return err
+	}
+	return s.releaseEgressIP(policyName)
+}
+
+// resyncEgressPolicies replaces all egress policies. The egress IPs assigned to the policies which no longer
+// exist are released.
+// The method must be called with the CNI server lock held.
+func (s *remoteCNIserver) resyncEgressPolicies(policies []*egress.EgressPolicy) error {
+	if s.egress == nil {
+		return nil
+	}
+	oldPolicies := s.egressPolicies
+	s.egressPolicies = map[string]*egress.EgressPolicy{}
+	for _, policy := range policies {
+		name := egressPolicyName(policy.Namespace, policy.Name)
+		if err := s.validateEgressPolicy(policy); err != nil {
+			s.Logger.Warnf("Egress policy %s is not applied: %v", name, err)
+			continue
+		}
+		s.egressPolicies[name] = policy
+	}
+	for name := range oldPolicies {
+		if _, exists := s.egressPolicies[name]; !exists {
+			delete(s.egressIPs, name)
+		}
+	}
+
+	var wasErr error
+	if claims, err := s.egressStore.ListEgressIPs(); err == nil {
+		for _, claim := range claims {
+			if _, exists := s.egressPolicies[claim.Policy]; !exists {
+				s.Logger.Infof("Releasing egress IP %s of the removed policy %s", claim.Address, claim.Policy)
+				if err := s.egressStore.ReleaseEgressIP(claim.Address); err != nil {
+					wasErr = err
+				}
+			}
+		}
+	} else {
+		wasErr = err
+	}
+	if err := s.renderEgress(); err != nil {
+		wasErr = err
+	}
+	return wasErr
+}
+
+// validateEgressPolicy checks that the egress policy can be applied.
+func (s *remoteCNIserver) validateEgressPolicy(policy *egress.EgressPolicy) error {
+	if policy.Namespace == "" || policy.Name == "" {
+		return fmt.Errorf("namespace and name of the policy must be set")
+	}
+	if len(policy.GatewayNodes) == 0 {
+		return fmt.Errorf("no gateway node")
+	}
+	if policy.EgressIp != "" {
+		ip := net.ParseIP(policy.EgressIp)
+		if ip == nil || !s.egressPool.Contains(ip) {
+			return fmt.Errorf("egress IP %s is not from the pool %v", policy.EgressIp, s.egressPool)
+		}
+	}
+	return nil
+}
+
+// updateEgressGateway is called when a gateway node announces itself.
+// The method must be called with the CNI server lock held.
+func (s *remoteCNIserver) updateEgressGateway(nodeName string) error {
+	if s.egress == nil {
+		return nil
+	}
+	if _, live := s.egressGateways[nodeName]; live {
+		return nil
+	}
+	s.Logger.Infof("Egress gateway %s is live", nodeName)
+	s.egressGateways[nodeName] = struct{}{}
+	return s.renderEgress()
+}
+
+// deleteEgressGateway is called when the announcement of a gateway node expires, i.e. the node is dead
+// (or was stopped). The policies fail over to the next live gateway node.
+// The method must be called with the CNI server lock held.
+func (s *remoteCNIserver) deleteEgressGateway(nodeName string) error {
+	if s.egress == nil {
+		return nil
+	}
+	if _, live := s.egressGateways[nodeName]; !live {
+		return nil
+	}
+	s.Logger.Warnf("Egress gateway %s is not live anymore", nodeName)
+	delete(s.egressGateways, nodeName)
+	return s.renderEgress()
+}
+
+// resyncEgressGateways replaces the set of live gateway nodes.
+// The method must be called with the CNI server lock held.
+func (s *remoteCNIserver) resyncEgressGateways(nodeNames []string) error {
+	if s.egress == nil {
+		return nil
+	}
+	s.egressGateways = map[string]struct{}{}
+	for _, nodeName := range nodeNames {
+		s.egressGateways[nodeName] = struct{}{}
+	}
+	return s.renderEgress()
+}
+
+// updateEgressPod is called when a POD is created or updated in ETCD by KSR.
+func (s *remoteCNIserver) updateEgressPod(pod *podmodel.Pod) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.egress == nil || s.egressPods == nil {
+		return nil
+	}
+	id := podmodel.GetID(pod)
+	previous := s.egressPods[id]
+	s.egressPods[id] = pod
+	var wasErr error
+	for name, policy := range s.egressPolicies {
+		if s.egressPolicySelects(policy, previous) || s.egressPolicySelects(policy, pod) {
+			if err := s.renderEgressPolicy(name); err != nil {
+				wasErr = err
+			}
+		}
+	}
+	return wasErr
+}
+
+// deleteEgressPod is called when a POD is deleted from ETCD by KSR.
+func (s *remoteCNIserver) deleteEgressPod(id podmodel.ID) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.egress == nil || s.egressPods == nil {
+		return nil
+	}
+	previous, exists := s.egressPods[id]
+	if !exists {
+		return nil
+	}
+	delete(s.egressPods, id)
+	var wasErr error
+	for name, policy := range s.egressPolicies {
+		if s.egressPolicySelects(policy, previous) {
+			if err := s.renderEgressPolicy(name); err != nil {
+				wasErr = err
+			}
+		}
+	}
+	return wasErr
+}
+
+// resyncEgressPods replaces the set of PODs existing in the cluster.
+func (s *remoteCNIserver) resyncEgressPods(pods []*podmodel.Pod) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.egress == nil {
+		return nil
+	}
+	s.egressPods = map[podmodel.ID]*podmodel.Pod{}
+	for _, pod := range pods {
+		s.egressPods[podmodel.GetID(pod)] = pod
+	}
+	return s.renderEgress()
+}
+
+// egressPolicySelects returns true if the policy selects the POD (with an IP address in the pod network).
+func (s *remoteCNIserver) egressPolicySelects(policy *egress.EgressPolicy, pod *podmodel.Pod) bool {
+	if pod == nil || pod.Namespace != policy.Namespace || net.ParseIP(pod.IpAddress) == nil {
+		return false
+	}
+	if s.namespaceTenants[pod.Namespace] != "" {
+		// tenants are isolated in their own VRFs
+		return false
+	}
+	for _, selector := range policy.PodSelector {
+		selected := false
+		for _, label := range pod.Label {
+			if label.Key == selector.Key && label.Value == selector.Value {
+				selected = true
+				break
+			}
+		}
+		if !selected {
+			return false
+		}
+	}
+	return true
+}
+
+// renderEgress applies all egress policies and removes the policies which no longer exist.
+// The method must be called with the CNI server lock held.
+func (s *remoteCNIserver) renderEgress() error {
+	if s.egress == nil {
+		return nil
+	}
+	names := map[string]struct{}{}
+	for name := range s.egressPolicies {
+		names[name] = struct{}{}
+	}
+	for name := range s.egress.rules {
+		names[name] = struct{}{}
+	}
+	var wasErr error
+	for name := range names {
+		if err := s.renderEgressPolicy(name); err != nil {
+			wasErr = err
+		}
+	}
+	return wasErr
+}
+
+// renderEgressPolicy applies the egress policy with the given name (namespace/name) on this node,
+// or removes it if the policy does not exist.
+// The method must be called with the CNI server lock held.
+func (s *remoteCNIserver) renderEgressPolicy(name string) error {
+	if s.egress == nil || !s.vswitchConnectivityConfigured || s.egressPods == nil {
+		// applied once the vswitch is configured and the PODs are resynced
+		return nil
+	}
+	rule, err := s.egressRule(name)
+	if err != nil {
+		s.Logger.Warnf("Egress policy %s is not applied: %v", name, err)
+		rule = nil
+	}
+	err = s.egress.update(name, rule, s.ipam.ClusterSubnets())
+	if err != nil {
+		return fmt.Errorf("can't apply egress policy %s: %v", name, err)
+	}
+	return nil
+}
+
+// egressRule builds the egress rule of the policy on this node. Returns nil if the policy does not exist
+// or none of its gateway nodes is live.
+func (s *remoteCNIserver) egressRule(name string) (*egressRule, error) {
+	policy, exists := s.egressPolicies[name]
+	if !exists {
+		return nil, nil
+	}
+	isGateway, gateway := s.activeEgressGateway(policy)
+	if !isGateway && gateway == nil {
+		s.Logger.Warnf("No gateway node of the egress policy %s is live, the traffic is not redirected", name)
+		return nil, nil
+	}
+
+	rule := &egressRule{}
+	for _, pod := range s.egressPods {
+		if !s.egressPolicySelects(policy, pod) {
+			continue
+		}
+		local := false
+		for _, containerID := range s.configuredContainers.LookupPodName(pod.Name) {
+			config, found := s.configuredContainers.LookupContainer(containerID)
+			if found && config.PodNamespace == pod.Namespace && config.Tenant == "" && config.VppIfName != "" {
+				rule.ifNames = append(rule.ifNames, config.VppIfName)
+				local = true
+			}
+		}
+		if local || isGateway {
+			rule.srcIPs = append(rule.srcIPs, pod.IpAddress)
+		}
+	}
+	if len(rule.srcIPs) == 0 {
+		return nil, nil
+	}
+	sort.Strings(rule.srcIPs)
+	sort.Strings(rule.ifNames)
+
+	if isGateway {
+		// the traffic of the PODs on the other nodes is received from the overlay
+		rule.ifNames = append(rule.ifNames, s.overlay.bviIfName())
+		egressIP, err := s.assignEgressIP(name, policy)
+		if err != nil {
+			return nil, err
+		}
+		rule.egressIP = egressIP
+	} else {
+		nextHop, err := s.overlay.nextHop(gateway)
+		if err != nil {
+			return nil, err
+		}
+		rule.nextHop = nextHop
+		rule.viaIfName = s.overlay.bviIfName()
+	}
+	return rule, nil
+}
+
+// activeEgressGateway returns the first live gateway node of the policy. Returns true if this node
+// is the active gateway, the info of the other node otherwise (nil if no gateway node is live).
+func (s *remoteCNIserver) activeEgressGateway(policy *egress.EgressPolicy) (isGateway bool, gateway *node.NodeInfo) {
+	for _, nodeName := range policy.GatewayNodes {
+		if _, live := s.egressGateways[nodeName]; !live {
+			continue
+		}
+		if nodeName == s.agentLabel {
+			return true, nil
+		}
+		for _, nodeInfo := range s.otherNodes {
+			if nodeInfo.Name == nodeName {
+				return false, nodeInfo
+			}
+		}
+	}
+	return false, nil
+}
+
+// assignEgressIP returns the egress IP of the policy this node is the gateway for. The address already
+// assigned to the policy (e.g. by the previous gateway) is preserved, otherwise the requested address
+// or the first free address of the pool is claimed.
+func (s *remoteCNIserver) assignEgressIP(name string, policy *egress.EgressPolicy) (string, error) {
+	if address, assigned := s.egressIPs[name]; assigned {
+		return address, nil
+	}
+	for attempt := 0; attempt < maxEgressIPClaimAttempts; attempt++ {
+		claims, err := s.egressStore.ListEgressIPs()
+		if err != nil {
+			return "", err
+		}
+		used := map[string]string{}
+		for _, claim := range claims {
+			if claim.Policy == name && (policy.EgressIp == "" || claim.Address == policy.EgressIp) {
+				s.egressIPs[name] = claim.Address
+				return claim.Address, nil
+			}
+			used[claim.Address] = claim.Policy
+		}
+
+		address := policy.EgressIp
+		if address != "" {
+			if owner, isUsed := used[address]; isUsed {
+				return "", fmt.Errorf("egress IP %s is already assigned to the policy %s", address, owner)
+			}
+		} else {
+			address = s.freeEgressIP(used)
+			if address == "" {
+				return "", fmt.Errorf("no free address in the egress IP pool %v", s.egressPool)
+			}
+		}
+		succeeded, err := s.egressStore.ClaimEgressIP(&egress.EgressIP{Address: address, Policy: name})
+		if err != nil {
+			return "", err
+		}
+		if succeeded {
+			s.Logger.Infof("Egress IP %s assigned to the policy %s", address, name)
+			s.egressIPs[name] = address
+			return address, nil
+		}
+	}
+	return "", fmt.Errorf("can't assign egress IP to the policy %s, the pool is changing concurrently", name)
+}
+
+// freeEgressIP returns the first address of the pool which is not used. The network and the broadcast
+// addresses are skipped unless the pool is a /31 or /32 prefix.
+func (s *remoteCNIserver) freeEgressIP(used map[string]string) string {
+	ones, bits := s.egressPool.Mask.Size()
+	first := binary.BigEndian.Uint32(s.egressPool.IP.To4())
+	begin, end := uint64(0), uint64(1)<<uint(bits-ones)
+	if bits-ones > 1 {
+		begin, end = begin+1, end-1
+	}
+	for i := begin; i < end; i++ {
+		ip := make(net.IP, net.IPv4len)
+		binary.BigEndian.PutUint32(ip, first+uint32(i))
+		if _, isUsed := used[ip.String()]; !isUsed {
+			return ip.String()
+		}
+	}
+	return ""
+}
+
+// releaseEgressIP releases the egress IP assigned to the policy by this node.
+func (s *remoteCNIserver) releaseEgressIP(name string) error {
+	address, assigned := s.egressIPs[name]
+	if !assigned {
+		return nil
+	}
+	delete(s.egressIPs, name)
+	s.Logger.Infof("Egress IP %s of the policy %s released", address, name)
+	return s.egressStore.ReleaseEgressIP(address)
+}
+
+// detachPodEgress detaches the egress policies from the interface of the POD being removed.
+// The method must be called with the CNI server lock held.
+func (s *remoteCNIserver) detachPodEgress(vppIfName string) error {
+	if s.egress == nil || vppIfName == "" {
+		return nil
+	}
+	return s.egress.detachInterface(vppIfName)
+}
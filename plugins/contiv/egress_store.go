@@ -0,0 +1,107 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contiv
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/contiv/vpp/flavors/ksr"
+	"github.com/contiv/vpp/plugins/contiv/model/egress"
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/db/keyval"
+	"github.com/ligato/cn-infra/db/keyval/etcdv3"
+	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/cn-infra/servicelabel"
+)
+
+// egressStore persists the egress IPs assigned to the egress policies in ETCD and announces the liveness
+// of this node as an egress gateway. Both are stored under the same (cluster-wide) prefix as the allocated
+// node IDs, so that every node can watch them.
+type egressStore struct {
+	etcd   *etcdv3.Plugin
+	broker keyval.ProtoBroker
+}
+
+// newEgressStore creates new instance of egressStore.
+func newEgressStore(etcd *etcdv3.Plugin) *egressStore {
+	return &egressStore{
+		etcd:   etcd,
+		broker: etcd.NewBroker(servicelabel.GetDifferentAgentPrefix(ksr.MicroserviceLabel)),
+	}
+}
+
+// ListEgressIPs returns all egress IPs assigned in the cluster.
+func (es *egressStore) ListEgressIPs() ([]*egress.EgressIP, error) {
+	it, err := es.broker.ListValues(egress.IPKeyPrefix())
+	if err != nil {
+		return nil, err
+	}
+
+	var ips []*egress.EgressIP
+	for {
+		kv, stop := it.GetNext()
+		if stop {
+			break
+		}
+		ip := &egress.EgressIP{}
+		err = kv.GetValue(ip)
+		if err != nil {
+			return nil, err
+		}
+		ips = append(ips, ip)
+	}
+	return ips, nil
+}
+
+// ClaimEgressIP atomically assigns the address to the policy. Returns false if the address is already assigned.
+func (es *egressStore) ClaimEgressIP(ip *egress.EgressIP) (succeeded bool, err error) {
+	encoded, err := json.Marshal(ip)
+	if err != nil {
+		return false, err
+	}
+	return es.etcd.PutIfNotExists(servicelabel.GetDifferentAgentPrefix(ksr.MicroserviceLabel)+
+		egress.IPKey(ip.Address), encoded)
+}
+
+// ReleaseEgressIP returns the address back to the pool.
+func (es *egressStore) ReleaseEgressIP(address string) error {
+	_, err := es.broker.Delete(egress.IPKey(address))
+	return err
+}
+
+// announceGateway periodically refreshes the announcement of this node as a live egress gateway.
+// The announcement expires after the TTL unless refreshed, the announcement is removed when the context
+// is cancelled (i.e. the agent is stopped), so that the policies fail over immediately.
+func (es *egressStore) announceGateway(ctx context.Context, logger logging.Logger, nodeName string, ttl time.Duration) {
+	key := egress.GatewayKey(nodeName)
+	ticker := time.NewTicker(ttl / 3)
+	defer ticker.Stop()
+	for {
+		err := es.broker.Put(key, &egress.Gateway{NodeName: nodeName}, datasync.WithTTL(ttl))
+		if err != nil {
+			logger.Warnf("Can't announce the egress gateway: %v", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			if _, err := es.broker.Delete(key); err != nil {
+				logger.Warnf("Can't withdraw the egress gateway: %v", err)
+			}
+			return
+		}
+	}
+}
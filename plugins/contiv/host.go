@@ -60,6 +60,14 @@ func (s *remoteCNIserver) routePODsFromHost(nextHopIP string) *linux_l3.LinuxSta
 	return route
 }
 
+// routePODsFromHostIPv6 returns the route from the host to the IPv6 pod subnet.
+func (s *remoteCNIserver) routePODsFromHostIPv6(nextHopIP string) *linux_l3.LinuxStaticRoutes_Route {
+	route := s.routePODsFromHost(nextHopIP)
+	route.Name = "pods-to-vpp-ipv6"
+	route.DstIpAddr = s.ipam.PodSubnetIPv6().String()
+	return route
+}
+
 func (s *remoteCNIserver) routeServicesFromHost(nextHopIP string) *linux_l3.LinuxStaticRoutes_Route {
 	route := &linux_l3.LinuxStaticRoutes_Route{
 		Name:        "service-to-vpp",
@@ -81,7 +89,7 @@ func (s *remoteCNIserver) routeServicesFromHost(nextHopIP string) *linux_l3.Linu
 
 func (s *remoteCNIserver) defaultRoute(gwIP string, outIfName string) *vpp_l3.StaticRoutes_Route {
 	route := &vpp_l3.StaticRoutes_Route{
-		DstIpAddr:         "0.0.0.0/0",
+		DstIpAddr:         ipv4DefaultRoute,
 		NextHopAddr:       gwIP,
 		OutgoingInterface: outIfName,
 	}
@@ -110,7 +118,6 @@ func (s *remoteCNIserver) routesToHost(nextHopIP string) []*vpp_l3.StaticRoutes_
 }
 
 func (s *remoteCNIserver) interconnectTap() *vpp_intf.Interfaces_Interface {
-	size, _ := s.ipam.VPPHostNetwork().Mask.Size()
 	tap := &vpp_intf.Interfaces_Interface{
 		Name:    TapVPPEndLogicalName,
 		Type:    vpp_intf.InterfaceType_TAP_INTERFACE,
@@ -119,7 +126,7 @@ func (s *remoteCNIserver) interconnectTap() *vpp_intf.Interfaces_Interface {
 		Tap: &vpp_intf.Interfaces_Interface_Tap{
 			HostIfName: TapHostEndName,
 		},
-		IpAddresses: []string{s.ipam.VEthVPPEndIP().String() + "/" + strconv.Itoa(size)},
+		IpAddresses: s.hostInterconnectIPs(s.ipam.VEthVPPEndIP(), s.ipam.VEthVPPEndIPv6()),
 		PhysAddress: HostInterconnectMAC,
 	}
 	if s.tapVersion == 2 {
@@ -132,19 +139,17 @@ func (s *remoteCNIserver) interconnectTap() *vpp_intf.Interfaces_Interface {
 }
 
 func (s *remoteCNIserver) interconnectTapHost() *linux_intf.LinuxInterfaces_Interface {
-	size, _ := s.ipam.VPPHostNetwork().Mask.Size()
 	return &linux_intf.LinuxInterfaces_Interface{
 		Name:        TapHostEndLogicalName,
 		Mtu:         s.config.MTUSize,
 		HostIfName:  TapHostEndName,
 		Type:        linux_intf.LinuxInterfaces_AUTO_TAP,
 		Enabled:     true,
-		IpAddresses: []string{s.ipam.VEthHostEndIP().String() + "/" + strconv.Itoa(size)},
+		IpAddresses: s.hostInterconnectIPs(s.ipam.VEthHostEndIP(), s.ipam.VEthHostEndIPv6()),
 	}
 }
 
 func (s *remoteCNIserver) interconnectVethHost() *linux_intf.LinuxInterfaces_Interface {
-	size, _ := s.ipam.VPPHostNetwork().Mask.Size()
 	return &linux_intf.LinuxInterfaces_Interface{
 		Name:       vethHostEndLogicalName,
 		Type:       linux_intf.LinuxInterfaces_VETH,
@@ -154,7 +159,7 @@ func (s *remoteCNIserver) interconnectVethHost() *linux_intf.LinuxInterfaces_Int
 		Veth: &linux_intf.LinuxInterfaces_Interface_Veth{
 			PeerIfName: vethVPPEndLogicalName,
 		},
-		IpAddresses: []string{s.ipam.VEthHostEndIP().String() + "/" + strconv.Itoa(size)},
+		IpAddresses: s.hostInterconnectIPs(s.ipam.VEthHostEndIP(), s.ipam.VEthHostEndIPv6()),
 	}
 }
 
@@ -176,7 +181,6 @@ func (s *remoteCNIserver) interconnectAfpacketName() string {
 }
 
 func (s *remoteCNIserver) interconnectAfpacket() *vpp_intf.Interfaces_Interface {
-	size, _ := s.ipam.VPPHostNetwork().Mask.Size()
 	return &vpp_intf.Interfaces_Interface{
 		Name:    s.interconnectAfpacketName(),
 		Type:    vpp_intf.InterfaceType_AF_PACKET_INTERFACE,
@@ -185,8 +189,20 @@ func (s *remoteCNIserver) interconnectAfpacket() *vpp_intf.Interfaces_Interface
 		Afpacket: &vpp_intf.Interfaces_Interface_Afpacket{
 			HostIfName: vethVPPEndName,
 		},
-		IpAddresses: []string{s.ipam.VEthVPPEndIP().String() + "/" + strconv.Itoa(size)},
+		IpAddresses: s.hostInterconnectIPs(s.ipam.VEthVPPEndIP(), s.ipam.VEthVPPEndIPv6()),
+	}
+}
+
+// hostInterconnectIPs returns addresses (with the VPP-host network prefix length) for one end
+// of the VPP-host interconnect. The IPv6 address is included only if IPv6 is enabled.
+func (s *remoteCNIserver) hostInterconnectIPs(ip net.IP, ipv6 net.IP) []string {
+	size, _ := s.ipam.VPPHostNetwork().Mask.Size()
+	ips := []string{ip.String() + "/" + strconv.Itoa(size)}
+	if s.ipam.IPv6Enabled() {
+		size, _ = s.ipam.VPPHostNetworkIPv6().Mask.Size()
+		ips = append(ips, ipv6.String()+"/"+strconv.Itoa(size))
 	}
+	return ips
 }
 
 func (s *remoteCNIserver) physicalInterface(name string, ipAddress string) *vpp_intf.Interfaces_Interface {
@@ -213,17 +229,29 @@ func (s *remoteCNIserver) vxlanBVILoopback() (*vpp_intf.Interfaces_Interface, er
 	if err != nil {
 		return nil, err
 	}
+	ipAddresses := []string{vxlanIP.String()}
+	if s.ipam.IPv6Enabled() {
+		vxlanIPv6, err := s.ipam.VxlanIPv6WithPrefix(s.ipam.NodeID())
+		if err != nil {
+			return nil, err
+		}
+		ipAddresses = append(ipAddresses, vxlanIPv6.String())
+	}
 	return &vpp_intf.Interfaces_Interface{
 		Name:        vxlanBVIInterfaceName,
 		Type:        vpp_intf.InterfaceType_SOFTWARE_LOOPBACK,
 		Enabled:     true,
-		IpAddresses: []string{vxlanIP.String()},
+		IpAddresses: ipAddresses,
 		PhysAddress: s.hwAddrForVXLAN(s.ipam.NodeID()),
 	}, nil
 }
 
-func (s *remoteCNIserver) hwAddrForVXLAN(nodeID uint8) string {
-	return fmt.Sprintf("1a:2b:3c:4d:5e:%02x", nodeID)
+// hwAddrForVXLAN returns MAC address of the VXLAN BVI of the given node.
+// The node ID is XOR-ed into the 1a:2b:3c:4d:5e:00 base address, which keeps
+// addresses of nodes with ID < 256 unchanged.
+func (s *remoteCNIserver) hwAddrForVXLAN(nodeID uint32) string {
+	return fmt.Sprintf("1a:2b:%02x:%02x:%02x:%02x",
+		0x3c^byte(nodeID>>24), 0x4d^byte(nodeID>>16), 0x5e^byte(nodeID>>8), byte(nodeID))
 }
 
 func (s *remoteCNIserver) vxlanBridgeDomain(bviInterface string) *vpp_l2.BridgeDomains_BridgeDomain {
@@ -243,7 +271,7 @@ func (s *remoteCNIserver) vxlanBridgeDomain(bviInterface string) *vpp_l2.BridgeD
 	}
 }
 
-func (s *remoteCNIserver) vxlanArpEntry(nodeID uint8, hostIP string) *vpp_l3.ArpTable_ArpTableEntry {
+func (s *remoteCNIserver) vxlanArpEntry(nodeID uint32, hostIP string) *vpp_l3.ArpTable_ArpTableEntry {
 	return &vpp_l3.ArpTable_ArpTableEntry{
 		Interface:   vxlanBVIInterfaceName,
 		IpAddress:   hostIP,
@@ -252,8 +280,8 @@ func (s *remoteCNIserver) vxlanArpEntry(nodeID uint8, hostIP string) *vpp_l3.Arp
 	}
 }
 
-func (s *remoteCNIserver) computeRoutesToHost(hostID uint8, nextHopIP string) (podsRoute *vpp_l3.StaticRoutes_Route, hostRoute *vpp_l3.StaticRoutes_Route, err error) {
-	podsRoute, err = s.routeToOtherHostPods(hostID, nextHopIP)
+func (s *remoteCNIserver) computeRoutesToHost(hostID uint32, nextHopIP string) (podsRoutes []*vpp_l3.StaticRoutes_Route, hostRoute *vpp_l3.StaticRoutes_Route, err error) {
+	podsRoutes, err = s.routeToOtherHostPods(hostID, nextHopIP)
 	if err != nil {
 		err = fmt.Errorf("Can't construct route to pods of host %v: %v ", hostID, err)
 		return
@@ -266,15 +294,48 @@ func (s *remoteCNIserver) computeRoutesToHost(hostID uint8, nextHopIP string) (p
 	return
 }
 
-func (s *remoteCNIserver) routeToOtherHostPods(hostID uint8, nextHopIP string) (*vpp_l3.StaticRoutes_Route, error) {
-	podNetwork, err := s.ipam.OtherNodePodNetwork(hostID)
+// computeIPv6RoutesToHost returns IPv6 routes to pods and to the vswitch network of the given host.
+// The next hop is provided by the node overlay (e.g. the IPv6 address of the other node's VXLAN BVI,
+// or of its main interface if L2 interconnect is used).
+func (s *remoteCNIserver) computeIPv6RoutesToHost(hostID uint32) (podsRoute *vpp_l3.StaticRoutes_Route, hostRoute *vpp_l3.StaticRoutes_Route, err error) {
+	nextHop, err := s.overlay.nextHopIPv6(hostID)
+	if err != nil {
+		err = fmt.Errorf("Can't compute IPv6 next hop for host ID %v, error: %v ", hostID, err)
+		return
+	}
+	podNetwork, err := s.ipam.OtherNodePodNetworkIPv6(hostID)
+	if err != nil {
+		err = fmt.Errorf("Can't compute IPv6 pod network for host ID %v, error: %v ", hostID, err)
+		return
+	}
+	hostNw, err := s.ipam.OtherNodeVPPHostNetworkIPv6(hostID)
+	if err != nil {
+		err = fmt.Errorf("Can't compute IPv6 vswitch network for host ID %v, error: %v ", hostID, err)
+		return
+	}
+	podsRoute, _ = s.routeToOtherHostNetworks(podNetwork, nextHop.String())
+	hostRoute, _ = s.routeToOtherHostNetworks(hostNw, nextHop.String())
+	return
+}
+
+// routeToOtherHostPods returns one route for every pod CIDR block owned by the given host.
+func (s *remoteCNIserver) routeToOtherHostPods(hostID uint32, nextHopIP string) ([]*vpp_l3.StaticRoutes_Route, error) {
+	podNetworks, err := s.ipam.OtherNodePodNetworks(hostID)
 	if err != nil {
 		return nil, fmt.Errorf("Can't compute pod network for host ID %v, error: %v ", hostID, err)
 	}
-	return s.routeToOtherHostNetworks(podNetwork, nextHopIP)
+	var routes []*vpp_l3.StaticRoutes_Route
+	for _, podNetwork := range podNetworks {
+		route, err := s.routeToOtherHostNetworks(podNetwork, nextHopIP)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
 }
 
-func (s *remoteCNIserver) routeToOtherHostStack(hostID uint8, nextHopIP string) (*vpp_l3.StaticRoutes_Route, error) {
+func (s *remoteCNIserver) routeToOtherHostStack(hostID uint32, nextHopIP string) (*vpp_l3.StaticRoutes_Route, error) {
 	hostNw, err := s.ipam.OtherNodeVPPHostNetwork(hostID)
 	if err != nil {
 		return nil, fmt.Errorf("Can't compute vswitch network for host ID %v, error: %v ", hostID, err)
@@ -296,7 +357,7 @@ func (s *remoteCNIserver) routeToOtherHostNetworks(destNetwork *net.IPNet, nextH
 	}, nil
 }
 
-func (s *remoteCNIserver) computeVxlanToHost(hostID uint8, hostIP string) (*vpp_intf.Interfaces_Interface, error) {
+func (s *remoteCNIserver) computeVxlanToHost(hostID uint32, hostIP string) (*vpp_intf.Interfaces_Interface, error) {
 	return &vpp_intf.Interfaces_Interface{
 		Name:    fmt.Sprintf("vxlan%d", hostID),
 		Type:    vpp_intf.InterfaceType_VXLAN_TUNNEL,
@@ -316,7 +377,28 @@ func (s *remoteCNIserver) addInterfaceToVxlanBD(bd *vpp_l2.BridgeDomains_BridgeD
 	})
 }
 
-func (s *remoteCNIserver) otherHostIP(hostID uint8, hostIPPrefix string) string {
+// removeInterfaceFromVxlanBD removes the interface from the VXLAN bridge domain.
+func removeInterfaceFromVxlanBD(bd *vpp_l2.BridgeDomains_BridgeDomain, ifName string) {
+	var bdIfs []*vpp_l2.BridgeDomains_BridgeDomain_Interfaces
+	for _, bdIf := range bd.Interfaces {
+		if bdIf.Name != ifName {
+			bdIfs = append(bdIfs, bdIf)
+		}
+	}
+	bd.Interfaces = bdIfs
+}
+
+// vxlanBDHasInterface returns true if the interface is in the VXLAN bridge domain.
+func vxlanBDHasInterface(bd *vpp_l2.BridgeDomains_BridgeDomain, ifName string) bool {
+	for _, bdIf := range bd.Interfaces {
+		if bdIf.Name == ifName {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *remoteCNIserver) otherHostIP(hostID uint32, hostIPPrefix string) string {
 	// determine next hop IP - either use provided one, or calculate based on hostIPPrefix
 	if hostIPPrefix != "" {
 		// hostIPPrefix defined, just trim prefix length
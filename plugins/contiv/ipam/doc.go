@@ -24,4 +24,36 @@
 //		Calculated POD IPs: 10.1.5.2 - 10.1.5.254 (/24)
 //		Calculated VPP-host interconnect IPs: 172.30.5.1, 172.30.5.2 (/24)
 //  	Calculated Node Interconnect IP:  192.168.16.5 (/24)
+//
+// Node ID is a 32-bit number. The number of nodes supported by a configuration is given by the number
+// of bits between the subnet and network prefix lengths (8 bits in the example above = 255 nodes).
+// To support more nodes, use a wider subnet, e.g. PodSubnetCIDR "10.0.0.0/8" with PodNetworkPrefixLen 24
+// for up to 65535 nodes. If the node ID does not fit, IPAM initialization fails (and addresses are not computed
+// for other nodes with such ID) rather than letting addresses of different nodes collide.
+//
+// Dual-stack POD addressing is enabled by configuring the IPv6 counterparts of the subnets
+// (PodSubnetIPv6CIDR, PodNetworkIPv6PrefixLen, ...). The node ID is then applied
+// to the IPv6 subnets the same way, e.g. with PodSubnetIPv6CIDR "fd00:1::/48",
+// PodNetworkIPv6PrefixLen 64 and node ID 5 the POD IPv6 network is fd00:1:0:5::/64.
+// Each POD is then assigned one address from each family (see NextPodIP and NextPodIPv6).
+// K8s learns only the IPv4 address, the Contiv plugin therefore reflects the IPv6 address of every POD
+// into ETCD (see pod.IPv6Key), from where the policy plugin merges it into the POD data.
+//
+// With DynamicPodCIDRBlocks enabled, the IPv4 POD networks are not derived from the node ID. Instead, blocks
+// with PodNetworkPrefixLen are claimed from PodSubnetCIDR on demand and persisted in ETCD (see PodCIDRBlockStore).
+// A node claims its first block during the initialization, an extra block whenever all its blocks are exhausted,
+// and returns an extra block back to the pool once the last POD IP of the block is released.
+// The first block of the node holds the POD gateway IP and is kept for the lifetime of the node.
+//
+// Apart from the pool allocation (NextPodIP), a specific IPv4 address may be assigned to a POD (AssignPodIP),
+// either requested explicitly or by the name of a reservation from PodIPReservations (reserved addresses
+// are never assigned from the pool). With StickyPodIPs enabled, the address last assigned to a POD name is
+// persisted (SetStickyPodIP), so that a re-created POD with the same name may get the same address back.
+// NextPodIP assigns the sticky addresses of PODs which are not connected only if no other address is free.
+// The record is dropped by ReleaseStickyPodIP once the POD is deleted for good.
+//
+// PODs may be also attached to the SecondaryNetworks, each with its own IPv4 address pool. The network
+// of a node is derived from the SubnetCIDR of the secondary network and the node ID the same way as the POD
+// network (e.g. SubnetCIDR "172.16.0.0/16", NetworkPrefixLen 24 and node ID 5 gives 172.16.5.0/24),
+// the first address is the gateway. See NextSecondaryPodIP and ReleaseSecondaryPodIP.
 package ipam
@@ -17,7 +17,9 @@ package ipam
 import (
 	"bytes"
 	"fmt"
+	"math/big"
 	"net"
+	"sort"
 	"sync"
 
 	"github.com/ligato/cn-infra/db/keyval"
@@ -29,6 +31,8 @@ const (
 	vethVPPEndIPSeqID  = 1              // sequence ID reserved for VPP-end of the VPP to host interconnect
 	vethHostEndIPSeqID = 2              // sequence ID reserved for host-end of the VPP to host interconnect
 	defaultServiceCIDR = "10.96.0.0/12" // default subnet allocated by service
+	maxPodSeqIDBits    = 32             // maximum number of bits of the sequence ID iterated over when looking for a free POD IP (relevant for IPv6)
+	maxNodeIDBits      = 32             // size of the node ID in bits
 )
 
 // IPAM represents the basic Contiv IPAM module.
@@ -36,15 +40,21 @@ type IPAM struct {
 	logger logging.Logger
 	mutex  sync.RWMutex
 
-	nodeID uint8              // identifier of the node for which this IPAM is created for
-	broker keyval.ProtoBroker // broker that is used for persisting
+	nodeID        uint32             // identifier of the node for which this IPAM is created for
+	broker        keyval.ProtoBroker // broker that is used for persisting
+	blockStore    PodCIDRBlockStore  // cluster-wide storage of pod CIDR blocks (nil if the blocks are derived from node ID)
+	podCIDRBlocks map[string]uint32  // owners of all pod CIDR blocks of the cluster keyed by the block network, cached from blockStore
 
 	// POD related variables
-	podSubnetIPPrefix   net.IPNet        // IPv4 subnet from which individual POD networks are allocated, this is subnet for all PODs across all nodes
-	podNetworkIPPrefix  net.IPNet        // IPv4 subnet prefix for all PODs on the node (given by nodeID), podSubnetIPPrefix + nodeID ==<computation>==> podNetworkIPPrefix
-	podNetworkGatewayIP net.IP           // gateway IP address for PODs on the node (given by nodeID)
-	podIfIPCIDR         net.IPNet        // IPv4 subnet from which individual VPP-side POD interfaces networks are allocated, this is subnet for all PODS within 1 node.
-	assignedPodIPs      map[uintIP]podID // pool of assigned POD IP addresses
+	podSubnetIPPrefix   net.IPNet                    // IPv4 subnet from which individual POD networks are allocated, this is subnet for all PODs across all nodes
+	podNetworkIPPrefix  net.IPNet                    // IPv4 subnet prefix for all PODs on the node (given by nodeID), podSubnetIPPrefix + nodeID ==<computation>==> podNetworkIPPrefix (the primary block if blocks are allocated dynamically)
+	podBlocks           []*podCIDRBlock              // IPv4 pod CIDR blocks owned by the node, the first one is podNetworkIPPrefix
+	podNetworkGatewayIP net.IP                       // gateway IP address for PODs on the node (given by nodeID)
+	podIfIPCIDR         net.IPNet                    // IPv4 subnet from which individual VPP-side POD interfaces networks are allocated, this is subnet for all PODS within 1 node.
+	assignedPodIPs      map[string]podID             // pool of assigned POD IPv4 addresses (keyed by the string form of the IP address)
+	reservedPodIPs      map[string]string            // POD IPv4 addresses reserved by name, never assigned by NextPodIP (keyed by the string form of the IP address)
+	stickyPodIPs        map[string]string            // IPv4 addresses last assigned to PODs keyed by POD name (namespace/name), nil if sticky IPs are disabled
+	secondaryNetworks   map[string]*secondaryNetwork // IPv4 address pools of the secondary POD networks keyed by the network name
 
 	// VSwitch related variables
 	vppHostSubnetIPPrefix  net.IPNet // IPv4 subnet used across all nodes for VPP to host Linux stack interconnect
@@ -58,12 +68,26 @@ type IPAM struct {
 	vxlanCIDR            net.IPNet // IPv4 subnet used for for inter-node VXLAN
 	serviceCIDR          net.IPNet // IPv4 subnet used to allocate ClusterIPs for a service
 
-	lastAssigned int // counter denoting last assigned IP address
+	// IPv6 counterparts of the variables above, used only if dual-stack is enabled
+	ipv6                     bool             // true if dual-stack (IPv4 + IPv6) POD addressing is enabled
+	podSubnetIPv6Prefix      net.IPNet        // IPv6 subnet from which individual POD networks are allocated
+	podNetworkIPv6Prefix     net.IPNet        // IPv6 subnet prefix for all PODs on the node (given by nodeID)
+	podNetworkGatewayIPv6    net.IP           // IPv6 gateway address for PODs on the node (given by nodeID)
+	podIfIPv6CIDR            net.IPNet        // IPv6 subnet from which individual VPP-side POD interfaces addresses are allocated
+	assignedPodIPv6s         map[string]podID // pool of assigned POD IPv6 addresses (keyed by the string form of the IP address)
+	lastAssignedIPv6         int              // counter denoting last assigned IPv6 address
+	vppHostSubnetIPv6Prefix  net.IPNet        // IPv6 subnet used across all nodes for VPP to host Linux stack interconnect
+	vppHostNetworkIPv6Prefix net.IPNet        // IPv6 subnet used by the node (given by nodeID) for VPP to host Linux stack interconnect
+	vethVPPEndIPv6           net.IP           // IPv6 address for virtual ethernet's VPP-end on given node
+	vethHostEndIPv6          net.IP           // IPv6 address for virtual ethernet's host-end on given node
+	nodeInterconnectIPv6CIDR net.IPNet        // IPv6 subnet used for for inter-node connections
+	vxlanIPv6CIDR            net.IPNet        // IPv6 subnet used for for inter-node VXLAN
 }
 
-type uintIP = uint32
 type podID = string
 
+var errIPv6Disabled = fmt.Errorf("IPv6 is not enabled in the IPAM configuration")
+
 // Config represents configuration of the IPAM module.
 type Config struct {
 	PodIfIPCIDR             string // subnet from which individual VPP-side POD interfaces networks are allocated, this is subnet for all PODS within 1 node.
@@ -75,16 +99,54 @@ type Config struct {
 	NodeInterconnectDHCP    bool   // if set to true DHCP is used to acquire IP for the main VPP interface (NodeInterconnectCIDR can be omitted in config)
 	VxlanCIDR               string // subnet used for for inter-node VXLAN
 	ServiceCIDR             string // subnet used by services
+
+	// DynamicPodCIDRBlocks enables on-demand allocation of POD networks (blocks with PodNetworkPrefixLen) from PodSubnetCIDR.
+	// The blocks are persisted in etcd and a node may own multiple of them. If not set, the POD network is derived from the node ID.
+	DynamicPodCIDRBlocks bool
+
+	// PodIPReservations maps reservation names to POD IPv4 addresses. A reserved address is assigned
+	// only to a POD requesting it by name, it is never assigned from the pool.
+	PodIPReservations map[string]string
+
+	// StickyPodIPs enables re-assignment of the previous IP address to a POD re-created with the same name
+	// (e.g. a StatefulSet POD rescheduled on the same node), if the address is still free. Only the StatefulSet
+	// PODs and the PODs opting in by an annotation are sticky.
+	StickyPodIPs bool
+
+	// StickyPodIPHoldTime is the time in seconds for which the sticky IP address of a deleted POD is held
+	// for its re-creation, the address is then released (default 300).
+	StickyPodIPHoldTime uint32
+
+	// SecondaryNetworks defines IPv4 address pools of the secondary networks that PODs can be attached to
+	// in addition to the default POD network.
+	SecondaryNetworks []SecondaryNetworkConfig
+
+	// IPv6 counterparts of the subnets above. Dual-stack POD addressing is enabled by setting PodSubnetIPv6CIDR,
+	// in which case all the other IPv6 subnets must be configured as well.
+	PodIfIPv6CIDR               string // IPv6 subnet from which individual VPP-side POD interfaces addresses are allocated
+	PodSubnetIPv6CIDR           string // IPv6 subnet from which individual POD networks are allocated
+	PodNetworkIPv6PrefixLen     uint8  // prefix length of IPv6 subnet used for all PODs within 1 node
+	VPPHostSubnetIPv6CIDR       string // IPv6 subnet used across all nodes for VPP to host Linux stack interconnect
+	VPPHostNetworkIPv6PrefixLen uint8  // prefix length of IPv6 subnet used for VPP to host Linux stack interconnect within 1 node
+	NodeInterconnectIPv6CIDR    string // IPv6 subnet used for inter-node connections
+	VxlanIPv6CIDR               string // IPv6 subnet used for inter-node VXLAN
 }
 
 // New returns new IPAM module to be used on the node specified by the nodeID.
-func New(logger logging.Logger, nodeID uint8, config *Config, broker keyval.ProtoBroker) (*IPAM, error) {
+// If <config.DynamicPodCIDRBlocks> is enabled, <blockStore> must be provided, otherwise it may be nil.
+func New(logger logging.Logger, nodeID uint32, config *Config, broker keyval.ProtoBroker, blockStore PodCIDRBlockStore) (*IPAM, error) {
 	// create basic IPAM
 	ipam := &IPAM{
-		logger:       logger,
-		nodeID:       nodeID,
-		lastAssigned: 1,
-		broker:       broker,
+		logger:           logger,
+		nodeID:           nodeID,
+		lastAssignedIPv6: 1,
+		broker:           broker,
+	}
+	if config.DynamicPodCIDRBlocks {
+		if blockStore == nil {
+			return nil, fmt.Errorf("dynamic allocation of pod CIDR blocks requires a block store")
+		}
+		ipam.blockStore = blockStore
 	}
 
 	// computing IPAM struct variables from IPAM config
@@ -100,6 +162,27 @@ func New(logger logging.Logger, nodeID uint8, config *Config, broker keyval.Prot
 	if err := initializePodIfIPPrefix(ipam, config); err != nil {
 		return nil, err
 	}
+	if err := initializeIPv6IPAM(ipam, config, nodeID); err != nil {
+		return nil, err
+	}
+	if err := initializeReservedPodIPs(ipam, config); err != nil {
+		return nil, err
+	}
+	if err := initializeSecondaryNetworks(ipam, config, nodeID); err != nil {
+		return nil, err
+	}
+	if err := ipam.loadAssignedIPs(); err != nil {
+		return nil, err
+	}
+	if err := ipam.loadStickyIPs(); err != nil {
+		return nil, err
+	}
+	if err := ipam.loadSecondaryIPs(); err != nil {
+		return nil, err
+	}
+	if err := ipam.checkNodeIDBits(); err != nil {
+		return nil, err
+	}
 	logger.Infof("IPAM values loaded: %+v", ipam)
 
 	return ipam, nil
@@ -113,52 +196,79 @@ func (i *IPAM) NodeInterconnectDHCPEnabled() bool {
 	return i.nodeInterconnectDHCP
 }
 
+// IPv6Enabled returns true if dual-stack (IPv4 + IPv6) POD addressing is enabled.
+func (i *IPAM) IPv6Enabled() bool {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	return i.ipv6
+}
+
 // NodeIPAddress computes IP address of the node based on the provided node ID.
-func (i *IPAM) NodeIPAddress(nodeID uint8) (net.IP, error) {
+func (i *IPAM) NodeIPAddress(nodeID uint32) (net.IP, error) {
 	i.mutex.RLock()
 	defer i.mutex.RUnlock()
-	return i.computeNodeIPAddress(nodeID)
+	return computeNodeAddress(i.nodeInterconnectCIDR, nodeID)
 }
 
 // NodeIPWithPrefix computes node address with prefix length based on the provided node ID.
-func (i *IPAM) NodeIPWithPrefix(nodeID uint8) (*net.IPNet, error) {
+func (i *IPAM) NodeIPWithPrefix(nodeID uint32) (*net.IPNet, error) {
 	i.mutex.RLock()
 	defer i.mutex.RUnlock()
+	return computeNodeAddressWithPrefix(i.nodeInterconnectCIDR, nodeID)
+}
 
-	hostIP, err := i.computeNodeIPAddress(nodeID)
-	if err != nil {
-		return nil, err
+// NodeIPv6Address computes IPv6 address of the node based on the provided node ID.
+func (i *IPAM) NodeIPv6Address(nodeID uint32) (net.IP, error) {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	if !i.ipv6 {
+		return nil, errIPv6Disabled
 	}
-	maskSize, _ := i.nodeInterconnectCIDR.Mask.Size()
-	hostIPNetwork := net.IPNet{
-		IP:   hostIP,
-		Mask: uint32ToIpv4Mask(((1 << uint(maskSize)) - 1) << (32 - uint8(maskSize))),
+	return computeNodeAddress(i.nodeInterconnectIPv6CIDR, nodeID)
+}
+
+// NodeIPv6WithPrefix computes node IPv6 address with prefix length based on the provided node ID.
+func (i *IPAM) NodeIPv6WithPrefix(nodeID uint32) (*net.IPNet, error) {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	if !i.ipv6 {
+		return nil, errIPv6Disabled
 	}
-	return &hostIPNetwork, nil
+	return computeNodeAddressWithPrefix(i.nodeInterconnectIPv6CIDR, nodeID)
 }
 
 // VxlanIPAddress computes IP address of the VXLAN interface based on the provided node ID.
-func (i *IPAM) VxlanIPAddress(nodeID uint8) (net.IP, error) {
+func (i *IPAM) VxlanIPAddress(nodeID uint32) (net.IP, error) {
 	i.mutex.RLock()
 	defer i.mutex.RUnlock()
-	return i.computeVxlanIPAddress(nodeID)
+	return computeNodeAddress(i.vxlanCIDR, nodeID)
 }
 
 // VxlanIPWithPrefix computes VXLAN interface address with prefix length based on the provided node ID.
-func (i *IPAM) VxlanIPWithPrefix(nodeID uint8) (*net.IPNet, error) {
+func (i *IPAM) VxlanIPWithPrefix(nodeID uint32) (*net.IPNet, error) {
 	i.mutex.RLock()
 	defer i.mutex.RUnlock()
+	return computeNodeAddressWithPrefix(i.vxlanCIDR, nodeID)
+}
 
-	hostIP, err := i.computeVxlanIPAddress(nodeID)
-	if err != nil {
-		return nil, err
+// VxlanIPv6Address computes IPv6 address of the VXLAN interface based on the provided node ID.
+func (i *IPAM) VxlanIPv6Address(nodeID uint32) (net.IP, error) {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	if !i.ipv6 {
+		return nil, errIPv6Disabled
 	}
-	maskSize, _ := i.vxlanCIDR.Mask.Size()
-	vxlanNetwork := net.IPNet{
-		IP:   hostIP,
-		Mask: uint32ToIpv4Mask(((1 << uint(maskSize)) - 1) << (32 - uint8(maskSize))),
+	return computeNodeAddress(i.vxlanIPv6CIDR, nodeID)
+}
+
+// VxlanIPv6WithPrefix computes VXLAN interface IPv6 address with prefix length based on the provided node ID.
+func (i *IPAM) VxlanIPv6WithPrefix(nodeID uint32) (*net.IPNet, error) {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	if !i.ipv6 {
+		return nil, errIPv6Disabled
 	}
-	return &vxlanNetwork, nil
+	return computeNodeAddressWithPrefix(i.vxlanIPv6CIDR, nodeID)
 }
 
 // VEthVPPEndIP provides the IPv4 address of the VPP-end of the VPP to host interconnect veth pair.
@@ -175,6 +285,22 @@ func (i *IPAM) VEthHostEndIP() net.IP {
 	return newIP(i.vethHostEndIP) // defensive copy
 }
 
+// VEthVPPEndIPv6 provides the IPv6 address of the VPP-end of the VPP to host interconnect veth pair.
+// Returns nil if IPv6 is not enabled.
+func (i *IPAM) VEthVPPEndIPv6() net.IP {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	return newIP(i.vethVPPEndIPv6) // defensive copy
+}
+
+// VEthHostEndIPv6 provides the IPv6 address of the host-end of the VPP to host interconnect veth pair.
+// Returns nil if IPv6 is not enabled.
+func (i *IPAM) VEthHostEndIPv6() net.IP {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	return newIP(i.vethHostEndIPv6) // defensive copy
+}
+
 // VPPHostNetwork returns vswitch network used to connect VPP to its host Linux Stack.
 func (i *IPAM) VPPHostNetwork() *net.IPNet {
 	i.mutex.RLock()
@@ -183,6 +309,18 @@ func (i *IPAM) VPPHostNetwork() *net.IPNet {
 	return &vSwitchNetwork
 }
 
+// VPPHostNetworkIPv6 returns IPv6 vswitch network used to connect VPP to its host Linux Stack.
+// Returns nil if IPv6 is not enabled.
+func (i *IPAM) VPPHostNetworkIPv6() *net.IPNet {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	if !i.ipv6 {
+		return nil
+	}
+	vSwitchNetwork := newIPNet(i.vppHostNetworkIPv6Prefix) // defensive copy
+	return &vSwitchNetwork
+}
+
 // VPPIfIPPrefix returns VPP-side interface IP address prefix.
 func (i *IPAM) VPPIfIPPrefix() *net.IP {
 	i.mutex.RLock()
@@ -191,18 +329,41 @@ func (i *IPAM) VPPIfIPPrefix() *net.IP {
 	return &podIfIPPrefix.IP
 }
 
+// VPPIfIPNetwork returns the subnet from which VPP-side POD interface addresses are allocated.
+func (i *IPAM) VPPIfIPNetwork() *net.IPNet {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	podIfIPNetwork := newIPNet(i.podIfIPCIDR) // defensive copy
+	return &podIfIPNetwork
+}
+
+// VPPIfIPv6Prefix returns VPP-side interface IPv6 address prefix.
+// Returns nil if IPv6 is not enabled.
+func (i *IPAM) VPPIfIPv6Prefix() *net.IP {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	if !i.ipv6 {
+		return nil
+	}
+	podIfIPPrefix := newIPNet(i.podIfIPv6CIDR) // defensive copy
+	return &podIfIPPrefix.IP
+}
+
 // OtherNodeVPPHostNetwork returns VPP-host network of another node identified by nodeID.
-func (i *IPAM) OtherNodeVPPHostNetwork(nodeID uint8) (*net.IPNet, error) {
+func (i *IPAM) OtherNodeVPPHostNetwork(nodeID uint32) (*net.IPNet, error) {
 	i.mutex.RLock()
 	defer i.mutex.RUnlock()
+	return otherNodeNetwork(i.vppHostSubnetIPPrefix, i.vppHostNetworkIPPrefix, nodeID)
+}
 
-	networkSize, _ := i.vppHostNetworkIPPrefix.Mask.Size()
-	vSwitchNetworkIPPrefix, err := applyNodeID(i.vppHostSubnetIPPrefix, nodeID, uint8(networkSize))
-	if err != nil {
-		return nil, err
+// OtherNodeVPPHostNetworkIPv6 returns IPv6 VPP-host network of another node identified by nodeID.
+func (i *IPAM) OtherNodeVPPHostNetworkIPv6(nodeID uint32) (*net.IPNet, error) {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	if !i.ipv6 {
+		return nil, errIPv6Disabled
 	}
-	vSwitchNetwork := newIPNet(vSwitchNetworkIPPrefix) // defensive copy
-	return &vSwitchNetwork, nil
+	return otherNodeNetwork(i.vppHostSubnetIPv6Prefix, i.vppHostNetworkIPv6Prefix, nodeID)
 }
 
 // PodSubnet returns POD subnet ("network_address/prefix_length") that is a base subnet for all PODs of all nodes.
@@ -213,6 +374,18 @@ func (i *IPAM) PodSubnet() *net.IPNet {
 	return &podSubnet
 }
 
+// PodSubnetIPv6 returns IPv6 POD subnet that is a base subnet for all PODs of all nodes.
+// Returns nil if IPv6 is not enabled.
+func (i *IPAM) PodSubnetIPv6() *net.IPNet {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	if !i.ipv6 {
+		return nil
+	}
+	podSubnet := newIPNet(i.podSubnetIPv6Prefix) // defensive copy
+	return &podSubnet
+}
+
 // PodNetwork returns POD network for the current node (given by nodeID given at IPAM creation).
 func (i *IPAM) PodNetwork() *net.IPNet {
 	i.mutex.RLock()
@@ -221,18 +394,76 @@ func (i *IPAM) PodNetwork() *net.IPNet {
 	return &podNetwork
 }
 
-// OtherNodePodNetwork returns the POD network of another node identified by nodeID.
-func (i *IPAM) OtherNodePodNetwork(nodeID uint8) (*net.IPNet, error) {
+// PodNetworkIPv6 returns IPv6 POD network for the current node (given by nodeID given at IPAM creation).
+// Returns nil if IPv6 is not enabled.
+func (i *IPAM) PodNetworkIPv6() *net.IPNet {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	if !i.ipv6 {
+		return nil
+	}
+	podNetwork := newIPNet(i.podNetworkIPv6Prefix) // defensive copy
+	return &podNetwork
+}
+
+// PodNetworks returns all POD networks (pod CIDR blocks) owned by the current node.
+// Unless the blocks are allocated dynamically, the only network is the one returned by PodNetwork().
+func (i *IPAM) PodNetworks() []*net.IPNet {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	var networks []*net.IPNet
+	for _, block := range i.podBlocks {
+		network := newIPNet(block.network) // defensive copy
+		networks = append(networks, &network)
+	}
+	return networks
+}
+
+// DynamicPodCIDRBlocks returns true if POD networks are allocated dynamically as pod CIDR blocks.
+func (i *IPAM) DynamicPodCIDRBlocks() bool {
 	i.mutex.RLock()
 	defer i.mutex.RUnlock()
+	return i.blockStore != nil
+}
 
-	networkSize, _ := i.podNetworkIPPrefix.Mask.Size()
-	podNetworkIPPrefix, err := applyNodeID(i.podSubnetIPPrefix, nodeID, uint8(networkSize))
+// OtherNodePodNetwork returns the POD network of another node identified by nodeID.
+// If pod CIDR blocks are allocated dynamically, the first block of the node is returned
+// (see OtherNodePodNetworks).
+func (i *IPAM) OtherNodePodNetwork(nodeID uint32) (*net.IPNet, error) {
+	networks, err := i.OtherNodePodNetworks(nodeID)
 	if err != nil {
 		return nil, err
 	}
-	podNetwork := newIPNet(podNetworkIPPrefix) // defensive copy
-	return &podNetwork, nil
+	if len(networks) == 0 {
+		return nil, fmt.Errorf("no pod CIDR block is allocated for node %v", nodeID)
+	}
+	return networks[0], nil
+}
+
+// OtherNodePodNetworks returns all POD networks (pod CIDR blocks) of another node identified by nodeID.
+// With dynamically allocated blocks, the networks are given by the cached blocks, which are kept up-to-date
+// via UpdatePodCIDRBlock and ResyncPodCIDRBlocks.
+func (i *IPAM) OtherNodePodNetworks(nodeID uint32) ([]*net.IPNet, error) {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	if i.blockStore == nil {
+		network, err := otherNodeNetwork(i.podSubnetIPPrefix, i.podNetworkIPPrefix, nodeID)
+		if err != nil {
+			return nil, err
+		}
+		return []*net.IPNet{network}, nil
+	}
+	return i.cachedNodePodCIDRBlocks(nodeID), nil
+}
+
+// OtherNodePodNetworkIPv6 returns the IPv6 POD network of another node identified by nodeID.
+func (i *IPAM) OtherNodePodNetworkIPv6(nodeID uint32) (*net.IPNet, error) {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	if !i.ipv6 {
+		return nil, errIPv6Disabled
+	}
+	return otherNodeNetwork(i.podSubnetIPv6Prefix, i.podNetworkIPv6Prefix, nodeID)
 }
 
 // ServiceNetwork returns range allocated for services.
@@ -243,6 +474,23 @@ func (i *IPAM) ServiceNetwork() *net.IPNet {
 	return &serviceNetwork
 }
 
+// ClusterSubnets returns the IPv4 subnets used inside the cluster across all nodes (PODs, services,
+// VPP to host interconnects, VXLANs and the inter-node connections, if configured).
+func (i *IPAM) ClusterSubnets() []*net.IPNet {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	var subnets []*net.IPNet
+	for _, subnet := range []net.IPNet{i.podSubnetIPPrefix, i.serviceCIDR, i.vppHostSubnetIPPrefix, i.vxlanCIDR,
+		i.nodeInterconnectCIDR} {
+		if subnet.IP != nil {
+			subnetCopy := newIPNet(subnet) // defensive copy
+			subnets = append(subnets, &subnetCopy)
+		}
+	}
+	return subnets
+}
+
 // PodGatewayIP returns gateway IP address of the POD network of this node.
 func (i *IPAM) PodGatewayIP() net.IP {
 	i.mutex.RLock()
@@ -250,78 +498,187 @@ func (i *IPAM) PodGatewayIP() net.IP {
 	return newIP(i.podNetworkGatewayIP) // defensive copy
 }
 
+// PodGatewayIPv6 returns gateway IPv6 address of the POD network of this node.
+// Returns nil if IPv6 is not enabled.
+func (i *IPAM) PodGatewayIPv6() net.IP {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	return newIP(i.podNetworkGatewayIPv6) // defensive copy
+}
+
 // NodeID returns unique host ID used to calculate the IP addresses.
-func (i *IPAM) NodeID() uint8 {
+func (i *IPAM) NodeID() uint32 {
 	i.mutex.RLock()
 	defer i.mutex.RUnlock()
 	return i.nodeID
 }
 
 // NextPodIP returns next available POD IP address and remembers that this IP is meant to be used for the POD with the id <podID>.
+// If pod CIDR blocks are allocated dynamically and all blocks of the node are exhausted, a new block is claimed.
+// The sticky IP addresses of PODs which are not connected are held out of the pool, they are assigned
+// to other PODs only if there is no other free address (a warning is logged).
 func (i *IPAM) NextPodIP(podID string) (net.IP, error) {
 	i.mutex.Lock()
 	defer i.mutex.Unlock()
 
-	if len(podID) == 0 { // zero byte length <=> zero character size
-		return nil, fmt.Errorf("Pod ID can't be empty because it is used to release the assigned IP address")
+	held := i.heldStickyIPs()
+	ip, err := i.nextPodIPFromBlocks(podID, held)
+	if err == nil {
+		return ip, nil
+	}
+	if i.blockStore != nil {
+		// all blocks are exhausted, claim a new one
+		var block *podCIDRBlock
+		block, err = i.claimPodCIDRBlock()
+		if err == nil {
+			return i.nextPodIP(podID, block.network, i.assignedPodIPs, held, &block.lastAssigned, i.saveAssignedIP)
+		}
+	}
+	if len(held) == 0 {
+		return nil, err
 	}
 
-	// get network prefix as uint32
-	networkPrefix, err := ipv4ToUint32(i.podNetworkIPPrefix.IP)
-	if err != nil {
+	// only the sticky IP addresses are left
+	ip, stickyErr := i.nextPodIPFromBlocks(podID, nil)
+	if stickyErr != nil {
 		return nil, err
 	}
+	i.logger.Warnf("No IP address is free for pod %v, assigned IP address %v previously assigned to pod %v",
+		podID, ip, held[ip.String()])
+	return ip, nil
+}
+
+// nextPodIPFromBlocks allocates next available IPv4 address from the pod CIDR blocks owned by the node,
+// skipping the <held> addresses.
+func (i *IPAM) nextPodIPFromBlocks(podID string, held map[string]string) (ip net.IP, err error) {
+	for _, block := range i.podBlocks {
+		ip, err = i.nextPodIP(podID, block.network, i.assignedPodIPs, held, &block.lastAssigned, i.saveAssignedIP)
+		if err == nil {
+			return ip, nil
+		}
+	}
+	return nil, err
+}
+
+// NextPodIPv6 returns next available POD IPv6 address and remembers that this IP is meant to be used for the POD
+// with the id <podID>. Returns an error if IPv6 is not enabled.
+func (i *IPAM) NextPodIPv6(podID string) (net.IP, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	if !i.ipv6 {
+		return nil, errIPv6Disabled
+	}
+	return i.nextPodIP(podID, i.podNetworkIPv6Prefix, i.assignedPodIPv6s, nil, &i.lastAssignedIPv6, i.saveAssignedIP)
+}
+
+// nextPodIP allocates next available IP address from the given POD network (of either IP family).
+// The <held> addresses are not assigned. The assignment is persisted using the <persist> callback.
+func (i *IPAM) nextPodIP(podID string, podNetwork net.IPNet, assigned map[string]podID, held map[string]string, lastAssigned *int, persist func(podID string) error) (net.IP, error) {
+	if len(podID) == 0 { // zero byte length <=> zero character size
+		return nil, fmt.Errorf("Pod ID can't be empty because it is used to release the assigned IP address")
+	}
 
-	last := i.lastAssigned + 1
+	last := *lastAssigned + 1
 	// iterate over all possible IP addresses for pod network prefix
 	// start from the last assigned and take first available IP
-	prefixBits, totalBits := i.podNetworkIPPrefix.Mask.Size()
-	maxSeqID := 1 << uint(totalBits-prefixBits) //max IP addresses in network range
-	for j := last; j < maxSeqID; j++ {          // zero ending IP is reserved for network => skip seqID=0
-		ipForAssign, success := i.tryToAllocatePodIP(j, networkPrefix, podID)
+	maxSeqID := maxSeqIDInNetwork(podNetwork) //max IP addresses in network range
+	for j := last; j < maxSeqID; j++ {        // zero ending IP is reserved for network => skip seqID=0
+		ipForAssign, success := i.tryToAllocatePodIP(j, podNetwork, assigned, held, podID, persist)
 		if success {
-			i.lastAssigned = j
+			*lastAssigned = j
 			return ipForAssign, nil
 		}
 	}
 
 	// iterate from the range start until lastAssigned
 	for j := 1; j < last; j++ { // zero ending IP is reserved for network => skip seqID=0
-		ipForAssign, success := i.tryToAllocatePodIP(j, networkPrefix, podID)
+		ipForAssign, success := i.tryToAllocatePodIP(j, podNetwork, assigned, held, podID, persist)
 		if success {
-			i.lastAssigned = j
+			*lastAssigned = j
 			return ipForAssign, nil
 		}
 	}
 
-	return nil, fmt.Errorf("No IP address is free for assignment. All IP addresses for pod network %v are already assigned", i.podNetworkIPPrefix)
+	return nil, fmt.Errorf("No IP address is free for assignment. All IP addresses for pod network %v are already assigned", podNetwork)
 }
 
 // tryToAllocatePodIP checks whether the IP at the given index is available.
-func (i *IPAM) tryToAllocatePodIP(index int, networkPrefix uint32, podID string) (assignedIP net.IP, success bool) {
+func (i *IPAM) tryToAllocatePodIP(index int, podNetwork net.IPNet, assigned map[string]podID, held map[string]string, podID string, persist func(podID string) error) (assignedIP net.IP, success bool) {
 	if index == podGatewaySeqID {
 		return nil, false // gateway IP address can't be assigned as pod
 	}
-	ip := networkPrefix + uint32(index)
-	if _, found := i.assignedPodIPs[ip]; found {
+	ip := addToIP(podNetwork.IP, index)
+	if _, found := assigned[ip.String()]; found {
 		return nil, false // ignore already assigned IP addresses
 	}
-	err := i.saveAssignedIP(ip, podID)
+	if _, reserved := i.reservedPodIPs[ip.String()]; reserved {
+		return nil, false // reserved IP addresses are assigned only on request
+	}
+	if _, isHeld := held[ip.String()]; isHeld {
+		return nil, false // e.g. sticky IP addresses of PODs which are not connected
+	}
+	assigned[ip.String()] = podID
+
+	err := persist(podID)
 	if err != nil {
+		delete(assigned, ip.String())
 		i.logger.Error(err)
 		return nil, false
 	}
 
-	i.assignedPodIPs[ip] = podID
-
-	ipForAssign := uint32ToIpv4(ip)
-	i.logger.Infof("Assigned new pod IP %s", ipForAssign)
+	i.logger.Infof("Assigned new pod IP %s", ip)
 	i.logAssignedPodIPPool()
 
-	return ipForAssign, true
+	return ip, true
+}
+
+// PodIP returns the IPv4 address assigned to the POD with the id <podID>, nil if there is none.
+func (i *IPAM) PodIP(podID string) net.IP {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	ip, found := findIP(i.assignedPodIPs, podID)
+	if !found {
+		return nil
+	}
+	return net.ParseIP(ip).To4()
+}
+
+// PodIPPoolUsage returns the number of IPv4 addresses assigned to PODs from the pod network and the size
+// of the pool, i.e. the number of the addresses available for PODs in the pod CIDR blocks owned by the node.
+func (i *IPAM) PodIPPoolUsage() (used int, size int) {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	for _, block := range i.podBlocks {
+		// the network address and the gateway are never assigned to PODs
+		size += maxSeqIDInNetwork(block.network) - 2
+	}
+	return len(i.assignedPodIPs), size
 }
 
-// ReleasePodIP releases the pod IP address remembered for POD id string, so that it can be reused by the next PODs.
+// AssignedPodIPs returns the IPv4 addresses assigned to PODs from the pod network, keyed by the POD ID.
+func (i *IPAM) AssignedPodIPs() map[string]net.IP {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	assigned := make(map[string]net.IP, len(i.assignedPodIPs))
+	for ip, podID := range i.assignedPodIPs {
+		assigned[podID] = net.ParseIP(ip).To4()
+	}
+	return assigned
+}
+
+// PodIDs returns IDs of all PODs with an IP address (of any IP family) assigned from the pod network.
+func (i *IPAM) PodIDs() []string {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	return podIDs(i.assignedPodIPs, i.assignedPodIPv6s)
+}
+
+// ReleasePodIP releases the pod IP addresses (of all IP families) remembered for POD id string,
+// so that they can be reused by the next PODs.
 func (i *IPAM) ReleasePodIP(podID string) error {
 	i.mutex.Lock()
 	defer i.mutex.Unlock()
@@ -331,50 +688,67 @@ func (i *IPAM) ReleasePodIP(podID string) error {
 		return nil
 	}
 
-	ip, err := i.findIP(podID)
-	if err != nil {
-		return fmt.Errorf("Can't release pod IP: %v", err)
+	ip, found := findIP(i.assignedPodIPs, podID)
+	ipv6, foundIPv6 := findIP(i.assignedPodIPv6s, podID)
+	if !found && !foundIPv6 {
+		return fmt.Errorf("Can't release pod IP: Can't find assigned pod IP address for pod ID \"%v\"", podID)
 	}
-	err = i.deleteAssignedIP(podID)
+	err := i.deleteAssignedIP(podID)
 	if err != nil {
 		return err
 	}
 	delete(i.assignedPodIPs, ip)
+	delete(i.assignedPodIPv6s, ipv6)
 
-	i.logger.Infof("Released IP %v for pod ID %v", uint32ToIpv4(ip), podID)
+	i.logger.Infof("Released IP %v %v for pod ID %v", ip, ipv6, podID)
 	i.logAssignedPodIPPool()
+
+	if i.blockStore != nil && found {
+		// return the block if it is no longer used
+		return i.releaseUnusedPodCIDRBlock(net.ParseIP(ip))
+	}
 	return nil
 }
 
 // initializePodsIPAM initializes POD -related variables of IPAM.
-func initializePodsIPAM(ipam *IPAM, config *Config, nodeID uint8) (err error) {
+func initializePodsIPAM(ipam *IPAM, config *Config, nodeID uint32) (err error) {
+	if ipam.blockStore != nil {
+		// blocks are not derived from the node ID, the ID does not have to fit into PodSubnetCIDR
+		nodeID = 0
+	}
 	ipam.podSubnetIPPrefix, ipam.podNetworkIPPrefix, err = convertConfigNotation(config.PodSubnetCIDR, config.PodNetworkPrefixLen, nodeID)
 	if err != nil {
 		return
 	}
+	if ipam.podNetworkIPPrefix.IP.To4() == nil {
+		return fmt.Errorf("PodSubnetCIDR %v is not an IPv4 subnet", config.PodSubnetCIDR)
+	}
 
-	podNetworkPrefixUint32, err := ipv4ToUint32(ipam.podNetworkIPPrefix.IP)
-	if err != nil {
-		return
+	if ipam.blockStore != nil {
+		// replace the network derived from node ID with dynamically allocated block(s)
+		if err = ipam.loadPodCIDRBlocks(); err != nil {
+			return err
+		}
+		ipam.podNetworkIPPrefix = ipam.podBlocks[0].network
+	} else {
+		ipam.podBlocks = []*podCIDRBlock{{network: ipam.podNetworkIPPrefix, lastAssigned: 1}}
 	}
-	ipam.podNetworkGatewayIP = uint32ToIpv4(podNetworkPrefixUint32 + podGatewaySeqID)
-	ipam.assignedPodIPs = make(map[uintIP]podID)
-	return ipam.loadAssignedIPs()
+
+	ipam.podNetworkGatewayIP = addToIP(ipam.podNetworkIPPrefix.IP, podGatewaySeqID)
+	ipam.assignedPodIPs = make(map[string]podID)
+	ipam.assignedPodIPv6s = make(map[string]podID)
+	return nil
 }
 
 // initializeVPPHostIPAM initializes VPP-host interconnect -related variables of IPAM.
-func initializeVPPHostIPAM(ipam *IPAM, config *Config, nodeID uint8) (err error) {
+func initializeVPPHostIPAM(ipam *IPAM, config *Config, nodeID uint32) (err error) {
 	ipam.vppHostSubnetIPPrefix, ipam.vppHostNetworkIPPrefix, err = convertConfigNotation(config.VPPHostSubnetCIDR, config.VPPHostNetworkPrefixLen, nodeID)
 	if err != nil {
 		return
 	}
 
-	vSwitchNetworkPrefixUint32, err := ipv4ToUint32(ipam.vppHostNetworkIPPrefix.IP)
-	if err != nil {
-		return
-	}
-	ipam.vethVPPEndIP = uint32ToIpv4(vSwitchNetworkPrefixUint32 + vethVPPEndIPSeqID)
-	ipam.vethHostEndIP = uint32ToIpv4(vSwitchNetworkPrefixUint32 + vethHostEndIPSeqID)
+	ipam.vethVPPEndIP = addToIP(ipam.vppHostNetworkIPPrefix.IP, vethVPPEndIPSeqID)
+	ipam.vethHostEndIP = addToIP(ipam.vppHostNetworkIPPrefix.IP, vethHostEndIPSeqID)
 
 	if config.ServiceCIDR == "" {
 		config.ServiceCIDR = defaultServiceCIDR
@@ -426,9 +800,62 @@ func initializePodIfIPPrefix(ipam *IPAM, config *Config) (err error) {
 	return
 }
 
+// initializeIPv6IPAM initializes all IPv6 -related variables of IPAM (only if dual-stack is enabled).
+func initializeIPv6IPAM(ipam *IPAM, config *Config, nodeID uint32) (err error) {
+	if config.PodSubnetIPv6CIDR == "" {
+		return nil
+	}
+	if config.PodIfIPv6CIDR == "" || config.VPPHostSubnetIPv6CIDR == "" || config.NodeInterconnectIPv6CIDR == "" ||
+		config.VxlanIPv6CIDR == "" {
+		return fmt.Errorf("missing PodIfIPv6CIDR or VPPHostSubnetIPv6CIDR or NodeInterconnectIPv6CIDR or VxlanIPv6CIDR configuration")
+	}
+	ipam.ipv6 = true
+
+	// PODs
+	ipam.podSubnetIPv6Prefix, ipam.podNetworkIPv6Prefix, err = convertConfigNotation(config.PodSubnetIPv6CIDR, config.PodNetworkIPv6PrefixLen, nodeID)
+	if err != nil {
+		return
+	}
+	ipam.podNetworkGatewayIPv6 = addToIP(ipam.podNetworkIPv6Prefix.IP, podGatewaySeqID)
+
+	// VPP-host interconnect
+	ipam.vppHostSubnetIPv6Prefix, ipam.vppHostNetworkIPv6Prefix, err = convertConfigNotation(config.VPPHostSubnetIPv6CIDR, config.VPPHostNetworkIPv6PrefixLen, nodeID)
+	if err != nil {
+		return
+	}
+	ipam.vethVPPEndIPv6 = addToIP(ipam.vppHostNetworkIPv6Prefix.IP, vethVPPEndIPSeqID)
+	ipam.vethHostEndIPv6 = addToIP(ipam.vppHostNetworkIPv6Prefix.IP, vethHostEndIPSeqID)
+
+	// node interconnect + VXLAN + VPP-side POD interfaces
+	subnets := []struct {
+		cidr   string
+		target *net.IPNet
+	}{
+		{config.NodeInterconnectIPv6CIDR, &ipam.nodeInterconnectIPv6CIDR},
+		{config.VxlanIPv6CIDR, &ipam.vxlanIPv6CIDR},
+		{config.PodIfIPv6CIDR, &ipam.podIfIPv6CIDR},
+	}
+	for _, subnet := range subnets {
+		_, ipNet, err := net.ParseCIDR(subnet.cidr)
+		if err != nil {
+			return err
+		}
+		*subnet.target = *ipNet
+	}
+
+	// all IPv6 subnets must really be IPv6
+	for _, ipNet := range []net.IPNet{ipam.podSubnetIPv6Prefix, ipam.vppHostSubnetIPv6Prefix,
+		ipam.nodeInterconnectIPv6CIDR, ipam.vxlanIPv6CIDR, ipam.podIfIPv6CIDR} {
+		if ipNet.IP.To4() != nil {
+			return fmt.Errorf("subnet %v is configured as IPv6 subnet, but it is not", ipNet.String())
+		}
+	}
+	return nil
+}
+
 // convertConfigNotation converts config notation and given node ID to IPAM structure notation.
-// I.e: input 1.2.3.4/16 (string), /24 (uint8), 5 (uint8) results in 1.2.0.0/16 (IPNet), 1.2.5.0/24 (IPNet)
-func convertConfigNotation(subnetCIDR string, networkPrefixLen uint8, nodeID uint8) (subnetIPPrefix net.IPNet, networkIPPrefix net.IPNet, err error) {
+// I.e: input 1.2.3.4/16 (string), /24 (uint8), 5 (uint32) results in 1.2.0.0/16 (IPNet), 1.2.5.0/24 (IPNet)
+func convertConfigNotation(subnetCIDR string, networkPrefixLen uint8, nodeID uint32) (subnetIPPrefix net.IPNet, networkIPPrefix net.IPNet, err error) {
 	// convert subnetCIDR to net.IPNet
 	_, pSubnet, err := net.ParseCIDR(subnetCIDR)
 	if err != nil {
@@ -438,127 +865,230 @@ func convertConfigNotation(subnetCIDR string, networkPrefixLen uint8, nodeID uin
 	subnetIPPrefix = *pSubnet
 
 	// checking correct prefix sizes
-	subnetPrefixLen, _ := subnetIPPrefix.Mask.Size()
+	subnetPrefixLen, totalBits := subnetIPPrefix.Mask.Size()
 	if networkPrefixLen <= uint8(subnetPrefixLen) {
 		err = fmt.Errorf("Network prefix length (%v) must be higher than subnet prefix length (%v) ", networkPrefixLen, subnetPrefixLen)
 		return
 	}
+	if int(networkPrefixLen) >= totalBits {
+		err = fmt.Errorf("Network prefix length (%v) must be lower than address length (%v) ", networkPrefixLen, totalBits)
+		return
+	}
 
 	networkIPPrefix, err = applyNodeID(subnetIPPrefix, nodeID, networkPrefixLen)
 	return
 }
 
 // applyNodeID creates network (IPNet) from subnet by adding transformed node ID to it.
-func applyNodeID(subnetIPPrefix net.IPNet, nodeID uint8, networkPrefixLen uint8) (networkIPPrefix net.IPNet, err error) {
+func applyNodeID(subnetIPPrefix net.IPNet, nodeID uint32, networkPrefixLen uint8) (networkIPPrefix net.IPNet, err error) {
 	// compute part of IP address representing host
-	subnetPrefixLen, _ := subnetIPPrefix.Mask.Size()
-	nodePartBitSize := networkPrefixLen - uint8(subnetPrefixLen)
-	nodeIPPart := convertToNodeIPPart(nodeID, nodePartBitSize)
-
-	// composing network IP prefix from previously computed parts
-	subnetIPPartUint32, err := ipv4ToUint32(subnetIPPrefix.IP)
-	if err != nil {
+	subnetPrefixLen, totalBits := subnetIPPrefix.Mask.Size()
+	nodePartBitSize := int(networkPrefixLen) - subnetPrefixLen
+	if !nodeIDFits(nodeID, nodePartBitSize) {
+		err = fmt.Errorf("node ID %d does not fit into %d bits between subnet %v and network prefix length %d",
+			nodeID, nodePartBitSize, subnetIPPrefix.String(), networkPrefixLen)
 		return
 	}
-	networkPrefixUint32 := subnetIPPartUint32 + (uint32(nodeIPPart) << (32 - networkPrefixLen))
+
+	// composing network IP prefix from previously computed parts
+	networkPrefix := new(big.Int).Lsh(big.NewInt(int64(nodeID)), uint(totalBits)-uint(networkPrefixLen))
+	networkPrefix.Add(networkPrefix, ipToBigInt(subnetIPPrefix.IP))
 	networkIPPrefix = net.IPNet{
-		IP:   uint32ToIpv4(networkPrefixUint32),
-		Mask: uint32ToIpv4Mask(((1 << uint(networkPrefixLen)) - 1) << (32 - networkPrefixLen)),
+		IP:   bigIntToIP(networkPrefix, totalBits),
+		Mask: net.CIDRMask(int(networkPrefixLen), totalBits),
 	}
 	return
 }
 
+// otherNodeNetwork computes network of another node identified by nodeID, using the prefix length of the network
+// of this node.
+func otherNodeNetwork(subnetIPPrefix net.IPNet, thisNodeNetwork net.IPNet, nodeID uint32) (*net.IPNet, error) {
+	networkSize, _ := thisNodeNetwork.Mask.Size()
+	networkIPPrefix, err := applyNodeID(subnetIPPrefix, nodeID, uint8(networkSize))
+	if err != nil {
+		return nil, err
+	}
+	network := newIPNet(networkIPPrefix) // defensive copy
+	return &network, nil
+}
+
+// checkNodeIDBits returns an error if any of the configured subnets does not provide enough bits
+// to represent the ID of this node (addresses of different nodes would collide).
+func (i *IPAM) checkNodeIDBits() error {
+	type nodeBits struct {
+		name string
+		bits int
+	}
+	networkBits := func(subnet, network net.IPNet) int {
+		subnetPrefixLen, _ := subnet.Mask.Size()
+		networkPrefixLen, _ := network.Mask.Size()
+		return networkPrefixLen - subnetPrefixLen
+	}
+	hostBits := func(subnet net.IPNet) int {
+		prefixLen, totalBits := subnet.Mask.Size()
+		return totalBits - prefixLen
+	}
+
+	checks := []nodeBits{
+		{"VPPHostSubnetCIDR", networkBits(i.vppHostSubnetIPPrefix, i.vppHostNetworkIPPrefix)},
+		{"VxlanCIDR", hostBits(i.vxlanCIDR)},
+	}
+	if i.blockStore == nil {
+		checks = append(checks, nodeBits{"PodSubnetCIDR", networkBits(i.podSubnetIPPrefix, i.podNetworkIPPrefix)})
+	}
+	if !i.nodeInterconnectDHCP {
+		checks = append(checks, nodeBits{"NodeInterconnectCIDR", hostBits(i.nodeInterconnectCIDR)})
+	}
+	if i.ipv6 {
+		checks = append(checks,
+			nodeBits{"PodSubnetIPv6CIDR", networkBits(i.podSubnetIPv6Prefix, i.podNetworkIPv6Prefix)},
+			nodeBits{"VPPHostSubnetIPv6CIDR", networkBits(i.vppHostSubnetIPv6Prefix, i.vppHostNetworkIPv6Prefix)},
+			nodeBits{"NodeInterconnectIPv6CIDR", hostBits(i.nodeInterconnectIPv6CIDR)},
+			nodeBits{"VxlanIPv6CIDR", hostBits(i.vxlanIPv6CIDR)})
+	}
+	for _, check := range checks {
+		if !nodeIDFits(i.nodeID, check.bits) {
+			return fmt.Errorf("node ID %d does not fit into %d bits provided by %s, use a wider subnet",
+				i.nodeID, check.bits, check.name)
+		}
+	}
+	return nil
+}
+
 // logAssignedPodIPPool logs assigned POD IPs.
 func (i *IPAM) logAssignedPodIPPool() {
 	if i.logger.GetLevel() <= logging.DebugLevel { // log only if debug level or more verbose
 		var buffer bytes.Buffer
-		for uintIP, podID := range i.assignedPodIPs {
-			buffer.WriteString(" # " + uint32ToIpv4(uintIP).String() + ":" + podID)
+		for ip, podID := range i.assignedPodIPs {
+			buffer.WriteString(" # " + ip + ":" + podID)
+		}
+		for ip, podID := range i.assignedPodIPv6s {
+			buffer.WriteString(" # " + ip + ":" + podID)
 		}
 		i.logger.Debugf("Actual pool of assigned pod IP addresses: %v", buffer.String())
 	}
 
 }
 
-// computeNodeIPAddress computes IP address of node based on the given node ID.
-func (i *IPAM) computeNodeIPAddress(nodeID uint8) (net.IP, error) {
-	// trimming nodeID if its place in IP address is narrower than actual uint8 size
-	subnetPrefixLen, _ := i.nodeInterconnectCIDR.Mask.Size()
-	nodePartBitSize := 32 - uint8(subnetPrefixLen)
-	nodeIPPart := convertToNodeIPPart(nodeID, nodePartBitSize)
+// computeNodeAddress computes IP address of a node within the given subnet based on the given node ID.
+func computeNodeAddress(subnet net.IPNet, nodeID uint32) (net.IP, error) {
+	if subnet.IP == nil {
+		return nil, fmt.Errorf("subnet for node addresses is not configured")
+	}
+	// refusing nodeID if its place in IP address is narrower than needed
+	subnetPrefixLen, totalBits := subnet.Mask.Size()
+	if !nodeIDFits(nodeID, totalBits-subnetPrefixLen) {
+		return nil, fmt.Errorf("node ID %d does not fit into subnet %v", nodeID, subnet.String())
+	}
 
 	// combining it to get result IP address
-	networkIPPartUint32, err := ipv4ToUint32(i.nodeInterconnectCIDR.IP)
+	return addToIP(subnet.IP, int(nodeID)), nil
+}
+
+// computeNodeAddressWithPrefix computes IP address of a node within the given subnet based on the given node ID,
+// including the prefix length of the subnet.
+func computeNodeAddressWithPrefix(subnet net.IPNet, nodeID uint32) (*net.IPNet, error) {
+	hostIP, err := computeNodeAddress(subnet, nodeID)
 	if err != nil {
 		return nil, err
 	}
-	return uint32ToIpv4(networkIPPartUint32 + uint32(nodeIPPart)), nil
+	maskSize, totalBits := subnet.Mask.Size()
+	return &net.IPNet{
+		IP:   hostIP,
+		Mask: net.CIDRMask(maskSize, totalBits),
+	}, nil
 }
 
-// computeVxlanIPAddress computes IP address of the VXLAN interface based on the given node ID.
-func (i *IPAM) computeVxlanIPAddress(nodeID uint8) (net.IP, error) {
-	// trimming nodeID if its place in IP address is narrower than actual uint8 size
-	subnetPrefixLen, _ := i.vxlanCIDR.Mask.Size()
-	nodePartBitSize := 32 - uint8(subnetPrefixLen)
-	nodeIPPart := convertToNodeIPPart(nodeID, nodePartBitSize)
-
-	// combining it to get result IP address
-	networkIPPartUint32, err := ipv4ToUint32(i.vxlanCIDR.IP)
-	if err != nil {
-		return nil, err
+// podIDs returns sorted IDs of the PODs with an address assigned in any of the given pools.
+func podIDs(pools ...map[string]podID) []string {
+	ids := map[string]struct{}{}
+	for _, assigned := range pools {
+		for _, pod := range assigned {
+			ids[pod] = struct{}{}
+		}
 	}
-	return uint32ToIpv4(networkIPPartUint32 + uint32(nodeIPPart)), nil
+	sorted := make([]string, 0, len(ids))
+	for pod := range ids {
+		sorted = append(sorted, pod)
+	}
+	sort.Strings(sorted)
+	return sorted
 }
 
-// findIP finds assigned IP address (in uint form) for given POD id or returns an error if no entry is found.
-func (i *IPAM) findIP(podID string) (uintIP, error) {
-	for ip, curPodID := range i.assignedPodIPs {
+// findIP finds assigned IP address for given POD id in the given pool.
+func findIP(assigned map[string]podID, podID string) (ip string, found bool) {
+	for ip, curPodID := range assigned {
 		if curPodID == podID {
-			return ip, nil
+			return ip, true
 		}
 	}
-	return 0, fmt.Errorf("Can't find assigned pod IP address for pod ID \"%v\"", podID)
+	return "", false
 }
 
-// convertToNodeIPPart converts nodeID to part of IP address that distinguishes network IP address prefix among
-// different nodes. The result doesn't have to be that whole nodeID, because in IP address there can be allocated
-// less space than the size of the nodeID.
-func convertToNodeIPPart(nodeID uint8, expectedNodePartBitSize uint8) uint8 {
-	return nodeID & ((1 << expectedNodePartBitSize) - 1) //TODO this is only trimming nodeID to expected bit count, do we want to map nodeID to some value from config?
+// nodeIDFits returns true if the node ID can be represented in the given number of bits.
+func nodeIDFits(nodeID uint32, nodePartBitSize int) bool {
+	return nodePartBitSize >= maxNodeIDBits || nodeID < 1<<uint(nodePartBitSize)
 }
 
-// ipv4ToUint32 is simple utility function for conversion between IPv4 and uint32.
-func ipv4ToUint32(ip net.IP) (uint32, error) {
-	ip = ip.To4()
-	if ip == nil {
-		return 0, fmt.Errorf("Ip address %v is not ipv4 address (or ipv6 convertible to ipv4 address)", ip)
+// maxSeqIDInNetwork returns the number of IP addresses in the given network, limited to 2^maxPodSeqIDBits
+// (IPv6 networks are too large to be iterated over completely).
+func maxSeqIDInNetwork(network net.IPNet) int {
+	prefixBits, totalBits := network.Mask.Size()
+	hostBits := totalBits - prefixBits
+	if hostBits > maxPodSeqIDBits {
+		hostBits = maxPodSeqIDBits
 	}
-	var tmp uint32
-	for _, bytePart := range ip {
-		tmp = tmp<<8 + uint32(bytePart)
+	return 1 << uint(hostBits)
+}
+
+// ipToBigInt is simple utility function for conversion between IP address (of any family) and big.Int.
+func ipToBigInt(ip net.IP) *big.Int {
+	if ip4 := ip.To4(); ip4 != nil {
+		return new(big.Int).SetBytes(ip4)
 	}
-	return tmp, nil
+	return new(big.Int).SetBytes(ip.To16())
 }
 
-// uint32ToIpv4 is simple utility function for conversion between IPv4 and uint32.
-func uint32ToIpv4(ip uint32) net.IP {
-	return net.IPv4(byte(ip>>24), byte(ip>>16), byte(ip>>8), byte(ip)).To4()
+// bigIntToIP is simple utility function for conversion between big.Int and IP address with the given bit length.
+func bigIntToIP(i *big.Int, bits int) net.IP {
+	ip := make(net.IP, bits/8)
+	b := i.Bytes()
+	if len(b) > len(ip) {
+		b = b[len(b)-len(ip):]
+	}
+	copy(ip[len(ip)-len(b):], b)
+	return ip
 }
 
-// uint32ToIpv4Mask is simple utility function for conversion between IPv4Mask and uint32.
-func uint32ToIpv4Mask(ip uint32) net.IPMask {
-	return net.IPv4Mask(byte(ip>>24), byte(ip>>16), byte(ip>>8), byte(ip))
+// addToIP returns IP address computed by adding the given offset to the given IP address (of any family).
+func addToIP(ip net.IP, offset int) net.IP {
+	bits := net.IPv6len * 8
+	if ip.To4() != nil {
+		bits = net.IPv4len * 8
+	}
+	sum := new(big.Int).Add(ipToBigInt(ip), big.NewInt(int64(offset)))
+	return bigIntToIP(sum, bits)
 }
 
 // newIPNet is simple utility function to create defend copy of net.IPNet.
 func newIPNet(ipNet net.IPNet) net.IPNet {
+	mask := make(net.IPMask, len(ipNet.Mask))
+	copy(mask, ipNet.Mask)
 	return net.IPNet{
 		IP:   newIP(ipNet.IP),
-		Mask: net.IPv4Mask(ipNet.Mask[0], ipNet.Mask[1], ipNet.Mask[2], ipNet.Mask[3]),
+		Mask: mask,
 	}
 }
 
 // newIP is simple utility function to create defend copy of net.IP.
 func newIP(ip net.IP) net.IP {
-	return net.IPv4(ip[0], ip[1], ip[2], ip[3]).To4()
+	if ip == nil {
+		return nil
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return net.IPv4(ip4[0], ip4[1], ip4[2], ip4[3]).To4()
+	}
+	ipCopy := make(net.IP, len(ip))
+	copy(ipCopy, ip)
+	return ipCopy
 }
@@ -60,15 +60,27 @@ func newDefaultConfig() *ipam.Config {
 		PodNetworkPrefixLen:     29, // 3 bits left -> 6 free IP addresses (gateway IP + zero ending IP is reserved)
 		VPPHostSubnetCIDR:       "2.3." + str(b11000000) + ".2/18",
 		VPPHostNetworkPrefixLen: 30, // 2 bit left -> 3 free IP addresses (zero ending IP is reserved)
-		NodeInterconnectCIDR:    "3.4.5." + str(b11000010) + "/26",
-		VxlanCIDR:               "4.5.6." + str(b11000010) + "/26",
+		NodeInterconnectCIDR:    "3.4.5." + str(b11000010) + "/24",
+		VxlanCIDR:               "4.5.6." + str(b11000010) + "/24",
 	}
 }
 
+func newDualStackConfig() *ipam.Config {
+	config := newDefaultConfig()
+	config.PodIfIPv6CIDR = "fd00:2::/64"
+	config.PodSubnetIPv6CIDR = "fd00:1::/48"
+	config.PodNetworkIPv6PrefixLen = 64
+	config.VPPHostSubnetIPv6CIDR = "fd00:3::/48"
+	config.VPPHostNetworkIPv6PrefixLen = 64
+	config.NodeInterconnectIPv6CIDR = "fd00:4::/64"
+	config.VxlanIPv6CIDR = "fd00:5::/64"
+	return config
+}
+
 func setup(t *testing.T, cfg *ipam.Config) *ipam.IPAM {
 	RegisterTestingT(t)
 
-	i, err := ipam.New(logrus.DefaultLogger(), uint8(hostID1), cfg, nil)
+	i, err := ipam.New(logrus.DefaultLogger(), uint32(hostID1), cfg, nil, nil)
 	Expect(err).To(BeNil())
 	return i
 }
@@ -93,27 +105,38 @@ func TestStaticGetters(t *testing.T) {
 // TestDynamicGetters tests proper working IMAP API that provides data based on new input (func parameters)
 func TestDynamicGetters(t *testing.T) {
 	i := setup(t, newDefaultConfig())
-	ip, err := i.NodeIPAddress(hostID2)
+	ip, err := i.NodeIPAddress(uint32(hostID2))
 	Expect(err).To(BeNil())
-	Expect(ip).To(BeEquivalentTo(net.IPv4(3, 4, 5, b11100101).To4()))
+	Expect(ip).To(BeEquivalentTo(net.IPv4(3, 4, 5, hostID2).To4()))
 
-	ipNet, err := i.NodeIPWithPrefix(hostID2)
+	ipNet, err := i.NodeIPWithPrefix(uint32(hostID2))
 	Expect(err).To(BeNil())
-	Expect(*ipNet).To(BeEquivalentTo(ipWithNetworkMask("3.4.5." + str(b11100101) + "/26")))
+	Expect(*ipNet).To(BeEquivalentTo(ipWithNetworkMask("3.4.5." + str(int(hostID2)) + "/24")))
 
-	ipNet, err = i.VxlanIPWithPrefix(hostID2)
+	ipNet, err = i.VxlanIPWithPrefix(uint32(hostID2))
 	Expect(err).To(BeNil())
-	Expect(*ipNet).To(BeEquivalentTo(ipWithNetworkMask("4.5.6." + str(b11100101) + "/26")))
+	Expect(*ipNet).To(BeEquivalentTo(ipWithNetworkMask("4.5.6." + str(int(hostID2)) + "/24")))
 
-	ipNet, err = i.OtherNodePodNetwork(hostID2)
+	ipNet, err = i.OtherNodePodNetwork(uint32(hostID2))
 	Expect(err).To(BeNil())
 	Expect(*ipNet).To(BeEquivalentTo(network("1.2." + str(b10000000+int(hostID2>>5)) + "." + str(int(hostID2<<3)) + "/29")))
 
-	ipNet, err = i.OtherNodeVPPHostNetwork(hostID2)
+	ipNet, err = i.OtherNodeVPPHostNetwork(uint32(hostID2))
 	Expect(err).To(BeNil())
 	Expect(*ipNet).To(BeEquivalentTo(network("2.3." + str(b11000000+int(hostID2>>6)) + "." + str(int(hostID2<<2)) + "/30")))
 }
 
+// TestClusterSubnets tests that all subnets used inside the cluster are listed
+func TestClusterSubnets(t *testing.T) {
+	i := setup(t, newDefaultConfig())
+	var subnets []string
+	for _, subnet := range i.ClusterSubnets() {
+		subnets = append(subnets, subnet.String())
+	}
+	Expect(subnets).To(ConsistOf("1.2."+str(b10000000)+".0/17", "10.96.0.0/12", "2.3."+str(b11000000)+".0/18",
+		"3.4.5.0/24", "4.5.6.0/24"))
+}
+
 // TestBasicAllocateReleasePodAddress test simple happy path scenario for getting 1 pod address and releasing it
 func TestBasicAllocateReleasePodAddress(t *testing.T) {
 	i := setup(t, newDefaultConfig())
@@ -220,7 +243,13 @@ func Test8bitPodIPPoolSize(t *testing.T) {
 	podNetwork := network("1.2." + str(int(hostID1)) + ".0/24")
 	maxIPCount := 256 - 2 //2 IPs are reserved
 
+	used, size := i.PodIPPoolUsage()
+	Expect(used).To(BeZero())
+	Expect(size).To(BeEquivalentTo(maxIPCount))
+
 	assertAllocationOfAllIPAddresses(i, maxIPCount, podNetwork)
+	used, _ = i.PodIPPoolUsage()
+	Expect(used).To(BeEquivalentTo(maxIPCount))
 	assertCorrectIPExhaustion(i, maxIPCount)
 }
 
@@ -239,15 +268,60 @@ func TestBiggerThan8bitPodIPPoolSize(t *testing.T) {
 	assertCorrectIPExhaustion(i, maxIPCount)
 }
 
-// TestHostIDtrimming test IPAM ability to properly create IP address part corresponding to host ID. More precise the
-// case when 8-bit hostID doesn't fit into less-then-8bit IP Part.
-func TestHostIDtrimming(t *testing.T) {
+// TestHostIDNotFitting tests that IPAM refuses host ID that doesn't fit into the IP part corresponding to host ID.
+// More precise the case when 8-bit hostID doesn't fit into less-then-8bit IP Part.
+func TestHostIDNotFitting(t *testing.T) {
+	RegisterTestingT(t)
+
 	customConfig := newDefaultConfig()
 	customConfig.PodSubnetCIDR = "1.2.3.4/19"
 	customConfig.PodNetworkPrefixLen = 24
-	i := setup(t, customConfig)
+	_, err := ipam.New(logrus.DefaultLogger(), uint32(hostID1), customConfig, nil, nil)
+	Expect(err).NotTo(BeNil(), "Host ID doesn't fit into the pod subnet, but IPAM initialization didn't fail")
 
-	Expect(*i.PodNetwork()).To(BeEquivalentTo(network("1.2." + str(int(hostID1&(1<<5-1))) + ".0/24")))
+	customConfig = newDefaultConfig()
+	customConfig.NodeInterconnectCIDR = "3.4.5.0/25"
+	_, err = ipam.New(logrus.DefaultLogger(), uint32(hostID1), customConfig, nil, nil)
+	Expect(err).NotTo(BeNil(), "Host ID doesn't fit into the node interconnect subnet, but IPAM initialization didn't fail")
+
+	// addresses are not computed for other nodes with too wide ID
+	i := setup(t, newDefaultConfig())
+	_, err = i.OtherNodePodNetwork(1 << 16)
+	Expect(err).NotTo(BeNil())
+	_, err = i.NodeIPAddress(1 << 16)
+	Expect(err).NotTo(BeNil())
+}
+
+// TestWideNodeID tests IPAM with node ID that doesn't fit into 8 bits.
+func TestWideNodeID(t *testing.T) {
+	RegisterTestingT(t)
+
+	const wideNodeID = 1000 // 0x3e8
+	customConfig := newDefaultConfig()
+	customConfig.PodSubnetCIDR = "10.0.0.0/8"
+	customConfig.PodNetworkPrefixLen = 24
+	customConfig.VPPHostSubnetCIDR = "172.16.0.0/12"
+	customConfig.VPPHostNetworkPrefixLen = 24
+	customConfig.NodeInterconnectCIDR = "192.168.0.0/16"
+	customConfig.VxlanCIDR = "192.169.0.0/16"
+	i, err := ipam.New(logrus.DefaultLogger(), wideNodeID, customConfig, nil, nil)
+	Expect(err).To(BeNil())
+
+	Expect(i.NodeID()).To(BeEquivalentTo(wideNodeID))
+	Expect(*i.PodNetwork()).To(BeEquivalentTo(network("10.3.232.0/24")))
+	Expect(*i.VPPHostNetwork()).To(BeEquivalentTo(network("172.19.232.0/24")))
+
+	ip, err := i.NodeIPAddress(wideNodeID)
+	Expect(err).To(BeNil())
+	Expect(ip.String()).To(BeEquivalentTo("192.168.3.232"))
+
+	ip, err = i.VxlanIPAddress(wideNodeID + 1)
+	Expect(err).To(BeNil())
+	Expect(ip.String()).To(BeEquivalentTo("192.169.3.233"))
+
+	ipNet, err := i.OtherNodePodNetwork(4000)
+	Expect(err).To(BeNil())
+	Expect(*ipNet).To(BeEquivalentTo(network("10.15.160.0/24")))
 }
 
 // TestConfigWithBadCIDR test if IPAM detects incorrect unparsable CIDR string and handles it correctly (initialization returns error)
@@ -256,17 +330,17 @@ func TestConfigWithBadCIDR(t *testing.T) {
 
 	customConfig := newDefaultConfig()
 	customConfig.PodSubnetCIDR = "1.2.3./19"
-	_, err := ipam.New(logrus.DefaultLogger(), uint8(hostID1), customConfig, nil)
+	_, err := ipam.New(logrus.DefaultLogger(), uint32(hostID1), customConfig, nil, nil)
 	Expect(err).NotTo(BeNil(), "Pod subnet CIDR is unparsable, but IPAM initialization didn't fail")
 
 	customConfig = newDefaultConfig()
 	customConfig.VPPHostSubnetCIDR = "1.2.3./19"
-	_, err = ipam.New(logrus.DefaultLogger(), uint8(hostID1), customConfig, nil)
+	_, err = ipam.New(logrus.DefaultLogger(), uint32(hostID1), customConfig, nil, nil)
 	Expect(err).NotTo(BeNil(), "VSwitch subnet CIDR is unparsable, but IPAM initialization didn't fail")
 
 	customConfig = newDefaultConfig()
 	customConfig.NodeInterconnectCIDR = "1.2.3./19"
-	_, err = ipam.New(logrus.DefaultLogger(), uint8(hostID1), customConfig, nil)
+	_, err = ipam.New(logrus.DefaultLogger(), uint32(hostID1), customConfig, nil, nil)
 	Expect(err).NotTo(BeNil(), "Host subnet CIDR is unparsable, but IPAM initialization didn't fail")
 }
 
@@ -277,13 +351,77 @@ func TestConfigWithBadPrefixSizes(t *testing.T) {
 	customConfig := newDefaultConfig()
 	customConfig.PodSubnetCIDR = "1.2.3.4/19"
 	customConfig.PodNetworkPrefixLen = 18
-	_, err := ipam.New(logrus.DefaultLogger(), uint8(hostID1), customConfig, nil)
+	_, err := ipam.New(logrus.DefaultLogger(), uint32(hostID1), customConfig, nil, nil)
 	Expect(err).NotTo(BeNil())
 
 	customConfig = newDefaultConfig()
 	customConfig.VPPHostSubnetCIDR = "1.2.3.4/19"
 	customConfig.VPPHostNetworkPrefixLen = 18
-	_, err = ipam.New(logrus.DefaultLogger(), uint8(hostID1), customConfig, nil)
+	_, err = ipam.New(logrus.DefaultLogger(), uint32(hostID1), customConfig, nil, nil)
+	Expect(err).NotTo(BeNil())
+}
+
+// TestDualStack tests allocation and release of IPv4 + IPv6 pod addresses and IPv6 getters.
+func TestDualStack(t *testing.T) {
+	i := setup(t, newDualStackConfig())
+	Expect(i.IPv6Enabled()).To(BeTrue())
+
+	podNetworkIPv6 := network("fd00:1:0:" + fmt.Sprintf("%x", hostID1) + "::/64")
+	Expect(*i.PodSubnetIPv6()).To(BeEquivalentTo(network("fd00:1::/48")))
+	Expect(*i.PodNetworkIPv6()).To(BeEquivalentTo(podNetworkIPv6))
+	Expect(i.PodGatewayIPv6().String()).To(BeEquivalentTo("fd00:1:0:a1::1"))
+	Expect(i.VEthVPPEndIPv6().String()).To(BeEquivalentTo("fd00:3:0:a1::1"))
+	Expect(i.VEthHostEndIPv6().String()).To(BeEquivalentTo("fd00:3:0:a1::2"))
+
+	ipNet, err := i.OtherNodePodNetworkIPv6(uint32(hostID2))
+	Expect(err).To(BeNil())
+	Expect(*ipNet).To(BeEquivalentTo(network("fd00:1:0:a5::/64")))
+
+	ip, err := i.NodeIPv6Address(uint32(hostID2))
+	Expect(err).To(BeNil())
+	Expect(ip.String()).To(BeEquivalentTo("fd00:4::a5"))
+
+	ipNet, err = i.VxlanIPv6WithPrefix(uint32(hostID2))
+	Expect(err).To(BeNil())
+	Expect(ipNet.String()).To(BeEquivalentTo("fd00:5::a5/64"))
+
+	// one address per family
+	ipv4, err := i.NextPodIP(podID)
+	Expect(err).To(BeNil())
+	Expect(i.PodNetwork().Contains(ipv4)).To(BeTrue())
+	ipv6, err := i.NextPodIPv6(podID)
+	Expect(err).To(BeNil())
+	Expect(ipv6.String()).To(BeEquivalentTo("fd00:1:0:a1::2"))
+
+	second, err := i.NextPodIPv6(podID + "2")
+	Expect(err).To(BeNil())
+	Expect(second.String()).To(BeEquivalentTo("fd00:1:0:a1::3"))
+
+	// both addresses are released together
+	Expect(i.ReleasePodIP(podID)).To(BeNil())
+	Expect(i.ReleasePodIP(podID)).NotTo(BeNil())
+}
+
+// TestIPv6Disabled tests that IPv6 getters fail if IPv6 is not configured.
+func TestIPv6Disabled(t *testing.T) {
+	i := setup(t, newDefaultConfig())
+	Expect(i.IPv6Enabled()).To(BeFalse())
+	Expect(i.PodNetworkIPv6()).To(BeNil())
+	Expect(i.PodGatewayIPv6()).To(BeNil())
+	_, err := i.NextPodIPv6(podID)
+	Expect(err).NotTo(BeNil())
+
+	// incomplete IPv6 configuration
+	RegisterTestingT(t)
+	customConfig := newDualStackConfig()
+	customConfig.VxlanIPv6CIDR = ""
+	_, err = ipam.New(logrus.DefaultLogger(), uint32(hostID1), customConfig, nil, nil)
+	Expect(err).NotTo(BeNil())
+
+	// IPv4 subnet configured as IPv6
+	customConfig = newDualStackConfig()
+	customConfig.PodIfIPv6CIDR = "10.2.1.0/24"
+	_, err = ipam.New(logrus.DefaultLogger(), uint32(hostID1), customConfig, nil, nil)
 	Expect(err).NotTo(BeNil())
 }
 
@@ -9,6 +9,7 @@ It is generated from these files:
 
 It has these top-level messages:
 	AllocatedIP
+	PodCIDRBlock
 */
 package model
 
@@ -33,6 +34,8 @@ type AllocatedIP struct {
 	ID uint32 `protobuf:"varint,1,opt,name=ID" json:"ID,omitempty"`
 	// pod is an identifier tied to assigned IP
 	Pod string `protobuf:"bytes,2,opt,name=pod" json:"pod,omitempty"`
+	// IPv6 is the assigned IPv6 address (empty if IPv6 is not enabled)
+	IPv6 string `protobuf:"bytes,3,opt,name=IPv6" json:"IPv6,omitempty"`
 }
 
 func (m *AllocatedIP) Reset()                    { *m = AllocatedIP{} }
@@ -54,18 +57,58 @@ func (m *AllocatedIP) GetPod() string {
 	return ""
 }
 
+func (m *AllocatedIP) GetIPv6() string {
+	if m != nil {
+		return m.IPv6
+	}
+	return ""
+}
+
+// PodCIDRBlock represents a block of POD IP addresses allocated for a node
+// (used only if dynamic allocation of pod CIDR blocks is enabled)
+type PodCIDRBlock struct {
+	// network is the allocated block in the CIDR notation
+	Network string `protobuf:"bytes,1,opt,name=network" json:"network,omitempty"`
+	// nodeID is the ID of the node owning the block
+	NodeID uint32 `protobuf:"varint,2,opt,name=nodeID" json:"nodeID,omitempty"`
+}
+
+func (m *PodCIDRBlock) Reset()                    { *m = PodCIDRBlock{} }
+func (m *PodCIDRBlock) String() string            { return proto.CompactTextString(m) }
+func (*PodCIDRBlock) ProtoMessage()               {}
+func (*PodCIDRBlock) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *PodCIDRBlock) GetNetwork() string {
+	if m != nil {
+		return m.Network
+	}
+	return ""
+}
+
+func (m *PodCIDRBlock) GetNodeID() uint32 {
+	if m != nil {
+		return m.NodeID
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*AllocatedIP)(nil), "model.AllocatedIP")
+	proto.RegisterType((*PodCIDRBlock)(nil), "model.PodCIDRBlock")
 }
 
 func init() { proto.RegisterFile("ipam.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 95 bytes of a gzipped FileDescriptorProto
+	// 154 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0xca, 0x2c, 0x48, 0xcc,
-	0xd5, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0xcd, 0xcd, 0x4f, 0x49, 0xcd, 0x51, 0xd2, 0xe7,
+	0xd5, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0xcd, 0xcd, 0x4f, 0x49, 0xcd, 0x51, 0x72, 0xe6,
 	0xe2, 0x76, 0xcc, 0xc9, 0xc9, 0x4f, 0x4e, 0x2c, 0x49, 0x4d, 0xf1, 0x0c, 0x10, 0xe2, 0xe3, 0x62,
 	0xf2, 0x74, 0x91, 0x60, 0x54, 0x60, 0xd4, 0xe0, 0x0d, 0x62, 0xf2, 0x74, 0x11, 0x12, 0xe0, 0x62,
-	0x2e, 0xc8, 0x4f, 0x91, 0x60, 0x52, 0x60, 0xd4, 0xe0, 0x0c, 0x02, 0x31, 0x93, 0xd8, 0xc0, 0xda,
-	0x8d, 0x01, 0x01, 0x00, 0x00, 0xff, 0xff, 0x43, 0x71, 0x9e, 0xa6, 0x4c, 0x00, 0x00, 0x00,
+	0x2e, 0xc8, 0x4f, 0x91, 0x60, 0x52, 0x60, 0xd4, 0xe0, 0x0c, 0x02, 0x31, 0x85, 0x84, 0xb8, 0x58,
+	0x3c, 0x03, 0xca, 0xcc, 0x24, 0x98, 0xc1, 0x42, 0x60, 0xb6, 0x92, 0x03, 0x17, 0x4f, 0x40, 0x7e,
+	0x8a, 0xb3, 0xa7, 0x4b, 0x90, 0x53, 0x4e, 0x7e, 0x72, 0xb6, 0x90, 0x04, 0x17, 0x7b, 0x5e, 0x6a,
+	0x49, 0x79, 0x7e, 0x51, 0x36, 0xd8, 0x28, 0xce, 0x20, 0x18, 0x57, 0x48, 0x8c, 0x8b, 0x2d, 0x2f,
+	0x3f, 0x25, 0xd5, 0xd3, 0x05, 0x6c, 0x24, 0x6f, 0x10, 0x94, 0x97, 0xc4, 0x06, 0x76, 0x94, 0x31,
+	0x60, 0x00, 0x71, 0x14, 0xb1, 0xec, 0xa2, 0x00, 0x00, 0x00,
 }
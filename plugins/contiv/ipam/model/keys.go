@@ -14,6 +14,8 @@
 
 package model
 
+import "strings"
+
 // KeyPrefix return prefix where all ipam items are persisted
 func KeyPrefix() string {
 	return "ipam/"
@@ -23,3 +25,34 @@ func KeyPrefix() string {
 func Key(pod string) string {
 	return KeyPrefix() + pod
 }
+
+// StickyIPKeyPrefix returns prefix where IP addresses last assigned to pods (identified by namespace/name) are persisted
+func StickyIPKeyPrefix() string {
+	return "stickyIPs/"
+}
+
+// StickyIPKey returns the key for the sticky IP of a given pod (namespace/name)
+func StickyIPKey(podName string) string {
+	return StickyIPKeyPrefix() + podName
+}
+
+// PodCIDRBlockKeyPrefix returns prefix where all allocated pod CIDR blocks are persisted
+// (the blocks are shared by all nodes of the cluster).
+func PodCIDRBlockKeyPrefix() string {
+	return "podCIDRBlocks/"
+}
+
+// PodCIDRBlockKey returns the key for a given pod CIDR block (in the CIDR notation).
+func PodCIDRBlockKey(network string) string {
+	return PodCIDRBlockKeyPrefix() + strings.Replace(network, "/", "-", 1)
+}
+
+// SecondaryIPKeyPrefix returns prefix where IP addresses assigned to pods in the secondary networks are persisted
+func SecondaryIPKeyPrefix() string {
+	return "secondaryIPs/"
+}
+
+// SecondaryIPKey returns the key for the IP address assigned to a given pod in a given secondary network
+func SecondaryIPKey(network string, pod string) string {
+	return SecondaryIPKeyPrefix() + network + "/" + pod
+}
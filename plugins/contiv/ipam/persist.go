@@ -14,7 +14,13 @@
 
 package ipam
 
-import "github.com/contiv/vpp/plugins/contiv/ipam/model"
+import (
+	"fmt"
+	"math/big"
+	"net"
+
+	"github.com/contiv/vpp/plugins/contiv/ipam/model"
+)
 
 //go:generate protoc -I ./model --go_out=plugins=grpc:./model ./model/ipam.proto
 
@@ -23,10 +29,6 @@ func (i *IPAM) loadAssignedIPs() error {
 		i.logger.Info("No broker specified, assigned IPs will not be loaded from persisted storage")
 		return nil
 	}
-	networkPrefix, err := ipv4ToUint32(i.podNetworkIPPrefix.IP)
-	if err != nil {
-		return err
-	}
 
 	it, err := i.broker.ListValues(model.KeyPrefix())
 	if err != nil {
@@ -44,23 +46,54 @@ func (i *IPAM) loadAssignedIPs() error {
 			return err
 		}
 		cnt++
-		i.assignedPodIPs[ip.ID] = ip.Pod
-
-		diff := int(ip.ID - networkPrefix)
-		if i.lastAssigned < diff {
-			i.lastAssigned = diff
+		if ip.ID != 0 {
+			ipv4 := uint32ToIpv4(ip.ID)
+			block := i.podCIDRBlockForIP(ipv4)
+			if block == nil {
+				i.logger.Warnf("Ignoring persisted IP address %v of pod %v, it is not from any pod network of the node", ipv4, ip.Pod)
+			} else {
+				i.loadAssignedIP(ipv4, ip.Pod, block.network, i.assignedPodIPs, &block.lastAssigned)
+			}
+		}
+		if ip.IPv6 != "" {
+			if !i.ipv6 {
+				i.logger.Warnf("Ignoring persisted IPv6 address %v of pod %v, IPv6 is not enabled", ip.IPv6, ip.Pod)
+				continue
+			}
+			i.loadAssignedIP(net.ParseIP(ip.IPv6), ip.Pod, i.podNetworkIPv6Prefix, i.assignedPodIPv6s, &i.lastAssignedIPv6)
 		}
 	}
 	i.logger.Infof("%v persisted IPAM items were loaded", cnt)
 	return nil
 }
 
-func (i *IPAM) saveAssignedIP(ip uint32, pod string) error {
+// loadAssignedIP marks the given persisted IP address as assigned in the given pool.
+func (i *IPAM) loadAssignedIP(ip net.IP, pod string, podNetwork net.IPNet, assigned map[string]podID, lastAssigned *int) {
+	if ip == nil || !podNetwork.Contains(ip) {
+		i.logger.Warnf("Ignoring persisted IP address %v of pod %v, it is not from the pod network %v", ip, pod, podNetwork.String())
+		return
+	}
+	assigned[ip.String()] = pod
+
+	diff := new(big.Int).Sub(ipToBigInt(ip), ipToBigInt(podNetwork.IP))
+	if diff.IsInt64() && *lastAssigned < int(diff.Int64()) {
+		*lastAssigned = int(diff.Int64())
+	}
+}
+
+// saveAssignedIP persists all IP addresses (of all IP families) currently assigned to the given pod.
+func (i *IPAM) saveAssignedIP(pod string) error {
 	if i.broker == nil {
 		i.logger.Debug("No broker specified, allocated IP will not be persisted")
 		return nil
 	}
-	item := &model.AllocatedIP{ID: ip, Pod: pod}
+	item := &model.AllocatedIP{Pod: pod}
+	if ip, found := findIP(i.assignedPodIPs, pod); found {
+		item.ID, _ = ipv4ToUint32(net.ParseIP(ip))
+	}
+	if ip, found := findIP(i.assignedPodIPv6s, pod); found {
+		item.IPv6 = ip
+	}
 	return i.broker.Put(model.Key(item.Pod), item)
 }
 
@@ -72,3 +105,23 @@ func (i *IPAM) deleteAssignedIP(pod string) error {
 	_, err := i.broker.Delete(model.Key(pod))
 	return err
 }
+
+// ipv4ToUint32 is simple utility function for conversion between IPv4 and uint32.
+// It is used only for the persisted IPv4 addresses.
+func ipv4ToUint32(ip net.IP) (uint32, error) {
+	ip = ip.To4()
+	if ip == nil {
+		return 0, fmt.Errorf("Ip address %v is not ipv4 address (or ipv6 convertible to ipv4 address)", ip)
+	}
+	var tmp uint32
+	for _, bytePart := range ip {
+		tmp = tmp<<8 + uint32(bytePart)
+	}
+	return tmp, nil
+}
+
+// uint32ToIpv4 is simple utility function for conversion between IPv4 and uint32.
+// It is used only for the persisted IPv4 addresses.
+func uint32ToIpv4(ip uint32) net.IP {
+	return net.IPv4(byte(ip>>24), byte(ip>>16), byte(ip>>8), byte(ip)).To4()
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipam
+
+import (
+	"fmt"
+	"net"
+	"sort"
+
+	"github.com/contiv/vpp/plugins/contiv/ipam/model"
+)
+
+const (
+	maxBlockClaimAttempts = 10 // maximum number of attempts to claim a pod CIDR block (other nodes may claim the same block concurrently)
+	maxPodCIDRBlockBits   = 24 // maximum number of bits of the block index iterated over when looking for a free pod CIDR block
+)
+
+// PodCIDRBlockStore is a cluster-wide persistent storage of pod CIDR blocks allocated to nodes.
+// It is used by IPAM only if dynamic allocation of pod CIDR blocks is enabled.
+type PodCIDRBlockStore interface {
+	// ListPodCIDRBlocks returns all pod CIDR blocks allocated in the cluster.
+	ListPodCIDRBlocks() ([]*model.PodCIDRBlock, error)
+
+	// ClaimPodCIDRBlock atomically allocates the given block for the node.
+	// Returns false if the block is already allocated.
+	ClaimPodCIDRBlock(block *model.PodCIDRBlock) (succeeded bool, err error)
+
+	// ReleasePodCIDRBlock returns the block (given in the CIDR notation) back to the pool.
+	ReleasePodCIDRBlock(network string) error
+}
+
+// podCIDRBlock is a pod CIDR block owned by this node.
+type podCIDRBlock struct {
+	network      net.IPNet
+	lastAssigned int // counter denoting last assigned IP address within the block
+}
+
+// UpdatePodCIDRBlock updates the cached owner of the pod CIDR block claimed (or released)
+// by any node of the cluster. It should be called for every change of the block store.
+func (i *IPAM) UpdatePodCIDRBlock(block *model.PodCIDRBlock, claimed bool) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	if i.blockStore == nil {
+		return
+	}
+	if claimed {
+		i.podCIDRBlocks[block.Network] = block.NodeID
+	} else {
+		delete(i.podCIDRBlocks, block.Network)
+	}
+}
+
+// ResyncPodCIDRBlocks replaces the cached pod CIDR blocks with the full content of the block store.
+func (i *IPAM) ResyncPodCIDRBlocks(blocks []*model.PodCIDRBlock) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	if i.blockStore == nil {
+		return
+	}
+	i.cachePodCIDRBlocks(blocks)
+}
+
+// cachePodCIDRBlocks replaces the cached pod CIDR blocks with the given ones.
+func (i *IPAM) cachePodCIDRBlocks(blocks []*model.PodCIDRBlock) {
+	i.podCIDRBlocks = make(map[string]uint32)
+	for _, block := range blocks {
+		i.podCIDRBlocks[block.Network] = block.NodeID
+	}
+}
+
+// loadPodCIDRBlocks loads blocks owned by this node from the block store.
+// If the node does not own any block yet, a new block is claimed.
+func (i *IPAM) loadPodCIDRBlocks() error {
+	blocks, err := i.blockStore.ListPodCIDRBlocks()
+	if err != nil {
+		return err
+	}
+	i.cachePodCIDRBlocks(blocks)
+	for _, network := range i.cachedNodePodCIDRBlocks(i.nodeID) {
+		i.podBlocks = append(i.podBlocks, &podCIDRBlock{network: *network, lastAssigned: 1})
+	}
+	if len(i.podBlocks) == 0 {
+		_, err = i.claimPodCIDRBlock()
+		return err
+	}
+	i.logger.Infof("%v pod CIDR blocks owned by the node were loaded", len(i.podBlocks))
+	return nil
+}
+
+// cachedNodePodCIDRBlocks returns cached blocks owned by the given node, ordered by their network address.
+func (i *IPAM) cachedNodePodCIDRBlocks(nodeID uint32) []*net.IPNet {
+	var networks []*net.IPNet
+	for block, owner := range i.podCIDRBlocks {
+		if owner != nodeID {
+			continue
+		}
+		_, network, err := net.ParseCIDR(block)
+		if err != nil {
+			i.logger.Warnf("Ignoring invalid pod CIDR block %v: %v", block, err)
+			continue
+		}
+		networks = append(networks, network)
+	}
+	sort.Slice(networks, func(a, b int) bool {
+		return ipToBigInt(networks[a].IP).Cmp(ipToBigInt(networks[b].IP)) < 0
+	})
+	return networks
+}
+
+// claimPodCIDRBlock allocates the first free block of the pod subnet for this node.
+func (i *IPAM) claimPodCIDRBlock() (*podCIDRBlock, error) {
+	subnetPrefixLen, _ := i.podSubnetIPPrefix.Mask.Size()
+	networkPrefixLen, _ := i.podNetworkIPPrefix.Mask.Size()
+	blockBits := networkPrefixLen - subnetPrefixLen
+	if blockBits > maxPodCIDRBlockBits {
+		blockBits = maxPodCIDRBlockBits
+	}
+
+	for attempt := 0; attempt < maxBlockClaimAttempts; attempt++ {
+		blocks, err := i.blockStore.ListPodCIDRBlocks()
+		if err != nil {
+			return nil, err
+		}
+		// refresh the cache, the block store is the authority when claiming a block
+		i.cachePodCIDRBlocks(blocks)
+
+		var free *net.IPNet
+		for index := uint32(0); index < 1<<uint(blockBits); index++ {
+			network, err := applyNodeID(i.podSubnetIPPrefix, index, uint8(networkPrefixLen))
+			if err != nil {
+				return nil, err
+			}
+			if _, allocated := i.podCIDRBlocks[network.String()]; !allocated {
+				free = &network
+				break
+			}
+		}
+		if free == nil {
+			return nil, fmt.Errorf("no free pod CIDR block is left in the pod subnet %v", i.podSubnetIPPrefix.String())
+		}
+
+		succeeded, err := i.blockStore.ClaimPodCIDRBlock(&model.PodCIDRBlock{Network: free.String(), NodeID: i.nodeID})
+		if err != nil {
+			return nil, err
+		}
+		if succeeded {
+			block := &podCIDRBlock{network: *free, lastAssigned: 1}
+			i.podBlocks = append(i.podBlocks, block)
+			i.podCIDRBlocks[free.String()] = i.nodeID
+			i.logger.Infof("Claimed pod CIDR block %v", free.String())
+			return block, nil
+		}
+	}
+	return nil, fmt.Errorf("unable to claim pod CIDR block (max attempt limit reached)")
+}
+
+// releaseUnusedPodCIDRBlock returns the block containing the given IP address back to the pool
+// if there is no other POD IP assigned from it. The first (primary) block of the node is never released,
+// since it contains the POD gateway IP.
+func (i *IPAM) releaseUnusedPodCIDRBlock(ip net.IP) error {
+	for idx, block := range i.podBlocks {
+		if idx == 0 || !block.network.Contains(ip) {
+			continue
+		}
+		for assignedIP := range i.assignedPodIPs {
+			if block.network.Contains(net.ParseIP(assignedIP)) {
+				return nil // still in use
+			}
+		}
+		if err := i.blockStore.ReleasePodCIDRBlock(block.network.String()); err != nil {
+			return err
+		}
+		i.podBlocks = append(i.podBlocks[:idx], i.podBlocks[idx+1:]...)
+		delete(i.podCIDRBlocks, block.network.String())
+		i.logger.Infof("Released pod CIDR block %v", block.network.String())
+		return nil
+	}
+	return nil
+}
+
+// podCIDRBlockForIP returns block of this node that contains the given IP address, nil if there is none.
+func (i *IPAM) podCIDRBlockForIP(ip net.IP) *podCIDRBlock {
+	for _, block := range i.podBlocks {
+		if block.network.Contains(ip) {
+			return block
+		}
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipam_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/ligato/cn-infra/logging/logrus"
+	. "github.com/onsi/gomega"
+
+	"github.com/contiv/vpp/plugins/contiv/ipam"
+	"github.com/contiv/vpp/plugins/contiv/ipam/model"
+)
+
+const otherNodeID = 5
+
+// blockStoreMock is an in-memory implementation of ipam.PodCIDRBlockStore.
+type blockStoreMock struct {
+	blocks    map[string]*model.PodCIDRBlock
+	listCalls int
+}
+
+func newBlockStoreMock(blocks ...*model.PodCIDRBlock) *blockStoreMock {
+	bs := &blockStoreMock{blocks: map[string]*model.PodCIDRBlock{}}
+	for _, block := range blocks {
+		bs.blocks[block.Network] = block
+	}
+	return bs
+}
+
+func (bs *blockStoreMock) ListPodCIDRBlocks() ([]*model.PodCIDRBlock, error) {
+	bs.listCalls++
+	var blocks []*model.PodCIDRBlock
+	for _, block := range bs.blocks {
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+func (bs *blockStoreMock) ClaimPodCIDRBlock(block *model.PodCIDRBlock) (bool, error) {
+	if _, allocated := bs.blocks[block.Network]; allocated {
+		return false, nil
+	}
+	bs.blocks[block.Network] = block
+	return true, nil
+}
+
+func (bs *blockStoreMock) ReleasePodCIDRBlock(network string) error {
+	delete(bs.blocks, network)
+	return nil
+}
+
+func newDynamicBlocksConfig() *ipam.Config {
+	config := newDefaultConfig()
+	config.DynamicPodCIDRBlocks = true
+	return config
+}
+
+// TestDynamicPodCIDRBlocks tests claiming of an extra pod CIDR block when the node runs out of pod IPs
+// and releasing of the block once it is no longer used.
+func TestDynamicPodCIDRBlocks(t *testing.T) {
+	RegisterTestingT(t)
+
+	// the first block of the pod subnet is already owned by other node
+	store := newBlockStoreMock(&model.PodCIDRBlock{Network: "1.2.128.0/29", NodeID: otherNodeID})
+	i, err := ipam.New(logrus.DefaultLogger(), uint32(hostID1), newDynamicBlocksConfig(), nil, store)
+	Expect(err).To(BeNil())
+
+	Expect(i.DynamicPodCIDRBlocks()).To(BeTrue())
+	Expect(*i.PodNetwork()).To(BeEquivalentTo(network("1.2.128.8/29")))
+	Expect(i.PodGatewayIP().String()).To(BeEquivalentTo("1.2.128.9"))
+	Expect(store.blocks).To(HaveKey("1.2.128.8/29"))
+	Expect(store.blocks["1.2.128.8/29"].NodeID).To(BeEquivalentTo(hostID1))
+
+	// exhaust the first block
+	allocated, _ := exhaustPodIPAddresses(i, 6)
+	for _, ip := range allocated {
+		assertAllocationOfIPAddress(net.ParseIP(ip), network("1.2.128.8/29"))
+	}
+
+	// the next pod IP is taken from a newly claimed block
+	ip, err := i.NextPodIP("extraPod")
+	Expect(err).To(BeNil())
+	Expect(ip.String()).To(BeEquivalentTo("1.2.128.18"))
+	Expect(store.blocks).To(HaveKey("1.2.128.16/29"))
+	Expect(i.PodNetworks()).To(HaveLen(2))
+
+	networks, err := i.OtherNodePodNetworks(uint32(hostID1))
+	Expect(err).To(BeNil())
+	Expect(networks).To(HaveLen(2))
+	Expect(networks[0].String()).To(BeEquivalentTo("1.2.128.8/29"))
+	Expect(networks[1].String()).To(BeEquivalentTo("1.2.128.16/29"))
+
+	networks, err = i.OtherNodePodNetworks(otherNodeID)
+	Expect(err).To(BeNil())
+	Expect(networks).To(HaveLen(1))
+	Expect(networks[0].String()).To(BeEquivalentTo("1.2.128.0/29"))
+
+	// releasing the last pod of the extra block returns the block back to the pool
+	Expect(i.ReleasePodIP("extraPod")).To(BeNil())
+	Expect(store.blocks).ToNot(HaveKey("1.2.128.16/29"))
+	Expect(i.PodNetworks()).To(HaveLen(1))
+
+	// the primary block is never released
+	releaseAllPodAddresses(i, 6)
+	Expect(store.blocks).To(HaveKey("1.2.128.8/29"))
+}
+
+// TestDynamicPodCIDRBlocksWithoutStore tests that the block store is required for the dynamic allocation.
+func TestDynamicPodCIDRBlocksWithoutStore(t *testing.T) {
+	RegisterTestingT(t)
+
+	_, err := ipam.New(logrus.DefaultLogger(), uint32(hostID1), newDynamicBlocksConfig(), nil, nil)
+	Expect(err).NotTo(BeNil())
+}
+
+// TestDynamicPodCIDRBlocksLargeNodeID tests that with dynamically allocated blocks the node ID does not have
+// to fit into the bits between PodSubnetCIDR and PodNetworkPrefixLen.
+func TestDynamicPodCIDRBlocksLargeNodeID(t *testing.T) {
+	RegisterTestingT(t)
+
+	// 3 bits between /17 and /20 -> at most 8 nodes with the static layout
+	const largeNodeID = 100
+	config := newDefaultConfig()
+	config.PodNetworkPrefixLen = 20
+	_, err := ipam.New(logrus.DefaultLogger(), largeNodeID, config, nil, nil)
+	Expect(err).NotTo(BeNil())
+
+	config.DynamicPodCIDRBlocks = true
+	store := newBlockStoreMock(&model.PodCIDRBlock{Network: "1.2.128.0/20", NodeID: otherNodeID})
+	i, err := ipam.New(logrus.DefaultLogger(), largeNodeID, config, nil, store)
+	Expect(err).To(BeNil())
+	Expect(*i.PodNetwork()).To(BeEquivalentTo(network("1.2.144.0/20")))
+	Expect(store.blocks["1.2.144.0/20"].NodeID).To(BeEquivalentTo(largeNodeID))
+}
+
+// TestCachedPodCIDRBlocks tests that the pod CIDR blocks of the other nodes are served from the cache
+// maintained by the block change events.
+func TestCachedPodCIDRBlocks(t *testing.T) {
+	RegisterTestingT(t)
+
+	store := newBlockStoreMock(&model.PodCIDRBlock{Network: "1.2.128.0/29", NodeID: otherNodeID})
+	i, err := ipam.New(logrus.DefaultLogger(), uint32(hostID1), newDynamicBlocksConfig(), nil, store)
+	Expect(err).To(BeNil())
+	listCalls := store.listCalls
+
+	// the block store is not accessed
+	networks, err := i.OtherNodePodNetworks(otherNodeID)
+	Expect(err).To(BeNil())
+	Expect(networks).To(HaveLen(1))
+	Expect(networks[0].String()).To(BeEquivalentTo("1.2.128.0/29"))
+	Expect(store.listCalls).To(Equal(listCalls))
+
+	// block claimed by the other node
+	i.UpdatePodCIDRBlock(&model.PodCIDRBlock{Network: "1.2.128.16/29", NodeID: otherNodeID}, true)
+	networks, err = i.OtherNodePodNetworks(otherNodeID)
+	Expect(err).To(BeNil())
+	Expect(networks).To(HaveLen(2))
+	Expect(networks[1].String()).To(BeEquivalentTo("1.2.128.16/29"))
+
+	// block released by the other node
+	i.UpdatePodCIDRBlock(&model.PodCIDRBlock{Network: "1.2.128.0/29", NodeID: otherNodeID}, false)
+	networks, err = i.OtherNodePodNetworks(otherNodeID)
+	Expect(err).To(BeNil())
+	Expect(networks).To(HaveLen(1))
+	Expect(networks[0].String()).To(BeEquivalentTo("1.2.128.16/29"))
+
+	// resync replaces the cached blocks
+	i.ResyncPodCIDRBlocks([]*model.PodCIDRBlock{
+		{Network: "1.2.128.8/29", NodeID: uint32(hostID1)},
+		{Network: "1.2.128.24/29", NodeID: otherNodeID},
+	})
+	networks, err = i.OtherNodePodNetworks(otherNodeID)
+	Expect(err).To(BeNil())
+	Expect(networks).To(HaveLen(1))
+	Expect(networks[0].String()).To(BeEquivalentTo("1.2.128.24/29"))
+	Expect(store.listCalls).To(Equal(listCalls))
+}
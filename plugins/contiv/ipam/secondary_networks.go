@@ -0,0 +1,249 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipam
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/contiv/vpp/plugins/contiv/ipam/model"
+)
+
+// SecondaryNetworkConfig represents IPAM configuration of one secondary POD network.
+type SecondaryNetworkConfig struct {
+	Name             string // name of the network
+	SubnetCIDR       string // subnet from which the networks of individual nodes are allocated
+	NetworkPrefixLen uint8  // prefix length of the subnet used for the PODs of the network within 1 node
+}
+
+// secondaryNetwork is the IPv4 address pool of one secondary POD network on the node.
+type secondaryNetwork struct {
+	subnet       net.IPNet        // subnet of the network across all nodes
+	network      net.IPNet        // subnet of the network on this node (given by nodeID)
+	gatewayIP    net.IP           // gateway IP address of the network on the node
+	assigned     map[string]podID // assigned POD IP addresses (keyed by the string form of the IP address)
+	lastAssigned int              // counter denoting last assigned IP address
+}
+
+// SecondaryNetworks returns names of all configured secondary POD networks.
+func (i *IPAM) SecondaryNetworks() []string {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	var names []string
+	for name := range i.secondaryNetworks {
+		names = append(names, name)
+	}
+	return names
+}
+
+// SecondaryPodSubnet returns the subnet of the given secondary network across all nodes.
+func (i *IPAM) SecondaryPodSubnet(network string) (*net.IPNet, error) {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	nw, err := i.secondaryNetwork(network)
+	if err != nil {
+		return nil, err
+	}
+	subnet := newIPNet(nw.subnet) // defensive copy
+	return &subnet, nil
+}
+
+// SecondaryPodNetwork returns the subnet of the given secondary network used for the PODs on this node.
+func (i *IPAM) SecondaryPodNetwork(network string) (*net.IPNet, error) {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	nw, err := i.secondaryNetwork(network)
+	if err != nil {
+		return nil, err
+	}
+	podNetwork := newIPNet(nw.network) // defensive copy
+	return &podNetwork, nil
+}
+
+// SecondaryOtherNodePodNetwork returns the subnet of the given secondary network used for the PODs
+// on another node identified by nodeID.
+func (i *IPAM) SecondaryOtherNodePodNetwork(network string, nodeID uint32) (*net.IPNet, error) {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	nw, err := i.secondaryNetwork(network)
+	if err != nil {
+		return nil, err
+	}
+	return otherNodeNetwork(nw.subnet, nw.network, nodeID)
+}
+
+// SecondaryPodGatewayIP returns the gateway IP address of the given secondary network on this node.
+func (i *IPAM) SecondaryPodGatewayIP(network string) (net.IP, error) {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	nw, err := i.secondaryNetwork(network)
+	if err != nil {
+		return nil, err
+	}
+	return newIP(nw.gatewayIP), nil
+}
+
+// NextSecondaryPodIP returns next available IP address of the given secondary network and remembers
+// that this IP is meant to be used for the POD with the id <podID>.
+func (i *IPAM) NextSecondaryPodIP(network string, podID string) (net.IP, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	nw, err := i.secondaryNetwork(network)
+	if err != nil {
+		return nil, err
+	}
+	return i.nextPodIP(podID, nw.network, nw.assigned, nil, &nw.lastAssigned, func(pod string) error {
+		return i.saveSecondaryIP(network, pod)
+	})
+}
+
+// SecondaryPodIP returns the IP address of the given secondary network assigned to the POD with the id <podID>,
+// or nil if no address is assigned.
+func (i *IPAM) SecondaryPodIP(network string, podID string) (net.IP, error) {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	nw, err := i.secondaryNetwork(network)
+	if err != nil {
+		return nil, err
+	}
+	ip, found := findIP(nw.assigned, podID)
+	if !found {
+		return nil, nil
+	}
+	return net.ParseIP(ip).To4(), nil
+}
+
+// SecondaryPodIDs returns IDs of all PODs with an IP address assigned from the given secondary network.
+func (i *IPAM) SecondaryPodIDs(network string) ([]string, error) {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	nw, err := i.secondaryNetwork(network)
+	if err != nil {
+		return nil, err
+	}
+	return podIDs(nw.assigned), nil
+}
+
+// ReleaseSecondaryPodIP releases the IP address of the given secondary network remembered for POD id string.
+func (i *IPAM) ReleaseSecondaryPodIP(network string, podID string) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	nw, err := i.secondaryNetwork(network)
+	if err != nil {
+		return err
+	}
+	ip, found := findIP(nw.assigned, podID)
+	if !found {
+		return fmt.Errorf("Can't release pod IP: Can't find IP address of pod ID \"%v\" in the network %v", podID, network)
+	}
+	err = i.deleteSecondaryIP(network, podID)
+	if err != nil {
+		return err
+	}
+	delete(nw.assigned, ip)
+	i.logger.Infof("Released IP %v of the network %v for pod ID %v", ip, network, podID)
+	return nil
+}
+
+// secondaryNetwork returns pool of the given secondary network.
+func (i *IPAM) secondaryNetwork(network string) (*secondaryNetwork, error) {
+	nw, found := i.secondaryNetworks[network]
+	if !found {
+		return nil, fmt.Errorf("secondary network %q is not configured", network)
+	}
+	return nw, nil
+}
+
+// initializeSecondaryNetworks initializes address pools of the secondary POD networks.
+func initializeSecondaryNetworks(ipam *IPAM, config *Config, nodeID uint32) error {
+	ipam.secondaryNetworks = make(map[string]*secondaryNetwork)
+	for _, nwConfig := range config.SecondaryNetworks {
+		if nwConfig.Name == "" || strings.Contains(nwConfig.Name, "/") {
+			return fmt.Errorf("invalid name %q of a secondary network", nwConfig.Name)
+		}
+		if _, duplicate := ipam.secondaryNetworks[nwConfig.Name]; duplicate {
+			return fmt.Errorf("secondary network %q is defined more than once", nwConfig.Name)
+		}
+		subnet, network, err := convertConfigNotation(nwConfig.SubnetCIDR, nwConfig.NetworkPrefixLen, nodeID)
+		if err != nil {
+			return fmt.Errorf("invalid configuration of the secondary network %q: %v", nwConfig.Name, err)
+		}
+		if network.IP.To4() == nil {
+			return fmt.Errorf("SubnetCIDR %v of the secondary network %q is not an IPv4 subnet", nwConfig.SubnetCIDR, nwConfig.Name)
+		}
+		ipam.secondaryNetworks[nwConfig.Name] = &secondaryNetwork{
+			subnet:       subnet,
+			network:      network,
+			gatewayIP:    addToIP(network.IP, podGatewaySeqID),
+			assigned:     make(map[string]podID),
+			lastAssigned: 1,
+		}
+	}
+	return nil
+}
+
+// loadSecondaryIPs loads persisted IP addresses assigned to PODs in the secondary networks.
+func (i *IPAM) loadSecondaryIPs() error {
+	if i.broker == nil || len(i.secondaryNetworks) == 0 {
+		return nil
+	}
+
+	it, err := i.broker.ListValues(model.SecondaryIPKeyPrefix())
+	if err != nil {
+		return err
+	}
+	for {
+		ip := &model.AllocatedIP{}
+		kv, stop := it.GetNext()
+		if stop {
+			break
+		}
+		err = kv.GetValue(ip)
+		if err != nil {
+			return err
+		}
+		key := kv.GetKey()
+		key = key[strings.Index(key, model.SecondaryIPKeyPrefix())+len(model.SecondaryIPKeyPrefix()):]
+		network := strings.SplitN(key, "/", 2)[0]
+		nw, found := i.secondaryNetworks[network]
+		if !found {
+			i.logger.Warnf("Ignoring persisted IP address of pod %v, secondary network %v is not configured", ip.Pod, network)
+			continue
+		}
+		i.loadAssignedIP(uint32ToIpv4(ip.ID), ip.Pod, nw.network, nw.assigned, &nw.lastAssigned)
+	}
+	return nil
+}
+
+func (i *IPAM) saveSecondaryIP(network string, pod string) error {
+	if i.broker == nil {
+		return nil
+	}
+	ip, _ := findIP(i.secondaryNetworks[network].assigned, pod)
+	id, err := ipv4ToUint32(net.ParseIP(ip))
+	if err != nil {
+		return err
+	}
+	return i.broker.Put(model.SecondaryIPKey(network, pod), &model.AllocatedIP{ID: id, Pod: pod})
+}
+
+func (i *IPAM) deleteSecondaryIP(network string, pod string) error {
+	if i.broker == nil {
+		return nil
+	}
+	_, err := i.broker.Delete(model.SecondaryIPKey(network, pod))
+	return err
+}
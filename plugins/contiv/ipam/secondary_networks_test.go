@@ -0,0 +1,109 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipam_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/contiv/vpp/mock/broker"
+	"github.com/contiv/vpp/plugins/contiv/ipam"
+	"github.com/contiv/vpp/plugins/contiv/ipam/model"
+	"github.com/ligato/cn-infra/logging/logrus"
+	. "github.com/onsi/gomega"
+)
+
+func newSecondaryNetworksConfig() *ipam.Config {
+	cfg := newDefaultConfig()
+	cfg.SecondaryNetworks = []ipam.SecondaryNetworkConfig{
+		{Name: "data", SubnetCIDR: "172.16.0.0/16", NetworkPrefixLen: 24},
+	}
+	return cfg
+}
+
+// TestSecondaryNetworks tests allocation of pod IPs from the pools of the secondary networks.
+func TestSecondaryNetworks(t *testing.T) {
+	RegisterTestingT(t)
+	broker := &broker.MockBroker{}
+	cfg := newSecondaryNetworksConfig()
+
+	i, err := ipam.New(logrus.DefaultLogger(), uint32(hostID1), cfg, broker, nil)
+	Expect(err).To(BeNil())
+	Expect(i.SecondaryNetworks()).To(ConsistOf("data"))
+
+	subnet, err := i.SecondaryPodSubnet("data")
+	Expect(err).To(BeNil())
+	Expect(subnet.String()).To(BeEquivalentTo("172.16.0.0/16"))
+	podNetwork, err := i.SecondaryPodNetwork("data")
+	Expect(err).To(BeNil())
+	Expect(podNetwork.String()).To(BeEquivalentTo("172.16." + str(int(hostID1)) + ".0/24"))
+	gw, err := i.SecondaryPodGatewayIP("data")
+	Expect(err).To(BeNil())
+	Expect(gw).To(BeEquivalentTo(net.IPv4(172, 16, hostID1, 1).To4()))
+	otherNetwork, err := i.SecondaryOtherNodePodNetwork("data", uint32(hostID2))
+	Expect(err).To(BeNil())
+	Expect(otherNetwork.String()).To(BeEquivalentTo("172.16." + str(int(hostID2)) + ".0/24"))
+
+	// the secondary pool is independent of the primary one
+	primaryIP, err := i.NextPodIP("container1")
+	Expect(err).To(BeNil())
+	ip, err := i.NextSecondaryPodIP("data", "container1")
+	Expect(err).To(BeNil())
+	Expect(ip).To(BeEquivalentTo(net.IPv4(172, 16, hostID1, 2).To4()))
+	assignedIP, err := i.SecondaryPodIP("data", "container1")
+	Expect(err).To(BeNil())
+	Expect(assignedIP).To(BeEquivalentTo(ip))
+	Expect(podNetwork.Contains(primaryIP)).To(BeFalse())
+	Expect(broker.Keys()).To(ContainElement(model.SecondaryIPKey("data", "container1")))
+	Expect(i.PodIDs()).To(ConsistOf("container1"))
+	ids, err := i.SecondaryPodIDs("data")
+	Expect(err).To(BeNil())
+	Expect(ids).To(ConsistOf("container1"))
+
+	_, err = i.NextSecondaryPodIP("unknown", "container1")
+	Expect(err).NotTo(BeNil())
+
+	// assigned IPs are loaded by another IPAM instance
+	another, err := ipam.New(logrus.DefaultLogger(), uint32(hostID1), cfg, broker, nil)
+	Expect(err).To(BeNil())
+	nextIP, err := another.NextSecondaryPodIP("data", "container2")
+	Expect(err).To(BeNil())
+	Expect(nextIP).To(BeEquivalentTo(net.IPv4(172, 16, hostID1, 3).To4()))
+
+	// release
+	Expect(another.ReleaseSecondaryPodIP("data", "container1")).To(BeNil())
+	Expect(another.ReleaseSecondaryPodIP("data", "container1")).NotTo(BeNil())
+	Expect(broker.Keys()).NotTo(ContainElement(model.SecondaryIPKey("data", "container1")))
+}
+
+// TestSecondaryNetworksInvalidConfig tests that invalid configuration of secondary networks is rejected.
+func TestSecondaryNetworksInvalidConfig(t *testing.T) {
+	RegisterTestingT(t)
+
+	cfg := newSecondaryNetworksConfig()
+	cfg.SecondaryNetworks = append(cfg.SecondaryNetworks, cfg.SecondaryNetworks[0])
+	_, err := ipam.New(logrus.DefaultLogger(), uint32(hostID1), cfg, nil, nil)
+	Expect(err).NotTo(BeNil())
+
+	cfg = newSecondaryNetworksConfig()
+	cfg.SecondaryNetworks[0].SubnetCIDR = "fd00::/48"
+	_, err = ipam.New(logrus.DefaultLogger(), uint32(hostID1), cfg, nil, nil)
+	Expect(err).NotTo(BeNil())
+
+	cfg = newSecondaryNetworksConfig()
+	cfg.SecondaryNetworks[0].Name = "a/b"
+	_, err = ipam.New(logrus.DefaultLogger(), uint32(hostID1), cfg, nil, nil)
+	Expect(err).NotTo(BeNil())
+}
@@ -0,0 +1,216 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipam
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/contiv/vpp/plugins/contiv/ipam/model"
+)
+
+// AssignPodIP assigns the given (statically requested) IPv4 address to the POD with the id <podID>.
+// Returns an error if the address is not from the POD network(s) of the node, is reserved
+// for the network/gateway or is already assigned to another POD.
+func (i *IPAM) AssignPodIP(podID string, ip net.IP) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	if len(podID) == 0 {
+		return fmt.Errorf("Pod ID can't be empty because it is used to release the assigned IP address")
+	}
+	ip = ip.To4()
+	if ip == nil {
+		return fmt.Errorf("only IPv4 addresses can be statically assigned to pods")
+	}
+	block := i.podCIDRBlockForIP(ip)
+	if block == nil {
+		return fmt.Errorf("IP address %v is not from the pod network of this node", ip)
+	}
+	if ip.Equal(block.network.IP) || ip.Equal(i.podNetworkGatewayIP) {
+		return fmt.Errorf("IP address %v is reserved for the pod network and can't be assigned", ip)
+	}
+	if pod, assigned := i.assignedPodIPs[ip.String()]; assigned {
+		if pod == podID {
+			return nil
+		}
+		return fmt.Errorf("IP address %v is already assigned to pod %v", ip, pod)
+	}
+
+	i.assignedPodIPs[ip.String()] = podID
+	if err := i.saveAssignedIP(podID); err != nil {
+		delete(i.assignedPodIPs, ip.String())
+		return err
+	}
+
+	i.logger.Infof("Assigned requested pod IP %s", ip)
+	i.logAssignedPodIPPool()
+	return nil
+}
+
+// ReservedPodIP returns the IPv4 address reserved in the IPAM configuration under the given name.
+func (i *IPAM) ReservedPodIP(name string) (net.IP, error) {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	for ip, reservation := range i.reservedPodIPs {
+		if reservation == name {
+			return net.ParseIP(ip).To4(), nil
+		}
+	}
+	return nil, fmt.Errorf("pod IP reservation %q is not defined", name)
+}
+
+// StickyPodIPsEnabled returns true if PODs should get their previous IP address back
+// when they are re-created (with the same name) on the node.
+func (i *IPAM) StickyPodIPsEnabled() bool {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	return i.stickyPodIPs != nil
+}
+
+// StickyPodIP returns IPv4 address previously assigned to the POD with the given name (namespace/name),
+// nil if there is none.
+func (i *IPAM) StickyPodIP(podName string) net.IP {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	if ip, found := i.stickyPodIPs[podName]; found {
+		return net.ParseIP(ip).To4()
+	}
+	return nil
+}
+
+// SetStickyPodIP remembers the IPv4 address assigned to the POD with the given name (namespace/name),
+// so that it can be assigned again when the POD is re-created. Records of other PODs with the same
+// address are dropped. Does nothing if sticky POD IPs are not enabled.
+func (i *IPAM) SetStickyPodIP(podName string, ip net.IP) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	if i.stickyPodIPs == nil {
+		return nil
+	}
+
+	for otherPod, otherIP := range i.stickyPodIPs {
+		if otherPod != podName && otherIP == ip.String() {
+			if err := i.deleteStickyIP(otherPod); err != nil {
+				return err
+			}
+			delete(i.stickyPodIPs, otherPod)
+		}
+	}
+	i.stickyPodIPs[podName] = ip.String()
+	return i.saveStickyIP(podName, ip)
+}
+
+// ReleaseStickyPodIP forgets the IPv4 address previously assigned to the POD with the given name
+// (namespace/name). The address is no longer held out of the pool for the POD.
+func (i *IPAM) ReleaseStickyPodIP(podName string) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	if _, found := i.stickyPodIPs[podName]; !found {
+		return nil
+	}
+	if err := i.deleteStickyIP(podName); err != nil {
+		return err
+	}
+	delete(i.stickyPodIPs, podName)
+	return nil
+}
+
+// StickyPods returns the names (namespace/name) of the PODs with a sticky IPv4 address.
+func (i *IPAM) StickyPods() []string {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	var pods []string
+	for podName := range i.stickyPodIPs {
+		pods = append(pods, podName)
+	}
+	return pods
+}
+
+// heldStickyIPs returns the sticky IPv4 addresses which are not assigned to any POD, mapped to the names
+// of the PODs they are sticky for.
+func (i *IPAM) heldStickyIPs() map[string]string {
+	held := make(map[string]string)
+	for podName, ip := range i.stickyPodIPs {
+		if _, assigned := i.assignedPodIPs[ip]; !assigned {
+			held[ip] = podName
+		}
+	}
+	return held
+}
+
+// initializeReservedPodIPs parses the named pod IP reservations from the IPAM config.
+func initializeReservedPodIPs(ipam *IPAM, config *Config) error {
+	ipam.reservedPodIPs = make(map[string]string)
+	for name, ipStr := range config.PodIPReservations {
+		ip := net.ParseIP(ipStr).To4()
+		if ip == nil {
+			return fmt.Errorf("invalid IPv4 address %q of the pod IP reservation %q", ipStr, name)
+		}
+		if other, duplicate := ipam.reservedPodIPs[ip.String()]; duplicate {
+			return fmt.Errorf("IP address %v is reserved by both %q and %q", ip, other, name)
+		}
+		ipam.reservedPodIPs[ip.String()] = name
+	}
+	if config.StickyPodIPs {
+		ipam.stickyPodIPs = make(map[string]string)
+	}
+	return nil
+}
+
+// loadStickyIPs loads persisted sticky POD IPs.
+func (i *IPAM) loadStickyIPs() error {
+	if i.stickyPodIPs == nil || i.broker == nil {
+		return nil
+	}
+
+	it, err := i.broker.ListValues(model.StickyIPKeyPrefix())
+	if err != nil {
+		return err
+	}
+	for {
+		ip := &model.AllocatedIP{}
+		kv, stop := it.GetNext()
+		if stop {
+			break
+		}
+		err = kv.GetValue(ip)
+		if err != nil {
+			return err
+		}
+		i.stickyPodIPs[ip.Pod] = uint32ToIpv4(ip.ID).String()
+	}
+	i.logger.Infof("%v sticky pod IPs were loaded", len(i.stickyPodIPs))
+	return nil
+}
+
+func (i *IPAM) saveStickyIP(podName string, ip net.IP) error {
+	if i.broker == nil {
+		return nil
+	}
+	id, err := ipv4ToUint32(ip)
+	if err != nil {
+		return err
+	}
+	return i.broker.Put(model.StickyIPKey(podName), &model.AllocatedIP{ID: id, Pod: podName})
+}
+
+func (i *IPAM) deleteStickyIP(podName string) error {
+	if i.broker == nil {
+		return nil
+	}
+	_, err := i.broker.Delete(model.StickyIPKey(podName))
+	return err
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipam_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/contiv/vpp/mock/broker"
+	"github.com/contiv/vpp/plugins/contiv/ipam"
+	"github.com/contiv/vpp/plugins/contiv/ipam/model"
+	"github.com/ligato/cn-infra/logging/logrus"
+	. "github.com/onsi/gomega"
+)
+
+// TestAssignPodIP tests assignment of statically requested pod IPs.
+func TestAssignPodIP(t *testing.T) {
+	i := setup(t, newDefaultConfig())
+
+	requested := net.IPv4(1, 2, b10000000+hostID1>>5, (hostID1<<3)+5).To4()
+	Expect(i.AssignPodIP("static", requested)).To(BeNil())
+	Expect(i.AssignPodIP("static", requested)).To(BeNil()) // repeated request of the same pod
+
+	// conflicts
+	Expect(i.AssignPodIP("other", requested)).NotTo(BeNil())
+	Expect(i.AssignPodIP("other", i.PodGatewayIP())).NotTo(BeNil())
+	Expect(i.AssignPodIP("other", expectedPodNetworkZeroEndingIP)).NotTo(BeNil())
+	Expect(i.AssignPodIP("other", net.ParseIP("8.8.8.8"))).NotTo(BeNil())
+
+	// the static IP is skipped by the pool allocation
+	for j := 0; j < 5; j++ {
+		ip, err := i.NextPodIP(str(j))
+		Expect(err).To(BeNil())
+		Expect(ip.Equal(requested)).To(BeFalse())
+	}
+	_, err := i.NextPodIP("exhausted")
+	Expect(err).NotTo(BeNil())
+
+	// released static IP can be assigned again
+	Expect(i.ReleasePodIP("static")).To(BeNil())
+	Expect(i.AssignPodIP("other", requested)).To(BeNil())
+}
+
+// TestPodIPReservations tests that reserved IPs are assigned only by the name of the reservation.
+func TestPodIPReservations(t *testing.T) {
+	reserved := net.IPv4(1, 2, b10000000+hostID1>>5, (hostID1<<3)+2).To4()
+	cfg := newDefaultConfig()
+	cfg.PodIPReservations = map[string]string{"db": reserved.String()}
+	i := setup(t, cfg)
+
+	ip, err := i.ReservedPodIP("db")
+	Expect(err).To(BeNil())
+	Expect(ip).To(BeEquivalentTo(reserved))
+	_, err = i.ReservedPodIP("unknown")
+	Expect(err).NotTo(BeNil())
+
+	// reserved IP is never assigned from the pool
+	allocated, _ := exhaustPodIPAddresses(i, 5)
+	Expect(allocated).NotTo(ContainElement(reserved.String()))
+	_, err = i.NextPodIP("exhausted")
+	Expect(err).NotTo(BeNil())
+
+	Expect(i.AssignPodIP("dbPod", ip)).To(BeNil())
+
+	// invalid reservation
+	cfg.PodIPReservations = map[string]string{"db": "not-an-ip"}
+	_, err = ipam.New(logrus.DefaultLogger(), uint32(hostID1), cfg, nil, nil)
+	Expect(err).NotTo(BeNil())
+}
+
+// TestStickyPodIPs tests that sticky pod IPs are persisted and that an IP is sticky for one pod only.
+func TestStickyPodIPs(t *testing.T) {
+	RegisterTestingT(t)
+	broker := &broker.MockBroker{}
+	cfg := newDefaultConfig()
+	cfg.StickyPodIPs = true
+
+	i, err := ipam.New(logrus.DefaultLogger(), uint32(hostID1), cfg, broker, nil)
+	Expect(err).To(BeNil())
+	Expect(i.StickyPodIPsEnabled()).To(BeTrue())
+
+	ip, err := i.NextPodIP("container1")
+	Expect(err).To(BeNil())
+	Expect(i.SetStickyPodIP("default/web-0", ip)).To(BeNil())
+	Expect(broker.Keys()).To(ContainElement(model.StickyIPKey("default/web-0")))
+	Expect(i.ReleasePodIP("container1")).To(BeNil())
+
+	// sticky IPs are loaded by another IPAM instance
+	another, err := ipam.New(logrus.DefaultLogger(), uint32(hostID1), cfg, broker, nil)
+	Expect(err).To(BeNil())
+	Expect(another.StickyPodIP("default/web-0")).To(BeEquivalentTo(ip))
+	Expect(another.AssignPodIP("container2", ip)).To(BeNil())
+
+	// the IP taken by another pod is no longer sticky for the original one
+	Expect(another.SetStickyPodIP("default/web-1", ip)).To(BeNil())
+	Expect(another.StickyPodIP("default/web-0")).To(BeNil())
+	Expect(broker.Keys()).NotTo(ContainElement(model.StickyIPKey("default/web-0")))
+	Expect(another.StickyPods()).To(ConsistOf("default/web-1"))
+
+	// released sticky IP is forgotten
+	Expect(another.ReleaseStickyPodIP("default/web-1")).To(BeNil())
+	Expect(another.StickyPodIP("default/web-1")).To(BeNil())
+	Expect(another.StickyPods()).To(BeEmpty())
+	Expect(broker.Keys()).NotTo(ContainElement(model.StickyIPKey("default/web-1")))
+	Expect(another.ReleaseStickyPodIP("default/web-1")).To(BeNil())
+
+	// sticky IPs are disabled by default
+	Expect(setup(t, newDefaultConfig()).StickyPodIPsEnabled()).To(BeFalse())
+}
+
+// TestStickyPodIPsHeld tests that sticky pod IPs are not assigned to other pods until the pool is exhausted.
+func TestStickyPodIPsHeld(t *testing.T) {
+	cfg := newDefaultConfig()
+	cfg.StickyPodIPs = true
+	i := setup(t, cfg)
+
+	sticky, err := i.NextPodIP("container0")
+	Expect(err).To(BeNil())
+	Expect(i.SetStickyPodIP("default/web-0", sticky)).To(BeNil())
+	Expect(i.ReleasePodIP("container0")).To(BeNil())
+
+	// 5 of 6 free IP addresses are assigned without the sticky one
+	for j := 1; j <= 5; j++ {
+		ip, err := i.NextPodIP("container" + str(j))
+		Expect(err).To(BeNil())
+		Expect(ip).NotTo(BeEquivalentTo(sticky))
+	}
+
+	// the sticky IP address is assigned only when there is no other
+	ip, err := i.NextPodIP("container6")
+	Expect(err).To(BeNil())
+	Expect(ip).To(BeEquivalentTo(sticky))
+	_, err = i.NextPodIP("container7")
+	Expect(err).NotTo(BeNil())
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contiv
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+
+	"github.com/contiv/vpp/flavors/ksr"
+	"github.com/contiv/vpp/plugins/contiv/model/node"
+	"github.com/ligato/cn-infra/db/keyval/etcdv3"
+	"github.com/ligato/cn-infra/servicelabel"
+)
+
+const (
+	// IPSecKeysKeyPrefix is a key prefix used in ETCD to store the generations of the cluster secret
+	// the IPsec keys are derived from.
+	IPSecKeysKeyPrefix = "ipsecKeys/"
+
+	// ipsecSecretLen is the length of the generated cluster secret in bytes
+	ipsecSecretLen = 32
+)
+
+// IPSecConfig is the configuration of the IPsec protection of the VXLAN traffic between the nodes.
+type IPSecConfig struct {
+	Enabled bool
+	Mode    string // "transport" (default) or "tunnel"
+}
+
+// ipsecKeyKey returns the ETCD key of the given generation of the cluster secret.
+func ipsecKeyKey(generation uint32) string {
+	return IPSecKeysKeyPrefix + strconv.FormatUint(uint64(generation), 10)
+}
+
+// createIPSecKeyIfNotExists stores the first generation of the cluster secret into ETCD, unless some
+// generation already exists. The secret is generated randomly by the first node with IPsec enabled.
+func createIPSecKeyIfNotExists(etcd *etcdv3.Plugin) error {
+	prefix := servicelabel.GetDifferentAgentPrefix(ksr.MicroserviceLabel)
+	it, err := etcd.NewBroker(prefix).ListKeys(IPSecKeysKeyPrefix)
+	if err != nil {
+		return err
+	}
+	if _, _, stop := it.GetNext(); !stop {
+		return nil
+	}
+
+	key := &node.IPSecKey{
+		Generation: 1,
+		Secret:     make([]byte, ipsecSecretLen),
+	}
+	if _, err := rand.Read(key.Secret); err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+	_, err = etcd.PutIfNotExists(prefix+ipsecKeyKey(key.Generation), encoded)
+	return err
+}
+
+// sortIPSecKeys sorts the generations of the cluster secret in ascending order.
+func sortIPSecKeys(keys []*node.IPSecKey) {
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Generation < keys[j].Generation })
+}
+
+// WatchIPSecKeyGeneration adds given channel to the list of subscribers that are notified upon change
+// of the newest IPsec key generation accepted by this node. The channel must be buffered,
+// a notification not yet received is replaced by the newer one.
+func (s *remoteCNIserver) WatchIPSecKeyGeneration(subscriber chan uint32) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.ipsecKeyGenerationSubscribers = append(s.ipsecKeyGenerationSubscribers, subscriber)
+}
+
+// configureIPSec applies the base IPsec configuration once the node IP is known (otherwise it is applied
+// later by setNodeIP). The method must be called with the CNI server lock held.
+func (s *remoteCNIserver) configureIPSec() error {
+	if s.ipsec == nil || s.ipsec.configured || s.nodeIP == "" || !s.vswitchConnectivityConfigured {
+		return nil
+	}
+	err := s.ipsec.configure(s.mainPhysicalIf, net.ParseIP(s.ipPrefixToAddress(s.nodeIP)))
+	if err != nil {
+		return fmt.Errorf("can't configure IPsec: %v", err)
+	}
+	s.announceIPSecKeyGeneration()
+	return nil
+}
+
+// updateIPSecKeys adds the generations of the cluster secret and announces the newest generation accepted
+// by this node once the inbound security associations are configured.
+// The method must be called with the CNI server lock held.
+func (s *remoteCNIserver) updateIPSecKeys(keys ...*node.IPSecKey) error {
+	if s.ipsec == nil {
+		return nil
+	}
+	sortIPSecKeys(keys)
+	for _, key := range keys {
+		if err := s.ipsec.addKey(key.Generation, key.Secret); err != nil {
+			return err
+		}
+	}
+	if err := s.configureIPSec(); err != nil {
+		return err
+	}
+	s.announceIPSecKeyGeneration()
+	return nil
+}
+
+// updateIPSecPeer configures the security associations with the given node.
+// The method must be called with the CNI server lock held.
+func (s *remoteCNIserver) updateIPSecPeer(nodeInfo *node.NodeInfo) error {
+	if s.ipsec == nil {
+		return nil
+	}
+	hostIP := net.ParseIP(s.otherHostIP(nodeInfo.Id, nodeInfo.IpAddress))
+	err := s.ipsec.updatePeer(nodeInfo.Id, hostIP, nodeInfo.IpsecKeyGeneration)
+	if err != nil {
+		return fmt.Errorf("can't configure IPsec with the node %v: %v", nodeInfo.Id, err)
+	}
+	return s.configureIPSec()
+}
+
+// removeIPSecPeer removes the security associations with the given node.
+// The method must be called with the CNI server lock held.
+func (s *remoteCNIserver) removeIPSecPeer(nodeInfo *node.NodeInfo) error {
+	if s.ipsec == nil {
+		return nil
+	}
+	return s.ipsec.removePeer(nodeInfo.Id)
+}
+
+// announceIPSecKeyGeneration notifies the subscribers about a change of the newest accepted key generation.
+func (s *remoteCNIserver) announceIPSecKeyGeneration() {
+	generation := s.ipsec.acceptedGeneration()
+	if generation == s.announcedIPSecKeyGeneration {
+		return
+	}
+	s.announcedIPSecKeyGeneration = generation
+	for _, sub := range s.ipsecKeyGenerationSubscribers {
+		// replace the notification not yet received
+		select {
+		case <-sub:
+		default:
+		}
+		select {
+		case sub <- generation:
+		default:
+		}
+	}
+}
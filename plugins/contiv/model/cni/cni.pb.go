@@ -356,6 +356,8 @@ type RemoteCNIClient interface {
 	Add(ctx context.Context, in *CNIRequest, opts ...grpc.CallOption) (*CNIReply, error)
 	// The request to delete a container from network.
 	Delete(ctx context.Context, in *CNIRequest, opts ...grpc.CallOption) (*CNIReply, error)
+	// The request to check that a container is still connected to network as expected.
+	Check(ctx context.Context, in *CNIRequest, opts ...grpc.CallOption) (*CNIReply, error)
 }
 
 type remoteCNIClient struct {
@@ -384,6 +386,15 @@ func (c *remoteCNIClient) Delete(ctx context.Context, in *CNIRequest, opts ...gr
 	return out, nil
 }
 
+func (c *remoteCNIClient) Check(ctx context.Context, in *CNIRequest, opts ...grpc.CallOption) (*CNIReply, error) {
+	out := new(CNIReply)
+	err := grpc.Invoke(ctx, "/cni.RemoteCNI/Check", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for RemoteCNI service
 
 type RemoteCNIServer interface {
@@ -391,6 +402,8 @@ type RemoteCNIServer interface {
 	Add(context.Context, *CNIRequest) (*CNIReply, error)
 	// The request to delete a container from network.
 	Delete(context.Context, *CNIRequest) (*CNIReply, error)
+	// The request to check that a container is still connected to network as expected.
+	Check(context.Context, *CNIRequest) (*CNIReply, error)
 }
 
 func RegisterRemoteCNIServer(s *grpc.Server, srv RemoteCNIServer) {
@@ -433,6 +446,24 @@ func _RemoteCNI_Delete_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RemoteCNI_Check_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CNIRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RemoteCNIServer).Check(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/cni.RemoteCNI/Check",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RemoteCNIServer).Check(ctx, req.(*CNIRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _RemoteCNI_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "cni.RemoteCNI",
 	HandlerType: (*RemoteCNIServer)(nil),
@@ -445,6 +476,10 @@ var _RemoteCNI_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Delete",
 			Handler:    _RemoteCNI_Delete_Handler,
 		},
+		{
+			MethodName: "Check",
+			Handler:    _RemoteCNI_Check_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "cni.proto",
@@ -453,39 +488,40 @@ var _RemoteCNI_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("cni.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 541 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x53, 0xcd, 0x6e, 0x13, 0x31,
-	0x10, 0x6e, 0x76, 0xf3, 0xd3, 0x4c, 0x9a, 0x34, 0x35, 0x08, 0xac, 0x95, 0x90, 0x42, 0x10, 0xb4,
-	0x50, 0x29, 0x87, 0x80, 0xe0, 0x02, 0x87, 0x28, 0xb9, 0xec, 0x65, 0x15, 0x6d, 0xa5, 0x5e, 0x83,
-	0xbb, 0x3b, 0x0d, 0x2b, 0x12, 0x7b, 0x6b, 0x3b, 0x6c, 0xfb, 0x02, 0xbc, 0x01, 0x2f, 0xc0, 0x03,
-	0xf2, 0x0c, 0xc8, 0x8e, 0x9d, 0x36, 0x87, 0xaa, 0xb7, 0xf9, 0xbe, 0xf9, 0xec, 0xfd, 0xe6, 0xf3,
-	0x2c, 0xb4, 0x33, 0x5e, 0x8c, 0x4a, 0x29, 0xb4, 0x20, 0x61, 0xc6, 0x8b, 0xe1, 0xbf, 0x1a, 0xc0,
-	0x34, 0x89, 0x53, 0xbc, 0xd9, 0xa0, 0xd2, 0x84, 0x42, 0xeb, 0x17, 0x4a, 0x55, 0x08, 0x4e, 0x6b,
-	0x83, 0xda, 0x59, 0x3b, 0xf5, 0x90, 0xbc, 0x86, 0xa3, 0x4c, 0x70, 0xcd, 0x0a, 0x8e, 0x72, 0x51,
-	0xe4, 0x34, 0xb0, 0xed, 0xce, 0x8e, 0x8b, 0x73, 0x72, 0x0e, 0x27, 0x1c, 0x75, 0x25, 0xe4, 0xcf,
-	0x05, 0x67, 0x6b, 0x54, 0x25, 0xcb, 0x90, 0x86, 0x56, 0xd7, 0x77, 0x8d, 0xc4, 0xf3, 0xe4, 0x2d,
-	0xf4, 0x0a, 0xae, 0x51, 0x5e, 0xb3, 0x0c, 0xad, 0x9c, 0xd6, 0xad, 0xb2, 0xbb, 0x63, 0x8d, 0x96,
-	0xbc, 0x83, 0x63, 0xbc, 0xd5, 0x92, 0x2d, 0x78, 0xb5, 0xc8, 0x04, 0xbf, 0x2e, 0x96, 0xb4, 0xb1,
-	0xd5, 0x59, 0x3a, 0xa9, 0xa6, 0x96, 0x24, 0xa7, 0x5e, 0xc7, 0xe4, 0x72, 0xb3, 0x46, 0xae, 0x15,
-	0x6d, 0x5a, 0x5d, 0xcf, 0xd2, 0x13, 0xcf, 0x0e, 0x7f, 0x37, 0xe0, 0xd0, 0x0e, 0x5c, 0xae, 0xee,
-	0xc8, 0x0b, 0x68, 0x4a, 0x54, 0x9b, 0x95, 0xb6, 0xd3, 0x76, 0x53, 0x87, 0xc8, 0x73, 0x68, 0xa0,
-	0x94, 0x42, 0xba, 0x29, 0xb7, 0x80, 0x7c, 0x01, 0xd8, 0x99, 0x53, 0xb4, 0x3e, 0x08, 0xcf, 0x3a,
-	0xe3, 0x97, 0x23, 0x13, 0xa8, 0xbf, 0x70, 0x14, 0xfb, 0x7e, 0xfa, 0x40, 0x4a, 0xce, 0xa1, 0x29,
-	0xc5, 0x46, 0xa3, 0xa2, 0x0d, 0x7b, 0xe8, 0xd9, 0xfe, 0xa1, 0xd4, 0xf4, 0x52, 0x27, 0x21, 0x6f,
-	0x20, 0xcc, 0xb9, 0x71, 0x6f, 0x94, 0x27, 0xfb, 0xca, 0x59, 0x72, 0x91, 0x9a, 0x6e, 0xf4, 0x37,
-	0x80, 0xf6, 0xee, 0x5b, 0x84, 0x40, 0xdd, 0x26, 0xb8, 0x7d, 0x32, 0x5b, 0x93, 0x3e, 0x84, 0x6b,
-	0x96, 0xb9, 0x01, 0x4c, 0x69, 0xde, 0x56, 0x31, 0x9e, 0x5f, 0x89, 0x5b, 0xf7, 0x28, 0x1e, 0x92,
-	0x6f, 0x70, 0x54, 0x94, 0x0b, 0x96, 0xe7, 0x12, 0x95, 0xda, 0x8d, 0x16, 0x3d, 0x32, 0xda, 0x28,
-	0x9e, 0xa7, 0x9d, 0xa2, 0x9c, 0x78, 0x79, 0xf4, 0xa7, 0x06, 0x41, 0x3c, 0x27, 0x5f, 0xf7, 0x77,
-	0xa7, 0x37, 0x1e, 0x3e, 0x7e, 0xc1, 0xe8, 0x72, 0xab, 0xbc, 0xdf, 0x2f, 0x0a, 0x2d, 0x67, 0xc0,
-	0x79, 0xf6, 0xd0, 0x74, 0x96, 0x4c, 0x63, 0xc5, 0xee, 0xbc, 0x6f, 0x07, 0x87, 0xaf, 0xa0, 0xe5,
-	0xee, 0x21, 0x87, 0x50, 0x8f, 0xe7, 0x97, 0x9f, 0xfa, 0x07, 0xae, 0xfa, 0xdc, 0xaf, 0x45, 0xef,
-	0xa1, 0x61, 0xa3, 0x35, 0x59, 0xe4, 0x4a, 0xbb, 0x78, 0x4c, 0x49, 0x7a, 0x10, 0x2c, 0x2b, 0xf7,
-	0xa1, 0x60, 0x59, 0x45, 0x37, 0x10, 0xce, 0x92, 0x0b, 0xb3, 0x0f, 0xb9, 0x58, 0xb3, 0xc2, 0x6f,
-	0xbf, 0x43, 0x64, 0x00, 0x1d, 0xbb, 0xd1, 0x28, 0x8d, 0x5d, 0x1a, 0x0c, 0x42, 0xb3, 0xfb, 0x0f,
-	0x28, 0x73, 0x52, 0x21, 0x93, 0xd9, 0x0f, 0x1a, 0xda, 0xa6, 0x43, 0xc6, 0xbc, 0x28, 0x75, 0x21,
-	0xf8, 0x36, 0xd5, 0x76, 0xea, 0xe1, 0xf8, 0x3b, 0xb4, 0x53, 0x5c, 0x0b, 0x8d, 0xd3, 0x24, 0x26,
-	0xa7, 0x10, 0x4e, 0xf2, 0x9c, 0x1c, 0xdf, 0x27, 0x66, 0xff, 0xc7, 0xa8, 0xbb, 0x17, 0xe1, 0xf0,
-	0x80, 0x7c, 0x80, 0xe6, 0x0c, 0x57, 0xa8, 0xf1, 0x69, 0xed, 0x55, 0xd3, 0xfe, 0xe7, 0x1f, 0xff,
-	0x07, 0x00, 0x00, 0xff, 0xff, 0x3a, 0x51, 0x47, 0xbd, 0xf4, 0x03, 0x00, 0x00,
+	// 551 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x94, 0xc1, 0x4e, 0x1b, 0x3d,
+	0x10, 0xc7, 0xd9, 0xdd, 0x6c, 0x20, 0x13, 0x08, 0xc1, 0xdf, 0xa7, 0xd6, 0x5a, 0xa9, 0x52, 0x9a,
+	0xaa, 0x05, 0x8a, 0x94, 0x03, 0xad, 0xda, 0x4b, 0x7b, 0x40, 0xe1, 0xb2, 0x97, 0x55, 0xb4, 0x48,
+	0x5c, 0x23, 0xb3, 0x3b, 0x84, 0x15, 0x89, 0xbd, 0xd8, 0x4e, 0x17, 0x5e, 0xa0, 0x97, 0x9e, 0xfb,
+	0x02, 0x7d, 0xc0, 0x3e, 0x43, 0x65, 0xc7, 0x0e, 0xe4, 0x80, 0xda, 0xdb, 0xfc, 0x67, 0x7e, 0xf6,
+	0xce, 0xfc, 0x3d, 0x09, 0x74, 0x0a, 0x5e, 0x8d, 0x6a, 0x29, 0xb4, 0x20, 0x51, 0xc1, 0xab, 0xe1,
+	0xef, 0x00, 0x60, 0x9c, 0xa5, 0x39, 0xde, 0x2d, 0x51, 0x69, 0x42, 0x61, 0xfb, 0x1b, 0x4a, 0x55,
+	0x09, 0x4e, 0x83, 0x41, 0x70, 0xd4, 0xc9, 0xbd, 0x24, 0xaf, 0x61, 0xb7, 0x10, 0x5c, 0xb3, 0x8a,
+	0xa3, 0x9c, 0x56, 0x25, 0x0d, 0x6d, 0xb9, 0xbb, 0xce, 0xa5, 0x25, 0x39, 0x81, 0x03, 0x8e, 0xba,
+	0x11, 0xf2, 0x76, 0xca, 0xd9, 0x02, 0x55, 0xcd, 0x0a, 0xa4, 0x91, 0xe5, 0xfa, 0xae, 0x90, 0xf9,
+	0x3c, 0x79, 0x0b, 0xbd, 0x8a, 0x6b, 0x94, 0xd7, 0xac, 0x40, 0x8b, 0xd3, 0x96, 0x25, 0xf7, 0xd6,
+	0x59, 0xc3, 0x92, 0x77, 0xb0, 0x8f, 0xf7, 0x5a, 0xb2, 0x29, 0x6f, 0xa6, 0x85, 0xe0, 0xd7, 0xd5,
+	0x8c, 0xc6, 0x2b, 0xce, 0xa6, 0xb3, 0x66, 0x6c, 0x93, 0xe4, 0xd0, 0x73, 0x4c, 0xce, 0x96, 0x0b,
+	0xe4, 0x5a, 0xd1, 0xb6, 0xe5, 0x7a, 0x36, 0x7d, 0xe6, 0xb3, 0xc3, 0xef, 0x31, 0xec, 0xd8, 0x81,
+	0xeb, 0xf9, 0x03, 0x79, 0x01, 0x6d, 0x89, 0x6a, 0x39, 0xd7, 0x76, 0xda, 0xbd, 0xdc, 0x29, 0xf2,
+	0x3f, 0xc4, 0x28, 0xa5, 0x90, 0x6e, 0xca, 0x95, 0x20, 0x9f, 0x01, 0xd6, 0xcd, 0x29, 0xda, 0x1a,
+	0x44, 0x47, 0xdd, 0xd3, 0x97, 0x23, 0x63, 0xa8, 0xbf, 0x70, 0x94, 0xfa, 0x7a, 0xfe, 0x04, 0x25,
+	0x27, 0xd0, 0x96, 0x62, 0xa9, 0x51, 0xd1, 0xd8, 0x1e, 0xfa, 0x6f, 0xf3, 0x50, 0x6e, 0x6a, 0xb9,
+	0x43, 0xc8, 0x1b, 0x88, 0x4a, 0x6e, 0xba, 0x37, 0xe4, 0xc1, 0x26, 0x79, 0x9e, 0x5d, 0xe4, 0xa6,
+	0x9a, 0xfc, 0x0a, 0xa1, 0xb3, 0xfe, 0x16, 0x21, 0xd0, 0xb2, 0x0e, 0xae, 0x9e, 0xcc, 0xc6, 0xa4,
+	0x0f, 0xd1, 0x82, 0x15, 0x6e, 0x00, 0x13, 0x9a, 0xb7, 0x55, 0x8c, 0x97, 0x57, 0xe2, 0xde, 0x3d,
+	0x8a, 0x97, 0xe4, 0x2b, 0xec, 0x56, 0xf5, 0x94, 0x95, 0xa5, 0x44, 0xa5, 0xd6, 0xa3, 0x25, 0xcf,
+	0x8c, 0x36, 0x4a, 0x27, 0x79, 0xb7, 0xaa, 0xcf, 0x3c, 0x9e, 0xfc, 0x0c, 0x20, 0x4c, 0x27, 0xe4,
+	0xcb, 0xe6, 0xee, 0xf4, 0x4e, 0x87, 0xcf, 0x5f, 0x30, 0xba, 0x5c, 0x91, 0x8f, 0xfb, 0x45, 0x61,
+	0xdb, 0x35, 0xe0, 0x7a, 0xf6, 0xd2, 0x54, 0x66, 0x4c, 0x63, 0xc3, 0x1e, 0x7c, 0xdf, 0x4e, 0x0e,
+	0x5f, 0xc1, 0xb6, 0xbb, 0x87, 0xec, 0x40, 0x2b, 0x9d, 0x5c, 0x7e, 0xec, 0x6f, 0xb9, 0xe8, 0x53,
+	0x3f, 0x48, 0x8e, 0x21, 0xb6, 0xd6, 0x1a, 0x2f, 0x4a, 0xa5, 0x9d, 0x3d, 0x26, 0x24, 0x3d, 0x08,
+	0x67, 0x8d, 0xfb, 0x50, 0x38, 0x6b, 0x92, 0x3b, 0x88, 0xce, 0xb3, 0x0b, 0xb3, 0x0f, 0xa5, 0x58,
+	0xb0, 0xca, 0x6f, 0xbf, 0x53, 0x64, 0x00, 0x5d, 0xbb, 0xd1, 0x28, 0x4d, 0xbb, 0x34, 0x1c, 0x44,
+	0x66, 0xf7, 0x9f, 0xa4, 0xcc, 0x49, 0x85, 0x4c, 0x16, 0x37, 0x34, 0xb2, 0x45, 0xa7, 0x4c, 0xf3,
+	0xa2, 0xd6, 0x95, 0xe0, 0x2b, 0x57, 0x3b, 0xb9, 0x97, 0xa7, 0x3f, 0x02, 0xe8, 0xe4, 0xb8, 0x10,
+	0x1a, 0xc7, 0x59, 0x4a, 0x0e, 0x21, 0x3a, 0x2b, 0x4b, 0xb2, 0xff, 0x68, 0x99, 0xfd, 0x41, 0x26,
+	0x7b, 0x1b, 0x1e, 0x0e, 0xb7, 0xc8, 0x7b, 0x68, 0x9f, 0xe3, 0x1c, 0x35, 0xfe, 0x03, 0x7b, 0x0c,
+	0xf1, 0xf8, 0x06, 0x8b, 0xdb, 0xbf, 0xa3, 0x57, 0x6d, 0xfb, 0x9f, 0xf0, 0xe1, 0xcf, 0x00, 0xc1,
+	0x13, 0xe1, 0x20, 0x20, 0x04, 0x00, 0x00,
 }
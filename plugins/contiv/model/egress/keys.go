@@ -0,0 +1,59 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package egress
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PolicyKeyPrefix returns prefix where all egress policies are persisted.
+func PolicyKeyPrefix() string {
+	return "egressPolicies/"
+}
+
+// PolicyKey returns the key for the egress policy with the given namespace and name.
+func PolicyKey(namespace string, name string) string {
+	return PolicyKeyPrefix() + namespace + "/" + name
+}
+
+// ParsePolicyFromKey parses the namespace and the name of the egress policy from its key.
+func ParsePolicyFromKey(key string) (namespace string, name string, err error) {
+	parts := strings.Split(strings.TrimPrefix(key, PolicyKeyPrefix()), "/")
+	if !strings.HasPrefix(key, PolicyKeyPrefix()) || len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid egress policy key %q", key)
+	}
+	return parts[0], parts[1], nil
+}
+
+// IPKeyPrefix returns prefix where the egress IP addresses assigned to the policies are persisted.
+func IPKeyPrefix() string {
+	return "egressIPs/"
+}
+
+// IPKey returns the key for the given egress IP address.
+func IPKey(address string) string {
+	return IPKeyPrefix() + address
+}
+
+// GatewayKeyPrefix returns prefix where the live gateway nodes are announced.
+func GatewayKeyPrefix() string {
+	return "egressGateways/"
+}
+
+// GatewayKey returns the key announcing the gateway node with the given name.
+func GatewayKey(nodeName string) string {
+	return GatewayKeyPrefix() + nodeName
+}
@@ -0,0 +1,26 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import "strconv"
+
+// AllocatedIDsKeyPrefix is a key prefix used in ETCD to store information
+// about node ID and its IP addresses.
+const AllocatedIDsKeyPrefix = "allocatedIDs/"
+
+// AllocatedIDKey returns the key under which the information about the node with the given ID is stored.
+func AllocatedIDKey(id uint32) string {
+	return AllocatedIDsKeyPrefix + strconv.FormatUint(uint64(id), 10)
+}
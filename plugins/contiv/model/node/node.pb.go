@@ -9,6 +9,7 @@ It is generated from these files:
 
 It has these top-level messages:
 	NodeInfo
+	IPSecKey
 */
 package node
 
@@ -35,6 +36,9 @@ type NodeInfo struct {
 	Name                string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
 	IpAddress           string `protobuf:"bytes,3,opt,name=ip_address,json=ipAddress" json:"ip_address,omitempty"`
 	ManagementIpAddress string `protobuf:"bytes,4,opt,name=management_ip_address,json=managementIpAddress" json:"management_ip_address,omitempty"`
+	// generation of the newest IPsec key the node accepts for the inbound traffic
+	// (the other nodes use it to encrypt the traffic sent to the node)
+	IpsecKeyGeneration uint32 `protobuf:"varint,5,opt,name=ipsec_key_generation,json=ipsecKeyGeneration" json:"ipsec_key_generation,omitempty"`
 }
 
 func (m *NodeInfo) Reset()                    { *m = NodeInfo{} }
@@ -70,21 +74,59 @@ func (m *NodeInfo) GetManagementIpAddress() string {
 	return ""
 }
 
+func (m *NodeInfo) GetIpsecKeyGeneration() uint32 {
+	if m != nil {
+		return m.IpsecKeyGeneration
+	}
+	return 0
+}
+
+// IPSecKey is a generation of the cluster secret the keys of the IPsec
+// security associations between the nodes are derived from.
+type IPSecKey struct {
+	Generation uint32 `protobuf:"varint,1,opt,name=generation" json:"generation,omitempty"`
+	Secret     []byte `protobuf:"bytes,2,opt,name=secret,proto3" json:"secret,omitempty"`
+}
+
+func (m *IPSecKey) Reset()                    { *m = IPSecKey{} }
+func (m *IPSecKey) String() string            { return proto.CompactTextString(m) }
+func (*IPSecKey) ProtoMessage()               {}
+func (*IPSecKey) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *IPSecKey) GetGeneration() uint32 {
+	if m != nil {
+		return m.Generation
+	}
+	return 0
+}
+
+func (m *IPSecKey) GetSecret() []byte {
+	if m != nil {
+		return m.Secret
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*NodeInfo)(nil), "node.NodeInfo")
+	proto.RegisterType((*IPSecKey)(nil), "node.IPSecKey")
 }
 
 func init() { proto.RegisterFile("node.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 141 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0xca, 0xcb, 0x4f, 0x49,
-	0xd5, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x01, 0xb1, 0x95, 0x1a, 0x19, 0xb9, 0x38, 0xfc,
-	0xf2, 0x53, 0x52, 0x3d, 0xf3, 0xd2, 0xf2, 0x85, 0xf8, 0xb8, 0x98, 0x32, 0x53, 0x24, 0x18, 0x15,
-	0x18, 0x35, 0x78, 0x83, 0x98, 0x32, 0x53, 0x84, 0x84, 0xb8, 0x58, 0xf2, 0x12, 0x73, 0x53, 0x25,
-	0x98, 0x14, 0x18, 0x35, 0x38, 0x83, 0xc0, 0x6c, 0x21, 0x59, 0x2e, 0xae, 0xcc, 0x82, 0xf8, 0xc4,
-	0x94, 0x94, 0xa2, 0xd4, 0xe2, 0x62, 0x09, 0x66, 0xb0, 0x0c, 0x67, 0x66, 0x81, 0x23, 0x44, 0x40,
-	0xc8, 0x88, 0x4b, 0x34, 0x37, 0x31, 0x2f, 0x31, 0x3d, 0x35, 0x37, 0x35, 0xaf, 0x24, 0x1e, 0x49,
-	0x25, 0x0b, 0x58, 0xa5, 0x30, 0x42, 0xd2, 0x13, 0xa6, 0x27, 0x89, 0x0d, 0xec, 0x20, 0x63, 0x40,
-	0x00, 0x00, 0x00, 0xff, 0xff, 0xfd, 0x56, 0x0e, 0xe6, 0x9e, 0x00, 0x00, 0x00,
+	// 205 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x4c, 0x90, 0xcd, 0x4a, 0xc7, 0x30,
+	0x10, 0xc4, 0x49, 0xad, 0x7f, 0xda, 0x45, 0x3d, 0xac, 0x1f, 0xe4, 0xa2, 0x94, 0x9e, 0x7a, 0x12,
+	0xd1, 0x27, 0xd0, 0x8b, 0x14, 0x41, 0xa4, 0x3e, 0x40, 0x88, 0xcd, 0x5a, 0x82, 0x34, 0x09, 0x49,
+	0x2e, 0x7d, 0x2e, 0x5f, 0x50, 0xdc, 0x4a, 0xed, 0x6d, 0x32, 0xbf, 0x19, 0x98, 0x2c, 0x80, 0xf3,
+	0x86, 0x6e, 0x43, 0xf4, 0xd9, 0x63, 0xf9, 0xab, 0xdb, 0x6f, 0x01, 0xd5, 0xab, 0x37, 0xd4, 0xbb,
+	0x4f, 0x8f, 0x67, 0x50, 0x58, 0x23, 0x45, 0x23, 0xba, 0xd3, 0xa1, 0xb0, 0x06, 0x11, 0x4a, 0xa7,
+	0x67, 0x92, 0x45, 0x23, 0xba, 0x7a, 0x60, 0x8d, 0xd7, 0x00, 0x36, 0x28, 0x6d, 0x4c, 0xa4, 0x94,
+	0xe4, 0x11, 0x93, 0xda, 0x86, 0xc7, 0xd5, 0xc0, 0x7b, 0xb8, 0x9c, 0xb5, 0xd3, 0x13, 0xcd, 0xe4,
+	0xb2, 0xda, 0x25, 0x4b, 0x4e, 0x9e, 0xff, 0xc3, 0x7e, 0xeb, 0xdc, 0xc1, 0x85, 0x0d, 0x89, 0x46,
+	0xf5, 0x45, 0x8b, 0x9a, 0xc8, 0x51, 0xd4, 0xd9, 0x7a, 0x27, 0x8f, 0x79, 0x08, 0x32, 0x7b, 0xa1,
+	0xe5, 0x79, 0x23, 0xed, 0x13, 0x54, 0xfd, 0xdb, 0x3b, 0xbb, 0x78, 0x03, 0xb0, 0xeb, 0xac, 0xe3,
+	0x77, 0x0e, 0x5e, 0xc1, 0x21, 0xd1, 0x18, 0x29, 0xf3, 0x37, 0x4e, 0x86, 0xbf, 0xd7, 0xc7, 0x81,
+	0xcf, 0xf0, 0xf0, 0x33, 0x00, 0x4a, 0x74, 0xcf, 0x2a, 0x14, 0x01, 0x00, 0x00,
 }
@@ -0,0 +1,39 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodeconfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+// KeyPrefix returns prefix where the configuration of all nodes is persisted.
+func KeyPrefix() string {
+	return "nodeConfig/"
+}
+
+// Key returns the key for the configuration of the node with the given name.
+func Key(nodeName string) string {
+	return KeyPrefix() + nodeName
+}
+
+// ParseNodeNameFromKey parses the name of the node from the key of its configuration.
+func ParseNodeNameFromKey(key string) (nodeName string, err error) {
+	nodeName = strings.TrimPrefix(key, KeyPrefix())
+	if !strings.HasPrefix(key, KeyPrefix()) || nodeName == "" || strings.Contains(nodeName, "/") {
+		return "", fmt.Errorf("invalid node config key %q", key)
+	}
+	return nodeName, nil
+}
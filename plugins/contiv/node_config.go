@@ -0,0 +1,242 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contiv
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+
+	"github.com/contiv/vpp/plugins/contiv/model/nodeconfig"
+	"github.com/gogo/protobuf/proto"
+	vpp_intf "github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/interfaces"
+	vpp_l3 "github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/l3"
+)
+
+// nodeConfigFromProto converts the configuration of the node stored in ETCD into OneNodeConfig.
+func nodeConfigFromProto(config *nodeconfig.NodeConfig) *OneNodeConfig {
+	nodeConfig := &OneNodeConfig{
+		NodeName:           config.NodeName,
+		StealInterface:     config.StealInterface,
+		Gateway:            config.Gateway,
+		NatExternalTraffic: config.NatExternalTraffic,
+	}
+	if config.MainVppInterface != nil {
+		nodeConfig.MainVPPInterface = InterfaceWithIP{
+			InterfaceName: config.MainVppInterface.InterfaceName,
+			IP:            config.MainVppInterface.Ip,
+			UseDHCP:       config.MainVppInterface.UseDhcp,
+		}
+	}
+	for _, intf := range config.OtherVppInterfaces {
+		nodeConfig.OtherVPPInterfaces = append(nodeConfig.OtherVPPInterfaces, InterfaceWithIP{
+			InterfaceName: intf.InterfaceName,
+			IP:            intf.Ip,
+			UseDHCP:       intf.UseDhcp,
+		})
+	}
+	for _, uplink := range config.Uplinks {
+		nodeConfig.Uplinks = append(nodeConfig.Uplinks, UplinkConfig{
+			InterfaceName: uplink.InterfaceName,
+			IP:            uplink.Ip,
+			Gateway:       uplink.Gateway,
+		})
+	}
+	return nodeConfig
+}
+
+// fileNodeConfig returns the configuration of this node from the configuration file, nil if not present.
+func (s *remoteCNIserver) fileNodeConfig() *OneNodeConfig {
+	for i := range s.config.NodeConfig {
+		if s.config.NodeConfig[i].NodeName == s.agentLabel {
+			return &s.config.NodeConfig[i]
+		}
+	}
+	return nil
+}
+
+// resyncNodeConfig applies the configuration of this node found in ETCD among the given configurations.
+// The configuration from the configuration file is applied if the node has no configuration in ETCD.
+func (s *remoteCNIserver) resyncNodeConfig(configs []*nodeconfig.NodeConfig) error {
+	for _, config := range configs {
+		if config.NodeName == s.agentLabel {
+			return s.updateNodeConfig(nodeConfigFromProto(config))
+		}
+	}
+	return s.updateNodeConfig(s.fileNodeConfig())
+}
+
+// deleteNodeConfig falls back to the configuration from the configuration file once the configuration
+// of this node is removed from ETCD.
+func (s *remoteCNIserver) deleteNodeConfig() error {
+	return s.updateNodeConfig(s.fileNodeConfig())
+}
+
+// updateNodeConfig applies the changes of the configuration of this node at runtime: the default gateway,
+// the other VPP interfaces and the SNAT of the traffic leaving the cluster (applied by the subscribers
+// of WatchNodeConfig). Changes of the main VPP interface, of the stolen interface and of the uplinks require
+// restart of the agent. The method must be called with the CNI server lock held.
+func (s *remoteCNIserver) updateNodeConfig(nodeConfig *OneNodeConfig) error {
+	oldConfig := s.nodeConfig
+	if oldConfig == nil {
+		oldConfig = &OneNodeConfig{}
+	}
+	newConfig := nodeConfig
+	if newConfig == nil {
+		newConfig = &OneNodeConfig{}
+	}
+	if reflect.DeepEqual(oldConfig, newConfig) {
+		return nil
+	}
+	s.Logger.Infof("Configuration of the node changed: %+v", *newConfig)
+
+	// validate the changes applied at runtime
+	var gw net.IP
+	if newConfig.Gateway != "" {
+		if gw = net.ParseIP(newConfig.Gateway); gw == nil {
+			return fmt.Errorf("invalid default gateway %q", newConfig.Gateway)
+		}
+		if len(s.uplinks) > 0 {
+			return fmt.Errorf("default gateway must not be set on the node with uplinks")
+		}
+	}
+	for _, intf := range newConfig.OtherVPPInterfaces {
+		if _, _, err := net.ParseCIDR(intf.IP); err != nil {
+			return fmt.Errorf("invalid IP address %q of the interface %s", intf.IP, intf.InterfaceName)
+		}
+	}
+	if !reflect.DeepEqual(oldConfig.MainVPPInterface, newConfig.MainVPPInterface) ||
+		oldConfig.StealInterface != newConfig.StealInterface ||
+		!reflect.DeepEqual(oldConfig.Uplinks, newConfig.Uplinks) {
+		s.Logger.Warn("Change of the main VPP interface, of the stolen interface or of the uplinks " +
+			"is applied after restart of the agent")
+	}
+
+	var (
+		removedKeys []string
+		putChanges  = map[string]proto.Message{}
+	)
+	txn := s.vppTxnFactory()
+
+	// default gateway, configured only via the main physical interface
+	if oldConfig.Gateway != newConfig.Gateway && s.mainPhysicalIf != "" && s.stnIP == "" {
+		if oldConfig.Gateway != "" {
+			route := s.defaultRoute(oldConfig.Gateway, s.mainPhysicalIf)
+			s.Logger.Info("Deleting default route: ", route)
+			txn.Delete().StaticRoute(route.VrfId, route.DstIpAddr, route.NextHopAddr)
+			removedKeys = append(removedKeys, vpp_l3.RouteKey(route.VrfId, route.DstIpAddr, route.NextHopAddr))
+		}
+		if newConfig.Gateway != "" {
+			route := s.defaultRoute(newConfig.Gateway, s.mainPhysicalIf)
+			s.Logger.Info("Adding default route: ", route)
+			txn.Put().StaticRoute(route)
+			putChanges[vpp_l3.RouteKey(route.VrfId, route.DstIpAddr, route.NextHopAddr)] = route
+		}
+	}
+
+	// other VPP interfaces, only the interfaces existing in VPP are configured
+	newIfs := map[string]string{}
+	for _, intf := range newConfig.OtherVPPInterfaces {
+		newIfs[intf.InterfaceName] = intf.IP
+	}
+	otherPhysicalIfs := []string{}
+	for _, ifName := range s.otherPhysicalIfs {
+		if _, configured := newIfs[ifName]; !configured && !s.isUplink(ifName) {
+			s.Logger.Info("Unconfiguring interface ", ifName)
+			txn.Delete().VppInterface(ifName)
+			removedKeys = append(removedKeys, vpp_intf.InterfaceKey(ifName))
+			continue
+		}
+		otherPhysicalIfs = append(otherPhysicalIfs, ifName)
+	}
+	oldIfs := map[string]string{}
+	for _, intf := range oldConfig.OtherVPPInterfaces {
+		oldIfs[intf.InterfaceName] = intf.IP
+	}
+	for _, intf := range newConfig.OtherVPPInterfaces {
+		if oldIP, configured := oldIfs[intf.InterfaceName]; configured && oldIP == intf.IP {
+			continue
+		}
+		if _, _, exists := s.swIfIndex.LookupIdx(intf.InterfaceName); !exists {
+			s.Logger.Warnf("Interface %s not found in VPP, skipping", intf.InterfaceName)
+			continue
+		}
+		s.Logger.Infof("Configuring interface %s with IP %s", intf.InterfaceName, intf.IP)
+		nic := s.physicalInterface(intf.InterfaceName, intf.IP)
+		txn.Put().VppInterface(nic)
+		putChanges[vpp_intf.InterfaceKey(nic.Name)] = nic
+		if _, configured := oldIfs[intf.InterfaceName]; !configured {
+			otherPhysicalIfs = append(otherPhysicalIfs, nic.Name)
+		}
+	}
+
+	if len(removedKeys) > 0 || len(putChanges) > 0 {
+		err := txn.Send().ReceiveReply()
+		if err != nil {
+			return fmt.Errorf("can't apply the configuration of the node: %v", err)
+		}
+		err = s.persistChanges(removedKeys, putChanges, true)
+		if err != nil {
+			return err
+		}
+	}
+
+	// the list is replaced, since the previous one may still be used by the callers of GetOtherPhysicalIfNames
+	s.otherPhysicalIfs = otherPhysicalIfs
+	if gw != nil {
+		s.defaultGw = gw
+	} else if oldConfig.Gateway != "" {
+		s.defaultGw = nil
+	}
+	s.nodeConfig = nodeConfig
+
+	for _, sub := range s.nodeConfigSubscribers {
+		select {
+		case sub <- struct{}{}:
+		default:
+			// skip subscribers who are not ready to receive notification
+		}
+	}
+	return nil
+}
+
+// isUplink returns true if the interface is one of the uplinks of the node.
+func (s *remoteCNIserver) isUplink(ifName string) bool {
+	for _, uplink := range s.uplinks {
+		if uplink.InterfaceName == ifName {
+			return true
+		}
+	}
+	return false
+}
+
+// WatchNodeConfig adds given channel to the list of subscribers that are notified when the configuration
+// of this node is changed at runtime. If the channel is not ready to receive notification, the notification
+// is dropped.
+func (s *remoteCNIserver) WatchNodeConfig(subscriber chan struct{}) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.nodeConfigSubscribers = append(s.nodeConfigSubscribers, subscriber)
+}
+
+// NatExternalTraffic returns true if traffic with cluster-outside destination should be S-NATed
+// with node IP before being sent out from the node.
+func (s *remoteCNIserver) NatExternalTraffic() bool {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.config.NatExternalTraffic || (s.nodeConfig != nil && s.nodeConfig.NatExternalTraffic)
+}
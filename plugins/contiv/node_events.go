@@ -21,11 +21,12 @@ import (
 
 	"net"
 
+	ipamModel "github.com/contiv/vpp/plugins/contiv/ipam/model"
+	"github.com/contiv/vpp/plugins/contiv/model/egress"
 	"github.com/contiv/vpp/plugins/contiv/model/node"
-	"github.com/golang/protobuf/proto"
+	"github.com/contiv/vpp/plugins/contiv/model/nodeconfig"
 	"github.com/ligato/cn-infra/datasync"
 	"github.com/ligato/cn-infra/logging"
-	vpp_l2 "github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/l2"
 	vpp_l3 "github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/l3"
 )
 
@@ -65,8 +66,32 @@ func (s *remoteCNIserver) nodeResync(dataResyncEv datasync.ResyncEvent) error {
 	var err error
 	data := dataResyncEv.GetValues()
 
+	// pod CIDR blocks must be known before the routes to the other nodes are computed
+	if it, hasBlocks := data[ipamModel.PodCIDRBlockKeyPrefix()]; hasBlocks {
+		var blocks []*ipamModel.PodCIDRBlock
+		for {
+			kv, stop := it.GetNext()
+			if stop {
+				break
+			}
+			rev := kv.GetRevision()
+			if rev > s.nodeIDResyncRev {
+				s.nodeIDResyncRev = rev
+			}
+
+			block := &ipamModel.PodCIDRBlock{}
+			err = kv.GetValue(block)
+			if err != nil {
+				return err
+			}
+			blocks = append(blocks, block)
+		}
+		s.ipam.ResyncPodCIDRBlocks(blocks)
+	}
+
 	for prefix, it := range data {
 		if prefix == AllocatedIDsKeyPrefix {
+			nodes := map[uint32]bool{}
 			for {
 				kv, stop := it.GetNext()
 				if stop {
@@ -83,7 +108,8 @@ func (s *remoteCNIserver) nodeResync(dataResyncEv datasync.ResyncEvent) error {
 					return err
 				}
 
-				nodeID := uint8(nodeInfo.Id)
+				nodeID := nodeInfo.Id
+				nodes[nodeID] = true
 
 				if nodeID != s.ipam.NodeID() {
 					s.Logger.Info("Other node discovered: ", nodeID)
@@ -95,7 +121,122 @@ func (s *remoteCNIserver) nodeResync(dataResyncEv datasync.ResyncEvent) error {
 					}
 				}
 			}
+			if !nodes[s.nodeID] {
+				s.Logger.Warnf("ID %v of this node was released while the agent is running", s.nodeID)
+				s.notifyNodeIDRelease()
+			}
+			// remove routes to the nodes released while the events were not received (e.g. decommissioned)
+			for nodeID, nodeInfo := range s.otherNodes {
+				if !nodes[nodeID] {
+					s.Logger.Info("Node removed: ", nodeID)
+					err = s.deleteRoutesToNode(nodeInfo)
+				}
+			}
+			// remove the connectivity with the nodes released before the restart of the agent
+			if staleErr := s.overlay.removeStaleNodes(nodes); staleErr != nil {
+				err = staleErr
+			}
+		} else if prefix == IPSecKeysKeyPrefix {
+			var keys []*node.IPSecKey
+			for {
+				kv, stop := it.GetNext()
+				if stop {
+					break
+				}
+				rev := kv.GetRevision()
+				if rev > s.nodeIDResyncRev {
+					s.nodeIDResyncRev = rev
+				}
+
+				key := &node.IPSecKey{}
+				err = kv.GetValue(key)
+				if err != nil {
+					return err
+				}
+				keys = append(keys, key)
+			}
+			err = s.updateIPSecKeys(keys...)
+			if err != nil {
+				s.Logger.Error(err)
+			}
+		} else if prefix == egress.PolicyKeyPrefix() {
+			var policies []*egress.EgressPolicy
+			for {
+				kv, stop := it.GetNext()
+				if stop {
+					break
+				}
+				rev := kv.GetRevision()
+				if rev > s.nodeIDResyncRev {
+					s.nodeIDResyncRev = rev
+				}
+
+				policy := &egress.EgressPolicy{}
+				err = kv.GetValue(policy)
+				if err != nil {
+					return err
+				}
+				policies = append(policies, policy)
+			}
+			err = s.resyncEgressPolicies(policies)
+			if err != nil {
+				s.Logger.Error(err)
+			}
+		} else if prefix == egress.GatewayKeyPrefix() {
+			var gateways []string
+			for {
+				kv, stop := it.GetNext()
+				if stop {
+					break
+				}
+				rev := kv.GetRevision()
+				if rev > s.nodeIDResyncRev {
+					s.nodeIDResyncRev = rev
+				}
+
+				gateway := &egress.Gateway{}
+				err = kv.GetValue(gateway)
+				if err != nil {
+					return err
+				}
+				gateways = append(gateways, gateway.NodeName)
+			}
+			err = s.resyncEgressGateways(gateways)
+			if err != nil {
+				s.Logger.Error(err)
+			}
+		} else if prefix == nodeconfig.KeyPrefix() {
+			var configs []*nodeconfig.NodeConfig
+			for {
+				kv, stop := it.GetNext()
+				if stop {
+					break
+				}
+				rev := kv.GetRevision()
+				if rev > s.nodeIDResyncRev {
+					s.nodeIDResyncRev = rev
+				}
+
+				config := &nodeconfig.NodeConfig{}
+				err = kv.GetValue(config)
+				if err != nil {
+					return err
+				}
+				configs = append(configs, config)
+			}
+			err = s.resyncNodeConfig(configs)
+			if err != nil {
+				s.Logger.Error(err)
+			}
 		}
+		// pod CIDR blocks were already processed, their routes are added together
+		// with the routes to their owner nodes
+	}
+
+	// the gateways of the egress policies may have been discovered only after the policies
+	err = s.renderEgress()
+	if err != nil {
+		s.Logger.Error(err)
 	}
 
 	s.Logger.WithField("nodeResyncRev", s.nodeIDResyncRev).
@@ -147,32 +288,163 @@ func (s *remoteCNIserver) processChangeEvent(dataChngEv datasync.ChangeEvent) er
 		}
 
 		nodeInfo := &node.NodeInfo{}
-		err = dataChngEv.GetValue(nodeInfo)
-		if err != nil {
-			return err
+		if dataChngEv.GetChangeType() == datasync.Put {
+			err = dataChngEv.GetValue(nodeInfo)
+			if err != nil {
+				return err
+			}
+		} else {
+			// the delete event does not carry the value, the removed node is identified by the ID in the key
+			var nodeID int
+			nodeID, err = extractIndexFromKey(key)
+			if err != nil {
+				return err
+			}
+			nodeInfo.Id = uint32(nodeID)
 		}
 
 		// skip nodeInfo of this node
-		if nodeInfo.Id == uint32(s.nodeID) {
+		if nodeInfo.Id == s.nodeID {
+			if dataChngEv.GetChangeType() != datasync.Put {
+				s.Logger.Warnf("ID %v of this node was released while the agent is running", nodeInfo.Id)
+				s.notifyNodeIDRelease()
+			}
 			return nil
 		}
 
 		if dataChngEv.GetChangeType() == datasync.Put {
 
 			// Note: the case where IP address is changed during runtime is not handled
-			if nodeInfo.IpAddress != "" && nodeInfo.ManagementIpAddress != "" {
+			if known, exists := s.otherNodes[nodeInfo.Id]; exists &&
+				known.IpAddress == nodeInfo.IpAddress && known.ManagementIpAddress == nodeInfo.ManagementIpAddress {
+				// routes are already configured, only the IPsec key generation may have changed
+				s.otherNodes[nodeInfo.Id] = nodeInfo
+				err = s.updateIPSecPeer(nodeInfo)
+			} else if nodeInfo.IpAddress != "" && nodeInfo.ManagementIpAddress != "" {
 				s.Logger.Info("New node discovered: ", nodeInfo.Id)
 				// add routes to the node
 				err = s.addRoutesToNode(nodeInfo)
+				if err == nil {
+					// apply the POD ingress limits also to the interfaces created by the overlay (e.g. tunnels)
+					err = s.bandwidthLimiter.updateInterfaces()
+				}
 			} else {
 				s.Logger.Infof("IP address or management IP of node %v is not known yet.", nodeInfo.Id)
 			}
 		} else {
+			known, exists := s.otherNodes[nodeInfo.Id]
+			if !exists {
+				s.Logger.Infof("Removed node %v has no routes configured.", nodeInfo.Id)
+				return nil
+			}
 			s.Logger.Info("Node removed: ", nodeInfo.Id)
 
 			// delete routes to the node
-			err = s.deleteRoutesToNode(nodeInfo)
+			err = s.deleteRoutesToNode(known)
+		}
+		if err == nil {
+			// the node may be the gateway of some egress policy
+			err = s.renderEgress()
+		}
+	} else if strings.HasPrefix(key, IPSecKeysKeyPrefix) {
+		rev := dataChngEv.GetRevision()
+		if rev <= s.nodeIDResyncRev {
+			s.Logger.Info("IPsec key change event was generated before resync, skipping")
+			return nil
 		}
+
+		if dataChngEv.GetChangeType() != datasync.Put {
+			// the generations remain installed, only the two newest ones are accepted
+			s.Logger.Infof("IPsec key %v removed, ignoring", key)
+			return nil
+		}
+		ipsecKey := &node.IPSecKey{}
+		err = dataChngEv.GetValue(ipsecKey)
+		if err != nil {
+			return err
+		}
+		err = s.updateIPSecKeys(ipsecKey)
+	} else if strings.HasPrefix(key, ipamModel.PodCIDRBlockKeyPrefix()) {
+		rev := dataChngEv.GetRevision()
+		if rev <= s.nodeIDResyncRev {
+			s.Logger.Info("Pod CIDR block change event was generated before resync, skipping")
+			return nil
+		}
+
+		block := &ipamModel.PodCIDRBlock{}
+		if dataChngEv.GetChangeType() == datasync.Put {
+			err = dataChngEv.GetValue(block)
+		} else {
+			_, err = dataChngEv.GetPrevValue(block)
+		}
+		if err != nil {
+			return err
+		}
+		s.ipam.UpdatePodCIDRBlock(block, dataChngEv.GetChangeType() == datasync.Put)
+
+		// routes to the blocks of this node are not needed
+		if block.NodeID == s.nodeID {
+			return nil
+		}
+		err = s.updateRouteToPodCIDRBlock(block, dataChngEv.GetChangeType() == datasync.Put)
+	} else if strings.HasPrefix(key, egress.PolicyKeyPrefix()) {
+		rev := dataChngEv.GetRevision()
+		if rev <= s.nodeIDResyncRev {
+			s.Logger.Info("Egress policy change event was generated before resync, skipping")
+			return nil
+		}
+
+		if dataChngEv.GetChangeType() != datasync.Put {
+			namespace, name, err := egress.ParsePolicyFromKey(key)
+			if err != nil {
+				return err
+			}
+			return s.deleteEgressPolicy(namespace, name)
+		}
+		policy := &egress.EgressPolicy{}
+		err = dataChngEv.GetValue(policy)
+		if err != nil {
+			return err
+		}
+		err = s.updateEgressPolicy(policy)
+	} else if strings.HasPrefix(key, egress.GatewayKeyPrefix()) {
+		rev := dataChngEv.GetRevision()
+		if rev <= s.nodeIDResyncRev {
+			s.Logger.Info("Egress gateway change event was generated before resync, skipping")
+			return nil
+		}
+
+		nodeName := strings.TrimPrefix(key, egress.GatewayKeyPrefix())
+		if dataChngEv.GetChangeType() == datasync.Put {
+			err = s.updateEgressGateway(nodeName)
+		} else {
+			err = s.deleteEgressGateway(nodeName)
+		}
+	} else if strings.HasPrefix(key, nodeconfig.KeyPrefix()) {
+		rev := dataChngEv.GetRevision()
+		if rev <= s.nodeIDResyncRev {
+			s.Logger.Info("Node config change event was generated before resync, skipping")
+			return nil
+		}
+
+		var nodeName string
+		nodeName, err = nodeconfig.ParseNodeNameFromKey(key)
+		if err != nil {
+			return err
+		}
+		// only the configuration of this node is applied
+		if nodeName != s.agentLabel {
+			return nil
+		}
+		if dataChngEv.GetChangeType() != datasync.Put {
+			return s.deleteNodeConfig()
+		}
+		config := &nodeconfig.NodeConfig{}
+		err = dataChngEv.GetValue(config)
+		if err != nil {
+			return err
+		}
+		err = s.updateNodeConfig(nodeConfigFromProto(config))
 	} else {
 		return fmt.Errorf("Unknown key %v", key)
 	}
@@ -180,68 +452,73 @@ func (s *remoteCNIserver) processChangeEvent(dataChngEv datasync.ChangeEvent) er
 	return err
 }
 
-// addRoutesToNode add routes to the node specified by nodeID.
-func (s *remoteCNIserver) addRoutesToNode(nodeInfo *node.NodeInfo) error {
+// WatchNodeIDRelease adds given channel to the list of subscribers that are notified when the ID of this node
+// is released while the agent is running. If the channel is not ready to receive notification, the notification
+// is dropped.
+func (s *remoteCNIserver) WatchNodeIDRelease(subscriber chan struct{}) {
+	s.Lock()
+	defer s.Unlock()
 
-	txn := s.vppTxnFactory().Put()
-	hostIP := s.otherHostIP(uint8(nodeInfo.Id), nodeInfo.IpAddress)
+	s.nodeIDReleaseSubscribers = append(s.nodeIDReleaseSubscribers, subscriber)
+}
 
-	// VXLAN tunnel
-	if !s.useL2Interconnect {
-		vxlanIf, err := s.computeVxlanToHost(uint8(nodeInfo.Id), hostIP)
-		if err != nil {
-			return err
+// notifyNodeIDRelease notifies the subscribers that the ID of this node was released.
+// The method must be called with the CNI server lock held.
+func (s *remoteCNIserver) notifyNodeIDRelease() {
+	for _, sub := range s.nodeIDReleaseSubscribers {
+		select {
+		case sub <- struct{}{}:
+		default:
+			// skip subscribers who are not ready to receive notification
 		}
-		txn.VppInterface(vxlanIf)
-		s.Logger.WithFields(logging.Fields{
-			"srcIP":  vxlanIf.Vxlan.SrcAddress,
-			"destIP": vxlanIf.Vxlan.DstAddress}).Info("Configuring vxlan")
+	}
+}
 
-		// add the VXLAN interface into the VXLAN bridge domain
-		s.addInterfaceToVxlanBD(s.vxlanBD, vxlanIf.Name)
+// addRoutesToNode add routes to the node specified by nodeID.
+func (s *remoteCNIserver) addRoutesToNode(nodeInfo *node.NodeInfo) error {
 
-		// pass deep copy to local client since we are overwriting previously applied config
-		bd := proto.Clone(s.vxlanBD)
-		txn.BD(bd.(*vpp_l2.BridgeDomains_BridgeDomain))
+	txn := s.vppTxnFactory().Put()
 
-		vxlanIP, err := s.ipam.VxlanIPAddress(uint8(nodeInfo.Id))
-		if err != nil {
-			s.Logger.Error(err)
-			return err
-		}
-		vxlanArp := s.vxlanArpEntry(uint8(nodeInfo.Id), vxlanIP.String())
-		txn.Arp(vxlanArp)
+	// tunnel towards the node
+	err := s.overlay.connectNode(txn, nodeInfo)
+	if err != nil {
+		return err
 	}
 
 	// static routes
-	var (
-		podsRoute    *vpp_l3.StaticRoutes_Route
-		hostRoute    *vpp_l3.StaticRoutes_Route
-		vxlanNextHop net.IP
-		err          error
-		nextHop      string
-	)
-	if s.useL2Interconnect {
-		// static route directly to other node IP
-		podsRoute, hostRoute, err = s.computeRoutesToHost(uint8(nodeInfo.Id), hostIP)
-		nextHop = hostIP
-	} else {
-		// static route to other node VXLAN BVI
-		vxlanNextHop, err = s.ipam.VxlanIPAddress(uint8(nodeInfo.Id))
-		if err != nil {
-			return err
-		}
-		nextHop = vxlanNextHop.String()
-		podsRoute, hostRoute, err = s.computeRoutesToHost(uint8(nodeInfo.Id), nextHop)
+	nextHop, err := s.otherNodeNextHop(nodeInfo)
+	if err != nil {
+		return err
 	}
+	podsRoutes, hostRoute, err := s.computeRoutesToHost(nodeInfo.Id, nextHop)
 	if err != nil {
 		return err
 	}
-	txn.StaticRoute(podsRoute)
+	for _, podsRoute := range podsRoutes {
+		txn.StaticRoute(podsRoute)
+		s.Logger.Info("Adding PODs route: ", podsRoute)
+	}
+	// routes to the pod CIDR blocks released while the events were not received
+	for _, staleRoute := range s.otherNodePodRoutes[nodeInfo.Id] {
+		if findRoute(podsRoutes, staleRoute) == -1 {
+			txn.Delete().StaticRoute(staleRoute.VrfId, staleRoute.DstIpAddr, staleRoute.NextHopAddr)
+			s.Logger.Info("Deleting stale PODs route: ", staleRoute)
+		}
+	}
 	txn.StaticRoute(hostRoute)
-	s.Logger.Info("Adding PODs route: ", podsRoute)
 	s.Logger.Info("Adding host route: ", hostRoute)
 
+	if s.ipam.IPv6Enabled() {
+		podsRouteIPv6, hostRouteIPv6, err := s.computeIPv6RoutesToHost(nodeInfo.Id)
+		if err != nil {
+			return err
+		}
+		txn.StaticRoute(podsRouteIPv6)
+		txn.StaticRoute(hostRouteIPv6)
+		s.Logger.Info("Adding IPv6 PODs route: ", podsRouteIPv6)
+		s.Logger.Info("Adding IPv6 host route: ", hostRouteIPv6)
+	}
+
 	if s.stnIP == "" {
 		managementRoute := s.routeToOtherManagementIP(nodeInfo.ManagementIpAddress, nextHop)
 		txn.StaticRoute(managementRoute)
@@ -253,25 +530,150 @@ func (s *remoteCNIserver) addRoutesToNode(nodeInfo *node.NodeInfo) error {
 	if err != nil {
 		return fmt.Errorf("Can't configure VPP to add routes to node %v: %v ", nodeInfo.Id, err)
 	}
-	return nil
+	s.otherNodes[nodeInfo.Id] = nodeInfo
+	s.otherNodePodRoutes[nodeInfo.Id] = podsRoutes
+
+	// encryption of the VXLAN traffic
+	return s.updateIPSecPeer(nodeInfo)
 }
 
 // deleteRoutesToNode delete routes to the node specified by nodeID.
 func (s *remoteCNIserver) deleteRoutesToNode(nodeInfo *node.NodeInfo) error {
-	podsRoute, hostRoute, err := s.computeRoutesToHost(uint8(nodeInfo.Id), nodeInfo.IpAddress)
+	nextHop, err := s.otherNodeNextHop(nodeInfo)
 	if err != nil {
 		return err
 	}
-	s.Logger.Info("Deleting PODs route: ", podsRoute)
+	hostRoute, err := s.routeToOtherHostStack(nodeInfo.Id, nextHop)
+	if err != nil {
+		return fmt.Errorf("Can't construct route to host %v: %v ", nodeInfo.Id, err)
+	}
+	// delete the routes as they were installed, the pod CIDR blocks may have been released since then
+	podsRoutes := s.otherNodePodRoutes[nodeInfo.Id]
+
+	txn := s.vppTxnFactory().Delete()
+	for _, podsRoute := range podsRoutes {
+		s.Logger.Info("Deleting PODs route: ", podsRoute)
+		txn.StaticRoute(podsRoute.VrfId, podsRoute.DstIpAddr, podsRoute.NextHopAddr)
+	}
 	s.Logger.Info("Deleting host route: ", hostRoute)
+	txn.StaticRoute(hostRoute.VrfId, hostRoute.DstIpAddr, hostRoute.NextHopAddr)
 
-	err = s.vppTxnFactory().Delete().
-		StaticRoute(podsRoute.VrfId, podsRoute.DstIpAddr, podsRoute.NextHopAddr).
-		StaticRoute(hostRoute.VrfId, hostRoute.DstIpAddr, hostRoute.NextHopAddr).
-		Send().ReceiveReply()
+	if s.ipam.IPv6Enabled() {
+		podsRouteIPv6, hostRouteIPv6, err := s.computeIPv6RoutesToHost(nodeInfo.Id)
+		if err != nil {
+			return err
+		}
+		s.Logger.Info("Deleting IPv6 PODs route: ", podsRouteIPv6)
+		s.Logger.Info("Deleting IPv6 host route: ", hostRouteIPv6)
+		txn.StaticRoute(podsRouteIPv6.VrfId, podsRouteIPv6.DstIpAddr, podsRouteIPv6.NextHopAddr).
+			StaticRoute(hostRouteIPv6.VrfId, hostRouteIPv6.DstIpAddr, hostRouteIPv6.NextHopAddr)
+	}
+
+	if s.stnIP == "" && nodeInfo.ManagementIpAddress != "" {
+		managementRoute := s.routeToOtherManagementIP(nodeInfo.ManagementIpAddress, nextHop)
+		s.Logger.Info("Deleting managementIP route: ", managementRoute)
+		txn.StaticRoute(managementRoute.VrfId, managementRoute.DstIpAddr, managementRoute.NextHopAddr)
+	}
+
+	err = txn.Send().ReceiveReply()
 
 	if err != nil {
 		return fmt.Errorf("Can't configure vpp to remove route to host %v (and its pods): %v ", nodeInfo.Id, err)
 	}
+
+	// tunnel towards the node
+	err = s.overlay.disconnectNode(nodeInfo)
+	if err != nil {
+		return fmt.Errorf("Can't disconnect node %v: %v ", nodeInfo.Id, err)
+	}
+	delete(s.otherNodes, nodeInfo.Id)
+	delete(s.otherNodePodRoutes, nodeInfo.Id)
+	return s.removeIPSecPeer(nodeInfo)
+}
+
+// otherNodeNextHop returns the IP address used as the next hop for the routes towards the given node,
+// provided by the node overlay (e.g. the IP of the other node with L2 interconnect, or the IP of its VXLAN BVI).
+func (s *remoteCNIserver) otherNodeNextHop(nodeInfo *node.NodeInfo) (string, error) {
+	return s.overlay.nextHop(nodeInfo)
+}
+
+// updateRouteToPodCIDRBlock adds or deletes the route towards a pod CIDR block claimed (or released)
+// by another node. Blocks of nodes that are not known yet are skipped, their routes are added
+// together with the other routes to the node.
+func (s *remoteCNIserver) updateRouteToPodCIDRBlock(block *ipamModel.PodCIDRBlock, isAdd bool) error {
+	nodeInfo, known := s.otherNodes[block.NodeID]
+	if !known {
+		s.Logger.Infof("Owner %v of the pod CIDR block %v is not known yet.", block.NodeID, block.Network)
+		return nil
+	}
+	_, network, err := net.ParseCIDR(block.Network)
+	if err != nil {
+		return err
+	}
+	nextHop, err := s.otherNodeNextHop(nodeInfo)
+	if err != nil {
+		return err
+	}
+	route, err := s.routeToOtherHostNetworks(network, nextHop)
+	if err != nil {
+		return err
+	}
+
+	installed := s.otherNodePodRoutes[block.NodeID]
+	idx := findRoute(installed, route)
+	if isAdd {
+		if idx != -1 {
+			return nil
+		}
+		s.Logger.Info("Adding pod CIDR block route: ", route)
+		err = s.vppTxnFactory().Put().StaticRoute(route).Send().ReceiveReply()
+	} else {
+		if idx == -1 {
+			return nil
+		}
+		route = installed[idx]
+		s.Logger.Info("Deleting pod CIDR block route: ", route)
+		err = s.vppTxnFactory().Delete().StaticRoute(route.VrfId, route.DstIpAddr, route.NextHopAddr).Send().ReceiveReply()
+	}
+	if err != nil {
+		return fmt.Errorf("Can't configure VPP to update route to pod CIDR block %v: %v ", block.Network, err)
+	}
+	if isAdd {
+		s.otherNodePodRoutes[block.NodeID] = append(installed, route)
+	} else {
+		s.otherNodePodRoutes[block.NodeID] = append(installed[:idx], installed[idx+1:]...)
+	}
 	return nil
 }
+
+// findRoute returns index of the route with the same destination and VRF in the list, -1 if there is none.
+func findRoute(routes []*vpp_l3.StaticRoutes_Route, route *vpp_l3.StaticRoutes_Route) int {
+	for idx, installed := range routes {
+		if installed.VrfId == route.VrfId && installed.DstIpAddr == route.DstIpAddr {
+			return idx
+		}
+	}
+	return -1
+}
+
+// WatchOverlayTunnels adds given channel to the list of subscribers that are notified when the tunnels returned
+// by GetOverlayTunnelIfNames change. If the channel is not ready to receive notification, the notification
+// is dropped.
+func (s *remoteCNIserver) WatchOverlayTunnels(subscriber chan struct{}) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.overlayTunnelSubscribers = append(s.overlayTunnelSubscribers, subscriber)
+}
+
+// notifyOverlayTunnels notifies the subscribers that the tunnels of the overlay have changed.
+// The method must be called with the CNI server lock held.
+func (s *remoteCNIserver) notifyOverlayTunnels() {
+	for _, sub := range s.overlayTunnelSubscribers {
+		select {
+		case sub <- struct{}{}:
+		default:
+			// skip subscribers who are not ready to receive notification
+		}
+	}
+}
@@ -32,7 +32,7 @@ import (
 const (
 	// AllocatedIDsKeyPrefix is a key prefix used in ETCD to store information
 	// about node ID and its IP addresses.
-	AllocatedIDsKeyPrefix = "allocatedIDs/"
+	AllocatedIDsKeyPrefix = node.AllocatedIDsKeyPrefix
 
 	maxAttempts = 10
 )
@@ -61,6 +61,9 @@ type idAllocator struct {
 
 	// ip used by k8s to access node
 	managementIP string
+
+	// the newest IPsec key generation accepted by the node
+	ipsecKeyGeneration uint32
 }
 
 // newIDAllocator creates new instance of idAllocator
@@ -74,12 +77,12 @@ func newIDAllocator(etcd *etcdv3.Plugin, nodeName string, nodeIP string) *idAllo
 }
 
 // getID returns unique number for the given node
-func (ia *idAllocator) getID() (id uint8, err error) {
+func (ia *idAllocator) getID() (id uint32, err error) {
 	ia.Lock()
 	defer ia.Unlock()
 
 	if ia.allocated {
-		return uint8(ia.ID), nil
+		return ia.ID, nil
 	}
 
 	// check if there is already assign ID for the serviceLabel
@@ -91,7 +94,7 @@ func (ia *idAllocator) getID() (id uint8, err error) {
 	if existingEntry != nil {
 		ia.allocated = true
 		ia.ID = existingEntry.Id
-		return uint8(ia.ID), nil
+		return ia.ID, nil
 	}
 
 	attempts := 0
@@ -119,18 +122,23 @@ func (ia *idAllocator) getID() (id uint8, err error) {
 		}
 	}
 
-	return uint8(ia.ID), nil
+	return ia.ID, nil
 }
 
 func (ia *idAllocator) updateIP(newIP string) error {
-	return ia.updateEtcdEntry(newIP, ia.managementIP)
+	return ia.updateEtcdEntry(newIP, ia.managementIP, ia.ipsecKeyGeneration)
 }
 
 func (ia *idAllocator) updateManagementIP(newMgmtIP string) error {
-	return ia.updateEtcdEntry(ia.nodeIP, newMgmtIP)
+	return ia.updateEtcdEntry(ia.nodeIP, newMgmtIP, ia.ipsecKeyGeneration)
+}
+
+// updateIPSecKeyGeneration announces the newest IPsec key generation accepted by the node to the other nodes.
+func (ia *idAllocator) updateIPSecKeyGeneration(generation uint32) error {
+	return ia.updateEtcdEntry(ia.nodeIP, ia.managementIP, generation)
 }
 
-func (ia *idAllocator) updateEtcdEntry(newIP string, newManagementIP string) error {
+func (ia *idAllocator) updateEtcdEntry(newIP string, newManagementIP string, newIPSecKeyGeneration uint32) error {
 	// make sure that ID is allocated
 	_, err := ia.getID()
 	if err != nil {
@@ -139,18 +147,20 @@ func (ia *idAllocator) updateEtcdEntry(newIP string, newManagementIP string) err
 
 	ia.Lock()
 	defer ia.Unlock()
-	if ia.nodeIP == newIP && ia.managementIP == newManagementIP {
+	if ia.nodeIP == newIP && ia.managementIP == newManagementIP && ia.ipsecKeyGeneration == newIPSecKeyGeneration {
 		return nil
 	}
 
 	ia.nodeIP = newIP
 	ia.managementIP = newManagementIP
+	ia.ipsecKeyGeneration = newIPSecKeyGeneration
 
 	value := &node.NodeInfo{
 		Id:                  ia.ID,
 		Name:                ia.nodeName,
 		IpAddress:           ia.nodeIP,
 		ManagementIpAddress: ia.managementIP,
+		IpsecKeyGeneration:  ia.ipsecKeyGeneration,
 	}
 	err = ia.broker.Put(createKey(ia.ID), value)
 
@@ -175,6 +185,39 @@ func (ia *idAllocator) releaseID() error {
 	return err
 }
 
+// reclaimID writes the entry of the node back into ETCD after it was removed while the agent is running
+// (e.g. the node was decommissioned by KSR while it was only temporarily unreachable). The entry is written
+// only if the ID was not allocated to another node in the meantime.
+func (ia *idAllocator) reclaimID() error {
+	ia.Lock()
+	defer ia.Unlock()
+
+	if !ia.allocated {
+		return errNoIDallocated
+	}
+
+	value := &node.NodeInfo{
+		Id:                  ia.ID,
+		Name:                ia.nodeName,
+		IpAddress:           ia.nodeIP,
+		ManagementIpAddress: ia.managementIP,
+		IpsecKeyGeneration:  ia.ipsecKeyGeneration,
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	succeeded, err := ia.etcd.PutIfNotExists(servicelabel.GetDifferentAgentPrefix(ksr.MicroserviceLabel)+createKey(ia.ID), encoded)
+	if err != nil {
+		return err
+	}
+	if !succeeded {
+		return fmt.Errorf("ID %v of the node was allocated to another node, restart of the agent is required", ia.ID)
+	}
+	return nil
+}
+
 func (ia *idAllocator) writeIfNotExists(id uint32) (succeeded bool, err error) {
 
 	value := &node.NodeInfo{
@@ -274,6 +317,5 @@ func extractIndexFromKey(key string) (int, error) {
 }
 
 func createKey(index uint32) string {
-	str := strconv.FormatUint(uint64(index), 10)
-	return AllocatedIDsKeyPrefix + str
+	return node.AllocatedIDKey(index)
 }
@@ -0,0 +1,287 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contiv
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/contiv/vpp/plugins/contiv/model/node"
+	"github.com/gogo/protobuf/proto"
+	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/vpp-agent/clientv1/linux"
+	vpp_l2 "github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/l2"
+)
+
+const (
+	// VxlanOverlay interconnects the nodes by VXLAN tunnels in a bridge domain with BVI (default).
+	VxlanOverlay = "vxlan"
+
+	// L2Overlay interconnects the nodes directly, the main VPP interfaces of the nodes must be in the same L2 network.
+	L2Overlay = "l2"
+
+	// IPIPOverlay interconnects the nodes by routed IP-in-IP tunnels.
+	IPIPOverlay = "ipip"
+)
+
+// nodeOverlay interconnects the node with the other nodes of the cluster. The routes towards the other nodes
+// are configured by the CNI server, the overlay provides their next hops.
+// The methods are called with the CNI server lock held.
+type nodeOverlay interface {
+	// configure applies the base configuration of the overlay as part of the vswitch configuration.
+	// The VPP agent configuration is applied only if the vswitch is not configured yet (config.configured)
+	// and stored into config to be persisted.
+	configure(config *vswitchConfig) error
+
+	// bviIfName returns the name of the VPP interface with the overlay IP addresses of the node, which terminates
+	// the traffic of all tunnels. Returns an empty string if the overlay does not use such interface.
+	bviIfName() string
+
+	// tunnelIfNames returns the names of the tunnels which terminate the traffic of the overlay themselves,
+	// i.e. which are used instead of the BVI.
+	tunnelIfNames() []string
+
+	// nextHop returns the next hop of the IPv4 routes towards the given node.
+	nextHop(nodeInfo *node.NodeInfo) (string, error)
+
+	// nextHopIPv6 returns the next hop of the IPv6 routes towards the given node.
+	nextHopIPv6(nodeID uint32) (net.IP, error)
+
+	// connectNode configures the connectivity with the given node. The VPP agent configuration is added
+	// into the transaction, which is sent by the caller together with the routes towards the node.
+	connectNode(txn linux.PutDSL, nodeInfo *node.NodeInfo) error
+
+	// disconnectNode removes the connectivity with the given node, after the routes towards the node are removed.
+	disconnectNode(nodeInfo *node.NodeInfo) error
+
+	// removeStaleNodes removes the connectivity configured outside of the VPP agent before the restart
+	// of the agent with the nodes which no longer exist. nodes are the IDs of the existing nodes.
+	removeStaleNodes(nodes map[uint32]bool) error
+}
+
+// newNodeOverlay returns the node overlay selected in the configuration.
+// UseL2Interconnect is kept as an alias of the L2 overlay.
+func newNodeOverlay(s *remoteCNIserver, cli cliExecutor) (nodeOverlay, error) {
+	overlay := s.config.Overlay
+	if s.config.UseL2Interconnect {
+		if overlay != "" && overlay != L2Overlay {
+			return nil, fmt.Errorf("UseL2Interconnect conflicts with the %s overlay", overlay)
+		}
+		overlay = L2Overlay
+	}
+	switch overlay {
+	case "", VxlanOverlay:
+		return &vxlanOverlay{s: s}, nil
+	case L2Overlay:
+		return &l2Overlay{s: s}, nil
+	case IPIPOverlay:
+		return newIPIPOverlay(s, cli), nil
+	}
+	return nil, fmt.Errorf("unsupported node overlay %q", overlay)
+}
+
+// l2Overlay interconnects the nodes directly via their main VPP interfaces.
+type l2Overlay struct {
+	s *remoteCNIserver
+}
+
+// configure does nothing, no base configuration is needed.
+func (o *l2Overlay) configure(config *vswitchConfig) error {
+	return nil
+}
+
+// bviIfName returns an empty string, the traffic is received on the main VPP interface.
+func (o *l2Overlay) bviIfName() string {
+	return ""
+}
+
+// tunnelIfNames returns nil, no tunnels are used.
+func (o *l2Overlay) tunnelIfNames() []string {
+	return nil
+}
+
+// nextHop returns IP address of the main VPP interface of the other node.
+func (o *l2Overlay) nextHop(nodeInfo *node.NodeInfo) (string, error) {
+	return o.s.otherHostIP(nodeInfo.Id, nodeInfo.IpAddress), nil
+}
+
+// nextHopIPv6 returns IPv6 address of the main VPP interface of the other node.
+func (o *l2Overlay) nextHopIPv6(nodeID uint32) (net.IP, error) {
+	return o.s.ipam.NodeIPv6Address(nodeID)
+}
+
+// connectNode does nothing, the other node is directly reachable.
+func (o *l2Overlay) connectNode(txn linux.PutDSL, nodeInfo *node.NodeInfo) error {
+	return nil
+}
+
+// disconnectNode does nothing, the other node is directly reachable.
+func (o *l2Overlay) disconnectNode(nodeInfo *node.NodeInfo) error {
+	return nil
+}
+
+// removeStaleNodes does nothing, there is no configuration per node.
+func (o *l2Overlay) removeStaleNodes(nodes map[uint32]bool) error {
+	return nil
+}
+
+// vxlanOverlay interconnects the nodes by a full mesh of VXLAN tunnels in a bridge domain with BVI,
+// which has the VXLAN IP address of the node and is the next hop of the routes from the other nodes.
+type vxlanOverlay struct {
+	s *remoteCNIserver
+
+	// bvi is the name of the VXLAN BVI, empty until configured
+	bvi string
+
+	// bd is the VXLAN bridge domain, reconfigured with each new VXLAN (each new node)
+	bd *vpp_l2.BridgeDomains_BridgeDomain
+}
+
+// configure configures the VXLAN BVI and the bridge domain for the VXLAN tunnels.
+func (o *vxlanOverlay) configure(config *vswitchConfig) error {
+	txn := o.s.vppTxnFactory().Put()
+
+	// VXLAN BVI loopback
+	bvi, err := o.s.vxlanBVILoopback()
+	if err != nil {
+		return err
+	}
+	txn.VppInterface(bvi)
+	config.overlayIfs = append(config.overlayIfs, bvi)
+
+	// bridge domain for the VXLAN tunnel
+	bd := o.s.vxlanBridgeDomain(bvi.Name)
+	config.overlayBDs = append(config.overlayBDs, bd)
+	// create deep copy since the config will be overwritten when a node joins the cluster
+	txn.BD(proto.Clone(bd).(*vpp_l2.BridgeDomains_BridgeDomain))
+
+	// execute the config transaction
+	if !config.configured {
+		err = txn.Send().ReceiveReply()
+		if err != nil {
+			return err
+		}
+	}
+
+	o.bvi = bvi.Name
+	o.bd = bd
+	return nil
+}
+
+// bviIfName returns the name of the VXLAN BVI.
+func (o *vxlanOverlay) bviIfName() string {
+	return o.bvi
+}
+
+// tunnelIfNames returns nil, the traffic of the VXLAN tunnels is terminated by the VXLAN BVI.
+func (o *vxlanOverlay) tunnelIfNames() []string {
+	return nil
+}
+
+// nextHop returns IP address of the VXLAN BVI of the other node.
+func (o *vxlanOverlay) nextHop(nodeInfo *node.NodeInfo) (string, error) {
+	vxlanNextHop, err := o.s.ipam.VxlanIPAddress(nodeInfo.Id)
+	if err != nil {
+		return "", err
+	}
+	return vxlanNextHop.String(), nil
+}
+
+// nextHopIPv6 returns IPv6 address of the VXLAN BVI of the other node.
+func (o *vxlanOverlay) nextHopIPv6(nodeID uint32) (net.IP, error) {
+	return o.s.ipam.VxlanIPv6Address(nodeID)
+}
+
+// connectNode configures the VXLAN tunnel towards the other node, adds it into the VXLAN bridge domain
+// and adds static ARP entries for the VXLAN BVI of the other node. The tunnels of the tenants are configured
+// as well.
+func (o *vxlanOverlay) connectNode(txn linux.PutDSL, nodeInfo *node.NodeInfo) error {
+	hostIP := o.s.otherHostIP(nodeInfo.Id, nodeInfo.IpAddress)
+	vxlanIf, err := o.s.computeVxlanToHost(nodeInfo.Id, hostIP)
+	if err != nil {
+		return err
+	}
+	txn.VppInterface(vxlanIf)
+	o.s.Logger.WithFields(logging.Fields{
+		"srcIP":  vxlanIf.Vxlan.SrcAddress,
+		"destIP": vxlanIf.Vxlan.DstAddress}).Info("Configuring vxlan")
+
+	// add the VXLAN interface into the VXLAN bridge domain
+	if !vxlanBDHasInterface(o.bd, vxlanIf.Name) {
+		o.s.addInterfaceToVxlanBD(o.bd, vxlanIf.Name)
+	}
+
+	// pass deep copy to local client since we are overwriting previously applied config
+	txn.BD(proto.Clone(o.bd).(*vpp_l2.BridgeDomains_BridgeDomain))
+
+	vxlanIP, err := o.s.ipam.VxlanIPAddress(nodeInfo.Id)
+	if err != nil {
+		return err
+	}
+	txn.Arp(o.s.vxlanArpEntry(nodeInfo.Id, vxlanIP.String()))
+
+	if o.s.ipam.IPv6Enabled() {
+		vxlanIPv6, err := o.s.ipam.VxlanIPv6Address(nodeInfo.Id)
+		if err != nil {
+			return err
+		}
+		txn.Arp(o.s.vxlanArpEntry(nodeInfo.Id, vxlanIPv6.String()))
+	}
+	return o.s.connectTenants(txn, nodeInfo.Id, hostIP)
+}
+
+// disconnectNode removes the VXLAN tunnel towards the other node together with the static ARP entries.
+// The tunnels of the tenants are removed first.
+func (o *vxlanOverlay) disconnectNode(nodeInfo *node.NodeInfo) error {
+	err := o.s.disconnectTenants(nodeInfo.Id)
+	if err != nil {
+		return err
+	}
+	vxlanIf, err := o.s.computeVxlanToHost(nodeInfo.Id, "")
+	if err != nil {
+		return err
+	}
+	if !vxlanBDHasInterface(o.bd, vxlanIf.Name) {
+		return nil
+	}
+
+	// remove the VXLAN interface from the VXLAN bridge domain first
+	removeInterfaceFromVxlanBD(o.bd, vxlanIf.Name)
+	err = o.s.vppTxnFactory().Put().BD(proto.Clone(o.bd).(*vpp_l2.BridgeDomains_BridgeDomain)).Send().ReceiveReply()
+	if err != nil {
+		return err
+	}
+
+	txn := o.s.vppTxnFactory().Delete().VppInterface(vxlanIf.Name)
+	vxlanIP, err := o.s.ipam.VxlanIPAddress(nodeInfo.Id)
+	if err != nil {
+		return err
+	}
+	txn.Arp(vxlanBVIInterfaceName, vxlanIP.String())
+	if o.s.ipam.IPv6Enabled() {
+		vxlanIPv6, err := o.s.ipam.VxlanIPv6Address(nodeInfo.Id)
+		if err != nil {
+			return err
+		}
+		txn.Arp(vxlanBVIInterfaceName, vxlanIPv6.String())
+	}
+	return txn.Send().ReceiveReply()
+}
+
+// removeStaleNodes does nothing, the VXLAN tunnels are configured via the VPP agent, which removes
+// the tunnels no longer configured by its resync.
+func (o *vxlanOverlay) removeStaleNodes(nodes map[uint32]bool) error {
+	return nil
+}
@@ -0,0 +1,343 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contiv
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/contiv/vpp/plugins/contiv/model/node"
+	"github.com/ligato/vpp-agent/clientv1/linux"
+	vpp_intf "github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/interfaces"
+	"github.com/ligato/vpp-agent/plugins/defaultplugins/ifplugin/ifaceidx"
+)
+
+const (
+	// ipipLoopName is the logical name of the loopback with the overlay IP addresses of the node
+	ipipLoopName = "ipipLoop"
+)
+
+// ipipTunnelRegexp matches an IP-in-IP tunnel in the output of "show ipip tunnel".
+var ipipTunnelRegexp = regexp.MustCompile(`instance (\d+) src (\S+) dst (\S+)`)
+
+// ipipOverlay interconnects the nodes by a full mesh of routed IP-in-IP tunnels, without any bridge domain.
+// Each tunnel is unnumbered, borrowing the overlay IP addresses of the node (allocated from VxlanCIDR)
+// from a loopback, and has a host route to the overlay IP address of the other node. The routes towards
+// the other node use its overlay IP address as the next hop, which is resolved recursively via the tunnel.
+// The vendored VPP agent does not support IP-in-IP tunnels, therefore the tunnels are configured via VPP CLI.
+// The tunnels are not known to the VPP agent, the NAT44 features the service plugin applies to the VXLAN BVI
+// are therefore applied to each tunnel directly. The tunnels are registered in the interface index of the agent
+// for the policy ACLs, which the ACL plugin of the agent applies by the interface name. The tunnels are kept in VPP over the restart of the agent,
+// therefore the tunnels found in VPP are re-used or removed when the nodes are connected after the restart.
+type ipipOverlay struct {
+	s   *remoteCNIserver
+	cli cliExecutor
+
+	// tunnels towards the other nodes, by node ID
+	tunnels map[uint32]*ipipTunnel
+}
+
+// ipipTunnel is an IP-in-IP tunnel towards another node.
+type ipipTunnel struct {
+	ifName string // VPP interface name
+	dstIP  string // IP address of the other node
+	routes []string
+}
+
+// newIPIPOverlay returns new instance of ipipOverlay.
+func newIPIPOverlay(s *remoteCNIserver, cli cliExecutor) *ipipOverlay {
+	return &ipipOverlay{
+		s:       s,
+		cli:     cli,
+		tunnels: map[uint32]*ipipTunnel{},
+	}
+}
+
+// configure configures the loopback with the overlay IP addresses of the node.
+func (o *ipipOverlay) configure(config *vswitchConfig) error {
+	overlayIP, err := o.s.ipam.VxlanIPAddress(o.s.ipam.NodeID())
+	if err != nil {
+		return err
+	}
+	loop := &vpp_intf.Interfaces_Interface{
+		Name:        ipipLoopName,
+		Type:        vpp_intf.InterfaceType_SOFTWARE_LOOPBACK,
+		Enabled:     true,
+		IpAddresses: []string{ipWithFullPrefix(overlayIP)},
+	}
+	if o.s.ipam.IPv6Enabled() {
+		overlayIPv6, err := o.s.ipam.VxlanIPv6Address(o.s.ipam.NodeID())
+		if err != nil {
+			return err
+		}
+		loop.IpAddresses = append(loop.IpAddresses, ipWithFullPrefix(overlayIPv6))
+	}
+	config.overlayIfs = append(config.overlayIfs, loop)
+
+	if !config.configured {
+		return o.s.vppTxnFactory().Put().VppInterface(loop).Send().ReceiveReply()
+	}
+	return nil
+}
+
+// bviIfName returns an empty string, the traffic is received on the tunnel interfaces.
+func (o *ipipOverlay) bviIfName() string {
+	return ""
+}
+
+// tunnelIfNames returns the names of the tunnels towards the other nodes.
+func (o *ipipOverlay) tunnelIfNames() []string {
+	ifNames := []string{}
+	for _, tunnel := range o.tunnels {
+		ifNames = append(ifNames, tunnel.ifName)
+	}
+	sort.Strings(ifNames)
+	return ifNames
+}
+
+// nextHop returns the overlay IP address of the other node.
+func (o *ipipOverlay) nextHop(nodeInfo *node.NodeInfo) (string, error) {
+	overlayIP, err := o.s.ipam.VxlanIPAddress(nodeInfo.Id)
+	if err != nil {
+		return "", err
+	}
+	return overlayIP.String(), nil
+}
+
+// nextHopIPv6 returns the overlay IPv6 address of the other node.
+func (o *ipipOverlay) nextHopIPv6(nodeID uint32) (net.IP, error) {
+	return o.s.ipam.VxlanIPv6Address(nodeID)
+}
+
+// connectNode creates the IP-in-IP tunnel towards the other node together with the host routes
+// to its overlay IP addresses. The tunnel is created immediately, the transaction is not used.
+// A tunnel left in VPP by the previous run of the agent is re-used if it leads to the same node IP,
+// otherwise it is removed first.
+func (o *ipipOverlay) connectNode(txn linux.PutDSL, nodeInfo *node.NodeInfo) error {
+	dstIP := o.s.otherHostIP(nodeInfo.Id, nodeInfo.IpAddress)
+	if tunnel, exists := o.tunnels[nodeInfo.Id]; exists {
+		if tunnel.dstIP == dstIP {
+			return nil
+		}
+		if err := o.disconnectNode(nodeInfo); err != nil {
+			return err
+		}
+	}
+
+	loopIfName, err := o.cli.internalIfName(ipipLoopName)
+	if err != nil {
+		return err
+	}
+	tunnel, err := o.newTunnel(nodeInfo.Id, dstIP)
+	if err != nil {
+		return err
+	}
+
+	vppTunnels, err := o.dumpTunnels()
+	if err != nil {
+		return err
+	}
+	if vppDstIP, inVPP := vppTunnels[nodeInfo.Id]; inVPP {
+		if vppDstIP == dstIP {
+			// the VPP agent brings the interfaces it does not know down by its resync
+			err = o.cli.cli(fmt.Sprintf("set interface state %s up", tunnel.ifName))
+			if err != nil {
+				return err
+			}
+			if err := o.registerTunnel(tunnel); err != nil {
+				return err
+			}
+			o.tunnels[nodeInfo.Id] = tunnel
+			o.s.notifyOverlayTunnels()
+			o.s.Logger.Infof("IP-in-IP tunnel %s towards the node %v (%s) re-used", tunnel.ifName, nodeInfo.Id, dstIP)
+			return nil
+		}
+		if err := o.removeStaleTunnel(nodeInfo.Id); err != nil {
+			return err
+		}
+	}
+
+	// the create command prints the name of the new interface
+	out, err := o.cli.cliOutput(fmt.Sprintf("create ipip tunnel src %s dst %s instance %d",
+		o.s.ipPrefixToAddress(o.s.nodeIP), dstIP, nodeInfo.Id))
+	if err != nil {
+		return err
+	}
+	if out = strings.TrimSpace(out); out != "" && out != tunnel.ifName {
+		return fmt.Errorf("can't create IP-in-IP tunnel towards the node %v: %s", nodeInfo.Id, out)
+	}
+	o.tunnels[nodeInfo.Id] = tunnel
+
+	cmds := []string{
+		fmt.Sprintf("set interface unnumbered %s use %s", tunnel.ifName, loopIfName),
+		fmt.Sprintf("set interface state %s up", tunnel.ifName),
+		fmt.Sprintf("set interface nat44 in %s out %s", tunnel.ifName, tunnel.ifName),
+	}
+	if o.s.ipam.IPv6Enabled() {
+		cmds = append(cmds, fmt.Sprintf("enable ip6 interface %s", tunnel.ifName))
+	}
+	for _, route := range tunnel.routes {
+		cmds = append(cmds, fmt.Sprintf("ip route add %s via %s", route, tunnel.ifName))
+	}
+	for _, cmd := range cmds {
+		if err := o.cli.cli(cmd); err != nil {
+			return err
+		}
+	}
+	if err := o.registerTunnel(tunnel); err != nil {
+		return err
+	}
+	o.s.notifyOverlayTunnels()
+	o.s.Logger.Infof("IP-in-IP tunnel %s towards the node %v (%s) configured", tunnel.ifName, nodeInfo.Id, dstIP)
+	return nil
+}
+
+// disconnectNode removes the IP-in-IP tunnel towards the other node.
+func (o *ipipOverlay) disconnectNode(nodeInfo *node.NodeInfo) error {
+	tunnel, exists := o.tunnels[nodeInfo.Id]
+	if !exists {
+		return nil
+	}
+
+	for _, cmd := range o.unconfigureCommands(tunnel) {
+		if err := o.cli.cli(cmd); err != nil {
+			return err
+		}
+	}
+	if err := o.deleteTunnel(tunnel); err != nil {
+		return err
+	}
+
+	delete(o.tunnels, nodeInfo.Id)
+	o.s.notifyOverlayTunnels()
+	o.s.Logger.Infof("IP-in-IP tunnel %s towards the node %v removed", tunnel.ifName, nodeInfo.Id)
+	return nil
+}
+
+// removeStaleNodes removes the IP-in-IP tunnels left in VPP by the previous run of the agent
+// towards the nodes which no longer exist.
+func (o *ipipOverlay) removeStaleNodes(nodes map[uint32]bool) error {
+	vppTunnels, err := o.dumpTunnels()
+	if err != nil {
+		return err
+	}
+	for nodeID := range vppTunnels {
+		if _, connected := o.tunnels[nodeID]; connected || nodes[nodeID] {
+			continue
+		}
+		if err := o.removeStaleTunnel(nodeID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newTunnel returns the tunnel towards the node with the given ID and IP, the tunnel is not created.
+func (o *ipipOverlay) newTunnel(nodeID uint32, dstIP string) (*ipipTunnel, error) {
+	tunnel := &ipipTunnel{
+		ifName: fmt.Sprintf("ipip%d", nodeID),
+		dstIP:  dstIP,
+	}
+	nextHop, err := o.s.ipam.VxlanIPAddress(nodeID)
+	if err != nil {
+		return nil, err
+	}
+	tunnel.routes = append(tunnel.routes, nextHop.String()+"/32")
+	if o.s.ipam.IPv6Enabled() {
+		nextHopIPv6, err := o.nextHopIPv6(nodeID)
+		if err != nil {
+			return nil, err
+		}
+		tunnel.routes = append(tunnel.routes, nextHopIPv6.String()+"/128")
+	}
+	return tunnel, nil
+}
+
+// removeStaleTunnel removes the tunnel left in VPP by the previous run of the agent. Its configuration
+// may be incomplete, the errors of the commands removing it are therefore ignored.
+func (o *ipipOverlay) removeStaleTunnel(nodeID uint32) error {
+	tunnel, err := o.newTunnel(nodeID, "")
+	if err != nil {
+		return err
+	}
+	for _, cmd := range o.unconfigureCommands(tunnel) {
+		o.cli.cliOutput(cmd)
+	}
+	if err := o.deleteTunnel(tunnel); err != nil {
+		return err
+	}
+	o.s.Logger.Infof("Stale IP-in-IP tunnel %s towards the node %v removed", tunnel.ifName, nodeID)
+	return nil
+}
+
+// unconfigureCommands returns the CLI commands removing the routes and the features of the tunnel.
+func (o *ipipOverlay) unconfigureCommands(tunnel *ipipTunnel) []string {
+	var cmds []string
+	for _, route := range tunnel.routes {
+		cmds = append(cmds, fmt.Sprintf("ip route del %s via %s", route, tunnel.ifName))
+	}
+	return append(cmds, fmt.Sprintf("set interface nat44 in %s out %s del", tunnel.ifName, tunnel.ifName))
+}
+
+// registerTunnel registers the tunnel interface in the interface index of the VPP agent.
+func (o *ipipOverlay) registerTunnel(tunnel *ipipTunnel) error {
+	swIfIndex, writable := o.s.swIfIndex.(ifaceidx.SwIfIndexRW)
+	if !writable {
+		return nil
+	}
+	swIfIdx, err := o.cli.internalIfIndex(tunnel.ifName)
+	if err != nil {
+		return err
+	}
+	swIfIndex.RegisterName(tunnel.ifName, swIfIdx, &vpp_intf.Interfaces_Interface{
+		Name:    tunnel.ifName,
+		Enabled: true,
+	})
+	return nil
+}
+
+// deleteTunnel unregisters the tunnel interface from the interface index of the VPP agent and deletes it.
+func (o *ipipOverlay) deleteTunnel(tunnel *ipipTunnel) error {
+	swIfIdx, err := o.cli.internalIfIndex(tunnel.ifName)
+	if err != nil {
+		return err
+	}
+	if swIfIndex, writable := o.s.swIfIndex.(ifaceidx.SwIfIndexRW); writable {
+		swIfIndex.UnregisterName(tunnel.ifName)
+	}
+	return o.cli.cli(fmt.Sprintf("delete ipip tunnel sw_if_index %d", swIfIdx))
+}
+
+// dumpTunnels returns the destination IP addresses of the IP-in-IP tunnels configured in VPP,
+// by the tunnel instance (i.e. by the ID of the other node).
+func (o *ipipOverlay) dumpTunnels() (map[uint32]string, error) {
+	out, err := o.cli.cliOutput("show ipip tunnel")
+	if err != nil {
+		return nil, err
+	}
+	tunnels := map[uint32]string{}
+	for _, match := range ipipTunnelRegexp.FindAllStringSubmatch(out, -1) {
+		instance, err := strconv.ParseUint(match[1], 10, 32)
+		if err != nil {
+			continue
+		}
+		tunnels[uint32(instance)] = match[3]
+	}
+	return tunnels, nil
+}
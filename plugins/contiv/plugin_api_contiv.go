@@ -29,6 +29,10 @@ type API interface {
 	// GetPodNetwork provides subnet used for allocating pod IP addresses on this host node.
 	GetPodNetwork() *net.IPNet
 
+	// IsLocalPodIP returns true if the given IP address belongs to any of the pod networks of this host node
+	// (the primary pod network or a pod CIDR block allocated dynamically later on).
+	IsLocalPodIP(ip net.IP) bool
+
 	// GetContainerIndex exposes index of configured containers
 	GetContainerIndex() containeridx.Reader
 
@@ -48,6 +52,11 @@ type API interface {
 	// of nodeIP address. If the channel is not ready to receive notification, the notification is dropped.
 	WatchNodeIP(subscriber chan string)
 
+	// WatchNodeConfig adds given channel to the list of subscribers that are notified when the configuration
+	// of this node (default gateway, other VPP interfaces, NatExternalTraffic) is changed at runtime.
+	// If the channel is not ready to receive notification, the notification is dropped.
+	WatchNodeConfig(subscriber chan struct{})
+
 	// GetMainPhysicalIfName returns name of the "main" interface - i.e. physical interface connecting
 	// the node with the rest of the cluster.
 	GetMainPhysicalIfName() string
@@ -64,10 +73,28 @@ type API interface {
 	// Returns an empty string if VXLAN is not used (in L2 interconnect mode).
 	GetVxlanBVIIfName() string
 
+	// GetOverlayTunnelIfNames returns the names of the tunnels towards the other nodes which terminate
+	// the traffic of the overlay themselves (IP-in-IP), i.e. which are used instead of the VXLAN BVI.
+	// Returns an empty slice for the VXLAN and the L2 overlay.
+	GetOverlayTunnelIfNames() []string
+
+	// WatchOverlayTunnels adds given channel to the list of subscribers that are notified when the tunnels
+	// returned by GetOverlayTunnelIfNames change (a node joins or leaves the cluster).
+	// If the channel is not ready to receive notification, the notification is dropped.
+	WatchOverlayTunnels(subscriber chan struct{})
+
 	// GetDefaultGatewayIP returns the IP address of the default gateway for external traffic.
 	// If the default GW is not configured, the function returns nil.
 	GetDefaultGatewayIP() net.IP
 
+	// GetNamespaceTenant returns the name of the tenant whose network connects the PODs of the given namespace.
+	// Returns an empty string for the namespaces connected to the pod network.
+	GetNamespaceTenant(namespace string) string
+
+	// ExecuteVppCLI executes a VPP CLI command and returns its output. It is meant for the show commands
+	// reading the VPP state which is not available through the binary API (e.g. the ACL hit counters).
+	ExecuteVppCLI(cmd string) (string, error)
+
 	// RegisterPodPreRemovalHook allows to register callback that will be run for each
 	// pod immediately before its removal.
 	RegisterPodPreRemovalHook(hook PodActionHook)
@@ -14,6 +14,9 @@
 
 //go:generate protoc -I ./model/cni --go_out=plugins=grpc:./model/cni ./model/cni/cni.proto
 //go:generate protoc -I ./model/node --go_out=plugins=grpc:./model/node ./model/node/node.proto
+//go:generate protoc -I ./model/egress --go_out=plugins=grpc:./model/egress ./model/egress/egress.proto
+//go:generate protoc -I ./model/nodeconfig --go_out=plugins=grpc:./model/nodeconfig ./model/nodeconfig/nodeconfig.proto
+//go:generate protoc -I ./model/query --go_out=plugins=grpc:./model/query ./model/query/query.proto
 
 package contiv
 
@@ -21,15 +24,22 @@ import (
 	"context"
 	"fmt"
 	"net"
-
 	"strings"
+	"time"
 
 	"git.fd.io/govpp.git/api"
+	"github.com/contiv/vpp/flavors/ksr"
 	"github.com/contiv/vpp/plugins/contiv/containeridx"
 	"github.com/contiv/vpp/plugins/contiv/containeridx/model"
 	"github.com/contiv/vpp/plugins/contiv/ipam"
+	ipamModel "github.com/contiv/vpp/plugins/contiv/ipam/model"
 	"github.com/contiv/vpp/plugins/contiv/model/cni"
+	"github.com/contiv/vpp/plugins/contiv/model/egress"
+	"github.com/contiv/vpp/plugins/contiv/model/nodeconfig"
+	"github.com/contiv/vpp/plugins/contiv/model/query"
+	nsmodel "github.com/contiv/vpp/plugins/ksr/model/namespace"
 	protoNode "github.com/contiv/vpp/plugins/ksr/model/node"
+	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
 	"github.com/contiv/vpp/plugins/kvdbproxy"
 	"github.com/ligato/cn-infra/datasync"
 	"github.com/ligato/cn-infra/datasync/resync"
@@ -37,6 +47,9 @@ import (
 	"github.com/ligato/cn-infra/flavors/local"
 	"github.com/ligato/cn-infra/logging"
 	"github.com/ligato/cn-infra/rpc/grpc"
+	"github.com/ligato/cn-infra/rpc/prometheus"
+	"github.com/ligato/cn-infra/rpc/rest"
+	"github.com/ligato/cn-infra/servicelabel"
 	"github.com/ligato/cn-infra/utils/safeclose"
 	"github.com/ligato/vpp-agent/clientv1/linux"
 	linuxlocalclient "github.com/ligato/vpp-agent/clientv1/linux/localclient"
@@ -67,18 +80,23 @@ type Plugin struct {
 	Config        *Config
 	myNodeConfig  *OneNodeConfig
 	nodeIPWatcher chan string
+
+	ipsecKeyGenerationWatcher chan uint32
+	nodeIDReleaseWatcher      chan struct{}
 }
 
 // Deps groups the dependencies of the Plugin.
 type Deps struct {
 	local.PluginInfraDeps
-	GRPC    grpc.Server
-	Proxy   *kvdbproxy.Plugin
-	VPP     *defaultplugins.Plugin
-	GoVPP   govppmux.API
-	Resync  resync.Subscriber
-	ETCD    *etcdv3.Plugin
-	Watcher datasync.KeyValProtoWatcher
+	GRPC       grpc.Server
+	Proxy      *kvdbproxy.Plugin
+	VPP        *defaultplugins.Plugin
+	GoVPP      govppmux.API
+	Resync     resync.Subscriber
+	ETCD       *etcdv3.Plugin
+	Watcher    datasync.KeyValProtoWatcher
+	Prometheus prometheus.API    // optional, exports the metrics of the CNI server
+	HTTP       rest.HTTPHandlers // optional, serves the log of the recent CNI requests and the query API
 }
 
 // Config represents configuration for the Contiv plugin.
@@ -87,17 +105,27 @@ type Deps struct {
 type Config struct {
 	TCPChecksumOffloadDisabled bool
 	TCPstackDisabled           bool
-	UseL2Interconnect          bool
+	UseL2Interconnect          bool   // alias of the L2 overlay, kept for backward compatibility
+	Overlay                    string // interconnect of the nodes: "vxlan" (default), "l2" or "ipip"
 	UseTAPInterfaces           bool
 	TAPInterfaceVersion        uint8
 	TAPv2RxRingSize            uint16
 	TAPv2TxRingSize            uint16
 	MTUSize                    uint32
+	MemifSocketDir             string // host directory with the memif sockets of the pods connected via memif
+	MemifPodDir                string // directory where the pods connected via memif mount their memif socket directory
 	StealFirstNIC              bool
 	StealInterface             string
 	NatExternalTraffic         bool // if enabled, traffic with cluster-outside destination is SNATed on node output (for all nodes)
 	IPAMConfig                 ipam.Config
 	NodeConfig                 []OneNodeConfig
+	SecondaryNetworks          []SecondaryNetworkConfig // networks that pods may be attached to in addition to the pod network
+	StalePodCleanupInterval    uint32                   // interval of the periodic cleanup of stale pods in seconds (default is 5 minutes)
+	IPSec                      IPSecConfig              // encryption of the VXLAN traffic between the nodes
+	Tenants                    []TenantConfig           // tenants isolated in their own VRFs, selected by the namespace label
+	TenantLabel                string                   // namespace label with the name of the tenant (default "contiv.vpp/tenant")
+	Egress                     EgressConfig             // egress gateways, SNAT of the traffic of the selected pods to the egress IPs
+	UplinkBFD                  BFDConfig                // detection of the failures of the uplinks (OneNodeConfig.Uplinks)
 }
 
 // OneNodeConfig represents configuration for one node. It contains only settings specific to given node.
@@ -108,6 +136,24 @@ type OneNodeConfig struct {
 	StealInterface     string            // interface to be stolen from the host stack and bound to VPP
 	Gateway            string            // IP address of the default gateway
 	NatExternalTraffic bool              // if enabled, traffic with cluster-outside destination is SNATed on node output
+	Uplinks            []UplinkConfig    // uplinks balancing the traffic leaving the node (ECMP), replace the main VPP interface
+}
+
+// SecondaryNetworkConfig represents configuration of one secondary network.
+// The address pool of the network is configured in IPAMConfig.SecondaryNetworks under the same name.
+type SecondaryNetworkConfig struct {
+	Name  string // name of the network, used in the NETWORKS CNI argument
+	VrfID uint32 // VRF of the network on VPP, traffic is routed only between pods of the same network
+}
+
+// TenantConfig represents configuration of one tenant. The pods of the namespaces labeled with the name
+// of the tenant are connected to the VRF of the tenant instead of the default pod network.
+// The address pool of the tenant is configured in IPAMConfig.SecondaryNetworks under the same name.
+type TenantConfig struct {
+	Name           string   // name of the tenant, value of the TenantLabel namespace label
+	VrfID          uint32   // VRF of the tenant on VPP
+	Vni            uint32   // VNI of the VXLAN tunnels carrying the traffic of the tenant between the nodes
+	SharedPrefixes []string // prefixes of the default VRF reachable from the tenant (e.g. the IP of the DNS service)
 }
 
 // InterfaceWithIP binds interface name with IP address for configuration purposes.
@@ -132,7 +178,16 @@ func (plugin *Plugin) Init() error {
 		plugin.myNodeConfig = plugin.loadNodeSpecificConfig()
 	}
 
-	var err error
+	// the configuration of this node stored in ETCD takes precedence over the configuration file
+	nodeConfig, err := plugin.loadNodeConfigFromETCD()
+	if err != nil {
+		return err
+	}
+	if nodeConfig != nil {
+		plugin.Log.Info("Configuration of the node loaded from ETCD")
+		plugin.myNodeConfig = nodeConfig
+	}
+
 	plugin.govppCh, err = plugin.GoVPP.NewAPIChannel()
 	if err != nil {
 		return err
@@ -150,21 +205,38 @@ func (plugin *Plugin) Init() error {
 	}
 	plugin.Log.Infof("ID of the node is %v", nodeID)
 
+	// the first node with IPsec enabled generates the cluster secret
+	if plugin.Config.IPSec.Enabled {
+		if err := createIPSecKeyIfNotExists(plugin.ETCD); err != nil {
+			return err
+		}
+	}
+
 	plugin.nodeIDsresyncChan = make(chan datasync.ResyncEvent)
 	plugin.nodeIDSchangeChan = make(chan datasync.ChangeEvent)
 	plugin.resyncCh = make(chan datasync.ResyncEvent)
 	plugin.changeCh = make(chan datasync.ChangeEvent)
 
-	plugin.nodeIDwatchReg, err = plugin.Watcher.Watch("contiv-plugin-ids", plugin.nodeIDSchangeChan, plugin.nodeIDsresyncChan, AllocatedIDsKeyPrefix)
+	plugin.nodeIDwatchReg, err = plugin.Watcher.Watch("contiv-plugin-ids", plugin.nodeIDSchangeChan, plugin.nodeIDsresyncChan,
+		AllocatedIDsKeyPrefix, ipamModel.PodCIDRBlockKeyPrefix(), IPSecKeysKeyPrefix,
+		egress.PolicyKeyPrefix(), egress.GatewayKeyPrefix(), nodeconfig.KeyPrefix())
 	if err != nil {
 		return err
 	}
 
-	plugin.watchReg, err = plugin.Watcher.Watch("contiv-plugin-node", plugin.changeCh, plugin.resyncCh, protoNode.KeyPrefix())
+	plugin.watchReg, err = plugin.Watcher.Watch("contiv-plugin-node", plugin.changeCh, plugin.resyncCh,
+		protoNode.KeyPrefix(), podmodel.KeyPrefix(), nsmodel.KeyPrefix())
 	if err != nil {
 		return err
 	}
 
+	// pod CIDR blocks are allocated from ETCD only if enabled in the IPAM config
+	var blockStore ipam.PodCIDRBlockStore
+	if plugin.Config.IPAMConfig.DynamicPodCIDRBlocks {
+		blockStore = newPodCIDRBlockStore(plugin.ETCD)
+	}
+	egressStore := newEgressStore(plugin.ETCD)
+
 	// start the GRPC server handling the CNI requests
 	plugin.cniServer, err = newRemoteCNIServer(plugin.Log,
 		func() linux.DataChangeDSL {
@@ -179,19 +251,63 @@ func (plugin *Plugin) Init() error {
 		plugin.Config,
 		plugin.myNodeConfig,
 		nodeID,
-		broker)
+		broker,
+		blockStore,
+		egressStore)
 	if err != nil {
 		return fmt.Errorf("Can't create new remote CNI server due to error: %v ", err)
 	}
+	if plugin.Config.IPAMConfig.PodSubnetIPv6CIDR != "" {
+		// the IPv6 addresses of the PODs are reflected next to the PODs reflected by KSR
+		plugin.cniServer.podIPv6Broker = plugin.ETCD.NewBroker(servicelabel.GetDifferentAgentPrefix(ksr.MicroserviceLabel))
+	}
 	cni.RegisterRemoteCNIServer(plugin.GRPC.Server(), plugin.cniServer)
+	query.RegisterContivQueryServer(plugin.GRPC.Server(), plugin.cniServer)
+
+	// export the metrics of the CNI server and the log of the recent CNI requests
+	if plugin.Prometheus != nil {
+		err = plugin.cniServer.metrics.register(plugin.Prometheus, plugin.ServiceLabel.GetAgentLabel(),
+			plugin.cniServer.ipam.PodIPPoolUsage, plugin.configuredPodCount)
+		if err != nil {
+			return fmt.Errorf("Can't register the metrics of the CNI server: %v", err)
+		}
+	}
+	if plugin.HTTP != nil {
+		plugin.HTTP.RegisterHTTPHandler(CNIRequestsPath, plugin.cniServer.metrics.recentRequestsHandler, "GET")
+
+		// read-only REST API querying the runtime state of the plugin
+		for path, handler := range plugin.cniServer.queryHandlers() {
+			plugin.HTTP.RegisterHTTPHandler(path, handler, "GET")
+		}
+	}
 
 	plugin.nodeIPWatcher = make(chan string, 1)
+	plugin.ipsecKeyGenerationWatcher = make(chan uint32, 1)
+	plugin.nodeIDReleaseWatcher = make(chan struct{}, 1)
 	go plugin.watchEvents()
 	plugin.cniServer.WatchNodeIP(plugin.nodeIPWatcher)
+	plugin.cniServer.WatchIPSecKeyGeneration(plugin.ipsecKeyGenerationWatcher)
+	plugin.cniServer.WatchNodeIDRelease(plugin.nodeIDReleaseWatcher)
 
 	// start goroutine handling changes in nodes within the k8s cluster
 	go plugin.cniServer.handleNodeEvents(plugin.ctx, plugin.nodeIDsresyncChan, plugin.nodeIDSchangeChan)
 
+	// start goroutine periodically removing wiring of the pods deleted without the CNI Delete request
+	go plugin.cniServer.periodicStalePodCleanup()
+
+	// start goroutine withdrawing the routes via the failed uplinks
+	go plugin.cniServer.periodicUplinkCheck()
+
+	// start goroutine announcing this node as a live egress gateway
+	if plugin.Config.Egress.IPPool != "" {
+		ttl := plugin.Config.Egress.GatewayTTL
+		if ttl == 0 {
+			ttl = defaultEgressGatewayTTL
+		}
+		go egressStore.announceGateway(plugin.ctx, plugin.Log, plugin.ServiceLabel.GetAgentLabel(),
+			time.Duration(ttl)*time.Second)
+	}
+
 	return nil
 }
 
@@ -266,11 +382,26 @@ func (plugin *Plugin) GetNsIndex(podNamespace string, podName string) (nsIndex u
 	return 0, false
 }
 
+// configuredPodCount returns the number of the containers connected by the CNI server.
+func (plugin *Plugin) configuredPodCount() int {
+	return len(plugin.configuredContainers.ListAll())
+}
+
 // GetPodNetwork provides subnet used for allocating pod IP addresses on this node.
 func (plugin *Plugin) GetPodNetwork() *net.IPNet {
 	return plugin.cniServer.ipam.PodNetwork()
 }
 
+// IsLocalPodIP returns true if the given IP address belongs to any of the pod networks of this node.
+func (plugin *Plugin) IsLocalPodIP(ip net.IP) bool {
+	for _, podNetwork := range plugin.cniServer.ipam.PodNetworks() {
+		if podNetwork.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
 // GetContainerIndex returns the index of configured containers/pods
 func (plugin *Plugin) GetContainerIndex() containeridx.Reader {
 	return plugin.configuredContainers
@@ -284,11 +415,7 @@ func (plugin *Plugin) IsTCPstackDisabled() bool {
 // NatExternalTraffic returns true if traffic with cluster-outside destination should be S-NATed
 // with node IP before being sent out from the node.
 func (plugin *Plugin) NatExternalTraffic() bool {
-	if plugin.Config.NatExternalTraffic ||
-		(plugin.myNodeConfig != nil && plugin.myNodeConfig.NatExternalTraffic) {
-		return true
-	}
-	return false
+	return plugin.cniServer.NatExternalTraffic()
 }
 
 // GetNodeIP returns the IP address of this node.
@@ -302,6 +429,13 @@ func (plugin *Plugin) WatchNodeIP(subscriber chan string) {
 	plugin.cniServer.WatchNodeIP(subscriber)
 }
 
+// WatchNodeConfig adds given channel to the list of subscribers that are notified when the configuration
+// of this node (default gateway, other VPP interfaces, NatExternalTraffic) is changed at runtime.
+// If the channel is not ready to receive notification, the notification is dropped.
+func (plugin *Plugin) WatchNodeConfig(subscriber chan struct{}) {
+	plugin.cniServer.WatchNodeConfig(subscriber)
+}
+
 // GetMainPhysicalIfName returns name of the "main" interface - i.e. physical interface connecting
 // the node with the rest of the cluster.
 func (plugin *Plugin) GetMainPhysicalIfName() string {
@@ -326,12 +460,38 @@ func (plugin *Plugin) GetVxlanBVIIfName() string {
 	return plugin.cniServer.GetVxlanBVIIfName()
 }
 
+// GetOverlayTunnelIfNames returns the names of the tunnels towards the other nodes which terminate
+// the traffic of the overlay themselves (IP-in-IP), i.e. which are used instead of the VXLAN BVI.
+// Returns an empty slice for the VXLAN and the L2 overlay.
+func (plugin *Plugin) GetOverlayTunnelIfNames() []string {
+	return plugin.cniServer.GetOverlayTunnelIfNames()
+}
+
+// WatchOverlayTunnels adds given channel to the list of subscribers that are notified when the tunnels
+// returned by GetOverlayTunnelIfNames change. If the channel is not ready to receive notification,
+// the notification is dropped.
+func (plugin *Plugin) WatchOverlayTunnels(subscriber chan struct{}) {
+	plugin.cniServer.WatchOverlayTunnels(subscriber)
+}
+
 // GetDefaultGatewayIP returns the IP address of the default gateway for external traffic.
 // If the default GW is not configured, the function returns nil.
 func (plugin *Plugin) GetDefaultGatewayIP() net.IP {
 	return plugin.cniServer.GetDefaultGatewayIP()
 }
 
+// GetNamespaceTenant returns the name of the tenant whose network connects the PODs of the given namespace.
+// Returns an empty string for the namespaces connected to the pod network.
+func (plugin *Plugin) GetNamespaceTenant(namespace string) string {
+	return plugin.cniServer.GetNamespaceTenant(namespace)
+}
+
+// ExecuteVppCLI executes a VPP CLI command and returns its output. It is meant for the show commands
+// reading the VPP state which is not available through the binary API (e.g. the ACL hit counters).
+func (plugin *Plugin) ExecuteVppCLI(cmd string) (string, error) {
+	return plugin.cniServer.ExecuteVppCLI(cmd)
+}
+
 // RegisterPodPreRemovalHook allows to register callback that will be run for each
 // pod immediately before its removal.
 func (plugin *Plugin) RegisterPodPreRemovalHook(hook PodActionHook) {
@@ -387,6 +547,21 @@ func (plugin *Plugin) loadNodeSpecificConfig() *OneNodeConfig {
 	return nil
 }
 
+// loadNodeConfigFromETCD loads the configuration of this node from ETCD, returns nil if the node
+// has no configuration stored in ETCD.
+func (plugin *Plugin) loadNodeConfigFromETCD() (*OneNodeConfig, error) {
+	broker := plugin.ETCD.NewBroker(servicelabel.GetDifferentAgentPrefix(ksr.MicroserviceLabel))
+	value := &nodeconfig.NodeConfig{}
+	found, _, err := broker.GetValue(nodeconfig.Key(plugin.ServiceLabel.GetAgentLabel()), value)
+	if err != nil {
+		return nil, fmt.Errorf("can't load the configuration of the node from ETCD: %v", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return nodeConfigFromProto(value), nil
+}
+
 // getContainerConfig returns the configuration of the container associated with the given POD name.
 func (plugin *Plugin) getContainerConfig(podNamespace string, podName string) *container.Persisted {
 	podNamesMatch := plugin.configuredContainers.LookupPodName(podName)
@@ -416,11 +591,28 @@ func (plugin *Plugin) watchEvents() {
 					plugin.Log.Error(err)
 				}
 			}
+		case generation := <-plugin.ipsecKeyGenerationWatcher:
+			err := plugin.nodeIDAllocator.updateIPSecKeyGeneration(generation)
+			if err != nil {
+				plugin.Log.Error(err)
+			}
+		case <-plugin.nodeIDReleaseWatcher:
+			// the node is still alive, the ID released by the decommissioning is claimed back if still free
+			err := plugin.nodeIDAllocator.reclaimID()
+			if err != nil {
+				plugin.Log.Error(err)
+			} else {
+				plugin.Log.Info("Released ID of the node claimed back")
+			}
 		case changeEv := <-plugin.changeCh:
 			var err error
 			key := changeEv.GetKey()
 			if strings.HasPrefix(key, protoNode.KeyPrefix()) {
 				err = plugin.handleKsrNodeChange(changeEv)
+			} else if strings.HasPrefix(key, podmodel.KeyPrefix()) {
+				err = plugin.handleKsrPodChange(changeEv)
+			} else if strings.HasPrefix(key, nsmodel.KeyPrefix()) {
+				err = plugin.handleKsrNamespaceChange(changeEv)
 			} else {
 				plugin.Log.Warn("Change for unknown key %v received", key)
 			}
@@ -432,6 +624,10 @@ func (plugin *Plugin) watchEvents() {
 			for prefix, it := range data {
 				if prefix == protoNode.KeyPrefix() {
 					err = plugin.handleKsrNodeResync(it)
+				} else if prefix == podmodel.KeyPrefix() {
+					err = plugin.handleKsrPodResync(it)
+				} else if prefix == nsmodel.KeyPrefix() {
+					err = plugin.handleKsrNamespaceResync(it)
 				}
 			}
 			resyncEv.Done(err)
@@ -504,3 +700,117 @@ func (plugin *Plugin) handleKsrNodeResync(it datasync.KeyValIterator) error {
 	}
 	return err
 }
+
+// handleKsrPodChange handles change event for the prefix where pod data
+// is stored by ksr. The aim is to apply the bandwidth limits and to learn
+// the CNI arguments requested by the pod annotations, to track the existing
+// pods for the cleanup of stale pods and to select the pods of the egress policies.
+func (plugin *Plugin) handleKsrPodChange(change datasync.ChangeEvent) error {
+	if change.GetChangeType() == datasync.Delete {
+		name, namespace, err := podmodel.ParsePodFromKey(change.GetKey())
+		if err != nil {
+			plugin.Log.Error(err)
+			return err
+		}
+		plugin.cniServer.deletePodBandwidth(podmodel.ID{Name: name, Namespace: namespace})
+		plugin.cniServer.deletePodArgs(podmodel.ID{Name: name, Namespace: namespace})
+		plugin.cniServer.deleteLivePod(podmodel.ID{Name: name, Namespace: namespace})
+		err = plugin.cniServer.deleteEgressPod(podmodel.ID{Name: name, Namespace: namespace})
+		if err != nil {
+			plugin.Log.Error(err)
+		}
+		return err
+	}
+	value := &podmodel.Pod{}
+	err := change.GetValue(value)
+	if err != nil {
+		plugin.Log.Error(err)
+		return err
+	}
+	// the arguments must be learned before the CNI Add requests waiting for the pod are unblocked
+	plugin.cniServer.updatePodArgs(value)
+	plugin.cniServer.updateLivePod(podmodel.GetID(value))
+	err = plugin.cniServer.updatePodBandwidth(value)
+	if err != nil {
+		plugin.Log.Error(err)
+	}
+	if egressErr := plugin.cniServer.updateEgressPod(value); egressErr != nil {
+		plugin.Log.Error(egressErr)
+		err = egressErr
+	}
+	return err
+}
+
+// handleKsrPodResync handles resync event for the prefix where pod data
+// is stored by ksr. The aim is to apply the bandwidth limits and to learn
+// the CNI arguments requested by the pod annotations, to clean up the pods
+// which no longer exist and to select the pods of the egress policies.
+func (plugin *Plugin) handleKsrPodResync(it datasync.KeyValIterator) error {
+	var pods []*podmodel.Pod
+	for {
+		kv, stop := it.GetNext()
+		if stop {
+			break
+		}
+		value := &podmodel.Pod{}
+		err := kv.GetValue(value)
+		if err != nil {
+			return err
+		}
+		pods = append(pods, value)
+	}
+	// the arguments must be learned before the CNI Add requests waiting for the pods are unblocked
+	plugin.cniServer.resyncPodArgs(pods)
+	plugin.cniServer.resyncLivePods(pods)
+	err := plugin.cniServer.resyncPodBandwidth(pods)
+	if err != nil {
+		plugin.Log.Error(err)
+	}
+	if egressErr := plugin.cniServer.resyncEgressPods(pods); egressErr != nil {
+		plugin.Log.Error(egressErr)
+		err = egressErr
+	}
+	return err
+}
+
+// handleKsrNamespaceChange handles change event for the prefix where namespace data
+// is stored by ksr. The aim is to learn the tenants of the namespaces from their labels.
+func (plugin *Plugin) handleKsrNamespaceChange(change datasync.ChangeEvent) error {
+	if change.GetChangeType() == datasync.Delete {
+		name, err := nsmodel.ParseNamespaceFromKey(change.GetKey())
+		if err != nil {
+			plugin.Log.Error(err)
+			return err
+		}
+		plugin.cniServer.deleteNamespace(name)
+		return nil
+	}
+	value := &nsmodel.Namespace{}
+	err := change.GetValue(value)
+	if err != nil {
+		plugin.Log.Error(err)
+		return err
+	}
+	plugin.cniServer.updateNamespace(value)
+	return nil
+}
+
+// handleKsrNamespaceResync handles resync event for the prefix where namespace data
+// is stored by ksr. The aim is to learn the tenants of the namespaces from their labels.
+func (plugin *Plugin) handleKsrNamespaceResync(it datasync.KeyValIterator) error {
+	var namespaces []*nsmodel.Namespace
+	for {
+		kv, stop := it.GetNext()
+		if stop {
+			break
+		}
+		value := &nsmodel.Namespace{}
+		err := kv.GetValue(value)
+		if err != nil {
+			return err
+		}
+		namespaces = append(namespaces, value)
+	}
+	plugin.cniServer.resyncNamespaces(namespaces)
+	return nil
+}
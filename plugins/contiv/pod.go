@@ -40,6 +40,9 @@ type PodConfig struct {
 	PodName string
 	// PodNamespace from the CNI request
 	PodNamespace string
+	// Tenant is the name of the tenant of the POD namespace.
+	// Empty if the pod is connected to the default pod network.
+	Tenant string
 	// Veth1 one end end of veth pair that is in the given container namespace.
 	// Nil if TAPs are used instead.
 	Veth1 *linux_intf.LinuxInterfaces_Interface
@@ -51,6 +54,9 @@ type PodConfig struct {
 	// PodTap is the host end of the tap connecting pod to VPP
 	// Nil if TAPs are not used
 	PodTap *linux_intf.LinuxInterfaces_Interface
+	// MemifSocket is path to the socket of the memif connecting pod to VPP (VppIf is then the memif).
+	// Empty if the pod is not connected via memif.
+	MemifSocket string
 	// Loopback interface associated with the pod.
 	// Nil if VPP TCP stack is disabled.
 	Loopback *vpp_intf.Interfaces_Interface
@@ -70,6 +76,23 @@ type PodConfig struct {
 	PodLinkRoute *linux_l3.LinuxStaticRoutes_Route
 	// PodDefaultRoute is the default gateway for the pod.
 	PodDefaultRoute *linux_l3.LinuxStaticRoutes_Route
+	// VppARPEntryIPv6 is IPv6 neighbor entry configured in VPP to route traffic from VPP to pod.
+	// Nil if IPv6 is not enabled.
+	VppARPEntryIPv6 *vpp_l3.ArpTable_ArpTableEntry
+	// PodARPEntryIPv6 is IPv6 neighbor entry configured in the pod to route traffic from pod to VPP.
+	// Nil if IPv6 is not enabled.
+	PodARPEntryIPv6 *linux_l3.LinuxStaticArpEntries_ArpEntry
+	// VppRouteIPv6 is the IPv6 route from VPP to the container.
+	// Nil if IPv6 is not enabled.
+	VppRouteIPv6 *vpp_l3.StaticRoutes_Route
+	// PodLinkRouteIPv6 is the IPv6 route from pod to the default gateway.
+	// Nil if IPv6 is not enabled.
+	PodLinkRouteIPv6 *linux_l3.LinuxStaticRoutes_Route
+	// PodDefaultRouteIPv6 is the IPv6 default gateway for the pod.
+	// Nil if IPv6 is not enabled.
+	PodDefaultRouteIPv6 *linux_l3.LinuxStaticRoutes_Route
+	// SecondaryIfs are the pod interfaces attached to the secondary networks.
+	SecondaryIfs []*SecondaryIfConfig
 }
 
 // podConfigToProto transform config structure to structure that will be persisted
@@ -79,6 +102,7 @@ func podConfigToProto(cfg *PodConfig) *container.Persisted {
 	persisted.ID = cfg.ID
 	persisted.PodName = cfg.PodName
 	persisted.PodNamespace = cfg.PodNamespace
+	persisted.Tenant = cfg.Tenant
 	if cfg.Veth1 != nil {
 		persisted.Veth1Name = cfg.Veth1.Name
 	}
@@ -91,6 +115,7 @@ func podConfigToProto(cfg *PodConfig) *container.Persisted {
 	if cfg.PodTap != nil {
 		persisted.PodTapName = cfg.PodTap.Name
 	}
+	persisted.MemifSocket = cfg.MemifSocket
 	if cfg.Loopback != nil {
 		persisted.LoopbackName = cfg.Loopback.Name
 	}
@@ -118,6 +143,24 @@ func podConfigToProto(cfg *PodConfig) *container.Persisted {
 	if cfg.PodDefaultRoute != nil {
 		persisted.PodDefaultRouteName = cfg.PodDefaultRoute.Name
 	}
+	if cfg.VppARPEntryIPv6 != nil {
+		persisted.VppARPEntryIPv6 = cfg.VppARPEntryIPv6.IpAddress
+	}
+	if cfg.PodARPEntryIPv6 != nil {
+		persisted.PodARPEntryIPv6Name = cfg.PodARPEntryIPv6.Name
+	}
+	if cfg.VppRouteIPv6 != nil {
+		persisted.VppRouteIPv6Dest = cfg.VppRouteIPv6.DstIpAddr
+	}
+	if cfg.PodLinkRouteIPv6 != nil {
+		persisted.PodLinkRouteIPv6Name = cfg.PodLinkRouteIPv6.Name
+	}
+	if cfg.PodDefaultRouteIPv6 != nil {
+		persisted.PodDefaultRouteIPv6Name = cfg.PodDefaultRouteIPv6.Name
+	}
+	for _, secondaryIf := range cfg.SecondaryIfs {
+		persisted.SecondaryInterfaces = append(persisted.SecondaryInterfaces, secondaryIfToProto(secondaryIf))
+	}
 
 	return persisted
 }
@@ -235,16 +278,51 @@ func (s *remoteCNIserver) loopbackNameFromRequest(request *cni.CNIRequest) strin
 	return "loop" + s.veth2NameFromRequest(request)
 }
 
-func (s *remoteCNIserver) ipAddrForPodVPPIf(podIP string) string {
-	tapPrefix, _ := ipv4ToUint32(*s.ipam.VPPIfIPPrefix())
+// ipAddrForPodVPPIf returns the address of the VPP end of the pod interconnect
+// (with full-length prefix) for the given pod IP address (IPv4 or IPv6).
+func (s *remoteCNIserver) ipAddrForPodVPPIf(podIP net.IP) string {
+	var (
+		prefix  net.IP
+		podMask net.IPMask
+	)
+	if podIP.To4() != nil {
+		podIP = podIP.To4()
+		prefix = s.ipam.VPPIfIPPrefix().To4()
+		podMask = s.ipam.PodNetwork().Mask
+		if s.ipam.DynamicPodCIDRBlocks() {
+			// pod IPs may come from multiple blocks, keep all host bits of the VPP-side subnet
+			// so that interfaces of pods from different blocks do not share the same address
+			podMask = s.ipam.VPPIfIPNetwork().Mask
+		}
+	} else {
+		podIP = podIP.To16()
+		prefix = s.ipam.VPPIfIPv6Prefix().To16()
+		podMask = s.ipam.PodNetworkIPv6().Mask
+	}
 
-	podAddr, _ := ipv4ToUint32(net.ParseIP(podIP))
-	podMask, _ := ipv4ToUint32(net.IP(s.ipam.PodNetwork().Mask))
-	podSuffix := podAddr &^ podMask
+	ifAddress := make(net.IP, len(podIP))
+	for i := range podIP {
+		ifAddress[i] = prefix[i] | (podIP[i] &^ podMask[i])
+	}
+	return ipWithFullPrefix(ifAddress)
+}
 
-	tapAddress := uint32ToIpv4(tapPrefix + podSuffix)
+// podIPAddresses returns addresses of the pod interface (IPv4 and optionally IPv6) with full-length prefix.
+func (s *remoteCNIserver) podIPAddresses(podIP, podIPv6 net.IP) []string {
+	addrs := []string{ipWithFullPrefix(podIP)}
+	if podIPv6 != nil {
+		addrs = append(addrs, ipWithFullPrefix(podIPv6))
+	}
+	return addrs
+}
 
-	return net.IP.String(tapAddress) + "/32"
+// vppIfIPAddresses returns addresses of the VPP end of the pod interconnect (IPv4 and optionally IPv6).
+func (s *remoteCNIserver) vppIfIPAddresses(podIP, podIPv6 net.IP) []string {
+	addrs := []string{s.ipAddrForPodVPPIf(podIP)}
+	if podIPv6 != nil {
+		addrs = append(addrs, s.ipAddrForPodVPPIf(podIPv6))
+	}
+	return addrs
 }
 
 func (s *remoteCNIserver) hwAddrForContainer() string {
@@ -259,7 +337,7 @@ func (s *remoteCNIserver) generateHwAddrForPodVPPIf() string {
 	return hwAddr.String()
 }
 
-func (s *remoteCNIserver) veth1FromRequest(request *cni.CNIRequest, podIP string) *linux_intf.LinuxInterfaces_Interface {
+func (s *remoteCNIserver) veth1FromRequest(request *cni.CNIRequest, podIPs []string) *linux_intf.LinuxInterfaces_Interface {
 	return &linux_intf.LinuxInterfaces_Interface{
 		Name:        s.veth1NameFromRequest(request),
 		Type:        linux_intf.LinuxInterfaces_VETH,
@@ -270,7 +348,7 @@ func (s *remoteCNIserver) veth1FromRequest(request *cni.CNIRequest, podIP string
 		Veth: &linux_intf.LinuxInterfaces_Interface_Veth{
 			PeerIfName: s.veth2NameFromRequest(request),
 		},
-		IpAddresses: []string{podIP},
+		IpAddresses: podIPs,
 		Namespace: &linux_intf.LinuxInterfaces_Interface_Namespace{
 			Type:     linux_intf.LinuxInterfaces_Interface_Namespace_FILE_REF_NS,
 			Filepath: request.NetworkNamespace,
@@ -291,7 +369,7 @@ func (s *remoteCNIserver) veth2FromRequest(request *cni.CNIRequest) *linux_intf.
 	}
 }
 
-func (s *remoteCNIserver) afpacketFromRequest(request *cni.CNIRequest, podIP string, configureContainerProxy bool, containerProxyIP string) *vpp_intf.Interfaces_Interface {
+func (s *remoteCNIserver) afpacketFromRequest(request *cni.CNIRequest, vppIfIPs []string, configureContainerProxy bool, containerProxyIP string) *vpp_intf.Interfaces_Interface {
 	af := &vpp_intf.Interfaces_Interface{
 		Name:    s.afpacketNameFromRequest(request),
 		Type:    vpp_intf.InterfaceType_AF_PACKET_INTERFACE,
@@ -300,7 +378,7 @@ func (s *remoteCNIserver) afpacketFromRequest(request *cni.CNIRequest, podIP str
 		Afpacket: &vpp_intf.Interfaces_Interface_Afpacket{
 			HostIfName: s.veth2HostIfNameFromRequest(request),
 		},
-		IpAddresses: []string{s.ipAddrForPodVPPIf(podIP)},
+		IpAddresses: vppIfIPs,
 		PhysAddress: s.generateHwAddrForPodVPPIf(),
 	}
 	if configureContainerProxy {
@@ -309,7 +387,7 @@ func (s *remoteCNIserver) afpacketFromRequest(request *cni.CNIRequest, podIP str
 	return af
 }
 
-func (s *remoteCNIserver) tapFromRequest(request *cni.CNIRequest, podIP string, configureContainerProxy bool, containerProxyIP string) *vpp_intf.Interfaces_Interface {
+func (s *remoteCNIserver) tapFromRequest(request *cni.CNIRequest, vppIfIPs []string, configureContainerProxy bool, containerProxyIP string) *vpp_intf.Interfaces_Interface {
 	tap := &vpp_intf.Interfaces_Interface{
 		Name:    s.tapNameFromRequest(request),
 		Type:    vpp_intf.InterfaceType_TAP_INTERFACE,
@@ -318,7 +396,7 @@ func (s *remoteCNIserver) tapFromRequest(request *cni.CNIRequest, podIP string,
 		Tap: &vpp_intf.Interfaces_Interface_Tap{
 			HostIfName: s.tapTmpHostNameFromRequest(request),
 		},
-		IpAddresses: []string{s.ipAddrForPodVPPIf(podIP)},
+		IpAddresses: vppIfIPs,
 		PhysAddress: s.generateHwAddrForPodVPPIf(),
 	}
 	if s.tapVersion == 2 {
@@ -332,7 +410,7 @@ func (s *remoteCNIserver) tapFromRequest(request *cni.CNIRequest, podIP string,
 	return tap
 }
 
-func (s *remoteCNIserver) podTAP(request *cni.CNIRequest, podIPNet *net.IPNet) *linux_intf.LinuxInterfaces_Interface {
+func (s *remoteCNIserver) podTAP(request *cni.CNIRequest, podIPs []string) *linux_intf.LinuxInterfaces_Interface {
 	return &linux_intf.LinuxInterfaces_Interface{
 		Name:    "pod-" + s.tapTmpHostNameFromRequest(request),
 		Type:    linux_intf.LinuxInterfaces_AUTO_TAP,
@@ -347,7 +425,7 @@ func (s *remoteCNIserver) podTAP(request *cni.CNIRequest, podIPNet *net.IPNet) *
 			Filepath: request.NetworkNamespace,
 		},
 		PhysAddress: s.hwAddrForContainer(),
-		IpAddresses: []string{podIPNet.String()},
+		IpAddresses: podIPs,
 	}
 }
 
@@ -417,6 +495,15 @@ func (s *remoteCNIserver) podArpEntry(request *cni.CNIRequest, ifName string, ma
 	}
 }
 
+// podArpEntryIPv6 returns IPv6 neighbor entry for the pod gateway configured inside the pod.
+func (s *remoteCNIserver) podArpEntryIPv6(request *cni.CNIRequest, ifName string, macAddr string) *linux_l3.LinuxStaticArpEntries_ArpEntry {
+	entry := s.podArpEntry(request, ifName, macAddr)
+	entry.Name = "IPV6-" + request.ContainerId
+	entry.IpFamily.Family = linux_l3.LinuxStaticArpEntries_ArpEntry_IpFamily_IPV6
+	entry.IpAddr = s.ipam.PodGatewayIPv6().String()
+	return entry
+}
+
 func (s *remoteCNIserver) podLinkRouteFromRequest(request *cni.CNIRequest, ifName string) *linux_l3.LinuxStaticRoutes_Route {
 	containerNs := &linux_l3.LinuxStaticRoutes_Route_Namespace{
 		Type:     linux_l3.LinuxStaticRoutes_Route_Namespace_FILE_REF_NS,
@@ -434,6 +521,14 @@ func (s *remoteCNIserver) podLinkRouteFromRequest(request *cni.CNIRequest, ifNam
 	}
 }
 
+// podLinkRouteIPv6FromRequest returns IPv6 link-scope route to the pod gateway configured inside the pod.
+func (s *remoteCNIserver) podLinkRouteIPv6FromRequest(request *cni.CNIRequest, ifName string) *linux_l3.LinuxStaticRoutes_Route {
+	route := s.podLinkRouteFromRequest(request, ifName)
+	route.Name = "LINK-IPV6-" + request.ContainerId
+	route.DstIpAddr = ipWithFullPrefix(s.ipam.PodGatewayIPv6())
+	return route
+}
+
 func (s *remoteCNIserver) podDefaultRouteFromRequest(request *cni.CNIRequest, ifName string) *linux_l3.LinuxStaticRoutes_Route {
 	containerNs := &linux_l3.LinuxStaticRoutes_Route_Namespace{
 		Type:     linux_l3.LinuxStaticRoutes_Route_Namespace_FILE_REF_NS,
@@ -451,20 +546,20 @@ func (s *remoteCNIserver) podDefaultRouteFromRequest(request *cni.CNIRequest, if
 	}
 }
 
-// ipv4ToUint32 is simple utility function for conversion between IPv4 and uint32.
-func ipv4ToUint32(ip net.IP) (uint32, error) {
-	ip = ip.To4()
-	if ip == nil {
-		return 0, fmt.Errorf("Ip address %v is not ipv4 address (or ipv6 convertible to ipv4 address)", ip)
-	}
-	var tmp uint32
-	for _, bytePart := range ip {
-		tmp = tmp<<8 + uint32(bytePart)
-	}
-	return tmp, nil
+// podDefaultRouteIPv6FromRequest returns IPv6 default route configured inside the pod.
+func (s *remoteCNIserver) podDefaultRouteIPv6FromRequest(request *cni.CNIRequest, ifName string) *linux_l3.LinuxStaticRoutes_Route {
+	route := s.podDefaultRouteFromRequest(request, ifName)
+	route.Name = "DEFAULT-IPV6-" + request.ContainerId
+	route.DstIpAddr = ipv6DefaultRoute
+	route.GwAddr = s.ipam.PodGatewayIPv6().String()
+	return route
 }
 
-// uint32ToIpv4 is simple utility function for conversion between IPv4 and uint32.
-func uint32ToIpv4(ip uint32) net.IP {
-	return net.IPv4(byte(ip>>24), byte(ip>>16), byte(ip>>8), byte(ip)).To4()
+// ipWithFullPrefix returns the given IP address in the CIDR notation with full-length prefix
+// (/32 for IPv4, /128 for IPv6).
+func ipWithFullPrefix(ip net.IP) string {
+	if ip.To4() != nil {
+		return ip.String() + "/32"
+	}
+	return ip.String() + "/128"
 }
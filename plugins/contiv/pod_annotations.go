@@ -0,0 +1,239 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contiv
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
+)
+
+const (
+	// podIPAnnotation is the POD annotation requesting a specific IPv4 address (the same as podIPExtraArg)
+	podIPAnnotation = "contiv.vpp/ip"
+
+	// podIPReservationAnnotation is the POD annotation requesting the IPv4 address reserved in the IPAM
+	// config under the given name (the same as podIPReservationExtraArg)
+	podIPReservationAnnotation = "contiv.vpp/ip-reservation"
+
+	// podStickyIPAnnotation is the POD annotation requesting the previous IPv4 address of the POD
+	// to be re-assigned when the POD is re-created (the same as podStickyIPExtraArg)
+	podStickyIPAnnotation = "contiv.vpp/sticky-ip"
+
+	// podNetworksAnnotation is the POD annotation with comma-separated list of secondary networks
+	// the POD should be attached to (the same as podNetworksExtraArg)
+	podNetworksAnnotation = "contiv.vpp/networks"
+
+	// podIfTypeAnnotation is the POD annotation selecting the type of the interface connecting the POD
+	// to VPP (the same as podIfTypeExtraArg)
+	podIfTypeAnnotation = "contiv.vpp/interface-type"
+
+	// statefulSetPodLabel is the label set by K8s on every POD of a StatefulSet, such PODs are sticky
+	// without the annotation
+	statefulSetPodLabel = "statefulset.kubernetes.io/pod-name"
+
+	// defaultPodReflectionTimeout is the time for which the CNI Add request waits for the POD to be reflected
+	// by KSR, so that the arguments requested by the POD annotations are known
+	defaultPodReflectionTimeout = 10 * time.Second
+
+	// defaultStickyPodIPHoldTime is the time for which the sticky IP address of a deleted POD is held
+	// for its re-creation
+	defaultStickyPodIPHoldTime = 300 * time.Second
+)
+
+// podAnnotationArgs maps the POD annotations to the CNI extra arguments requesting the same.
+// Kubelet passes only the K8S_POD_* arguments to the CNI, the annotations are therefore the way
+// to make these requests in Kubernetes.
+var podAnnotationArgs = map[string]string{
+	podIPAnnotation:            podIPExtraArg,
+	podIPReservationAnnotation: podIPReservationExtraArg,
+	podStickyIPAnnotation:      podStickyIPExtraArg,
+	podNetworksAnnotation:      podNetworksExtraArg,
+	podIfTypeAnnotation:        podIfTypeExtraArg,
+}
+
+// updatePodArgs is called when a POD is created or updated in ETCD by KSR. The arguments requested
+// by the POD annotations are stored to be used by the next CNI Add request of the POD.
+func (s *remoteCNIserver) updatePodArgs(pod *podmodel.Pod) {
+	s.Lock()
+	defer s.Unlock()
+
+	id := podmodel.GetID(pod)
+	s.setPodArgs(id, pod)
+	s.cancelStickyIPRelease(id)
+}
+
+// deletePodArgs is called when a POD is deleted from ETCD by KSR. The sticky IP address of the POD
+// is released unless the POD is re-created within the hold time.
+func (s *remoteCNIserver) deletePodArgs(id podmodel.ID) {
+	s.Lock()
+	defer s.Unlock()
+
+	delete(s.podArgs, id)
+	s.scheduleStickyIPRelease(id)
+}
+
+// resyncPodArgs replaces the arguments requested by the annotations of all PODs. The sticky IP addresses
+// of the PODs which no longer exist are released after the hold time.
+func (s *remoteCNIserver) resyncPodArgs(pods []*podmodel.Pod) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.podArgs = map[podmodel.ID]map[string]string{}
+	live := map[podmodel.ID]struct{}{}
+	for _, pod := range pods {
+		id := podmodel.GetID(pod)
+		s.setPodArgs(id, pod)
+		s.cancelStickyIPRelease(id)
+		live[id] = struct{}{}
+	}
+	if !s.ipam.StickyPodIPsEnabled() {
+		return
+	}
+	for _, podName := range s.ipam.StickyPods() {
+		id := stickyPodID(podName)
+		if _, isLive := live[id]; !isLive {
+			s.scheduleStickyIPRelease(id)
+		}
+	}
+}
+
+// setPodArgs stores the arguments requested by the POD annotations, only PODs with some such annotation are stored.
+// The PODs of StatefulSets request the sticky IP address implicitly.
+func (s *remoteCNIserver) setPodArgs(id podmodel.ID, pod *podmodel.Pod) {
+	args := map[string]string{}
+	for _, label := range pod.Label {
+		if label.Key == statefulSetPodLabel {
+			args[podStickyIPExtraArg] = "true"
+		}
+	}
+	for _, annotation := range pod.Annotation {
+		if arg, isArg := podAnnotationArgs[annotation.Key]; isArg {
+			args[arg] = annotation.Value
+		}
+	}
+	if len(args) == 0 {
+		delete(s.podArgs, id)
+	} else {
+		s.podArgs[id] = args
+	}
+}
+
+// waitForPodReflection waits until the POD is reflected by KSR, so that the arguments requested by its annotations
+// are known. An error is returned if the POD is not reflected within the timeout, the CNI Add request is then
+// retried by kubelet. The method must be called with the CNI server lock held.
+func (s *remoteCNIserver) waitForPodReflection(id podmodel.ID) error {
+	if id.Name == "" || s.podReflectionTimeout == 0 {
+		return nil
+	}
+	timedOut := false
+	timer := time.AfterFunc(s.podReflectionTimeout, func() {
+		s.Lock()
+		defer s.Unlock()
+		timedOut = true
+		s.podReflectedCond.Broadcast()
+	})
+	defer timer.Stop()
+
+	for !s.podReflected(id) {
+		if timedOut {
+			return fmt.Errorf("pod %v was not reflected by KSR within %v, its annotations are unknown",
+				id, s.podReflectionTimeout)
+		}
+		s.podReflectedCond.Wait()
+	}
+	return nil
+}
+
+// podReflected returns true if the POD was already reflected by KSR.
+func (s *remoteCNIserver) podReflected(id podmodel.ID) bool {
+	if s.livePods == nil {
+		return false
+	}
+	_, reflected := s.livePods[id]
+	return reflected
+}
+
+// addPodArgs adds the arguments requested by the annotations of the POD to the CNI extra arguments
+// of its CNI request. The arguments passed directly in the CNI request take precedence.
+// The method must be called with the CNI server lock held.
+func (s *remoteCNIserver) addPodArgs(id podmodel.ID, extraArgs map[string]string) {
+	for arg, value := range s.podArgs[id] {
+		if _, passed := extraArgs[arg]; !passed {
+			extraArgs[arg] = value
+		}
+	}
+}
+
+// stickyIPRequested returns true if the POD requests its previous IP address to be re-assigned when re-created.
+func stickyIPRequested(extraArgs map[string]string) (bool, error) {
+	value, requested := extraArgs[podStickyIPExtraArg]
+	if !requested {
+		return false, nil
+	}
+	switch strings.ToLower(value) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid value %q of %v", value, podStickyIPExtraArg)
+}
+
+// scheduleStickyIPRelease releases the sticky IP address of the deleted POD after the hold time,
+// unless the POD is re-created in the meantime. The method must be called with the CNI server lock held.
+func (s *remoteCNIserver) scheduleStickyIPRelease(id podmodel.ID) {
+	if !s.ipam.StickyPodIPsEnabled() || s.ipam.StickyPodIP(id.String()) == nil {
+		return
+	}
+	s.cancelStickyIPRelease(id)
+
+	var timer *time.Timer
+	timer = time.AfterFunc(s.stickyPodIPHoldTime, func() {
+		s.Lock()
+		defer s.Unlock()
+		if s.stickyIPReleases[id] != timer {
+			// cancelled or re-scheduled
+			return
+		}
+		delete(s.stickyIPReleases, id)
+		if err := s.ipam.ReleaseStickyPodIP(id.String()); err != nil {
+			s.Logger.Warnf("Failed to release sticky IP address of pod %v: %v", id, err)
+			return
+		}
+		s.Logger.Infof("Released sticky IP address of deleted pod %v", id)
+	})
+	s.stickyIPReleases[id] = timer
+}
+
+// cancelStickyIPRelease cancels the pending release of the sticky IP address of the POD.
+// The method must be called with the CNI server lock held.
+func (s *remoteCNIserver) cancelStickyIPRelease(id podmodel.ID) {
+	if timer, scheduled := s.stickyIPReleases[id]; scheduled {
+		timer.Stop()
+		delete(s.stickyIPReleases, id)
+	}
+}
+
+// stickyPodID parses the name (namespace/name) under which the sticky IP address of the POD is stored in IPAM.
+func stickyPodID(podName string) podmodel.ID {
+	id := podmodel.ID{Name: podName}
+	if i := strings.Index(podName, "/"); i >= 0 {
+		id = podmodel.ID{Namespace: podName[:i], Name: podName[i+1:]}
+	}
+	return id
+}
@@ -0,0 +1,205 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contiv
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/contiv/vpp/plugins/contiv/containeridx/model"
+	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// ingressBandwidthAnnotation is the POD annotation limiting the bandwidth of the traffic received by the POD
+	ingressBandwidthAnnotation = "kubernetes.io/ingress-bandwidth"
+
+	// egressBandwidthAnnotation is the POD annotation limiting the bandwidth of the traffic sent by the POD
+	egressBandwidthAnnotation = "kubernetes.io/egress-bandwidth"
+
+	// minPodBandwidth and maxPodBandwidth are the limits of the bandwidth accepted in the annotations
+	// (the same as enforced by kubelet for the kubenet bandwidth shaping)
+	minPodBandwidth = 1000             // 1 kbit/s
+	maxPodBandwidth = 1000000000000000 // 1 Pbit/s
+)
+
+// podBandwidth is the bandwidth of the POD traffic requested by the POD annotations, in bits per second.
+// Zero means that the bandwidth is not limited.
+type podBandwidth struct {
+	ingress uint64
+	egress  uint64
+}
+
+// bandwidthLimiter limits the bandwidth of the traffic sent and received by PODs.
+type bandwidthLimiter interface {
+	// setLimits limits the rates of the traffic sent and received by the POD with the given IP addresses
+	// (of any IP version) connected via the given VPP interface. An already configured limit of the container
+	// is replaced, zero bandwidth removes the limits.
+	setLimits(containerID string, vppIfName string, podIPs []net.IP, bandwidth podBandwidth) error
+
+	// removeLimits removes the limits of the container. Does nothing if no limit is configured.
+	removeLimits(containerID string) error
+
+	// updateInterfaces applies the ingress limits also to the traffic received from the VPP interfaces
+	// created since the last update (e.g. interfaces of the new PODs).
+	updateInterfaces() error
+}
+
+// parsePodBandwidth parses the bandwidth limits from the POD annotations.
+func parsePodBandwidth(annotations []*podmodel.Pod_Annotation) (bandwidth podBandwidth, err error) {
+	for _, annotation := range annotations {
+		switch annotation.Key {
+		case ingressBandwidthAnnotation:
+			bandwidth.ingress, err = parseBandwidth(annotation.Value)
+		case egressBandwidthAnnotation:
+			bandwidth.egress, err = parseBandwidth(annotation.Value)
+		}
+		if err != nil {
+			return podBandwidth{}, fmt.Errorf("invalid value of the annotation %s: %v", annotation.Key, err)
+		}
+	}
+	return bandwidth, nil
+}
+
+// parseBandwidth parses bandwidth in bits per second from its Kubernetes quantity representation (e.g. "10M").
+func parseBandwidth(value string) (uint64, error) {
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return 0, err
+	}
+	bandwidth := quantity.Value()
+	if bandwidth < minPodBandwidth || bandwidth > maxPodBandwidth {
+		return 0, fmt.Errorf("bandwidth %s is out of the range [1k, 1P]", value)
+	}
+	return uint64(bandwidth), nil
+}
+
+// updatePodBandwidth is called when a POD is created or its annotations are changed. The bandwidth limits
+// requested by the POD annotations are stored to be applied once the POD is connected and
+// (re)applied immediately if the POD is already connected.
+func (s *remoteCNIserver) updatePodBandwidth(pod *podmodel.Pod) error {
+	s.Lock()
+	defer s.Unlock()
+
+	id := podmodel.GetID(pod)
+	bandwidth, err := parsePodBandwidth(pod.Annotation)
+	if err != nil {
+		return fmt.Errorf("can't limit bandwidth of the pod %v: %v", id, err)
+	}
+	if bandwidth == s.podBandwidth[id] {
+		return nil
+	}
+	s.setPodBandwidth(id, bandwidth)
+	return s.applyPodBandwidth(id)
+}
+
+// deletePodBandwidth is called when a POD is deleted. The limits already applied in VPP are removed together
+// with the POD connectivity.
+func (s *remoteCNIserver) deletePodBandwidth(id podmodel.ID) {
+	s.Lock()
+	defer s.Unlock()
+
+	delete(s.podBandwidth, id)
+}
+
+// resyncPodBandwidth replaces the bandwidth limits of all PODs and re-applies the limits which have changed.
+func (s *remoteCNIserver) resyncPodBandwidth(pods []*podmodel.Pod) error {
+	s.Lock()
+	defer s.Unlock()
+
+	var wasErr error
+	oldBandwidth := s.podBandwidth
+	s.podBandwidth = map[podmodel.ID]podBandwidth{}
+	for _, pod := range pods {
+		id := podmodel.GetID(pod)
+		bandwidth, err := parsePodBandwidth(pod.Annotation)
+		if err != nil {
+			s.Logger.Warnf("Can't limit bandwidth of the pod %v: %v", id, err)
+			wasErr = err
+			continue
+		}
+		s.setPodBandwidth(id, bandwidth)
+	}
+
+	for id, bandwidth := range s.podBandwidth {
+		if bandwidth != oldBandwidth[id] {
+			if err := s.applyPodBandwidth(id); err != nil {
+				wasErr = err
+			}
+		}
+	}
+	for id := range oldBandwidth {
+		if _, exists := s.podBandwidth[id]; !exists {
+			if err := s.applyPodBandwidth(id); err != nil {
+				wasErr = err
+			}
+		}
+	}
+	return wasErr
+}
+
+// setPodBandwidth stores the bandwidth limits of the POD, only PODs with some limit are stored.
+func (s *remoteCNIserver) setPodBandwidth(id podmodel.ID, bandwidth podBandwidth) {
+	if bandwidth == (podBandwidth{}) {
+		delete(s.podBandwidth, id)
+	} else {
+		s.podBandwidth[id] = bandwidth
+	}
+}
+
+// applyPodBandwidth applies the stored bandwidth limits to all containers of the POD connected to VPP.
+// The limits are applied once the vswitch connectivity is configured (see resync).
+func (s *remoteCNIserver) applyPodBandwidth(id podmodel.ID) error {
+	if s.configuredContainers == nil || !s.vswitchConnectivityConfigured {
+		return nil
+	}
+	for _, containerID := range s.configuredContainers.LookupPodName(id.Name) {
+		config, found := s.configuredContainers.LookupContainer(containerID)
+		if !found || config.PodNamespace != id.Namespace {
+			continue
+		}
+		podIPs := []net.IP{net.ParseIP(config.VppARPEntryIP)}
+		if config.VppARPEntryIPv6 != "" {
+			podIPs = append(podIPs, net.ParseIP(config.VppARPEntryIPv6))
+		}
+		err := s.bandwidthLimiter.setLimits(config.ID, config.VppIfName, podIPs, s.podBandwidth[id])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// limitPodBandwidth applies the stored bandwidth limits to the POD being connected to VPP.
+// The traffic sent by the POD is policed on the input of the POD-facing VPP interface, the traffic
+// received by the POD on the input of the other VPP interfaces (VPP supports only input policers).
+// The ingress limits of the other PODs are therefore applied also to the traffic from the new interface.
+func (s *remoteCNIserver) limitPodBandwidth(config *PodConfig, podIP, podIPv6 net.IP) error {
+	bandwidth := s.podBandwidth[podmodel.ID{Name: config.PodName, Namespace: config.PodNamespace}]
+	if bandwidth == (podBandwidth{}) {
+		return s.bandwidthLimiter.updateInterfaces()
+	}
+	podIPs := []net.IP{podIP}
+	if podIPv6 != nil {
+		podIPs = append(podIPs, podIPv6)
+	}
+	return s.bandwidthLimiter.setLimits(config.ID, config.VppIf.Name, podIPs, bandwidth)
+}
+
+// unlimitPodBandwidth removes the bandwidth limits of the POD being disconnected from VPP.
+func (s *remoteCNIserver) unlimitPodBandwidth(config *container.Persisted) error {
+	return s.bandwidthLimiter.removeLimits(config.ID)
+}
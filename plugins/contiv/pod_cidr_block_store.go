@@ -0,0 +1,80 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contiv
+
+import (
+	"encoding/json"
+
+	"github.com/contiv/vpp/flavors/ksr"
+	"github.com/contiv/vpp/plugins/contiv/ipam/model"
+	"github.com/ligato/cn-infra/db/keyval"
+	"github.com/ligato/cn-infra/db/keyval/etcdv3"
+	"github.com/ligato/cn-infra/servicelabel"
+)
+
+// podCIDRBlockStore persists pod CIDR blocks allocated by IPAM in ETCD. The blocks are stored
+// under the same (cluster-wide) prefix as the allocated node IDs, so that every node
+// can watch and route the blocks owned by the other nodes.
+type podCIDRBlockStore struct {
+	etcd   *etcdv3.Plugin
+	broker keyval.ProtoBroker
+}
+
+// newPodCIDRBlockStore creates new instance of podCIDRBlockStore.
+func newPodCIDRBlockStore(etcd *etcdv3.Plugin) *podCIDRBlockStore {
+	return &podCIDRBlockStore{
+		etcd:   etcd,
+		broker: etcd.NewBroker(servicelabel.GetDifferentAgentPrefix(ksr.MicroserviceLabel)),
+	}
+}
+
+// ListPodCIDRBlocks returns all pod CIDR blocks allocated in the cluster.
+func (bs *podCIDRBlockStore) ListPodCIDRBlocks() ([]*model.PodCIDRBlock, error) {
+	it, err := bs.broker.ListValues(model.PodCIDRBlockKeyPrefix())
+	if err != nil {
+		return nil, err
+	}
+
+	var blocks []*model.PodCIDRBlock
+	for {
+		kv, stop := it.GetNext()
+		if stop {
+			break
+		}
+		block := &model.PodCIDRBlock{}
+		err = kv.GetValue(block)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+// ClaimPodCIDRBlock atomically allocates the given block. Returns false if the block is already allocated.
+func (bs *podCIDRBlockStore) ClaimPodCIDRBlock(block *model.PodCIDRBlock) (succeeded bool, err error) {
+	encoded, err := json.Marshal(block)
+	if err != nil {
+		return false, err
+	}
+	return bs.etcd.PutIfNotExists(servicelabel.GetDifferentAgentPrefix(ksr.MicroserviceLabel)+
+		model.PodCIDRBlockKey(block.Network), encoded)
+}
+
+// ReleasePodCIDRBlock returns the block back to the pool.
+func (bs *podCIDRBlockStore) ReleasePodCIDRBlock(network string) error {
+	_, err := bs.broker.Delete(model.PodCIDRBlockKey(network))
+	return err
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contiv
+
+import (
+	"net"
+
+	"github.com/contiv/vpp/plugins/contiv/containeridx/model"
+	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
+)
+
+// publishPodIPv6 reflects the IPv6 address of the POD into ETCD next to the POD data reflected by KSR.
+// K8s reports only the IPv4 address of the POD, the policies selecting the POD as a peer need also
+// the IPv6 address to allow the IPv6 traffic of the POD.
+func (s *remoteCNIserver) publishPodIPv6(config *PodConfig, podIPv6 net.IP) error {
+	if s.podIPv6Broker == nil || podIPv6 == nil {
+		return nil
+	}
+	return s.podIPv6Broker.Put(podmodel.IPv6Key(config.PodName, config.PodNamespace), &podmodel.Pod{
+		Name:        config.PodName,
+		Namespace:   config.PodNamespace,
+		Ipv6Address: podIPv6.String(),
+	})
+}
+
+// withdrawPodIPv6 removes the IPv6 address of the removed container from ETCD. The record is left
+// untouched if it was already overwritten by a newer container of the same POD.
+func (s *remoteCNIserver) withdrawPodIPv6(config *container.Persisted) error {
+	if s.podIPv6Broker == nil || config.VppRouteIPv6Dest == "" {
+		return nil
+	}
+	podIPv6, _, err := net.ParseCIDR(config.VppRouteIPv6Dest)
+	if err != nil {
+		return err
+	}
+	key := podmodel.IPv6Key(config.PodName, config.PodNamespace)
+	published := &podmodel.Pod{}
+	found, _, err := s.podIPv6Broker.GetValue(key, published)
+	if err != nil || !found {
+		return err
+	}
+	if !podIPv6.Equal(net.ParseIP(published.Ipv6Address)) {
+		return nil
+	}
+	_, err = s.podIPv6Broker.Delete(key)
+	return err
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contiv
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/contiv/vpp/plugins/contiv/containeridx/model"
+	"github.com/contiv/vpp/plugins/contiv/model/cni"
+	"github.com/ligato/vpp-agent/clientv1/linux"
+	vpp_intf "github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/interfaces"
+)
+
+const (
+	// podIfTypeExtraArg is the CNI extra argument selecting the type of the interface connecting the POD to VPP
+	podIfTypeExtraArg = "INTERFACE_TYPE"
+
+	// memifPodIfType is the value of podIfTypeExtraArg requesting memif connectivity
+	memifPodIfType = "memif"
+
+	// defaultMemifSocketDir is the host directory with the memif sockets used if MemifSocketDir is not configured
+	defaultMemifSocketDir = "/var/run/contiv/memif"
+
+	// defaultMemifPodDir is the directory where the PODs mount their memif socket directory used if MemifPodDir
+	// is not configured
+	defaultMemifPodDir = "/run/contiv/memif"
+
+	// memifNamePrefix is the prefix of the logical names of the memif interfaces connecting PODs to VPP
+	memifNamePrefix = "memif"
+
+	// memifSocketName is the name of the memif socket file in the POD directory
+	memifSocketName = "memif.sock"
+
+	// memifInfoName is the name of the file describing the memif connection in the POD directory
+	memifInfoName = "memif.json"
+
+	// podMemifID is the ID of the memif connecting a POD to VPP (each POD has its own socket with a single memif)
+	podMemifID uint32 = 0
+)
+
+// MemifInfo describes the memif connection of a POD. It is stored in JSON format in the POD directory
+// next to the memif socket, so that the application inside the POD can configure its end of the memif.
+type MemifInfo struct {
+	Socket    string `json:"socket"`              // path to the memif socket (as seen inside the POD)
+	ID        uint32 `json:"id"`                  // memif ID
+	IP        string `json:"ip"`                  // IPv4 address of the POD with full-length prefix
+	IPv6      string `json:"ipv6,omitempty"`      // IPv6 address of the POD with full-length prefix
+	Gateway   string `json:"gateway"`             // IPv4 gateway of the POD
+	GatewayV6 string `json:"gatewayV6,omitempty"` // IPv6 gateway of the POD
+	MAC       string `json:"mac"`                 // MAC address the POD end of the memif must use
+}
+
+// usesMemif returns true if memif connectivity is requested for the POD in the CNI extra arguments.
+func usesMemif(extraArgs map[string]string) (bool, error) {
+	switch extraArgs[podIfTypeExtraArg] {
+	case "":
+		return false, nil
+	case memifPodIfType:
+		return true, nil
+	}
+	return false, fmt.Errorf("unsupported pod interface type %q", extraArgs[podIfTypeExtraArg])
+}
+
+// memifSocketDir returns the host directory where the memif sockets of PODs are created.
+func (s *remoteCNIserver) memifSocketDir() string {
+	if s.config.MemifSocketDir != "" {
+		return s.config.MemifSocketDir
+	}
+	return defaultMemifSocketDir
+}
+
+// memifPodDir returns the directory where the PODs mount their memif socket directory.
+func (s *remoteCNIserver) memifPodDir() string {
+	if s.config.MemifPodDir != "" {
+		return s.config.MemifPodDir
+	}
+	return defaultMemifPodDir
+}
+
+// memifSocketFromRequest returns path to the memif socket of the POD, placed into
+// <memifSocketDir>/<pod namespace>/<pod name>/ (the POD is expected to mount this directory into memifPodDir).
+func (s *remoteCNIserver) memifSocketFromRequest(request *cni.CNIRequest, config *PodConfig) string {
+	podDir := filepath.Join(config.PodNamespace, config.PodName)
+	if config.PodName == "" {
+		podDir = request.ContainerId
+	}
+	return filepath.Join(s.memifSocketDir(), podDir, memifSocketName)
+}
+
+func (s *remoteCNIserver) memifNameFromRequest(request *cni.CNIRequest) string {
+	return memifNamePrefix + s.tapTmpHostNameFromRequest(request)
+}
+
+func (s *remoteCNIserver) memifFromRequest(request *cni.CNIRequest, socket string, vppIfIPs []string) *vpp_intf.Interfaces_Interface {
+	return &vpp_intf.Interfaces_Interface{
+		Name:    s.memifNameFromRequest(request),
+		Type:    vpp_intf.InterfaceType_MEMORY_INTERFACE,
+		Mtu:     s.config.MTUSize,
+		Enabled: true,
+		Memif: &vpp_intf.Interfaces_Interface_Memif{
+			Master:         true,
+			Mode:           vpp_intf.Interfaces_Interface_Memif_ETHERNET,
+			Id:             podMemifID,
+			SocketFilename: socket,
+		},
+		IpAddresses: vppIfIPs,
+		PhysAddress: s.generateHwAddrForPodVPPIf(),
+	}
+}
+
+// podUsesTCPStack returns true if the VPP TCP stack is configured for the POD. The TCP stack is not used
+// for PODs connected via memif, which run their own user-space networking, and for PODs of tenants,
+// since the VPP TCP stack is bound to the default VRF.
+func (s *remoteCNIserver) podUsesTCPStack(memifSocket string, tenant string) bool {
+	return !s.disableTCPstack && memifSocket == "" && tenant == ""
+}
+
+// configurePodMemif connects the POD to VPP via memif. There is no configuration inside the POD namespace,
+// the POD end of the memif is configured by the application in the POD based on the memif info file.
+func (s *remoteCNIserver) configurePodMemif(request *cni.CNIRequest, podIP, podIPv6 net.IP, config *PodConfig) error {
+	podDir := filepath.Dir(config.MemifSocket)
+	err := os.MkdirAll(podDir, 0755)
+	if err != nil {
+		return fmt.Errorf("Can't create directory for the memif socket: %v", err)
+	}
+
+	config.VppIf = s.memifFromRequest(request, config.MemifSocket, s.vppIfIPAddresses(podIP, podIPv6))
+	err = s.podTxns.commit(func(txn linux.DataChangeDSL) {
+		txn.Put().VppInterface(config.VppIf)
+	})
+	if err != nil {
+		os.RemoveAll(podDir)
+		return err
+	}
+
+	info := &MemifInfo{
+		Socket:  filepath.Join(s.memifPodDir(), memifSocketName),
+		ID:      podMemifID,
+		IP:      ipWithFullPrefix(podIP),
+		Gateway: s.ipam.PodGatewayIP().String(),
+		MAC:     s.hwAddrForContainer(),
+	}
+	if podIPv6 != nil {
+		info.IPv6 = ipWithFullPrefix(podIPv6)
+		info.GatewayV6 = s.ipam.PodGatewayIPv6().String()
+	}
+	infoJSON, _ := json.MarshalIndent(info, "", "  ")
+	err = ioutil.WriteFile(filepath.Join(podDir, memifInfoName), infoJSON, 0644)
+	if err != nil {
+		s.podTxns.commit(func(txn linux.DataChangeDSL) {
+			txn.Delete().VppInterface(config.VppIf.Name)
+		})
+		os.RemoveAll(podDir)
+		return fmt.Errorf("Can't write memif info file: %v", err)
+	}
+	return nil
+}
+
+// unconfigurePodMemif removes the memif connecting the POD to VPP together with the POD directory.
+func (s *remoteCNIserver) unconfigurePodMemif(config *container.Persisted) error {
+	err := s.podTxns.commit(func(txn linux.DataChangeDSL) {
+		txn.Delete().VppInterface(config.VppIfName)
+	})
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(filepath.Dir(config.MemifSocket))
+}
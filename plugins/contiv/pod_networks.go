@@ -0,0 +1,400 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contiv
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/contiv/vpp/plugins/contiv/containeridx/model"
+	"github.com/contiv/vpp/plugins/contiv/model/cni"
+	"github.com/gogo/protobuf/proto"
+	"github.com/ligato/vpp-agent/clientv1/linux"
+	vpp_intf "github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/interfaces"
+	vpp_l3 "github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/l3"
+	linux_intf "github.com/ligato/vpp-agent/plugins/linuxplugin/common/model/interfaces"
+	linux_l3 "github.com/ligato/vpp-agent/plugins/linuxplugin/common/model/l3"
+)
+
+const (
+	// podNetworksExtraArg is the CNI extra argument with comma-separated list of secondary networks
+	// the POD should be attached to
+	podNetworksExtraArg = "NETWORKS"
+
+	// secondaryIfNamePrefix is the prefix of the names of the POD interfaces in the secondary networks
+	// (the interfaces are numbered from 1, e.g. net1, net2)
+	secondaryIfNamePrefix = "net"
+
+	// secondaryNetworkLoopPrefix is the prefix of the name of the loopback holding the gateway IP of a secondary network
+	secondaryNetworkLoopPrefix = "loop-net-"
+)
+
+// SecondaryIfConfig groups applied configuration of a POD interface attached to a secondary network.
+type SecondaryIfConfig struct {
+	// Network is the name of the secondary network
+	Network string
+	// IfName is the name of the interface inside the POD
+	IfName string
+	// IP is the IP address assigned to the POD in the network
+	IP net.IP
+	// Veth1 is the end of veth pair in the POD namespace. Nil if TAPs are used instead.
+	Veth1 *linux_intf.LinuxInterfaces_Interface
+	// Veth2 is the end of veth pair in the default namespace. Nil if TAPs are used instead.
+	Veth2 *linux_intf.LinuxInterfaces_Interface
+	// VppIf is AF_PACKET/TAP interface connecting the POD to VPP
+	VppIf *vpp_intf.Interfaces_Interface
+	// PodTap is the host end of the tap connecting the POD to VPP. Nil if TAPs are not used.
+	PodTap *linux_intf.LinuxInterfaces_Interface
+	// VppLoop is the loopback holding the gateway IP of the network
+	VppLoop *vpp_intf.Interfaces_Interface
+	// VppARPEntry is ARP entry configured in VPP to route traffic from VPP to the POD
+	VppARPEntry *vpp_l3.ArpTable_ArpTableEntry
+	// VppRoute is the route from VPP to the POD (in the VRF of the network)
+	VppRoute *vpp_l3.StaticRoutes_Route
+	// PodARPEntry is ARP entry for the network gateway configured in the POD
+	PodARPEntry *linux_l3.LinuxStaticArpEntries_ArpEntry
+	// PodLinkRoute is the route from POD to the network gateway
+	PodLinkRoute *linux_l3.LinuxStaticRoutes_Route
+	// PodNetworkRoute is the route from POD to the subnet of the network via the gateway
+	PodNetworkRoute *linux_l3.LinuxStaticRoutes_Route
+}
+
+// secondaryIfToProto transforms configuration of a secondary interface to the structure that will be persisted.
+func secondaryIfToProto(cfg *SecondaryIfConfig) *container.SecondaryInterface {
+	persisted := &container.SecondaryInterface{
+		Network:             cfg.Network,
+		IfName:              cfg.IfName,
+		IP:                  cfg.IP.String(),
+		VppIfName:           cfg.VppIf.Name,
+		VppRouteVrf:         cfg.VppRoute.VrfId,
+		VppRouteDest:        cfg.VppRoute.DstIpAddr,
+		PodARPEntryName:     cfg.PodARPEntry.Name,
+		PodLinkRouteName:    cfg.PodLinkRoute.Name,
+		PodNetworkRouteName: cfg.PodNetworkRoute.Name,
+	}
+	if cfg.Veth1 != nil {
+		persisted.Veth1Name = cfg.Veth1.Name
+	}
+	if cfg.Veth2 != nil {
+		persisted.Veth2Name = cfg.Veth2.Name
+	}
+	if cfg.PodTap != nil {
+		persisted.PodTapName = cfg.PodTap.Name
+	}
+	return persisted
+}
+
+// parsePodNetworks parses the list of secondary networks requested for the POD in the CNI extra arguments.
+func (s *remoteCNIserver) parsePodNetworks(extraArgs map[string]string) ([]string, error) {
+	var networks []string
+	requested := map[string]bool{}
+	for _, network := range strings.Split(extraArgs[podNetworksExtraArg], ",") {
+		network = strings.TrimSpace(network)
+		if network == "" {
+			continue
+		}
+		if _, configured := s.secondaryNetworks[network]; !configured {
+			return nil, fmt.Errorf("secondary network %q is not configured", network)
+		}
+		if requested[network] {
+			return nil, fmt.Errorf("secondary network %q is requested more than once", network)
+		}
+		requested[network] = true
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// secondaryIfRequest returns a copy of the CNI request used to derive the names of the POD interface
+// attached to the idx-th secondary network (numbered from 1).
+func (s *remoteCNIserver) secondaryIfRequest(request *cni.CNIRequest, idx int) *cni.CNIRequest {
+	suffix := "-" + strconv.Itoa(idx)
+	id := request.ContainerId
+	if len(id)+len(suffix) > linuxIfMaxLen {
+		id = id[:linuxIfMaxLen-len(suffix)]
+	}
+	secondary := *request
+	secondary.ContainerId = id + suffix
+	secondary.InterfaceName = secondaryIfNamePrefix + strconv.Itoa(idx)
+	return &secondary
+}
+
+// secondaryNetworkLoop returns the loopback holding the gateway IP of the given secondary network,
+// the VPP ends of the POD interfaces in the network are unnumbered with this loopback.
+func (s *remoteCNIserver) secondaryNetworkLoop(network string) (*vpp_intf.Interfaces_Interface, error) {
+	podNetwork, err := s.ipam.SecondaryPodNetwork(network)
+	if err != nil {
+		return nil, err
+	}
+	gw, err := s.ipam.SecondaryPodGatewayIP(network)
+	if err != nil {
+		return nil, err
+	}
+	prefixLen, _ := podNetwork.Mask.Size()
+	return &vpp_intf.Interfaces_Interface{
+		Name:        secondaryNetworkLoopPrefix + network,
+		Type:        vpp_intf.InterfaceType_SOFTWARE_LOOPBACK,
+		Enabled:     true,
+		Vrf:         s.secondaryNetworks[network].VrfID,
+		IpAddresses: []string{fmt.Sprintf("%s/%d", gw.String(), prefixLen)},
+	}, nil
+}
+
+// podNetworkRouteFromRequest returns route to the given subnet via the gateway configured inside the POD.
+func (s *remoteCNIserver) podNetworkRouteFromRequest(request *cni.CNIRequest, ifName string, subnet *net.IPNet, gw net.IP) *linux_l3.LinuxStaticRoutes_Route {
+	return &linux_l3.LinuxStaticRoutes_Route{
+		Name: "NET-" + request.ContainerId,
+		Namespace: &linux_l3.LinuxStaticRoutes_Route_Namespace{
+			Type:     linux_l3.LinuxStaticRoutes_Route_Namespace_FILE_REF_NS,
+			Filepath: request.NetworkNamespace,
+		},
+		Interface: ifName,
+		Scope: &linux_l3.LinuxStaticRoutes_Route_Scope{
+			Type: linux_l3.LinuxStaticRoutes_Route_Scope_GLOBAL,
+		},
+		DstIpAddr: subnet.String(),
+		GwAddr:    gw.String(),
+	}
+}
+
+// configureSecondaryInterfaces attaches the POD to the given secondary networks, one interface per network.
+// The configured interfaces are stored in <config>.
+func (s *remoteCNIserver) configureSecondaryInterfaces(request *cni.CNIRequest, networks []string, config *PodConfig, revertTxn linux.DeleteDSL) error {
+	for i, network := range networks {
+		secondaryIf, err := s.configureSecondaryInterface(request, i+1, network, config, revertTxn)
+		if secondaryIf != nil {
+			config.SecondaryIfs = append(config.SecondaryIfs, secondaryIf)
+		}
+		if err != nil {
+			return fmt.Errorf("Can't attach pod to the secondary network %v: %v", network, err)
+		}
+	}
+	return nil
+}
+
+// configureSecondaryInterface configures one POD interface attached to a secondary network.
+// The returned config is not nil once the IP address of the POD is allocated, even if an error occurs later.
+func (s *remoteCNIserver) configureSecondaryInterface(request *cni.CNIRequest, idx int, network string, podConfig *PodConfig, revertTxn linux.DeleteDSL) (*SecondaryIfConfig, error) {
+	subnet, err := s.ipam.SecondaryPodSubnet(network)
+	if err != nil {
+		return nil, err
+	}
+	gw, err := s.ipam.SecondaryPodGatewayIP(network)
+	if err != nil {
+		return nil, err
+	}
+	ip, err := s.ipam.NextSecondaryPodIP(network, podConfig.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	secondaryReq := s.secondaryIfRequest(request, idx)
+	config := &SecondaryIfConfig{
+		Network: network,
+		IfName:  secondaryReq.InterfaceName,
+		IP:      ip,
+	}
+	podIPs := []string{ipWithFullPrefix(ip)}
+
+	// the loopback with the network gateway IP must exist before the unnumbered interfaces
+	config.VppLoop, err = s.secondaryNetworkLoop(network)
+	if err != nil {
+		return config, err
+	}
+	err = s.podTxns.commit(func(txn linux.DataChangeDSL) {
+		txn.Put().VppInterface(config.VppLoop)
+	})
+	if err != nil {
+		return config, err
+	}
+
+	// create VPP to POD interconnect interface
+	podIfName := ""
+	if s.useTAPInterfaces {
+		config.VppIf = s.tapFromRequest(secondaryReq, nil, false, "")
+		config.PodTap = s.podTAP(secondaryReq, podIPs)
+		podIfName = config.PodTap.Name
+	} else {
+		config.VppIf = s.afpacketFromRequest(secondaryReq, nil, false, "")
+		config.Veth1 = s.veth1FromRequest(secondaryReq, podIPs)
+		config.Veth2 = s.veth2FromRequest(secondaryReq)
+		podIfName = config.Veth1.Name
+	}
+	config.VppIf.Vrf = s.secondaryNetworks[network].VrfID
+	config.VppIf.Unnumbered = &vpp_intf.Interfaces_Interface_Unnumbered{
+		IsUnnumbered:    true,
+		InterfaceWithIP: config.VppLoop.Name,
+	}
+
+	if s.useTAPInterfaces {
+		// configure vpp TAP interface in a separate transaction, see configurePodInterface
+		err = s.podTxns.commit(func(txn linux.DataChangeDSL) {
+			txn.Put().VppInterface(config.VppIf)
+		})
+		if err != nil {
+			return config, err
+		}
+	}
+	revertTxn.VppInterface(config.VppIf.Name)
+
+	// link scope route + ARP entry for the network gateway
+	config.PodLinkRoute = s.podLinkRouteFromRequest(secondaryReq, podIfName)
+	config.PodLinkRoute.DstIpAddr = ipWithFullPrefix(gw)
+	config.PodARPEntry = s.podArpEntry(secondaryReq, podIfName, config.VppIf.PhysAddress)
+	config.PodARPEntry.IpAddr = gw.String()
+	err = s.podTxns.commit(func(txn linux.DataChangeDSL) {
+		txn1 := txn.Put()
+		if s.useTAPInterfaces {
+			txn1.LinuxInterface(config.PodTap)
+		} else {
+			txn1.LinuxInterface(config.Veth1).
+				LinuxInterface(config.Veth2).
+				VppInterface(config.VppIf)
+		}
+		txn1.LinuxRoute(config.PodLinkRoute).
+			LinuxArpEntry(config.PodARPEntry)
+	})
+	if err != nil {
+		return config, err
+	}
+
+	// the route to the network subnet depends on the link-local route from the transaction 1
+	config.PodNetworkRoute = s.podNetworkRouteFromRequest(secondaryReq, podIfName, subnet, gw)
+	config.VppRoute = s.vppRouteFromRequest(secondaryReq, ipWithFullPrefix(ip))
+	config.VppRoute.VrfId = s.secondaryNetworks[network].VrfID
+	config.VppARPEntry = s.vppArpEntry(config.VppIf.Name, ip, s.hwAddrForContainer())
+	revertTxn.StaticRoute(config.VppRoute.VrfId, config.VppRoute.DstIpAddr, config.VppRoute.NextHopAddr).
+		Arp(config.VppARPEntry.Interface, config.VppARPEntry.IpAddress)
+
+	err = s.podTxns.commit(func(txn linux.DataChangeDSL) {
+		txn.Put().LinuxRoute(config.PodNetworkRoute).
+			StaticRoute(config.VppRoute).
+			Arp(config.VppARPEntry)
+	})
+	if err != nil {
+		return config, err
+	}
+	return config, nil
+}
+
+// unconfigureSecondaryInterfaces removes all POD interfaces attached to the secondary networks.
+func (s *remoteCNIserver) unconfigureSecondaryInterfaces(config *container.Persisted) error {
+	for _, secondaryIf := range config.SecondaryInterfaces {
+		// routes and ARPs must be removed before the interfaces, see unconfigurePodInterface
+		err := s.podTxns.commit(func(txn linux.DataChangeDSL) {
+			txn1 := txn.Delete().
+				StaticRoute(secondaryIf.VppRouteVrf, secondaryIf.VppRouteDest, "").
+				Arp(secondaryIf.VppIfName, secondaryIf.IP)
+			if !s.test {
+				txn1.LinuxRoute(secondaryIf.PodNetworkRouteName).
+					LinuxRoute(secondaryIf.PodLinkRouteName).
+					LinuxArpEntry(secondaryIf.PodARPEntryName)
+			}
+		})
+		if err != nil {
+			return err
+		}
+
+		err = s.podTxns.commit(func(txn linux.DataChangeDSL) {
+			txn2 := txn.Delete().VppInterface(secondaryIf.VppIfName)
+			if s.useTAPInterfaces {
+				txn2.LinuxInterface(secondaryIf.PodTapName)
+			} else {
+				txn2.LinuxInterface(secondaryIf.Veth1Name).
+					LinuxInterface(secondaryIf.Veth2Name)
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseSecondaryIPs releases IP addresses allocated for the POD in the given secondary networks.
+func (s *remoteCNIserver) releaseSecondaryIPs(podID string, networks []string) {
+	for _, network := range networks {
+		err := s.ipam.ReleaseSecondaryPodIP(network, podID)
+		if err != nil {
+			s.Logger.Warn(err)
+		}
+	}
+}
+
+// secondaryIfsChanges returns the configuration of the secondary interfaces to be persisted.
+func (s *remoteCNIserver) secondaryIfsChanges(config *PodConfig, changes map[string]proto.Message) {
+	for _, secondaryIf := range config.SecondaryIfs {
+		changes[vpp_intf.InterfaceKey(secondaryIf.VppLoop.Name)] = secondaryIf.VppLoop
+		changes[vpp_intf.InterfaceKey(secondaryIf.VppIf.Name)] = secondaryIf.VppIf
+		if secondaryIf.PodTap != nil {
+			changes[linux_intf.InterfaceKey(secondaryIf.PodTap.Name)] = secondaryIf.PodTap
+		} else {
+			changes[linux_intf.InterfaceKey(secondaryIf.Veth1.Name)] = secondaryIf.Veth1
+			changes[linux_intf.InterfaceKey(secondaryIf.Veth2.Name)] = secondaryIf.Veth2
+		}
+		changes[linux_l3.StaticRouteKey(secondaryIf.PodLinkRoute.Name)] = secondaryIf.PodLinkRoute
+		changes[linux_l3.StaticRouteKey(secondaryIf.PodNetworkRoute.Name)] = secondaryIf.PodNetworkRoute
+		changes[linux_l3.StaticArpKey(secondaryIf.PodARPEntry.Name)] = secondaryIf.PodARPEntry
+		changes[vpp_l3.RouteKey(secondaryIf.VppRoute.VrfId, secondaryIf.VppRoute.DstIpAddr, secondaryIf.VppRoute.NextHopAddr)] = secondaryIf.VppRoute
+		changes[vpp_l3.ArpEntryKey(secondaryIf.VppARPEntry.Interface, secondaryIf.VppARPEntry.IpAddress)] = secondaryIf.VppARPEntry
+	}
+}
+
+// secondaryIfsRemovedKeys returns the keys of the persisted configuration of the secondary interfaces.
+// The loopbacks of the secondary networks are shared by all PODs of the network and they are kept.
+func (s *remoteCNIserver) secondaryIfsRemovedKeys(config *container.Persisted) []string {
+	var removedKeys []string
+	for _, secondaryIf := range config.SecondaryInterfaces {
+		removedKeys = append(removedKeys, vpp_intf.InterfaceKey(secondaryIf.VppIfName))
+		if secondaryIf.PodTapName != "" {
+			removedKeys = append(removedKeys, linux_intf.InterfaceKey(secondaryIf.PodTapName))
+		} else {
+			removedKeys = append(removedKeys,
+				linux_intf.InterfaceKey(secondaryIf.Veth1Name),
+				linux_intf.InterfaceKey(secondaryIf.Veth2Name))
+		}
+		removedKeys = append(removedKeys,
+			linux_l3.StaticRouteKey(secondaryIf.PodLinkRouteName),
+			linux_l3.StaticRouteKey(secondaryIf.PodNetworkRouteName),
+			linux_l3.StaticArpKey(secondaryIf.PodARPEntryName),
+			vpp_l3.RouteKey(secondaryIf.VppRouteVrf, secondaryIf.VppRouteDest, ""),
+			vpp_l3.ArpEntryKey(secondaryIf.VppIfName, secondaryIf.IP))
+	}
+	return removedKeys
+}
+
+// secondaryIfsReply returns CNI reply interfaces and routes of the POD interfaces in the secondary networks.
+func (s *remoteCNIserver) secondaryIfsReply(config *PodConfig, nsName string) (interfaces []*cni.CNIReply_Interface, routes []*cni.CNIReply_Route) {
+	for _, secondaryIf := range config.SecondaryIfs {
+		interfaces = append(interfaces, &cni.CNIReply_Interface{
+			Name:    secondaryIf.VppIf.Name,
+			Sandbox: nsName,
+			IpAddresses: []*cni.CNIReply_Interface_IP{
+				{
+					Version: cni.CNIReply_Interface_IP_IPV4,
+					Address: ipWithFullPrefix(secondaryIf.IP),
+					Gateway: secondaryIf.PodNetworkRoute.GwAddr,
+				},
+			},
+		})
+		routes = append(routes, &cni.CNIReply_Route{
+			Dst: secondaryIf.PodNetworkRoute.DstIpAddr,
+			Gw:  secondaryIf.PodNetworkRoute.GwAddr,
+		})
+	}
+	return interfaces, routes
+}
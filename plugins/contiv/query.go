@@ -0,0 +1,200 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contiv
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/unrolled/render"
+	"golang.org/x/net/context"
+
+	"github.com/contiv/vpp/plugins/contiv/model/query"
+)
+
+// URL paths of the REST API querying the runtime state of the plugin.
+// The responses are the JSON forms of the replies of the ContivQuery gRPC service.
+const (
+	// NodeInfoPath returns the identity and the addresses of the node.
+	NodeInfoPath = "/contiv/v1/node"
+
+	// IPAMPath returns the IPAM pools of the node and the IP addresses allocated to PODs.
+	IPAMPath = "/contiv/v1/ipam"
+
+	// PodsPath returns the PODs connected by the node.
+	PodsPath = "/contiv/v1/pods"
+
+	// RemoteNodesPath returns the other nodes the node has routes to.
+	RemoteNodesPath = "/contiv/v1/remote-nodes"
+)
+
+// GetNodeInfo returns the identity and the addresses of the node.
+func (s *remoteCNIserver) GetNodeInfo(ctx context.Context, request *query.QueryRequest) (*query.NodeInfo, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	nodeInfo := &query.NodeInfo{
+		Id:                        s.nodeID,
+		Name:                      s.agentLabel,
+		IpAddress:                 s.nodeIP,
+		MainVppInterface:          s.mainPhysicalIf,
+		OtherVppInterfaces:        append([]string(nil), s.otherPhysicalIfs...),
+		HostInterconnectInterface: s.hostInterconnectIfName,
+	}
+	if s.defaultGw != nil {
+		nodeInfo.DefaultGateway = s.defaultGw.String()
+	}
+	if s.overlay != nil {
+		nodeInfo.VxlanBviInterface = s.overlay.bviIfName()
+	}
+	return nodeInfo, nil
+}
+
+// GetIPAM returns the IPAM pools of the node and the IP addresses allocated to PODs.
+func (s *remoteCNIserver) GetIPAM(ctx context.Context, request *query.QueryRequest) (*query.IPAMInfo, error) {
+	used, size := s.ipam.PodIPPoolUsage()
+	ipamInfo := &query.IPAMInfo{
+		PodSubnet:      s.ipam.PodSubnet().String(),
+		PodGateway:     s.ipam.PodGatewayIP().String(),
+		VppHostNetwork: s.ipam.VPPHostNetwork().String(),
+		PodIpPoolUsed:  uint32(used),
+		PodIpPoolSize:  uint32(size),
+	}
+	for _, podNetwork := range s.ipam.PodNetworks() {
+		ipamInfo.PodNetworks = append(ipamInfo.PodNetworks, podNetwork.String())
+	}
+	for podID, ip := range s.ipam.AssignedPodIPs() {
+		ipamInfo.AllocatedIps = append(ipamInfo.AllocatedIps, &query.IPAMInfo_AllocatedIP{
+			PodId:     podID,
+			IpAddress: ip.String(),
+		})
+	}
+	sort.Slice(ipamInfo.AllocatedIps, func(i, j int) bool {
+		return ipamInfo.AllocatedIps[i].PodId < ipamInfo.AllocatedIps[j].PodId
+	})
+	return ipamInfo, nil
+}
+
+// GetPods returns the PODs connected by the node and their interfaces.
+func (s *remoteCNIserver) GetPods(ctx context.Context, request *query.QueryRequest) (*query.PodList, error) {
+	podList := &query.PodList{}
+	containerIDs := s.configuredContainers.ListAll()
+	sort.Strings(containerIDs)
+	for _, containerID := range containerIDs {
+		config, found := s.configuredContainers.LookupContainer(containerID)
+		if !found {
+			// removed in the meantime
+			continue
+		}
+		pod := &query.Pod{
+			ContainerId:  containerID,
+			Namespace:    config.PodNamespace,
+			Name:         config.PodName,
+			IpAddress:    config.VppARPEntryIP,
+			Ipv6Address:  config.VppARPEntryIPv6,
+			VppInterface: config.VppIfName,
+			PodInterface: config.Veth1Name,
+			MemifSocket:  config.MemifSocket,
+			Tenant:       config.Tenant,
+		}
+		if pod.PodInterface == "" {
+			pod.PodInterface = config.PodTapName
+		}
+		for _, secondaryIf := range config.SecondaryInterfaces {
+			pod.SecondaryInterfaces = append(pod.SecondaryInterfaces, &query.Pod_SecondaryInterface{
+				Network:      secondaryIf.Network,
+				IfName:       secondaryIf.IfName,
+				IpAddress:    secondaryIf.IP,
+				VppInterface: secondaryIf.VppIfName,
+			})
+		}
+		podList.Pods = append(podList.Pods, pod)
+	}
+	return podList, nil
+}
+
+// GetRemoteNodes returns the other nodes the node has routes to.
+func (s *remoteCNIserver) GetRemoteNodes(ctx context.Context, request *query.QueryRequest) (*query.RemoteNodeList, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	nodeList := &query.RemoteNodeList{}
+	for _, nodeInfo := range s.otherNodes {
+		remoteNode := &query.RemoteNode{
+			Id:                  nodeInfo.Id,
+			Name:                nodeInfo.Name,
+			IpAddress:           nodeInfo.IpAddress,
+			ManagementIpAddress: nodeInfo.ManagementIpAddress,
+		}
+		nextHop, err := s.otherNodeNextHop(nodeInfo)
+		if err != nil {
+			return nil, err
+		}
+		remoteNode.NextHop = nextHop
+		podNetworks, err := s.ipam.OtherNodePodNetworks(nodeInfo.Id)
+		if err != nil {
+			return nil, err
+		}
+		for _, podNetwork := range podNetworks {
+			remoteNode.PodNetworks = append(remoteNode.PodNetworks, podNetwork.String())
+		}
+		hostNetwork, err := s.ipam.OtherNodeVPPHostNetwork(nodeInfo.Id)
+		if err != nil {
+			return nil, err
+		}
+		remoteNode.VppHostNetwork = hostNetwork.String()
+		nodeList.Nodes = append(nodeList.Nodes, remoteNode)
+	}
+	sort.Slice(nodeList.Nodes, func(i, j int) bool {
+		return nodeList.Nodes[i].Id < nodeList.Nodes[j].Id
+	})
+	return nodeList, nil
+}
+
+// queryHandlers returns the HTTP handlers of the REST API querying the runtime state of the plugin,
+// keyed by the URL path.
+func (s *remoteCNIserver) queryHandlers() map[string]func(formatter *render.Render) http.HandlerFunc {
+	request := &query.QueryRequest{}
+	return map[string]func(formatter *render.Render) http.HandlerFunc{
+		NodeInfoPath: queryHandler(func(ctx context.Context) (interface{}, error) {
+			return s.GetNodeInfo(ctx, request)
+		}),
+		IPAMPath: queryHandler(func(ctx context.Context) (interface{}, error) {
+			return s.GetIPAM(ctx, request)
+		}),
+		PodsPath: queryHandler(func(ctx context.Context) (interface{}, error) {
+			return s.GetPods(ctx, request)
+		}),
+		RemoteNodesPath: queryHandler(func(ctx context.Context) (interface{}, error) {
+			return s.GetRemoteNodes(ctx, request)
+		}),
+	}
+}
+
+// queryHandler returns the HTTP handler replying with the result of the given query in JSON.
+func queryHandler(query func(ctx context.Context) (interface{}, error)) func(formatter *render.Render) http.HandlerFunc {
+	return func(formatter *render.Render) http.HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) {
+			reply, err := query(req.Context())
+			if err != nil {
+				formatter.JSON(w, http.StatusInternalServerError, struct {
+					Error string `json:"error"`
+				}{err.Error()})
+				return
+			}
+			formatter.JSON(w, http.StatusOK, reply)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contiv
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/onsi/gomega"
+	"github.com/unrolled/render"
+	"golang.org/x/net/context"
+
+	"github.com/contiv/vpp/plugins/contiv/model/query"
+)
+
+func TestQuery(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	server, _, _, conn := setupTestCNIServer(&configTapVxlanTCP, nil)
+	defer conn.Disconnect()
+
+	// exec resync to configure vswitch
+	err := server.resync()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	err = server.nodeChangePropageteEvent(&nodeAddDelEvent{evType: datasync.Put})
+	gomega.Expect(err).To(gomega.BeNil())
+
+	reply, err := server.Add(context.Background(), &req)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(reply.Result).To(gomega.BeEquivalentTo(0))
+	podIP := server.ipam.PodIP(containerID)
+	gomega.Expect(podIP).NotTo(gomega.BeNil())
+
+	// node
+	nodeInfo, err := server.GetNodeInfo(context.Background(), &query.QueryRequest{})
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(nodeInfo.Id).To(gomega.BeEquivalentTo(server.nodeID))
+	gomega.Expect(nodeInfo.MainVppInterface).To(gomega.Equal(server.GetMainPhysicalIfName()))
+	gomega.Expect(nodeInfo.VxlanBviInterface).NotTo(gomega.BeEmpty())
+
+	// IPAM
+	ipamInfo, err := server.GetIPAM(context.Background(), &query.QueryRequest{})
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(ipamInfo.PodNetworks).To(gomega.ConsistOf(server.ipam.PodNetwork().String()))
+	gomega.Expect(ipamInfo.PodIpPoolUsed).To(gomega.BeEquivalentTo(1))
+	gomega.Expect(ipamInfo.AllocatedIps).To(gomega.ConsistOf(&query.IPAMInfo_AllocatedIP{
+		PodId:     containerID,
+		IpAddress: podIP.String(),
+	}))
+
+	// PODs
+	pods, err := server.GetPods(context.Background(), &query.QueryRequest{})
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(pods.Pods).To(gomega.HaveLen(1))
+	gomega.Expect(pods.Pods[0].ContainerId).To(gomega.Equal(containerID))
+	gomega.Expect(pods.Pods[0].Name).To(gomega.Equal(podName))
+	gomega.Expect(pods.Pods[0].Namespace).To(gomega.Equal(podNamespace))
+	gomega.Expect(pods.Pods[0].IpAddress).To(gomega.Equal(podIP.String()))
+	gomega.Expect(pods.Pods[0].VppInterface).NotTo(gomega.BeEmpty())
+	gomega.Expect(pods.Pods[0].PodInterface).NotTo(gomega.BeEmpty())
+
+	// remote nodes
+	nodes, err := server.GetRemoteNodes(context.Background(), &query.QueryRequest{})
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(nodes.Nodes).To(gomega.HaveLen(1))
+	gomega.Expect(nodes.Nodes[0].Id).To(gomega.Equal(otherNodeInfo.Id))
+	gomega.Expect(nodes.Nodes[0].Name).To(gomega.Equal(otherNodeInfo.Name))
+	nextHop, _ := server.ipam.VxlanIPAddress(otherNodeInfo.Id)
+	gomega.Expect(nodes.Nodes[0].NextHop).To(gomega.Equal(nextHop.String()))
+	otherPodNetwork, _ := server.ipam.OtherNodePodNetwork(otherNodeInfo.Id)
+	gomega.Expect(nodes.Nodes[0].PodNetworks).To(gomega.ConsistOf(otherPodNetwork.String()))
+
+	// REST API returns the same data in JSON
+	handlers := server.queryHandlers()
+	gomega.Expect(handlers).To(gomega.HaveKey(PodsPath))
+	recorder := httptest.NewRecorder()
+	handlers[PodsPath](render.New())(recorder, httptest.NewRequest(http.MethodGet, PodsPath, nil))
+	gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusOK))
+	restPods := &query.PodList{}
+	err = json.Unmarshal(recorder.Body.Bytes(), restPods)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(restPods).To(gomega.Equal(pods))
+}
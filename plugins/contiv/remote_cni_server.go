@@ -17,8 +17,11 @@ package contiv
 import (
 	"fmt"
 	"net"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"git.fd.io/govpp.git/api"
 	"github.com/apparentlymart/go-cidr/cidr"
@@ -27,12 +30,16 @@ import (
 	"github.com/contiv/vpp/plugins/contiv/containeridx/model"
 	"github.com/contiv/vpp/plugins/contiv/ipam"
 	"github.com/contiv/vpp/plugins/contiv/model/cni"
+	"github.com/contiv/vpp/plugins/contiv/model/egress"
+	"github.com/contiv/vpp/plugins/contiv/model/node"
+	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
 	"github.com/contiv/vpp/plugins/kvdbproxy"
 	"github.com/gogo/protobuf/proto"
 	"github.com/ligato/cn-infra/datasync"
 	"github.com/ligato/cn-infra/db/keyval"
 	"github.com/ligato/cn-infra/logging"
 	"github.com/ligato/vpp-agent/clientv1/linux"
+	vpp_bfd "github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/bfd"
 	vpp_intf "github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/interfaces"
 	vpp_l2 "github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/l2"
 	vpp_l3 "github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/l3"
@@ -53,10 +60,15 @@ const (
 	tapNamePrefix                 = "tap"
 	podNameExtraArg               = "K8S_POD_NAME"
 	podNamespaceExtraArg          = "K8S_POD_NAMESPACE"
+	podIPExtraArg                 = "IP"
+	podIPReservationExtraArg      = "IP_RESERVATION"
+	podStickyIPExtraArg           = "STICKY_IP"
 	vethHostEndLogicalName        = "veth-vpp1"
 	vethHostEndName               = "vpp1"
 	vethVPPEndLogicalName         = "veth-vpp2"
 	vethVPPEndName                = "vpp2"
+	ipv4DefaultRoute              = "0.0.0.0/0"
+	ipv6DefaultRoute              = "::/0"
 
 	// TapHostEndLogicalName is the logical name of the VPP-host interconnect TAP interface (host end)
 	TapHostEndLogicalName = "tap-vpp1"
@@ -72,6 +84,11 @@ const (
 
 // remoteCNIserver represents the remote CNI server instance. It accepts the requests from the contiv-CNI
 // (acting as a GRPC-client) and configures the networking between VPP and the PODs.
+//
+// The lock of the server is held only while the state of the server is changed, including
+// the allocation and the release of the POD IP addresses and the updates of the map of the configured
+// containers. The interfaces of the PODs are wired into VPP without the lock, serialized only per container,
+// the requests of independent PODs are therefore processed concurrently.
 type remoteCNIserver struct {
 	logging.Logger
 	sync.Mutex
@@ -79,6 +96,24 @@ type remoteCNIserver struct {
 	// VPP local client transaction factory
 	vppTxnFactory func() linux.DataChangeDSL
 
+	// podTxns merges the transactions of the PODs wired concurrently
+	podTxns *txnBatcher
+
+	// metrics of the CNI requests and the log of the recent requests
+	metrics *cniMetrics
+
+	// podsInProgress is the set of containers with a CNI request being processed,
+	// the requests for the same container are serialized using podRequestCond
+	podsInProgress map[string]struct{}
+	podRequestCond *sync.Cond
+
+	// podsWiring is the number of PODs being wired (or unwired) into VPP without the lock, the base vswitch
+	// configuration is re-applied by resync only when no POD is being wired (signalled by podWiringCond),
+	// vswitchResyncing blocks the wiring of new PODs in the meantime
+	podsWiring       int
+	podWiringCond    *sync.Cond
+	vswitchResyncing bool
+
 	// kvdbsync plugin with ability to filter the change events
 	proxy kvdbproxy.Proxy
 
@@ -104,7 +139,7 @@ type remoteCNIserver struct {
 	agentLabel string
 
 	// unique identifier of the node
-	nodeID uint8
+	nodeID uint32
 
 	// this node's main IP address
 	nodeIP string
@@ -112,6 +147,23 @@ type remoteCNIserver struct {
 	// nodeIPsubsribers is a slice of channels that are notified when nodeIP is changed
 	nodeIPsubscribers []chan string
 
+	// nodeConfigSubscribers are notified when the configuration of this node is changed at runtime
+	nodeConfigSubscribers []chan struct{}
+
+	// nodeIDReleaseSubscribers are notified when the ID of this node is released while the agent is running
+	nodeIDReleaseSubscribers []chan struct{}
+
+	// overlayTunnelSubscribers are notified when the tunnels of the overlay terminating the traffic
+	// change (see GetOverlayTunnelIfNames)
+	overlayTunnelSubscribers []chan struct{}
+
+	// ipsec protects the VXLAN traffic between the nodes, nil if IPsec is not enabled
+	ipsec *ipsecOverlay
+
+	// ipsecKeyGenerationSubscribers are notified when the newest IPsec key generation accepted by this node changes
+	ipsecKeyGenerationSubscribers []chan uint32
+	announcedIPSecKeyGeneration   uint32
+
 	// global config
 	config *Config
 
@@ -143,11 +195,11 @@ type remoteCNIserver struct {
 	tapV2RxRingSize uint16
 	tapV2TxRingSize uint16
 
-	// use pure L2 node interconnect instead of VXLANs
-	useL2Interconnect bool
+	// interconnect with the other nodes
+	overlay nodeOverlay
 
-	// bridge domain used for VXLAN tunnels
-	vxlanBD *vpp_l2.BridgeDomains_BridgeDomain
+	// cli executes VPP CLI commands for the features missing in the VPP binary API
+	cli cliExecutor
 
 	// name of the main physical interface
 	mainPhysicalIf string
@@ -158,9 +210,6 @@ type remoteCNIserver struct {
 	// name of the interface interconnecting VPP with the host stack
 	hostInterconnectIfName string
 
-	// the name of an BVI interface facing towards VXLAN tunnels to other hosts
-	vxlanBVIIfName string
-
 	stnIP string
 	stnGw string
 
@@ -183,6 +232,81 @@ type remoteCNIserver struct {
 
 	// nodeIDChangeEvs is buffer where change events are stored until resync event is processed
 	nodeIDChangeEvs []datasync.ChangeEvent
+
+	// otherNodes maps IDs of the other nodes to their info, for the nodes with routes already configured
+	otherNodes map[uint32]*node.NodeInfo
+
+	// otherNodePodRoutes maps IDs of the other nodes to the installed routes towards their pod networks
+	// (the routes are removed as installed, even if the pod CIDR blocks have changed in the meantime)
+	otherNodePodRoutes map[uint32][]*vpp_l3.StaticRoutes_Route
+
+	// secondaryNetworks maps names of the secondary networks to their configuration
+	secondaryNetworks map[string]SecondaryNetworkConfig
+
+	// podBandwidth maps PODs to the bandwidth limits requested by their annotations (only PODs with some limit)
+	podBandwidth map[podmodel.ID]podBandwidth
+
+	// podArgs maps PODs to the CNI extra arguments requested by their annotations (only PODs with some such annotation)
+	podArgs map[podmodel.ID]map[string]string
+
+	// bandwidthLimiter applies the bandwidth limits of PODs in VPP
+	bandwidthLimiter bandwidthLimiter
+
+	// livePods is the set of PODs reflected into ETCD by KSR, nil until the PODs are resynced
+	livePods map[podmodel.ID]struct{}
+
+	// CNI Add requests wait for the PODs to be reflected by KSR (signalled by podReflectedCond)
+	// for podReflectionTimeout at most, 0 disables the waiting
+	podReflectedCond     *sync.Cond
+	podReflectionTimeout time.Duration
+
+	// stickyIPReleases maps the deleted PODs to the pending releases of their sticky IP addresses,
+	// the addresses are released after stickyPodIPHoldTime
+	stickyIPReleases    map[podmodel.ID]*time.Timer
+	stickyPodIPHoldTime time.Duration
+
+	// staleContainers are the containers connected in this run found stale by the last cleanup of stale PODs
+	staleContainers map[string]struct{}
+
+	// tenants maps names of the tenants to their networks
+	tenants map[string]*tenantNetwork
+
+	// tenantLabel is the namespace label with the name of the tenant of the namespace
+	tenantLabel string
+
+	// namespaceTenants maps the namespaces reflected by KSR to their tenants (empty for the pod network),
+	// nil until the namespaces are resynced
+	namespaceTenants map[string]string
+
+	// egress steers the traffic of the PODs selected by the egress policies, nil if the egress IP pool is not configured
+	egress *vppEgress
+
+	// egressPool is the pool of the egress IPs
+	egressPool *net.IPNet
+
+	// egressStore persists the egress IPs assigned to the egress policies
+	egressStore egressIPStore
+
+	// egressPolicies maps namespace/name of the egress policies to their definition
+	egressPolicies map[string]*egress.EgressPolicy
+
+	// egressGateways is the set of the live gateway nodes
+	egressGateways map[string]struct{}
+
+	// egressPods are the PODs reflected into ETCD by KSR, nil until the PODs are resynced
+	egressPods map[podmodel.ID]*podmodel.Pod
+
+	// egressIPs maps the egress policies this node is the gateway for to the assigned egress IPs
+	egressIPs map[string]string
+
+	// uplinks of the node balancing the traffic leaving the node, empty if not configured
+	uplinks []*uplink
+
+	// bfd reads the states of the BFD sessions of the uplinks, nil if BFD is not enabled
+	bfd bfdMonitor
+
+	// podIPv6Broker stores the IPv6 addresses of the PODs under the KSR prefix, nil if dual-stack is not enabled
+	podIPv6Broker keyval.ProtoBroker
 }
 
 // vswitchConfig holds base vSwitch VPP configuration.
@@ -202,22 +326,28 @@ type vswitchConfig struct {
 
 	routesToHost     []*vpp_l3.StaticRoutes_Route
 	routeFromHost    *linux_l3.LinuxStaticRoutes_Route
+	routeFromHostV6  *linux_l3.LinuxStaticRoutes_Route
 	routeForServices *linux_l3.LinuxStaticRoutes_Route
 	l4Features       *vpp_l4.L4Features
 
-	vxlanBVI *vpp_intf.Interfaces_Interface
-	vxlanBD  *vpp_l2.BridgeDomains_BridgeDomain
+	overlayIfs []*vpp_intf.Interfaces_Interface
+	overlayBDs []*vpp_l2.BridgeDomains_BridgeDomain
+
+	uplinkRoutes []*vpp_l3.StaticRoutes_Route
+	bfdSessions  []*vpp_bfd.SingleHopBFD_Session
 }
 
 // newRemoteCNIServer initializes a new remote CNI server instance.
 func newRemoteCNIServer(logger logging.Logger, vppTxnFactory func() linux.DataChangeDSL, proxy kvdbproxy.Proxy,
 	configuredContainers *containeridx.ConfigIndex, govppChan *api.Channel, index ifaceidx.SwIfIndex, dhcpIndex ifaceidx.DhcpIndex, agentLabel string,
-	config *Config, nodeConfig *OneNodeConfig, nodeID uint8, broker keyval.ProtoBroker) (*remoteCNIserver, error) {
-	ipam, err := ipam.New(logger, nodeID, &config.IPAMConfig, broker)
+	config *Config, nodeConfig *OneNodeConfig, nodeID uint32, broker keyval.ProtoBroker, blockStore ipam.PodCIDRBlockStore,
+	egressStore egressIPStore) (*remoteCNIserver, error) {
+	ipam, err := ipam.New(logger, nodeID, &config.IPAMConfig, broker, blockStore)
 	if err != nil {
 		return nil, err
 	}
 
+	cli := newVppCLI(govppChan, index)
 	server := &remoteCNIserver{
 		Logger:               logger,
 		vppTxnFactory:        vppTxnFactory,
@@ -237,10 +367,65 @@ func newRemoteCNIServer(logger logging.Logger, vppTxnFactory func() linux.DataCh
 		tapV2RxRingSize:            config.TAPv2RxRingSize,
 		tapV2TxRingSize:            config.TAPv2TxRingSize,
 		disableTCPstack:            config.TCPstackDisabled,
-		useL2Interconnect:          config.UseL2Interconnect,
 		configuredInThisRun:        map[string]bool{},
+		podsInProgress:             map[string]struct{}{},
+		metrics:                    newCNIMetrics(agentLabel),
+		otherNodes:                 map[uint32]*node.NodeInfo{},
+		otherNodePodRoutes:         map[uint32][]*vpp_l3.StaticRoutes_Route{},
+		secondaryNetworks:          map[string]SecondaryNetworkConfig{},
+		podBandwidth:               map[podmodel.ID]podBandwidth{},
+		podArgs:                    map[podmodel.ID]map[string]string{},
+		podReflectionTimeout:       defaultPodReflectionTimeout,
+		stickyIPReleases:           map[podmodel.ID]*time.Timer{},
+		stickyPodIPHoldTime:        defaultStickyPodIPHoldTime,
+		bandwidthLimiter:           newVppPolicers(logger, cli),
+		cli:                        cli,
+		egressStore:                egressStore,
+	}
+	server.overlay, err = newNodeOverlay(server, cli)
+	if err != nil {
+		return nil, err
+	}
+	if config.IPSec.Enabled {
+		if _, isVxlan := server.overlay.(*vxlanOverlay); !isVxlan {
+			return nil, fmt.Errorf("IPsec is supported only with the VXLAN overlay")
+		}
+		server.ipsec, err = newIPSecOverlay(logger, cli, nodeID, config.IPSec.Mode)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, network := range config.SecondaryNetworks {
+		if _, err := ipam.SecondaryPodNetwork(network.Name); err != nil {
+			return nil, err
+		}
+		if _, duplicate := server.secondaryNetworks[network.Name]; duplicate {
+			return nil, fmt.Errorf("secondary network %v is configured more than once", network.Name)
+		}
+		server.secondaryNetworks[network.Name] = network
+	}
+	err = server.initTenants()
+	if err != nil {
+		return nil, err
+	}
+	err = server.initEgress()
+	if err != nil {
+		return nil, err
+	}
+	err = server.initUplinks()
+	if err != nil {
+		return nil, err
 	}
 	server.vswitchCond = sync.NewCond(&server.Mutex)
+	server.podRequestCond = sync.NewCond(&server.Mutex)
+	server.podWiringCond = sync.NewCond(&server.Mutex)
+	server.podReflectedCond = sync.NewCond(&server.Mutex)
+	if config.IPAMConfig.StickyPodIPHoldTime != 0 {
+		server.stickyPodIPHoldTime = time.Duration(config.IPAMConfig.StickyPodIPHoldTime) * time.Second
+	}
+	server.podTxns = newTxnBatcher(func() linux.DataChangeDSL {
+		return server.vppTxnFactory()
+	})
 	server.ctx, server.ctxCancelFunc = context.WithCancel(context.Background())
 	if nodeConfig != nil && nodeConfig.Gateway != "" {
 		server.defaultGw = net.ParseIP(nodeConfig.Gateway)
@@ -255,11 +440,37 @@ func (s *remoteCNIserver) resync() error {
 	s.Lock()
 	defer s.Unlock()
 
+	// wait until the PODs being wired are finished, the new ones wait until the resync is done
+	s.vswitchResyncing = true
+	for s.podsWiring > 0 {
+		s.podWiringCond.Wait()
+	}
+	defer func() {
+		s.vswitchResyncing = false
+		s.vswitchCond.Broadcast()
+	}()
+
 	err := s.configureVswitchConnectivity()
 	if err != nil {
 		s.Logger.Error(err)
+		return err
+	}
+
+	// policers are configured outside of the VPP plugins and therefore need to be re-applied
+	// for the PODs connected before the restart
+	for id := range s.podBandwidth {
+		err = s.applyPodBandwidth(id)
+		if err != nil {
+			s.Logger.Error(err)
+		}
 	}
 
+	// the isolation ACLs of the tenants are also attached outside of the VPP plugins
+	s.isolateTenantPods()
+
+	// remove wiring of the PODs deleted while the vswitch was down
+	s.cleanupStalePods()
+
 	return err
 }
 
@@ -282,6 +493,12 @@ func (s *remoteCNIserver) Delete(ctx context.Context, request *cni.CNIRequest) (
 	return s.unconfigureContainerConnectivity(request)
 }
 
+// Check handles CNI Check request, verifies that the container is still connected to the network as expected.
+func (s *remoteCNIserver) Check(ctx context.Context, request *cni.CNIRequest) (*cni.CNIReply, error) {
+	s.Info("Check request received ", *request)
+	return s.checkContainerConnectivity(request)
+}
+
 // configureVswitchConnectivity configures base vSwitch VPP connectivity to the host IP stack and to the other hosts.
 // Namely, it configures:
 //  - physical NIC interface + static routes to PODs on other hosts
@@ -303,7 +520,7 @@ func (s *remoteCNIserver) configureVswitchConnectivity() error {
 		// For STN case, do not rely on TAP interconnect, since it has been pre-configured by contiv-init.
 		// Let's relay on VXLAN BVI interface name. Note that this may not work in case that VXLANs are disabled.
 		expectedIfName = vxlanBVIInterfaceName
-		if s.config.UseL2Interconnect {
+		if _, isVxlan := s.overlay.(*vxlanOverlay); !isVxlan {
 			s.Logger.Warn("Unable to reliably determine whether VSwitch connectivity is configured, proceeeding with config.")
 		}
 	}
@@ -332,13 +549,18 @@ func (s *remoteCNIserver) configureVswitchConnectivity() error {
 		return err
 	}
 
-	if !s.useL2Interconnect {
-		// configure VXLAN tunnel bridge domain
-		err = s.configureVswitchVxlanBridgeDomain(config)
-		if err != nil {
-			s.Logger.Error(err)
-			return err
-		}
+	// configure the base config of the node overlay
+	err = s.overlay.configure(config)
+	if err != nil {
+		s.Logger.Error(err)
+		return err
+	}
+
+	// configure the networks of the tenants
+	err = s.configureTenants(config)
+	if err != nil {
+		s.Logger.Error(err)
+		return err
 	}
 
 	// persist vswitch configuration in ETCD
@@ -374,12 +596,12 @@ func (s *remoteCNIserver) configureVswitchNICs(config *vswitchConfig) error {
 		s.Logger.Debugf("Physical NIC name taken from nodeConfig: %v ", nicName)
 	}
 
-	if nicName == "" {
+	if nicName == "" && len(s.uplinks) == 0 {
 		// name not specified in config, use heuristic - first non-virtual interface
 		for _, name := range s.swIfIndex.GetMapping().ListNames() {
 			if strings.HasPrefix(name, "local") || strings.HasPrefix(name, "loop") ||
 				strings.HasPrefix(name, "host") || strings.HasPrefix(name, "tap") ||
-				name == vxlanBVIInterfaceName {
+				name == vxlanBVIInterfaceName || name == ipipLoopName {
 				continue
 			} else {
 				nicName = name
@@ -417,6 +639,17 @@ func (s *remoteCNIserver) configureVswitchNICs(config *vswitchConfig) error {
 		}
 	}
 
+	// configure the uplinks, the node IP is configured on a loopback instead of the main interface
+	if len(s.uplinks) > 0 {
+		s.Logger.Debug("Configuring VPP for uplinks")
+
+		err := s.configureUplinks(config)
+		if err != nil {
+			s.Logger.Error(err)
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -473,6 +706,18 @@ func (s *remoteCNIserver) configureMainVPPInterface(config *vswitchConfig, nicNa
 		s.Logger.Infof("Configuring %v to use %v", nicName, nodeIP.String())
 	}
 
+	// determine main node IPv6 address (DHCP is supported for IPv4 only)
+	nodeIPv6 := ""
+	if !useSTN && s.ipam.IPv6Enabled() {
+		nodeIPv6Net, err := s.ipam.NodeIPv6WithPrefix(s.ipam.NodeID())
+		if err != nil {
+			s.Logger.Error("Unable to generate node IPv6 address.")
+			return err
+		}
+		nodeIPv6 = nodeIPv6Net.String()
+		s.Logger.Infof("Configuring %v to use %v", nicName, nodeIPv6)
+	}
+
 	if !useSTN {
 		if nicName != "" {
 			// configure the physical NIC
@@ -493,6 +738,9 @@ func (s *remoteCNIserver) configureMainVPPInterface(config *vswitchConfig, nicNa
 					s.applyDHCPdata(metadata)
 				}
 			}
+			if nodeIPv6 != "" {
+				nic.IpAddresses = append(nic.IpAddresses, nodeIPv6)
+			}
 			txn1.VppInterface(nic)
 			config.nics = append(config.nics, nic)
 			s.mainPhysicalIf = nicName
@@ -501,6 +749,9 @@ func (s *remoteCNIserver) configureMainVPPInterface(config *vswitchConfig, nicNa
 			s.Logger.Debug("Physical NIC not found, configuring loopback instead.")
 
 			loop := s.physicalInterfaceLoopback(s.nodeIP)
+			if nodeIPv6 != "" {
+				loop.IpAddresses = append(loop.IpAddresses, nodeIPv6)
+			}
 			txn1.VppInterface(loop)
 			config.nics = append(config.nics, loop)
 		}
@@ -748,6 +999,12 @@ func (s *remoteCNIserver) configureVswitchHostConnectivity(config *vswitchConfig
 	}
 	txn2.LinuxRoute(config.routeFromHost)
 
+	// IPv6 route from the host to PODs (only if the interconnect is configured by the agent)
+	if s.ipam.IPv6Enabled() && s.stnGw == "" {
+		config.routeFromHostV6 = s.routePODsFromHostIPv6(s.ipam.VEthVPPEndIPv6().String())
+		txn2.LinuxRoute(config.routeFromHostV6)
+	}
+
 	// route from the host to k8s service range from the host
 	if s.stnGw == "" {
 		config.routeForServices = s.routeServicesFromHost(s.ipam.VEthVPPEndIP().String())
@@ -772,40 +1029,6 @@ func (s *remoteCNIserver) configureVswitchHostConnectivity(config *vswitchConfig
 	return nil
 }
 
-// configureVswitchVxlanBridgeDomain configures bridge domain for the VXLAN tunnels.
-func (s *remoteCNIserver) configureVswitchVxlanBridgeDomain(config *vswitchConfig) error {
-	var err error
-	txn := s.vppTxnFactory().Put()
-
-	// VXLAN BVI loopback
-	config.vxlanBVI, err = s.vxlanBVILoopback()
-	if err != nil {
-		s.Logger.Error(err)
-		return err
-	}
-	txn.VppInterface(config.vxlanBVI)
-	s.vxlanBVIIfName = config.vxlanBVI.Name
-
-	// bridge domain for the VXLAN tunnel
-	config.vxlanBD = s.vxlanBridgeDomain(config.vxlanBVI.Name)
-	// create deep copy since the config will be overwritten when a node joins the cluster
-	newbd := proto.Clone(config.vxlanBD)
-	txn.BD(newbd.(*vpp_l2.BridgeDomains_BridgeDomain))
-	// remember the VXLAN config - needs to be reconfigured with each new VXLAN (each new node)
-	s.vxlanBD = config.vxlanBD
-
-	// execute the config transaction
-	if !config.configured {
-		err = txn.Send().ReceiveReply()
-		if err != nil {
-			s.Logger.Error(err)
-			return err
-		}
-	}
-
-	return nil
-}
-
 // persistVswitchConfig persits vswitch configuration in ETCD
 func (s *remoteCNIserver) persistVswitchConfig(config *vswitchConfig) error {
 	if config.configured {
@@ -824,10 +1047,20 @@ func (s *remoteCNIserver) persistVswitchConfig(config *vswitchConfig) error {
 		changes[vpp_l3.RouteKey(config.defaultRoute.VrfId, config.defaultRoute.DstIpAddr, config.defaultRoute.NextHopAddr)] = config.defaultRoute
 	}
 
-	// VXLAN-related data
-	if !s.useL2Interconnect {
-		changes[vpp_intf.InterfaceKey(config.vxlanBVI.Name)] = config.vxlanBVI
-		changes[vpp_l2.BridgeDomainKey(config.vxlanBD.Name)] = config.vxlanBD
+	// uplinks
+	for _, route := range config.uplinkRoutes {
+		changes[vpp_l3.RouteKey(route.VrfId, route.DstIpAddr, route.NextHopAddr)] = route
+	}
+	for _, session := range config.bfdSessions {
+		changes[vpp_bfd.SessionKey(session.Interface)] = session
+	}
+
+	// node overlay
+	for _, overlayIf := range config.overlayIfs {
+		changes[vpp_intf.InterfaceKey(overlayIf.Name)] = overlayIf
+	}
+	for _, overlayBD := range config.overlayBDs {
+		changes[vpp_l2.BridgeDomainKey(overlayBD.Name)] = overlayBD
 	}
 
 	// TAP / veths + AF_APCKET
@@ -849,6 +1082,9 @@ func (s *remoteCNIserver) persistVswitchConfig(config *vswitchConfig) error {
 		}
 	}
 	changes[linux_l3.StaticRouteKey(config.routeFromHost.Name)] = config.routeFromHost
+	if config.routeFromHostV6 != nil {
+		changes[linux_l3.StaticRouteKey(config.routeFromHostV6.Name)] = config.routeFromHostV6
+	}
 	changes[linux_l3.StaticRouteKey(config.routeForServices.Name)] = config.routeForServices
 	changes[vpp_l4.FeatureKey()] = config.l4Features
 
@@ -894,17 +1130,25 @@ func (s *remoteCNIserver) cleanupVswitchConnectivity() {
 // It also configures the VPP TCP stack for this container, in case it would be LD_PRELOAD-ed.
 func (s *remoteCNIserver) configureContainerConnectivity(request *cni.CNIRequest) (reply *cni.CNIReply, err error) {
 	var (
-		podIP                  net.IP
-		persisted              bool
-		revertTxn1, revertTxn2 linux.DeleteDSL
+		podIP, podIPv6                     net.IP
+		persisted                          bool
+		revertTxn1, revertTxn2, revertTxn3 linux.DeleteDSL
+		networks                           []string
+		phaseStart                         time.Time
 	)
 
-	// do not connect any containers until the base vswitch config is successfully applied
+	id := request.ContainerId
+	trace := s.metrics.startRequest(cniAddOperation, id)
+	defer func() {
+		trace.finish(err)
+	}()
+
 	s.Lock()
-	for !s.vswitchConnectivityConfigured {
-		s.vswitchCond.Wait()
-	}
-	defer s.Unlock()
+	s.startPodRequest(id)
+	defer func() {
+		s.finishPodRequest(id)
+		s.Unlock()
+	}()
 
 	// prepare config details struct
 	extraArgs := s.parseCniExtraArgs(request.ExtraArguments)
@@ -912,57 +1156,160 @@ func (s *remoteCNIserver) configureContainerConnectivity(request *cni.CNIRequest
 		PodName:      extraArgs[podNameExtraArg],
 		PodNamespace: extraArgs[podNamespaceExtraArg],
 	}
-
-	id := request.ContainerId
 	config.ID = id
+	trace.setPod(config.PodNamespace, config.PodName)
+	podID := podmodel.ID{Name: config.PodName, Namespace: config.PodNamespace}
+	if err = s.waitForPodReflection(podID); err != nil {
+		s.Logger.Error(err)
+		return s.generateCniErrorReply(err)
+	}
+	s.addPodArgs(podID, extraArgs)
 
+	// the revert is executed with the lock held
 	defer func() {
 		if err != nil {
+			if config.MemifSocket != "" {
+				os.RemoveAll(filepath.Dir(config.MemifSocket))
+			}
 			if persisted {
 				s.deletePersistedPodConfig(podConfigToProto(config))
 				delete(s.configuredInThisRun, id)
 			}
+			if revertTxn3 != nil {
+				revertTxn3.Send().ReceiveReply()
+			}
 			if revertTxn2 != nil {
+				s.bandwidthLimiter.removeLimits(id)
 				revertTxn2.Send().ReceiveReply()
 			}
 			if revertTxn1 != nil {
 				revertTxn1.Send().ReceiveReply()
 			}
 			if podIP != nil {
-				s.ipam.ReleasePodIP(id)
+				s.releasePodIP(id, config.Tenant)
 			}
+			s.releaseSecondaryIPs(id, networks)
 		}
 	}()
 
+	// check if the POD should be connected via memif
+	memif, err := usesMemif(extraArgs)
+	if err != nil {
+		s.Logger.Error(err)
+		trace.fail(causeInvalidRequest)
+		return s.generateCniErrorReply(err)
+	}
+	if memif {
+		config.MemifSocket = s.memifSocketFromRequest(request, config)
+	}
+
+	// connect the POD to the network of the tenant of its namespace
+	tenant, err := s.podTenant(config.PodNamespace)
+	if err != nil {
+		s.Logger.Error(err)
+		return s.generateCniErrorReply(err)
+	}
+	if tenant != nil {
+		if memif {
+			err = fmt.Errorf("memif is not supported for the pods of tenants")
+			s.Logger.Error(err)
+			trace.fail(causeInvalidRequest)
+			return s.generateCniErrorReply(err)
+		}
+		config.Tenant = tenant.Name
+	}
+
+	// check the secondary networks requested for the POD
+	networks, err = s.parsePodNetworks(extraArgs)
+	if err != nil {
+		s.Logger.Error(err)
+		trace.fail(causeInvalidRequest)
+		return s.generateCniErrorReply(err)
+	}
+
 	// assign an IP address for this POD
-	podIP, err = s.ipam.NextPodIP(id)
+	phaseStart = time.Now()
+	podIP, err = s.assignPodIP(id, config, extraArgs)
 	if err != nil {
-		return nil, fmt.Errorf("Can't get new IP address for pod: %v", err)
+		s.Logger.Error(err)
+		if used, size := s.ipam.PodIPPoolUsage(); config.Tenant == "" && used >= size {
+			trace.fail(causeIPPoolExhausted)
+		}
+		trace.phase(phaseIPAM, phaseStart, err)
+		return s.generateCniErrorReply(err)
 	}
 	podIPCIDR := podIP.String() + "/32"
 
+	// assign also an IPv6 address if dual-stack is enabled (the tenant networks are IPv4-only)
+	if s.ipam.IPv6Enabled() && config.Tenant == "" {
+		podIPv6, err = s.ipam.NextPodIPv6(id)
+		if err != nil {
+			err = fmt.Errorf("Can't get new IPv6 address for pod: %v", err)
+			s.Logger.Error(err)
+			trace.phase(phaseIPAM, phaseStart, err)
+			return s.generateCniErrorReply(err)
+		}
+	}
+	trace.phase(phaseIPAM, phaseStart, nil)
+
 	// TODO: merge transactions into one once linuxplugin supports TAPs and all race-conditions are fixed.
 
-	// configure POD interface
+	// the POD is wired without the lock, concurrently with the other PODs
 	revertTxn1 = s.vppTxnFactory().Delete()
-	err = s.configurePodInterface(request, podIP, config, revertTxn1)
+	revertTxn2 = s.vppTxnFactory().Delete()
+	if len(networks) > 0 {
+		revertTxn3 = s.vppTxnFactory().Delete()
+	}
+	s.startPodWiring()
+	s.Unlock()
+
+	// configure POD interface
+	phaseStart = time.Now()
+	err = s.configurePodInterface(request, podIP, podIPv6, config, revertTxn1)
+	trace.phase(phaseLinux, phaseStart, err)
+
+	// configure POD-related config on VPP
+	if err == nil {
+		phaseStart = time.Now()
+		err = s.configurePodVPPSide(request, podIP, podIPv6, config, revertTxn2)
+		trace.phase(phaseVPP, phaseStart, err)
+	}
+
+	// attach POD to the requested secondary networks
+	if err == nil && len(networks) > 0 {
+		phaseStart = time.Now()
+		err = s.configureSecondaryInterfaces(request, networks, config, revertTxn3)
+		trace.phase(phaseSecondary, phaseStart, err)
+	}
+
+	s.Lock()
+	s.finishPodWiring()
 	if err != nil {
 		s.Logger.Error(err)
 		return s.generateCniErrorReply(err)
 	}
 
-	// configure POD-related config on VPP
-	revertTxn2 = s.vppTxnFactory().Delete()
-	err = s.configurePodVPPSide(request, podIP, config, revertTxn2)
+	// attach policers to the POD interface if the bandwidth is limited by the POD annotations
+	// (the limits may be changed by the POD annotations only with the lock held)
+	err = s.limitPodBandwidth(config, podIP, podIPv6)
+	if err != nil {
+		s.Logger.Error(err)
+		return s.generateCniErrorReply(err)
+	}
+
+	// isolate the POD of a tenant from the default VRF
+	err = s.isolateTenantPod(config.Tenant, config.VppIf.Name, false)
 	if err != nil {
 		s.Logger.Error(err)
 		return s.generateCniErrorReply(err)
 	}
 
 	// persist POD configuration in ETCD
+	phaseStart = time.Now()
 	err = s.persistPodConfig(config)
 	if err != nil {
 		s.Logger.Error(err)
+		trace.phase(phasePersist, phaseStart, err)
 		return s.generateCniErrorReply(err)
 	}
 	s.configuredInThisRun[id] = true
@@ -973,41 +1320,100 @@ func (s *remoteCNIserver) configureContainerConnectivity(request *cni.CNIRequest
 		err = s.configuredContainers.RegisterContainer(id, podConfigToProto(config))
 		if err != nil {
 			s.Logger.Error(err)
+			trace.phase(phasePersist, phaseStart, err)
 			return s.generateCniErrorReply(err)
 		}
 	}
 
+	// reflect the IPv6 address of the POD for the policies of the other PODs
+	err = s.publishPodIPv6(config, podIPv6)
+	if err != nil {
+		s.Logger.Error(err)
+		trace.phase(phasePersist, phaseStart, err)
+		return s.generateCniErrorReply(err)
+	}
+	trace.phase(phasePersist, phaseStart, nil)
+
+	// attach the egress policies selecting the POD
+	err = s.renderEgress()
+	if err != nil {
+		// treat error as warning, the POD is connected
+		s.Logger.WithField("err", err).Warn("Failed to apply the egress policies")
+		err = nil
+	}
+
 	// prepare and send reply for the CNI request
-	reply = s.generateCniReply(config, request.NetworkNamespace, podIPCIDR)
+	reply = s.generateCniReply(config, request.NetworkNamespace, podIPCIDR, podIPv6)
 	return reply, nil
 }
 
 // unconfigureContainerConnectivity disconnects the POD from vSwitch VPP.
-func (s *remoteCNIserver) unconfigureContainerConnectivity(request *cni.CNIRequest) (*cni.CNIReply, error) {
-	var err error
+func (s *remoteCNIserver) unconfigureContainerConnectivity(request *cni.CNIRequest) (reply *cni.CNIReply, err error) {
+	id := request.ContainerId
+	trace := s.metrics.startRequest(cniDeleteOperation, id)
+	defer func() {
+		trace.finish(err)
+	}()
 
-	// do not try to disconnect any containers until the base vswitch config is successfully applied
 	s.Lock()
-	for !s.vswitchConnectivityConfigured {
-		s.vswitchCond.Wait()
-	}
-	defer s.Unlock()
+	s.startPodRequest(id)
+	defer func() {
+		s.finishPodRequest(id)
+		s.Unlock()
+	}()
 
 	// configuredContainers should not be nil unless this is a unit test
 	if s.configuredContainers == nil {
-		err = fmt.Errorf("configuration was not stored for container: %s", request.ContainerId)
+		err = fmt.Errorf("configuration was not stored for container: %s", id)
 		s.Logger.Warn(err)
 		return s.generateCniEmptyOKReply(), nil
 	}
 
-	id := request.ContainerId
 	// load container config
 	config, found := s.configuredContainers.LookupContainer(id)
 	if !found {
 		s.Logger.Warnf("cannot find configuration for container: %s\n", id)
-		reply := s.generateCniEmptyOKReply()
+		reply = s.generateCniEmptyOKReply()
 		return reply, nil
 	}
+	trace.setPod(config.PodNamespace, config.PodName)
+
+	// the POD is unwired without the lock, concurrently with the other PODs
+	s.prepareContainerRemoval(config)
+	s.startPodWiring()
+	s.Unlock()
+	err = s.unwireContainer(config, trace)
+	s.Lock()
+	s.finishPodWiring()
+	if err == nil {
+		err = s.releaseContainer(config, trace)
+	}
+	if err != nil {
+		return s.generateCniErrorReply(err)
+	}
+
+	// prepare and send reply for the CNI request
+	reply = s.generateCniEmptyOKReply()
+	return reply, nil
+}
+
+// removeContainer disconnects the container from vSwitch VPP, removes its configuration from ETCD
+// and from the internal map and releases its IP addresses.
+// The method must be called with the CNI server lock held.
+func (s *remoteCNIserver) removeContainer(config *container.Persisted) error {
+	s.prepareContainerRemoval(config)
+	err := s.unwireContainer(config, nil)
+	if err != nil {
+		return err
+	}
+	return s.releaseContainer(config, nil)
+}
+
+// prepareContainerRemoval runs the pre-removal hooks of the container and detaches the egress policies
+// and the isolation ACLs of the tenant.
+// The method must be called with the CNI server lock held.
+func (s *remoteCNIserver) prepareContainerRemoval(config *container.Persisted) {
+	var err error
 
 	// Run all registered pre-removal hooks.
 	for _, hook := range s.podPreRemovalHooks {
@@ -1019,50 +1425,242 @@ func (s *remoteCNIserver) unconfigureContainerConnectivity(request *cni.CNIReque
 		}
 	}
 
+	// detach the egress policies from the POD interface
+	err = s.detachPodEgress(config.VppIfName)
+	if err != nil {
+		// treat error as warning, the policies are re-applied with the next update
+		s.Logger.WithField("err", err).Warn("Failed to detach the egress policies from the pod")
+	}
+
+	// detach the isolation ACLs of the tenant from the POD interface
+	err = s.releaseTenantPod(config.Tenant, config.VppIfName)
+	if err != nil {
+		// treat error as warning, the interface is removed anyway
+		s.Logger.WithField("err", err).Warn("Failed to detach the tenant isolation ACLs from the pod")
+	}
+}
+
+// unwireContainer disconnects the container from vSwitch VPP. The phases are measured by the trace
+// of the CNI request, if given. The method must be called either with the CNI server lock held,
+// or between startPodWiring and finishPodWiring with the container marked as being processed.
+func (s *remoteCNIserver) unwireContainer(config *container.Persisted, trace *cniRequestTrace) error {
+	// detach POD from the secondary networks
+	if len(config.SecondaryInterfaces) > 0 {
+		phaseStart := time.Now()
+		err := s.unconfigureSecondaryInterfaces(config)
+		trace.phase(phaseSecondary, phaseStart, err)
+		if err != nil {
+			s.Logger.Error(err)
+			return err
+		}
+	}
+
 	// delete POD-related config on VPP
-	err = s.unconfigurePodVPPSide(config)
+	phaseStart := time.Now()
+	err := s.unconfigurePodVPPSide(config)
+	trace.phase(phaseVPP, phaseStart, err)
 	if err != nil {
 		s.Logger.Error(err)
-		return s.generateCniErrorReply(err)
+		return err
 	}
 
 	// unconfigure POD interface
-	err = s.unconfigurePodInterface(request, config)
+	phaseStart = time.Now()
+	err = s.unconfigurePodInterface(config)
+	trace.phase(phaseLinux, phaseStart, err)
 	if err != nil {
 		s.Logger.Error(err)
-		return s.generateCniErrorReply(err)
+		return err
 	}
+	return nil
+}
 
+// releaseContainer removes the configuration of the disconnected container from ETCD and from the internal map
+// and releases its IP addresses. The phases are measured by the trace of the CNI request, if given.
+// The method must be called with the CNI server lock held.
+func (s *remoteCNIserver) releaseContainer(config *container.Persisted, trace *cniRequestTrace) error {
 	// delete persisted POD configuration from ETCD
-	err = s.deletePersistedPodConfig(config)
+	phaseStart := time.Now()
+	err := s.deletePersistedPodConfig(config)
 	if err != nil {
 		s.Logger.Error(err)
-		return s.generateCniErrorReply(err)
+		trace.phase(phasePersist, phaseStart, err)
+		return err
 	}
+	delete(s.configuredInThisRun, config.ID)
 
 	// remove POD configuration from the internal map
 	if s.configuredContainers != nil {
-		_, _, err = s.configuredContainers.UnregisterContainer(id)
+		_, _, err = s.configuredContainers.UnregisterContainer(config.ID)
 		if err != nil {
 			s.Logger.Error(err)
-			return s.generateCniErrorReply(err)
+			trace.phase(phasePersist, phaseStart, err)
+			return err
 		}
 	}
 
+	// withdraw the IPv6 address of the POD
+	err = s.withdrawPodIPv6(config)
+	if err != nil {
+		// treat error as warning, the record is overwritten when the address is re-assigned
+		s.Logger.WithField("err", err).Warn("Failed to withdraw the IPv6 address of the pod")
+	}
+	trace.phase(phasePersist, phaseStart, nil)
+
 	// release IP address of the POD
-	err = s.ipam.ReleasePodIP(id)
+	phaseStart = time.Now()
+	err = s.releasePodIP(config.ID, config.Tenant)
 	if err != nil {
 		s.Logger.Error(err)
-		return s.generateCniErrorReply(err)
+		trace.phase(phaseIPAM, phaseStart, err)
+		return err
 	}
+	for _, secondaryIf := range config.SecondaryInterfaces {
+		err = s.ipam.ReleaseSecondaryPodIP(secondaryIf.Network, config.ID)
+		if err != nil {
+			s.Logger.Error(err)
+			trace.phase(phaseIPAM, phaseStart, err)
+			return err
+		}
+	}
+	trace.phase(phaseIPAM, phaseStart, nil)
+	return nil
+}
 
-	// prepare and send reply for the CNI request
-	reply := s.generateCniEmptyOKReply()
+// checkContainerConnectivity verifies that the POD is still connected to vSwitch VPP as it was configured
+// by the Add request. The reply lists the IP addresses of the POD interfaces, so that the caller can compare
+// them with the result of the Add request.
+func (s *remoteCNIserver) checkContainerConnectivity(request *cni.CNIRequest) (*cni.CNIReply, error) {
+	id := request.ContainerId
+	s.Lock()
+	s.startPodRequest(id)
+	defer func() {
+		s.finishPodRequest(id)
+		s.Unlock()
+	}()
+
+	if s.configuredContainers == nil {
+		return s.generateCniErrorReply(fmt.Errorf("configuration was not stored for container: %s", id))
+	}
+	config, found := s.configuredContainers.LookupContainer(id)
+	if !found {
+		return s.generateCniErrorReply(fmt.Errorf("container %s is not connected to the network", id))
+	}
+
+	// the IP address must be still assigned to the POD
+	podIP := s.podIP(id, config.Tenant)
+	if podIP == nil || podIP.String() != config.VppARPEntryIP {
+		return s.generateCniErrorReply(fmt.Errorf("IP address %s is no longer assigned to container %s", config.VppARPEntryIP, id))
+	}
+
+	// the interfaces connecting the POD must exist in VPP
+	ifNames := []string{config.VppIfName}
+	for _, secondaryIf := range config.SecondaryInterfaces {
+		ifNames = append(ifNames, secondaryIf.VppIfName)
+	}
+	for _, ifName := range ifNames {
+		if _, _, exists := s.swIfIndex.LookupIdx(ifName); !exists {
+			return s.generateCniErrorReply(fmt.Errorf("interface %s of container %s does not exist in VPP", ifName, id))
+		}
+	}
+
+	// reply with the addresses of the POD
+	reply := &cni.CNIReply{
+		Result: resultOk,
+		Interfaces: []*cni.CNIReply_Interface{
+			{
+				Name:    config.VppIfName,
+				Sandbox: request.NetworkNamespace,
+				IpAddresses: []*cni.CNIReply_Interface_IP{
+					{
+						Version: cni.CNIReply_Interface_IP_IPV4,
+						Address: ipWithFullPrefix(podIP),
+						Gateway: s.podGatewayIP(config.Tenant).String(),
+					},
+				},
+			},
+		},
+	}
+	if config.VppARPEntryIPv6 != "" {
+		reply.Interfaces[0].IpAddresses = append(reply.Interfaces[0].IpAddresses, &cni.CNIReply_Interface_IP{
+			Version: cni.CNIReply_Interface_IP_IPV6,
+			Address: ipWithFullPrefix(net.ParseIP(config.VppARPEntryIPv6)),
+			Gateway: s.ipam.PodGatewayIPv6().String(),
+		})
+	}
+	for _, secondaryIf := range config.SecondaryInterfaces {
+		gw, err := s.ipam.SecondaryPodGatewayIP(secondaryIf.Network)
+		if err != nil {
+			return s.generateCniErrorReply(err)
+		}
+		reply.Interfaces = append(reply.Interfaces, &cni.CNIReply_Interface{
+			Name:    secondaryIf.VppIfName,
+			Sandbox: request.NetworkNamespace,
+			IpAddresses: []*cni.CNIReply_Interface_IP{
+				{
+					Version: cni.CNIReply_Interface_IP_IPV4,
+					Address: ipWithFullPrefix(net.ParseIP(secondaryIf.IP)),
+					Gateway: gw.String(),
+				},
+			},
+		})
+	}
 	return reply, nil
 }
 
+// startPodRequest waits until the base vswitch config is successfully applied and until the processing
+// of the previous CNI request for the same container is finished, then marks the container as being
+// processed. The method must be called with the CNI server lock held.
+func (s *remoteCNIserver) startPodRequest(id string) {
+	// do not connect / disconnect any containers until the base vswitch config is successfully applied
+	for !s.vswitchConnectivityConfigured || s.vswitchResyncing {
+		s.vswitchCond.Wait()
+	}
+	for s.podInProgress(id) {
+		s.podRequestCond.Wait()
+	}
+	s.podsInProgress[id] = struct{}{}
+}
+
+// finishPodRequest marks the processing of the CNI request for the container as finished.
+// The method must be called with the CNI server lock held.
+func (s *remoteCNIserver) finishPodRequest(id string) {
+	delete(s.podsInProgress, id)
+	s.podRequestCond.Broadcast()
+}
+
+// startPodWiring marks the start of wiring of a POD into VPP performed without the lock.
+// The method must be called with the CNI server lock held.
+func (s *remoteCNIserver) startPodWiring() {
+	s.podsWiring++
+}
+
+// finishPodWiring marks the end of wiring of a POD into VPP performed without the lock.
+// The method must be called with the CNI server lock held.
+func (s *remoteCNIserver) finishPodWiring() {
+	s.podsWiring--
+	s.podWiringCond.Broadcast()
+}
+
+// podInProgress returns true if a CNI request for the container is being processed.
+func (s *remoteCNIserver) podInProgress(id string) bool {
+	_, inProgress := s.podsInProgress[id]
+	return inProgress
+}
+
 // configurePodInterface configures POD's network interface and its routes + ARPs.
-func (s *remoteCNIserver) configurePodInterface(request *cni.CNIRequest, podIP net.IP, config *PodConfig, revertTxn linux.DeleteDSL) error {
+func (s *remoteCNIserver) configurePodInterface(request *cni.CNIRequest, podIP, podIPv6 net.IP, config *PodConfig, revertTxn linux.DeleteDSL) error {
+
+	// memif has no counterpart in the POD namespace to be configured
+	if config.MemifSocket != "" {
+		err := s.configurePodMemif(request, podIP, podIPv6, config)
+		if err != nil {
+			s.Logger.Error(err)
+			return err
+		}
+		revertTxn.VppInterface(config.VppIf.Name)
+		return nil
+	}
 
 	// this is necessary for the latest docker where ipv6 is disabled by default.
 	// OS assigns automatically ipv6 addr to a newly created TAP. We
@@ -1077,57 +1675,76 @@ func (s *remoteCNIserver) configurePodInterface(request *cni.CNIRequest, podIP n
 	}
 
 	podIPCIDR := podIP.String() + "/32"
-	podIPNet := &net.IPNet{
-		IP:   podIP,
-		Mask: net.CIDRMask(net.IPv4len*8, net.IPv4len*8),
+	podIPs := s.podIPAddresses(podIP, podIPv6)
+	vppIfIPs := s.vppIfIPAddresses(podIP, podIPv6)
+	if config.Tenant != "" {
+		// the VPP end is unnumbered with the loopback of the tenant, see tenantPodInterface
+		vppIfIPs = nil
 	}
 
-	// prepare the config transaction 1
-	txn1 := s.vppTxnFactory().Put()
-
 	podIfName := ""
 
 	// create VPP to POD interconnect interface
 	if s.useTAPInterfaces {
 		// TAP interface
-		config.VppIf = s.tapFromRequest(request, podIP.String(), !s.disableTCPstack, podIPCIDR)
-		config.PodTap = s.podTAP(request, podIPNet)
+		config.VppIf = s.tapFromRequest(request, vppIfIPs, s.podUsesTCPStack("", config.Tenant), podIPCIDR)
+		s.tenantPodInterface(config)
+		config.PodTap = s.podTAP(request, podIPs)
 
 		podIfName = config.PodTap.Name
 
 		// configure vpp TAP interface in a separate transaction otherwise the AUTO_TAP
 		// might try to configure the other end before VPP is finished
-		err := s.vppTxnFactory().Put().VppInterface(config.VppIf).Send().ReceiveReply()
+		err := s.podTxns.commit(func(txn linux.DataChangeDSL) {
+			txn.Put().VppInterface(config.VppIf)
+		})
 		if err != nil {
 			s.Logger.Error(err)
 			return err
 		}
 		revertTxn.VppInterface(config.VppIf.Name)
-
-		txn1.LinuxInterface(config.PodTap)
 	} else {
 		// veth pair + AF_PACKET
-		config.Veth1 = s.veth1FromRequest(request, podIPCIDR)
+		config.Veth1 = s.veth1FromRequest(request, podIPs)
 		config.Veth2 = s.veth2FromRequest(request)
-		config.VppIf = s.afpacketFromRequest(request, podIP.String(), !s.disableTCPstack, podIPCIDR)
+		config.VppIf = s.afpacketFromRequest(request, vppIfIPs, s.podUsesTCPStack("", config.Tenant), podIPCIDR)
+		s.tenantPodInterface(config)
 
-		txn1.LinuxInterface(config.Veth1).
-			LinuxInterface(config.Veth2).
-			VppInterface(config.VppIf)
 		revertTxn.VppInterface(config.VppIf.Name)
 		podIfName = config.Veth1.Name
 	}
 
 	// link scope route - must be added before the default route
 	config.PodLinkRoute = s.podLinkRouteFromRequest(request, podIfName)
-	txn1.LinuxRoute(config.PodLinkRoute)
+	config.PodLinkRoute.DstIpAddr = ipWithFullPrefix(s.podGatewayIP(config.Tenant))
 
 	// ARP to VPP
 	config.PodARPEntry = s.podArpEntry(request, podIfName, config.VppIf.PhysAddress)
-	txn1.LinuxArpEntry(config.PodARPEntry)
+	config.PodARPEntry.IpAddr = s.podGatewayIP(config.Tenant).String()
 
-	// execute the config transaction
-	err := txn1.Send().ReceiveReply()
+	if podIPv6 != nil {
+		// IPv6 link scope route + neighbor entry for the IPv6 gateway
+		config.PodLinkRouteIPv6 = s.podLinkRouteIPv6FromRequest(request, podIfName)
+		config.PodARPEntryIPv6 = s.podArpEntryIPv6(request, podIfName, config.VppIf.PhysAddress)
+	}
+
+	// execute the config transaction 1
+	err := s.podTxns.commit(func(txn linux.DataChangeDSL) {
+		txn1 := txn.Put()
+		if s.useTAPInterfaces {
+			txn1.LinuxInterface(config.PodTap)
+		} else {
+			txn1.LinuxInterface(config.Veth1).
+				LinuxInterface(config.Veth2).
+				VppInterface(config.VppIf)
+		}
+		txn1.LinuxRoute(config.PodLinkRoute).
+			LinuxArpEntry(config.PodARPEntry)
+		if podIPv6 != nil {
+			txn1.LinuxRoute(config.PodLinkRouteIPv6).
+				LinuxArpEntry(config.PodARPEntryIPv6)
+		}
+	})
 	if err != nil {
 		s.Logger.Error(err)
 		return err
@@ -1136,14 +1753,22 @@ func (s *remoteCNIserver) configurePodInterface(request *cni.CNIRequest, podIP n
 	// prepare the config transaction 2
 	// the default route needs to be configured after the first transaction,
 	// since it depends on the link-local route in the transaction 1
-	txn2 := s.vppTxnFactory().Put()
 
 	// Add default route for the container
 	config.PodDefaultRoute = s.podDefaultRouteFromRequest(request, podIfName)
-	txn2.LinuxRoute(config.PodDefaultRoute)
+	config.PodDefaultRoute.GwAddr = s.podGatewayIP(config.Tenant).String()
+
+	if podIPv6 != nil {
+		config.PodDefaultRouteIPv6 = s.podDefaultRouteIPv6FromRequest(request, podIfName)
+	}
 
 	// execute the config transaction
-	err = txn2.Send().ReceiveReply()
+	err = s.podTxns.commit(func(txn linux.DataChangeDSL) {
+		txn2 := txn.Put().LinuxRoute(config.PodDefaultRoute)
+		if podIPv6 != nil {
+			txn2.LinuxRoute(config.PodDefaultRouteIPv6)
+		}
+	})
 	if err != nil {
 		s.Logger.Error(err)
 		return err
@@ -1153,23 +1778,43 @@ func (s *remoteCNIserver) configurePodInterface(request *cni.CNIRequest, podIP n
 }
 
 // unconfigurePodInterface unconfigures POD's network interface and its routes + ARPs.
-func (s *remoteCNIserver) unconfigurePodInterface(request *cni.CNIRequest, config *container.Persisted) error {
+func (s *remoteCNIserver) unconfigurePodInterface(config *container.Persisted) error {
+
+	if config.MemifSocket != "" {
+		err := s.unconfigurePodMemif(config)
+		if err != nil {
+			s.Logger.Error(err)
+		}
+		return err
+	}
 
 	// removal of configuration is split into multiple transactions because the order of delete operations
 	// in a transaction can not be guaranteed. If the interface is deleted before routes and arp entries,
 	// they are deleted automatically and follow up attempt to delete them results into errors.
 
 	if !s.test {
-		// prepare the config transaction
-		txn1 := s.vppTxnFactory().Delete()
+		// execute the config transaction
+		err := s.podTxns.commit(func(txn linux.DataChangeDSL) {
+			txn1 := txn.Delete()
+
+			// delete static routes
+			txn1.LinuxRoute(config.PodLinkRouteName).
+				LinuxRoute(config.PodDefaultRouteName)
 
-		// delete static routes
-		txn1.LinuxRoute(config.PodLinkRouteName).
-			LinuxRoute(config.PodDefaultRouteName)
+			// delete the ARP entry
+			txn1.LinuxArpEntry(config.PodARPEntryName)
 
-		// delete the ARP entry
-		txn1.LinuxArpEntry(config.PodARPEntryName)
-		err := txn1.Send().ReceiveReply()
+			// delete IPv6 routes and neighbor entry
+			if config.PodDefaultRouteIPv6Name != "" {
+				txn1.LinuxRoute(config.PodDefaultRouteIPv6Name)
+			}
+			if config.PodLinkRouteIPv6Name != "" {
+				txn1.LinuxRoute(config.PodLinkRouteIPv6Name)
+			}
+			if config.PodARPEntryIPv6Name != "" {
+				txn1.LinuxArpEntry(config.PodARPEntryIPv6Name)
+			}
+		})
 		if err != nil {
 			s.Logger.Error(err)
 			return err
@@ -1177,23 +1822,25 @@ func (s *remoteCNIserver) unconfigurePodInterface(request *cni.CNIRequest, confi
 	}
 
 	if s.useTAPInterfaces {
-		err := s.vppTxnFactory().Delete().LinuxInterface(config.PodTapName).Send().ReceiveReply()
+		err := s.podTxns.commit(func(txn linux.DataChangeDSL) {
+			txn.Delete().LinuxInterface(config.PodTapName)
+		})
 		if err != nil {
 			s.Logger.Warn(err)
 		}
 	}
 
-	txn2 := s.vppTxnFactory().Delete()
-
-	// delete VPP to POD interconnect interface
-	txn2.VppInterface(config.VppIfName)
-	if !s.useTAPInterfaces {
-		txn2.LinuxInterface(config.Veth1Name).
-			LinuxInterface(config.Veth2Name)
-	}
-
 	// execute the config transaction
-	err := txn2.Send().ReceiveReply()
+	err := s.podTxns.commit(func(txn linux.DataChangeDSL) {
+		txn2 := txn.Delete()
+
+		// delete VPP to POD interconnect interface
+		txn2.VppInterface(config.VppIfName)
+		if !s.useTAPInterfaces {
+			txn2.LinuxInterface(config.Veth1Name).
+				LinuxInterface(config.Veth2Name)
+		}
+	})
 	if err != nil {
 		s.Logger.Error(err)
 		return err
@@ -1203,39 +1850,57 @@ func (s *remoteCNIserver) unconfigurePodInterface(request *cni.CNIRequest, confi
 }
 
 // configurePodVPPSide configures vswitch VPP part of the POD networking.
-func (s *remoteCNIserver) configurePodVPPSide(request *cni.CNIRequest, podIP net.IP, config *PodConfig, revertTxn linux.DeleteDSL) error {
+func (s *remoteCNIserver) configurePodVPPSide(request *cni.CNIRequest, podIP, podIPv6 net.IP, config *PodConfig, revertTxn linux.DeleteDSL) error {
 	podIPCIDR := podIP.String() + "/32"
+	usesTCPStack := s.podUsesTCPStack(config.MemifSocket, config.Tenant)
 
-	// prepare the config transaction
-	txn := s.vppTxnFactory().Put()
-
-	if !s.disableTCPstack {
+	if usesTCPStack {
 		// VPP TCP stack config
 		config.Loopback = s.loopbackFromRequest(request, podIP.String())
 		config.AppNamespace = s.appNamespaceFromRequest(request)
 		config.StnRule = s.stnRule(podIP, config.VppIf.Name)
 
-		txn.VppInterface(config.Loopback).
-			AppNamespace(config.AppNamespace).
-			StnRule(config.StnRule)
 		revertTxn.VppInterface(config.Loopback.Name).
 			AppNamespace(config.AppNamespace.NamespaceId).
 			StnRule(config.StnRule.RuleName)
 	} else {
 		// route to PodIP via AF_PACKET / TAP
 		config.VppRoute = s.vppRouteFromRequest(request, podIPCIDR)
+		config.VppRoute.VrfId = s.tenantVrf(config.Tenant)
 
-		txn.StaticRoute(config.VppRoute)
 		revertTxn.StaticRoute(config.VppRoute.VrfId, config.VppRoute.DstIpAddr, config.VppRoute.NextHopAddr)
 	}
 
 	// ARP entry for POD IP
 	config.VppARPEntry = s.vppArpEntry(config.VppIf.Name, podIP, s.hwAddrForContainer())
-	txn.Arp(config.VppARPEntry)
 	revertTxn.Arp(config.VppARPEntry.Interface, config.VppARPEntry.IpAddress)
 
+	if podIPv6 != nil {
+		// IPv6 is not supported by the VPP TCP stack, always route IPv6 via AF_PACKET / TAP
+		config.VppRouteIPv6 = s.vppRouteFromRequest(request, ipWithFullPrefix(podIPv6))
+		revertTxn.StaticRoute(config.VppRouteIPv6.VrfId, config.VppRouteIPv6.DstIpAddr, config.VppRouteIPv6.NextHopAddr)
+
+		// neighbor entry for POD IPv6
+		config.VppARPEntryIPv6 = s.vppArpEntry(config.VppIf.Name, podIPv6, s.hwAddrForContainer())
+		revertTxn.Arp(config.VppARPEntryIPv6.Interface, config.VppARPEntryIPv6.IpAddress)
+	}
+
 	// execute the config transaction
-	err := txn.Send().ReceiveReply()
+	err := s.podTxns.commit(func(txn linux.DataChangeDSL) {
+		put := txn.Put()
+		if usesTCPStack {
+			put.VppInterface(config.Loopback).
+				AppNamespace(config.AppNamespace).
+				StnRule(config.StnRule)
+		} else {
+			put.StaticRoute(config.VppRoute)
+		}
+		put.Arp(config.VppARPEntry)
+		if podIPv6 != nil {
+			put.StaticRoute(config.VppRouteIPv6).
+				Arp(config.VppARPEntryIPv6)
+		}
+	})
 	if err != nil {
 		s.Logger.Error(err)
 		return err
@@ -1256,24 +1921,38 @@ func (s *remoteCNIserver) configurePodVPPSide(request *cni.CNIRequest, podIP net
 // unconfigurePodVPPSide deletes vswitch VPP part of the POD networking.
 func (s *remoteCNIserver) unconfigurePodVPPSide(config *container.Persisted) error {
 
-	// prepare the config transaction
-	txn := s.vppTxnFactory().Delete()
-
-	if !s.disableTCPstack {
-		// VPP TCP stack config
-		txn.VppInterface(config.LoopbackName).
-			AppNamespace(config.AppNamespaceID).
-			StnRule(config.StnRuleName)
-	} else {
-		// route to PodIP via AF_PACKET / TAP
-		txn.StaticRoute(config.VppRouteVrf, config.VppRouteDest, config.VppRouteNextHop)
+	// remove policers limiting the POD bandwidth
+	err := s.unlimitPodBandwidth(config)
+	if err != nil {
+		s.Logger.Error(err)
+		return err
 	}
 
-	// ARP entry for POD IP
-	txn.Arp(config.VppARPEntryInterface, config.VppARPEntryIP)
-
 	// execute the config transaction
-	err := txn.Send().ReceiveReply()
+	err = s.podTxns.commit(func(txn linux.DataChangeDSL) {
+		del := txn.Delete()
+
+		if s.podUsesTCPStack(config.MemifSocket, config.Tenant) {
+			// VPP TCP stack config
+			del.VppInterface(config.LoopbackName).
+				AppNamespace(config.AppNamespaceID).
+				StnRule(config.StnRuleName)
+		} else {
+			// route to PodIP via AF_PACKET / TAP
+			del.StaticRoute(config.VppRouteVrf, config.VppRouteDest, config.VppRouteNextHop)
+		}
+
+		// ARP entry for POD IP
+		del.Arp(config.VppARPEntryInterface, config.VppARPEntryIP)
+
+		// IPv6 route + neighbor entry for POD IPv6
+		if config.VppRouteIPv6Dest != "" {
+			del.StaticRoute(config.VppRouteVrf, config.VppRouteIPv6Dest, config.VppRouteNextHop)
+		}
+		if config.VppARPEntryIPv6 != "" {
+			del.Arp(config.VppARPEntryInterface, config.VppARPEntryIPv6)
+		}
+	})
 	if err != nil {
 		s.Logger.Error(err)
 		return err
@@ -1289,18 +1968,20 @@ func (s *remoteCNIserver) persistPodConfig(config *PodConfig) error {
 
 	// POD interface configuration
 	changes[vpp_intf.InterfaceKey(config.VppIf.Name)] = config.VppIf
-	if !s.useTAPInterfaces {
-		changes[linux_intf.InterfaceKey(config.Veth1.Name)] = config.Veth1
-		changes[linux_intf.InterfaceKey(config.Veth2.Name)] = config.Veth2
-	} else {
-		changes[linux_intf.InterfaceKey(config.PodTap.Name)] = config.PodTap
+	if config.MemifSocket == "" {
+		if !s.useTAPInterfaces {
+			changes[linux_intf.InterfaceKey(config.Veth1.Name)] = config.Veth1
+			changes[linux_intf.InterfaceKey(config.Veth2.Name)] = config.Veth2
+		} else {
+			changes[linux_intf.InterfaceKey(config.PodTap.Name)] = config.PodTap
+		}
+		changes[linux_l3.StaticRouteKey(config.PodLinkRoute.Name)] = config.PodLinkRoute
+		changes[linux_l3.StaticRouteKey(config.PodDefaultRoute.Name)] = config.PodDefaultRoute
+		changes[linux_l3.StaticArpKey(config.PodARPEntry.Name)] = config.PodARPEntry
 	}
-	changes[linux_l3.StaticRouteKey(config.PodLinkRoute.Name)] = config.PodLinkRoute
-	changes[linux_l3.StaticRouteKey(config.PodDefaultRoute.Name)] = config.PodDefaultRoute
-	changes[linux_l3.StaticArpKey(config.PodARPEntry.Name)] = config.PodARPEntry
 
 	// VPP-side configuration
-	if !s.disableTCPstack {
+	if s.podUsesTCPStack(config.MemifSocket, config.Tenant) {
 		changes[vpp_intf.InterfaceKey(config.Loopback.Name)] = config.Loopback
 		changes[stn.Key(config.StnRule.RuleName)] = config.StnRule
 		changes[vpp_l4.AppNamespacesKey(config.AppNamespace.NamespaceId)] = config.AppNamespace
@@ -1309,6 +1990,26 @@ func (s *remoteCNIserver) persistPodConfig(config *PodConfig) error {
 	}
 	changes[vpp_l3.ArpEntryKey(config.VppARPEntry.Interface, config.VppARPEntry.IpAddress)] = config.VppARPEntry
 
+	// IPv6 configuration
+	if config.PodLinkRouteIPv6 != nil {
+		changes[linux_l3.StaticRouteKey(config.PodLinkRouteIPv6.Name)] = config.PodLinkRouteIPv6
+	}
+	if config.PodDefaultRouteIPv6 != nil {
+		changes[linux_l3.StaticRouteKey(config.PodDefaultRouteIPv6.Name)] = config.PodDefaultRouteIPv6
+	}
+	if config.PodARPEntryIPv6 != nil {
+		changes[linux_l3.StaticArpKey(config.PodARPEntryIPv6.Name)] = config.PodARPEntryIPv6
+	}
+	if config.VppRouteIPv6 != nil {
+		changes[vpp_l3.RouteKey(config.VppRouteIPv6.VrfId, config.VppRouteIPv6.DstIpAddr, config.VppRouteIPv6.NextHopAddr)] = config.VppRouteIPv6
+	}
+	if config.VppARPEntryIPv6 != nil {
+		changes[vpp_l3.ArpEntryKey(config.VppARPEntryIPv6.Interface, config.VppARPEntryIPv6.IpAddress)] = config.VppARPEntryIPv6
+	}
+
+	// secondary networks configuration
+	s.secondaryIfsChanges(config, changes)
+
 	// persist the configuration
 	err = s.persistChanges(nil, changes, true)
 	if err != nil {
@@ -1326,21 +2027,23 @@ func (s *remoteCNIserver) deletePersistedPodConfig(config *container.Persisted)
 
 	// POD interface configuration
 	removedKeys = append(removedKeys, vpp_intf.InterfaceKey(config.VppIfName))
-	if !s.useTAPInterfaces {
-		removedKeys = append(removedKeys,
-			linux_intf.InterfaceKey(config.Veth1Name),
-			linux_intf.InterfaceKey(config.Veth2Name),
-		)
-	} else {
-		removedKeys = append(removedKeys, linux_intf.InterfaceKey(config.PodTapName))
-	}
+	if config.MemifSocket == "" {
+		if !s.useTAPInterfaces {
+			removedKeys = append(removedKeys,
+				linux_intf.InterfaceKey(config.Veth1Name),
+				linux_intf.InterfaceKey(config.Veth2Name),
+			)
+		} else {
+			removedKeys = append(removedKeys, linux_intf.InterfaceKey(config.PodTapName))
+		}
 
-	removedKeys = append(removedKeys, linux_l3.StaticRouteKey(config.PodLinkRouteName),
-		linux_l3.StaticRouteKey(config.PodDefaultRouteName),
-		linux_l3.StaticArpKey(config.PodARPEntryName))
+		removedKeys = append(removedKeys, linux_l3.StaticRouteKey(config.PodLinkRouteName),
+			linux_l3.StaticRouteKey(config.PodDefaultRouteName),
+			linux_l3.StaticArpKey(config.PodARPEntryName))
+	}
 
 	// VPP-side configuration
-	if !s.disableTCPstack {
+	if s.podUsesTCPStack(config.MemifSocket, config.Tenant) {
 		removedKeys = append(removedKeys,
 			vpp_intf.InterfaceKey(config.LoopbackName),
 			stn.Key(config.StnRuleName),
@@ -1351,6 +2054,27 @@ func (s *remoteCNIserver) deletePersistedPodConfig(config *container.Persisted)
 	}
 	removedKeys = append(removedKeys, vpp_l3.ArpEntryKey(config.VppARPEntryInterface, config.VppARPEntryIP))
 
+	// IPv6 configuration
+	if config.PodLinkRouteIPv6Name != "" {
+		removedKeys = append(removedKeys, linux_l3.StaticRouteKey(config.PodLinkRouteIPv6Name))
+	}
+	if config.PodDefaultRouteIPv6Name != "" {
+		removedKeys = append(removedKeys, linux_l3.StaticRouteKey(config.PodDefaultRouteIPv6Name))
+	}
+	if config.PodARPEntryIPv6Name != "" {
+		removedKeys = append(removedKeys, linux_l3.StaticArpKey(config.PodARPEntryIPv6Name))
+	}
+	if config.VppRouteIPv6Dest != "" {
+		removedKeys = append(removedKeys,
+			vpp_l3.RouteKey(config.VppRouteVrf, config.VppRouteIPv6Dest, config.VppRouteNextHop))
+	}
+	if config.VppARPEntryIPv6 != "" {
+		removedKeys = append(removedKeys, vpp_l3.ArpEntryKey(config.VppARPEntryInterface, config.VppARPEntryIPv6))
+	}
+
+	// secondary networks configuration
+	removedKeys = append(removedKeys, s.secondaryIfsRemovedKeys(config)...)
+
 	_, skip := s.configuredInThisRun[config.ID]
 
 	// remove persisted configuration from ETCD
@@ -1363,6 +2087,87 @@ func (s *remoteCNIserver) deletePersistedPodConfig(config *container.Persisted)
 	return nil
 }
 
+// assignPodIP assigns an IPv4 address to the POD. The address is either requested by the CNI extra arguments
+// or the POD annotations (explicitly or by the name of a reservation from the IPAM config), the address previously assigned to a POD
+// with the same name (if sticky POD IPs are enabled and the POD is sticky), or the next free address from the pool.
+// PODs of tenants are assigned the next free address from the pool of the tenant.
+func (s *remoteCNIserver) assignPodIP(id string, config *PodConfig, extraArgs map[string]string) (net.IP, error) {
+	var (
+		requestedIP net.IP
+		err         error
+	)
+	ipArg, ipRequested := extraArgs[podIPExtraArg]
+	reservation, reservationRequested := extraArgs[podIPReservationExtraArg]
+
+	if config.Tenant != "" {
+		if ipRequested || reservationRequested {
+			return nil, fmt.Errorf("Can't assign IP address to pod: static IP addresses are not supported for the pods of tenants")
+		}
+		podIP, err := s.ipam.NextSecondaryPodIP(config.Tenant, id)
+		if err != nil {
+			return nil, fmt.Errorf("Can't get new IP address for pod of the tenant %v: %v", config.Tenant, err)
+		}
+		return podIP, nil
+	}
+
+	switch {
+	case ipRequested && reservationRequested:
+		return nil, fmt.Errorf("Can't assign IP address to pod: both %v and %v are requested", podIPExtraArg, podIPReservationExtraArg)
+	case ipRequested:
+		requestedIP = net.ParseIP(ipArg)
+		if requestedIP == nil {
+			return nil, fmt.Errorf("Can't assign IP address to pod: invalid IP address %q requested", ipArg)
+		}
+	case reservationRequested:
+		requestedIP, err = s.ipam.ReservedPodIP(reservation)
+		if err != nil {
+			return nil, fmt.Errorf("Can't assign IP address to pod: %v", err)
+		}
+	}
+
+	sticky, err := stickyIPRequested(extraArgs)
+	if err != nil {
+		return nil, fmt.Errorf("Can't assign IP address to pod: %v", err)
+	}
+	podName := ""
+	if sticky && config.PodName != "" && s.ipam.StickyPodIPsEnabled() {
+		podName = config.PodNamespace + "/" + config.PodName
+	}
+
+	var podIP net.IP
+	if requestedIP != nil {
+		// statically requested IP, conflicts are reported back as CNI errors
+		err = s.ipam.AssignPodIP(id, requestedIP)
+		if err != nil {
+			return nil, fmt.Errorf("Can't assign requested IP address to pod: %v", err)
+		}
+		podIP = requestedIP.To4()
+	} else if stickyIP := s.ipam.StickyPodIP(podName); podName != "" && stickyIP != nil {
+		// previous IP of the pod, held out of the pool unless the pool is exhausted
+		if err = s.ipam.AssignPodIP(id, stickyIP); err != nil {
+			s.Logger.Warnf("Can't re-assign previous IP address %v to pod %v: %v", stickyIP, podName, err)
+		} else {
+			s.Logger.Infof("Re-assigned previous IP address %v to pod %v", stickyIP, podName)
+			podIP = stickyIP
+		}
+	}
+	if podIP == nil {
+		podIP, err = s.ipam.NextPodIP(id)
+		if err != nil {
+			return nil, fmt.Errorf("Can't get new IP address for pod: %v", err)
+		}
+	}
+
+	if podName != "" {
+		err = s.ipam.SetStickyPodIP(podName, podIP)
+		if err != nil {
+			s.ipam.ReleasePodIP(id)
+			return nil, err
+		}
+	}
+	return podIP, nil
+}
+
 // parseCniExtraArgs parses CNI extra arguments from a string into a map.
 func (s *remoteCNIserver) parseCniExtraArgs(input string) map[string]string {
 	res := map[string]string{}
@@ -1378,8 +2183,9 @@ func (s *remoteCNIserver) parseCniExtraArgs(input string) map[string]string {
 }
 
 // generateCniReply fills the CNI reply with the data of an interface.
-func (s *remoteCNIserver) generateCniReply(config *PodConfig, nsName string, podIP string) *cni.CNIReply {
-	return &cni.CNIReply{
+// If podIPv6 is not nil, the IPv6 address and default route are included as well.
+func (s *remoteCNIserver) generateCniReply(config *PodConfig, nsName string, podIP string, podIPv6 net.IP) *cni.CNIReply {
+	reply := &cni.CNIReply{
 		Result: resultOk,
 		Interfaces: []*cni.CNIReply_Interface{
 			{
@@ -1389,18 +2195,33 @@ func (s *remoteCNIserver) generateCniReply(config *PodConfig, nsName string, pod
 					{
 						Version: cni.CNIReply_Interface_IP_IPV4,
 						Address: podIP,
-						Gateway: s.ipam.PodGatewayIP().String(),
+						Gateway: s.podGatewayIP(config.Tenant).String(),
 					},
 				},
 			},
 		},
 		Routes: []*cni.CNIReply_Route{
 			{
-				Dst: "0.0.0.0/0",
-				Gw:  s.ipam.PodGatewayIP().String(),
+				Dst: ipv4DefaultRoute,
+				Gw:  s.podGatewayIP(config.Tenant).String(),
 			},
 		},
 	}
+	if podIPv6 != nil {
+		reply.Interfaces[0].IpAddresses = append(reply.Interfaces[0].IpAddresses, &cni.CNIReply_Interface_IP{
+			Version: cni.CNIReply_Interface_IP_IPV6,
+			Address: ipWithFullPrefix(podIPv6),
+			Gateway: s.ipam.PodGatewayIPv6().String(),
+		})
+		reply.Routes = append(reply.Routes, &cni.CNIReply_Route{
+			Dst: ipv6DefaultRoute,
+			Gw:  s.ipam.PodGatewayIPv6().String(),
+		})
+	}
+	secondaryIfs, secondaryRoutes := s.secondaryIfsReply(config, nsName)
+	reply.Interfaces = append(reply.Interfaces, secondaryIfs...)
+	reply.Routes = append(reply.Routes, secondaryRoutes...)
+	return reply
 }
 
 // generateCniEmptyOKReply generates CNI reply with OK result code and ampty body.
@@ -1469,16 +2290,22 @@ func (s *remoteCNIserver) GetOtherPhysicalIfNames() []string {
 }
 
 // GetVxlanBVIIfName returns the name of an BVI interface facing towards VXLAN tunnels to other hosts.
-// Returns an empty string if VXLAN is not used (in L2 interconnect mode).
+// Returns an empty string if VXLAN is not used (L2 or IP-in-IP overlay).
 func (s *remoteCNIserver) GetVxlanBVIIfName() string {
 	s.Lock()
 	defer s.Unlock()
 
-	if s.useL2Interconnect {
-		return ""
-	}
+	return s.overlay.bviIfName()
+}
 
-	return s.vxlanBVIIfName
+// GetOverlayTunnelIfNames returns the names of the tunnels towards the other nodes which terminate the traffic
+// of the overlay themselves (IP-in-IP). Returns an empty slice if the traffic is terminated by the VXLAN BVI
+// or if no tunnels are used (L2 overlay).
+func (s *remoteCNIserver) GetOverlayTunnelIfNames() []string {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.overlay.tunnelIfNames()
 }
 
 // GetHostInterconnectIfName returns the name of the TAP/AF_PACKET interface
@@ -1539,6 +2366,11 @@ func (s *remoteCNIserver) setNodeIP(nodeIP string) error {
 		}
 	}
 
+	// IPsec is configured once the node IP is known
+	if err := s.configureIPSec(); err != nil {
+		s.Logger.Error(err)
+	}
+
 	return nil
 }
 
@@ -1550,3 +2382,9 @@ func (s *remoteCNIserver) GetDefaultGatewayIP() net.IP {
 
 	return s.defaultGw
 }
+
+// ExecuteVppCLI executes a VPP CLI command and returns its output.
+// The server lock is not needed, the CLI executor serializes the requests itself.
+func (s *remoteCNIserver) ExecuteVppCLI(cmd string) (string, error) {
+	return s.cli.cliOutput(cmd)
+}
@@ -16,11 +16,17 @@ package contiv
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"git.fd.io/govpp.git/adapter/mock"
 	govppmock "git.fd.io/govpp.git/adapter/mock"
@@ -28,10 +34,16 @@ import (
 	"git.fd.io/govpp.git/api"
 	govpp "git.fd.io/govpp.git/core"
 
+	"github.com/contiv/vpp/mock/broker"
 	"github.com/contiv/vpp/mock/localclient"
 	"github.com/contiv/vpp/plugins/contiv/containeridx"
+	ipamModel "github.com/contiv/vpp/plugins/contiv/ipam/model"
 	"github.com/contiv/vpp/plugins/contiv/model/cni"
+	"github.com/contiv/vpp/plugins/contiv/model/egress"
 	"github.com/contiv/vpp/plugins/contiv/model/node"
+	"github.com/contiv/vpp/plugins/contiv/model/nodeconfig"
+	nsmodel "github.com/contiv/vpp/plugins/ksr/model/namespace"
+	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
 	"github.com/contiv/vpp/plugins/kvdbproxy"
 	"github.com/golang/protobuf/proto"
 
@@ -46,6 +58,7 @@ import (
 	"github.com/ligato/vpp-agent/plugins/defaultplugins/common/bin_api/tap"
 	"github.com/ligato/vpp-agent/plugins/defaultplugins/common/bin_api/vpe"
 	"github.com/ligato/vpp-agent/plugins/defaultplugins/common/bin_api/vxlan"
+	vpp_bfd "github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/bfd"
 	vpp_intf "github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/interfaces"
 	vpp_l3 "github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/l3"
 	"github.com/ligato/vpp-agent/plugins/defaultplugins/ifplugin/ifaceidx"
@@ -98,6 +111,96 @@ var (
 			VxlanCIDR:               "192.168.30.0/24",
 		},
 	}
+	configTapIPIPTCP = Config{
+		UseTAPInterfaces:    true,
+		TAPInterfaceVersion: 2,
+		Overlay:             IPIPOverlay,
+		IPAMConfig: ipam.Config{
+			PodSubnetCIDR:           "10.1.0.0/16",
+			PodNetworkPrefixLen:     24,
+			PodIfIPCIDR:             "10.2.1.0/24",
+			VPPHostSubnetCIDR:       "172.30.0.0/16",
+			VPPHostNetworkPrefixLen: 24,
+			NodeInterconnectCIDR:    "192.168.16.0/24",
+			VxlanCIDR:               "192.168.30.0/24",
+		},
+	}
+	configVethL2NoTCPDualStack = Config{
+		TCPstackDisabled:  true,
+		UseL2Interconnect: true,
+		IPAMConfig: ipam.Config{
+			PodSubnetCIDR:               "10.1.0.0/16",
+			PodNetworkPrefixLen:         24,
+			PodIfIPCIDR:                 "10.2.1.0/24",
+			VPPHostSubnetCIDR:           "172.30.0.0/16",
+			VPPHostNetworkPrefixLen:     24,
+			NodeInterconnectCIDR:        "192.168.16.0/24",
+			VxlanCIDR:                   "192.168.30.0/24",
+			PodSubnetIPv6CIDR:           "fd00:1::/48",
+			PodNetworkIPv6PrefixLen:     64,
+			PodIfIPv6CIDR:               "fd00:2::/64",
+			VPPHostSubnetIPv6CIDR:       "fd00:3::/48",
+			VPPHostNetworkIPv6PrefixLen: 64,
+			NodeInterconnectIPv6CIDR:    "fd00:4::/64",
+			VxlanIPv6CIDR:               "fd00:5::/64",
+		},
+	}
+	configVethL2NoTCPSecondaryNet = Config{
+		TCPstackDisabled:  true,
+		UseL2Interconnect: true,
+		IPAMConfig: ipam.Config{
+			PodSubnetCIDR:           "10.1.0.0/16",
+			PodNetworkPrefixLen:     24,
+			PodIfIPCIDR:             "10.2.1.0/24",
+			VPPHostSubnetCIDR:       "172.30.0.0/16",
+			VPPHostNetworkPrefixLen: 24,
+			NodeInterconnectCIDR:    "192.168.16.0/24",
+			VxlanCIDR:               "192.168.30.0/24",
+			SecondaryNetworks: []ipam.SecondaryNetworkConfig{
+				{Name: "data", SubnetCIDR: "10.10.0.0/16", NetworkPrefixLen: 24},
+			},
+		},
+		SecondaryNetworks: []SecondaryNetworkConfig{
+			{Name: "data", VrfID: 10},
+		},
+	}
+	configTapVxlanTenants = Config{
+		UseTAPInterfaces:    true,
+		TAPInterfaceVersion: 2,
+		IPAMConfig: ipam.Config{
+			PodSubnetCIDR:           "10.1.0.0/16",
+			PodNetworkPrefixLen:     24,
+			PodIfIPCIDR:             "10.2.1.0/24",
+			VPPHostSubnetCIDR:       "172.30.0.0/16",
+			VPPHostNetworkPrefixLen: 24,
+			NodeInterconnectCIDR:    "192.168.16.0/24",
+			VxlanCIDR:               "192.168.30.0/24",
+			SecondaryNetworks: []ipam.SecondaryNetworkConfig{
+				{Name: "tenantA", SubnetCIDR: "10.3.0.0/16", NetworkPrefixLen: 24},
+				{Name: "tenantB", SubnetCIDR: "10.1.0.0/16", NetworkPrefixLen: 24},
+			},
+		},
+		Tenants: []TenantConfig{
+			{Name: "tenantA", VrfID: 10, Vni: 20, SharedPrefixes: []string{"10.1.1.53/32"}},
+			{Name: "tenantB", VrfID: 11, Vni: 21},
+		},
+	}
+	configTapVxlanEgress = Config{
+		UseTAPInterfaces:    true,
+		TAPInterfaceVersion: 2,
+		IPAMConfig: ipam.Config{
+			PodSubnetCIDR:           "10.1.0.0/16",
+			PodNetworkPrefixLen:     24,
+			PodIfIPCIDR:             "10.2.1.0/24",
+			VPPHostSubnetCIDR:       "172.30.0.0/16",
+			VPPHostNetworkPrefixLen: 24,
+			NodeInterconnectCIDR:    "192.168.16.0/24",
+			VxlanCIDR:               "192.168.30.0/24",
+		},
+		Egress: EgressConfig{
+			IPPool: "80.80.80.0/30",
+		},
+	}
 	nodeConfig = OneNodeConfig{
 		NodeName: "test-node",
 		Gateway:  "192.168.1.100",
@@ -112,6 +215,13 @@ var (
 			},
 		},
 	}
+	nodeUplinksConfig = OneNodeConfig{
+		NodeName: "test-node",
+		Uplinks: []UplinkConfig{
+			{InterfaceName: "GigabitEthernet0/0/0/1", IP: "10.10.1.2/30", Gateway: "10.10.1.1"},
+			{InterfaceName: "GigabitEthernet0/0/0/2", IP: "10.10.2.2/30", Gateway: "10.10.2.1"},
+		},
+	}
 	nodeDHCPConfig = OneNodeConfig{
 		NodeName: "test-node",
 		MainVPPInterface: InterfaceWithIP{
@@ -134,6 +244,11 @@ var (
 )
 
 func setupTestCNIServer(config *Config, nodeConfig *OneNodeConfig, existingInterfaces ...string) (*remoteCNIserver, *localclient.TxnTracker, *containeridx.ConfigIndex, *govpp.Connection) {
+	return setupTestCNIServerWithBlockStore(config, nodeConfig, nil, existingInterfaces...)
+}
+
+func setupTestCNIServerWithBlockStore(config *Config, nodeConfig *OneNodeConfig, blockStore ipam.PodCIDRBlockStore,
+	existingInterfaces ...string) (*remoteCNIserver, *localclient.TxnTracker, *containeridx.ConfigIndex, *govpp.Connection) {
 	swIfIdx := swIfIndexMock()
 	// add existing interfaces into swIfIndex
 	for i, intf := range existingInterfaces {
@@ -156,9 +271,13 @@ func setupTestCNIServer(config *Config, nodeConfig *OneNodeConfig, existingInter
 		config,
 		nodeConfig,
 		1,
-		nil)
+		nil,
+		blockStore,
+		newEgressIPStoreMock())
 	server.test = true
 	gomega.Expect(err).To(gomega.BeNil())
+	// KSR does not run in the tests, the CNI requests do not wait for the pods to be reflected
+	server.podReflectionTimeout = 0
 
 	return server, txns, configuredContainers, vppMockConn
 }
@@ -194,6 +313,794 @@ func TestAddDelVeth(t *testing.T) {
 	gomega.Expect(reply).NotTo(gomega.BeNil())
 }
 
+func TestAddDelVethDualStack(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	server, txns, configuredContainers, conn := setupTestCNIServer(&configVethL2NoTCPDualStack, nil)
+	defer conn.Disconnect()
+	podIPv6Broker := &broker.MockBroker{}
+	server.podIPv6Broker = podIPv6Broker
+
+	// pretend that connectivity is configured to unblock CNI requests
+	server.vswitchConnectivityConfigured = true
+
+	// CNI Add
+	reply, err := server.Add(context.Background(), &req)
+
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(reply).NotTo(gomega.BeNil())
+	gomega.Expect(reply.Interfaces).To(gomega.HaveLen(1))
+	gomega.Expect(reply.Interfaces[0].IpAddresses).To(gomega.HaveLen(2))
+	gomega.Expect(reply.Interfaces[0].IpAddresses[0].Version).To(gomega.BeEquivalentTo(cni.CNIReply_Interface_IP_IPV4))
+	gomega.Expect(reply.Interfaces[0].IpAddresses[1].Version).To(gomega.BeEquivalentTo(cni.CNIReply_Interface_IP_IPV6))
+	gomega.Expect(reply.Interfaces[0].IpAddresses[1].Address).To(gomega.BeEquivalentTo("fd00:1:0:1::2/128"))
+	gomega.Expect(reply.Routes).To(gomega.HaveLen(2))
+	gomega.Expect(reply.Routes[1].Dst).To(gomega.BeEquivalentTo("::/0"))
+
+	gomega.Expect(len(txns.PendingTxns)).To(gomega.BeEquivalentTo(2)) // not applied reverts
+	gomega.Expect(len(txns.CommittedTxns)).To(gomega.BeEquivalentTo(3))
+
+	config, found := configuredContainers.LookupContainer(containerID)
+	gomega.Expect(found).To(gomega.BeTrue())
+	gomega.Expect(config.VppARPEntryIPv6).To(gomega.BeEquivalentTo("fd00:1:0:1::2"))
+	gomega.Expect(config.VppRouteIPv6Dest).To(gomega.BeEquivalentTo("fd00:1:0:1::2/128"))
+	gomega.Expect(config.PodDefaultRouteIPv6Name).NotTo(gomega.BeEmpty())
+
+	// the IPv6 address is reflected for the policies
+	podIPv6 := &podmodel.Pod{}
+	found, _, err = podIPv6Broker.GetValue(podmodel.IPv6Key(podName, podNamespace), podIPv6)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(found).To(gomega.BeTrue())
+	gomega.Expect(podIPv6.Ipv6Address).To(gomega.BeEquivalentTo("fd00:1:0:1::2"))
+
+	txns.Clear()
+
+	// CNI Delete
+	reply, err = server.Delete(context.Background(), &req)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(reply).NotTo(gomega.BeNil())
+	gomega.Expect(podIPv6Broker.Data).To(gomega.BeEmpty())
+}
+
+func TestCheck(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	server, _, _, conn := setupTestCNIServer(&configVethL2NoTCP, nil)
+	defer conn.Disconnect()
+
+	// pretend that connectivity is configured to unblock CNI requests
+	server.vswitchConnectivityConfigured = true
+
+	// CNI Check of a container that was not added
+	reply, err := server.Check(context.Background(), &req)
+	gomega.Expect(err).NotTo(gomega.BeNil())
+	gomega.Expect(reply.Result).To(gomega.BeEquivalentTo(resultErr))
+
+	// CNI Check reports the same addresses as CNI Add
+	addReply, err := server.Add(context.Background(), &req)
+	gomega.Expect(err).To(gomega.BeNil())
+	reply, err = server.Check(context.Background(), &req)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(reply.Result).To(gomega.BeEquivalentTo(resultOk))
+	gomega.Expect(reply.Interfaces).To(gomega.HaveLen(1))
+	gomega.Expect(reply.Interfaces[0].Name).To(gomega.BeEquivalentTo(addReply.Interfaces[0].Name))
+	gomega.Expect(reply.Interfaces[0].IpAddresses[0].Address).To(gomega.BeEquivalentTo(addReply.Interfaces[0].IpAddresses[0].Address))
+
+	// CNI Check fails once the pod IP is released
+	gomega.Expect(server.ipam.ReleasePodIP(containerID)).To(gomega.Succeed())
+	reply, err = server.Check(context.Background(), &req)
+	gomega.Expect(err).NotTo(gomega.BeNil())
+	gomega.Expect(reply.Result).To(gomega.BeEquivalentTo(resultErr))
+}
+
+func TestAddStaticIP(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	server, _, _, conn := setupTestCNIServer(&configVethL2NoTCP, nil)
+	defer conn.Disconnect()
+
+	// pretend that connectivity is configured to unblock CNI requests
+	server.vswitchConnectivityConfigured = true
+
+	// CNI Add requesting a static IP
+	staticReq := req
+	staticReq.ExtraArguments += ";IP=10.1.1.10"
+	reply, err := server.Add(context.Background(), &staticReq)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(reply.Interfaces).To(gomega.HaveLen(1))
+	gomega.Expect(reply.Interfaces[0].IpAddresses[0].Address).To(gomega.BeEquivalentTo("10.1.1.10/32"))
+
+	// another pod requesting the same IP is rejected
+	conflictReq := staticReq
+	conflictReq.ContainerId = "conflictingContainer"
+	reply, err = server.Add(context.Background(), &conflictReq)
+	gomega.Expect(err).NotTo(gomega.BeNil())
+	gomega.Expect(reply.Result).To(gomega.BeEquivalentTo(resultErr))
+	gomega.Expect(reply.Error).To(gomega.ContainSubstring("already assigned"))
+
+	// IP from outside of the pod network is rejected
+	outsideReq := req
+	outsideReq.ContainerId = "outsideContainer"
+	outsideReq.ExtraArguments += ";IP=10.2.0.10"
+	reply, err = server.Add(context.Background(), &outsideReq)
+	gomega.Expect(err).NotTo(gomega.BeNil())
+	gomega.Expect(reply.Result).To(gomega.BeEquivalentTo(resultErr))
+
+	// CNI Delete
+	reply, err = server.Delete(context.Background(), &staticReq)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(reply).NotTo(gomega.BeNil())
+}
+
+func TestPodArgsFromAnnotations(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	server, _, _, conn := setupTestCNIServer(&configVethL2NoTCP, nil)
+	defer conn.Disconnect()
+
+	// pretend that connectivity is configured to unblock CNI requests
+	server.vswitchConnectivityConfigured = true
+
+	// the static IP is requested by the pod annotation reflected by KSR
+	pod := &podmodel.Pod{Name: podName, Namespace: podNamespace, Annotation: []*podmodel.Pod_Annotation{
+		{Key: podIPAnnotation, Value: "10.1.1.20"},
+		{Key: "unrelated", Value: "value"},
+	}}
+	server.resyncLivePods([]*podmodel.Pod{pod})
+	server.updatePodArgs(pod)
+	reply, err := server.Add(context.Background(), &req)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(reply.Interfaces[0].IpAddresses[0].Address).To(gomega.BeEquivalentTo("10.1.1.20/32"))
+	reply, err = server.Delete(context.Background(), &req)
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// the CNI extra arguments take precedence over the annotations
+	argsReq := req
+	argsReq.ExtraArguments += ";IP=10.1.1.30"
+	reply, err = server.Add(context.Background(), &argsReq)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(reply.Interfaces[0].IpAddresses[0].Address).To(gomega.BeEquivalentTo("10.1.1.30/32"))
+	reply, err = server.Delete(context.Background(), &argsReq)
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// the request is removed together with the pod
+	server.deletePodArgs(podmodel.GetID(pod))
+	reply, err = server.Add(context.Background(), &req)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(reply.Interfaces[0].IpAddresses[0].Address).NotTo(gomega.BeEquivalentTo("10.1.1.20/32"))
+}
+
+func TestStickyPodIPs(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	config := configVethL2NoTCP
+	config.IPAMConfig.StickyPodIPs = true
+	server, _, _, conn := setupTestCNIServer(&config, nil)
+	defer conn.Disconnect()
+
+	// pretend that connectivity is configured to unblock CNI requests
+	server.vswitchConnectivityConfigured = true
+
+	// the IP address of an ordinary pod is not sticky
+	pod := &podmodel.Pod{Name: podName, Namespace: podNamespace}
+	server.resyncPodArgs([]*podmodel.Pod{pod})
+	_, err := server.Add(context.Background(), &req)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(server.ipam.StickyPodIP(podmodel.GetID(pod).String())).To(gomega.BeNil())
+	_, err = server.Delete(context.Background(), &req)
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// the pods of StatefulSets are sticky
+	pod.Label = []*podmodel.Pod_Label{{Key: statefulSetPodLabel, Value: podName}}
+	server.updatePodArgs(pod)
+	reply, err := server.Add(context.Background(), &req)
+	gomega.Expect(err).To(gomega.BeNil())
+	podIP := strings.TrimSuffix(reply.Interfaces[0].IpAddresses[0].Address, "/32")
+	gomega.Expect(server.ipam.StickyPodIP(podmodel.GetID(pod).String()).String()).To(gomega.BeEquivalentTo(podIP))
+	_, err = server.Delete(context.Background(), &req)
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// the pods may opt in by the annotation
+	annotatedPod := &podmodel.Pod{Name: "web", Namespace: podNamespace, Annotation: []*podmodel.Pod_Annotation{
+		{Key: podStickyIPAnnotation, Value: "true"},
+	}}
+	server.updatePodArgs(annotatedPod)
+	annotatedReq := req
+	annotatedReq.ContainerId = "annotatedContainer"
+	annotatedReq.ExtraArguments = "K8S_POD_NAMESPACE=" + podNamespace + ";K8S_POD_NAME=web"
+	_, err = server.Add(context.Background(), &annotatedReq)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(server.ipam.StickyPodIP(podmodel.GetID(annotatedPod).String())).NotTo(gomega.BeNil())
+	_, err = server.Delete(context.Background(), &annotatedReq)
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// the release is cancelled when the pod is re-created within the hold time
+	server.stickyPodIPHoldTime = time.Hour
+	server.deletePodArgs(podmodel.GetID(pod))
+	gomega.Expect(server.stickyIPReleases).To(gomega.HaveKey(podmodel.GetID(pod)))
+	server.updatePodArgs(pod)
+	gomega.Expect(server.stickyIPReleases).To(gomega.BeEmpty())
+	gomega.Expect(server.ipam.StickyPodIP(podmodel.GetID(pod).String())).NotTo(gomega.BeNil())
+
+	// the sticky IP addresses are released after the hold time once the pods are deleted
+	server.stickyPodIPHoldTime = 10 * time.Millisecond
+	server.deletePodArgs(podmodel.GetID(pod))
+	server.resyncPodArgs(nil)
+	gomega.Eventually(func() []string {
+		server.Lock()
+		defer server.Unlock()
+		return server.ipam.StickyPods()
+	}).Should(gomega.BeEmpty())
+}
+
+func TestWaitForPodReflection(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	server, _, _, conn := setupTestCNIServer(&configVethL2NoTCP, nil)
+	defer conn.Disconnect()
+
+	// pretend that connectivity is configured to unblock CNI requests
+	server.vswitchConnectivityConfigured = true
+
+	// the request fails if the pod is not reflected by KSR in time
+	server.podReflectionTimeout = 10 * time.Millisecond
+	server.resyncLivePods(nil)
+	reply, err := server.Add(context.Background(), &req)
+	gomega.Expect(err).NotTo(gomega.BeNil())
+	gomega.Expect(reply.Result).To(gomega.BeEquivalentTo(resultErr))
+
+	// the request waits for the pod and applies its annotations
+	server.podReflectionTimeout = 10 * time.Second
+	pod := &podmodel.Pod{Name: podName, Namespace: podNamespace, Annotation: []*podmodel.Pod_Annotation{
+		{Key: podIPAnnotation, Value: "10.1.1.20"},
+	}}
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		server.updatePodArgs(pod)
+		server.updateLivePod(podmodel.GetID(pod))
+	}()
+	reply, err = server.Add(context.Background(), &req)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(reply.Interfaces[0].IpAddresses[0].Address).To(gomega.BeEquivalentTo("10.1.1.20/32"))
+}
+
+func TestAddDelSecondaryNetwork(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	server, _, configuredContainers, conn := setupTestCNIServer(&configVethL2NoTCPSecondaryNet, nil)
+	defer conn.Disconnect()
+
+	// pretend that connectivity is configured to unblock CNI requests
+	server.vswitchConnectivityConfigured = true
+
+	// CNI Add attaching the pod also to the secondary network
+	netReq := req
+	netReq.ExtraArguments += ";NETWORKS=data"
+	reply, err := server.Add(context.Background(), &netReq)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(reply.Interfaces).To(gomega.HaveLen(2))
+	gomega.Expect(reply.Interfaces[1].IpAddresses[0].Address).To(gomega.BeEquivalentTo("10.10.1.2/32"))
+	gomega.Expect(reply.Interfaces[1].IpAddresses[0].Gateway).To(gomega.BeEquivalentTo("10.10.1.1"))
+	gomega.Expect(reply.Routes).To(gomega.HaveLen(2))
+	gomega.Expect(reply.Routes[1].Dst).To(gomega.BeEquivalentTo("10.10.0.0/16"))
+
+	config, found := configuredContainers.LookupContainer(containerID)
+	gomega.Expect(found).To(gomega.BeTrue())
+	gomega.Expect(config.SecondaryInterfaces).To(gomega.HaveLen(1))
+	gomega.Expect(config.SecondaryInterfaces[0].Network).To(gomega.BeEquivalentTo("data"))
+	gomega.Expect(config.SecondaryInterfaces[0].IfName).To(gomega.BeEquivalentTo("net1"))
+	gomega.Expect(config.SecondaryInterfaces[0].VppRouteVrf).To(gomega.BeEquivalentTo(10))
+	gomega.Expect(configuredContainers.LookupPodIf(config.SecondaryInterfaces[0].VppIfName)).To(gomega.ContainElement(containerID))
+
+	// unknown network is rejected
+	unknownReq := req
+	unknownReq.ContainerId = "unknownNetContainer"
+	unknownReq.ExtraArguments += ";NETWORKS=unknown"
+	reply, err = server.Add(context.Background(), &unknownReq)
+	gomega.Expect(err).NotTo(gomega.BeNil())
+	gomega.Expect(reply.Result).To(gomega.BeEquivalentTo(resultErr))
+
+	// CNI Delete
+	reply, err = server.Delete(context.Background(), &netReq)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(reply).NotTo(gomega.BeNil())
+	_, err = server.ipam.NextSecondaryPodIP("data", "otherContainer")
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// the networks requested by the pod annotation
+	server.updatePodArgs(&podmodel.Pod{Name: podName, Namespace: podNamespace, Annotation: []*podmodel.Pod_Annotation{
+		{Key: podNetworksAnnotation, Value: "data"},
+	}})
+	reply, err = server.Add(context.Background(), &req)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(reply.Interfaces).To(gomega.HaveLen(2))
+}
+
+func TestAddDelMemif(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	socketDir, err := ioutil.TempDir("", "memif")
+	gomega.Expect(err).To(gomega.BeNil())
+	defer os.RemoveAll(socketDir)
+	config := configVethL2NoTCP
+	config.MemifSocketDir = socketDir
+
+	server, txns, configuredContainers, conn := setupTestCNIServer(&config, nil)
+	defer conn.Disconnect()
+
+	// pretend that connectivity is configured to unblock CNI requests
+	server.vswitchConnectivityConfigured = true
+
+	// CNI Add requesting memif
+	memifReq := req
+	memifReq.ExtraArguments += ";INTERFACE_TYPE=memif"
+	reply, err := server.Add(context.Background(), &memifReq)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(reply.Interfaces).To(gomega.HaveLen(1))
+
+	persisted, found := configuredContainers.LookupContainer(containerID)
+	gomega.Expect(found).To(gomega.BeTrue())
+	socket := filepath.Join(socketDir, podNamespace, podName, memifSocketName)
+	gomega.Expect(persisted.MemifSocket).To(gomega.BeEquivalentTo(socket))
+	gomega.Expect(persisted.Veth1Name).To(gomega.BeEmpty())
+	gomega.Expect(reply.Interfaces[0].Name).To(gomega.BeEquivalentTo(persisted.VppIfName))
+
+	memifIf := interfaceInSnapshot(txns.AppliedConfig, persisted.VppIfName)
+	gomega.Expect(memifIf).NotTo(gomega.BeNil())
+	gomega.Expect(memifIf.Type).To(gomega.BeEquivalentTo(vpp_intf.InterfaceType_MEMORY_INTERFACE))
+	gomega.Expect(memifIf.Memif.SocketFilename).To(gomega.BeEquivalentTo(socket))
+
+	// the memif info is available to the pod next to the socket
+	infoJSON, err := ioutil.ReadFile(filepath.Join(filepath.Dir(socket), memifInfoName))
+	gomega.Expect(err).To(gomega.BeNil())
+	info := &MemifInfo{}
+	gomega.Expect(json.Unmarshal(infoJSON, info)).To(gomega.Succeed())
+	gomega.Expect(info.Socket).To(gomega.BeEquivalentTo(filepath.Join(defaultMemifPodDir, memifSocketName)))
+	gomega.Expect(info.ID).To(gomega.BeEquivalentTo(memifIf.Memif.Id))
+	gomega.Expect(info.IP).To(gomega.BeEquivalentTo(reply.Interfaces[0].IpAddresses[0].Address))
+	gomega.Expect(info.Gateway).To(gomega.BeEquivalentTo(reply.Interfaces[0].IpAddresses[0].Gateway))
+
+	// unsupported interface type is rejected
+	unsupportedReq := req
+	unsupportedReq.ContainerId = "unsupportedContainer"
+	unsupportedReq.ExtraArguments += ";INTERFACE_TYPE=vhost-user"
+	reply, err = server.Add(context.Background(), &unsupportedReq)
+	gomega.Expect(err).NotTo(gomega.BeNil())
+	gomega.Expect(reply.Result).To(gomega.BeEquivalentTo(resultErr))
+
+	// CNI Delete
+	reply, err = server.Delete(context.Background(), &memifReq)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(reply).NotTo(gomega.BeNil())
+	gomega.Expect(interfaceInSnapshot(txns.AppliedConfig, persisted.VppIfName)).To(gomega.BeNil())
+	_, err = os.Stat(filepath.Dir(socket))
+	gomega.Expect(os.IsNotExist(err)).To(gomega.BeTrue())
+
+	// memif requested by the pod annotation
+	server.updatePodArgs(&podmodel.Pod{Name: podName, Namespace: podNamespace, Annotation: []*podmodel.Pod_Annotation{
+		{Key: podIfTypeAnnotation, Value: memifPodIfType},
+	}})
+	_, err = server.Add(context.Background(), &req)
+	gomega.Expect(err).To(gomega.BeNil())
+	persisted, found = configuredContainers.LookupContainer(containerID)
+	gomega.Expect(found).To(gomega.BeTrue())
+	gomega.Expect(persisted.MemifSocket).To(gomega.BeEquivalentTo(socket))
+}
+
+// bandwidthLimiterMock records the bandwidth limits instead of configuring VPP.
+type bandwidthLimiterMock struct {
+	limits  map[string]podBandwidth // container ID -> limits
+	ifs     map[string]string       // container ID -> VPP interface
+	updates int                     // number of interface updates
+}
+
+func (m *bandwidthLimiterMock) setLimits(containerID string, vppIfName string, podIPs []net.IP, bandwidth podBandwidth) error {
+	if bandwidth == (podBandwidth{}) {
+		return m.removeLimits(containerID)
+	}
+	m.limits[containerID] = bandwidth
+	m.ifs[containerID] = vppIfName
+	return nil
+}
+
+func (m *bandwidthLimiterMock) removeLimits(containerID string) error {
+	delete(m.limits, containerID)
+	delete(m.ifs, containerID)
+	return nil
+}
+
+func (m *bandwidthLimiterMock) updateInterfaces() error {
+	m.updates++
+	return nil
+}
+
+func podWithBandwidth(ingress, egress string) *podmodel.Pod {
+	pod := &podmodel.Pod{Name: podName, Namespace: podNamespace}
+	if ingress != "" {
+		pod.Annotation = append(pod.Annotation, &podmodel.Pod_Annotation{Key: ingressBandwidthAnnotation, Value: ingress})
+	}
+	if egress != "" {
+		pod.Annotation = append(pod.Annotation, &podmodel.Pod_Annotation{Key: egressBandwidthAnnotation, Value: egress})
+	}
+	return pod
+}
+
+// cliMock records the VPP CLI commands instead of executing them.
+type cliMock struct {
+	cmds []string
+
+	// outputs maps prefixes of the commands to their output
+	outputs map[string]string
+
+	// ifNames are the VPP internal names of the interfaces
+	ifNames []string
+}
+
+func (m *cliMock) cli(cmd string) error {
+	m.cmds = append(m.cmds, cmd)
+	return nil
+}
+
+func (m *cliMock) cliOutput(cmd string) (string, error) {
+	m.cmds = append(m.cmds, cmd)
+	for prefix, output := range m.outputs {
+		if strings.HasPrefix(cmd, prefix) {
+			return output, nil
+		}
+	}
+	return "", nil
+}
+
+func (m *cliMock) internalIfName(vppIfName string) (string, error) {
+	return "if-" + vppIfName, nil
+}
+
+func (m *cliMock) internalIfIndex(ifName string) (uint32, error) {
+	return 42, nil
+}
+
+func (m *cliMock) internalIfNames() ([]string, error) {
+	return m.ifNames, nil
+}
+
+// flush returns the recorded commands and clears the record.
+func (m *cliMock) flush() []string {
+	cmds := m.cmds
+	m.cmds = nil
+	return cmds
+}
+
+func TestIPSecOverlay(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	ip1, ip2 := net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.2")
+	secret1, secret2, secret3 := []byte("secret1"), []byte("secret2"), []byte("secret3")
+	saAdd := func(generation, src, dst uint32) string {
+		return fmt.Sprintf("ipsec sa add %d ", ipsecSPI(generation, src, dst))
+	}
+
+	cli1, cli2 := &cliMock{}, &cliMock{}
+	node1, err := newIPSecOverlay(logrus.DefaultLogger(), cli1, 1, "")
+	gomega.Expect(err).To(gomega.BeNil())
+	node2, err := newIPSecOverlay(logrus.DefaultLogger(), cli2, 2, "transport")
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// nothing is configured until the interface and the node IP are known
+	gomega.Expect(node1.addKey(1, secret1)).To(gomega.Succeed())
+	gomega.Expect(node1.updatePeer(2, ip2, 0)).To(gomega.Succeed())
+	gomega.Expect(cli1.cmds).To(gomega.BeEmpty())
+	gomega.Expect(node1.acceptedGeneration()).To(gomega.BeZero())
+
+	// generation 1 is accepted from the peer, the peer does not accept any generation yet
+	gomega.Expect(node1.configure("GigabitEthernet0/8/0", ip1)).To(gomega.Succeed())
+	cmds := cli1.flush()
+	gomega.Expect(cmds).To(gomega.HaveLen(6))
+	gomega.Expect(cmds[0]).To(gomega.Equal("ipsec spd add 1"))
+	gomega.Expect(cmds[1]).To(gomega.Equal("set interface ipsec spd if-GigabitEthernet0/8/0 1"))
+	gomega.Expect(cmds[4]).To(gomega.HavePrefix(saAdd(1, 2, 1)))
+	gomega.Expect(cmds[5]).To(gomega.HavePrefix("ipsec policy add spd 1 priority 100 inbound action protect"))
+	gomega.Expect(node1.acceptedGeneration()).To(gomega.BeEquivalentTo(1))
+
+	// the other node derives the same security associations
+	gomega.Expect(node2.addKey(1, secret1)).To(gomega.Succeed())
+	gomega.Expect(node2.configure("GigabitEthernet0/8/0", ip2)).To(gomega.Succeed())
+	gomega.Expect(node2.updatePeer(1, ip1, 1)).To(gomega.Succeed())
+	cmds2 := cli2.flush()
+	gomega.Expect(cmds2).To(gomega.ContainElement(cmds[4]))
+
+	// outbound traffic is protected once the peer announces the generation,
+	// unprotected VXLAN traffic from the peer is discarded from then on
+	gomega.Expect(node1.updatePeer(2, ip2, 1)).To(gomega.Succeed())
+	cmds = cli1.flush()
+	gomega.Expect(cmds).To(gomega.HaveLen(3))
+	gomega.Expect(cmds[0]).To(gomega.HavePrefix(saAdd(1, 1, 2)))
+	gomega.Expect(cmds2).To(gomega.ContainElement(cmds[0]))
+	gomega.Expect(cmds[1]).To(gomega.HavePrefix("ipsec policy add spd 1 priority 100 outbound action protect"))
+	gomega.Expect(cmds[1]).To(gomega.HaveSuffix("protocol 17 remote-port-range 4789 - 4789"))
+	gomega.Expect(cmds[2]).To(gomega.Equal("ipsec policy add spd 1 priority 50 inbound action discard " +
+		"local-ip-range 10.0.0.1 - 10.0.0.1 remote-ip-range 10.0.0.2 - 10.0.0.2 protocol 17 local-port-range 4789 - 4789"))
+
+	// a new generation is accepted for the inbound traffic immediately
+	gomega.Expect(node1.addKey(2, secret2)).To(gomega.Succeed())
+	cmds = cli1.flush()
+	gomega.Expect(cmds).To(gomega.HaveLen(2))
+	gomega.Expect(cmds[0]).To(gomega.HavePrefix(saAdd(2, 2, 1)))
+	gomega.Expect(node1.acceptedGeneration()).To(gomega.BeEquivalentTo(2))
+
+	// outbound traffic switches to the new generation once accepted by the peer, the old one is removed last
+	gomega.Expect(node1.updatePeer(2, ip2, 2)).To(gomega.Succeed())
+	cmds = cli1.flush()
+	gomega.Expect(cmds).To(gomega.HaveLen(4))
+	gomega.Expect(cmds[0]).To(gomega.HavePrefix(saAdd(2, 1, 2)))
+	gomega.Expect(cmds[1]).To(gomega.HavePrefix("ipsec policy add spd 1 priority 100 outbound"))
+	gomega.Expect(cmds[2]).To(gomega.HavePrefix(fmt.Sprintf(
+		"ipsec policy del spd 1 priority 100 outbound action protect sa %d ", ipsecSPI(1, 1, 2))))
+	gomega.Expect(cmds[3]).To(gomega.Equal(fmt.Sprintf("ipsec sa del %d", ipsecSPI(1, 1, 2))))
+
+	// the third generation expires the first one for the inbound traffic
+	gomega.Expect(node1.addKey(3, secret3)).To(gomega.Succeed())
+	cmds = cli1.flush()
+	gomega.Expect(cmds).To(gomega.HaveLen(4))
+	gomega.Expect(cmds[0]).To(gomega.HavePrefix(saAdd(3, 2, 1)))
+	gomega.Expect(cmds[3]).To(gomega.Equal(fmt.Sprintf("ipsec sa del %d", ipsecSPI(1, 2, 1))))
+
+	// the secret of a known generation can't be changed
+	gomega.Expect(node1.addKey(3, secret1)).NotTo(gomega.Succeed())
+	gomega.Expect(node1.addKey(3, secret3)).To(gomega.Succeed())
+	gomega.Expect(cli1.cmds).To(gomega.BeEmpty())
+
+	// removal of the peer removes all its security associations
+	gomega.Expect(node1.removePeer(2)).To(gomega.Succeed())
+	cmds = cli1.flush()
+	gomega.Expect(cmds).To(gomega.HaveLen(7))
+	for _, cmd := range cmds {
+		gomega.Expect(cmd).To(gomega.ContainSubstring(" del "))
+	}
+
+	// the generations are encoded into the SPI cyclically
+	gomega.Expect(ipsecSPI(255, 1, 2) >> 24).To(gomega.BeEquivalentTo(255))
+	gomega.Expect(ipsecSPI(256, 1, 2)).To(gomega.Equal(ipsecSPI(1, 1, 2)))
+
+	// tunnel mode
+	cli3 := &cliMock{}
+	node3, err := newIPSecOverlay(logrus.DefaultLogger(), cli3, 3, "tunnel")
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(node3.addKey(1, secret1)).To(gomega.Succeed())
+	gomega.Expect(node3.configure("GigabitEthernet0/8/0", ip1)).To(gomega.Succeed())
+	gomega.Expect(node3.updatePeer(2, ip2, 0)).To(gomega.Succeed())
+	gomega.Expect(cli3.cmds).To(gomega.ContainElement(gomega.HaveSuffix("tunnel-src 10.0.0.2 tunnel-dst 10.0.0.1")))
+
+	// invalid configuration
+	_, err = newIPSecOverlay(logrus.DefaultLogger(), cli3, 1, "esp")
+	gomega.Expect(err).NotTo(gomega.BeNil())
+	_, err = newIPSecOverlay(logrus.DefaultLogger(), cli3, ipsecMaxNodeID+1, "")
+	gomega.Expect(err).NotTo(gomega.BeNil())
+}
+
+func TestIPSecGenerationWrap(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	cli := &cliMock{}
+	node1, err := newIPSecOverlay(logrus.DefaultLogger(), cli, 1, "")
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(node1.addKey(1, []byte("secret1"))).To(gomega.Succeed())
+	gomega.Expect(node1.configure("GigabitEthernet0/8/0", net.ParseIP("10.0.0.1"))).To(gomega.Succeed())
+	gomega.Expect(node1.updatePeer(2, net.ParseIP("10.0.0.2"), 1)).To(gomega.Succeed())
+
+	// a generation with the same SPI as the accepted one is rejected
+	gomega.Expect(node1.addKey(256, []byte("secret256"))).NotTo(gomega.Succeed())
+	gomega.Expect(node1.acceptedGeneration()).To(gomega.BeEquivalentTo(1))
+
+	// the generation is accepted once the generation with the same SPI expires
+	gomega.Expect(node1.addKey(255, []byte("secret255"))).To(gomega.Succeed())
+	gomega.Expect(node1.addKey(256, []byte("secret256"))).To(gomega.Succeed())
+	gomega.Expect(node1.acceptedGeneration()).To(gomega.BeEquivalentTo(256))
+	cli.flush()
+
+	// the outbound association with the same SPI is removed before the new one is added
+	gomega.Expect(node1.updatePeer(2, net.ParseIP("10.0.0.2"), 256)).To(gomega.Succeed())
+	cmds := cli.flush()
+	gomega.Expect(cmds).To(gomega.HaveLen(4))
+	gomega.Expect(cmds[0]).To(gomega.HavePrefix("ipsec policy del spd 1 priority 100 outbound"))
+	gomega.Expect(cmds[1]).To(gomega.Equal(fmt.Sprintf("ipsec sa del %d", ipsecSPI(1, 1, 2))))
+	gomega.Expect(cmds[2]).To(gomega.HavePrefix(fmt.Sprintf("ipsec sa add %d ", ipsecSPI(256, 1, 2))))
+	gomega.Expect(cmds[3]).To(gomega.HavePrefix("ipsec policy add spd 1 priority 100 outbound"))
+}
+
+func TestPodBandwidth(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	server, _, configuredContainers, conn := setupTestCNIServer(&configVethL2NoTCP, nil)
+	defer conn.Disconnect()
+	limiter := &bandwidthLimiterMock{limits: map[string]podBandwidth{}, ifs: map[string]string{}}
+	server.bandwidthLimiter = limiter
+
+	// pretend that connectivity is configured to unblock CNI requests
+	server.vswitchConnectivityConfigured = true
+
+	// annotations are reflected before the pod is connected
+	gomega.Expect(server.updatePodBandwidth(podWithBandwidth("10M", "1M"))).To(gomega.Succeed())
+	gomega.Expect(limiter.limits).To(gomega.BeEmpty())
+
+	// CNI Add applies the limit
+	reply, err := server.Add(context.Background(), &req)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(reply.Result).To(gomega.BeEquivalentTo(resultOk))
+	persisted, found := configuredContainers.LookupContainer(containerID)
+	gomega.Expect(found).To(gomega.BeTrue())
+	gomega.Expect(limiter.limits).To(gomega.HaveKeyWithValue(containerID, podBandwidth{ingress: 10000000, egress: 1000000}))
+	gomega.Expect(limiter.ifs).To(gomega.HaveKeyWithValue(containerID, persisted.VppIfName))
+
+	// change of the annotation is applied to the connected pod
+	gomega.Expect(server.updatePodBandwidth(podWithBandwidth("10M", "2M"))).To(gomega.Succeed())
+	gomega.Expect(limiter.limits).To(gomega.HaveKeyWithValue(containerID, podBandwidth{ingress: 10000000, egress: 2000000}))
+
+	// invalid annotation is rejected, the previous limit is kept
+	gomega.Expect(server.updatePodBandwidth(podWithBandwidth("10M", "1"))).NotTo(gomega.Succeed())
+	gomega.Expect(server.updatePodBandwidth(podWithBandwidth("10M", "fast"))).NotTo(gomega.Succeed())
+	gomega.Expect(limiter.limits).To(gomega.HaveKeyWithValue(containerID, podBandwidth{ingress: 10000000, egress: 2000000}))
+
+	// removal of the annotations removes the limit
+	gomega.Expect(server.updatePodBandwidth(podWithBandwidth("10M", ""))).To(gomega.Succeed())
+	gomega.Expect(limiter.limits).To(gomega.HaveKeyWithValue(containerID, podBandwidth{ingress: 10000000}))
+	gomega.Expect(server.updatePodBandwidth(podWithBandwidth("", ""))).To(gomega.Succeed())
+	gomega.Expect(limiter.limits).To(gomega.BeEmpty())
+
+	// resync restores the limit
+	gomega.Expect(server.resyncPodBandwidth([]*podmodel.Pod{podWithBandwidth("", "500k")})).To(gomega.Succeed())
+	gomega.Expect(limiter.limits).To(gomega.HaveKeyWithValue(containerID, podBandwidth{egress: 500000}))
+
+	// CNI Delete removes the limit
+	reply, err = server.Delete(context.Background(), &req)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(reply.Result).To(gomega.BeEquivalentTo(resultOk))
+	gomega.Expect(limiter.limits).To(gomega.BeEmpty())
+}
+
+// policerCLIMock simulates creation of the classify tables on top of cliMock.
+type policerCLIMock struct {
+	*cliMock
+	tables int
+}
+
+func (m *policerCLIMock) cli(cmd string) error {
+	if strings.HasPrefix(cmd, "classify table ") {
+		m.tables++
+	}
+	return m.cliMock.cli(cmd)
+}
+
+func (m *policerCLIMock) cliOutput(cmd string) (string, error) {
+	if cmd == "show classify tables" {
+		var out []string
+		for idx := 0; idx < m.tables; idx++ {
+			out = append(out, fmt.Sprintf("%d 1024 0 0", idx))
+		}
+		return strings.Join(out, "\n"), nil
+	}
+	return m.cliMock.cliOutput(cmd)
+}
+
+func TestVppPolicers(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	cli := &policerCLIMock{cliMock: &cliMock{ifNames: []string{"local0", "if-pod1", "if-pod2", "if-uplink"}}}
+	policers := newVppPolicers(logrus.DefaultLogger(), cli)
+	ipv4, ipv6 := net.ParseIP("10.1.1.2"), net.ParseIP("fd00:1::2")
+
+	// no limits, no VPP configuration
+	gomega.Expect(policers.updateInterfaces()).To(gomega.Succeed())
+	gomega.Expect(cli.flush()).To(gomega.BeEmpty())
+
+	// egress limit of a dual-stack pod: source IPs matched on the input of the pod interface
+	err := policers.setLimits("pod1", "pod1", []net.IP{ipv4, ipv6}, podBandwidth{egress: 1000000})
+	gomega.Expect(err).To(gomega.BeNil())
+	cmds := cli.flush()
+	gomega.Expect(cmds).To(gomega.ContainElement("classify table mask l3 ip4 dst buckets 1024"))
+	gomega.Expect(cmds).To(gomega.ContainElement("classify table mask l3 ip4 src buckets 1024 next-table 0"))
+	gomega.Expect(cmds).To(gomega.ContainElement("classify table mask l3 ip6 src buckets 1024 next-table 2"))
+	gomega.Expect(cmds).To(gomega.ContainElement("classify session policer-hit-next contiv-egress-pod1 table-index 1 match l3 ip4 src 10.1.1.2"))
+	gomega.Expect(cmds).To(gomega.ContainElement("classify session policer-hit-next contiv-egress-pod1 table-index 3 match l3 ip6 src fd00:1::2"))
+	gomega.Expect(cmds).To(gomega.ContainElement("set policer classify interface if-pod1 ip4-table 1"))
+	gomega.Expect(cmds).To(gomega.ContainElement("set policer classify interface if-pod1 ip6-table 3"))
+
+	// ingress limit of another pod: destination IP matched on the input of all the other interfaces
+	err = policers.setLimits("pod2", "pod2", []net.IP{net.ParseIP("10.1.1.3")}, podBandwidth{ingress: 2000000})
+	gomega.Expect(err).To(gomega.BeNil())
+	cmds = cli.flush()
+	gomega.Expect(cmds).To(gomega.ContainElement("classify session policer-hit-next contiv-ingress-pod2 table-index 0 match l3 ip4 dst 10.1.1.3"))
+	gomega.Expect(cmds).To(gomega.ContainElement("set policer classify interface if-uplink ip4-table 0"))
+	gomega.Expect(cmds).To(gomega.ContainElement("set policer classify interface if-uplink ip6-table 2"))
+	gomega.Expect(cmds).To(gomega.ContainElement("set policer classify interface if-pod2 ip4-table 0"))
+	gomega.Expect(cmds).NotTo(gomega.ContainElement(gomega.ContainSubstring("interface if-pod1")))
+	gomega.Expect(cmds).NotTo(gomega.ContainElement(gomega.ContainSubstring("interface local0")))
+
+	// new interfaces are attached to the ingress tables by the update
+	cli.ifNames = append(cli.ifNames, "if-pod3")
+	gomega.Expect(policers.updateInterfaces()).To(gomega.Succeed())
+	gomega.Expect(cli.flush()).To(gomega.ConsistOf(
+		"set policer classify interface if-pod3 ip4-table 0",
+		"set policer classify interface if-pod3 ip6-table 2"))
+
+	// removal of the egress limit makes the pod interface use the ingress tables
+	gomega.Expect(policers.removeLimits("pod1")).To(gomega.Succeed())
+	cmds = cli.flush()
+	gomega.Expect(cmds).To(gomega.ContainElement("set policer classify interface if-pod1 ip4-table 1 del"))
+	gomega.Expect(cmds).To(gomega.ContainElement("classify session del table-index 3 match l3 ip6 src fd00:1::2"))
+	gomega.Expect(cmds).To(gomega.ContainElement("configure policer name contiv-egress-pod1 del"))
+	gomega.Expect(cmds[len(cmds)-2:]).To(gomega.ConsistOf(
+		"set policer classify interface if-pod1 ip4-table 0",
+		"set policer classify interface if-pod1 ip6-table 2"))
+
+	// removal of the last ingress limit detaches the ingress tables
+	gomega.Expect(policers.setLimits("pod2", "pod2", nil, podBandwidth{})).To(gomega.Succeed())
+	cmds = cli.flush()
+	gomega.Expect(cmds).To(gomega.ContainElement("configure policer name contiv-ingress-pod2 del"))
+	gomega.Expect(cmds).To(gomega.ContainElement("set policer classify interface if-uplink ip4-table 0 del"))
+	gomega.Expect(cmds).To(gomega.HaveLen(2 + 2*4))
+	gomega.Expect(policers.updateInterfaces()).To(gomega.Succeed())
+	gomega.Expect(cli.flush()).To(gomega.BeEmpty())
+}
+
+func TestStalePodCleanup(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	server, _, configuredContainers, conn := setupTestCNIServer(&configVethL2NoTCP, nil)
+	defer conn.Disconnect()
+
+	// pretend that connectivity is configured to unblock CNI requests
+	server.vswitchConnectivityConfigured = true
+
+	reply, err := server.Add(context.Background(), &req)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(reply.Result).To(gomega.BeEquivalentTo(resultOk))
+	gomega.Expect(server.ipam.PodIP(containerID)).NotTo(gomega.BeNil())
+
+	// IP address allocated for a container which was never connected
+	_, err = server.ipam.NextPodIP("orphan")
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// nothing is removed until the pods are resynced
+	report := server.cleanupStalePods()
+	gomega.Expect(report.removedContainers).To(gomega.BeEmpty())
+	gomega.Expect(report.releasedIPs).To(gomega.ConsistOf("orphan"))
+	gomega.Expect(server.ipam.PodIP("orphan")).To(gomega.BeNil())
+	_, found := configuredContainers.LookupContainer(containerID)
+	gomega.Expect(found).To(gomega.BeTrue())
+
+	// the container of an existing pod is kept
+	server.resyncLivePods([]*podmodel.Pod{{Name: podName, Namespace: podNamespace}})
+	report = server.cleanupStalePods()
+	gomega.Expect(report.empty()).To(gomega.BeTrue())
+	_, found = configuredContainers.LookupContainer(containerID)
+	gomega.Expect(found).To(gomega.BeTrue())
+
+	// the container connected in this run is removed only if it is found stale twice
+	server.deleteLivePod(podmodel.ID{Name: podName, Namespace: podNamespace})
+	report = server.cleanupStalePods()
+	gomega.Expect(report.empty()).To(gomega.BeTrue())
+	_, found = configuredContainers.LookupContainer(containerID)
+	gomega.Expect(found).To(gomega.BeTrue())
+
+	report = server.cleanupStalePods()
+	gomega.Expect(report.removedContainers).To(gomega.ConsistOf(containerID))
+	gomega.Expect(report.failedContainers).To(gomega.BeEmpty())
+	_, found = configuredContainers.LookupContainer(containerID)
+	gomega.Expect(found).To(gomega.BeFalse())
+	gomega.Expect(server.ipam.PodIP(containerID)).To(gomega.BeNil())
+
+	// the container connected before the restart of the vswitch is removed immediately
+	server.configuredInThisRun = map[string]bool{}
+	server.updateLivePod(podmodel.ID{Name: podName, Namespace: podNamespace})
+	reply, err = server.Add(context.Background(), &req)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(reply.Result).To(gomega.BeEquivalentTo(resultOk))
+	delete(server.configuredInThisRun, containerID)
+	server.resyncLivePods(nil)
+	_, found = configuredContainers.LookupContainer(containerID)
+	gomega.Expect(found).To(gomega.BeFalse())
+}
+
 func TestConfigureVswitchDHCP(t *testing.T) {
 	gomega.RegisterTestingT(t)
 
@@ -228,24 +1135,149 @@ func TestAddDelTap(t *testing.T) {
 	// CNI Add
 	reply, err := server.Add(context.Background(), &req)
 
-	gomega.Expect(err).To(gomega.BeNil())
-	gomega.Expect(reply).NotTo(gomega.BeNil())
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(reply).NotTo(gomega.BeNil())
+
+	gomega.Expect(len(txns.PendingTxns)).To(gomega.BeEquivalentTo(2)) // not applied reverts
+	gomega.Expect(len(txns.CommittedTxns)).To(gomega.BeEquivalentTo(4))
+	// TODO add asserts for txns(one linux plugin txn and one default plugins txn) / currently applied config
+
+	res := configuredContainers.LookupPodName(podName)
+	gomega.Expect(len(res)).To(gomega.BeEquivalentTo(1))
+	gomega.Expect(res).To(gomega.ContainElement(containerID))
+
+	txns.Clear()
+
+	// CNI Delete
+	reply, err = server.Delete(context.Background(), &req)
+	// TODO: re-enable checks once TAPs are fully supported by the vpp-agent
+	//gomega.Expect(err).To(gomega.BeNil())
+	//gomega.Expect(reply).NotTo(gomega.BeNil())
+}
+
+func TestConcurrentAddDel(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	server, txns, configuredContainers, conn := setupTestCNIServer(&configVethL2NoTCP, nil)
+	defer conn.Disconnect()
+
+	// pretend that connectivity is configured to unblock CNI requests
+	server.vswitchConnectivityConfigured = true
+
+	const podCount = 20
+	requests := make([]*cni.CNIRequest, podCount)
+	for i := range requests {
+		request := req
+		request.ContainerId = fmt.Sprintf("%s-%d", containerID, i)
+		request.ExtraArguments = fmt.Sprintf("K8S_POD_NAMESPACE=%s;K8S_POD_NAME=%s-%d", podNamespace, podName, i)
+		requests[i] = &request
+	}
+	replies := make([]*cni.CNIReply, podCount)
+	sendAll := func(send func(context.Context, *cni.CNIRequest) (*cni.CNIReply, error)) {
+		var wg sync.WaitGroup
+		for i := range requests {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				replies[i], _ = send(context.Background(), requests[i])
+			}(i)
+		}
+		wg.Wait()
+	}
+
+	// CNI Add of all PODs at once
+	sendAll(server.Add)
+	podIPs := map[string]struct{}{}
+	for i, reply := range replies {
+		gomega.Expect(reply).NotTo(gomega.BeNil())
+		gomega.Expect(reply.Result).To(gomega.BeEquivalentTo(resultOk))
+		podIPs[reply.Interfaces[0].IpAddresses[0].Address] = struct{}{}
+		_, found := configuredContainers.LookupContainer(requests[i].ContainerId)
+		gomega.Expect(found).To(gomega.BeTrue())
+	}
+	gomega.Expect(podIPs).To(gomega.HaveLen(podCount))
+	gomega.Expect(server.ipam.PodIDs()).To(gomega.HaveLen(podCount))
+
+	// the transactions of the PODs wired concurrently may be merged
+	gomega.Expect(len(txns.CommittedTxns)).To(gomega.BeNumerically("<=", 3*podCount))
+	for i := range requests {
+		gomega.Expect(txns.AppliedConfig).To(gomega.HaveKey(
+			vpp_intf.InterfaceKey(server.afpacketNameFromRequest(requests[i]))))
+	}
+
+	// CNI Delete of all PODs at once
+	sendAll(server.Delete)
+	for _, reply := range replies {
+		gomega.Expect(reply).NotTo(gomega.BeNil())
+		gomega.Expect(reply.Result).To(gomega.BeEquivalentTo(resultOk))
+	}
+	gomega.Expect(configuredContainers.ListAll()).To(gomega.BeEmpty())
+	gomega.Expect(server.ipam.PodIDs()).To(gomega.BeEmpty())
+	gomega.Expect(server.podsInProgress).To(gomega.BeEmpty())
+}
+
+func TestConcurrentWiring(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	server, _, configuredContainers, conn := setupTestCNIServer(&configVethL2NoTCP, nil)
+	defer conn.Disconnect()
 
-	gomega.Expect(len(txns.PendingTxns)).To(gomega.BeEquivalentTo(2)) // not applied reverts
-	gomega.Expect(len(txns.CommittedTxns)).To(gomega.BeEquivalentTo(4))
-	// TODO add asserts for txns(one linux plugin txn and one default plugins txn) / currently applied config
+	// pretend that connectivity is configured to unblock CNI requests
+	server.vswitchConnectivityConfigured = true
 
-	res := configuredContainers.LookupPodName(podName)
-	gomega.Expect(len(res)).To(gomega.BeEquivalentTo(1))
-	gomega.Expect(res).To(gomega.ContainElement(containerID))
+	// block the first transaction wiring a POD
+	var (
+		blockFirst = true
+		committing = make(chan struct{})
+		release    = make(chan struct{})
+	)
+	podTxns := localclient.NewTxnTracker(func(txn *localclient.Txn) error {
+		if blockFirst {
+			blockFirst = false
+			close(committing)
+			<-release
+		}
+		return nil
+	})
+	server.podTxns = newTxnBatcher(podTxns.NewLinuxDataChangeTxn)
+	queueLen := func() int {
+		server.podTxns.Lock()
+		defer server.podTxns.Unlock()
+		return len(server.podTxns.queue)
+	}
 
-	txns.Clear()
+	requests := make([]*cni.CNIRequest, 2)
+	for i := range requests {
+		request := req
+		request.ContainerId = fmt.Sprintf("%s-%d", containerID, i)
+		request.ExtraArguments = fmt.Sprintf("K8S_POD_NAMESPACE=%s;K8S_POD_NAME=%s-%d", podNamespace, podName, i)
+		requests[i] = &request
+	}
+	replies := make([]*cni.CNIReply, 2)
+	var wg sync.WaitGroup
+	add := func(i int) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			replies[i], _ = server.Add(context.Background(), requests[i])
+		}()
+	}
 
-	// CNI Delete
-	reply, err = server.Delete(context.Background(), &req)
-	// TODO: re-enable checks once TAPs are fully supported by the vpp-agent
-	//gomega.Expect(err).To(gomega.BeNil())
-	//gomega.Expect(reply).NotTo(gomega.BeNil())
+	// the second POD is wired while the wiring of the first one is blocked in VPP
+	add(0)
+	<-committing
+	add(1)
+	gomega.Eventually(queueLen).Should(gomega.Equal(1))
+	close(release)
+	wg.Wait()
+
+	for i, reply := range replies {
+		gomega.Expect(reply).NotTo(gomega.BeNil())
+		gomega.Expect(reply.Result).To(gomega.BeEquivalentTo(resultOk))
+		_, found := configuredContainers.LookupContainer(requests[i].ContainerId)
+		gomega.Expect(found).To(gomega.BeTrue())
+	}
+	gomega.Expect(server.podsWiring).To(gomega.BeZero())
 }
 
 func TestConfigureVswitchVeth(t *testing.T) {
@@ -352,12 +1384,663 @@ func TestNodeAddDelVXLAN(t *testing.T) {
 	gomega.Expect(otherNodeInfo.IpAddress).To(gomega.ContainSubstring(vxlanIf.Vxlan.DstAddress))
 
 	// check routes to the other node pointing to VXLAN IP
-	nexthopIP, _ := server.ipam.VxlanIPAddress(uint8(otherNodeInfo.Id))
+	nexthopIP, _ := server.ipam.VxlanIPAddress(otherNodeInfo.Id)
+	routes := routesViaInSnapshot(txns.AppliedConfig, nexthopIP.String())
+	gomega.Expect(len(routes)).To(gomega.BeEquivalentTo(3))
+
+	err = server.nodeChangePropageteEvent(&nodeAddDelEvent{evType: datasync.Delete})
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// check that the VXLAN tunnel and the routes have been removed
+	gomega.Expect(interfaceInSnapshot(txns.AppliedConfig, vxlanIf.Name)).To(gomega.BeNil())
+	gomega.Expect(routesViaInSnapshot(txns.AppliedConfig, nexthopIP.String())).To(gomega.BeEmpty())
+}
+
+func TestNodeAddDelPodCIDRBlocks(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	config := configTapVxlanTCP
+	config.IPAMConfig.DynamicPodCIDRBlocks = true
+	blockStore := newPodCIDRBlockStoreMock(&ipamModel.PodCIDRBlock{Network: "10.1.7.0/24", NodeID: otherNodeInfo.Id})
+	server, txns, _, conn := setupTestCNIServerWithBlockStore(&config, nil, blockStore)
+	defer conn.Disconnect()
+
+	// exec resync to configure vswitch
+	err := server.resync()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	err = server.nodeChangePropageteEvent(&nodeAddDelEvent{evType: datasync.Put})
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// routes to the pod CIDR block, the vswitch network and the management IP of the other node
+	nexthopIP, _ := server.ipam.VxlanIPAddress(otherNodeInfo.Id)
+	routes := routesViaInSnapshot(txns.AppliedConfig, nexthopIP.String())
+	gomega.Expect(routes).To(gomega.HaveLen(3))
+
+	// the other node claims another block
+	extraBlock := &ipamModel.PodCIDRBlock{Network: "10.1.8.0/24", NodeID: otherNodeInfo.Id}
+	err = server.nodeChangePropageteEvent(&podCIDRBlockEvent{evType: datasync.Put, block: extraBlock})
+	gomega.Expect(err).To(gomega.BeNil())
+	routes = routesViaInSnapshot(txns.AppliedConfig, nexthopIP.String())
+	gomega.Expect(routes).To(gomega.HaveLen(4))
+
+	// the first block is released without the route being updated (e.g. the event was not received)
+	server.ipam.UpdatePodCIDRBlock(&ipamModel.PodCIDRBlock{Network: "10.1.7.0/24", NodeID: otherNodeInfo.Id}, false)
+
+	// all installed routes are removed with the node
+	err = server.nodeChangePropageteEvent(&nodeAddDelEvent{evType: datasync.Delete})
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(routesViaInSnapshot(txns.AppliedConfig, nexthopIP.String())).To(gomega.BeEmpty())
+}
+
+func TestNodeAddDelIPIP(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	server, txns, _, conn := setupTestCNIServer(&configTapIPIPTCP, nil)
+	defer conn.Disconnect()
+	cli := &cliMock{}
+	server.overlay.(*ipipOverlay).cli = cli
+	tunnelsChanged := make(chan struct{}, 1)
+	server.WatchOverlayTunnels(tunnelsChanged)
+
+	// exec resync to configure vswitch
+	err := server.resync()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// the overlay IP of the node is configured on a loopback, there is no VXLAN BVI
+	gomega.Expect(interfaceInSnapshot(txns.AppliedConfig, ipipLoopName)).ToNot(gomega.BeNil())
+	gomega.Expect(interfaceInSnapshot(txns.AppliedConfig, vxlanBVIInterfaceName)).To(gomega.BeNil())
+	gomega.Expect(server.GetVxlanBVIIfName()).To(gomega.BeEmpty())
+
+	err = server.nodeChangePropageteEvent(&nodeAddDelEvent{evType: datasync.Put})
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// check that the tunnel has been created with the route to the overlay IP of the other node
+	nexthopIP, _ := server.ipam.VxlanIPAddress(otherNodeInfo.Id)
+	tunnelIf := fmt.Sprintf("ipip%d", otherNodeInfo.Id)
+	cmds := cli.flush()
+	gomega.Expect(cmds).To(gomega.ContainElement(fmt.Sprintf("create ipip tunnel src %s dst %s instance %d",
+		server.ipPrefixToAddress(server.nodeIP), server.ipPrefixToAddress(otherNodeInfo.IpAddress), otherNodeInfo.Id)))
+	gomega.Expect(cmds).To(gomega.ContainElement(fmt.Sprintf("ip route add %s/32 via %s", nexthopIP, tunnelIf)))
+
+	// the tunnel is registered for the policy ACLs and announced to the subscribers
+	_, _, registered := server.swIfIndex.LookupIdx(tunnelIf)
+	gomega.Expect(registered).To(gomega.BeTrue())
+	gomega.Expect(server.GetOverlayTunnelIfNames()).To(gomega.ConsistOf(tunnelIf))
+	gomega.Expect(tunnelsChanged).To(gomega.Receive())
+
+	// check routes to the other node pointing to its overlay IP
 	routes := routesViaInSnapshot(txns.AppliedConfig, nexthopIP.String())
 	gomega.Expect(len(routes)).To(gomega.BeEquivalentTo(3))
 
 	err = server.nodeChangePropageteEvent(&nodeAddDelEvent{evType: datasync.Delete})
 	gomega.Expect(err).To(gomega.BeNil())
+
+	// check that the routes and the tunnel have been removed
+	gomega.Expect(routesViaInSnapshot(txns.AppliedConfig, nexthopIP.String())).To(gomega.BeEmpty())
+	cmds = cli.flush()
+	gomega.Expect(cmds).To(gomega.ContainElement(fmt.Sprintf("ip route del %s/32 via %s", nexthopIP, tunnelIf)))
+	gomega.Expect(cmds).To(gomega.ContainElement("delete ipip tunnel sw_if_index 42"))
+	_, _, registered = server.swIfIndex.LookupIdx(tunnelIf)
+	gomega.Expect(registered).To(gomega.BeFalse())
+	gomega.Expect(server.GetOverlayTunnelIfNames()).To(gomega.BeEmpty())
+	gomega.Expect(tunnelsChanged).To(gomega.Receive())
+}
+
+func TestIPIPTunnelsAfterRestart(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	server, _, _, conn := setupTestCNIServer(&configTapIPIPTCP, nil)
+	defer conn.Disconnect()
+	tunnelIf := fmt.Sprintf("ipip%d", otherNodeInfo.Id)
+	otherIP := server.ipPrefixToAddress(otherNodeInfo.IpAddress)
+
+	// the tunnels towards the other node and a removed node were created by the previous run of the agent
+	cli := &cliMock{outputs: map[string]string{
+		"show ipip tunnel": fmt.Sprintf("[0] instance %d src 10.0.0.1 dst %s table-ID 0 sw-if-idx 3\n"+
+			"[1] instance 7 src 10.0.0.1 dst 10.0.0.7 table-ID 0 sw-if-idx 4\n", otherNodeInfo.Id, otherIP),
+	}}
+	server.overlay.(*ipipOverlay).cli = cli
+	gomega.Expect(server.resync()).To(gomega.Succeed())
+	cli.flush()
+
+	// the tunnel towards the same node IP is re-used
+	err := server.nodeChangePropageteEvent(&nodeAddDelEvent{evType: datasync.Put})
+	gomega.Expect(err).To(gomega.BeNil())
+	cmds := cli.flush()
+	gomega.Expect(cmds).To(gomega.ContainElement(fmt.Sprintf("set interface state %s up", tunnelIf)))
+	gomega.Expect(cmds).NotTo(gomega.ContainElement(gomega.HavePrefix("create ipip tunnel")))
+
+	// the tunnel towards the removed node is removed
+	overlay := server.overlay.(*ipipOverlay)
+	gomega.Expect(overlay.removeStaleNodes(map[uint32]bool{otherNodeInfo.Id: true})).To(gomega.Succeed())
+	cmds = cli.flush()
+	gomega.Expect(cmds).To(gomega.ContainElement(gomega.HavePrefix("ip route del")))
+	gomega.Expect(cmds).To(gomega.ContainElement("set interface nat44 in ipip7 out ipip7 del"))
+	gomega.Expect(cmds).To(gomega.ContainElement("delete ipip tunnel sw_if_index 42"))
+	gomega.Expect(cmds).NotTo(gomega.ContainElement(gomega.ContainSubstring(tunnelIf)))
+
+	// the tunnel towards another node IP is replaced
+	delete(overlay.tunnels, otherNodeInfo.Id)
+	cli.outputs["show ipip tunnel"] = fmt.Sprintf("[0] instance %d src 10.0.0.1 dst 10.0.0.99 table-ID 0 sw-if-idx 3\n",
+		otherNodeInfo.Id)
+	gomega.Expect(overlay.connectNode(nil, &otherNodeInfo)).To(gomega.Succeed())
+	cmds = cli.flush()
+	gomega.Expect(cmds).To(gomega.ContainElement("delete ipip tunnel sw_if_index 42"))
+	gomega.Expect(cmds).To(gomega.ContainElement(fmt.Sprintf("create ipip tunnel src %s dst %s instance %d",
+		server.ipPrefixToAddress(server.nodeIP), otherIP, otherNodeInfo.Id)))
+}
+
+func TestNodeIDRelease(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	server, txns, _, conn := setupTestCNIServer(&configTapVxlanTCP, nil)
+	defer conn.Disconnect()
+	released := make(chan struct{}, 1)
+	server.WatchNodeIDRelease(released)
+
+	// exec resync to configure vswitch
+	err := server.resync()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	err = server.nodeChangePropageteEvent(&nodeAddDelEvent{evType: datasync.Put})
+	gomega.Expect(err).To(gomega.BeNil())
+	nexthopIP, _ := server.ipam.VxlanIPAddress(otherNodeInfo.Id)
+	gomega.Expect(routesViaInSnapshot(txns.AppliedConfig, nexthopIP.String())).ToNot(gomega.BeEmpty())
+
+	// release of the ID of this node is announced to the subscribers, the other nodes are not affected
+	err = server.nodeChangePropageteEvent(&nodeAddDelEvent{evType: datasync.Delete, nodeID: server.nodeID})
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(released).To(gomega.Receive())
+	gomega.Expect(routesViaInSnapshot(txns.AppliedConfig, nexthopIP.String())).ToNot(gomega.BeEmpty())
+
+	// the other node is identified by the ID in the key of the delete event
+	err = server.nodeChangePropageteEvent(&nodeAddDelEvent{evType: datasync.Delete})
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(routesViaInSnapshot(txns.AppliedConfig, nexthopIP.String())).To(gomega.BeEmpty())
+	gomega.Expect(released).ToNot(gomega.Receive())
+
+	// repeated removal of the node is ignored
+	err = server.nodeChangePropageteEvent(&nodeAddDelEvent{evType: datasync.Delete})
+	gomega.Expect(err).To(gomega.BeNil())
+}
+
+func TestNodeOverlayConfig(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	config := configVethL2NoTCP
+	config.Overlay = IPIPOverlay
+	_, err := newRemoteCNIServer(logrus.DefaultLogger(), nil, nil, nil, nil, nil, nil, "testLabel", &config, nil, 1, nil, nil, nil)
+	gomega.Expect(err).NotTo(gomega.BeNil())
+
+	config = configTapVxlanTCP
+	config.Overlay = "geneve"
+	_, err = newRemoteCNIServer(logrus.DefaultLogger(), nil, nil, nil, nil, nil, nil, "testLabel", &config, nil, 1, nil, nil, nil)
+	gomega.Expect(err).NotTo(gomega.BeNil())
+
+	config = configTapIPIPTCP
+	config.IPSec.Enabled = true
+	_, err = newRemoteCNIServer(logrus.DefaultLogger(), nil, nil, nil, nil, nil, nil, "testLabel", &config, nil, 1, nil, nil, nil)
+	gomega.Expect(err).NotTo(gomega.BeNil())
+}
+
+func TestTenants(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	server, txns, configuredContainers, conn := setupTestCNIServer(&configTapVxlanTenants, nil)
+	defer conn.Disconnect()
+	cli := &cliMock{outputs: map[string]string{
+		"set acl-plugin acl permit+": "ACL index: 3\n",
+		"set acl-plugin acl permit ": "ACL index: 4\n",
+	}}
+	server.cli = cli
+
+	// exec resync to configure vswitch
+	err := server.resync()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// check the gateways and the VXLAN BVIs of the tenants
+	loop := interfaceInSnapshot(txns.AppliedConfig, tenantLoopPrefix+"tenantA")
+	gomega.Expect(loop).ToNot(gomega.BeNil())
+	gomega.Expect(loop.Vrf).To(gomega.BeEquivalentTo(10))
+	gomega.Expect(loop.IpAddresses).To(gomega.ConsistOf("10.3.1.1/24"))
+	bvi := interfaceInSnapshot(txns.AppliedConfig, tenantBVIPrefix+"tenantB")
+	gomega.Expect(bvi).ToNot(gomega.BeNil())
+	gomega.Expect(bvi.Vrf).To(gomega.BeEquivalentTo(11))
+
+	// only the prefixes of tenantA are leaked, the pods of tenantA are isolated from the default VRF
+	cmds := cli.flush()
+	gomega.Expect(cmds).To(gomega.ConsistOf(
+		"show acl-plugin acl",
+		"set acl-plugin acl permit+reflect src 0.0.0.0/0 dst 0.0.0.0/0 tag contiv-tenant-tenantA-in",
+		"show acl-plugin acl",
+		"set acl-plugin acl permit src 10.3.0.0/16 dst 0.0.0.0/0, deny src 0.0.0.0/0 dst 0.0.0.0/0 tag contiv-tenant-tenantA-out",
+		"ip route add 10.1.1.53/32 table 10 via ip4-lookup-in-table 0",
+		"ip route add 10.3.0.0/16 via ip4-lookup-in-table 10"))
+
+	// check the tunnels and the routes of the tenants towards the other node
+	err = server.nodeChangePropageteEvent(&nodeAddDelEvent{evType: datasync.Put})
+	gomega.Expect(err).To(gomega.BeNil())
+	vxlanIf := interfaceInSnapshot(txns.AppliedConfig, fmt.Sprintf("vxlan%d-tenantA", otherNodeInfo.Id))
+	gomega.Expect(vxlanIf).ToNot(gomega.BeNil())
+	gomega.Expect(vxlanIf.Vxlan.Vni).To(gomega.BeEquivalentTo(20))
+	nexthopIP, _ := server.ipam.VxlanIPAddress(otherNodeInfo.Id)
+	var tenantRoutes []string
+	for _, route := range routesViaInSnapshot(txns.AppliedConfig, nexthopIP.String()) {
+		if route.VrfId != 0 {
+			tenantRoutes = append(tenantRoutes, fmt.Sprintf("%d %s", route.VrfId, route.DstIpAddr))
+		}
+	}
+	gomega.Expect(tenantRoutes).To(gomega.ConsistOf("10 10.3.5.0/24", "11 10.1.5.0/24"))
+
+	// check that the tunnels and the routes of the tenants have been removed
+	err = server.nodeChangePropageteEvent(&nodeAddDelEvent{evType: datasync.Delete})
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(interfaceInSnapshot(txns.AppliedConfig, vxlanIf.Name)).To(gomega.BeNil())
+	gomega.Expect(routesViaInSnapshot(txns.AppliedConfig, nexthopIP.String())).To(gomega.BeEmpty())
+
+	// pods are not connected until the namespaces are reflected
+	reply, err := server.Add(context.Background(), &req)
+	gomega.Expect(err).NotTo(gomega.BeNil())
+	gomega.Expect(reply.Result).To(gomega.BeEquivalentTo(resultErr))
+
+	server.resyncNamespaces([]*nsmodel.Namespace{{
+		Name:  podNamespace,
+		Label: []*nsmodel.Namespace_Label{{Key: DefaultTenantLabel, Value: "tenantB"}},
+	}})
+
+	// the pod of tenantB gets an IP from the pool of the tenant, overlapping with the pod network
+	reply, err = server.Add(context.Background(), &req)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(reply.Interfaces[0].IpAddresses[0].Address).To(gomega.BeEquivalentTo("10.1.1.2/32"))
+	gomega.Expect(reply.Interfaces[0].IpAddresses[0].Gateway).To(gomega.BeEquivalentTo("10.1.1.1"))
+	config, found := configuredContainers.LookupContainer(containerID)
+	gomega.Expect(found).To(gomega.BeTrue())
+	gomega.Expect(config.Tenant).To(gomega.BeEquivalentTo("tenantB"))
+	podIf := interfaceInSnapshot(txns.AppliedConfig, config.VppIfName)
+	gomega.Expect(podIf).ToNot(gomega.BeNil())
+	gomega.Expect(podIf.Vrf).To(gomega.BeEquivalentTo(11))
+	gomega.Expect(podIf.Unnumbered.InterfaceWithIP).To(gomega.BeEquivalentTo(tenantLoopPrefix + "tenantB"))
+	gomega.Expect(podIf.IpAddresses).To(gomega.BeEmpty())
+
+	// the pod network pool is not used by the tenant pods
+	podIP, err := server.ipam.NextPodIP("otherContainer")
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(podIP.String()).To(gomega.BeEquivalentTo("10.1.1.2"))
+
+	// CNI Delete
+	reply, err = server.Delete(context.Background(), &req)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(reply).NotTo(gomega.BeNil())
+	ip, err := server.ipam.SecondaryPodIP("tenantB", containerID)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(ip).To(gomega.BeNil())
+}
+
+func TestTenantConfig(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	newServer := func(config *Config) error {
+		_, err := newRemoteCNIServer(logrus.DefaultLogger(), nil, nil, nil, nil, nil, nil, "testLabel", config, nil, 1, nil, nil, nil)
+		return err
+	}
+	config := configTapVxlanTenants
+	gomega.Expect(newServer(&config)).To(gomega.Succeed())
+
+	// tenants require the VXLAN overlay
+	config.Overlay = L2Overlay
+	gomega.Expect(newServer(&config)).NotTo(gomega.Succeed())
+
+	// the VRF and the VNI must be unique
+	config = configTapVxlanTenants
+	config.Tenants = []TenantConfig{configTapVxlanTenants.Tenants[0], configTapVxlanTenants.Tenants[1]}
+	config.Tenants[1].VrfID = 10
+	gomega.Expect(newServer(&config)).NotTo(gomega.Succeed())
+	config.Tenants[1].VrfID = 0
+	gomega.Expect(newServer(&config)).NotTo(gomega.Succeed())
+	config.Tenants[1].VrfID = 11
+	config.Tenants[1].Vni = vxlanVNI
+	gomega.Expect(newServer(&config)).NotTo(gomega.Succeed())
+
+	// the pool of the tenant must be configured
+	config = configTapVxlanTenants
+	config.Tenants = []TenantConfig{{Name: "tenantC", VrfID: 12, Vni: 22}}
+	gomega.Expect(newServer(&config)).NotTo(gomega.Succeed())
+
+	// the tenants sharing prefixes must not overlap with the pod network
+	config.Tenants = []TenantConfig{{Name: "tenantB", VrfID: 11, Vni: 21, SharedPrefixes: []string{"192.168.16.53/32"}}}
+	gomega.Expect(newServer(&config)).NotTo(gomega.Succeed())
+
+	// cluster IPs cannot be shared, the services are not NATed for the tenants
+	config = configTapVxlanTenants
+	config.Tenants = []TenantConfig{{Name: "tenantA", VrfID: 10, Vni: 20, SharedPrefixes: []string{"10.96.0.10/32"}}}
+	err := newServer(&config)
+	gomega.Expect(err).NotTo(gomega.BeNil())
+	gomega.Expect(err.Error()).To(gomega.ContainSubstring("service network"))
+}
+
+func TestTenantIsolation(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	server, _, configuredContainers, conn := setupTestCNIServer(&configTapVxlanTenants, nil)
+	defer conn.Disconnect()
+	// the ACLs already exist (restart of the agent)
+	cli := &cliMock{outputs: map[string]string{
+		"show acl-plugin acl": "acl-index 0 count 1 tag {contiv-egress-0}\n" +
+			"acl-index 3 count 1 tag {contiv-tenant-tenantA-in}\n" +
+			"  0: ipv4 permit+reflect src 0.0.0.0/0 dst 0.0.0.0/0 proto 0 sport 0-65535 dport 0-65535\n" +
+			"acl-index 4 count 2 tag {contiv-tenant-tenantA-out}\n" +
+			"  0: ipv4 permit src 10.3.0.0/16 dst 0.0.0.0/0 proto 0 sport 0-65535 dport 0-65535\n" +
+			"  1: ipv4 deny src 0.0.0.0/0 dst 0.0.0.0/0 proto 0 sport 0-65535 dport 0-65535\n",
+	}}
+	server.cli = cli
+
+	err := server.resync()
+	gomega.Expect(err).To(gomega.BeNil())
+	for _, cmd := range cli.flush() {
+		gomega.Expect(cmd).ToNot(gomega.HavePrefix("set acl-plugin acl"))
+	}
+
+	server.resyncNamespaces([]*nsmodel.Namespace{{
+		Name:  podNamespace,
+		Label: []*nsmodel.Namespace_Label{{Key: DefaultTenantLabel, Value: "tenantA"}},
+	}})
+
+	// the pod of tenantA accepts only the traffic from the subnet of the tenant and the replies
+	// to its own traffic, the default VRF routing the subnet of the tenant cannot reach the pod
+	reply, err := server.Add(context.Background(), &req)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(reply.Interfaces[0].IpAddresses[0].Address).To(gomega.BeEquivalentTo("10.3.1.2/32"))
+	config, found := configuredContainers.LookupContainer(containerID)
+	gomega.Expect(found).To(gomega.BeTrue())
+	gomega.Expect(cli.flush()).To(gomega.ConsistOf(
+		"set acl-plugin interface if-"+config.VppIfName+" input acl 3",
+		"set acl-plugin interface if-"+config.VppIfName+" output acl 4"))
+
+	// the ACLs are detached when the pod is removed
+	_, err = server.Delete(context.Background(), &req)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(cli.flush()).To(gomega.ConsistOf(
+		"set acl-plugin interface if-"+config.VppIfName+" input acl 3 del",
+		"set acl-plugin interface if-"+config.VppIfName+" output acl 4 del"))
+
+	// the pods of the tenants not sharing any prefix are not reachable from the default VRF at all
+	server.resyncNamespaces([]*nsmodel.Namespace{{
+		Name:  podNamespace,
+		Label: []*nsmodel.Namespace_Label{{Key: DefaultTenantLabel, Value: "tenantB"}},
+	}})
+	_, err = server.Add(context.Background(), &req)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(cli.flush()).To(gomega.BeEmpty())
+}
+
+func TestEgressGateway(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	server, _, configuredContainers, conn := setupTestCNIServer(&configTapVxlanEgress, nil)
+	defer conn.Disconnect()
+	cli := &cliMock{outputs: map[string]string{
+		"create loopback":    "loop5\n",
+		"set acl-plugin acl": "ACL index: 3\n",
+	}}
+	server.egress.cli = cli
+	store := server.egressStore.(*egressIPStoreMock)
+
+	// exec resync to configure vswitch and add the other node
+	err := server.resync()
+	gomega.Expect(err).To(gomega.BeNil())
+	err = server.nodeChangePropageteEvent(&nodeAddDelEvent{evType: datasync.Put})
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// connect the pod selected by the policy
+	reply, err := server.Add(context.Background(), &req)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(reply.Result).To(gomega.BeEquivalentTo(resultOk))
+	config, found := configuredContainers.LookupContainer(containerID)
+	gomega.Expect(found).To(gomega.BeTrue())
+	podIf := "if-" + config.VppIfName
+	gomega.Expect(server.resyncEgressPods([]*podmodel.Pod{{
+		Name:      podName,
+		Namespace: podNamespace,
+		IpAddress: "10.1.1.2",
+		Label:     []*podmodel.Pod_Label{{Key: "app", Value: "web"}},
+	}})).To(gomega.Succeed())
+	gomega.Expect(server.resyncEgressGateways([]string{otherNodeInfo.Name, "testLabel"})).To(gomega.Succeed())
+	gomega.Expect(cli.flush()).To(gomega.BeEmpty())
+
+	// the egress IP must be from the pool
+	policy := &egress.EgressPolicy{
+		Name:         "web",
+		Namespace:    podNamespace,
+		PodSelector:  []*egress.EgressPolicy_Label{{Key: "app", Value: "web"}},
+		GatewayNodes: []string{otherNodeInfo.Name, "testLabel"},
+		EgressIp:     "80.80.81.1",
+	}
+	gomega.Expect(server.updateEgressPolicy(policy)).To(gomega.Succeed())
+	gomega.Expect(cli.flush()).To(gomega.BeEmpty())
+
+	// the traffic of the pod leaving the cluster is forwarded to the other node
+	policy.EgressIp = ""
+	gomega.Expect(server.updateEgressPolicy(policy)).To(gomega.Succeed())
+	nexthopIP, _ := server.ipam.VxlanIPAddress(otherNodeInfo.Id)
+	cmds := cli.flush()
+	gomega.Expect(cmds).To(gomega.HaveLen(3))
+	gomega.Expect(cmds[0]).To(gomega.HavePrefix("set acl-plugin acl deny src 0.0.0.0/0 dst 10.1.0.0/16, "))
+	gomega.Expect(cmds[0]).To(gomega.HaveSuffix(", permit src 10.1.1.2/32 dst 0.0.0.0/0 tag contiv-egress-0"))
+	gomega.Expect(cmds[1]).To(gomega.Equal(fmt.Sprintf("abf policy add id 0 acl 3 via %s if-%s",
+		nexthopIP, vxlanBVIInterfaceName)))
+	gomega.Expect(cmds[2]).To(gomega.Equal("abf attach ip4 policy 0 " + podIf))
+	gomega.Expect(store.ips).To(gomega.BeEmpty())
+
+	// the other node dies, this node takes over and source-NATs the traffic to the first address of the pool
+	gomega.Expect(server.deleteEgressGateway(otherNodeInfo.Name)).To(gomega.Succeed())
+	cmds = cli.flush()
+	gomega.Expect(cmds).To(gomega.ContainElement("abf attach ip4 del policy 0 " + podIf))
+	gomega.Expect(cmds).To(gomega.ContainElement("delete acl-plugin acl index 3"))
+	gomega.Expect(cmds).To(gomega.ContainElement("ip table add 1000"))
+	gomega.Expect(cmds).To(gomega.ContainElement("set interface ip table loop5 1000"))
+	gomega.Expect(cmds).To(gomega.ContainElement("nat44 add address 80.80.80.1 tenant-vrf 1000"))
+	gomega.Expect(cmds).To(gomega.ContainElement("abf policy add id 0 acl 3 via 169.254.254.2 loop5"))
+	gomega.Expect(cmds).To(gomega.ContainElement("abf attach ip4 policy 0 " + podIf))
+	gomega.Expect(cmds).To(gomega.ContainElement("abf attach ip4 policy 0 if-" + vxlanBVIInterfaceName))
+	gomega.Expect(store.ips).To(gomega.HaveKeyWithValue("80.80.80.1", podNamespace+"/web"))
+
+	// the policy is detached from the interface of the removed pod
+	reply, err = server.Delete(context.Background(), &req)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(reply.Result).To(gomega.BeEquivalentTo(resultOk))
+	gomega.Expect(cli.flush()).To(gomega.ConsistOf("abf attach ip4 del policy 0 " + podIf))
+
+	// the configuration is removed and the egress IP released together with the policy
+	gomega.Expect(server.deleteEgressPolicy(podNamespace, "web")).To(gomega.Succeed())
+	cmds = cli.flush()
+	gomega.Expect(cmds).To(gomega.ContainElement("abf attach ip4 del policy 0 if-" + vxlanBVIInterfaceName))
+	gomega.Expect(cmds).To(gomega.ContainElement("nat44 add address 80.80.80.1 tenant-vrf 1000 del"))
+	gomega.Expect(cmds).To(gomega.ContainElement("delete loopback interface intfc loop5"))
+	gomega.Expect(cmds).To(gomega.ContainElement("ip table del 1000"))
+	gomega.Expect(store.ips).To(gomega.BeEmpty())
+}
+
+func TestEgressConfig(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	newServer := func(config *Config) error {
+		_, err := newRemoteCNIServer(logrus.DefaultLogger(), nil, nil, nil, nil, nil, nil, "testLabel", config, nil, 1, nil, nil, nil)
+		return err
+	}
+	config := configTapVxlanEgress
+	gomega.Expect(newServer(&config)).To(gomega.Succeed())
+
+	// egress gateways require the VXLAN overlay
+	config.Overlay = IPIPOverlay
+	gomega.Expect(newServer(&config)).NotTo(gomega.Succeed())
+
+	// the pool must be an IPv4 prefix
+	config = configTapVxlanEgress
+	config.Egress.IPPool = "80.80.80.1"
+	gomega.Expect(newServer(&config)).NotTo(gomega.Succeed())
+
+	// the VRFs of the tenants must not collide with the VRFs of the policies
+	config = configTapVxlanTenants
+	config.Egress = EgressConfig{IPPool: "80.80.80.0/30", FirstVrfID: 11}
+	gomega.Expect(newServer(&config)).NotTo(gomega.Succeed())
+}
+
+func TestUplinks(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	config := configTapVxlanTCP
+	config.UplinkBFD.Enabled = true
+	server, txns, _, conn := setupTestCNIServer(&config, &nodeUplinksConfig)
+	defer conn.Disconnect()
+	bfd := &bfdMonitorMock{states: map[string]bool{}}
+	server.bfd = bfd
+
+	// exec resync to configure vswitch
+	err := server.resync()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// the node IP is on a loopback, the traffic leaving the node is balanced over both uplinks
+	loop := interfaceInSnapshot(txns.AppliedConfig, "loopbackNIC")
+	gomega.Expect(loop).ToNot(gomega.BeNil())
+	gomega.Expect(loop.IpAddresses).To(gomega.ConsistOf("192.168.16.1/24"))
+	gomega.Expect(server.GetMainPhysicalIfName()).To(gomega.BeEmpty())
+	gomega.Expect(server.GetOtherPhysicalIfNames()).To(gomega.ConsistOf("GigabitEthernet0/0/0/1", "GigabitEthernet0/0/0/2"))
+	for _, uplink := range nodeUplinksConfig.Uplinks {
+		nic := interfaceInSnapshot(txns.AppliedConfig, uplink.InterfaceName)
+		gomega.Expect(nic).ToNot(gomega.BeNil())
+		gomega.Expect(nic.IpAddresses).To(gomega.ConsistOf(uplink.IP))
+		routes := routesViaInSnapshot(txns.AppliedConfig, uplink.Gateway)
+		gomega.Expect(routes).To(gomega.HaveLen(1))
+		gomega.Expect(routes[0].DstIpAddr).To(gomega.Equal(ipv4DefaultRoute))
+		gomega.Expect(routes[0].OutgoingInterface).To(gomega.Equal(uplink.InterfaceName))
+		session, found := txns.AppliedConfig[vpp_bfd.SessionKey(uplink.InterfaceName)]
+		gomega.Expect(found).To(gomega.BeTrue())
+		gomega.Expect(session.(*vpp_bfd.SingleHopBFD_Session).DestinationAddress).To(gomega.Equal(uplink.Gateway))
+	}
+
+	// the route via the failed uplink is withdrawn
+	bfd.states["10.10.1.1"] = false
+	bfd.states["10.10.2.1"] = true
+	gomega.Expect(server.checkUplinks()).To(gomega.Succeed())
+	gomega.Expect(routesViaInSnapshot(txns.AppliedConfig, "10.10.1.1")).To(gomega.BeEmpty())
+	gomega.Expect(routesViaInSnapshot(txns.AppliedConfig, "10.10.2.1")).To(gomega.HaveLen(1))
+
+	// the route via the last live uplink is kept
+	bfd.states["10.10.2.1"] = false
+	gomega.Expect(server.checkUplinks()).To(gomega.Succeed())
+	gomega.Expect(routesViaInSnapshot(txns.AppliedConfig, "10.10.2.1")).To(gomega.HaveLen(1))
+
+	// the route is restored once the uplink is up again
+	bfd.states["10.10.1.1"] = true
+	bfd.states["10.10.2.1"] = true
+	gomega.Expect(server.checkUplinks()).To(gomega.Succeed())
+	gomega.Expect(routesViaInSnapshot(txns.AppliedConfig, "10.10.1.1")).To(gomega.HaveLen(1))
+	gomega.Expect(routesViaInSnapshot(txns.AppliedConfig, "10.10.2.1")).To(gomega.HaveLen(1))
+}
+
+func TestUplinkConfig(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	newServer := func(config *Config, nodeConfig *OneNodeConfig) error {
+		_, err := newRemoteCNIServer(logrus.DefaultLogger(), nil, nil, nil, nil, nil, nil, "testLabel", config, nodeConfig, 1, nil, nil, nil)
+		return err
+	}
+	config := configTapVxlanTCP
+	nodeConfig := nodeUplinksConfig
+	gomega.Expect(newServer(&config, &nodeConfig)).To(gomega.Succeed())
+
+	// uplinks are not supported with the L2 overlay
+	config.Overlay = L2Overlay
+	gomega.Expect(newServer(&config, &nodeConfig)).NotTo(gomega.Succeed())
+
+	// the uplinks replace the main interface and the gateway
+	config = configTapVxlanTCP
+	nodeConfig.Gateway = "10.10.1.1"
+	gomega.Expect(newServer(&config, &nodeConfig)).NotTo(gomega.Succeed())
+
+	// the gateway must be in the subnet of the uplink
+	nodeConfig = nodeUplinksConfig
+	nodeConfig.Uplinks = []UplinkConfig{{InterfaceName: "GigabitEthernet0/0/0/1", IP: "10.10.1.2/30", Gateway: "10.10.2.1"}}
+	gomega.Expect(newServer(&config, &nodeConfig)).NotTo(gomega.Succeed())
+}
+
+func TestNodeConfigUpdate(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	// configuration loaded from ETCD, the node has no configuration in the configuration file
+	etcdConfig := nodeConfig
+	etcdConfig.NodeName = "testLabel"
+	server, txns, _, conn := setupTestCNIServer(&configTapVxlanTCP, &etcdConfig,
+		"GigabitEthernet0/0/0/1", "GigabitEthernet0/0/0/10", "GigabitEthernet0/0/0/11")
+	defer conn.Disconnect()
+	subscriber := make(chan struct{}, 1)
+	server.WatchNodeConfig(subscriber)
+
+	// exec resync to configure vswitch
+	err := server.resync()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(routesViaInSnapshot(txns.AppliedConfig, "192.168.1.100")).To(gomega.HaveLen(1))
+	gomega.Expect(server.GetOtherPhysicalIfNames()).To(gomega.ConsistOf("GigabitEthernet0/0/0/10"))
+	gomega.Expect(server.NatExternalTraffic()).To(gomega.BeFalse())
+
+	// the same configuration stored in ETCD does not change anything
+	gomega.Expect(server.resyncNodeConfig([]*nodeconfig.NodeConfig{{
+		NodeName:         "testLabel",
+		Gateway:          "192.168.1.100",
+		MainVppInterface: &nodeconfig.NodeConfig_InterfaceWithIP{InterfaceName: "GigabitEthernet0/0/0/1", Ip: "192.168.1.1/24"},
+		OtherVppInterfaces: []*nodeconfig.NodeConfig_InterfaceWithIP{
+			{InterfaceName: "GigabitEthernet0/0/0/10", Ip: "192.168.1.10/24"},
+		},
+	}})).To(gomega.Succeed())
+	gomega.Expect(subscriber).ToNot(gomega.Receive())
+
+	// new gateway, another interface instead of the original one and NAT enabled
+	newConfig := etcdConfig
+	newConfig.Gateway = "192.168.1.200"
+	newConfig.OtherVPPInterfaces = []InterfaceWithIP{{InterfaceName: "GigabitEthernet0/0/0/11", IP: "192.168.2.11/24"}}
+	newConfig.NatExternalTraffic = true
+	gomega.Expect(server.updateNodeConfig(&newConfig)).To(gomega.Succeed())
+	gomega.Expect(subscriber).To(gomega.Receive())
+
+	gomega.Expect(routesViaInSnapshot(txns.AppliedConfig, "192.168.1.100")).To(gomega.BeEmpty())
+	routes := routesViaInSnapshot(txns.AppliedConfig, "192.168.1.200")
+	gomega.Expect(routes).To(gomega.HaveLen(1))
+	gomega.Expect(routes[0].OutgoingInterface).To(gomega.Equal("GigabitEthernet0/0/0/1"))
+	gomega.Expect(server.GetDefaultGatewayIP().String()).To(gomega.Equal("192.168.1.200"))
+	gomega.Expect(interfaceInSnapshot(txns.AppliedConfig, "GigabitEthernet0/0/0/10")).To(gomega.BeNil())
+	nic := interfaceInSnapshot(txns.AppliedConfig, "GigabitEthernet0/0/0/11")
+	gomega.Expect(nic).ToNot(gomega.BeNil())
+	gomega.Expect(nic.IpAddresses).To(gomega.ConsistOf("192.168.2.11/24"))
+	gomega.Expect(server.GetOtherPhysicalIfNames()).To(gomega.ConsistOf("GigabitEthernet0/0/0/11"))
+	gomega.Expect(server.NatExternalTraffic()).To(gomega.BeTrue())
+
+	// invalid configuration is refused
+	invalidConfig := newConfig
+	invalidConfig.Gateway = "invalid"
+	gomega.Expect(server.updateNodeConfig(&invalidConfig)).ToNot(gomega.Succeed())
+	gomega.Expect(server.NatExternalTraffic()).To(gomega.BeTrue())
+
+	// the configuration from the configuration file (none) is applied once removed from ETCD
+	gomega.Expect(server.deleteNodeConfig()).To(gomega.Succeed())
+	gomega.Expect(subscriber).To(gomega.Receive())
+	gomega.Expect(routesViaInSnapshot(txns.AppliedConfig, "192.168.1.200")).To(gomega.BeEmpty())
+	gomega.Expect(server.GetDefaultGatewayIP()).To(gomega.BeNil())
+	gomega.Expect(interfaceInSnapshot(txns.AppliedConfig, "GigabitEthernet0/0/0/11")).To(gomega.BeNil())
+	gomega.Expect(server.GetOtherPhysicalIfNames()).To(gomega.BeEmpty())
+	gomega.Expect(server.NatExternalTraffic()).To(gomega.BeFalse())
+}
+
+func TestHwAddrForVXLAN(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	server, _, _, conn := setupTestCNIServer(&configTapVxlanTCP, nil)
+	defer conn.Disconnect()
+
+	// addresses of nodes with 8-bit IDs are kept unchanged
+	gomega.Expect(server.hwAddrForVXLAN(5)).To(gomega.BeEquivalentTo("1a:2b:3c:4d:5e:05"))
+	gomega.Expect(server.hwAddrForVXLAN(0x1234)).To(gomega.BeEquivalentTo("1a:2b:3c:4d:4c:34"))
+	gomega.Expect(server.hwAddrForVXLAN(0x1234)).NotTo(gomega.BeEquivalentTo(server.hwAddrForVXLAN(0x34)))
 }
 
 func TestVeth1NameFromRequest(t *testing.T) {
@@ -375,7 +2058,7 @@ func TestVeth1NameFromRequest(t *testing.T) {
 		"testlabel",
 		&configVethL2NoTCP,
 		nil,
-		1, nil)
+		1, nil, nil, nil)
 	gomega.Expect(err).To(gomega.BeNil())
 
 	hostIfName := server.veth1HostIfNameFromRequest(&req)
@@ -525,6 +2208,7 @@ func routesViaInSnapshot(snapshot localclient.ConfigSnapshot, nexthopIP string)
 // nodeAddDelEvent simulates addition of a k8s node into a cluster
 type nodeAddDelEvent struct {
 	evType datasync.PutDel
+	nodeID uint32 // ID of the node, otherNodeInfo.Id if not set
 }
 
 func (e *nodeAddDelEvent) Done(error) {}
@@ -534,10 +2218,17 @@ func (e nodeAddDelEvent) GetChangeType() datasync.PutDel {
 }
 
 func (e nodeAddDelEvent) GetKey() string {
-	return AllocatedIDsKeyPrefix
+	if e.nodeID != 0 {
+		return createKey(e.nodeID)
+	}
+	return createKey(otherNodeInfo.Id)
 }
 
 func (e nodeAddDelEvent) GetValue(value proto.Message) error {
+	if e.evType == datasync.Delete {
+		// as with ETCD, the delete event does not carry the value
+		return nil
+	}
 	v := value.(*node.NodeInfo)
 	v.Id = otherNodeInfo.Id
 	v.Name = otherNodeInfo.Name
@@ -554,3 +2245,109 @@ func (e nodeAddDelEvent) GetRevision() int64 {
 	// return revision should be bigger than resync Rev in order to apply the change
 	return 1
 }
+
+// podCIDRBlockEvent simulates claim or release of a pod CIDR block
+type podCIDRBlockEvent struct {
+	evType datasync.PutDel
+	block  *ipamModel.PodCIDRBlock
+}
+
+func (e *podCIDRBlockEvent) Done(error) {}
+
+func (e podCIDRBlockEvent) GetChangeType() datasync.PutDel {
+	return e.evType
+}
+
+func (e podCIDRBlockEvent) GetKey() string {
+	return ipamModel.PodCIDRBlockKey(e.block.Network)
+}
+
+func (e podCIDRBlockEvent) GetValue(value proto.Message) error {
+	if e.evType == datasync.Delete {
+		return nil
+	}
+	proto.Merge(value, e.block)
+	return nil
+}
+
+func (e podCIDRBlockEvent) GetPrevValue(prevValue proto.Message) (prevValueExist bool, err error) {
+	proto.Merge(prevValue, e.block)
+	return true, nil
+}
+
+func (e podCIDRBlockEvent) GetRevision() int64 {
+	return 1
+}
+
+// podCIDRBlockStoreMock keeps the pod CIDR blocks in memory.
+type podCIDRBlockStoreMock struct {
+	blocks map[string]*ipamModel.PodCIDRBlock
+}
+
+func newPodCIDRBlockStoreMock(blocks ...*ipamModel.PodCIDRBlock) *podCIDRBlockStoreMock {
+	m := &podCIDRBlockStoreMock{blocks: map[string]*ipamModel.PodCIDRBlock{}}
+	for _, block := range blocks {
+		m.blocks[block.Network] = block
+	}
+	return m
+}
+
+func (m *podCIDRBlockStoreMock) ListPodCIDRBlocks() ([]*ipamModel.PodCIDRBlock, error) {
+	var blocks []*ipamModel.PodCIDRBlock
+	for _, block := range m.blocks {
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+func (m *podCIDRBlockStoreMock) ClaimPodCIDRBlock(block *ipamModel.PodCIDRBlock) (succeeded bool, err error) {
+	if _, claimed := m.blocks[block.Network]; claimed {
+		return false, nil
+	}
+	m.blocks[block.Network] = block
+	return true, nil
+}
+
+func (m *podCIDRBlockStoreMock) ReleasePodCIDRBlock(network string) error {
+	delete(m.blocks, network)
+	return nil
+}
+
+// egressIPStoreMock keeps the egress IPs in memory.
+type egressIPStoreMock struct {
+	ips map[string]string // address -> policy
+}
+
+func newEgressIPStoreMock() *egressIPStoreMock {
+	return &egressIPStoreMock{ips: map[string]string{}}
+}
+
+func (m *egressIPStoreMock) ListEgressIPs() ([]*egress.EgressIP, error) {
+	var ips []*egress.EgressIP
+	for address, policy := range m.ips {
+		ips = append(ips, &egress.EgressIP{Address: address, Policy: policy})
+	}
+	return ips, nil
+}
+
+func (m *egressIPStoreMock) ClaimEgressIP(ip *egress.EgressIP) (succeeded bool, err error) {
+	if _, claimed := m.ips[ip.Address]; claimed {
+		return false, nil
+	}
+	m.ips[ip.Address] = ip.Policy
+	return true, nil
+}
+
+func (m *egressIPStoreMock) ReleaseEgressIP(address string) error {
+	delete(m.ips, address)
+	return nil
+}
+
+// bfdMonitorMock returns the states of the BFD sessions set by the test.
+type bfdMonitorMock struct {
+	states map[string]bool
+}
+
+func (m *bfdMonitorMock) sessionStates() (map[string]bool, error) {
+	return m.states, nil
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contiv
+
+import (
+	"fmt"
+	"time"
+
+	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
+)
+
+// defaultStalePodCleanupInterval is the interval of the periodic cleanup of stale PODs used if not configured
+const defaultStalePodCleanupInterval = 5 * time.Minute
+
+// stalePodCleanupReport summarizes what was cleaned up by one run of the stale POD cleanup.
+type stalePodCleanupReport struct {
+	removedContainers []string // containers of the deleted PODs disconnected from VPP
+	releasedIPs       []string // container IDs (prefixed with the network for the secondary networks) with released IPs
+	failedContainers  []string // containers which failed to be disconnected, retried by the next run
+}
+
+// empty returns true if nothing was cleaned up.
+func (r *stalePodCleanupReport) empty() bool {
+	return len(r.removedContainers) == 0 && len(r.releasedIPs) == 0 && len(r.failedContainers) == 0
+}
+
+// String returns human-readable representation of the report.
+func (r *stalePodCleanupReport) String() string {
+	return fmt.Sprintf("removed containers: %v, released IPs of: %v, failed containers: %v",
+		r.removedContainers, r.releasedIPs, r.failedContainers)
+}
+
+// stalePodCleanupInterval returns the interval of the periodic cleanup of stale PODs.
+func (s *remoteCNIserver) stalePodCleanupInterval() time.Duration {
+	if s.config.StalePodCleanupInterval != 0 {
+		return time.Duration(s.config.StalePodCleanupInterval) * time.Second
+	}
+	return defaultStalePodCleanupInterval
+}
+
+// periodicStalePodCleanup runs the cleanup of stale PODs periodically until the CNI server is closed.
+func (s *remoteCNIserver) periodicStalePodCleanup() {
+	ticker := time.NewTicker(s.stalePodCleanupInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.Lock()
+			s.cleanupStalePods()
+			s.Unlock()
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// updateLivePod is called when a POD is created or updated in ETCD by KSR.
+func (s *remoteCNIserver) updateLivePod(id podmodel.ID) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.livePods != nil {
+		s.livePods[id] = struct{}{}
+		s.podReflectedCond.Broadcast()
+	}
+}
+
+// deleteLivePod is called when a POD is deleted from ETCD by KSR. The containers of the POD
+// left behind (i.e. not removed by the CNI Delete request) are removed by the next cleanup.
+func (s *remoteCNIserver) deleteLivePod(id podmodel.ID) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.livePods != nil {
+		delete(s.livePods, id)
+	}
+}
+
+// resyncLivePods replaces the set of PODs existing in the cluster and runs the cleanup of stale PODs.
+func (s *remoteCNIserver) resyncLivePods(pods []*podmodel.Pod) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.livePods = map[podmodel.ID]struct{}{}
+	for _, pod := range pods {
+		s.livePods[podmodel.GetID(pod)] = struct{}{}
+	}
+	s.podReflectedCond.Broadcast()
+	s.cleanupStalePods()
+}
+
+// cleanupStalePods reconciles the connected containers and the IPAM allocations with the PODs existing
+// in the cluster. The wiring of a POD deleted without the CNI Delete request (e.g. while the vswitch
+// was down) is removed the same way as by the Delete request and the IP addresses not used by any
+// connected container are released. Containers connected in this run of the vswitch may belong to PODs
+// not yet reflected by KSR, therefore they are removed only if they are found stale by two consecutive runs.
+// The containers are not touched until the PODs are resynced from ETCD, neither are the containers
+// with a CNI request being processed.
+// The method must be called with the CNI server lock held.
+func (s *remoteCNIserver) cleanupStalePods() *stalePodCleanupReport {
+	report := &stalePodCleanupReport{}
+	if s.configuredContainers == nil || !s.vswitchConnectivityConfigured {
+		return report
+	}
+
+	// disconnect the containers of the PODs which no longer exist
+	if s.livePods != nil {
+		staleContainers := map[string]struct{}{}
+		for _, id := range s.configuredContainers.ListAll() {
+			config, found := s.configuredContainers.LookupContainer(id)
+			if !found || config.PodName == "" || s.podInProgress(id) {
+				continue
+			}
+			if _, live := s.livePods[podmodel.ID{Name: config.PodName, Namespace: config.PodNamespace}]; live {
+				continue
+			}
+			if _, wasStale := s.staleContainers[id]; s.configuredInThisRun[id] && !wasStale {
+				staleContainers[id] = struct{}{}
+				continue
+			}
+			s.Logger.Warnf("Removing container %s of the pod %s/%s which no longer exists",
+				id, config.PodNamespace, config.PodName)
+			err := s.removeContainer(config)
+			if err != nil {
+				staleContainers[id] = struct{}{}
+				report.failedContainers = append(report.failedContainers, id)
+				continue
+			}
+			report.removedContainers = append(report.removedContainers, id)
+		}
+		s.staleContainers = staleContainers
+	}
+
+	// release the IP addresses not used by any connected container
+	for _, id := range s.ipam.PodIDs() {
+		if _, connected := s.configuredContainers.LookupContainer(id); connected || s.podInProgress(id) {
+			continue
+		}
+		err := s.ipam.ReleasePodIP(id)
+		if err != nil {
+			s.Logger.Error(err)
+			continue
+		}
+		report.releasedIPs = append(report.releasedIPs, id)
+	}
+	for _, network := range s.ipam.SecondaryNetworks() {
+		ids, err := s.ipam.SecondaryPodIDs(network)
+		if err != nil {
+			s.Logger.Error(err)
+			continue
+		}
+		for _, id := range ids {
+			if _, connected := s.configuredContainers.LookupContainer(id); connected || s.podInProgress(id) {
+				continue
+			}
+			err = s.ipam.ReleaseSecondaryPodIP(network, id)
+			if err != nil {
+				s.Logger.Error(err)
+				continue
+			}
+			report.releasedIPs = append(report.releasedIPs, network+"/"+id)
+		}
+	}
+
+	if !report.empty() {
+		s.Logger.Infof("Stale pods cleaned up: %v", report)
+	}
+	return report
+}
@@ -0,0 +1,600 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contiv
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	nsmodel "github.com/contiv/vpp/plugins/ksr/model/namespace"
+	"github.com/gogo/protobuf/proto"
+	"github.com/ligato/vpp-agent/clientv1/linux"
+	vpp_intf "github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/interfaces"
+	vpp_l2 "github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/l2"
+	vpp_l3 "github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/l3"
+)
+
+const (
+	// DefaultTenantLabel is the default namespace label with the name of the tenant of the namespace.
+	DefaultTenantLabel = "contiv.vpp/tenant"
+
+	// tenantLoopPrefix is the prefix of the name of the loopback holding the gateway IP of a tenant
+	tenantLoopPrefix = "loop-tenant-"
+
+	// tenantBVIPrefix is the prefix of the name of the VXLAN BVI of a tenant
+	tenantBVIPrefix = "vxlanBVI-"
+
+	// tenantBDPrefix is the prefix of the name of the VXLAN bridge domain of a tenant
+	tenantBDPrefix = "vxlanBD-"
+
+	// tenantACLTagPrefix is the prefix of the tags of the ACLs isolating the PODs of a tenant
+	tenantACLTagPrefix = "contiv-tenant-"
+)
+
+// aclTagRegexp matches an ACL in the output of "show acl-plugin acl".
+var aclTagRegexp = regexp.MustCompile(`acl-index (\d+) count \d+ tag \{([^}]*)\}`)
+
+// tenantNetwork is the network of one tenant, routed in its own VRF and interconnected with the other
+// nodes by its own VXLAN tunnels.
+type tenantNetwork struct {
+	TenantConfig
+
+	// subnet is the subnet of the tenant across all nodes
+	subnet *net.IPNet
+
+	// gatewayIP is the gateway IP of the tenant on this node
+	gatewayIP net.IP
+
+	// bd is the VXLAN bridge domain of the tenant, nil until configured
+	bd *vpp_l2.BridgeDomains_BridgeDomain
+
+	// inACL and outACL isolate the PODs of the tenant sharing prefixes with the default VRF,
+	// valid only if isolated is true
+	inACL    uint32
+	outACL   uint32
+	isolated bool
+}
+
+// initTenants validates the configuration of the tenants.
+func (s *remoteCNIserver) initTenants() error {
+	s.tenants = map[string]*tenantNetwork{}
+	s.tenantLabel = s.config.TenantLabel
+	if s.tenantLabel == "" {
+		s.tenantLabel = DefaultTenantLabel
+	}
+	if len(s.config.Tenants) == 0 {
+		return nil
+	}
+	if _, isVxlan := s.overlay.(*vxlanOverlay); !isVxlan {
+		return fmt.Errorf("tenants are supported only with the VXLAN overlay")
+	}
+
+	// VRFs and VNIs must not be shared, the routes between the leaking VRFs must be unambiguous
+	vrfs := map[uint32]string{0: "the pod network"}
+	for _, network := range s.secondaryNetworks {
+		vrfs[network.VrfID] = "the secondary network " + network.Name
+	}
+	vnis := map[uint32]string{vxlanVNI: "the pod network"}
+	leaking := []*net.IPNet{s.ipam.PodSubnet()}
+
+	for _, config := range s.config.Tenants {
+		if config.Name == "" {
+			return fmt.Errorf("name of a tenant is not set")
+		}
+		if _, duplicate := s.tenants[config.Name]; duplicate {
+			return fmt.Errorf("tenant %v is configured more than once", config.Name)
+		}
+		if _, isNetwork := s.secondaryNetworks[config.Name]; isNetwork {
+			return fmt.Errorf("tenant %v conflicts with the secondary network of the same name", config.Name)
+		}
+		subnet, err := s.ipam.SecondaryPodSubnet(config.Name)
+		if err != nil {
+			return fmt.Errorf("address pool of the tenant %v is not configured: %v", config.Name, err)
+		}
+		gw, err := s.ipam.SecondaryPodGatewayIP(config.Name)
+		if err != nil {
+			return err
+		}
+		if owner, used := vrfs[config.VrfID]; used {
+			return fmt.Errorf("VRF %v of the tenant %v is already used by %v", config.VrfID, config.Name, owner)
+		}
+		if config.Vni == 0 {
+			return fmt.Errorf("VNI of the tenant %v is not set", config.Name)
+		}
+		if owner, used := vnis[config.Vni]; used {
+			return fmt.Errorf("VNI %v of the tenant %v is already used by %v", config.Vni, config.Name, owner)
+		}
+		for _, prefix := range config.SharedPrefixes {
+			_, network, err := net.ParseCIDR(prefix)
+			if err != nil || network.IP.To4() == nil {
+				return fmt.Errorf("invalid shared prefix %q of the tenant %v", prefix, config.Name)
+			}
+			// the services are not rendered in the VRFs of the tenants, a cluster IP would not be translated
+			if services := s.ipam.ServiceNetwork(); services.Contains(network.IP) || network.Contains(services.IP) {
+				return fmt.Errorf("shared prefix %v of the tenant %v overlaps with the service network %v, "+
+					"services are not NATed for the tenants, share the addresses of the endpoints instead",
+					prefix, config.Name, services)
+			}
+		}
+		if len(config.SharedPrefixes) > 0 {
+			for _, network := range leaking {
+				if network.Contains(subnet.IP) || subnet.Contains(network.IP) {
+					return fmt.Errorf("subnet %v of the tenant %v with shared prefixes overlaps with %v", subnet, config.Name, network)
+				}
+			}
+			leaking = append(leaking, subnet)
+		}
+		vrfs[config.VrfID] = "the tenant " + config.Name
+		vnis[config.Vni] = "the tenant " + config.Name
+		s.tenants[config.Name] = &tenantNetwork{
+			TenantConfig: config,
+			subnet:       subnet,
+			gatewayIP:    gw,
+		}
+	}
+	return nil
+}
+
+// sortedTenants returns the tenant networks ordered by the name.
+func (s *remoteCNIserver) sortedTenants() []*tenantNetwork {
+	var tenants []*tenantNetwork
+	for _, tenant := range s.tenants {
+		tenants = append(tenants, tenant)
+	}
+	sort.Slice(tenants, func(i, j int) bool { return tenants[i].Name < tenants[j].Name })
+	return tenants
+}
+
+// updateNamespace updates the tenant of the namespace from its labels.
+func (s *remoteCNIserver) updateNamespace(namespace *nsmodel.Namespace) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.namespaceTenants == nil {
+		// not resynced yet
+		return
+	}
+	s.setNamespaceTenant(namespace)
+}
+
+// deleteNamespace forgets the tenant of the deleted namespace.
+func (s *remoteCNIserver) deleteNamespace(name string) {
+	s.Lock()
+	defer s.Unlock()
+
+	delete(s.namespaceTenants, name)
+}
+
+// resyncNamespaces replaces the tenants of all namespaces.
+func (s *remoteCNIserver) resyncNamespaces(namespaces []*nsmodel.Namespace) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.namespaceTenants = map[string]string{}
+	for _, namespace := range namespaces {
+		s.setNamespaceTenant(namespace)
+	}
+}
+
+// setNamespaceTenant stores the tenant of the namespace given by the tenant label. The PODs already
+// connected are not moved, a change of the tenant takes effect for the PODs connected after the change.
+func (s *remoteCNIserver) setNamespaceTenant(namespace *nsmodel.Namespace) {
+	tenant := ""
+	for _, label := range namespace.Label {
+		if label.Key == s.tenantLabel {
+			tenant = label.Value
+		}
+	}
+	if _, known := s.tenants[tenant]; tenant != "" && !known {
+		s.Logger.Warnf("Namespace %v refers to the unknown tenant %q, its pods are connected to the pod network",
+			namespace.Name, tenant)
+		tenant = ""
+	}
+	if previous, reflected := s.namespaceTenants[namespace.Name]; reflected && previous != tenant {
+		s.Logger.Warnf("Tenant of the namespace %v changed from %q to %q, the pods connected before the change "+
+			"must be re-created", namespace.Name, previous, tenant)
+	}
+	s.namespaceTenants[namespace.Name] = tenant
+}
+
+// GetNamespaceTenant returns the name of the tenant whose network connects the PODs of the given namespace.
+// Returns an empty string for the namespaces connected to the pod network.
+func (s *remoteCNIserver) GetNamespaceTenant(namespace string) string {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.namespaceTenants[namespace]
+}
+
+// podTenant returns the tenant network of the PODs in the given namespace, nil for the pod network.
+// If some tenant is configured, the PODs are not connected until their namespace is reflected,
+// otherwise the PODs of a tenant could end up in the pod network.
+func (s *remoteCNIserver) podTenant(namespace string) (*tenantNetwork, error) {
+	if len(s.tenants) == 0 || namespace == "" {
+		return nil, nil
+	}
+	tenant, reflected := s.namespaceTenants[namespace]
+	if !reflected {
+		return nil, fmt.Errorf("namespace %v is not reflected yet, the tenant of the pod is not known", namespace)
+	}
+	return s.tenants[tenant], nil
+}
+
+// tenantVrf returns the VRF of the PODs of the tenant (VRF 0 for the pod network).
+func (s *remoteCNIserver) tenantVrf(tenant string) uint32 {
+	if network, isTenant := s.tenants[tenant]; isTenant {
+		return network.VrfID
+	}
+	return 0
+}
+
+// podGatewayIP returns the gateway IP of the PODs of the tenant (or of the pod network).
+func (s *remoteCNIserver) podGatewayIP(tenant string) net.IP {
+	if network, isTenant := s.tenants[tenant]; isTenant {
+		return network.gatewayIP
+	}
+	return s.ipam.PodGatewayIP()
+}
+
+// podIP returns the IP address assigned to the POD from the pool of the tenant (or of the pod network).
+func (s *remoteCNIserver) podIP(podID string, tenant string) net.IP {
+	if tenant == "" {
+		return s.ipam.PodIP(podID)
+	}
+	ip, err := s.ipam.SecondaryPodIP(tenant, podID)
+	if err != nil {
+		s.Logger.Warn(err)
+	}
+	return ip
+}
+
+// releasePodIP releases the IP address assigned to the POD from the pool of the tenant (or of the pod network).
+func (s *remoteCNIserver) releasePodIP(podID string, tenant string) error {
+	if tenant == "" {
+		return s.ipam.ReleasePodIP(podID)
+	}
+	return s.ipam.ReleaseSecondaryPodIP(tenant, podID)
+}
+
+// tenantPodInterface moves the VPP end of the POD interface into the VRF of the tenant of the POD.
+// The interface is unnumbered with the loopback holding the gateway IP of the tenant.
+func (s *remoteCNIserver) tenantPodInterface(config *PodConfig) {
+	tenant, isTenant := s.tenants[config.Tenant]
+	if !isTenant {
+		return
+	}
+	config.VppIf.Vrf = tenant.VrfID
+	config.VppIf.Unnumbered = &vpp_intf.Interfaces_Interface_Unnumbered{
+		IsUnnumbered:    true,
+		InterfaceWithIP: tenantLoopPrefix + tenant.Name,
+	}
+}
+
+// tenantLoop returns the loopback holding the gateway IP of the tenant on this node.
+func (s *remoteCNIserver) tenantLoop(tenant *tenantNetwork) (*vpp_intf.Interfaces_Interface, error) {
+	podNetwork, err := s.ipam.SecondaryPodNetwork(tenant.Name)
+	if err != nil {
+		return nil, err
+	}
+	prefixLen, _ := podNetwork.Mask.Size()
+	return &vpp_intf.Interfaces_Interface{
+		Name:        tenantLoopPrefix + tenant.Name,
+		Type:        vpp_intf.InterfaceType_SOFTWARE_LOOPBACK,
+		Enabled:     true,
+		Vrf:         tenant.VrfID,
+		IpAddresses: []string{fmt.Sprintf("%s/%d", tenant.gatewayIP.String(), prefixLen)},
+	}, nil
+}
+
+// tenantBVI returns the VXLAN BVI of the tenant. It has the same IPv4 address as the VXLAN BVI
+// of the pod network, in the VRF of the tenant.
+func (s *remoteCNIserver) tenantBVI(tenant *tenantNetwork) (*vpp_intf.Interfaces_Interface, error) {
+	vxlanIP, err := s.ipam.VxlanIPWithPrefix(s.ipam.NodeID())
+	if err != nil {
+		return nil, err
+	}
+	return &vpp_intf.Interfaces_Interface{
+		Name:        tenantBVIPrefix + tenant.Name,
+		Type:        vpp_intf.InterfaceType_SOFTWARE_LOOPBACK,
+		Enabled:     true,
+		Vrf:         tenant.VrfID,
+		IpAddresses: []string{vxlanIP.String()},
+		PhysAddress: s.hwAddrForVXLAN(s.ipam.NodeID()),
+	}, nil
+}
+
+// tenantVxlanToHost returns the VXLAN tunnel of the tenant towards the given host.
+func (s *remoteCNIserver) tenantVxlanToHost(tenant *tenantNetwork, hostID uint32, hostIP string) (*vpp_intf.Interfaces_Interface, error) {
+	vxlanIf, err := s.computeVxlanToHost(hostID, hostIP)
+	if err != nil {
+		return nil, err
+	}
+	vxlanIf.Name = fmt.Sprintf("vxlan%d-%s", hostID, tenant.Name)
+	vxlanIf.Vxlan.Vni = tenant.Vni
+	return vxlanIf, nil
+}
+
+// tenantRouteToOtherHostPods returns the route to the PODs of the tenant on the given host
+// via the VXLAN BVI of the tenant on the host.
+func (s *remoteCNIserver) tenantRouteToOtherHostPods(tenant *tenantNetwork, hostID uint32) (*vpp_l3.StaticRoutes_Route, error) {
+	podNetwork, err := s.ipam.SecondaryOtherNodePodNetwork(tenant.Name, hostID)
+	if err != nil {
+		return nil, err
+	}
+	vxlanIP, err := s.ipam.VxlanIPAddress(hostID)
+	if err != nil {
+		return nil, err
+	}
+	return &vpp_l3.StaticRoutes_Route{
+		VrfId:       tenant.VrfID,
+		DstIpAddr:   podNetwork.String(),
+		NextHopAddr: vxlanIP.String(),
+	}, nil
+}
+
+// configureTenants configures the loopbacks with the gateway IPs, the VXLAN BVIs and the VXLAN bridge domains
+// of the tenants, together with the routes leaking the shared prefixes between the VRFs of the tenants
+// and the default VRF.
+func (s *remoteCNIserver) configureTenants(config *vswitchConfig) error {
+	if len(s.tenants) == 0 {
+		return nil
+	}
+
+	txn := s.vppTxnFactory().Put()
+	for _, tenant := range s.sortedTenants() {
+		loop, err := s.tenantLoop(tenant)
+		if err != nil {
+			return err
+		}
+		bvi, err := s.tenantBVI(tenant)
+		if err != nil {
+			return err
+		}
+		tenant.bd = s.vxlanBridgeDomain(bvi.Name)
+		tenant.bd.Name = tenantBDPrefix + tenant.Name
+
+		// pass deep copy to local client since the BD is reconfigured when a node joins the cluster
+		txn.VppInterface(loop).
+			VppInterface(bvi).
+			BD(proto.Clone(tenant.bd).(*vpp_l2.BridgeDomains_BridgeDomain))
+		config.overlayIfs = append(config.overlayIfs, loop, bvi)
+		config.overlayBDs = append(config.overlayBDs, tenant.bd)
+	}
+	if !config.configured {
+		err := txn.Send().ReceiveReply()
+		if err != nil {
+			return err
+		}
+	}
+
+	// the VPP agent does not support routes resolved in another VRF, the leaks are configured via VPP CLI
+	var err error
+	for _, tenant := range s.sortedTenants() {
+		if len(tenant.SharedPrefixes) == 0 {
+			continue
+		}
+		err = s.configureTenantIsolation(tenant)
+		if err != nil {
+			return err
+		}
+		if config.configured {
+			// VPP was not restarted, the leaks are still configured
+			continue
+		}
+		for _, prefix := range tenant.SharedPrefixes {
+			err = s.cli.cli(fmt.Sprintf("ip route add %s table %d via ip4-lookup-in-table 0", prefix, tenant.VrfID))
+			if err != nil {
+				return err
+			}
+		}
+		// the return traffic, the traffic initiated from the default VRF is dropped by the isolation ACLs
+		err = s.cli.cli(fmt.Sprintf("ip route add %s via ip4-lookup-in-table %d", tenant.subnet, tenant.VrfID))
+		if err != nil {
+			return err
+		}
+		s.Logger.Infof("Prefixes %v shared with the tenant %v", tenant.SharedPrefixes, tenant.Name)
+	}
+	return nil
+}
+
+// configureTenantIsolation creates (or finds after a restart of the agent) the ACLs isolating the PODs
+// of the tenant from the default VRF. The route of the subnet of the tenant in the default VRF makes the PODs
+// reachable from the whole pod network, the ACLs therefore permit the traffic towards the PODs of the tenant
+// only from the subnet of the tenant, or as a reply to the traffic initiated by the PODs (reflected sessions).
+func (s *remoteCNIserver) configureTenantIsolation(tenant *tenantNetwork) error {
+	var err error
+	tenant.inACL, err = s.tenantACL(tenantACLTagPrefix+tenant.Name+"-in",
+		"permit+reflect src 0.0.0.0/0 dst 0.0.0.0/0")
+	if err != nil {
+		return err
+	}
+	tenant.outACL, err = s.tenantACL(tenantACLTagPrefix+tenant.Name+"-out",
+		fmt.Sprintf("permit src %s dst 0.0.0.0/0, deny src 0.0.0.0/0 dst 0.0.0.0/0", tenant.subnet))
+	if err != nil {
+		return err
+	}
+	tenant.isolated = true
+	return nil
+}
+
+// tenantACL returns the index of the ACL with the given tag, the ACL is created with the given rules
+// if it does not exist yet. The ACLs are configured via VPP CLI, as the ACLs of the egress policies.
+func (s *remoteCNIserver) tenantACL(tag string, rules string) (uint32, error) {
+	out, err := s.cli.cliOutput("show acl-plugin acl")
+	if err != nil {
+		return 0, err
+	}
+	for _, match := range aclTagRegexp.FindAllStringSubmatch(out, -1) {
+		if match[2] == tag {
+			aclIdx, _ := strconv.ParseUint(match[1], 10, 32)
+			return uint32(aclIdx), nil
+		}
+	}
+	out, err = s.cli.cliOutput(fmt.Sprintf("set acl-plugin acl %s tag %s", rules, tag))
+	if err != nil {
+		return 0, err
+	}
+	match := aclIndexRegexp.FindStringSubmatch(out)
+	if match == nil {
+		return 0, fmt.Errorf("can't create ACL %s: %s", tag, strings.TrimSpace(out))
+	}
+	aclIdx, _ := strconv.ParseUint(match[1], 10, 32)
+	return uint32(aclIdx), nil
+}
+
+// isolateTenantPod attaches the isolation ACLs of the tenant to the VPP end of the POD interface.
+// With reattach set, the ACLs possibly attached before a restart of the agent are detached first.
+// The method must be called with the CNI server lock held.
+func (s *remoteCNIserver) isolateTenantPod(tenantName string, vppIfName string, reattach bool) error {
+	tenant, isTenant := s.tenants[tenantName]
+	if !isTenant || !tenant.isolated {
+		return nil
+	}
+	ifName, err := s.cli.internalIfName(vppIfName)
+	if err != nil {
+		return err
+	}
+	if reattach {
+		s.cli.cli(fmt.Sprintf("set acl-plugin interface %s input acl %d del", ifName, tenant.inACL))
+		s.cli.cli(fmt.Sprintf("set acl-plugin interface %s output acl %d del", ifName, tenant.outACL))
+	}
+	err = s.cli.cli(fmt.Sprintf("set acl-plugin interface %s input acl %d", ifName, tenant.inACL))
+	if err != nil {
+		return err
+	}
+	return s.cli.cli(fmt.Sprintf("set acl-plugin interface %s output acl %d", ifName, tenant.outACL))
+}
+
+// releaseTenantPod detaches the isolation ACLs of the tenant from the interface of the POD being removed.
+// The method must be called with the CNI server lock held.
+func (s *remoteCNIserver) releaseTenantPod(tenantName string, vppIfName string) error {
+	tenant, isTenant := s.tenants[tenantName]
+	if !isTenant || !tenant.isolated || vppIfName == "" {
+		return nil
+	}
+	ifName, err := s.cli.internalIfName(vppIfName)
+	if err != nil {
+		return err
+	}
+	err = s.cli.cli(fmt.Sprintf("set acl-plugin interface %s input acl %d del", ifName, tenant.inACL))
+	if err != nil {
+		return err
+	}
+	return s.cli.cli(fmt.Sprintf("set acl-plugin interface %s output acl %d del", ifName, tenant.outACL))
+}
+
+// isolateTenantPods re-attaches the isolation ACLs to the interfaces of the tenant PODs connected
+// before the restart. The method must be called with the CNI server lock held.
+func (s *remoteCNIserver) isolateTenantPods() {
+	if s.configuredContainers == nil {
+		return
+	}
+	for _, id := range s.configuredContainers.ListAll() {
+		config, found := s.configuredContainers.LookupContainer(id)
+		if !found || config.Tenant == "" {
+			continue
+		}
+		err := s.isolateTenantPod(config.Tenant, config.VppIfName, true)
+		if err != nil {
+			s.Logger.Error(err)
+		}
+	}
+}
+
+// connectTenants adds the VXLAN tunnels of the tenants towards the given host into the transaction, together
+// with the static ARP entries for the VXLAN BVIs of the tenants on the host and the routes to the PODs
+// of the tenants on the host.
+func (s *remoteCNIserver) connectTenants(txn linux.PutDSL, hostID uint32, hostIP string) error {
+	for _, tenant := range s.sortedTenants() {
+		vxlanIf, err := s.tenantVxlanToHost(tenant, hostID, hostIP)
+		if err != nil {
+			return err
+		}
+		txn.VppInterface(vxlanIf)
+		if !vxlanBDHasInterface(tenant.bd, vxlanIf.Name) {
+			s.addInterfaceToVxlanBD(tenant.bd, vxlanIf.Name)
+		}
+		txn.BD(proto.Clone(tenant.bd).(*vpp_l2.BridgeDomains_BridgeDomain))
+
+		vxlanIP, err := s.ipam.VxlanIPAddress(hostID)
+		if err != nil {
+			return err
+		}
+		arp := s.vxlanArpEntry(hostID, vxlanIP.String())
+		arp.Interface = tenantBVIPrefix + tenant.Name
+		txn.Arp(arp)
+
+		route, err := s.tenantRouteToOtherHostPods(tenant, hostID)
+		if err != nil {
+			return err
+		}
+		txn.StaticRoute(route)
+	}
+	return nil
+}
+
+// disconnectTenants removes the routes to the PODs of the tenants on the given host, the VXLAN tunnels
+// of the tenants towards the host and the static ARP entries.
+func (s *remoteCNIserver) disconnectTenants(hostID uint32) error {
+	var connected []*tenantNetwork
+	for _, tenant := range s.sortedTenants() {
+		vxlanIf, err := s.tenantVxlanToHost(tenant, hostID, "")
+		if err != nil {
+			return err
+		}
+		if tenant.bd != nil && vxlanBDHasInterface(tenant.bd, vxlanIf.Name) {
+			connected = append(connected, tenant)
+		}
+	}
+	if len(connected) == 0 {
+		return nil
+	}
+	vxlanIP, err := s.ipam.VxlanIPAddress(hostID)
+	if err != nil {
+		return err
+	}
+
+	// the routes and the tunnels in the bridge domains must be removed before the tunnels
+	routesTxn := s.vppTxnFactory().Delete()
+	bdTxn := s.vppTxnFactory().Put()
+	tunnelsTxn := s.vppTxnFactory().Delete()
+	for _, tenant := range connected {
+		route, err := s.tenantRouteToOtherHostPods(tenant, hostID)
+		if err != nil {
+			return err
+		}
+		routesTxn.StaticRoute(route.VrfId, route.DstIpAddr, route.NextHopAddr)
+
+		vxlanIf, _ := s.tenantVxlanToHost(tenant, hostID, "")
+		removeInterfaceFromVxlanBD(tenant.bd, vxlanIf.Name)
+		bdTxn.BD(proto.Clone(tenant.bd).(*vpp_l2.BridgeDomains_BridgeDomain))
+
+		tunnelsTxn.VppInterface(vxlanIf.Name).
+			Arp(tenantBVIPrefix+tenant.Name, vxlanIP.String())
+	}
+	err = routesTxn.Send().ReceiveReply()
+	if err != nil {
+		return err
+	}
+	err = bdTxn.Send().ReceiveReply()
+	if err != nil {
+		return err
+	}
+	return tunnelsTxn.Send().ReceiveReply()
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contiv
+
+import (
+	"sync"
+
+	"github.com/ligato/vpp-agent/clientv1/linux"
+)
+
+// txnBatcher merges the VPP/linux transactions of the PODs wired concurrently into a single
+// local client transaction (group commit). The first caller becomes the leader, which sends
+// the transactions queued while the previous batch was being applied. A caller wiring PODs
+// one by one therefore still sends exactly one transaction per commit.
+//
+// The operations of a single commit are always sent within the same transaction. If a batch
+// of multiple commits fails, the commits are re-sent one by one, so that the error is reported
+// only to the PODs whose configuration actually failed.
+type txnBatcher struct {
+	txnFactory func() linux.DataChangeDSL
+
+	sync.Mutex
+	queue   []*batchedTxn
+	sending bool
+}
+
+// batchedTxn is a single commit waiting in the queue of the batcher.
+type batchedTxn struct {
+	fill func(txn linux.DataChangeDSL)
+	done chan error
+}
+
+// newTxnBatcher returns new instance of txnBatcher.
+func newTxnBatcher(txnFactory func() linux.DataChangeDSL) *txnBatcher {
+	return &txnBatcher{txnFactory: txnFactory}
+}
+
+// commit adds the operations filled into the transaction by <fill> into the next batch
+// and waits until the batch is applied.
+func (b *txnBatcher) commit(fill func(txn linux.DataChangeDSL)) error {
+	batched := &batchedTxn{fill: fill, done: make(chan error, 1)}
+
+	b.Lock()
+	b.queue = append(b.queue, batched)
+	if b.sending {
+		// the leader sends the commit with the next batch
+		b.Unlock()
+		return <-batched.done
+	}
+	b.sending = true
+	for len(b.queue) > 0 {
+		batch := b.queue
+		b.queue = nil
+		b.Unlock()
+		b.send(batch)
+		b.Lock()
+	}
+	b.sending = false
+	b.Unlock()
+
+	return <-batched.done
+}
+
+// send applies the given batch of commits and reports the results to the waiting callers.
+func (b *txnBatcher) send(batch []*batchedTxn) {
+	txn := b.txnFactory()
+	for _, batched := range batch {
+		batched.fill(txn)
+	}
+	err := txn.Send().ReceiveReply()
+	if err == nil || len(batch) == 1 {
+		for _, batched := range batch {
+			batched.done <- err
+		}
+		return
+	}
+
+	// the failed commit is unknown, re-send one by one
+	for _, batched := range batch {
+		txn := b.txnFactory()
+		batched.fill(txn)
+		batched.done <- txn.Send().ReceiveReply()
+	}
+}
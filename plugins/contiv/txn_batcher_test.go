@@ -0,0 +1,97 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contiv
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/onsi/gomega"
+
+	"github.com/contiv/vpp/mock/localclient"
+	"github.com/ligato/vpp-agent/clientv1/linux"
+	vpp_intf "github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/interfaces"
+)
+
+func TestTxnBatcher(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	var (
+		blockFirst = true
+		committing = make(chan struct{})
+		release    = make(chan struct{})
+	)
+	txns := localclient.NewTxnTracker(func(txn *localclient.Txn) error {
+		if blockFirst {
+			// block the first transaction to let the other commits queue up
+			blockFirst = false
+			close(committing)
+			<-release
+		}
+		for _, op := range txn.LinuxDataChangeTxn.Ops {
+			if op.Key == vpp_intf.InterfaceKey("failing") {
+				return errors.New("interface failed")
+			}
+		}
+		return nil
+	})
+	batcher := newTxnBatcher(txns.NewLinuxDataChangeTxn)
+	putInterface := func(name string) func(txn linux.DataChangeDSL) {
+		return func(txn linux.DataChangeDSL) {
+			txn.Put().VppInterface(&vpp_intf.Interfaces_Interface{Name: name})
+		}
+	}
+	queueLen := func() int {
+		batcher.Lock()
+		defer batcher.Unlock()
+		return len(batcher.queue)
+	}
+
+	// the commits queued while the first one is being sent are merged into one transaction
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		errs[0] = batcher.commit(putInterface("if0"))
+	}()
+	<-committing
+	for i, name := range []string{"if1", "if2", "failing"} {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			errs[i+1] = batcher.commit(putInterface(name))
+		}(i, name)
+	}
+	gomega.Eventually(queueLen).Should(gomega.Equal(3))
+	close(release)
+	wg.Wait()
+
+	// the failed batch is re-sent one by one, only the failed commit reports the error
+	gomega.Expect(errs[0]).To(gomega.BeNil())
+	gomega.Expect(errs[1]).To(gomega.BeNil())
+	gomega.Expect(errs[2]).To(gomega.BeNil())
+	gomega.Expect(errs[3]).NotTo(gomega.BeNil())
+	gomega.Expect(txns.CommittedTxns).To(gomega.HaveLen(5))
+	gomega.Expect(txns.CommittedTxns[1].LinuxDataChangeTxn.Ops).To(gomega.HaveLen(3))
+	gomega.Expect(txns.AppliedConfig).To(gomega.HaveKey(vpp_intf.InterfaceKey("if2")))
+
+	// a single commit is sent in its own transaction
+	txns.Clear()
+	err := batcher.commit(putInterface("if3"))
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(txns.CommittedTxns).To(gomega.HaveLen(1))
+}
@@ -0,0 +1,298 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contiv
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"git.fd.io/govpp.git/api"
+	"github.com/gogo/protobuf/proto"
+	bfd_api "github.com/ligato/vpp-agent/plugins/defaultplugins/common/bin_api/bfd"
+	vpp_bfd "github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/bfd"
+	vpp_l3 "github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/l3"
+)
+
+const (
+	// defaultBFDInterval is the default interval of the BFD control packets in milliseconds
+	defaultBFDInterval = 300
+
+	// defaultBFDDetectMultiplier is the default number of missed BFD control packets after which the uplink is down
+	defaultBFDDetectMultiplier = 3
+
+	// bfdStateUp is the state of an established BFD session (RFC 5880)
+	bfdStateUp = 3
+)
+
+// UplinkConfig represents configuration of one uplink of the node, i.e. of a physical VPP interface
+// connecting the node to a gateway of the fabric (e.g. a ToR switch). The traffic leaving the node
+// (including the tunnels towards the other nodes) is balanced over all live uplinks by ECMP default routes.
+type UplinkConfig struct {
+	InterfaceName string // name of the VPP interface
+	IP            string // IP address of the interface with the prefix length
+	Gateway       string // IP address of the gateway in the subnet of the interface
+}
+
+// BFDConfig represents configuration of the BFD sessions detecting the failures of the uplinks.
+type BFDConfig struct {
+	Enabled          bool   // enable BFD sessions with the gateways of the uplinks
+	Interval         uint32 // interval of the BFD control packets in milliseconds (default 300)
+	DetectMultiplier uint32 // number of missed control packets after which the uplink is down (default 3)
+}
+
+// uplink is one uplink of the node together with its state.
+type uplink struct {
+	UplinkConfig
+
+	// live is false while the BFD session with the gateway is down, the default route via the uplink
+	// is withdrawn meanwhile
+	live bool
+
+	// checked is true once the state of the BFD session was applied, the route may have been withdrawn
+	// in the previous run of the agent
+	checked bool
+}
+
+// bfdMonitor reads the states of the BFD sessions.
+type bfdMonitor interface {
+	// sessionStates returns the liveness of the BFD sessions, by the IP address of the peer.
+	sessionStates() (map[string]bool, error)
+}
+
+// vppBFDMonitor reads the states of the VPP BFD sessions over the binary API.
+type vppBFDMonitor struct {
+	govppChan *api.Channel
+}
+
+// sessionStates dumps the BFD sessions configured in VPP.
+func (m *vppBFDMonitor) sessionStates() (map[string]bool, error) {
+	states := map[string]bool{}
+	reqCtx := m.govppChan.SendMultiRequest(&bfd_api.BfdUDPSessionDump{})
+	for {
+		msg := &bfd_api.BfdUDPSessionDetails{}
+		stop, err := reqCtx.ReceiveReply(msg)
+		if stop {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		peer := net.IP(msg.PeerAddr)
+		if msg.IsIpv6 == 0 {
+			peer = peer[:net.IPv4len]
+		}
+		states[peer.String()] = msg.State == bfdStateUp
+	}
+	return states, nil
+}
+
+// initUplinks validates the uplinks of this node.
+func (s *remoteCNIserver) initUplinks() error {
+	if s.nodeConfig == nil || len(s.nodeConfig.Uplinks) == 0 {
+		return nil
+	}
+	if _, isL2 := s.overlay.(*l2Overlay); isL2 {
+		return fmt.Errorf("uplinks are not supported with the L2 overlay")
+	}
+	if s.nodeConfig.MainVPPInterface.InterfaceName != "" || s.nodeConfig.MainVPPInterface.UseDHCP ||
+		s.nodeConfig.Gateway != "" {
+		return fmt.Errorf("the node IP of the node with uplinks is configured on a loopback, " +
+			"MainVPPInterface.InterfaceName, MainVPPInterface.UseDHCP and Gateway must not be set")
+	}
+	if s.config.StealFirstNIC || s.config.StealInterface != "" || s.nodeConfig.StealInterface != "" {
+		return fmt.Errorf("uplinks are not supported with STN")
+	}
+	if s.config.NatExternalTraffic || s.nodeConfig.NatExternalTraffic {
+		return fmt.Errorf("NatExternalTraffic is not supported with uplinks")
+	}
+	gateways := map[string]struct{}{}
+	for _, config := range s.nodeConfig.Uplinks {
+		ip, network, err := net.ParseCIDR(config.IP)
+		if err != nil || ip.To4() == nil {
+			return fmt.Errorf("invalid IP address %q of the uplink %s", config.IP, config.InterfaceName)
+		}
+		gw := net.ParseIP(config.Gateway)
+		if config.InterfaceName == "" || gw == nil || !network.Contains(gw) {
+			return fmt.Errorf("invalid gateway %q of the uplink %s", config.Gateway, config.InterfaceName)
+		}
+		if _, duplicate := gateways[gw.String()]; duplicate {
+			return fmt.Errorf("gateway %s is used by more than one uplink", gw)
+		}
+		gateways[gw.String()] = struct{}{}
+		s.uplinks = append(s.uplinks, &uplink{UplinkConfig: config, live: true})
+	}
+	if s.config.UplinkBFD.Enabled {
+		s.bfd = &vppBFDMonitor{govppChan: s.govppChan}
+	}
+	return nil
+}
+
+// configureUplinks configures the uplink interfaces, the ECMP default routes via their gateways
+// and the BFD sessions with the gateways.
+func (s *remoteCNIserver) configureUplinks(config *vswitchConfig) error {
+	txn := s.vppTxnFactory().Put()
+	for _, uplink := range s.uplinks {
+		nic := s.physicalInterface(uplink.InterfaceName, uplink.IP)
+		txn.VppInterface(nic)
+		config.nics = append(config.nics, nic)
+		s.otherPhysicalIfs = append(s.otherPhysicalIfs, nic.Name)
+
+		route := s.uplinkRoute(uplink)
+		txn.StaticRoute(route)
+		config.uplinkRoutes = append(config.uplinkRoutes, route)
+	}
+	if !config.configured {
+		err := txn.Send().ReceiveReply()
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.bfd == nil {
+		return nil
+	}
+	// the BFD sessions are configured once the interfaces have their IP addresses
+	txn = s.vppTxnFactory().Put()
+	for _, uplink := range s.uplinks {
+		session := s.uplinkBFDSession(uplink)
+		txn.BfdSession(session)
+		config.bfdSessions = append(config.bfdSessions, session)
+	}
+	if !config.configured {
+		return txn.Send().ReceiveReply()
+	}
+	return nil
+}
+
+// uplinkBFDSession returns the BFD session with the gateway of the uplink.
+func (s *remoteCNIserver) uplinkBFDSession(uplink *uplink) *vpp_bfd.SingleHopBFD_Session {
+	interval := s.config.UplinkBFD.Interval
+	if interval == 0 {
+		interval = defaultBFDInterval
+	}
+	multiplier := s.config.UplinkBFD.DetectMultiplier
+	if multiplier == 0 {
+		multiplier = defaultBFDDetectMultiplier
+	}
+	return &vpp_bfd.SingleHopBFD_Session{
+		Interface:             uplink.InterfaceName,
+		SourceAddress:         s.ipPrefixToAddress(uplink.IP),
+		DestinationAddress:    uplink.Gateway,
+		Enabled:               true,
+		DesiredMinTxInterval:  interval * 1000,
+		RequiredMinRxInterval: interval * 1000,
+		DetectMultiplier:      multiplier,
+	}
+}
+
+// uplinkRoute returns the default route via the uplink.
+func (s *remoteCNIserver) uplinkRoute(uplink *uplink) *vpp_l3.StaticRoutes_Route {
+	return s.defaultRoute(uplink.Gateway, uplink.InterfaceName)
+}
+
+// periodicUplinkCheck checks the states of the BFD sessions of the uplinks until the CNI server is closed.
+func (s *remoteCNIserver) periodicUplinkCheck() {
+	if s.bfd == nil {
+		return
+	}
+	interval := s.config.UplinkBFD.Interval
+	if interval == 0 {
+		interval = defaultBFDInterval
+	}
+	ticker := time.NewTicker(time.Duration(interval) * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.Lock()
+			if s.vswitchConnectivityConfigured {
+				err := s.checkUplinks()
+				if err != nil {
+					s.Logger.Warn(err)
+				}
+			}
+			s.Unlock()
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// checkUplinks withdraws the default routes via the uplinks with the BFD session down and restores
+// the routes via the uplinks with the BFD session up again. The routes via the last live uplink
+// are never withdrawn, so that the node is not cut off by a failure of the BFD itself.
+// The method must be called with the CNI server lock held.
+func (s *remoteCNIserver) checkUplinks() error {
+	states, err := s.bfd.sessionStates()
+	if err != nil {
+		return err
+	}
+	var wasErr error
+	for _, uplink := range s.uplinks {
+		up, found := states[uplink.Gateway]
+		if !found || (uplink.checked && up == uplink.live) {
+			// the session is not configured yet or the state has not changed
+			continue
+		}
+		if !up && s.liveUplinks() == 1 {
+			s.Logger.Warnf("BFD session of the last live uplink %s is down, keeping the route", uplink.InterfaceName)
+			continue
+		}
+		if err := s.setUplinkLive(uplink, up); err != nil {
+			wasErr = err
+		}
+	}
+	return wasErr
+}
+
+// liveUplinks returns the number of live uplinks.
+func (s *remoteCNIserver) liveUplinks() int {
+	var live int
+	for _, uplink := range s.uplinks {
+		if uplink.live {
+			live++
+		}
+	}
+	return live
+}
+
+// setUplinkLive adds or withdraws the default route via the uplink, the route is persisted accordingly,
+// so that the resync of the VPP agent does not restore the withdrawn route.
+func (s *remoteCNIserver) setUplinkLive(uplink *uplink, live bool) error {
+	route := s.uplinkRoute(uplink)
+	routeKey := vpp_l3.RouteKey(route.VrfId, route.DstIpAddr, route.NextHopAddr)
+	var err error
+	if live {
+		s.Logger.Infof("Uplink %s is up, restoring the route via %s", uplink.InterfaceName, uplink.Gateway)
+		err = s.vppTxnFactory().Put().StaticRoute(route).Send().ReceiveReply()
+		if err == nil {
+			err = s.persistChanges(nil, map[string]proto.Message{routeKey: route}, false)
+		}
+	} else {
+		s.Logger.Warnf("Uplink %s is down, withdrawing the route via %s", uplink.InterfaceName, uplink.Gateway)
+		err = s.vppTxnFactory().Delete().StaticRoute(route.VrfId, route.DstIpAddr, route.NextHopAddr).
+			Send().ReceiveReply()
+		if err == nil {
+			err = s.persistChanges([]string{routeKey}, nil, true)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("can't update the route via the uplink %s: %v", uplink.InterfaceName, err)
+	}
+	uplink.live = live
+	uplink.checked = true
+	return nil
+}
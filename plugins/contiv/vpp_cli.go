@@ -0,0 +1,162 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contiv
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+
+	"git.fd.io/govpp.git/api"
+	if_binapi "github.com/ligato/vpp-agent/plugins/defaultplugins/common/bin_api/interfaces"
+	"github.com/ligato/vpp-agent/plugins/defaultplugins/common/bin_api/vpe"
+	"github.com/ligato/vpp-agent/plugins/defaultplugins/ifplugin/ifaceidx"
+)
+
+// cliExecutor executes VPP CLI configuration commands.
+type cliExecutor interface {
+	// cli executes a VPP CLI configuration command, any output is treated as an error.
+	cli(cmd string) error
+
+	// cliOutput executes a VPP CLI command and returns its output.
+	cliOutput(cmd string) (string, error)
+
+	// internalIfName returns the name used by VPP CLI for the interface with the given logical name.
+	internalIfName(vppIfName string) (string, error)
+
+	// internalIfIndex returns sw_if_index of the interface with the given name used by VPP CLI,
+	// i.e. also of the interfaces not managed by the VPP agent.
+	internalIfIndex(ifName string) (uint32, error)
+
+	// internalIfNames returns the names used by VPP CLI of all VPP interfaces.
+	internalIfNames() ([]string, error)
+}
+
+// vppCLI executes VPP CLI commands over the binary API. It is used to configure the VPP features
+// which are not supported by the vendored VPP agent (policers, classifiers, IPsec). The requests
+// are serialized, since the executor is shared with other plugins (see ExecuteVppCLI).
+type vppCLI struct {
+	sync.Mutex
+	govppChan *api.Channel
+	swIfIndex ifaceidx.SwIfIndex
+}
+
+// newVppCLI returns new instance of vppCLI.
+func newVppCLI(govppChan *api.Channel, swIfIndex ifaceidx.SwIfIndex) *vppCLI {
+	return &vppCLI{
+		govppChan: govppChan,
+		swIfIndex: swIfIndex,
+	}
+}
+
+// internalIfName returns the name of the interface used by VPP (and VPP CLI) for the interface
+// with the given logical name.
+func (c *vppCLI) internalIfName(vppIfName string) (string, error) {
+	swIfIdx, _, found := c.swIfIndex.LookupIdx(vppIfName)
+	if !found {
+		return "", fmt.Errorf("interface %s not found in VPP", vppIfName)
+	}
+	ifNames, err := c.dumpInterfaceNames()
+	if err != nil {
+		return "", err
+	}
+	ifName, found := ifNames[swIfIdx]
+	if !found {
+		return "", fmt.Errorf("interface %s (sw_if_index %d) not found in the VPP interface dump", vppIfName, swIfIdx)
+	}
+	return ifName, nil
+}
+
+// internalIfIndex returns sw_if_index of the interface with the given name used by VPP (and VPP CLI).
+func (c *vppCLI) internalIfIndex(ifName string) (uint32, error) {
+	ifNames, err := c.dumpInterfaceNames()
+	if err != nil {
+		return 0, err
+	}
+	for swIfIdx, name := range ifNames {
+		if name == ifName {
+			return swIfIdx, nil
+		}
+	}
+	return 0, fmt.Errorf("interface %s not found in the VPP interface dump", ifName)
+}
+
+// internalIfNames returns the names used by VPP (and VPP CLI) of all VPP interfaces.
+func (c *vppCLI) internalIfNames() ([]string, error) {
+	ifNames, err := c.dumpInterfaceNames()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(ifNames))
+	for _, name := range ifNames {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// dumpInterfaceNames returns the names used by VPP of all VPP interfaces, by sw_if_index.
+func (c *vppCLI) dumpInterfaceNames() (map[uint32]string, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	ifNames := map[uint32]string{}
+	reqCtx := c.govppChan.SendMultiRequest(&if_binapi.SwInterfaceDump{})
+	for {
+		msg := &if_binapi.SwInterfaceDetails{}
+		stop, err := reqCtx.ReceiveReply(msg)
+		if stop {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		ifNames[msg.SwIfIndex] = string(bytes.Trim(msg.InterfaceName, "\x00"))
+	}
+	return ifNames, nil
+}
+
+// cli executes a VPP CLI configuration command. The configuration commands print nothing
+// on success, any output is therefore treated as an error.
+func (c *vppCLI) cli(cmd string) error {
+	out, err := c.cliOutput(cmd)
+	if err != nil {
+		return err
+	}
+	if out = strings.TrimSpace(out); out != "" {
+		return fmt.Errorf("VPP CLI command '%s' failed: %s", cmd, out)
+	}
+	return nil
+}
+
+// cliOutput executes a VPP CLI command and returns its output.
+func (c *vppCLI) cliOutput(cmd string) (string, error) {
+	req := &vpe.CliInband{
+		Cmd:    []byte(cmd),
+		Length: uint32(len(cmd)),
+	}
+	reply := &vpe.CliInbandReply{}
+
+	c.Lock()
+	defer c.Unlock()
+	err := c.govppChan.SendRequest(req).ReceiveReply(reply)
+	if err != nil {
+		return "", err
+	}
+	if reply.Retval != 0 {
+		return "", fmt.Errorf("VPP CLI command '%s' returned %d", cmd, reply.Retval)
+	}
+	return string(reply.Reply), nil
+}
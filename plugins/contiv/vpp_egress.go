@@ -0,0 +1,302 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contiv
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ligato/cn-infra/logging"
+)
+
+const (
+	// egressHairpinIP is the IP address of the hairpin loopbacks on the gateway node, each loopback is in its own VRF
+	egressHairpinIP = "169.254.254.1/24"
+
+	// egressHairpinNextHop is the next hop of the traffic steered into the hairpin loopbacks,
+	// resolved by a static ARP entry to the MAC address of the loopback
+	egressHairpinNextHop = "169.254.254.2"
+
+	// egressACLTagPrefix is the prefix of the tags of the ACLs matching the traffic of the egress policies
+	egressACLTagPrefix = "contiv-egress-"
+)
+
+// aclIndexRegexp matches the output of the CLI command creating an ACL.
+var aclIndexRegexp = regexp.MustCompile(`ACL index:\s*(\d+)`)
+
+// vppEgress steers the traffic of the PODs selected by the egress policies towards the gateway nodes
+// and source-NATs it to the egress IPs on the gateway nodes.
+// The traffic is selected by an ACL (permitting the traffic from the POD IPs to destinations outside
+// of the cluster subnets) of an ACL-based forwarding (ABF) policy attached to the input of the POD-facing
+// interfaces. On the other nodes the ABF policy forwards the traffic via the overlay to the gateway node.
+// On the gateway node the policy is attached also to the VXLAN BVI and forwards the traffic into a hairpin
+// loopback in the VRF of the policy, where it is source-NATed to the egress IP (NAT44 selects the address
+// of the VRF the traffic is received in) and routed out of the node via the default VRF.
+// The vendored VPP agent does not support ABF and the NAT44 addresses bound to a VRF, therefore the whole
+// configuration is applied via VPP CLI. The methods are not thread-safe, the access is synchronized
+// by the lock of the CNI server.
+type vppEgress struct {
+	logging.Logger
+	cli cliExecutor
+
+	// firstVrfID is the VRF of the policy with ID 0
+	firstVrfID uint32
+
+	// rules applied in VPP, by policy (namespace/name)
+	rules map[string]*appliedEgressRule
+
+	// IDs of the applied rules (ABF policy ID and offset of the VRF)
+	usedIDs map[uint32]struct{}
+}
+
+// egressRule is the configuration of one egress policy on this node.
+type egressRule struct {
+	srcIPs  []string // IPv4 addresses of the selected PODs
+	ifNames []string // logical names of the VPP interfaces the ABF policy is attached to
+
+	// nextHop and viaIfName forward the traffic to the gateway node, empty on the gateway node
+	nextHop   string
+	viaIfName string
+
+	// egressIP is the address the traffic is NATed to, set only on the gateway node
+	egressIP string
+}
+
+// appliedEgressRule is an egress rule applied in VPP, possibly only partially.
+type appliedEgressRule struct {
+	egressRule
+
+	id uint32
+
+	// hairpin loopback in the VRF of the policy, on the gateway node only
+	vrfCreated bool
+	loop       string
+	natApplied bool
+
+	aclIdx     uint32
+	aclCreated bool
+
+	// path of the ABF policy, empty until the policy is created
+	via string
+
+	// VPP internal names of the interfaces the ABF policy is attached to
+	attached []string
+}
+
+// newVppEgress returns new instance of vppEgress.
+func newVppEgress(logger logging.Logger, cli cliExecutor, firstVrfID uint32) *vppEgress {
+	return &vppEgress{
+		Logger:     logger,
+		cli:        cli,
+		firstVrfID: firstVrfID,
+		rules:      map[string]*appliedEgressRule{},
+		usedIDs:    map[uint32]struct{}{},
+	}
+}
+
+// update applies the egress rule of the policy, replacing the rule applied before. A nil rule
+// (or a rule without any POD) removes the configuration of the policy.
+func (e *vppEgress) update(policy string, rule *egressRule, clusterSubnets []*net.IPNet) error {
+	if rule != nil && len(rule.srcIPs) == 0 {
+		rule = nil
+	}
+	applied, exists := e.rules[policy]
+	if exists && rule != nil && reflect.DeepEqual(applied.egressRule, *rule) {
+		return nil
+	}
+	if exists {
+		if err := e.remove(policy, applied); err != nil {
+			return err
+		}
+	}
+	if rule == nil {
+		return nil
+	}
+	return e.apply(policy, rule, clusterSubnets)
+}
+
+// apply configures a new egress rule of the policy.
+func (e *vppEgress) apply(policy string, rule *egressRule, clusterSubnets []*net.IPNet) error {
+	applied := &appliedEgressRule{
+		egressRule: *rule,
+		id:         e.allocateID(),
+	}
+	applied.ifNames = nil // filled as the interfaces are attached
+	vrf := e.firstVrfID + applied.id
+
+	// the rule is stored before it is complete, so that a partial configuration is removed by the next update
+	e.rules[policy] = applied
+
+	var via string
+	if rule.egressIP != "" {
+		// hairpin loopback in the VRF of the policy, the create command prints the name of the new interface
+		mac := fmt.Sprintf("02:fe:%02x:%02x:%02x:%02x", byte(vrf>>24), byte(vrf>>16), byte(vrf>>8), byte(vrf))
+		err := e.cli.cli(fmt.Sprintf("ip table add %d", vrf))
+		if err != nil {
+			return err
+		}
+		applied.vrfCreated = true
+		out, err := e.cli.cliOutput(fmt.Sprintf("create loopback interface mac %s", mac))
+		if err != nil {
+			return err
+		}
+		if out = strings.TrimSpace(out); out == "" || strings.ContainsAny(out, " \n") {
+			return fmt.Errorf("can't create hairpin loopback of the egress policy %s: %s", policy, out)
+		}
+		applied.loop = out
+		cmds := []string{
+			fmt.Sprintf("set interface ip table %s %d", applied.loop, vrf),
+			fmt.Sprintf("set interface ip address %s %s", applied.loop, egressHairpinIP),
+			fmt.Sprintf("set interface state %s up", applied.loop),
+			fmt.Sprintf("set ip arp static %s %s %s", applied.loop, egressHairpinNextHop, mac),
+			fmt.Sprintf("set interface nat44 in %s", applied.loop),
+			fmt.Sprintf("nat44 add address %s tenant-vrf %d", rule.egressIP, vrf),
+			fmt.Sprintf("ip route add 0.0.0.0/0 table %d via ip4-lookup-in-table 0", vrf),
+		}
+		applied.natApplied = true
+		for _, cmd := range cmds {
+			if err := e.cli.cli(cmd); err != nil {
+				return err
+			}
+		}
+		via = fmt.Sprintf("%s %s", egressHairpinNextHop, applied.loop)
+	} else {
+		viaIfName, err := e.cli.internalIfName(rule.viaIfName)
+		if err != nil {
+			return err
+		}
+		via = fmt.Sprintf("%s %s", rule.nextHop, viaIfName)
+	}
+
+	// ACL matching the traffic of the PODs leaving the cluster
+	var aclRules []string
+	for _, subnet := range clusterSubnets {
+		aclRules = append(aclRules, fmt.Sprintf("deny src 0.0.0.0/0 dst %s", subnet))
+	}
+	for _, srcIP := range rule.srcIPs {
+		aclRules = append(aclRules, fmt.Sprintf("permit src %s/32 dst 0.0.0.0/0", srcIP))
+	}
+	out, err := e.cli.cliOutput(fmt.Sprintf("set acl-plugin acl %s tag %s%d",
+		strings.Join(aclRules, ", "), egressACLTagPrefix, applied.id))
+	if err != nil {
+		return err
+	}
+	match := aclIndexRegexp.FindStringSubmatch(out)
+	if match == nil {
+		return fmt.Errorf("can't create ACL of the egress policy %s: %s", policy, strings.TrimSpace(out))
+	}
+	aclIdx, _ := strconv.ParseUint(match[1], 10, 32)
+	applied.aclIdx = uint32(aclIdx)
+	applied.aclCreated = true
+
+	err = e.cli.cli(fmt.Sprintf("abf policy add id %d acl %d via %s", applied.id, applied.aclIdx, via))
+	if err != nil {
+		return err
+	}
+	applied.via = via
+
+	for _, ifName := range rule.ifNames {
+		internalName, err := e.cli.internalIfName(ifName)
+		if err != nil {
+			return err
+		}
+		err = e.cli.cli(fmt.Sprintf("abf attach ip4 policy %d %s", applied.id, internalName))
+		if err != nil {
+			return err
+		}
+		applied.ifNames = append(applied.ifNames, ifName)
+		applied.attached = append(applied.attached, internalName)
+	}
+	e.Debugf("Egress policy %s applied: %+v", policy, *rule)
+	return nil
+}
+
+// remove removes the applied (possibly partially) egress rule of the policy. The rule is forgotten even if some
+// part of the configuration fails to be removed, the first error is returned.
+func (e *vppEgress) remove(policy string, applied *appliedEgressRule) error {
+	var cmds []string
+	for _, ifName := range applied.attached {
+		cmds = append(cmds, fmt.Sprintf("abf attach ip4 del policy %d %s", applied.id, ifName))
+	}
+	if applied.via != "" {
+		cmds = append(cmds, fmt.Sprintf("abf policy del id %d acl %d via %s", applied.id, applied.aclIdx, applied.via))
+	}
+	if applied.aclCreated {
+		cmds = append(cmds, fmt.Sprintf("delete acl-plugin acl index %d", applied.aclIdx))
+	}
+	vrf := e.firstVrfID + applied.id
+	if applied.natApplied {
+		cmds = append(cmds,
+			fmt.Sprintf("ip route del 0.0.0.0/0 table %d via ip4-lookup-in-table 0", vrf),
+			fmt.Sprintf("nat44 add address %s tenant-vrf %d del", applied.egressIP, vrf),
+			fmt.Sprintf("set interface nat44 in %s del", applied.loop))
+	}
+	if applied.loop != "" {
+		cmds = append(cmds, fmt.Sprintf("delete loopback interface intfc %s", applied.loop))
+	}
+	if applied.vrfCreated {
+		cmds = append(cmds, fmt.Sprintf("ip table del %d", vrf))
+	}
+
+	var wasErr error
+	for _, cmd := range cmds {
+		if err := e.cli.cli(cmd); err != nil {
+			e.Warn(err)
+			if wasErr == nil {
+				wasErr = err
+			}
+		}
+	}
+	delete(e.rules, policy)
+	delete(e.usedIDs, applied.id)
+	e.Debugf("Egress policy %s removed", policy)
+	return wasErr
+}
+
+// detachInterface detaches the ABF policies from the POD-facing interface being removed.
+// The ACLs are updated by the next update of the policies.
+func (e *vppEgress) detachInterface(vppIfName string) error {
+	for _, applied := range e.rules {
+		for i, ifName := range applied.ifNames {
+			if ifName != vppIfName || i >= len(applied.attached) {
+				continue
+			}
+			err := e.cli.cli(fmt.Sprintf("abf attach ip4 del policy %d %s", applied.id, applied.attached[i]))
+			if err != nil {
+				return err
+			}
+			applied.ifNames = append(applied.ifNames[:i:i], applied.ifNames[i+1:]...)
+			applied.attached = append(applied.attached[:i:i], applied.attached[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// allocateID returns the lowest ID not used by any applied rule.
+func (e *vppEgress) allocateID() uint32 {
+	var id uint32
+	for {
+		if _, used := e.usedIDs[id]; !used {
+			e.usedIDs[id] = struct{}{}
+			return id
+		}
+		id++
+	}
+}
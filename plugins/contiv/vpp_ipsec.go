@@ -0,0 +1,459 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contiv
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"net"
+	"sort"
+
+	"github.com/ligato/cn-infra/logging"
+)
+
+const (
+	// ipsecSPD is the ID of the security policy database attached to the main VPP interface
+	ipsecSPD = 1
+
+	// priorities of the security policies (VPP evaluates the policies with higher priority first)
+	ipsecBypassPriority  = 10  // all traffic not matched by other policies
+	ipsecDiscardPriority = 50  // unprotected VXLAN traffic towards the nodes without the security association or from the nodes with IPsec
+	ipsecProtectPriority = 100 // VXLAN traffic towards the nodes with the security association
+
+	// vxlanPort is the UDP destination port of the VXLAN traffic
+	vxlanPort = 4789
+
+	// ipsecMaxNodeID is the maximal ID of a node supported by the IPsec overlay (node IDs are encoded into SPIs)
+	ipsecMaxNodeID = 0xfff
+
+	// ipsecInboundGenerations is the number of the newest key generations accepted for the inbound traffic
+	ipsecInboundGenerations = 2
+
+	// ipsecGenerationSlots is the number of key generations distinguished by the SPI, the generations are
+	// encoded into the SPI cyclically
+	ipsecGenerationSlots = 0xff
+)
+
+// ipsecOverlay protects the VXLAN traffic between the nodes using VPP IPsec. Each ordered pair of nodes
+// uses a security association with the SPI and keys derived from the node IDs and the cluster secret
+// (see ipsecSPI, ipsecKeys), so that both ends derive the same association without any negotiation.
+// The secret is rotated by adding a new key generation. The traffic is accepted with the two newest
+// generations and each node announces the newest one it accepts in the node info, the traffic towards
+// a node is encrypted with the newest generation announced by the node, therefore the nodes switch
+// to a new generation without dropping the traffic.
+// The vendored VPP binary API does not contain the IPsec messages, therefore the configuration is applied
+// via VPP CLI. The methods are not thread-safe, the access is synchronized by the lock of the CNI server.
+type ipsecOverlay struct {
+	logging.Logger
+	cli cliExecutor
+
+	tunnelMode bool
+	nodeID     uint32
+
+	// the configuration is applied once the main VPP interface and the node IP are known
+	configured bool
+	localIP    net.IP
+
+	// secrets of the key generations, by generation
+	secrets map[uint32][]byte
+
+	// generations accepted for the inbound traffic, sorted in ascending order
+	inbound []uint32
+
+	// the other nodes, by node ID
+	peers map[uint32]*ipsecPeer
+}
+
+// ipsecPeer is the IPsec configuration of the traffic with another node.
+type ipsecPeer struct {
+	ip        net.IP
+	announced uint32 // the newest generation accepted by the node
+	outbound  uint32 // generation of the outbound security association, 0 if not configured
+	discard   bool   // true if the unprotected inbound VXLAN traffic from the node is discarded
+}
+
+// newIPSecOverlay returns new instance of ipsecOverlay, mode is either "transport" (default) or "tunnel".
+func newIPSecOverlay(logger logging.Logger, cli cliExecutor, nodeID uint32, mode string) (*ipsecOverlay, error) {
+	if nodeID > ipsecMaxNodeID {
+		return nil, fmt.Errorf("IPsec overlay supports node IDs up to %d, ID of the node is %d", ipsecMaxNodeID, nodeID)
+	}
+	overlay := &ipsecOverlay{
+		Logger:  logger,
+		cli:     cli,
+		nodeID:  nodeID,
+		secrets: map[uint32][]byte{},
+		peers:   map[uint32]*ipsecPeer{},
+	}
+	switch mode {
+	case "", "transport":
+	case "tunnel":
+		overlay.tunnelMode = true
+	default:
+		return nil, fmt.Errorf("unsupported IPsec mode %q", mode)
+	}
+	return overlay, nil
+}
+
+// ipsecSPI returns SPI of the security association for the traffic from the node src to the node dst.
+func ipsecSPI(generation, src, dst uint32) uint32 {
+	return ipsecGenerationSlot(generation)<<24 | (src&ipsecMaxNodeID)<<12 | dst&ipsecMaxNodeID
+}
+
+// ipsecGenerationSlot returns the slot (1-255) of the key generation in the SPI. The slots are re-used
+// after ipsecGenerationSlots generations, two generations in the same slot are therefore never used together.
+func ipsecGenerationSlot(generation uint32) uint32 {
+	return (generation-1)%ipsecGenerationSlots + 1
+}
+
+// ipsecKeys derives the encryption and integrity keys of the security association for the traffic
+// from the node src to the node dst from the secret of the given generation.
+func ipsecKeys(secret []byte, generation, src, dst uint32) (cryptoKey, integKey []byte) {
+	derive := func(purpose string) []byte {
+		mac := hmac.New(sha256.New, secret)
+		fmt.Fprintf(mac, "contiv-ipsec/%d/%d->%d/%s", generation, src, dst, purpose)
+		return mac.Sum(nil)
+	}
+	return derive("crypt")[:16], derive("integ")
+}
+
+// configure attaches the security policy database to the main VPP interface and applies the configuration
+// of the already known peers. Until the peers are configured, the VXLAN traffic leaving the interface is dropped.
+func (o *ipsecOverlay) configure(vppIfName string, localIP net.IP) error {
+	if o.configured {
+		return nil
+	}
+	ifName, err := o.cli.internalIfName(vppIfName)
+	if err != nil {
+		return err
+	}
+
+	cmds := []string{
+		fmt.Sprintf("ipsec spd add %d", ipsecSPD),
+		fmt.Sprintf("set interface ipsec spd %s %d", ifName, ipsecSPD),
+		fmt.Sprintf("ipsec policy add spd %d priority %d outbound action bypass "+
+			"local-ip-range 0.0.0.0 - 255.255.255.255 remote-ip-range 0.0.0.0 - 255.255.255.255",
+			ipsecSPD, ipsecBypassPriority),
+		fmt.Sprintf("ipsec policy add spd %d priority %d outbound action discard "+
+			"local-ip-range 0.0.0.0 - 255.255.255.255 remote-ip-range 0.0.0.0 - 255.255.255.255 "+
+			"protocol 17 remote-port-range %d - %d",
+			ipsecSPD, ipsecDiscardPriority, vxlanPort, vxlanPort),
+	}
+	for _, cmd := range cmds {
+		if err := o.cli.cli(cmd); err != nil {
+			return err
+		}
+	}
+	o.configured = true
+	o.localIP = localIP
+	o.Infof("IPsec configured on the interface %s (mode: %s)", ifName, o.modeName())
+
+	for _, id := range o.peerIDs() {
+		peer := o.peers[id]
+		for _, generation := range o.inbound {
+			if err := o.addInbound(id, peer, generation); err != nil {
+				return err
+			}
+		}
+		if err := o.updateOutbound(id, peer); err != nil {
+			return err
+		}
+		if err := o.updateInboundDiscard(peer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// acceptedGeneration returns the newest key generation accepted for the inbound traffic,
+// 0 if the inbound traffic is not accepted yet.
+func (o *ipsecOverlay) acceptedGeneration() uint32 {
+	if !o.configured || len(o.inbound) == 0 {
+		return 0
+	}
+	return o.inbound[len(o.inbound)-1]
+}
+
+// addKey adds a generation of the cluster secret. A generation newer than all the known ones is accepted
+// for the inbound traffic immediately, the oldest generation is no longer accepted.
+// The generations must be added in ascending order, an older generation is only used for the outbound traffic.
+// A new generation sharing the SPI slot with a generation which remains accepted is rejected.
+func (o *ipsecOverlay) addKey(generation uint32, secret []byte) error {
+	if generation == 0 || len(secret) == 0 {
+		return fmt.Errorf("invalid IPsec key generation %d", generation)
+	}
+	if known, exists := o.secrets[generation]; exists {
+		if !bytes.Equal(known, secret) {
+			return fmt.Errorf("secret of the IPsec key generation %d can't be changed", generation)
+		}
+		return nil
+	}
+
+	newest := len(o.inbound) == 0 || generation > o.inbound[len(o.inbound)-1]
+	if newest {
+		kept := o.inbound
+		if len(kept) > ipsecInboundGenerations-1 {
+			kept = kept[len(kept)-(ipsecInboundGenerations-1):]
+		}
+		for _, accepted := range kept {
+			if ipsecGenerationSlot(accepted) == ipsecGenerationSlot(generation) {
+				return fmt.Errorf("IPsec key generation %d has the same SPI as the accepted generation %d, "+
+					"a generation in between must be added first", generation, accepted)
+			}
+		}
+	}
+	o.secrets[generation] = secret
+
+	if newest {
+		o.inbound = append(o.inbound, generation)
+		var expired []uint32
+		if len(o.inbound) > ipsecInboundGenerations {
+			expired = o.inbound[:len(o.inbound)-ipsecInboundGenerations]
+			o.inbound = o.inbound[len(o.inbound)-ipsecInboundGenerations:]
+		}
+		if o.configured {
+			for _, id := range o.peerIDs() {
+				if err := o.addInbound(id, o.peers[id], generation); err != nil {
+					return err
+				}
+				for _, old := range expired {
+					if err := o.deleteInbound(id, o.peers[id], old); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		o.Infof("IPsec key generation %d accepted for the inbound traffic", generation)
+	}
+
+	// the peers may already accept the new generation
+	return o.updateOutbounds()
+}
+
+// updatePeer adds or updates the node with the given ID. acceptedGeneration is the newest key generation
+// accepted by the node, the outbound traffic towards the node is encrypted with it once it is known locally.
+func (o *ipsecOverlay) updatePeer(id uint32, ip net.IP, acceptedGeneration uint32) error {
+	if id > ipsecMaxNodeID {
+		return fmt.Errorf("IPsec overlay supports node IDs up to %d, can't connect the node %d", ipsecMaxNodeID, id)
+	}
+	peer, exists := o.peers[id]
+	if exists && !peer.ip.Equal(ip) {
+		if err := o.removePeer(id); err != nil {
+			return err
+		}
+		exists = false
+	}
+	if !exists {
+		peer = &ipsecPeer{ip: ip}
+		o.peers[id] = peer
+		if o.configured {
+			for _, generation := range o.inbound {
+				if err := o.addInbound(id, peer, generation); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	peer.announced = acceptedGeneration
+	if !o.configured {
+		return nil
+	}
+	if err := o.updateOutbound(id, peer); err != nil {
+		return err
+	}
+	return o.updateInboundDiscard(peer)
+}
+
+// removePeer removes all security associations with the given node.
+func (o *ipsecOverlay) removePeer(id uint32) error {
+	peer, exists := o.peers[id]
+	if !exists {
+		return nil
+	}
+	if o.configured {
+		if peer.outbound != 0 {
+			if err := o.deleteOutbound(id, peer, peer.outbound); err != nil {
+				return err
+			}
+		}
+		for _, generation := range o.inbound {
+			if err := o.deleteInbound(id, peer, generation); err != nil {
+				return err
+			}
+		}
+		if peer.discard {
+			if err := o.cli.cli(o.discardCommand("del", peer.ip)); err != nil {
+				return err
+			}
+		}
+	}
+	delete(o.peers, id)
+	return nil
+}
+
+// updateOutbounds updates the outbound security associations of all peers.
+func (o *ipsecOverlay) updateOutbounds() error {
+	if !o.configured {
+		return nil
+	}
+	for _, id := range o.peerIDs() {
+		if err := o.updateOutbound(id, o.peers[id]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateOutbound switches the outbound traffic towards the peer to the newest generation known locally and
+// accepted by the peer. The new policy is added before the old one is removed, the peer accepts both of them.
+func (o *ipsecOverlay) updateOutbound(id uint32, peer *ipsecPeer) error {
+	generation := uint32(0)
+	for known := range o.secrets {
+		if known <= peer.announced && known > generation {
+			generation = known
+		}
+	}
+	if generation == peer.outbound {
+		return nil
+	}
+
+	if generation != 0 && peer.outbound != 0 && ipsecGenerationSlot(generation) == ipsecGenerationSlot(peer.outbound) {
+		// the generations share the SPI, the old association must be removed first (the traffic
+		// towards the peer is dropped in the meantime)
+		o.Warnf("IPsec key generations %d and %d towards the node %d share the SPI, re-keying",
+			peer.outbound, generation, id)
+		if err := o.deleteOutbound(id, peer, peer.outbound); err != nil {
+			return err
+		}
+		peer.outbound = 0
+	}
+	if generation != 0 {
+		err := o.cli.cli(o.saCommand("add", generation, o.nodeID, id, o.localIP, peer.ip))
+		if err != nil {
+			return err
+		}
+		err = o.cli.cli(o.policyCommand("add", "outbound", generation, o.nodeID, id, peer.ip))
+		if err != nil {
+			return err
+		}
+	}
+	if peer.outbound != 0 {
+		if err := o.deleteOutbound(id, peer, peer.outbound); err != nil {
+			return err
+		}
+	}
+	o.Debugf("IPsec traffic towards the node %d switched from the key generation %d to %d",
+		id, peer.outbound, generation)
+	peer.outbound = generation
+	return nil
+}
+
+// updateInboundDiscard discards the unprotected inbound VXLAN traffic from the peer once the peer announces
+// an accepted generation, i.e. once it has IPsec enabled. Such peer never sends unprotected VXLAN traffic,
+// since its outbound VXLAN traffic without a security association is discarded.
+func (o *ipsecOverlay) updateInboundDiscard(peer *ipsecPeer) error {
+	discard := peer.announced != 0
+	if discard == peer.discard {
+		return nil
+	}
+	action := "del"
+	if discard {
+		action = "add"
+	}
+	if err := o.cli.cli(o.discardCommand(action, peer.ip)); err != nil {
+		return err
+	}
+	peer.discard = discard
+	return nil
+}
+
+// deleteOutbound removes the outbound security association of the given generation.
+func (o *ipsecOverlay) deleteOutbound(id uint32, peer *ipsecPeer, generation uint32) error {
+	err := o.cli.cli(o.policyCommand("del", "outbound", generation, o.nodeID, id, peer.ip))
+	if err != nil {
+		return err
+	}
+	return o.cli.cli(fmt.Sprintf("ipsec sa del %d", ipsecSPI(generation, o.nodeID, id)))
+}
+
+// addInbound adds the inbound security association of the given generation.
+func (o *ipsecOverlay) addInbound(id uint32, peer *ipsecPeer, generation uint32) error {
+	err := o.cli.cli(o.saCommand("add", generation, id, o.nodeID, peer.ip, o.localIP))
+	if err != nil {
+		return err
+	}
+	return o.cli.cli(o.policyCommand("add", "inbound", generation, id, o.nodeID, peer.ip))
+}
+
+// deleteInbound removes the inbound security association of the given generation.
+func (o *ipsecOverlay) deleteInbound(id uint32, peer *ipsecPeer, generation uint32) error {
+	err := o.cli.cli(o.policyCommand("del", "inbound", generation, id, o.nodeID, peer.ip))
+	if err != nil {
+		return err
+	}
+	return o.cli.cli(fmt.Sprintf("ipsec sa del %d", ipsecSPI(generation, id, o.nodeID)))
+}
+
+// saCommand returns CLI command adding the security association for the traffic from the node src to the node dst.
+func (o *ipsecOverlay) saCommand(action string, generation, src, dst uint32, srcIP, dstIP net.IP) string {
+	spi := ipsecSPI(generation, src, dst)
+	cryptoKey, integKey := ipsecKeys(o.secrets[generation], generation, src, dst)
+	cmd := fmt.Sprintf("ipsec sa %s %d spi %d esp crypto-key %x crypto-alg aes-cbc-128 integ-key %x integ-alg sha-256-128",
+		action, spi, spi, cryptoKey, integKey)
+	if o.tunnelMode {
+		cmd += fmt.Sprintf(" tunnel-src %s tunnel-dst %s", srcIP, dstIP)
+	}
+	return cmd
+}
+
+// policyCommand returns CLI command adding or deleting the policy protecting the traffic with the peer
+// by the security association for the traffic from the node src to the node dst.
+func (o *ipsecOverlay) policyCommand(action, direction string, generation, src, dst uint32, peerIP net.IP) string {
+	cmd := fmt.Sprintf("ipsec policy %s spd %d priority %d %s action protect sa %d "+
+		"local-ip-range %s - %s remote-ip-range %s - %s",
+		action, ipsecSPD, ipsecProtectPriority, direction, ipsecSPI(generation, src, dst),
+		o.localIP, o.localIP, peerIP, peerIP)
+	if direction == "outbound" {
+		cmd += fmt.Sprintf(" protocol 17 remote-port-range %d - %d", vxlanPort, vxlanPort)
+	}
+	return cmd
+}
+
+// discardCommand returns CLI command adding or deleting the policy discarding the unprotected inbound VXLAN traffic
+// from the peer.
+func (o *ipsecOverlay) discardCommand(action string, peerIP net.IP) string {
+	return fmt.Sprintf("ipsec policy %s spd %d priority %d inbound action discard "+
+		"local-ip-range %s - %s remote-ip-range %s - %s protocol 17 local-port-range %d - %d",
+		action, ipsecSPD, ipsecDiscardPriority, o.localIP, o.localIP, peerIP, peerIP, vxlanPort, vxlanPort)
+}
+
+// peerIDs returns IDs of the peers in ascending order.
+func (o *ipsecOverlay) peerIDs() []uint32 {
+	ids := make([]uint32, 0, len(o.peers))
+	for id := range o.peers {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// modeName returns name of the IPsec mode.
+func (o *ipsecOverlay) modeName() string {
+	if o.tunnelMode {
+		return "tunnel"
+	}
+	return "transport"
+}
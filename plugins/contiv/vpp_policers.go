@@ -0,0 +1,400 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contiv
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ligato/cn-infra/logging"
+)
+
+const (
+	// egressPolicerPrefix is the prefix of the names of the policers limiting the egress bandwidth of PODs
+	egressPolicerPrefix = "contiv-egress-"
+
+	// ingressPolicerPrefix is the prefix of the names of the policers limiting the ingress bandwidth of PODs
+	ingressPolicerPrefix = "contiv-ingress-"
+
+	// policerClassifyBuckets is the number of buckets of the classify tables matching the POD traffic
+	policerClassifyBuckets = 1024
+
+	// minPolicerBurst is the minimal committed burst size of the POD policers in bytes (10 full-size frames)
+	minPolicerBurst = 15000
+
+	// localIfName is the VPP name of the local interface, which never receives any traffic
+	localIfName = "local0"
+)
+
+// vppPolicers limits the bandwidth of the traffic sent and received by PODs using VPP policers.
+// VPP supports only input policers, which are selected by the policer classify tables attached
+// to the input of the interfaces. There are two tables for each IP version. The egress table matches
+// the source IP addresses of the PODs with an egress limit and it is attached to the input of the POD-facing
+// VPP interfaces of these PODs. The ingress table matches the destination IP addresses of the PODs with
+// an ingress limit and it is attached to the input of all the other VPP interfaces while there is any
+// ingress limit. The egress table continues with the ingress table if the packet does not match.
+// The traffic sent by a POD with an egress limit to a POD on the same node is therefore policed only
+// by the egress limit. The vendored VPP binary API does not contain the policer and classifier messages,
+// therefore the configuration is applied via VPP CLI.
+// The methods are thread-safe, the limits of PODs wired concurrently by the CNI server
+// are serialized by the internal lock.
+type vppPolicers struct {
+	logging.Logger
+	cliExecutor
+	sync.Mutex
+
+	// classify tables by IP version ("ip4" or "ip6"), created on the first use
+	tables map[string]*policerTables
+
+	// limits configured for the containers, by container ID
+	limits map[string]*podLimit
+
+	// interface tables with the ingress table attached
+	ingressIfs map[ifTable]struct{}
+}
+
+// policerTables are indexes of the classify tables of one IP version.
+type policerTables struct {
+	egress  uint32
+	ingress uint32
+}
+
+// ifTable identifies the policer classify table of one IP version attached to a VPP interface.
+type ifTable struct {
+	ifName    string // VPP internal name of the interface
+	ipVersion string
+}
+
+// podLimit is the VPP configuration limiting the bandwidth of a single container.
+type podLimit struct {
+	ingress   bool      // true if the ingress bandwidth is limited
+	egressIfs []ifTable // interface tables with the egress table attached
+
+	// undo are the CLI commands removing the configuration of the limit, in the order of execution
+	undo []string
+}
+
+// newVppPolicers returns new instance of vppPolicers.
+func newVppPolicers(logger logging.Logger, cli cliExecutor) *vppPolicers {
+	return &vppPolicers{
+		Logger:      logger,
+		cliExecutor: cli,
+		tables:      map[string]*policerTables{},
+		limits:      map[string]*podLimit{},
+		ingressIfs:  map[ifTable]struct{}{},
+	}
+}
+
+// setLimits limits the rates (in bits per second) of the traffic sent and received by the POD with the given
+// IP addresses, connected via the given VPP interface. Zero rate means no limit. An already configured limit
+// of the container is replaced.
+func (p *vppPolicers) setLimits(containerID string, vppIfName string, podIPs []net.IP, bandwidth podBandwidth) error {
+	p.Lock()
+	defer p.Unlock()
+
+	err := p.removeLimit(containerID)
+	if err == nil && bandwidth != (podBandwidth{}) {
+		err = p.addLimits(containerID, vppIfName, podIPs, bandwidth)
+	}
+	if updateErr := p.updateIngressTables(); err == nil {
+		err = updateErr
+	}
+	if err != nil {
+		return err
+	}
+	p.Debugf("Bandwidth of the container %s limited to %d bps ingress, %d bps egress (0 = unlimited)",
+		containerID, bandwidth.ingress, bandwidth.egress)
+	return nil
+}
+
+// removeLimits removes the limits of the container. Does nothing if no limit is configured.
+func (p *vppPolicers) removeLimits(containerID string) error {
+	p.Lock()
+	defer p.Unlock()
+
+	err := p.removeLimit(containerID)
+	if err != nil {
+		return err
+	}
+	return p.updateIngressTables()
+}
+
+// updateInterfaces attaches the ingress tables also to the VPP interfaces created since the last update,
+// so that the ingress limits apply to the traffic received from them.
+func (p *vppPolicers) updateInterfaces() error {
+	p.Lock()
+	defer p.Unlock()
+
+	return p.updateIngressTables()
+}
+
+// addLimits configures the limits of the container, the lock must be held by the caller. A partially
+// applied configuration is reverted.
+func (p *vppPolicers) addLimits(containerID string, vppIfName string, podIPs []net.IP, bandwidth podBandwidth) error {
+	ifName, err := p.internalIfName(vppIfName)
+	if err != nil {
+		return err
+	}
+
+	limit := &podLimit{ingress: bandwidth.ingress != 0}
+	p.limits[containerID] = limit
+	if bandwidth.egress != 0 {
+		err = p.addPolicer(limit, egressPolicerPrefix+containerID, bandwidth.egress, podIPs, "src",
+			func(tables *policerTables) uint32 { return tables.egress })
+	}
+	if err == nil && bandwidth.ingress != 0 {
+		err = p.addPolicer(limit, ingressPolicerPrefix+containerID, bandwidth.ingress, podIPs, "dst",
+			func(tables *policerTables) uint32 { return tables.ingress })
+	}
+	if err == nil && bandwidth.egress != 0 {
+		for _, podIP := range podIPs {
+			version := ipVersion(podIP)
+			err = p.attachEgressTable(limit, ifTable{ifName: ifName, ipVersion: version}, p.tables[version].egress)
+			if err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		p.removeLimit(containerID)
+		return err
+	}
+	return nil
+}
+
+// addPolicer configures a policer with the given rate and the sessions matching the POD IP addresses
+// (as the source or destination) in the classify tables selected by <table>.
+func (p *vppPolicers) addPolicer(limit *podLimit, policer string, rate uint64, podIPs []net.IP, match string,
+	table func(tables *policerTables) uint32) error {
+
+	burst := rate / 8 / 10 // 100ms of traffic
+	if burst < minPolicerBurst {
+		burst = minPolicerBurst
+	}
+	err := p.apply(limit,
+		fmt.Sprintf("configure policer name %s cir %d cb %d rate kbps round closest type 1r2c "+
+			"conform-action transmit exceed-action drop", policer, rate/1000, burst),
+		fmt.Sprintf("configure policer name %s del", policer))
+	if err != nil {
+		return err
+	}
+	for _, podIP := range podIPs {
+		version := ipVersion(podIP)
+		tables, err := p.classifyTables(version)
+		if err != nil {
+			return err
+		}
+		err = p.apply(limit,
+			fmt.Sprintf("classify session policer-hit-next %s table-index %d match l3 %s %s %s",
+				policer, table(tables), version, match, podIP),
+			fmt.Sprintf("classify session del table-index %d match l3 %s %s %s",
+				table(tables), version, match, podIP))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attachEgressTable attaches the egress table to the POD-facing interface instead of the ingress table.
+func (p *vppPolicers) attachEgressTable(limit *podLimit, ift ifTable, tableIdx uint32) error {
+	if _, attached := p.ingressIfs[ift]; attached {
+		if err := p.detachTable(ift, p.tables[ift.ipVersion].ingress); err != nil {
+			return err
+		}
+		delete(p.ingressIfs, ift)
+	}
+	err := p.apply(limit,
+		fmt.Sprintf("set policer classify interface %s %s-table %d", ift.ifName, ift.ipVersion, tableIdx),
+		fmt.Sprintf("set policer classify interface %s %s-table %d del", ift.ifName, ift.ipVersion, tableIdx))
+	if err != nil {
+		return err
+	}
+	limit.egressIfs = append(limit.egressIfs, ift)
+	return nil
+}
+
+// apply executes the CLI command configuring a part of the limit and records the command removing it.
+func (p *vppPolicers) apply(limit *podLimit, cmd string, undo string) error {
+	if err := p.cli(cmd); err != nil {
+		return err
+	}
+	limit.undo = append([]string{undo}, limit.undo...)
+	return nil
+}
+
+// removeLimit removes the limits of the container, the lock must be held by the caller.
+func (p *vppPolicers) removeLimit(containerID string) error {
+	limit, exists := p.limits[containerID]
+	if !exists {
+		return nil
+	}
+
+	for len(limit.undo) > 0 {
+		if err := p.cli(limit.undo[0]); err != nil {
+			return err
+		}
+		limit.undo = limit.undo[1:]
+	}
+
+	delete(p.limits, containerID)
+	p.Debugf("Bandwidth limits of the container %s removed", containerID)
+	return nil
+}
+
+// updateIngressTables attaches the ingress tables to the input of all VPP interfaces except the POD-facing
+// interfaces with an egress limit while there is any ingress limit, and detaches them otherwise.
+// The lock must be held by the caller.
+func (p *vppPolicers) updateIngressTables() error {
+	ingressLimited := false
+	egressIfs := map[ifTable]struct{}{}
+	for _, limit := range p.limits {
+		ingressLimited = ingressLimited || limit.ingress
+		for _, ift := range limit.egressIfs {
+			egressIfs[ift] = struct{}{}
+		}
+	}
+	if !ingressLimited && len(p.ingressIfs) == 0 {
+		return nil
+	}
+
+	ifNames, err := p.internalIfNames()
+	if err != nil {
+		return err
+	}
+	existing := map[ifTable]struct{}{}
+	for _, ifName := range ifNames {
+		for version := range p.tables {
+			existing[ifTable{ifName: ifName, ipVersion: version}] = struct{}{}
+		}
+	}
+
+	// detach the ingress tables which are no longer needed (interfaces removed from VPP are just forgotten)
+	for ift := range p.ingressIfs {
+		_, exists := existing[ift]
+		_, egressLimited := egressIfs[ift]
+		if exists && ingressLimited && !egressLimited {
+			continue
+		}
+		if exists {
+			if err := p.detachTable(ift, p.tables[ift.ipVersion].ingress); err != nil {
+				return err
+			}
+		}
+		delete(p.ingressIfs, ift)
+	}
+	if !ingressLimited {
+		return nil
+	}
+
+	// attach the ingress tables to the new interfaces
+	for ift := range existing {
+		_, attached := p.ingressIfs[ift]
+		_, egressLimited := egressIfs[ift]
+		if attached || egressLimited || ift.ifName == localIfName {
+			continue
+		}
+		err := p.cli(fmt.Sprintf("set policer classify interface %s %s-table %d",
+			ift.ifName, ift.ipVersion, p.tables[ift.ipVersion].ingress))
+		if err != nil {
+			return err
+		}
+		p.ingressIfs[ift] = struct{}{}
+	}
+	return nil
+}
+
+// detachTable detaches the policer classify table from the interface.
+func (p *vppPolicers) detachTable(ift ifTable, tableIdx uint32) error {
+	return p.cli(fmt.Sprintf("set policer classify interface %s %s-table %d del", ift.ifName, ift.ipVersion, tableIdx))
+}
+
+// classifyTables returns the classify tables of the given IP version, which are shared by all POD policers.
+// The tables are created on the first use.
+func (p *vppPolicers) classifyTables(version string) (*policerTables, error) {
+	if tables, created := p.tables[version]; created {
+		return tables, nil
+	}
+
+	ingress, err := p.createClassifyTable(fmt.Sprintf("classify table mask l3 %s dst buckets %d",
+		version, policerClassifyBuckets))
+	if err != nil {
+		return nil, err
+	}
+	egress, err := p.createClassifyTable(fmt.Sprintf("classify table mask l3 %s src buckets %d next-table %d",
+		version, policerClassifyBuckets, ingress))
+	if err != nil {
+		return nil, err
+	}
+	tables := &policerTables{egress: egress, ingress: ingress}
+	p.tables[version] = tables
+	return tables, nil
+}
+
+// createClassifyTable creates a classify table using the given CLI command and returns its index.
+func (p *vppPolicers) createClassifyTable(cmd string) (uint32, error) {
+	// the CLI does not print the index of a new table, find it by comparing the list of tables
+	before, err := p.listClassifyTables()
+	if err != nil {
+		return 0, err
+	}
+	err = p.cli(cmd)
+	if err != nil {
+		return 0, err
+	}
+	after, err := p.listClassifyTables()
+	if err != nil {
+		return 0, err
+	}
+	for idx := range after {
+		if _, existed := before[idx]; !existed {
+			return idx, nil
+		}
+	}
+	return 0, fmt.Errorf("classify table for POD policers not found in VPP")
+}
+
+// listClassifyTables returns indexes of the classify tables configured in VPP.
+func (p *vppPolicers) listClassifyTables() (map[uint32]struct{}, error) {
+	out, err := p.cliOutput("show classify tables")
+	if err != nil {
+		return nil, err
+	}
+	tables := map[uint32]struct{}{}
+	for _, line := range strings.Split(out, "\n") {
+		// table rows start with the table index, the header and detail lines do not
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		idx, err := strconv.ParseUint(fields[0], 10, 32)
+		if err != nil {
+			continue
+		}
+		tables[uint32(idx)] = struct{}{}
+	}
+	return tables, nil
+}
+
+// ipVersion returns the IP version of the address as used by the VPP classifier CLI.
+func ipVersion(ip net.IP) string {
+	if ip.To4() != nil {
+		return "ip4"
+	}
+	return "ip6"
+}
@@ -16,6 +16,7 @@ package ksr
 
 import (
 	"encoding/json"
+	"strings"
 	"sync"
 
 	"github.com/ligato/cn-infra/db/keyval"
@@ -134,6 +135,18 @@ func (mock *mockKeyProtoValBroker) Delete(key string, opts ...datasync.DelOption
 		return false, mock.rwErr
 	}
 
+	for _, opt := range opts {
+		if _, withPrefix := opt.(*datasync.WithPrefixOpt); withPrefix {
+			for dsKey := range mock.ds {
+				if strings.HasPrefix(dsKey, key) {
+					delete(mock.ds, dsKey)
+					existed = true
+				}
+			}
+			return existed, nil
+		}
+	}
+
 	_, existed = mock.ds[key]
 	if !existed {
 		return false, nil
@@ -21,6 +21,11 @@ import (
 const (
 	// PodKeyword defines the keyword identifying Pod data.
 	PodKeyword = "pod"
+
+	// IPv6Keyword defines the keyword identifying the IPv6 addresses of the pods
+	// reflected by the Contiv agents. The keyword must not start with PodKeyword,
+	// otherwise the keys would fall under the pod key prefix watched by KSR.
+	IPv6Keyword = "ipv6pod"
 )
 
 // KeyPrefix returns the key prefix identifying all K8s Pods in the
@@ -40,3 +45,21 @@ func ParsePodFromKey(key string) (pod string, namespace string, err error) {
 func Key(name string, namespace string) string {
 	return ksrkey.Key(PodKeyword, name, namespace)
 }
+
+// IPv6KeyPrefix returns the key prefix identifying the IPv6 addresses of all
+// pods in the data store.
+func IPv6KeyPrefix() string {
+	return ksrkey.KeyPrefix(IPv6Keyword)
+}
+
+// ParsePodIPv6FromKey parses pod and namespace ids from the key of the pod's
+// IPv6 address.
+func ParsePodIPv6FromKey(key string) (pod string, namespace string, err error) {
+	return ksrkey.ParseNameFromKey(IPv6Keyword, key)
+}
+
+// IPv6Key returns the key under which the IPv6 address of a given pod is stored
+// in the data store.
+func IPv6Key(name string, namespace string) string {
+	return ksrkey.Key(IPv6Keyword, name, namespace)
+}
@@ -80,6 +80,14 @@ type Pod struct {
 	// There must be at least one container in a Pod.
 	// Cannot be updated.
 	Container []*Pod_Container `protobuf:"bytes,6,rep,name=container" json:"container,omitempty"`
+	// A list of annotations attached to this pod.
+	// +optional
+	Annotation []*Pod_Annotation `protobuf:"bytes,7,rep,name=annotation" json:"annotation,omitempty"`
+	// IPv6 address allocated to the pod if dual-stack is enabled. K8s reports
+	// only the IPv4 address, the IPv6 address is reflected by the Contiv agent
+	// of the pod's node.
+	// +optional
+	Ipv6Address string `protobuf:"bytes,8,opt,name=ipv6_address,json=ipv6Address" json:"ipv6_address,omitempty"`
 }
 
 func (m *Pod) Reset()                    { *m = Pod{} }
@@ -129,6 +137,20 @@ func (m *Pod) GetContainer() []*Pod_Container {
 	return nil
 }
 
+func (m *Pod) GetAnnotation() []*Pod_Annotation {
+	if m != nil {
+		return m.Annotation
+	}
+	return nil
+}
+
+func (m *Pod) GetIpv6Address() string {
+	if m != nil {
+		return m.Ipv6Address
+	}
+	return ""
+}
+
 // Label is a key/value pair attached to an object (pod in this case).
 // Labels are used to organize and to select subsets of objects.
 type Pod_Label struct {
@@ -255,37 +277,65 @@ func (m *Pod_Container_Port) GetHostIpAddress() string {
 	return ""
 }
 
+// Annotation is a key/value pair attached to an object (pod in this case).
+// Annotations are used to attach arbitrary non-identifying metadata.
+type Pod_Annotation struct {
+	Key   string `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
+	Value string `protobuf:"bytes,2,opt,name=value" json:"value,omitempty"`
+}
+
+func (m *Pod_Annotation) Reset()                    { *m = Pod_Annotation{} }
+func (m *Pod_Annotation) String() string            { return proto.CompactTextString(m) }
+func (*Pod_Annotation) ProtoMessage()               {}
+func (*Pod_Annotation) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0, 2} }
+
+func (m *Pod_Annotation) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *Pod_Annotation) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*Pod)(nil), "pod.Pod")
 	proto.RegisterType((*Pod_Label)(nil), "pod.Pod.Label")
 	proto.RegisterType((*Pod_Container)(nil), "pod.Pod.Container")
 	proto.RegisterType((*Pod_Container_Port)(nil), "pod.Pod.Container.Port")
+	proto.RegisterType((*Pod_Annotation)(nil), "pod.Pod.Annotation")
 	proto.RegisterEnum("pod.Pod_Container_Port_Protocol", Pod_Container_Port_Protocol_name, Pod_Container_Port_Protocol_value)
 }
 
 func init() { proto.RegisterFile("pod.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 322 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x91, 0xdf, 0x4a, 0xc3, 0x30,
-	0x14, 0xc6, 0xed, 0xd2, 0xce, 0xe5, 0xc8, 0xe6, 0x08, 0x82, 0x61, 0x4e, 0x18, 0x43, 0x65, 0x20,
-	0x54, 0x99, 0xb7, 0xde, 0xc8, 0xbc, 0x11, 0xbc, 0x08, 0x41, 0xaf, 0x47, 0xb6, 0x04, 0x1c, 0xd6,
-	0x25, 0xb4, 0x55, 0xf0, 0xb1, 0xbc, 0xf6, 0x6d, 0x7c, 0x12, 0xc9, 0x69, 0x9b, 0x09, 0x4e, 0xf0,
-	0xaa, 0x27, 0xdf, 0xf9, 0x9d, 0xef, 0xfc, 0x29, 0x50, 0x67, 0x75, 0xea, 0x72, 0x5b, 0x5a, 0x46,
-	0x9c, 0xd5, 0xe3, 0xcf, 0x18, 0x88, 0xb0, 0x9a, 0x31, 0x88, 0xd7, 0xea, 0xc5, 0xf0, 0x68, 0x14,
-	0x4d, 0xa8, 0xc4, 0x98, 0x0d, 0x81, 0xfa, 0x6f, 0xe1, 0xd4, 0xd2, 0xf0, 0x16, 0x26, 0x36, 0x02,
-	0x3b, 0x81, 0x24, 0x53, 0x0b, 0x93, 0x71, 0x32, 0x22, 0x93, 0xbd, 0x69, 0x2f, 0xf5, 0xce, 0xc2,
-	0xea, 0xf4, 0xde, 0xab, 0xb2, 0x4a, 0xb2, 0x63, 0x80, 0x95, 0x9b, 0x2b, 0xad, 0x73, 0x53, 0x14,
-	0x3c, 0xae, 0x4c, 0x56, 0xee, 0xa6, 0x12, 0xd8, 0x19, 0xec, 0x3f, 0xd9, 0xa2, 0x9c, 0xff, 0x60,
-	0x12, 0x64, 0xba, 0x5e, 0xbe, 0x0b, 0xdc, 0x25, 0xd0, 0xa5, 0x5d, 0x97, 0x6a, 0xb5, 0x36, 0x39,
-	0x6f, 0x63, 0x43, 0x16, 0x1a, 0xce, 0x9a, 0x8c, 0xdc, 0x40, 0x83, 0x0b, 0x48, 0x70, 0x10, 0xd6,
-	0x07, 0xf2, 0x6c, 0xde, 0xeb, 0xc5, 0x7c, 0xc8, 0x0e, 0x20, 0x79, 0x53, 0xd9, 0x6b, 0xb3, 0x53,
-	0xf5, 0x18, 0x7c, 0xb4, 0x80, 0x06, 0xa7, 0xad, 0xf7, 0x38, 0x87, 0xd8, 0xd9, 0xbc, 0xe4, 0x2d,
-	0xec, 0x7f, 0xf8, 0xbb, 0x7f, 0x2a, 0x6c, 0x5e, 0x4a, 0x84, 0x06, 0x5f, 0x11, 0xc4, 0xfe, 0xb9,
-	0xd5, 0xe9, 0x08, 0x28, 0xae, 0x5d, 0xdb, 0x45, 0x93, 0x44, 0x76, 0xbc, 0x80, 0x05, 0xa7, 0xd0,
-	0x0b, 0x6b, 0x54, 0x04, 0x41, 0xa2, 0x1b, 0x54, 0xc4, 0xae, 0xa1, 0x83, 0xff, 0x71, 0x69, 0x33,
-	0xbc, 0x6b, 0x6f, 0x3a, 0xfa, 0x63, 0xa2, 0x54, 0xd4, 0x9c, 0x0c, 0x15, 0xff, 0x3d, 0xfc, 0x78,
-	0x08, 0x9d, 0xa6, 0x9a, 0xed, 0x02, 0x79, 0x98, 0x89, 0xfe, 0x8e, 0x0f, 0x1e, 0x6f, 0x45, 0x3f,
-	0x5a, 0xb4, 0xd1, 0xef, 0xea, 0x3b, 0x00, 0x00, 0xff, 0xff, 0x1e, 0x6d, 0x78, 0xcf, 0x56, 0x02,
-	0x00, 0x00,
+	// 350 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x91, 0x4f, 0x4b, 0xf3, 0x40,
+	0x10, 0xc6, 0xdf, 0x34, 0x49, 0xdb, 0xcc, 0x4b, 0x6b, 0x59, 0x05, 0x97, 0x58, 0xa1, 0x14, 0x95,
+	0x82, 0x10, 0xa5, 0xf5, 0xe8, 0xa5, 0xd4, 0x8b, 0xe0, 0x21, 0x2c, 0x7a, 0x2e, 0xdb, 0x26, 0x60,
+	0x30, 0x66, 0x96, 0x64, 0x15, 0xfc, 0x42, 0xde, 0xfd, 0x4a, 0x7e, 0x12, 0xd9, 0x49, 0xbb, 0x2d,
+	0x58, 0xa1, 0xa7, 0xcc, 0x3e, 0xf3, 0x9b, 0x3f, 0x79, 0x06, 0x02, 0x85, 0x49, 0xa4, 0x4a, 0xd4,
+	0xc8, 0x5c, 0x85, 0xc9, 0xf0, 0xd3, 0x07, 0x37, 0xc6, 0x84, 0x31, 0xf0, 0x0a, 0xf9, 0x9a, 0x72,
+	0x67, 0xe0, 0x8c, 0x02, 0x41, 0x31, 0xeb, 0x43, 0x60, 0xbe, 0x95, 0x92, 0xcb, 0x94, 0x37, 0x28,
+	0xb1, 0x11, 0xd8, 0x19, 0xf8, 0xb9, 0x5c, 0xa4, 0x39, 0x77, 0x07, 0xee, 0xe8, 0xff, 0xb8, 0x1b,
+	0x99, 0xce, 0x31, 0x26, 0xd1, 0x83, 0x51, 0x45, 0x9d, 0x64, 0xa7, 0x00, 0x99, 0x9a, 0xcb, 0x24,
+	0x29, 0xd3, 0xaa, 0xe2, 0x5e, 0xdd, 0x24, 0x53, 0xd3, 0x5a, 0x60, 0x17, 0x70, 0xf0, 0x8c, 0x95,
+	0x9e, 0x6f, 0x31, 0x3e, 0x31, 0x1d, 0x23, 0xdf, 0x5b, 0xee, 0x1a, 0x82, 0x25, 0x16, 0x5a, 0x66,
+	0x45, 0x5a, 0xf2, 0x26, 0x0d, 0x64, 0x76, 0xe0, 0x6c, 0x9d, 0x11, 0x1b, 0x88, 0x4d, 0x00, 0x64,
+	0x51, 0xa0, 0x96, 0x3a, 0xc3, 0x82, 0xb7, 0xa8, 0xe4, 0xd0, 0x96, 0x4c, 0x6d, 0x4a, 0x6c, 0x61,
+	0xe1, 0x15, 0xf8, 0xb4, 0x3d, 0xeb, 0x81, 0xfb, 0x92, 0x7e, 0xac, 0xdc, 0x30, 0x21, 0x3b, 0x02,
+	0xff, 0x5d, 0xe6, 0x6f, 0x6b, 0x23, 0xea, 0x47, 0xf8, 0xd5, 0x80, 0xc0, 0x8e, 0xdf, 0x69, 0xe2,
+	0x25, 0x78, 0x0a, 0x4b, 0xcd, 0x1b, 0xb4, 0xc1, 0xf1, 0xef, 0xa5, 0xa3, 0x18, 0x4b, 0x2d, 0x08,
+	0x0a, 0xbf, 0x1d, 0xf0, 0xcc, 0x73, 0x67, 0xa7, 0x13, 0x08, 0xc8, 0xab, 0x55, 0x3b, 0x67, 0xe4,
+	0x8b, 0xb6, 0x11, 0xa8, 0xe0, 0x1c, 0xba, 0xf6, 0xdf, 0x6b, 0xc2, 0x25, 0xa2, 0x63, 0x55, 0xc2,
+	0x6e, 0xa1, 0x4d, 0xc7, 0x5f, 0x62, 0x4e, 0xc7, 0xe8, 0x8e, 0x07, 0x7f, 0x6c, 0x14, 0xc5, 0x2b,
+	0x4e, 0xd8, 0x8a, 0x7d, 0xaf, 0x35, 0xec, 0x43, 0x7b, 0x5d, 0xcd, 0x5a, 0xe0, 0x3e, 0xce, 0xe2,
+	0xde, 0x3f, 0x13, 0x3c, 0xdd, 0xc5, 0x3d, 0x27, 0xbc, 0x01, 0xd8, 0xd8, 0xbf, 0xaf, 0xd3, 0x8b,
+	0x26, 0x6d, 0x31, 0xf9, 0x19, 0x00, 0x25, 0x6e, 0xba, 0xbd, 0xc1, 0x02, 0x00, 0x00,
 }
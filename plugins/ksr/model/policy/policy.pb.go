@@ -98,23 +98,29 @@ func (Policy_LabelSelector_LabelExpression_Operator) EnumDescriptor() ([]byte, [
 	return fileDescriptor0, []int{0, 1, 0, 0}
 }
 
-// The protocol (TCP or UDP) which traffic must match.
+// The protocol (TCP, UDP, SCTP or ICMP) which traffic must match.
 // If not specified, this field defaults to TCP.
 // +optional
 type Policy_Port_Protocol int32
 
 const (
-	Policy_Port_TCP Policy_Port_Protocol = 0
-	Policy_Port_UDP Policy_Port_Protocol = 1
+	Policy_Port_TCP  Policy_Port_Protocol = 0
+	Policy_Port_UDP  Policy_Port_Protocol = 1
+	Policy_Port_SCTP Policy_Port_Protocol = 2
+	Policy_Port_ICMP Policy_Port_Protocol = 3
 )
 
 var Policy_Port_Protocol_name = map[int32]string{
 	0: "TCP",
 	1: "UDP",
+	2: "SCTP",
+	3: "ICMP",
 }
 var Policy_Port_Protocol_value = map[string]int32{
-	"TCP": 0,
-	"UDP": 1,
+	"TCP":  0,
+	"UDP":  1,
+	"SCTP": 2,
+	"ICMP": 3,
 }
 
 func (x Policy_Port_Protocol) String() string {
@@ -356,6 +362,16 @@ type Policy_Port struct {
 	// will be matched.
 	// +optional
 	Port *Policy_Port_PortNameOrNumber `protobuf:"bytes,1,opt,name=port" json:"port,omitempty"`
+	// If specified (with ICMP protocol), only the selected ICMP messages
+	// will be matched.
+	// If this field is not provided, the rule matches all ICMP messages.
+	// +optional
+	Icmp *Policy_Port_ICMPMessages `protobuf:"bytes,4,opt,name=icmp" json:"icmp,omitempty"`
+	// If set, indicates that the range of ports from port to end_port,
+	// inclusive, should be matched. The port must be numerical and
+	// end_port must be equal or greater than the port.
+	// +optional
+	EndPort int32 `protobuf:"varint,5,opt,name=end_port,json=endPort" json:"end_port,omitempty"`
 }
 
 func (m *Policy_Port) Reset()                    { *m = Policy_Port{} }
@@ -377,6 +393,20 @@ func (m *Policy_Port) GetPort() *Policy_Port_PortNameOrNumber {
 	return nil
 }
 
+func (m *Policy_Port) GetIcmp() *Policy_Port_ICMPMessages {
+	if m != nil {
+		return m.Icmp
+	}
+	return nil
+}
+
+func (m *Policy_Port) GetEndPort() int32 {
+	if m != nil {
+		return m.EndPort
+	}
+	return 0
+}
+
 // Numerical or named port.
 type Policy_Port_PortNameOrNumber struct {
 	Type Policy_Port_PortNameOrNumber_Type `protobuf:"varint,1,opt,name=type,enum=policy.Policy_Port_PortNameOrNumber_Type" json:"type,omitempty"`
@@ -414,6 +444,43 @@ func (m *Policy_Port_PortNameOrNumber) GetName() string {
 	return ""
 }
 
+// Selection of ICMP messages.
+type Policy_Port_ICMPMessages struct {
+	// ICMP type.
+	Type uint32 `protobuf:"varint,1,opt,name=type" json:"type,omitempty"`
+	// ICMP code, ignored if any_code is true.
+	Code uint32 `protobuf:"varint,2,opt,name=code" json:"code,omitempty"`
+	// If true, the messages of the given type are matched regardless
+	// of the code.
+	AnyCode bool `protobuf:"varint,3,opt,name=any_code,json=anyCode" json:"any_code,omitempty"`
+}
+
+func (m *Policy_Port_ICMPMessages) Reset()                    { *m = Policy_Port_ICMPMessages{} }
+func (m *Policy_Port_ICMPMessages) String() string            { return proto.CompactTextString(m) }
+func (*Policy_Port_ICMPMessages) ProtoMessage()               {}
+func (*Policy_Port_ICMPMessages) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0, 2, 1} }
+
+func (m *Policy_Port_ICMPMessages) GetType() uint32 {
+	if m != nil {
+		return m.Type
+	}
+	return 0
+}
+
+func (m *Policy_Port_ICMPMessages) GetCode() uint32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *Policy_Port_ICMPMessages) GetAnyCode() bool {
+	if m != nil {
+		return m.AnyCode
+	}
+	return false
+}
+
 // A selector for a set of pods.
 type Policy_Peer struct {
 	// This is a label selector which selects Pods in this namespace.
@@ -576,6 +643,7 @@ func init() {
 	proto.RegisterType((*Policy_LabelSelector_LabelExpression)(nil), "policy.Policy.LabelSelector.LabelExpression")
 	proto.RegisterType((*Policy_Port)(nil), "policy.Policy.Port")
 	proto.RegisterType((*Policy_Port_PortNameOrNumber)(nil), "policy.Policy.Port.PortNameOrNumber")
+	proto.RegisterType((*Policy_Port_ICMPMessages)(nil), "policy.Policy.Port.ICMPMessages")
 	proto.RegisterType((*Policy_Peer)(nil), "policy.Policy.Peer")
 	proto.RegisterType((*Policy_Peer_IPBlock)(nil), "policy.Policy.Peer.IPBlock")
 	proto.RegisterType((*Policy_IngressRule)(nil), "policy.Policy.IngressRule")
@@ -589,49 +657,55 @@ func init() {
 func init() { proto.RegisterFile("policy.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 703 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x54, 0xdd, 0x6e, 0xd3, 0x4a,
-	0x10, 0x8e, 0x1d, 0xe7, 0xa7, 0xe3, 0x9e, 0xd6, 0xda, 0x56, 0x55, 0x8e, 0x4f, 0x2e, 0xa2, 0x1c,
-	0x24, 0x02, 0x42, 0x06, 0x05, 0x15, 0x55, 0x88, 0x22, 0xb5, 0xc4, 0xa0, 0xa0, 0xd6, 0x31, 0x9b,
-	0x54, 0x20, 0x6e, 0x2c, 0xc7, 0x5d, 0xc0, 0xaa, 0x93, 0xb5, 0x36, 0x0e, 0x6a, 0x9e, 0xa5, 0xef,
-	0xc1, 0x53, 0xf0, 0x1a, 0xdc, 0xf2, 0x0c, 0x68, 0xc7, 0x8e, 0x5d, 0x42, 0x54, 0x0a, 0x57, 0x9e,
-	0x99, 0xfd, 0xbe, 0xd9, 0x99, 0xcf, 0x33, 0x0b, 0x9b, 0x31, 0x8f, 0xc2, 0x60, 0x61, 0xc5, 0x82,
-	0x27, 0x9c, 0x54, 0x53, 0xaf, 0x7d, 0xb5, 0x09, 0x55, 0x17, 0x4d, 0x42, 0x40, 0x9b, 0xfa, 0x13,
-	0xd6, 0x50, 0x5a, 0x4a, 0x67, 0x83, 0xa2, 0x4d, 0x9a, 0xb0, 0x21, 0xbf, 0xb3, 0xd8, 0x0f, 0x58,
-	0x43, 0xc5, 0x83, 0x22, 0x40, 0xee, 0x43, 0x25, 0xf2, 0xc7, 0x2c, 0x6a, 0x94, 0x5b, 0xe5, 0x8e,
-	0xde, 0xdd, 0xb5, 0xb2, 0x2b, 0xd2, 0x84, 0xd6, 0x89, 0x3c, 0xa3, 0x29, 0x84, 0x3c, 0x02, 0x2d,
-	0xe6, 0xe7, 0xb3, 0x86, 0xd6, 0x52, 0x3a, 0x7a, 0xb7, 0xb9, 0x0e, 0x3a, 0x64, 0x11, 0x0b, 0x12,
-	0x2e, 0x28, 0x22, 0xc9, 0x53, 0xd0, 0x53, 0x90, 0x97, 0x2c, 0x62, 0xd6, 0xa8, 0xb4, 0x94, 0xce,
-	0x56, 0xf7, 0xdf, 0x15, 0x62, 0xfa, 0x19, 0x2d, 0x62, 0x46, 0x21, 0xce, 0x6d, 0x72, 0x08, 0x9b,
-	0xe1, 0xf4, 0xa3, 0x60, 0xb3, 0x99, 0x27, 0xe6, 0x11, 0x6b, 0x54, 0xb1, 0x40, 0x73, 0x85, 0xdc,
-	0x4f, 0x21, 0x74, 0x1e, 0x31, 0xaa, 0x87, 0x85, 0x23, 0xaf, 0x66, 0xd7, 0xd8, 0x35, 0x64, 0xaf,
-	0x5e, 0x6d, 0x17, 0x64, 0x60, 0xb9, 0x6d, 0x3e, 0x84, 0x0a, 0x76, 0x43, 0x0c, 0x28, 0x5f, 0xb0,
-	0x45, 0x26, 0xa7, 0x34, 0xc9, 0x2e, 0x54, 0x3e, 0xfb, 0xd1, 0x7c, 0xa9, 0x64, 0xea, 0x98, 0xdf,
-	0x55, 0xf8, 0xe7, 0xa7, 0xfe, 0xc9, 0x3e, 0xe8, 0x13, 0x3f, 0x09, 0x3e, 0x79, 0xa9, 0xba, 0xca,
-	0x0d, 0xea, 0x02, 0x02, 0xd3, 0x0b, 0xdf, 0x82, 0x91, 0xd2, 0xd8, 0x65, 0x2c, 0xcb, 0x09, 0xf9,
-	0xb4, 0xa1, 0x22, 0xf7, 0xc1, 0x4d, 0x72, 0xa7, 0x9e, 0x9d, 0x73, 0xe8, 0x36, 0x66, 0x29, 0x02,
-	0xe6, 0x57, 0x05, 0xb6, 0x57, 0x40, 0x6b, 0xba, 0x7b, 0x03, 0x75, 0x1e, 0x33, 0xe1, 0x27, 0x5c,
-	0x60, 0x83, 0x5b, 0xdd, 0xfd, 0x3f, 0xb9, 0xd6, 0x1a, 0x64, 0x64, 0x9a, 0xa7, 0x29, 0x04, 0x93,
-	0x03, 0xb6, 0x14, 0xac, 0xfd, 0x1c, 0xea, 0x4b, 0x2c, 0xa9, 0x82, 0xda, 0x77, 0x8c, 0x12, 0x01,
-	0xa8, 0x3a, 0x83, 0x91, 0xd7, 0x77, 0x0c, 0x45, 0xda, 0xf6, 0xbb, 0xfe, 0x70, 0x34, 0x34, 0x54,
-	0x42, 0x60, 0xab, 0x37, 0xb0, 0x87, 0x9e, 0x3c, 0xc4, 0xa0, 0x51, 0x36, 0xbf, 0xa8, 0xa0, 0xb9,
-	0x5c, 0x24, 0xe4, 0x00, 0xea, 0xb8, 0x0d, 0x01, 0x97, 0x23, 0x2c, 0x2b, 0x6e, 0xfe, 0x32, 0x5e,
-	0x22, 0xb1, 0xdc, 0x0c, 0x43, 0x73, 0x34, 0x39, 0x90, 0xd3, 0x2c, 0x12, 0x6c, 0x5f, 0xef, 0xde,
-	0x59, 0xcb, 0xe2, 0x22, 0x71, 0xfc, 0x09, 0x1b, 0x08, 0x67, 0x3e, 0x19, 0x33, 0x9c, 0x6a, 0x91,
-	0x98, 0x57, 0x0a, 0x18, 0xab, 0x47, 0xe4, 0x10, 0x34, 0x9c, 0x71, 0x05, 0x8b, 0xb8, 0x77, 0x9b,
-	0x74, 0x16, 0xce, 0x3c, 0xd2, 0xc8, 0x1e, 0x54, 0xa7, 0x18, 0x44, 0xdd, 0x2b, 0x34, 0xf3, 0xf2,
-	0x8d, 0x2e, 0x17, 0x1b, 0xdd, 0x6e, 0x82, 0x86, 0x1b, 0x22, 0x05, 0x3b, 0x3b, 0x3d, 0xb6, 0xa9,
-	0x51, 0x22, 0x75, 0xd0, 0x9c, 0xa3, 0x53, 0xdb, 0x50, 0xda, 0x4d, 0xa8, 0x2f, 0xbb, 0x25, 0x35,
-	0x28, 0x8f, 0x5e, 0xb8, 0x46, 0x49, 0x1a, 0x67, 0x3d, 0xd7, 0x50, 0xcc, 0x6f, 0x0a, 0x68, 0x2e,
-	0x63, 0x22, 0x5f, 0x66, 0xe5, 0xd6, 0xcb, 0xfc, 0x0c, 0x20, 0x7f, 0x37, 0x66, 0x58, 0xe6, 0xef,
-	0x78, 0xd7, 0xf0, 0xe4, 0x09, 0xd4, 0xc3, 0xd8, 0x1b, 0x47, 0x3c, 0xb8, 0xc0, 0x66, 0xf4, 0xee,
-	0x7f, 0xab, 0x1a, 0x31, 0x26, 0xac, 0xbe, 0x7b, 0x2c, 0x21, 0xb4, 0x16, 0xc6, 0x68, 0x98, 0xfb,
-	0x50, 0xcb, 0x62, 0x52, 0x8b, 0x20, 0x3c, 0x17, 0xcb, 0xd7, 0x4d, 0xda, 0x52, 0x37, 0x76, 0x19,
-	0xb0, 0x38, 0xc1, 0x35, 0xd9, 0xa0, 0x99, 0x67, 0x7a, 0xa0, 0x5f, 0x7b, 0x1a, 0xc8, 0xdd, 0xfc,
-	0x67, 0xcb, 0x5d, 0xda, 0x59, 0xf3, 0x77, 0xd2, 0x7f, 0x2b, 0x81, 0x1f, 0x04, 0x9f, 0x64, 0x4b,
-	0xb7, 0xb3, 0xa6, 0x44, 0x8a, 0x00, 0xf3, 0x3d, 0x80, 0xfd, 0x17, 0xf9, 0xff, 0x07, 0x35, 0xe1,
-	0x37, 0x65, 0x57, 0x13, 0xde, 0x7e, 0x0d, 0x50, 0x3c, 0x8a, 0x44, 0x87, 0x5a, 0xcf, 0x7e, 0x79,
-	0x74, 0x76, 0x32, 0x32, 0x4a, 0xd2, 0xe9, 0x3b, 0xaf, 0xa8, 0x3d, 0x1c, 0x66, 0x5b, 0x92, 0xda,
-	0x2a, 0xd9, 0x03, 0x92, 0x1d, 0x78, 0x47, 0x4e, 0xcf, 0xcb, 0xe2, 0xe5, 0x71, 0x15, 0x07, 0xfe,
-	0xf1, 0x8f, 0x00, 0x00, 0x00, 0xff, 0xff, 0x40, 0x91, 0x36, 0x48, 0x3c, 0x06, 0x00, 0x00,
+	// 791 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x54, 0xdd, 0x6e, 0xeb, 0x44,
+	0x10, 0x8e, 0x7f, 0x92, 0xb8, 0xe3, 0xa6, 0xb5, 0x96, 0xaa, 0x72, 0x4d, 0x2e, 0xa2, 0x80, 0xd4,
+	0x80, 0x50, 0xa8, 0x02, 0x45, 0x15, 0xa2, 0x48, 0x6d, 0x62, 0x50, 0x50, 0xe3, 0xb8, 0x9b, 0x54,
+	0x20, 0x6e, 0x2c, 0xc7, 0x59, 0x8a, 0x55, 0xc7, 0x6b, 0xad, 0x1d, 0xd4, 0x3c, 0x0b, 0xaf, 0xc4,
+	0x1d, 0xcf, 0xc0, 0x6d, 0x9f, 0x01, 0xed, 0xda, 0x71, 0x72, 0x72, 0xa2, 0xaa, 0xe7, 0x5c, 0xf9,
+	0x9b, 0xf1, 0xf7, 0xed, 0xec, 0xcc, 0xce, 0x0c, 0x1c, 0x26, 0x34, 0x0a, 0x83, 0x55, 0x37, 0x61,
+	0x34, 0xa3, 0xa8, 0x96, 0x5b, 0xed, 0x7f, 0x1b, 0x50, 0x73, 0x05, 0x44, 0x08, 0xd4, 0xd8, 0x5f,
+	0x10, 0x53, 0x6a, 0x49, 0x9d, 0x03, 0x2c, 0x30, 0x6a, 0xc2, 0x01, 0xff, 0xa6, 0x89, 0x1f, 0x10,
+	0x53, 0x16, 0x3f, 0x36, 0x0e, 0xf4, 0x25, 0x54, 0x23, 0x7f, 0x46, 0x22, 0x53, 0x69, 0x29, 0x1d,
+	0xbd, 0x77, 0xd2, 0x2d, 0x42, 0xe4, 0x07, 0x76, 0xef, 0xf8, 0x3f, 0x9c, 0x53, 0xd0, 0x05, 0xa8,
+	0x09, 0x9d, 0xa7, 0xa6, 0xda, 0x92, 0x3a, 0x7a, 0xaf, 0xb9, 0x8f, 0x3a, 0x21, 0x11, 0x09, 0x32,
+	0xca, 0xb0, 0x60, 0xa2, 0xef, 0x41, 0xcf, 0x49, 0x5e, 0xb6, 0x4a, 0x88, 0x59, 0x6d, 0x49, 0x9d,
+	0xa3, 0xde, 0xd9, 0x8e, 0x30, 0xff, 0x4c, 0x57, 0x09, 0xc1, 0x90, 0x94, 0x18, 0x5d, 0xc3, 0x61,
+	0x18, 0x3f, 0x32, 0x92, 0xa6, 0x1e, 0x5b, 0x46, 0xc4, 0xac, 0x89, 0x0b, 0x5a, 0x3b, 0xe2, 0x61,
+	0x4e, 0xc1, 0xcb, 0x88, 0x60, 0x3d, 0xdc, 0x18, 0x3c, 0x34, 0xd9, 0x52, 0xd7, 0x85, 0x7a, 0x37,
+	0xb4, 0xbd, 0x11, 0x03, 0x29, 0xb1, 0xf5, 0x35, 0x54, 0x45, 0x36, 0xc8, 0x00, 0xe5, 0x89, 0xac,
+	0x8a, 0x72, 0x72, 0x88, 0x4e, 0xa0, 0xfa, 0x97, 0x1f, 0x2d, 0xd7, 0x95, 0xcc, 0x0d, 0xeb, 0x45,
+	0x86, 0xc6, 0x3b, 0xf9, 0xa3, 0x4b, 0xd0, 0x17, 0x7e, 0x16, 0xfc, 0xe9, 0xe5, 0xd5, 0x95, 0x5e,
+	0xa9, 0x2e, 0x08, 0x62, 0x1e, 0xf0, 0x57, 0x30, 0x72, 0x19, 0x79, 0x4e, 0xf8, 0x75, 0x42, 0x1a,
+	0x9b, 0xb2, 0xd0, 0x7e, 0xf5, 0x5a, 0xb9, 0x73, 0xcb, 0x2e, 0x35, 0xf8, 0x58, 0x9c, 0xb2, 0x71,
+	0x58, 0xff, 0x48, 0x70, 0xbc, 0x43, 0xda, 0x93, 0xdd, 0x3d, 0x68, 0x34, 0x21, 0xcc, 0xcf, 0x28,
+	0x13, 0x09, 0x1e, 0xf5, 0x2e, 0x3f, 0x24, 0x6c, 0x77, 0x5c, 0x88, 0x71, 0x79, 0xcc, 0xa6, 0x60,
+	0xbc, 0xc1, 0xd6, 0x05, 0x6b, 0xff, 0x08, 0xda, 0x9a, 0x8b, 0x6a, 0x20, 0x0f, 0x1d, 0xa3, 0x82,
+	0x00, 0x6a, 0xce, 0x78, 0xea, 0x0d, 0x1d, 0x43, 0xe2, 0xd8, 0xfe, 0x6d, 0x38, 0x99, 0x4e, 0x0c,
+	0x19, 0x21, 0x38, 0x1a, 0x8c, 0xed, 0x89, 0xc7, 0x7f, 0x0a, 0xa7, 0xa1, 0x58, 0x2f, 0x0a, 0xa8,
+	0x2e, 0x65, 0x19, 0xba, 0x02, 0x4d, 0x4c, 0x43, 0x40, 0x79, 0x0b, 0xf3, 0x1b, 0x37, 0xdf, 0x6b,
+	0x2f, 0x96, 0x75, 0xdd, 0x82, 0x83, 0x4b, 0x36, 0xba, 0xe2, 0xdd, 0xcc, 0x32, 0x91, 0xbe, 0xde,
+	0xfb, 0x7c, 0xaf, 0x8a, 0xb2, 0xcc, 0xf1, 0x17, 0x64, 0xcc, 0x9c, 0xe5, 0x62, 0x46, 0x44, 0x57,
+	0xb3, 0x0c, 0x7d, 0x0b, 0x6a, 0x18, 0x2c, 0x92, 0x62, 0x0e, 0x5a, 0xfb, 0x94, 0xc3, 0xfe, 0xc8,
+	0x1d, 0x91, 0x34, 0xf5, 0x1f, 0x49, 0x8a, 0x05, 0x1b, 0x9d, 0x81, 0x46, 0xe2, 0xb9, 0x27, 0x62,
+	0xf2, 0x41, 0xa8, 0xe2, 0x3a, 0x89, 0xe7, 0x9c, 0x6d, 0xfd, 0x2d, 0x81, 0xb1, 0x1b, 0x0b, 0x5d,
+	0x83, 0x2a, 0x86, 0x46, 0x12, 0x59, 0x7d, 0xf1, 0x96, 0xfb, 0x75, 0xc5, 0x10, 0x09, 0x19, 0x3a,
+	0x85, 0x5a, 0x2c, 0x9c, 0xe2, 0x21, 0xab, 0xb8, 0xb0, 0xca, 0x15, 0xa1, 0x6c, 0x56, 0x44, 0xbb,
+	0x09, 0xaa, 0x18, 0x39, 0xfe, 0x02, 0x0f, 0xa3, 0x5b, 0x1b, 0x1b, 0x15, 0xa4, 0x81, 0xea, 0xdc,
+	0x8c, 0x6c, 0x43, 0xb2, 0xee, 0xe1, 0x70, 0x3b, 0x1d, 0x7e, 0x42, 0x79, 0xb1, 0x46, 0x11, 0x0d,
+	0x81, 0x1a, 0xd0, 0x79, 0x3e, 0x15, 0x0d, 0x2c, 0x30, 0x4f, 0xd8, 0x8f, 0x57, 0x9e, 0xf0, 0xf3,
+	0x68, 0x1a, 0xae, 0xfb, 0xf1, 0xaa, 0x4f, 0xe7, 0xa4, 0x7d, 0x01, 0xda, 0xfa, 0x45, 0x50, 0x1d,
+	0x94, 0x69, 0xdf, 0x35, 0x2a, 0x1c, 0x3c, 0x0c, 0x5c, 0x43, 0xe2, 0xa1, 0x27, 0xfd, 0xa9, 0x6b,
+	0xc8, 0x1c, 0xf1, 0xd0, 0x86, 0x62, 0xfd, 0x27, 0x81, 0xea, 0x12, 0xc2, 0xca, 0x25, 0x24, 0xbd,
+	0x79, 0x09, 0xfd, 0x00, 0x50, 0xee, 0xbb, 0xd4, 0x94, 0xdf, 0xa0, 0xdb, 0xe2, 0xa3, 0xef, 0x40,
+	0x0b, 0x13, 0x6f, 0x16, 0xd1, 0xe0, 0x49, 0x64, 0xa1, 0xf7, 0x3e, 0xdd, 0x7d, 0x0a, 0x42, 0x58,
+	0x77, 0xe8, 0xde, 0x72, 0x0a, 0xae, 0x87, 0x89, 0x00, 0xd6, 0x25, 0xd4, 0x0b, 0x9f, 0x28, 0x4e,
+	0x38, 0x67, 0xeb, 0xad, 0xcc, 0x31, 0x7f, 0x1e, 0xf2, 0x1c, 0x90, 0x24, 0x13, 0xe3, 0x7d, 0x80,
+	0x0b, 0xcb, 0xf2, 0x40, 0xdf, 0x5a, 0x69, 0xe8, 0xbc, 0x6c, 0x52, 0xbe, 0x03, 0x3e, 0xd9, 0xd3,
+	0x04, 0x45, 0x4f, 0x9e, 0x83, 0xfa, 0x07, 0xa3, 0x0b, 0x53, 0xde, 0x4f, 0x24, 0xbc, 0x79, 0x39,
+	0xc1, 0xfa, 0x1d, 0xc0, 0xfe, 0x88, 0xf3, 0x3f, 0x03, 0x39, 0xa3, 0xaf, 0x9d, 0x2e, 0x67, 0xb4,
+	0xfd, 0x0b, 0xc0, 0x66, 0x99, 0x23, 0x1d, 0xea, 0x03, 0xfb, 0xa7, 0x9b, 0x87, 0xbb, 0xa9, 0x51,
+	0xe1, 0xc6, 0xd0, 0xf9, 0x19, 0xdb, 0x93, 0x49, 0x31, 0xdd, 0x39, 0x96, 0xd1, 0x29, 0xa0, 0xe2,
+	0x87, 0x77, 0xe3, 0x0c, 0xbc, 0xc2, 0xaf, 0xcc, 0x6a, 0x62, 0x50, 0xbf, 0xf9, 0x7f, 0x00, 0x41,
+	0x7d, 0x44, 0x32, 0xf4, 0x06, 0x00, 0x00,
 }
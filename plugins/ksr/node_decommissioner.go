@@ -0,0 +1,234 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ksr
+
+import (
+	"sync"
+	"time"
+
+	ipamModel "github.com/contiv/vpp/plugins/contiv/ipam/model"
+	nodeinfo "github.com/contiv/vpp/plugins/contiv/model/node"
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/logging"
+)
+
+// NodeDecommissionDelay is the time between the removal of a node from the K8s cluster
+// and the release of the data allocated for the node by its contiv agent.
+const NodeDecommissionDelay = 5 * time.Minute
+
+// nodeDecommissioner releases the data allocated in the data store by the contiv agent
+// of a node (the node ID and the IPAM data) once the node is removed from the K8s cluster.
+// The other nodes then remove their tunnels and routes towards the node and the ID and
+// the pod CIDR blocks of the node can be allocated to another node.
+//
+// The node is decommissioned only after its K8s node object is deleted (never when the node
+// is only NotReady or unreachable) and only if the node object is not re-created in the K8s
+// cluster within the decommission delay.
+type nodeDecommissioner struct {
+	Log logging.Logger
+
+	// Broker is the interface to the data store with the cluster-wide data
+	// of the contiv agents (the KSR prefix).
+	Broker KeyProtoValBroker
+
+	// NodeBroker returns the interface to the data store with the data
+	// of the contiv agent running on the given node.
+	NodeBroker func(nodeName string) KeyProtoValBroker
+
+	// NodeExists checks if the node with the given name exists in the K8s cluster.
+	NodeExists func(nodeName string) (bool, error)
+
+	// Delay between the removal of a node and the release of its data.
+	Delay time.Duration
+
+	sync.Mutex
+	pending map[string]*time.Timer
+	stopped bool
+}
+
+// schedule schedules the decommissioning of the given node removed from the K8s cluster.
+func (nd *nodeDecommissioner) schedule(nodeName string) {
+	nd.Lock()
+	defer nd.Unlock()
+
+	if nd.stopped {
+		return
+	}
+	if nd.pending == nil {
+		nd.pending = make(map[string]*time.Timer)
+	}
+	if timer, scheduled := nd.pending[nodeName]; scheduled {
+		timer.Stop()
+	}
+	nd.Log.Infof("Node %s removed, decommissioning scheduled in %v", nodeName, nd.Delay)
+	nd.pending[nodeName] = time.AfterFunc(nd.Delay, func() {
+		nd.run(nodeName)
+	})
+}
+
+// cancel cancels the decommissioning of the given node re-added into the K8s cluster.
+func (nd *nodeDecommissioner) cancel(nodeName string) {
+	nd.Lock()
+	defer nd.Unlock()
+
+	if timer, scheduled := nd.pending[nodeName]; scheduled {
+		timer.Stop()
+		delete(nd.pending, nodeName)
+		nd.Log.Infof("Node %s re-added, decommissioning canceled", nodeName)
+	}
+}
+
+// close cancels all scheduled decommissionings.
+func (nd *nodeDecommissioner) close() {
+	nd.Lock()
+	defer nd.Unlock()
+
+	for _, timer := range nd.pending {
+		timer.Stop()
+	}
+	nd.pending = nil
+	nd.stopped = true
+}
+
+// resync schedules the decommissioning of the nodes with the ID allocated in the data store,
+// which were removed from the K8s cluster while KSR was not running. Only the nodes with
+// the management IP known are considered, i.e. the nodes known to the K8s cluster at some point.
+func (nd *nodeDecommissioner) resync() error {
+	nodes, err := nd.listNodeInfo()
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		if node.ManagementIpAddress == "" {
+			continue
+		}
+		exists, err := nd.NodeExists(node.Name)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			nd.schedule(node.Name)
+		}
+	}
+	return nil
+}
+
+// run decommissions the given node unless it was re-added into the K8s cluster in the meantime.
+// A failed decommissioning is re-scheduled.
+func (nd *nodeDecommissioner) run(nodeName string) {
+	nd.Lock()
+	delete(nd.pending, nodeName)
+	nd.Unlock()
+
+	exists, err := nd.NodeExists(nodeName)
+	if err == nil && exists {
+		nd.Log.Infof("Node %s exists in the cluster, not decommissioned", nodeName)
+		return
+	}
+	if err == nil {
+		err = nd.decommission(nodeName)
+	}
+	if err != nil {
+		nd.Log.Errorf("Failed to decommission node %s: %v", nodeName, err)
+		nd.schedule(nodeName)
+	}
+}
+
+// decommission releases the data allocated for the given node: the pod CIDR blocks, the IPAM
+// data of the node and, as the last one, the ID of the node. The removal of the ID is watched
+// by the contiv agents of the other nodes, which remove their tunnels and routes towards the node.
+func (nd *nodeDecommissioner) decommission(nodeName string) error {
+	nodes, err := nd.listNodeInfo()
+	if err != nil {
+		return err
+	}
+	var ids []uint32
+	for _, node := range nodes {
+		if node.Name == nodeName {
+			ids = append(ids, node.Id)
+		}
+	}
+
+	// pod CIDR blocks owned by the node
+	if len(ids) > 0 {
+		blocks, err := nd.Broker.ListValues(ipamModel.PodCIDRBlockKeyPrefix())
+		if err != nil {
+			return err
+		}
+		for {
+			kv, stop := blocks.GetNext()
+			if stop {
+				break
+			}
+			block := &ipamModel.PodCIDRBlock{}
+			err = kv.GetValue(block)
+			if err != nil {
+				return err
+			}
+			for _, id := range ids {
+				if block.NodeID == id {
+					nd.Log.Infof("Releasing pod CIDR block %s of node %s", block.Network, nodeName)
+					_, err = nd.Broker.Delete(ipamModel.PodCIDRBlockKey(block.Network))
+					if err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	// IPAM data of the node
+	nodeBroker := nd.NodeBroker(nodeName)
+	for _, prefix := range []string{ipamModel.KeyPrefix(), ipamModel.StickyIPKeyPrefix(),
+		ipamModel.SecondaryIPKeyPrefix()} {
+		_, err = nodeBroker.Delete(prefix, datasync.WithPrefix())
+		if err != nil {
+			return err
+		}
+	}
+
+	// ID of the node
+	for _, id := range ids {
+		nd.Log.Infof("Releasing ID %d of node %s", id, nodeName)
+		_, err = nd.Broker.Delete(nodeinfo.AllocatedIDKey(id))
+		if err != nil {
+			return err
+		}
+	}
+	nd.Log.Infof("Node %s decommissioned", nodeName)
+	return nil
+}
+
+// listNodeInfo returns the nodes with the ID allocated in the data store.
+func (nd *nodeDecommissioner) listNodeInfo() ([]*nodeinfo.NodeInfo, error) {
+	it, err := nd.Broker.ListValues(nodeinfo.AllocatedIDsKeyPrefix)
+	if err != nil {
+		return nil, err
+	}
+	var nodes []*nodeinfo.NodeInfo
+	for {
+		kv, stop := it.GetNext()
+		if stop {
+			break
+		}
+		node := &nodeinfo.NodeInfo{}
+		err = kv.GetValue(node)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
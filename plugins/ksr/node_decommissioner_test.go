@@ -0,0 +1,126 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ksr
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+
+	ipamModel "github.com/contiv/vpp/plugins/contiv/ipam/model"
+	nodeinfo "github.com/contiv/vpp/plugins/contiv/model/node"
+	"github.com/ligato/cn-infra/flavors/local"
+)
+
+func TestNodeDecommissioner(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	flavorLocal := &local.FlavorLocal{}
+	flavorLocal.Inject()
+
+	broker := newMockKeyProtoValBroker()
+	nodeBrokers := map[string]*mockKeyProtoValBroker{
+		"worker1": newMockKeyProtoValBroker(),
+		"worker2": newMockKeyProtoValBroker(),
+		"worker3": newMockKeyProtoValBroker(),
+	}
+	var existsMtx sync.Mutex
+	existingNodes := map[string]bool{"worker1": true, "worker2": true, "worker3": true}
+	setNodeExists := func(nodeName string, exists bool) {
+		existsMtx.Lock()
+		defer existsMtx.Unlock()
+		existingNodes[nodeName] = exists
+	}
+
+	nd := &nodeDecommissioner{
+		Log:    flavorLocal.LoggerFor("node-decommissioner"),
+		Broker: broker,
+		NodeBroker: func(nodeName string) KeyProtoValBroker {
+			return nodeBrokers[nodeName]
+		},
+		NodeExists: func(nodeName string) (bool, error) {
+			existsMtx.Lock()
+			defer existsMtx.Unlock()
+			return existingNodes[nodeName], nil
+		},
+		Delay: 10 * time.Millisecond,
+	}
+	defer nd.close()
+
+	// worker3 never learned its management IP from K8s
+	nodes := []*nodeinfo.NodeInfo{
+		{Id: 1, Name: "worker1", IpAddress: "192.168.16.1/24", ManagementIpAddress: "10.0.0.1"},
+		{Id: 2, Name: "worker2", IpAddress: "192.168.16.2/24", ManagementIpAddress: "10.0.0.2"},
+		{Id: 3, Name: "worker3", IpAddress: "192.168.16.3/24"},
+	}
+	for _, node := range nodes {
+		broker.Put(nodeinfo.AllocatedIDKey(node.Id), node)
+		block := &ipamModel.PodCIDRBlock{Network: fmt.Sprintf("10.1.1%d.0/24", node.Id), NodeID: node.Id}
+		broker.Put(ipamModel.PodCIDRBlockKey(block.Network), block)
+		nodeBroker := nodeBrokers[node.Name]
+		nodeBroker.Put(ipamModel.Key("pod1"), &ipamModel.AllocatedIP{ID: 2, Pod: "pod1"})
+		nodeBroker.Put(ipamModel.StickyIPKey("default/pod1"), &ipamModel.AllocatedIP{ID: 2, Pod: "default/pod1"})
+		nodeBroker.Put("vpp/config/v1/interface/loop0", &nodeinfo.NodeInfo{})
+	}
+	idAllocated := func(id uint32) func() bool {
+		return func() bool {
+			found, _, _ := broker.GetValue(nodeinfo.AllocatedIDKey(id), &nodeinfo.NodeInfo{})
+			return found
+		}
+	}
+
+	// the node re-added before the delay elapses is not decommissioned
+	setNodeExists("worker1", false)
+	nd.schedule("worker1")
+	nd.cancel("worker1")
+	gomega.Consistently(idAllocated(1), 50*time.Millisecond).Should(gomega.BeTrue())
+
+	// the node existing in the cluster once the delay elapses is not decommissioned
+	setNodeExists("worker1", true)
+	nd.schedule("worker1")
+	gomega.Consistently(idAllocated(1), 50*time.Millisecond).Should(gomega.BeTrue())
+
+	// the data of the removed node are released
+	setNodeExists("worker1", false)
+	nd.schedule("worker1")
+	gomega.Eventually(idAllocated(1)).Should(gomega.BeFalse())
+	found, _, _ := broker.GetValue(ipamModel.PodCIDRBlockKey("10.1.11.0/24"), &ipamModel.PodCIDRBlock{})
+	gomega.Expect(found).To(gomega.BeFalse())
+	found, _, _ = nodeBrokers["worker1"].GetValue(ipamModel.Key("pod1"), &ipamModel.AllocatedIP{})
+	gomega.Expect(found).To(gomega.BeFalse())
+	found, _, _ = nodeBrokers["worker1"].GetValue(ipamModel.StickyIPKey("default/pod1"), &ipamModel.AllocatedIP{})
+	gomega.Expect(found).To(gomega.BeFalse())
+	found, _, _ = nodeBrokers["worker1"].GetValue("vpp/config/v1/interface/loop0", &nodeinfo.NodeInfo{})
+	gomega.Expect(found).To(gomega.BeTrue())
+
+	// the data of the other nodes are kept
+	gomega.Expect(idAllocated(2)()).To(gomega.BeTrue())
+	found, _, _ = broker.GetValue(ipamModel.PodCIDRBlockKey("10.1.12.0/24"), &ipamModel.PodCIDRBlock{})
+	gomega.Expect(found).To(gomega.BeTrue())
+	found, _, _ = nodeBrokers["worker2"].GetValue(ipamModel.Key("pod1"), &ipamModel.AllocatedIP{})
+	gomega.Expect(found).To(gomega.BeTrue())
+
+	// the nodes removed while KSR was not running are decommissioned by the resync,
+	// except for the nodes which never learned their management IP
+	setNodeExists("worker2", false)
+	setNodeExists("worker3", false)
+	err := nd.resync()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Eventually(idAllocated(2)).Should(gomega.BeFalse())
+	gomega.Consistently(idAllocated(3), 50*time.Millisecond).Should(gomega.BeTrue())
+}
@@ -30,6 +30,9 @@ import (
 // into the selected key-value store.
 type NodeReflector struct {
 	Reflector
+
+	// decommissioner releases the data of the removed nodes, nil if disabled
+	decommissioner *nodeDecommissioner
 }
 
 // Init subscribes to K8s cluster to watch for changes in the configuration
@@ -79,6 +82,10 @@ func (nr *NodeReflector) addNode(obj interface{}) {
 	nodeProto := nr.nodeToProto(k8sNode)
 	key := node.Key(k8sNode.GetName())
 	nr.ksrAdd(key, nodeProto)
+
+	if nr.decommissioner != nil {
+		nr.decommissioner.cancel(k8sNode.GetName())
+	}
 }
 
 // deleteNode deletes data of a removed K8s node from the data store.
@@ -93,6 +100,10 @@ func (nr *NodeReflector) deleteNode(obj interface{}) {
 
 	key := node.Key(k8sNode.GetName())
 	nr.ksrDelete(key)
+
+	if nr.decommissioner != nil {
+		nr.decommissioner.schedule(k8sNode.GetName())
+	}
 }
 
 // updateNode updates  data of a changed K8s node from the data store.
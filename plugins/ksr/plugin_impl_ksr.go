@@ -29,6 +29,8 @@ import (
 
 	"github.com/contiv/vpp/plugins/ksr/model/ksrapi"
 
+	"k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -39,6 +41,7 @@ import (
 	"github.com/ligato/cn-infra/health/statuscheck"
 	"github.com/ligato/cn-infra/health/statuscheck/model/status"
 	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/cn-infra/servicelabel"
 	"github.com/ligato/cn-infra/utils/safeclose"
 )
 
@@ -60,6 +63,8 @@ type Plugin struct {
 	endpointsReflector *EndpointsReflector
 	nodeReflector      *NodeReflector
 
+	nodeDecommissioner *nodeDecommissioner
+
 	reflectorRegistry *ReflectorRegistry
 
 	StatusMonitor  statuscheck.StatusReader
@@ -184,8 +189,19 @@ func (plugin *Plugin) Init() error {
 		return err
 	}
 
+	plugin.nodeDecommissioner = &nodeDecommissioner{
+		Log:    plugin.Log.NewLogger("-decommissioner"),
+		Broker: broker,
+		NodeBroker: func(nodeName string) KeyProtoValBroker {
+			return plugin.Publish.Deps.KvPlugin.NewBroker(servicelabel.GetDifferentAgentPrefix(nodeName))
+		},
+		NodeExists: plugin.nodeExists,
+		Delay:      NodeDecommissionDelay,
+	}
+
 	plugin.nodeReflector = &NodeReflector{
-		Reflector: plugin.newReflector("-node", nodeObjType, broker),
+		Reflector:      plugin.newReflector("-node", nodeObjType, broker),
+		decommissioner: plugin.nodeDecommissioner,
 	}
 	// plugin.nodeReflector.Log.SetLevel(logging.DebugLevel)
 	err = plugin.nodeReflector.Init(plugin.stopCh, &plugin.wg)
@@ -218,12 +234,20 @@ func (plugin *Plugin) AfterInit() error {
 
 	go plugin.monitorEtcdStatus(plugin.stopCh)
 
+	// decommission the nodes removed while KSR was not running
+	go func() {
+		if err := plugin.nodeDecommissioner.resync(); err != nil {
+			plugin.Log.WithField("rwErr", err).Error("Failed to resync node decommissioning")
+		}
+	}()
+
 	return nil
 }
 
 // Close stops all reflectors.
 func (plugin *Plugin) Close() error {
 	close(plugin.stopCh)
+	plugin.nodeDecommissioner.close()
 	safeclose.CloseAll(plugin.nsReflector, plugin.podReflector, plugin.policyReflector,
 		plugin.serviceReflector, plugin.endpointsReflector)
 	plugin.wg.Wait()
@@ -250,6 +274,18 @@ func (plugin *Plugin) monitorEtcdStatus(closeCh chan struct{}) {
 	}
 }
 
+// nodeExists checks in the K8s API if the node with the given name exists.
+func (plugin *Plugin) nodeExists(nodeName string) (bool, error) {
+	_, err := plugin.k8sClientset.CoreV1().Nodes().Get(nodeName, metaV1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // newReflector returns a new instance of KSR Reflector
 func (plugin *Plugin) newReflector(logName string, objType string, broker KeyProtoValBroker) Reflector {
 	return Reflector{
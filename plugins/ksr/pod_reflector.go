@@ -16,6 +16,7 @@ package ksr
 
 import (
 	"reflect"
+	"sort"
 	"sync"
 
 	coreV1 "k8s.io/api/core/v1"
@@ -26,6 +27,10 @@ import (
 	"github.com/contiv/vpp/plugins/ksr/model/pod"
 )
 
+// lastAppliedConfigAnnotation is set by kubectl apply to the whole applied
+// object, which is of no interest to the consumers of the reflected data.
+const lastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
 // PodReflector subscribes to K8s cluster to watch for changes in the
 // configuration of k8s pods. Protobuf-modelled changes are published
 // into the selected key-value store.
@@ -133,6 +138,19 @@ func (pr *PodReflector) podToProto(k8sPod *coreV1.Pod) *pod.Pod {
 	for _, container := range k8sPod.Spec.Containers {
 		podProto.Container = append(podProto.Container, pr.containerToProto(&container))
 	}
+	// annotations are sorted by key so that the same set of annotations
+	// always translates into the same protobuf value
+	annotations := k8sPod.GetAnnotations()
+	keys := make([]string, 0, len(annotations))
+	for key := range annotations {
+		if key != lastAppliedConfigAnnotation {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		podProto.Annotation = append(podProto.Annotation, &pod.Pod_Annotation{Key: key, Value: annotations[key]})
+	}
 
 	return podProto
 }
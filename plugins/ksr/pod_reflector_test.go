@@ -82,6 +82,11 @@ func TestPodReflector(t *testing.T) {
 				CreationTimestamp: metav1.Date(2017, 12, 28, 19, 58, 37, 0,
 					time.FixedZone("PST", -800)),
 				Labels: map[string]string{"ksrRun": "my-nginx"},
+				Annotations: map[string]string{
+					"kubernetes.io/ingress-bandwidth":                  "10M",
+					"kubernetes.io/egress-bandwidth":                   "1M",
+					"kubectl.kubernetes.io/last-applied-configuration": "{}",
+				},
 			},
 			Spec: coreV1.PodSpec{
 				Containers: []coreV1.Container{
@@ -364,6 +369,12 @@ func testAddDeletePod(t *testing.T) {
 	gomega.Expect(protoPod.Container[0].Port[0].HostIpAddress).
 		To(gomega.Equal(k8sPod.Spec.Containers[0].Ports[0].HostIP))
 
+	gomega.Expect(protoPod.Annotation).To(gomega.HaveLen(2))
+	gomega.Expect(protoPod.Annotation[0].Key).To(gomega.Equal("kubernetes.io/egress-bandwidth"))
+	gomega.Expect(protoPod.Annotation[0].Value).To(gomega.Equal("1M"))
+	gomega.Expect(protoPod.Annotation[1].Key).To(gomega.Equal("kubernetes.io/ingress-bandwidth"))
+	gomega.Expect(protoPod.Annotation[1].Value).To(gomega.Equal("10M"))
+
 	// Take a snapshot of counters
 	dels := podTestVars.podReflector.GetStats().Deletes
 	argErrs = podTestVars.podReflector.GetStats().ArgErrors
@@ -417,4 +428,16 @@ func testUpdatePod(t *testing.T) {
 	gomega.Expect(found).To(gomega.BeTrue())
 	gomega.Ω(err).Should(gomega.BeNil())
 	gomega.Expect(protoPodNew.HostIpAddress).To(gomega.Equal(k8sPodNew.Status.HostIP))
+
+	// Test update of annotations
+	k8sPodOld = k8sPodNew.DeepCopy()
+	k8sPodNew.Annotations["kubernetes.io/egress-bandwidth"] = "2M"
+	podTestVars.k8sListWatch.Update(k8sPodOld, k8sPodNew)
+	gomega.Expect(upds + 2).To(gomega.Equal(podTestVars.podReflector.GetStats().Updates))
+
+	protoPodNew = &pod.Pod{}
+	found, _, err = podTestVars.mockKvBroker.GetValue(key, protoPodNew)
+	gomega.Expect(found).To(gomega.BeTrue())
+	gomega.Ω(err).Should(gomega.BeNil())
+	gomega.Expect(protoPodNew.Annotation[0].Value).To(gomega.Equal("2M"))
 }
@@ -32,6 +32,17 @@ import (
 	"github.com/contiv/vpp/plugins/ksr/model/policy"
 )
 
+const (
+	// protocolSCTP is the k8s name of the SCTP protocol (not defined
+	// by the k8s API release the reflector is built with).
+	protocolSCTP coreV1.Protocol = "SCTP"
+
+	// protocolICMP is the name of the ICMP protocol, used by policies
+	// to allow ICMP messages. For ICMP, the numerical port selects
+	// the ICMP type.
+	protocolICMP coreV1.Protocol = "ICMP"
+)
+
 // PolicyReflector subscribes to K8s cluster to watch for changes
 // in the configuration of k8s network policies.
 // Protobuf-modelled changes are published into the selected key-value store.
@@ -270,7 +281,23 @@ func (pr *PolicyReflector) portsToProto(ports []coreV1Beta1.NetworkPolicyPort) (
 				portProto.Protocol = policy.Policy_Port_TCP
 			case coreV1.ProtocolUDP:
 				portProto.Protocol = policy.Policy_Port_UDP
+			case protocolSCTP:
+				portProto.Protocol = policy.Policy_Port_SCTP
+			case protocolICMP:
+				portProto.Protocol = policy.Policy_Port_ICMP
+			}
+		}
+		// ICMP type
+		if portProto.Protocol == policy.Policy_Port_ICMP {
+			if port.Port != nil {
+				if port.Port.Type != intstr.Int {
+					pr.Log.WithField("port", port.Port.StrVal).Warn("Skipping ICMP port with non-numerical ICMP type")
+					continue
+				}
+				portProto.Icmp = &policy.Policy_Port_ICMPMessages{Type: uint32(port.Port.IntVal), AnyCode: true}
 			}
+			portsProto = append(portsProto, portProto)
+			continue
 		}
 		// Port number/name
 		if port.Port != nil {
@@ -284,6 +311,8 @@ func (pr *PolicyReflector) portsToProto(ports []coreV1Beta1.NetworkPolicyPort) (
 				portProto.Port.Name = port.Port.StrVal
 			}
 		}
+		// Port range: NetworkPolicyPort of the k8s API release the reflector
+		// is built with does not define endPort yet, EndPort is therefore left unset.
 		// append port
 		portsProto = append(portsProto, portProto)
 	}
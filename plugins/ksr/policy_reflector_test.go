@@ -332,6 +332,8 @@ func TestPolicyReflector(t *testing.T) {
 	policyTestVars.mockKvBroker.ClearDs()
 	t.Run("updatePolicy", testUpdatePolicy)
 
+	t.Run("portProtocols", testPortProtocols)
+
 	// The following tests check the KSR resync feature under various failure
 	// scenarios. These tests exercise mostly the core KSR Reflector code. We
 	// only perform them on policies, as the core KSR reflector code is common
@@ -356,6 +358,51 @@ func TestPolicyReflector(t *testing.T) {
 
 }
 
+func testPortProtocols(t *testing.T) {
+	var (
+		pprotUDP  coreV1.Protocol = "UDP"
+		pprotSCTP coreV1.Protocol = "SCTP"
+		pprotICMP coreV1.Protocol = "ICMP"
+	)
+	k8sPorts := []coreV1Beta1.NetworkPolicyPort{
+		{
+			Protocol: &pprotUDP,
+			Port:     &intstr.IntOrString{Type: intstr.Int, IntVal: 53},
+		},
+		{
+			Protocol: &pprotSCTP,
+			Port:     &intstr.IntOrString{Type: intstr.Int, IntVal: 3868},
+		},
+		{
+			// echo request
+			Protocol: &pprotICMP,
+			Port:     &intstr.IntOrString{Type: intstr.Int, IntVal: 8},
+		},
+		{
+			// all ICMP messages
+			Protocol: &pprotICMP,
+		},
+		{
+			// invalid ICMP type
+			Protocol: &pprotICMP,
+			Port:     &intstr.IntOrString{Type: intstr.String, StrVal: "ping"},
+		},
+	}
+
+	protoPorts := policyTestVars.policyReflector.portsToProto(k8sPorts)
+	gomega.Expect(protoPorts).To(gomega.HaveLen(4))
+	checkRulePorts(protoPorts[:2], k8sPorts[:2])
+	gomega.Expect(protoPorts[1].Protocol).To(gomega.BeEquivalentTo(policy.Policy_Port_SCTP))
+
+	gomega.Expect(protoPorts[2].Protocol).To(gomega.BeEquivalentTo(policy.Policy_Port_ICMP))
+	gomega.Expect(protoPorts[2].Port).To(gomega.BeNil())
+	gomega.Expect(protoPorts[2].Icmp).To(gomega.Equal(&policy.Policy_Port_ICMPMessages{Type: 8, AnyCode: true}))
+
+	gomega.Expect(protoPorts[3].Protocol).To(gomega.BeEquivalentTo(policy.Policy_Port_ICMP))
+	gomega.Expect(protoPorts[3].Port).To(gomega.BeNil())
+	gomega.Expect(protoPorts[3].Icmp).To(gomega.BeNil())
+}
+
 func testAddDeletePolicy(t *testing.T) {
 	// Test the policy add operation
 	for _, k8sPolicy := range policyTestVars.policyTestData {
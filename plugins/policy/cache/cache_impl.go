@@ -31,6 +31,10 @@ type PolicyCache struct {
 	configuredPods       *podidx.ConfigIndex
 	configuredNamespaces *namespaceidx.ConfigIndex
 	watchers             []PolicyCacheWatcher
+
+	// podIPv6s maps the pods to their IPv6 addresses reflected by the Contiv
+	// agents, which are merged into the pod data reflected by KSR.
+	podIPv6s map[string]string
 }
 
 // Deps lists dependencies of PolicyCache.
@@ -44,6 +48,7 @@ func (pc *PolicyCache) Init() error {
 	pc.configuredPolicies = policyidx.NewConfigIndex(pc.Log, pc.PluginName, "policies")
 	pc.configuredPods = podidx.NewConfigIndex(pc.Log, pc.PluginName, "pods")
 	pc.configuredNamespaces = namespaceidx.NewConfigIndex(pc.Log, pc.PluginName, "namespaces")
+	pc.podIPv6s = make(map[string]string)
 
 	pc.watchers = []PolicyCacheWatcher{}
 	return nil
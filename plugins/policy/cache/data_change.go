@@ -17,6 +17,7 @@
 package cache
 
 import (
+	"github.com/golang/protobuf/proto"
 	"github.com/ligato/cn-infra/datasync"
 
 	namespacemodel "github.com/contiv/vpp/plugins/ksr/model/namespace"
@@ -92,6 +93,11 @@ func (pc *PolicyCache) changePropagateEvent(dataChngEv datasync.ChangeEvent) err
 		if diff, err = dataChngEv.GetPrevValue(&prevValue); err != nil {
 			return err
 		}
+		value.Ipv6Address = pc.podIPv6s[podID.String()]
+		if diff {
+			_, prevData := pc.configuredPods.LookupPod(podID.String())
+			prevValue.Ipv6Address = prevData.GetIpv6Address()
+		}
 
 		if datasync.Delete == dataChngEv.GetChangeType() {
 			pc.configuredPods.UnregisterPod(podID.String())
@@ -124,6 +130,39 @@ func (pc *PolicyCache) changePropagateEvent(dataChngEv datasync.ChangeEvent) err
 		return nil
 	}
 
+	// Propagate pod IPv6 address CHANGE event
+	podName, podNs, err = podmodel.ParsePodIPv6FromKey(key)
+	if err == nil {
+		var value podmodel.Pod
+		podID := podmodel.ID{Name: podName, Namespace: podNs}
+
+		if datasync.Delete == dataChngEv.GetChangeType() {
+			delete(pc.podIPv6s, podID.String())
+		} else {
+			if err = dataChngEv.GetValue(&value); err != nil {
+				return err
+			}
+			pc.podIPv6s[podID.String()] = value.Ipv6Address
+		}
+
+		// Update the address in the pod data.
+		found, prevPod := pc.configuredPods.LookupPod(podID.String())
+		if !found || prevPod.Ipv6Address == pc.podIPv6s[podID.String()] {
+			return nil
+		}
+		pod := proto.Clone(prevPod).(*podmodel.Pod)
+		pod.Ipv6Address = pc.podIPv6s[podID.String()]
+		pc.configuredPods.UnregisterPod(podID.String())
+		pc.configuredPods.RegisterPod(podID.String(), pod)
+
+		for _, watcher := range pc.watchers {
+			if err := watcher.UpdatePod(podID, prevPod, pod); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	// Propagate Namespace CHANGE event
 	_, err = namespacemodel.ParseNamespaceFromKey(key)
 	if err == nil {
@@ -49,6 +49,7 @@ func (pc *PolicyCache) resyncParseEvent(resyncEv datasync.ResyncEvent) *DataResy
 	var numPod int
 
 	event := NewDataResyncEvent()
+	pc.podIPv6s = make(map[string]string)
 
 	for key, resyncData := range resyncEv.GetValues() {
 		pc.Log.Debug("Received RESYNC key ", key)
@@ -89,6 +90,18 @@ func (pc *PolicyCache) resyncParseEvent(resyncEv datasync.ResyncEvent) *DataResy
 				continue
 			}
 
+			// Parse pod IPv6 address RESYNC event
+			podName, podNs, err := podmodel.ParsePodIPv6FromKey(key)
+			if err == nil {
+				value := &podmodel.Pod{}
+				err := evData.GetValue(value)
+				if err == nil {
+					podID := podmodel.ID{Name: podName, Namespace: podNs}
+					pc.podIPv6s[podID.String()] = value.Ipv6Address
+				}
+				continue
+			}
+
 			// Parse namespace RESYNC event
 			_, err = namespacemodel.ParseNamespaceFromKey(key)
 			if err == nil {
@@ -105,6 +118,11 @@ func (pc *PolicyCache) resyncParseEvent(resyncEv datasync.ResyncEvent) *DataResy
 		}
 	}
 
+	// Merge the IPv6 addresses into the pod data.
+	for _, pod := range event.Pods {
+		pod.Ipv6Address = pc.podIPv6s[podmodel.GetID(pod).String()]
+	}
+
 	pc.Log.WithFields(logging.Fields{
 		"num-policies": numPolicy,
 		"num-pods":     numPod,
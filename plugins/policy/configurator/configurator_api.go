@@ -51,6 +51,12 @@ type PolicyConfiguratorAPI interface {
 	// replace the existing one, otherwise pods not mentioned in the transaction
 	// are left unchanged.
 	NewTxn(resync bool) Txn
+
+	// EvaluateTraffic evaluates the configured policies against the given
+	// connection (what-if analysis). Only pods deployed on this node are
+	// taken into account - the policies of other pods are enforced
+	// by their own nodes.
+	EvaluateTraffic(conn Connection) *TrafficEvaluation
 }
 
 // Txn defines the API of PolicyConfigurator transaction.
@@ -213,7 +219,7 @@ func (mt MatchType) String() string {
 	return "INVALID"
 }
 
-// ProtocolType is either TCP or UDP.
+// ProtocolType is one of TCP, UDP, ICMP, SCTP.
 type ProtocolType int
 
 const (
@@ -222,6 +228,12 @@ const (
 
 	// UDP protocol.
 	UDP
+
+	// ICMP protocol.
+	ICMP
+
+	// SCTP protocol.
+	SCTP
 )
 
 // String converts ProtocolType into a human-readable string.
@@ -231,26 +243,36 @@ func (pt ProtocolType) String() string {
 		return "TCP"
 	case UDP:
 		return "UDP"
+	case ICMP:
+		return "ICMP"
+	case SCTP:
+		return "SCTP"
 	}
 	return "INVALID"
 }
 
-// Port represent a TCP or UDP port.
+// Port represent a TCP, UDP or SCTP port, or a set of ICMP messages.
 // Number=0 represents all ports for a given protocol.
+// ICMP=nil represents all ICMP messages.
 type Port struct {
-	Protocol ProtocolType
-	Number   uint16
+	Protocol  ProtocolType
+	Number    uint16              // not used with ICMP
+	EndNumber uint16              // > Number = port range <Number, EndNumber>, not used with ICMP
+	ICMP      *renderer.ICMPMatch // used only with ICMP
 }
 
 // String return a human-readable string representation of the Port.
 func (port Port) String() string {
-	protocol := "TCP"
-	if port.Protocol == UDP {
-		protocol = "UDP"
+	protocol := port.Protocol.String()
+	if port.Protocol == ICMP {
+		return protocol + ":" + port.ICMP.String()
 	}
 	if port.Number == 0 {
 		return protocol + ":ANY"
 	}
+	if port.EndNumber > port.Number {
+		return protocol + ":" + strconv.Itoa(int(port.Number)) + "-" + strconv.Itoa(int(port.EndNumber))
+	}
 	return protocol + ":" + strconv.Itoa(int(port.Number))
 }
 
@@ -273,3 +295,87 @@ func (ipb IPBlock) String() string {
 		ipb.Network, excepts)
 
 }
+
+// Connection represents traffic to evaluate against the configured policies.
+type Connection struct {
+	SrcIP    net.IP
+	DestIP   net.IP
+	Protocol renderer.ProtocolType
+	SrcPort  uint16 // ICMP type with ICMP
+	DestPort uint16 // ICMP code with ICMP
+}
+
+// String return a human-readable string representation of the Connection.
+func (conn Connection) String() string {
+	return fmt.Sprintf("%s %s:%d -> %s:%d",
+		conn.Protocol, conn.SrcIP, conn.SrcPort, conn.DestIP, conn.DestPort)
+}
+
+// TrafficVerdict is one of ALLOWED, DENIED.
+type TrafficVerdict int
+
+const (
+	// TrafficAllowed is returned when the traffic is allowed by the policies.
+	TrafficAllowed TrafficVerdict = iota
+
+	// TrafficDenied is returned when the traffic is blocked by the policies.
+	TrafficDenied
+)
+
+// String converts TrafficVerdict into a human-readable string.
+func (tv TrafficVerdict) String() string {
+	switch tv {
+	case TrafficAllowed:
+		return "ALLOWED"
+	case TrafficDenied:
+		return "DENIED"
+	}
+	return "INVALID"
+}
+
+// TrafficEvaluation is the outcome of the evaluation of policies against
+// a connection.
+type TrafficEvaluation struct {
+	// Verdict is TrafficDenied if the connection is blocked by the policies
+	// of either side.
+	Verdict TrafficVerdict
+
+	// Evaluations of the policies of the source pod (egress) and the destination
+	// pod (ingress), each present only if the pod is deployed on this node.
+	Pods []*PodTrafficEvaluation
+
+	// Rendered is the evaluation of the rules actually rendered into the data
+	// plane, nil if no registered renderer supports the evaluation.
+	// If present, the Verdict is given by the rendered rules.
+	Rendered *RenderedTrafficEvaluation
+}
+
+// RenderedTrafficEvaluation is the outcome of the evaluation of the rendered
+// rules against a connection.
+type RenderedTrafficEvaluation struct {
+	Verdict TrafficVerdict
+
+	// Rule is the rendered rule matching the connection, nil if no rendered rule
+	// applies to the connection or if the connection is denied by not matching
+	// any of the rendered rules.
+	Rule *renderer.ContivRule
+}
+
+// PodTrafficEvaluation is the outcome of the evaluation of the policies
+// of one pod against a connection.
+type PodTrafficEvaluation struct {
+	Pod podmodel.ID
+
+	// Direction is from the Pod point of view!
+	Direction MatchType
+
+	Verdict TrafficVerdict
+
+	// Rule is the first rule matching the connection, nil if the pod is not
+	// isolated in the given direction.
+	Rule *renderer.ContivRule
+
+	// Policies responsible for the verdict - policies allowing the connection
+	// or all policies isolating the pod if the connection is denied.
+	Policies []policymodel.ID
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/ligato/cn-infra/logging"
 
 	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
+	policymodel "github.com/contiv/vpp/plugins/ksr/model/policy"
 	"github.com/contiv/vpp/plugins/policy/cache"
 	"github.com/contiv/vpp/plugins/policy/renderer"
 	"github.com/contiv/vpp/plugins/policy/utils"
@@ -42,6 +43,7 @@ type PolicyConfigurator struct {
 	renderers         []renderer.PolicyRendererAPI
 	parallelRendering bool
 	podIPAddresses    PodIPAddresses
+	podConfigs        PodPolicyConfigs
 }
 
 // Deps lists dependencies of PolicyConfigurator.
@@ -57,6 +59,7 @@ type PolicyConfiguratorTxn struct {
 	resync         bool
 	config         map[podmodel.ID]ContivPolicies // config to render
 	podIPAddresses PodIPAddresses
+	podConfigs     PodPolicyConfigs
 }
 
 // ContivPolicies is a list of policies that can be ordered by policy ID.
@@ -77,11 +80,24 @@ type ContivRules []*renderer.ContivRule
 // PodIPAddresses is a map used to remember IP address for each configured pod.
 type PodIPAddresses map[podmodel.ID]*net.IPNet
 
+// PodPolicyConfig stores the policies configured for a pod and the rules
+// generated for them.
+type PodPolicyConfig struct {
+	policies ContivPolicies // ordered
+	ingress  ContivRules
+	egress   ContivRules
+}
+
+// PodPolicyConfigs is a map used to remember configuration of each configured pod.
+// It is used to evaluate the policies against a given traffic.
+type PodPolicyConfigs map[podmodel.ID]*PodPolicyConfig
+
 // Init initializes policy configurator.
 func (pc *PolicyConfigurator) Init(parallelRendering bool) error {
 	pc.renderers = []renderer.PolicyRendererAPI{}
 	pc.parallelRendering = parallelRendering
 	pc.podIPAddresses = make(PodIPAddresses)
+	pc.podConfigs = make(PodPolicyConfigs)
 	return nil
 }
 
@@ -99,6 +115,45 @@ func (pc *PolicyConfigurator) Close() error {
 	return nil
 }
 
+// EvaluateTraffic evaluates the configured policies against the given
+// connection (what-if analysis). Only pods deployed on this node are taken
+// into account.
+// The method must not be called concurrently with Commit of a transaction.
+func (pc *PolicyConfigurator) EvaluateTraffic(conn Connection) *TrafficEvaluation {
+	evaluation := &TrafficEvaluation{Verdict: TrafficAllowed}
+	if srcPod, found := pc.lookupPodByIP(conn.SrcIP); found {
+		evaluation.Pods = append(evaluation.Pods, pc.evaluatePodTraffic(srcPod, MatchEgress, conn))
+	}
+	if destPod, found := pc.lookupPodByIP(conn.DestIP); found {
+		evaluation.Pods = append(evaluation.Pods, pc.evaluatePodTraffic(destPod, MatchIngress, conn))
+	}
+	for _, podEvaluation := range evaluation.Pods {
+		if podEvaluation.Verdict == TrafficDenied {
+			evaluation.Verdict = TrafficDenied
+		}
+	}
+
+	// The verdict of the data plane is given by the rules actually rendered.
+	for _, policyRenderer := range pc.renderers {
+		evaluator, canEvaluate := policyRenderer.(renderer.TrafficEvaluator)
+		if !canEvaluate {
+			continue
+		}
+		rule, applied := evaluator.EvaluateTraffic(conn.SrcIP, conn.DestIP, conn.Protocol, conn.SrcPort, conn.DestPort)
+		rendered := &RenderedTrafficEvaluation{Verdict: TrafficAllowed, Rule: rule}
+		if applied && (rule == nil || rule.Action == renderer.ActionDeny) {
+			rendered.Verdict = TrafficDenied
+		}
+		if evaluation.Rendered == nil || rendered.Verdict == TrafficDenied {
+			evaluation.Rendered = rendered
+		}
+	}
+	if evaluation.Rendered != nil {
+		evaluation.Verdict = evaluation.Rendered.Verdict
+	}
+	return evaluation
+}
+
 // NewTxn starts a new transaction. The re-configuration executes only after
 // Commit() is called. If <resync> is enabled, the supplied configuration will
 // completely replace the existing one, otherwise pods not mentioned in the
@@ -110,6 +165,7 @@ func (pc *PolicyConfigurator) NewTxn(resync bool) Txn {
 		resync:         resync,
 		config:         make(map[podmodel.ID]ContivPolicies),
 		podIPAddresses: pc.podIPAddresses.Copy(),
+		podConfigs:     pc.podConfigs.Copy(),
 	}
 	return txn
 }
@@ -149,6 +205,7 @@ func (pct *PolicyConfiguratorTxn) Commit() error {
 				pct.Log.WithField("pod", pod).Debug("Removing policies from the pod.")
 				delPodConfig = true
 				delete(pct.podIPAddresses, pod)
+				delete(pct.podConfigs, pod)
 			} else {
 				/* already un-configured */
 				continue
@@ -192,6 +249,7 @@ func (pct *PolicyConfiguratorTxn) Commit() error {
 						egress:   egress,
 					})
 			}
+			pct.podConfigs[pod] = &PodPolicyConfig{policies: policies, ingress: ingress, egress: egress}
 		}
 
 		// Start transaction on every renderer if they are not running already.
@@ -234,6 +292,7 @@ func (pct *PolicyConfiguratorTxn) Commit() error {
 
 	// Save changes to the configurator.
 	pct.configurator.podIPAddresses = pct.podIPAddresses.Copy()
+	pct.configurator.podConfigs = pct.podConfigs.Copy()
 
 	return wasError
 }
@@ -249,6 +308,7 @@ func (pct *PolicyConfiguratorTxn) generateRules(direction MatchType, policies Co
 	rules := ContivRules{}
 	hasPolicy := false
 	allAllowed := false
+	isolatedBy := []policymodel.ID{} /* policies applied in this direction */
 
 	for _, policy := range policies {
 		if (policy.Type == PolicyIngress && direction == MatchEgress) ||
@@ -257,6 +317,8 @@ func (pct *PolicyConfiguratorTxn) generateRules(direction MatchType, policies Co
 			continue
 		}
 		hasPolicy = true
+		isolatedBy = append(isolatedBy, policy.ID)
+		origin := []policymodel.ID{policy.ID}
 
 		for _, match := range policy.Matches {
 			if match.Type != direction {
@@ -283,6 +345,17 @@ func (pct *PolicyConfiguratorTxn) generateRules(direction MatchType, policies Co
 					continue
 				}
 				peers = append(peers, PeerPod{ID: peer, IPNet: peerIPNet})
+				if peerData.Ipv6Address != "" {
+					// Dual-stack peer - allow also its IPv6 traffic.
+					peerIPv6Net := utils.GetOneHostSubnet(peerData.Ipv6Address)
+					if peerIPv6Net == nil {
+						pct.Log.WithFields(logging.Fields{
+							"peer": peer,
+							"ip":   peerData.Ipv6Address}).Warn("Peer pod has invalid IPv6 address assigned")
+						continue
+					}
+					peers = append(peers, PeerPod{ID: peer, IPNet: peerIPv6Net})
+				}
 			}
 
 			// Collect all subnets from IPBlocks.
@@ -304,150 +377,54 @@ func (pct *PolicyConfiguratorTxn) generateRules(direction MatchType, policies Co
 			if match.Pods == nil && match.IPBlocks == nil {
 				if len(match.Ports) == 0 {
 					// = match anything on L3 & L4
-					ruleTCPAny := &renderer.ContivRule{
-						Action:      renderer.ActionPermit,
-						SrcNetwork:  &net.IPNet{},
-						DestNetwork: &net.IPNet{},
-						Protocol:    renderer.TCP,
-						SrcPort:     0,
-						DestPort:    0,
-					}
-					ruleUDPAny := &renderer.ContivRule{
-						Action:      renderer.ActionPermit,
-						SrcNetwork:  &net.IPNet{},
-						DestNetwork: &net.IPNet{},
-						Protocol:    renderer.UDP,
-						SrcPort:     0,
-						DestPort:    0,
-					}
-					rules = pct.appendRules(rules, ruleTCPAny, ruleUDPAny)
+					rules = pct.appendRules(rules, origin, anyProtocolRules(renderer.ActionPermit, &net.IPNet{}, &net.IPNet{})...)
 					allAllowed = true
 				} else {
 					// = match by L4
 					for _, port := range match.Ports {
-						rule := &renderer.ContivRule{
-							Action:      renderer.ActionPermit,
-							SrcNetwork:  &net.IPNet{},
-							DestNetwork: &net.IPNet{},
-							SrcPort:     0,
-							DestPort:    port.Number,
-						}
-						if port.Protocol == TCP {
-							rule.Protocol = renderer.TCP
-						} else {
-							rule.Protocol = renderer.UDP
-						}
-						rules = pct.appendRules(rules, rule)
+						rules = pct.appendRules(rules, origin, portRule(port, &net.IPNet{}, &net.IPNet{}))
 					}
 				}
 			}
 
 			// Combine pod peers with ports.
 			for _, peer := range peers {
+				srcNetwork, destNetwork := &net.IPNet{}, &net.IPNet{}
+				if direction == MatchIngress {
+					srcNetwork = peer.IPNet
+				} else {
+					destNetwork = peer.IPNet
+				}
 				if len(match.Ports) == 0 {
 					// Match all ports.
 					// = match by L3
-					ruleTCPAny := &renderer.ContivRule{
-						Action:      renderer.ActionPermit,
-						Protocol:    renderer.TCP,
-						SrcNetwork:  &net.IPNet{},
-						DestNetwork: &net.IPNet{},
-						SrcPort:     0,
-						DestPort:    0,
-					}
-					ruleUDPAny := &renderer.ContivRule{
-						Action:      renderer.ActionPermit,
-						Protocol:    renderer.UDP,
-						SrcNetwork:  &net.IPNet{},
-						DestNetwork: &net.IPNet{},
-						SrcPort:     0,
-						DestPort:    0,
-					}
-					if direction == MatchIngress {
-						ruleTCPAny.SrcNetwork = peer.IPNet
-						ruleUDPAny.SrcNetwork = peer.IPNet
-					} else {
-						ruleTCPAny.DestNetwork = peer.IPNet
-						ruleUDPAny.DestNetwork = peer.IPNet
-					}
-					rules = pct.appendRules(rules, ruleTCPAny, ruleUDPAny)
+					rules = pct.appendRules(rules, origin, anyProtocolRules(renderer.ActionPermit, srcNetwork, destNetwork)...)
 				} else {
 					// Combine each port with the peer.
 					// = match by L3 & L4
 					for _, port := range match.Ports {
-						rule := &renderer.ContivRule{
-							Action:      renderer.ActionPermit,
-							SrcNetwork:  &net.IPNet{},
-							DestNetwork: &net.IPNet{},
-							SrcPort:     0,
-							DestPort:    port.Number,
-						}
-						if direction == MatchIngress {
-							rule.SrcNetwork = peer.IPNet
-						} else {
-							rule.DestNetwork = peer.IPNet
-						}
-						if port.Protocol == TCP {
-							rule.Protocol = renderer.TCP
-						} else {
-							rule.Protocol = renderer.UDP
-						}
-						rules = pct.appendRules(rules, rule)
+						rules = pct.appendRules(rules, origin, portRule(port, srcNetwork, destNetwork))
 					}
 				}
 			}
 
 			// Combine IPBlocks with ports.
 			for _, subnet := range allSubnets {
+				srcNetwork, destNetwork := &net.IPNet{}, &net.IPNet{}
+				if direction == MatchIngress {
+					srcNetwork = subnet
+				} else {
+					destNetwork = subnet
+				}
 				if len(match.Ports) == 0 {
 					// Handle IPBlock with no ports.
 					// = match by L3
-					ruleTCPAny := &renderer.ContivRule{
-						Action:      renderer.ActionPermit,
-						Protocol:    renderer.TCP,
-						SrcNetwork:  &net.IPNet{},
-						DestNetwork: &net.IPNet{},
-						SrcPort:     0,
-						DestPort:    0,
-					}
-					ruleUDPAny := &renderer.ContivRule{
-						Action:      renderer.ActionPermit,
-						Protocol:    renderer.UDP,
-						SrcNetwork:  &net.IPNet{},
-						DestNetwork: &net.IPNet{},
-						SrcPort:     0,
-						DestPort:    0,
-					}
-					if direction == MatchIngress {
-						ruleTCPAny.SrcNetwork = subnet
-						ruleUDPAny.SrcNetwork = subnet
-					} else {
-						ruleTCPAny.DestNetwork = subnet
-						ruleUDPAny.DestNetwork = subnet
-					}
-					rules = pct.appendRules(rules, ruleTCPAny, ruleUDPAny)
+					rules = pct.appendRules(rules, origin, anyProtocolRules(renderer.ActionPermit, srcNetwork, destNetwork)...)
 				} else {
 					// Combine each port with the block.
 					// = match by L3 & L4
 					for _, port := range match.Ports {
-						rule := &renderer.ContivRule{
-							Action:      renderer.ActionPermit,
-							SrcNetwork:  &net.IPNet{},
-							DestNetwork: &net.IPNet{},
-							SrcPort:     0,
-							DestPort:    port.Number,
-						}
-						if direction == MatchIngress {
-							rule.SrcNetwork = subnet
-						} else {
-							rule.DestNetwork = subnet
-						}
-						if port.Protocol == TCP {
-							rule.Protocol = renderer.TCP
-						} else {
-							rule.Protocol = renderer.UDP
-						}
-						rules = pct.appendRules(rules, rule)
+						rules = pct.appendRules(rules, origin, portRule(port, srcNetwork, destNetwork))
 					}
 				}
 			}
@@ -456,43 +433,146 @@ func (pct *PolicyConfiguratorTxn) generateRules(direction MatchType, policies Co
 
 	if hasPolicy && !allAllowed {
 		// Deny the rest.
-		ruleTCPNone := &renderer.ContivRule{
-			Action:      renderer.ActionDeny,
-			SrcNetwork:  &net.IPNet{},
-			DestNetwork: &net.IPNet{},
-			Protocol:    renderer.TCP,
-			SrcPort:     0,
-			DestPort:    0,
+		rules = pct.appendRules(rules, isolatedBy, anyProtocolRules(renderer.ActionDeny, &net.IPNet{}, &net.IPNet{})...)
+	}
+
+	return rules
+}
+
+// lookupPodByIP returns ID of the configured pod with the given IP address.
+func (pc *PolicyConfigurator) lookupPodByIP(ip net.IP) (pod podmodel.ID, found bool) {
+	for pod, podIPNet := range pc.podIPAddresses {
+		if podIPNet.IP.Equal(ip) {
+			return pod, true
 		}
-		ruleUDPNone := &renderer.ContivRule{
-			Action:      renderer.ActionDeny,
-			SrcNetwork:  &net.IPNet{},
-			DestNetwork: &net.IPNet{},
-			Protocol:    renderer.UDP,
-			SrcPort:     0,
-			DestPort:    0,
+	}
+	return pod, false
+}
+
+// evaluatePodTraffic evaluates the policies of the given pod against
+// the connection in the given direction (from the pod point of view).
+func (pc *PolicyConfigurator) evaluatePodTraffic(pod podmodel.ID, direction MatchType, conn Connection) *PodTrafficEvaluation {
+	evaluation := &PodTrafficEvaluation{
+		Pod:       pod,
+		Direction: direction,
+		Verdict:   TrafficAllowed,
+	}
+	podConfig, hasConfig := pc.podConfigs[pod]
+	if !hasConfig {
+		return evaluation
+	}
+
+	// Direction in policies is from the pod point of view, whereas rules
+	// are evaluated from the vswitch perspective.
+	rules := podConfig.egress
+	if direction == MatchEgress {
+		rules = podConfig.ingress
+	}
+	evaluation.Rule = matchingRule(rules, conn)
+	if evaluation.Rule == nil {
+		// Pod is not isolated in this direction.
+		return evaluation
+	}
+	if evaluation.Rule.Action == renderer.ActionDeny {
+		evaluation.Verdict = TrafficDenied
+	}
+
+	// Find policies responsible for the verdict.
+	txn := &PolicyConfiguratorTxn{Log: pc.Log, configurator: pc}
+	for _, policy := range podConfig.policies {
+		if (policy.Type == PolicyIngress && direction == MatchEgress) ||
+			(policy.Type == PolicyEgress && direction == MatchIngress) {
+			// Policy does not apply to this direction.
+			continue
 		}
-		rules = pct.appendRules(rules, ruleTCPNone, ruleUDPNone)
+		if evaluation.Verdict == TrafficAllowed {
+			// Check if the policy alone allows the connection.
+			rule := matchingRule(txn.generateRules(direction, ContivPolicies{policy}), conn)
+			if rule == nil || rule.Action != renderer.ActionPermit {
+				continue
+			}
+		}
+		evaluation.Policies = append(evaluation.Policies, policy.ID)
 	}
+	return evaluation
+}
 
+// matchingRule returns the first rule from the list matching the connection.
+func matchingRule(rules ContivRules, conn Connection) *renderer.ContivRule {
+	for _, rule := range rules {
+		if rule.Matches(conn.SrcIP, conn.DestIP, conn.Protocol, conn.SrcPort, conn.DestPort) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// anyProtocolRules returns one rule for every supported protocol, each matching
+// all ports (or ICMP messages) of the protocol between the given networks.
+func anyProtocolRules(action renderer.ActionType, srcNetwork, destNetwork *net.IPNet) (rules []*renderer.ContivRule) {
+	for _, protocol := range renderer.Protocols {
+		rules = append(rules, &renderer.ContivRule{
+			Action:      action,
+			SrcNetwork:  srcNetwork,
+			DestNetwork: destNetwork,
+			Protocol:    protocol,
+			SrcPort:     0,
+			DestPort:    0,
+		})
+	}
 	return rules
 }
 
-// Append rule into the list if it is not there already.
-func (pct *PolicyConfiguratorTxn) appendRule(rules []*renderer.ContivRule, newRule *renderer.ContivRule) []*renderer.ContivRule {
+// portRule returns rule permitting traffic between the given networks
+// for the given port (or ICMP messages).
+func portRule(port Port, srcNetwork, destNetwork *net.IPNet) *renderer.ContivRule {
+	rule := &renderer.ContivRule{
+		Action:      renderer.ActionPermit,
+		SrcNetwork:  srcNetwork,
+		DestNetwork: destNetwork,
+		SrcPort:     0,
+	}
+	switch port.Protocol {
+	case TCP:
+		rule.Protocol = renderer.TCP
+	case UDP:
+		rule.Protocol = renderer.UDP
+	case SCTP:
+		rule.Protocol = renderer.SCTP
+	case ICMP:
+		rule.Protocol = renderer.ICMP
+		rule.ICMP = port.ICMP
+		return rule
+	}
+	rule.DestPort = port.Number
+	if port.EndNumber > port.Number {
+		rule.DestPortEnd = port.EndNumber
+	}
+	return rule
+}
+
+// Append rule originating from the given policies into the list if it is not
+// there already. For a duplicate rule only the list of origins is extended.
+func (pct *PolicyConfiguratorTxn) appendRule(rules []*renderer.ContivRule, origin []policymodel.ID, newRule *renderer.ContivRule) []*renderer.ContivRule {
 	for _, rule := range rules {
 		if rule.Compare(newRule) == 0 {
 			pct.Log.WithField("rule", newRule).Debug("Skipping duplicate rule")
+			for _, policy := range origin {
+				if !rule.HasPolicy(policy) {
+					rule.Policies = append(rule.Policies, policy)
+				}
+			}
 			return rules
 		}
 	}
+	newRule.Policies = append([]policymodel.ID{}, origin...)
 	return append(rules, newRule)
 }
 
 // Append rules into the list. Skip those which are already there.
-func (pct *PolicyConfiguratorTxn) appendRules(rules []*renderer.ContivRule, newRules ...*renderer.ContivRule) []*renderer.ContivRule {
+func (pct *PolicyConfiguratorTxn) appendRules(rules []*renderer.ContivRule, origin []policymodel.ID, newRules ...*renderer.ContivRule) []*renderer.ContivRule {
 	for _, newRule := range newRules {
-		rules = pct.appendRule(rules, newRule)
+		rules = pct.appendRule(rules, origin, newRule)
 	}
 	return rules
 }
@@ -559,6 +639,16 @@ func (pa PodIPAddresses) Copy() PodIPAddresses {
 	return paCopy
 }
 
+// Copy creates a shallow copy of PodPolicyConfigs (PodPolicyConfig is never
+// modified once created).
+func (pc PodPolicyConfigs) Copy() PodPolicyConfigs {
+	pcCopy := make(PodPolicyConfigs, len(pc))
+	for pod, config := range pc {
+		pcCopy[pod] = config
+	}
+	return pcCopy
+}
+
 // Function returns a list of subnets with all IPs included in net1 and not included in net2.
 func subtractSubnet(net1, net2 *net.IPNet) []*net.IPNet {
 	result := []*net.IPNet{}
@@ -251,6 +251,306 @@ func TestSinglePolicyWithIPBlockSinglePod(t *testing.T) {
 	gomega.Expect(action).To(gomega.BeEquivalentTo(DeniedTraffic))
 }
 
+func TestIPv6PolicyWithIPBlockSinglePod(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestIPv6PolicyWithIPBlockSinglePod")
+
+	// Prepare input data.
+	const (
+		namespace = "default"
+		pod1Name  = "pod1"
+		pod2Name  = "pod2"
+		pod1IP    = "2001:db8:1::1"
+		pod2IP    = "2001:db8:2::1"
+	)
+	pod1 := podmodel.ID{Name: pod1Name, Namespace: namespace}
+	pod2 := podmodel.ID{Name: pod2Name, Namespace: namespace}
+
+	policy1 := &ContivPolicy{
+		ID:   policymodel.ID{Name: "policy1", Namespace: namespace},
+		Type: PolicyIngress,
+		Matches: []Match{
+			{
+				Type: MatchIngress,
+				IPBlocks: []IPBlock{
+					{
+						Network: parseIPNet("2001:db8:2::/48"),
+						Except: []net.IPNet{
+							parseIPNet("2001:db8:2:bad::/64"),
+						},
+					},
+					{
+						Network: parseIPNet("10.0.0.0/8"),
+					},
+				},
+				Ports: []Port{
+					{Protocol: TCP, Number: 80},
+				},
+			},
+		},
+	}
+	pod1Policies := []*ContivPolicy{policy1}
+
+	// Initialize mocks.
+	cache := NewMockPolicyCache()
+	cache.AddPodConfig(pod1, pod1IP)
+	cache.AddPodConfig(pod2, pod2IP)
+
+	renderer := NewMockRenderer("A", logger)
+
+	// Initialize configurator.
+	configurator := &PolicyConfigurator{
+		Deps: Deps{
+			Log:   logger,
+			Cache: cache,
+		},
+	}
+	configurator.Init(false)
+
+	// Register one renderer.
+	err := configurator.RegisterRenderer(renderer)
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Run single transaction.
+	txn := configurator.NewTxn(false)
+
+	txn.Configure(pod1, pod1Policies)
+
+	err = txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Test IP address provided by the configurator.
+	ip, masklen := renderer.GetPodIP(pod1)
+	gomega.Expect(masklen).To(gomega.BeEquivalentTo(net.IPv6len * 8))
+	gomega.Expect(ip).To(gomega.BeEquivalentTo(pod1IP))
+
+	// Test with fake traffic.
+
+	// Allowed by policy1.
+	action := renderer.TestTraffic(pod1, EgressTraffic,
+		parseIP(pod2IP), parseIP(pod1IP), rendererAPI.TCP, 123, 80)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(AllowedTraffic))
+
+	// Allowed by policy1.
+	action = renderer.TestTraffic(pod1, EgressTraffic,
+		parseIP("2001:db8:2:bae::1"), parseIP(pod1IP), rendererAPI.TCP, 123, 80)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(AllowedTraffic))
+
+	// Blocked by policy1 - TCP:100 not allowed.
+	action = renderer.TestTraffic(pod1, EgressTraffic,
+		parseIP(pod2IP), parseIP(pod1IP), rendererAPI.TCP, 789, 100)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(DeniedTraffic))
+
+	// Blocked by policy1 - ip from the except range.
+	action = renderer.TestTraffic(pod1, EgressTraffic,
+		parseIP("2001:db8:2:bad::5"), parseIP(pod1IP), rendererAPI.TCP, 123, 80)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(DeniedTraffic))
+
+	// Blocked by policy1 - ip outside of the IP blocks.
+	action = renderer.TestTraffic(pod1, EgressTraffic,
+		parseIP("2001:db8:3::1"), parseIP(pod1IP), rendererAPI.TCP, 123, 80)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(DeniedTraffic))
+}
+
+func TestICMPAndSCTPPolicySinglePod(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestICMPAndSCTPPolicySinglePod")
+
+	// Prepare input data.
+	const (
+		namespace = "default"
+		pod1Name  = "pod1"
+		pod2Name  = "pod2"
+		pod1IP    = "192.168.1.1"
+		pod2IP    = "192.168.2.1"
+	)
+	pod1 := podmodel.ID{Name: pod1Name, Namespace: namespace}
+	pod2 := podmodel.ID{Name: pod2Name, Namespace: namespace}
+
+	policy1 := &ContivPolicy{
+		ID:   policymodel.ID{Name: "policy1", Namespace: namespace},
+		Type: PolicyIngress,
+		Matches: []Match{
+			{
+				Type: MatchIngress,
+				IPBlocks: []IPBlock{
+					{
+						Network: parseIPNet("192.168.2.0/24"),
+					},
+				},
+				Ports: []Port{
+					{Protocol: ICMP, ICMP: &rendererAPI.ICMPMatch{Type: 8, AnyCode: true}},
+					{Protocol: SCTP, Number: 5000},
+				},
+			},
+		},
+	}
+	pod1Policies := []*ContivPolicy{policy1}
+
+	// Initialize mocks.
+	cache := NewMockPolicyCache()
+	cache.AddPodConfig(pod1, pod1IP)
+	cache.AddPodConfig(pod2, pod2IP)
+
+	renderer := NewMockRenderer("A", logger)
+
+	// Initialize configurator.
+	configurator := &PolicyConfigurator{
+		Deps: Deps{
+			Log:   logger,
+			Cache: cache,
+		},
+	}
+	configurator.Init(false)
+
+	// Register one renderer.
+	err := configurator.RegisterRenderer(renderer)
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Run single transaction.
+	txn := configurator.NewTxn(false)
+
+	txn.Configure(pod1, pod1Policies)
+
+	err = txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Test with fake traffic.
+
+	// Allowed by policy1 - echo request.
+	action := renderer.TestTraffic(pod1, EgressTraffic,
+		parseIP(pod2IP), parseIP(pod1IP), rendererAPI.ICMP, 8, 0)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(AllowedTraffic))
+
+	// Allowed by policy1.
+	action = renderer.TestTraffic(pod1, EgressTraffic,
+		parseIP(pod2IP), parseIP(pod1IP), rendererAPI.SCTP, 123, 5000)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(AllowedTraffic))
+
+	// Blocked by policy1 - only echo request is allowed.
+	action = renderer.TestTraffic(pod1, EgressTraffic,
+		parseIP(pod2IP), parseIP(pod1IP), rendererAPI.ICMP, 13, 0)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(DeniedTraffic))
+
+	// Blocked by policy1 - SCTP:5001 not allowed.
+	action = renderer.TestTraffic(pod1, EgressTraffic,
+		parseIP(pod2IP), parseIP(pod1IP), rendererAPI.SCTP, 123, 5001)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(DeniedTraffic))
+
+	// Blocked by policy1 - TCP not allowed.
+	action = renderer.TestTraffic(pod1, EgressTraffic,
+		parseIP(pod2IP), parseIP(pod1IP), rendererAPI.TCP, 123, 80)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(DeniedTraffic))
+
+	// Blocked by policy1 - ping from outside of the IP block.
+	action = renderer.TestTraffic(pod1, EgressTraffic,
+		parseIP("10.0.0.1"), parseIP(pod1IP), rendererAPI.ICMP, 8, 0)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(DeniedTraffic))
+}
+
+func TestPortRangePolicySinglePod(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestPortRangePolicySinglePod")
+
+	// Prepare input data.
+	const (
+		namespace = "default"
+		pod1Name  = "pod1"
+		pod2Name  = "pod2"
+		pod1IP    = "192.168.1.1"
+		pod2IP    = "192.168.2.1"
+	)
+	pod1 := podmodel.ID{Name: pod1Name, Namespace: namespace}
+	pod2 := podmodel.ID{Name: pod2Name, Namespace: namespace}
+
+	policy1 := &ContivPolicy{
+		ID:   policymodel.ID{Name: "policy1", Namespace: namespace},
+		Type: PolicyIngress,
+		Matches: []Match{
+			{
+				Type: MatchIngress,
+				IPBlocks: []IPBlock{
+					{
+						Network: parseIPNet("192.168.2.0/24"),
+					},
+				},
+				Ports: []Port{
+					{Protocol: TCP, Number: 8000, EndNumber: 8080},
+					{Protocol: TCP, Number: 8050, EndNumber: 8100},
+					{Protocol: UDP, Number: 53},
+				},
+			},
+		},
+	}
+	pod1Policies := []*ContivPolicy{policy1}
+
+	// Initialize mocks.
+	cache := NewMockPolicyCache()
+	cache.AddPodConfig(pod1, pod1IP)
+	cache.AddPodConfig(pod2, pod2IP)
+
+	renderer := NewMockRenderer("A", logger)
+
+	// Initialize configurator.
+	configurator := &PolicyConfigurator{
+		Deps: Deps{
+			Log:   logger,
+			Cache: cache,
+		},
+	}
+	configurator.Init(false)
+
+	// Register one renderer.
+	err := configurator.RegisterRenderer(renderer)
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Run single transaction.
+	txn := configurator.NewTxn(false)
+
+	txn.Configure(pod1, pod1Policies)
+
+	err = txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Test with fake traffic.
+
+	// Allowed by policy1 - boundaries and overlap of the port ranges.
+	for _, port := range []uint16{8000, 8055, 8080, 8100} {
+		action := renderer.TestTraffic(pod1, EgressTraffic,
+			parseIP(pod2IP), parseIP(pod1IP), rendererAPI.TCP, 123, port)
+		gomega.Expect(action).To(gomega.BeEquivalentTo(AllowedTraffic))
+	}
+
+	// Allowed by policy1.
+	action := renderer.TestTraffic(pod1, EgressTraffic,
+		parseIP(pod2IP), parseIP(pod1IP), rendererAPI.UDP, 123, 53)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(AllowedTraffic))
+
+	// Blocked by policy1 - outside of the port ranges.
+	action = renderer.TestTraffic(pod1, EgressTraffic,
+		parseIP(pod2IP), parseIP(pod1IP), rendererAPI.TCP, 123, 7999)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(DeniedTraffic))
+	action = renderer.TestTraffic(pod1, EgressTraffic,
+		parseIP(pod2IP), parseIP(pod1IP), rendererAPI.TCP, 123, 8101)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(DeniedTraffic))
+
+	// Blocked by policy1 - UDP range not allowed.
+	action = renderer.TestTraffic(pod1, EgressTraffic,
+		parseIP(pod2IP), parseIP(pod1IP), rendererAPI.UDP, 123, 8050)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(DeniedTraffic))
+
+	// Blocked by policy1 - traffic from outside of the IP block.
+	action = renderer.TestTraffic(pod1, EgressTraffic,
+		parseIP("10.0.0.1"), parseIP(pod1IP), rendererAPI.TCP, 123, 8050)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(DeniedTraffic))
+}
+
 func TestSinglePolicyMultiplePods(t *testing.T) {
 	gomega.RegisterTestingT(t)
 	logger := logrus.DefaultLogger()
@@ -1465,3 +1765,161 @@ func TestMultiplePodsSpecialCases(t *testing.T) {
 		parseIP("10.5.10.10"), parseIP(pod3IP), rendererAPI.TCP, 123, 9000)
 	gomega.Expect(action).To(gomega.BeEquivalentTo(DeniedTraffic))
 }
+
+func TestEvaluateTraffic(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestEvaluateTraffic")
+
+	// Prepare input data.
+	const (
+		namespace = "default"
+		pod1Name  = "pod1"
+		pod2Name  = "pod2"
+		pod1IP    = "192.168.1.1"
+		pod2IP    = "192.168.2.1"
+	)
+	pod1 := podmodel.ID{Name: pod1Name, Namespace: namespace}
+	pod2 := podmodel.ID{Name: pod2Name, Namespace: namespace}
+
+	policy1 := &ContivPolicy{
+		ID:   policymodel.ID{Name: "policy1", Namespace: namespace},
+		Type: PolicyIngress,
+		Matches: []Match{
+			{
+				Type: MatchIngress,
+				Pods: []podmodel.ID{
+					pod2,
+				},
+				Ports: []Port{
+					{Protocol: TCP, Number: 8000},
+				},
+			},
+		},
+	}
+	policy2 := &ContivPolicy{
+		ID:   policymodel.ID{Name: "policy2", Namespace: namespace},
+		Type: PolicyIngress,
+		Matches: []Match{
+			{
+				Type: MatchIngress,
+				IPBlocks: []IPBlock{
+					{
+						Network: parseIPNet("192.168.0.0/16"),
+					},
+				},
+				Ports: []Port{
+					{Protocol: TCP, Number: 8000},
+					{Protocol: UDP, Number: 53},
+				},
+			},
+		},
+	}
+	pod1Policies := []*ContivPolicy{policy1, policy2}
+
+	// Initialize mocks.
+	cache := NewMockPolicyCache()
+	cache.AddPodConfig(pod1, pod1IP)
+	cache.AddPodConfig(pod2, pod2IP)
+
+	renderer := NewMockRenderer("A", logger)
+
+	// Initialize configurator.
+	configurator := &PolicyConfigurator{
+		Deps: Deps{
+			Log:   logger,
+			Cache: cache,
+		},
+	}
+	configurator.Init(false)
+
+	// Register one renderer.
+	err := configurator.RegisterRenderer(renderer)
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Run single transaction.
+	txn := configurator.NewTxn(false)
+
+	txn.Configure(pod1, pod1Policies)
+	txn.Configure(pod2, []*ContivPolicy{})
+
+	err = txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Allowed by both policies.
+	evaluation := configurator.EvaluateTraffic(Connection{
+		SrcIP: *parseIP(pod2IP), DestIP: *parseIP(pod1IP), Protocol: rendererAPI.TCP, SrcPort: 123, DestPort: 8000})
+	gomega.Expect(evaluation.Verdict).To(gomega.BeEquivalentTo(TrafficAllowed))
+	gomega.Expect(evaluation.Pods).To(gomega.HaveLen(2))
+	gomega.Expect(evaluation.Pods[0].Pod).To(gomega.BeEquivalentTo(pod2))
+	gomega.Expect(evaluation.Pods[0].Direction).To(gomega.BeEquivalentTo(MatchEgress))
+	gomega.Expect(evaluation.Pods[0].Verdict).To(gomega.BeEquivalentTo(TrafficAllowed))
+	gomega.Expect(evaluation.Pods[0].Rule).To(gomega.BeNil())
+	gomega.Expect(evaluation.Pods[1].Pod).To(gomega.BeEquivalentTo(pod1))
+	gomega.Expect(evaluation.Pods[1].Direction).To(gomega.BeEquivalentTo(MatchIngress))
+	gomega.Expect(evaluation.Pods[1].Verdict).To(gomega.BeEquivalentTo(TrafficAllowed))
+	gomega.Expect(evaluation.Pods[1].Rule).ToNot(gomega.BeNil())
+	gomega.Expect(evaluation.Pods[1].Rule.Action).To(gomega.BeEquivalentTo(rendererAPI.ActionPermit))
+	gomega.Expect(evaluation.Pods[1].Rule.Policies).To(gomega.Equal([]policymodel.ID{policy1.ID}))
+	gomega.Expect(evaluation.Pods[1].Policies).To(gomega.ConsistOf(policy1.ID, policy2.ID))
+
+	// Allowed only by policy2.
+	evaluation = configurator.EvaluateTraffic(Connection{
+		SrcIP: *parseIP(pod2IP), DestIP: *parseIP(pod1IP), Protocol: rendererAPI.UDP, SrcPort: 123, DestPort: 53})
+	gomega.Expect(evaluation.Verdict).To(gomega.BeEquivalentTo(TrafficAllowed))
+	gomega.Expect(evaluation.Pods).To(gomega.HaveLen(2))
+	gomega.Expect(evaluation.Pods[1].Policies).To(gomega.ConsistOf(policy2.ID))
+
+	// Blocked - port not allowed by any policy.
+	evaluation = configurator.EvaluateTraffic(Connection{
+		SrcIP: *parseIP(pod2IP), DestIP: *parseIP(pod1IP), Protocol: rendererAPI.TCP, SrcPort: 123, DestPort: 8080})
+	gomega.Expect(evaluation.Verdict).To(gomega.BeEquivalentTo(TrafficDenied))
+	gomega.Expect(evaluation.Pods).To(gomega.HaveLen(2))
+	gomega.Expect(evaluation.Pods[0].Verdict).To(gomega.BeEquivalentTo(TrafficAllowed))
+	gomega.Expect(evaluation.Pods[1].Verdict).To(gomega.BeEquivalentTo(TrafficDenied))
+	gomega.Expect(evaluation.Pods[1].Rule).ToNot(gomega.BeNil())
+	gomega.Expect(evaluation.Pods[1].Rule.Action).To(gomega.BeEquivalentTo(rendererAPI.ActionDeny))
+	gomega.Expect(evaluation.Pods[1].Rule.Policies).To(gomega.Equal([]policymodel.ID{policy1.ID, policy2.ID}))
+	gomega.Expect(evaluation.Pods[1].Policies).To(gomega.ConsistOf(policy1.ID, policy2.ID))
+
+	// Blocked - traffic from outside of the IP block.
+	evaluation = configurator.EvaluateTraffic(Connection{
+		SrcIP: *parseIP("10.0.0.1"), DestIP: *parseIP(pod1IP), Protocol: rendererAPI.UDP, SrcPort: 123, DestPort: 53})
+	gomega.Expect(evaluation.Verdict).To(gomega.BeEquivalentTo(TrafficDenied))
+	gomega.Expect(evaluation.Pods).To(gomega.HaveLen(1))
+	gomega.Expect(evaluation.Pods[0].Pod).To(gomega.BeEquivalentTo(pod1))
+
+	// Allowed - the egress side of pod1 is not isolated.
+	evaluation = configurator.EvaluateTraffic(Connection{
+		SrcIP: *parseIP(pod1IP), DestIP: *parseIP("10.0.0.1"), Protocol: rendererAPI.TCP, SrcPort: 123, DestPort: 80})
+	gomega.Expect(evaluation.Verdict).To(gomega.BeEquivalentTo(TrafficAllowed))
+	gomega.Expect(evaluation.Pods).To(gomega.HaveLen(1))
+	gomega.Expect(evaluation.Pods[0].Direction).To(gomega.BeEquivalentTo(MatchEgress))
+	gomega.Expect(evaluation.Pods[0].Rule).To(gomega.BeNil())
+	gomega.Expect(evaluation.Pods[0].Policies).To(gomega.BeEmpty())
+	gomega.Expect(evaluation.Rendered).To(gomega.BeNil())
+
+	// The verdict is given by the rendered rules if a renderer can evaluate them.
+	err = configurator.RegisterRenderer(&evaluatingRenderer{MockRenderer: NewMockRenderer("B", logger), applied: true})
+	gomega.Expect(err).To(gomega.BeNil())
+	evaluation = configurator.EvaluateTraffic(Connection{
+		SrcIP: *parseIP(pod2IP), DestIP: *parseIP(pod1IP), Protocol: rendererAPI.TCP, SrcPort: 123, DestPort: 8000})
+	gomega.Expect(evaluation.Verdict).To(gomega.BeEquivalentTo(TrafficDenied))
+	gomega.Expect(evaluation.Pods[1].Verdict).To(gomega.BeEquivalentTo(TrafficAllowed))
+	gomega.Expect(evaluation.Rendered).ToNot(gomega.BeNil())
+	gomega.Expect(evaluation.Rendered.Verdict).To(gomega.BeEquivalentTo(TrafficDenied))
+	gomega.Expect(evaluation.Rendered.Rule).To(gomega.BeNil())
+}
+
+// evaluatingRenderer is a mock renderer evaluating every connection with the given outcome.
+type evaluatingRenderer struct {
+	*MockRenderer
+	rule    *rendererAPI.ContivRule
+	applied bool
+}
+
+// EvaluateTraffic returns the configured outcome.
+func (er *evaluatingRenderer) EvaluateTraffic(srcIP, destIP net.IP, protocol rendererAPI.ProtocolType, srcPort, destPort uint16) (*rendererAPI.ContivRule, bool) {
+	return er.rule, er.applied
+}
@@ -37,6 +37,9 @@
 //     - watches ETCD for changes written by KSR
 //     - propagates datasync events into the Policy Cache without any processing
 //     - postpones RESYNC until the Contiv plugin has finalized its RESYNC
+//     - serves the "what-if" REST API (/contiv/v1/policy/what-if), which
+//       evaluates the installed policies against a given connection
+//       (see model/whatif and the policy-what-if CLI from cmd/tools)
 //
 //  2. Policy Processor
 //     - implements the PolicyCacheWatcher interface
@@ -65,6 +68,10 @@
 //           * evaluates Label Selectors
 //           * translates port names into numbers
 //           * expands namespaces into pods
+//     - reports an error for policies that cannot be enforced, e.g. the policies
+//       of namespaces connected to a tenant network (multi-tenancy of the Contiv
+//       plugin), which are not rendered for the tenant pods, or the policies
+//       allowing SCTP on selected ports only
 //
 //  3. Policy Configurator
 //     - for a given pod, translates a set of Contiv Policies into ingress and
@@ -84,9 +91,54 @@
 //       that the same set of policies always results in the same list of rules,
 //       allowing renderers to group and share them across multiple interfaces
 //       (if supported by the destination network stack)
+//     - remembers the policies and the rules configured for each pod
+//       to evaluate them against a given connection (what-if analysis),
+//       reporting the verdict, the matching rule and the responsible policies
+//     - the verdict is given by the rules actually rendered if a registered
+//       renderer implements renderer.TrafficEvaluator (the ACL renderer does),
+//       so that rules not rendered and the tables combined by the renderer
+//       cache are taken into account; a verdict of the policies differing from
+//       the rendered one is reported as a warning
+//     - every generated rule records the policies it originates from
+//       (ContivRule.Policies)
 //
 //  4. Policy Renderer
 //     - applies a list of Contiv Rules into the destination network stack
+//     - SCTP is supported only for all ports: the ACL plugin cannot match SCTP
+//       ports, a rule allowing SCTP on a selected port is therefore not rendered
+//       and the traffic it would allow is denied (the policy is reported with
+//       an error by the processor)
+//     - the VPP TCP renderer expands a range of destination ports into one
+//       session rule per port, a rule allowing a range wider than
+//       MaxExpandedPortRange is not rendered and the traffic it would allow
+//       is denied by the session rules (unless the TCP stack is disabled,
+//       the policy is reported with an error by the processor)
+//     - the ACL renderer names every ACL rule with acl.RuleID, derived from
+//       the content of the rule; the ID is therefore the same in every table
+//       sharing the rule and it is restored by the resync (VPP does not keep
+//       the rule names)
+//     - the ACL renderer periodically reads the hit counters of the ACL rules
+//       (every ACLStatsInterval seconds of the policy plugin configuration)
+//       through the VPP CLI of the Contiv plugin: the binary API of the ACL
+//       plugin has no counters, the hash lookup of the plugin however counts
+//       the hits of every rule applied to an interface ("show acl-plugin
+//       tables applied", the ACLs are identified by "show acl-plugin acl");
+//       with the hash matching disabled a warning is logged once and nothing
+//       is exported
+//     - the hits are summed over the interfaces the ACL is applied to and
+//       exported with the Prometheus plugin in the registry /metrics/policy
+//       as contiv_policy_acl_rule_hits, labelled by the ACL (table), the rule
+//       ID, the action and the policies the rule originates from; the pods
+//       a (shared) table is applied to are exported as contiv_policy_acl_pods
+//       labelled by the ACL and the namespace and the name of the pod
+//     - with DenyLogSampling set to N, VPP traces up to N packets received by
+//       each input node in every collection period and the traced packets
+//       denied by a policy ACL are logged with their 5-tuple, the ACL rule, the
+//       pod and the policies of the rule by a dedicated logger
+//       ("policy-aclDenyLog"), which can be redirected or silenced
+//       independently of the agent log; the packet trace is cleared by every
+//       collection, the deny log should be therefore disabled while VPP is
+//       debugged with the packet trace
 //
 // Caches
 // -------
@@ -0,0 +1,92 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package whatif defines the REST API of the policy plugin that evaluates
+// the installed network policies against a given connection ("what-if" analysis).
+package whatif
+
+// RestPath is the URL path of the what-if REST API.
+// The connection to evaluate is described by the query parameters,
+// e.g. /contiv/v1/policy/what-if?src=default/client&dst=10.1.1.3&protocol=TCP&port=8080
+const RestPath = "/contiv/v1/policy/what-if"
+
+// Query parameters of the what-if REST API.
+const (
+	// SrcParam selects the source of the connection: pod as <namespace>/<name>
+	// or an IP address.
+	SrcParam = "src"
+
+	// DstParam selects the destination of the connection: pod as <namespace>/<name>
+	// or an IP address.
+	DstParam = "dst"
+
+	// ProtocolParam is one of TCP (default), UDP, SCTP, ICMP.
+	ProtocolParam = "protocol"
+
+	// PortParam is the destination port, or the ICMP type with ICMP.
+	PortParam = "port"
+
+	// CodeParam is the ICMP code, used only with ICMP.
+	CodeParam = "code"
+)
+
+// Reply is the JSON-encoded reply of the what-if REST API.
+type Reply struct {
+	// Connection is a human-readable representation of the evaluated connection.
+	Connection string `json:"connection,omitempty"`
+
+	// Verdict is ALLOWED or DENIED.
+	Verdict string `json:"verdict,omitempty"`
+
+	// Rendered is true if the verdict is given by the rules actually rendered
+	// into the data plane, otherwise the verdict is given by the policies only.
+	Rendered bool `json:"rendered,omitempty"`
+
+	// RenderedRule is the rendered rule matching the connection, empty if no
+	// rendered rule applies or if the connection is denied by not matching
+	// any of the rendered rules.
+	RenderedRule string `json:"renderedRule,omitempty"`
+
+	// Warning explains a difference between the verdict of the policies
+	// and the verdict of the rendered rules.
+	Warning string `json:"warning,omitempty"`
+
+	// Pods lists evaluations of the policies of the source and the destination
+	// pod, each present only if the pod is deployed on the queried node.
+	Pods []PodEvaluation `json:"pods,omitempty"`
+
+	// Error is set if the query is invalid.
+	Error string `json:"error,omitempty"`
+}
+
+// PodEvaluation is the outcome of the evaluation of the policies of one pod.
+type PodEvaluation struct {
+	// Pod as <namespace>/<name>.
+	Pod string `json:"pod"`
+
+	// Direction from the pod point of view: EGRESS for the source pod,
+	// INGRESS for the destination pod.
+	Direction string `json:"direction"`
+
+	// Verdict is ALLOWED or DENIED.
+	Verdict string `json:"verdict"`
+
+	// Rule is the first rule installed for the pod matching the connection,
+	// empty if the pod is not isolated in the given direction.
+	Rule string `json:"rule,omitempty"`
+
+	// Policies responsible for the verdict as <namespace>/<name> - policies allowing
+	// the connection, or all policies isolating the pod if the connection is denied.
+	Policies []string `json:"policies,omitempty"`
+}
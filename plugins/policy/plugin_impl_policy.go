@@ -16,12 +16,16 @@ package policy
 
 import (
 	"context"
+	"fmt"
 	"sync"
+	"time"
 
 	"github.com/ligato/cn-infra/datasync"
 	"github.com/ligato/cn-infra/datasync/resync"
 	"github.com/ligato/cn-infra/flavors/local"
 	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/cn-infra/rpc/prometheus"
+	"github.com/ligato/cn-infra/rpc/rest"
 	"github.com/ligato/cn-infra/utils/safeclose"
 
 	"github.com/ligato/vpp-agent/clientv1/linux"
@@ -32,6 +36,7 @@ import (
 	"github.com/contiv/vpp/plugins/contiv"
 	"github.com/contiv/vpp/plugins/policy/cache"
 	"github.com/contiv/vpp/plugins/policy/configurator"
+	"github.com/contiv/vpp/plugins/policy/model/whatif"
 	"github.com/contiv/vpp/plugins/policy/processor"
 	"github.com/contiv/vpp/plugins/policy/renderer/acl"
 	"github.com/contiv/vpp/plugins/policy/renderer/vpptcp"
@@ -46,6 +51,7 @@ import (
 // set of network stacks.
 type Plugin struct {
 	Deps
+	Config *Config
 
 	resyncChan chan datasync.ResyncEvent
 	changeChan chan datasync.ChangeEvent
@@ -89,6 +95,17 @@ type Deps struct {
 	Contiv  contiv.API                  /* for GetIfName() */
 	VPP     defaultplugins.API          /* for DumpACLs() */
 	GoVPP   govppmux.API                /* for VPPTCP Renderer */
+	HTTP    rest.HTTPHandlers           /* optional, serves the what-if REST API */
+
+	Prometheus prometheus.API /* optional, exports the hit counters of the policy ACLs */
+}
+
+// Config represents the configuration of the policy plugin.
+// It can be injected or loaded from an external config file (optional). To use external
+// config file, add `-policy-config="<path to config>` argument when running the contiv-agent.
+type Config struct {
+	ACLStatsInterval uint32 // interval of the collection of the ACL hit counters in seconds (default is 10 seconds)
+	DenyLogSampling  uint64 // packets traced per input node in each ACLStatsInterval to log the denied ones (default is 0 - the deny log is disabled)
 }
 
 // Init initializes policy layers and caches and starts watching ETCD for K8s configuration.
@@ -99,6 +116,24 @@ func (p *Plugin) Init() error {
 	p.resyncChan = make(chan datasync.ResyncEvent)
 	p.changeChan = make(chan datasync.ChangeEvent)
 
+	if p.Config == nil {
+		p.Config = &Config{}
+		_, err = p.PluginConfig.GetValue(p.Config)
+		if err != nil {
+			return fmt.Errorf("failed to load the policy plugin configuration: %v", err)
+		}
+	}
+	var aclStats *acl.StatsConfig
+	if p.Prometheus != nil || p.Config.DenyLogSampling > 0 {
+		aclStats = &acl.StatsConfig{
+			Interval:        acl.DefaultStatsInterval,
+			DenyLogSampling: p.Config.DenyLogSampling,
+		}
+		if p.Config.ACLStatsInterval > 0 {
+			aclStats.Interval = time.Duration(p.Config.ACLStatsInterval) * time.Second
+		}
+	}
+
 	// Inject dependencies between layers.
 	p.policyCache = &cache.PolicyCache{
 		Deps: cache.Deps{
@@ -135,6 +170,9 @@ func (p *Plugin) Init() error {
 			ACLTxnFactory: func() linux.DataChangeDSL {
 				return localclient.DataChangeRequest(p.PluginName)
 			},
+			Stats:        aclStats,
+			Prometheus:   p.Prometheus,
+			ServiceLabel: p.ServiceLabel,
 		},
 	}
 	p.aclRenderer.Log.SetLevel(logging.DebugLevel)
@@ -159,7 +197,10 @@ func (p *Plugin) Init() error {
 	p.policyCache.Init()
 	p.processor.Init()
 	p.configurator.Init(false) // Do not render in parallel while we do lot of debugging.
-	p.aclRenderer.Init()
+	err = p.aclRenderer.Init()
+	if err != nil {
+		return err
+	}
 	if !p.Contiv.IsTCPstackDisabled() {
 		p.vppTCPRenderer.Init()
 	}
@@ -170,9 +211,25 @@ func (p *Plugin) Init() error {
 		p.configurator.RegisterRenderer(p.vppTCPRenderer)
 	}
 
+	// Serve the REST API evaluating the installed policies against a given connection.
+	if p.HTTP != nil {
+		p.HTTP.RegisterHTTPHandler(whatif.RestPath, p.whatIfHandler, "GET")
+	}
+
 	p.ctx, p.cancel = context.WithCancel(context.Background())
 
 	go p.watchEvents()
+	if aclStats != nil {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			p.aclRenderer.CollectStats(p.ctx)
+		}()
+	}
+	overlayTunnelWatcher := make(chan struct{}, 1)
+	p.Contiv.WatchOverlayTunnels(overlayTunnelWatcher)
+	p.wg.Add(1)
+	go p.watchOverlayTunnels(overlayTunnelWatcher)
 	err = p.subscribeWatcher()
 	if err != nil {
 		return err
@@ -195,7 +252,7 @@ func (p *Plugin) AfterInit() error {
 func (p *Plugin) subscribeWatcher() (err error) {
 	p.watchConfigReg, err = p.Watcher.
 		Watch("K8s policies", p.changeChan, p.resyncChan,
-			nsmodel.KeyPrefix(), podmodel.KeyPrefix(), policymodel.KeyPrefix())
+			nsmodel.KeyPrefix(), podmodel.KeyPrefix(), podmodel.IPv6KeyPrefix(), policymodel.KeyPrefix())
 	return err
 }
 
@@ -232,6 +289,23 @@ func (p *Plugin) watchEvents() {
 	}
 }
 
+// watchOverlayTunnels re-applies the ACLs on the interfaces connecting this node with the outside world
+// when the tunnels of the overlay towards the other nodes change.
+func (p *Plugin) watchOverlayTunnels(overlayTunnelWatcher chan struct{}) {
+	defer p.wg.Done()
+
+	for {
+		select {
+		case <-overlayTunnelWatcher:
+			if err := p.aclRenderer.UpdateNodeOutputInterfaces(); err != nil {
+				p.Log.Error(err)
+			}
+		case <-p.ctx.Done():
+			return
+		}
+	}
+}
+
 func (p *Plugin) handleResync(resyncChan chan resync.StatusEvent) {
 	// block until NodeIP is set
 	nodeIPWatcher := make(chan string, 1)
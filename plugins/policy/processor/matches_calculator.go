@@ -6,6 +6,7 @@ import (
 	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
 	policymodel "github.com/contiv/vpp/plugins/ksr/model/policy"
 	config "github.com/contiv/vpp/plugins/policy/configurator"
+	"github.com/contiv/vpp/plugins/policy/renderer"
 )
 
 // calculateMatches finds the returns a predicate that selects a subset of the traffic by calculating
@@ -68,16 +69,7 @@ func (pp *PolicyProcessor) calculateMatches(policyData *policymodel.Policy) []co
 
 			ingressRulePorts := ingressRule.Port
 			for _, ingressRulePort := range ingressRulePorts {
-				ingressPortProtocol := config.TCP
-				if ingressRulePort.Protocol == policymodel.Policy_Port_UDP {
-					ingressPortProtocol = config.UDP
-				}
-				// todo: translate form name to port number
-				ingressPortNumber := uint16(ingressRulePort.Port.Number)
-				ingressPorts = append(ingressPorts, config.Port{
-					Protocol: ingressPortProtocol,
-					Number:   ingressPortNumber,
-				})
+				ingressPorts = append(ingressPorts, portToConfig(ingressRulePort))
 			}
 
 			matches = append(matches, config.Match{
@@ -140,16 +132,7 @@ func (pp *PolicyProcessor) calculateMatches(policyData *policymodel.Policy) []co
 			egressRulePorts := egressRule.Port
 			// Egress ports to appropriate type
 			for _, egressRulePort := range egressRulePorts {
-				egressPortProtocol := config.TCP
-				if egressRulePort.Protocol == policymodel.Policy_Port_UDP {
-					egressPortProtocol = config.UDP
-				}
-				// todo: translate form name to port number
-				egressPortNumber := uint16(egressRulePort.Port.Number)
-				egressPorts = append(egressPorts, config.Port{
-					Protocol: egressPortProtocol,
-					Number:   egressPortNumber,
-				})
+				egressPorts = append(egressPorts, portToConfig(egressRulePort))
 			}
 
 			matches = append(matches, config.Match{
@@ -199,3 +182,33 @@ func (pp *PolicyProcessor) calculateLabelSelectorMatches(
 	// empty labelselector selects all pods
 	return true
 }
+
+// portToConfig converts port selector from the policy model into the format
+// used by Policy Configurator.
+func portToConfig(port *policymodel.Policy_Port) config.Port {
+	if port.Protocol == policymodel.Policy_Port_ICMP {
+		configPort := config.Port{Protocol: config.ICMP}
+		if port.Icmp != nil {
+			configPort.ICMP = &renderer.ICMPMatch{
+				Type:    uint8(port.Icmp.Type),
+				Code:    uint8(port.Icmp.Code),
+				AnyCode: port.Icmp.AnyCode,
+			}
+		}
+		return configPort
+	}
+
+	// todo: translate form name to port number
+	configPort := config.Port{Protocol: config.TCP, Number: uint16(port.GetPort().GetNumber())}
+	switch port.Protocol {
+	case policymodel.Policy_Port_UDP:
+		configPort.Protocol = config.UDP
+	case policymodel.Policy_Port_SCTP:
+		configPort.Protocol = config.SCTP
+	}
+	if port.GetPort().GetType() == policymodel.Policy_Port_PortNameOrNumber_NUMBER &&
+		port.EndPort > port.GetPort().GetNumber() {
+		configPort.EndNumber = uint16(port.EndPort)
+	}
+	return configPort
+}
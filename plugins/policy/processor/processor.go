@@ -17,6 +17,7 @@
 package processor
 
 import (
+	"fmt"
 	"net"
 
 	"github.com/ligato/cn-infra/logging"
@@ -27,6 +28,7 @@ import (
 	policymodel "github.com/contiv/vpp/plugins/ksr/model/policy"
 	"github.com/contiv/vpp/plugins/policy/cache"
 	config "github.com/contiv/vpp/plugins/policy/configurator"
+	vpptcprule "github.com/contiv/vpp/plugins/policy/renderer/vpptcp/rule"
 	"github.com/contiv/vpp/plugins/policy/utils"
 )
 
@@ -145,6 +147,9 @@ func (pp *PolicyProcessor) Process(resync bool, pods []podmodel.ID) error {
 // Resync processes the RESYNC event by re-calculating the policies for all
 // known pods.
 func (pp *PolicyProcessor) Resync(data *cache.DataResyncEvent) error {
+	for _, policy := range data.Policies {
+		pp.reportUnenforceablePolicy(policy)
+	}
 	return pp.Process(true, pp.Cache.ListAllPods())
 }
 
@@ -238,7 +243,7 @@ func (pp *PolicyProcessor) UpdatePod(podID podmodel.ID, oldPod, newPod *podmodel
 	}
 
 	// Process this pod also in case the IP address has changed.
-	if newPod.IpAddress != oldPod.IpAddress {
+	if newPod.IpAddress != oldPod.IpAddress || newPod.Ipv6Address != oldPod.Ipv6Address {
 		pods = append(pods, podID)
 	}
 
@@ -256,6 +261,7 @@ func (pp *PolicyProcessor) AddPolicy(policy *policymodel.Policy) error {
 		pp.Log.WithField("policy", policy).Error("Error reading Policy")
 		return nil
 	}
+	pp.reportUnenforceablePolicy(policy)
 
 	// Find all the pods that match the newly added policy.
 	pods := pp.getPodsAssignedToPolicy(policy)
@@ -297,6 +303,7 @@ func (pp *PolicyProcessor) UpdatePolicy(oldPolicy, newPolicy *policymodel.Policy
 		pp.Log.WithField("policy", oldPolicy).Error("Error reading Old Policy")
 		return nil
 	}
+	pp.reportUnenforceablePolicy(newPolicy)
 
 	// Get all matching pods before the change and now.
 	pods := []podmodel.ID{}
@@ -354,6 +361,50 @@ func (pp *PolicyProcessor) Close() error {
 	return nil
 }
 
+// reportUnenforceablePolicy logs an error if the given policy cannot be enforced
+// (or can be enforced only partially) by the policy renderers.
+func (pp *PolicyProcessor) reportUnenforceablePolicy(policy *policymodel.Policy) {
+	if err := pp.checkPolicy(policy); err != nil {
+		pp.Log.WithField("policy", policy.Namespace+"/"+policy.Name).Error(err)
+	}
+}
+
+// checkPolicy returns an error describing why the given policy cannot be enforced,
+// nil if the policy is fully supported.
+func (pp *PolicyProcessor) checkPolicy(policy *policymodel.Policy) error {
+	if tenant := pp.Contiv.GetNamespaceTenant(policy.Namespace); tenant != "" {
+		return fmt.Errorf("policy selects pods of the namespace %s connected to the network of the tenant %s, "+
+			"policies are not enforced for tenant pods", policy.Namespace, tenant)
+	}
+	var ports []*policymodel.Policy_Port
+	for _, rule := range policy.IngressRule {
+		ports = append(ports, rule.Port...)
+	}
+	for _, rule := range policy.EgressRule {
+		ports = append(ports, rule.Port...)
+	}
+	for _, port := range ports {
+		if port.Protocol != policymodel.Policy_Port_SCTP && port.Port != nil && !pp.Contiv.IsTCPstackDisabled() &&
+			port.Port.Type == policymodel.Policy_Port_PortNameOrNumber_NUMBER && port.EndPort > port.Port.Number &&
+			!vpptcprule.IsExpandablePortRange(uint16(port.Port.Number), uint16(port.EndPort)) {
+			// session rules cannot match port ranges, the traffic is left to the subsequent deny rules
+			return fmt.Errorf("policy allows port range %d-%d, but session rules of the VPP TCP stack can "+
+				"allow ranges of at most %d ports, the traffic of pods using the stack is denied",
+				port.Port.Number, port.EndPort, vpptcprule.MaxExpandedPortRange)
+		}
+		if port.Protocol == policymodel.Policy_Port_SCTP && port.Port != nil {
+			// ACL plugin cannot match SCTP ports, the traffic is left to the subsequent deny rules
+			portName := port.Port.Name
+			if port.Port.Type == policymodel.Policy_Port_PortNameOrNumber_NUMBER {
+				portName = fmt.Sprint(port.Port.Number)
+			}
+			return fmt.Errorf("policy allows SCTP port %s, but SCTP can be allowed only for all ports "+
+				"(the ACL plugin cannot match SCTP ports), the traffic is denied", portName)
+		}
+	}
+	return nil
+}
+
 // filterHostPods filters out pods from the passed list which are not deployed
 // on the current node.
 func (pp *PolicyProcessor) filterHostPods(pods []podmodel.ID) []podmodel.ID {
@@ -362,8 +413,6 @@ func (pp *PolicyProcessor) filterHostPods(pods []podmodel.ID) []podmodel.ID {
 		hadIP        bool
 		hostPods     []podmodel.ID
 	)
-	hostNetwork := pp.Contiv.GetPodNetwork()
-
 	for _, podID := range pods {
 		found, podData := pp.Cache.LookupPod(podID)
 
@@ -374,7 +423,7 @@ func (pp *PolicyProcessor) filterHostPods(pods []podmodel.ID) []podmodel.ID {
 		} else {
 			podIPAddress = net.ParseIP(podData.IpAddress)
 		}
-		if !hostNetwork.Contains(podIPAddress) {
+		if !pp.Contiv.IsLocalPodIP(podIPAddress) {
 			continue
 		}
 		hostPods = append(hostPods, podID)
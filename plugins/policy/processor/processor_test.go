@@ -1 +1,327 @@
 package processor
+
+import (
+	"net"
+	"testing"
+
+	"github.com/onsi/gomega"
+
+	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/cn-infra/logging/logrus"
+
+	"github.com/contiv/vpp/mock/aclengine"
+	"github.com/contiv/vpp/mock/contiv"
+	"github.com/contiv/vpp/mock/datasync"
+	"github.com/contiv/vpp/mock/defaultplugins"
+	"github.com/contiv/vpp/mock/localclient"
+	nsmodel "github.com/contiv/vpp/plugins/ksr/model/namespace"
+	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
+	policymodel "github.com/contiv/vpp/plugins/ksr/model/policy"
+	"github.com/contiv/vpp/plugins/policy/cache"
+	config "github.com/contiv/vpp/plugins/policy/configurator"
+	"github.com/contiv/vpp/plugins/policy/renderer/acl"
+)
+
+func TestTenantPolicy(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestTenantPolicy")
+
+	contiv := contiv.NewMockContiv()
+	contiv.SetNamespaceTenant("tenant-ns", "tenant1")
+
+	processor := &PolicyProcessor{
+		Deps: Deps{
+			Log:    logger,
+			Contiv: contiv,
+		},
+	}
+
+	// policy of the pod network
+	policy := &policymodel.Policy{Name: "policy1", Namespace: "default"}
+	gomega.Expect(processor.checkPolicy(policy)).To(gomega.BeNil())
+
+	// policy of a tenant namespace
+	policy = &policymodel.Policy{Name: "policy2", Namespace: "tenant-ns"}
+	err := processor.checkPolicy(policy)
+	gomega.Expect(err).ToNot(gomega.BeNil())
+	gomega.Expect(err.Error()).To(gomega.ContainSubstring("tenant1"))
+}
+
+func TestSCTPPortPolicy(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestSCTPPortPolicy")
+
+	processor := &PolicyProcessor{
+		Deps: Deps{
+			Log:    logger,
+			Contiv: contiv.NewMockContiv(),
+		},
+	}
+
+	// SCTP allowed for all ports
+	policy := &policymodel.Policy{
+		Name:      "policy1",
+		Namespace: "default",
+		IngressRule: []*policymodel.Policy_IngressRule{
+			{
+				Port: []*policymodel.Policy_Port{
+					{Protocol: policymodel.Policy_Port_SCTP},
+				},
+			},
+		},
+	}
+	gomega.Expect(processor.checkPolicy(policy)).To(gomega.BeNil())
+
+	// SCTP allowed for a selected port
+	policy.EgressRule = []*policymodel.Policy_EgressRule{
+		{
+			Port: []*policymodel.Policy_Port{
+				{
+					Protocol: policymodel.Policy_Port_SCTP,
+					Port: &policymodel.Policy_Port_PortNameOrNumber{
+						Type:   policymodel.Policy_Port_PortNameOrNumber_NUMBER,
+						Number: 3868,
+					},
+				},
+			},
+		},
+	}
+	err := processor.checkPolicy(policy)
+	gomega.Expect(err).ToNot(gomega.BeNil())
+	gomega.Expect(err.Error()).To(gomega.ContainSubstring("SCTP port 3868"))
+}
+
+func TestPortRangePolicy(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestPortRangePolicy")
+
+	contiv := contiv.NewMockContiv()
+	processor := &PolicyProcessor{
+		Deps: Deps{
+			Log:    logger,
+			Contiv: contiv,
+		},
+	}
+
+	policyWithRange := func(port, endPort int32) *policymodel.Policy {
+		return &policymodel.Policy{
+			Name:      "policy1",
+			Namespace: "default",
+			IngressRule: []*policymodel.Policy_IngressRule{
+				{
+					Port: []*policymodel.Policy_Port{
+						{
+							Protocol: policymodel.Policy_Port_TCP,
+							Port: &policymodel.Policy_Port_PortNameOrNumber{
+								Type:   policymodel.Policy_Port_PortNameOrNumber_NUMBER,
+								Number: port,
+							},
+							EndPort: endPort,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	// range expanded into session rules
+	gomega.Expect(processor.checkPolicy(policyWithRange(8080, 8143))).To(gomega.BeNil())
+
+	// range too wide for session rules
+	err := processor.checkPolicy(policyWithRange(30000, 32767))
+	gomega.Expect(err).ToNot(gomega.BeNil())
+	gomega.Expect(err.Error()).To(gomega.ContainSubstring("30000-32767"))
+
+	// session rules are not used with the TCP stack disabled
+	contiv.SetTCPStackDisabled(true)
+	gomega.Expect(processor.checkPolicy(policyWithRange(30000, 32767))).To(gomega.BeNil())
+}
+
+func TestFilterHostPods(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestFilterHostPods")
+
+	contiv := contiv.NewMockContiv()
+	contiv.SetPodNetwork("10.1.1.0/24")
+	contiv.AddPodNetwork("10.1.5.0/24") // pod CIDR block allocated when the primary block was exhausted
+
+	policyCache := &cache.PolicyCache{Deps: cache.Deps{Log: logger}}
+	gomega.Expect(policyCache.Init()).To(gomega.BeNil())
+	processor := &PolicyProcessor{
+		Deps: Deps{
+			Log:    logger,
+			Contiv: contiv,
+			Cache:  policyCache,
+		},
+	}
+	gomega.Expect(processor.Init()).To(gomega.BeNil())
+
+	primaryPod := podmodel.ID{Name: "pod1", Namespace: "default"}
+	extraBlockPod := podmodel.ID{Name: "pod2", Namespace: "default"}
+	remotePod := podmodel.ID{Name: "pod3", Namespace: "default"}
+	processor.podIPAddressMap[primaryPod] = net.ParseIP("10.1.1.3")
+	processor.podIPAddressMap[extraBlockPod] = net.ParseIP("10.1.5.3")
+	processor.podIPAddressMap[remotePod] = net.ParseIP("10.1.2.3")
+
+	hostPods := processor.filterHostPods([]podmodel.ID{primaryPod, extraBlockPod, remotePod})
+	gomega.Expect(hostPods).To(gomega.ConsistOf(primaryPod, extraBlockPod))
+}
+
+func TestDualStackPodPeers(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestDualStackPodPeers")
+
+	const namespace = "default"
+	dbPod := podmodel.ID{Name: "db", Namespace: namespace}
+	webPod := podmodel.ID{Name: "web", Namespace: namespace}
+	otherPod := podmodel.ID{Name: "other", Namespace: namespace}
+	pods := []struct {
+		id     podmodel.ID
+		ip     string
+		ipv6   string
+		app    string
+		ifName string
+	}{
+		{id: dbPod, ip: "10.1.1.1", ipv6: "fd00:1::1", app: "db", ifName: "tap1"},
+		{id: webPod, ip: "10.1.1.2", ipv6: "fd00:1::2", app: "web", ifName: "tap2"},
+		{id: otherPod, ip: "10.1.1.3", ipv6: "fd00:1::3", app: "other", ifName: "tap3"},
+	}
+
+	// Prepare the whole pipeline: cache -> processor -> configurator -> ACL renderer.
+	contiv := contiv.NewMockContiv()
+	contiv.SetPodNetwork("10.1.1.0/24")
+	contiv.SetMainPhysicalIfName("GbE")
+	contiv.SetVxlanBVIIfName("VXLAN-BVI")
+	contiv.SetHostInterconnectIfName("VPP-Host")
+	for _, pod := range pods {
+		contiv.SetPodIfName(pod.id, pod.ifName)
+	}
+
+	aclEngine := aclengine.NewMockACLEngine(logger, contiv)
+	txnTracker := localclient.NewTxnTracker(aclEngine.ApplyTxn)
+	aclRenderer := &acl.Renderer{
+		Deps: acl.Deps{
+			Log:           logger,
+			Contiv:        contiv,
+			VPP:           defaultplugins.NewMockVppPlugin(),
+			ACLTxnFactory: txnTracker.NewLinuxDataChangeTxn,
+		},
+	}
+	gomega.Expect(aclRenderer.Init()).To(gomega.BeNil())
+
+	policyCache := &cache.PolicyCache{Deps: cache.Deps{Log: logger}}
+	gomega.Expect(policyCache.Init()).To(gomega.BeNil())
+	configurator := &config.PolicyConfigurator{
+		Deps: config.Deps{
+			Log:   logger,
+			Cache: policyCache,
+		},
+	}
+	gomega.Expect(configurator.Init(false)).To(gomega.BeNil())
+	gomega.Expect(configurator.RegisterRenderer(aclRenderer)).To(gomega.BeNil())
+	processor := &PolicyProcessor{
+		Deps: Deps{
+			Log:          logger,
+			Contiv:       contiv,
+			Cache:        policyCache,
+			Configurator: configurator,
+		},
+	}
+	gomega.Expect(processor.Init()).To(gomega.BeNil())
+
+	// Reflect the K8s state: db pods accept TCP:80 only from web pods.
+	dataSync := datasync.NewMockDataSync()
+	gomega.Expect(policyCache.Update(dataSync.Put(nsmodel.Key(namespace),
+		&nsmodel.Namespace{Name: namespace}))).To(gomega.BeNil())
+	for _, pod := range pods {
+		gomega.Expect(policyCache.Update(dataSync.Put(podmodel.Key(pod.id.Name, pod.id.Namespace), &podmodel.Pod{
+			Name:      pod.id.Name,
+			Namespace: pod.id.Namespace,
+			IpAddress: pod.ip,
+			Label:     []*podmodel.Pod_Label{{Key: "app", Value: pod.app}},
+		}))).To(gomega.BeNil())
+	}
+	policy := &policymodel.Policy{
+		Name:       "allow-web",
+		Namespace:  namespace,
+		PolicyType: policymodel.Policy_INGRESS,
+		Pods: &policymodel.Policy_LabelSelector{
+			MatchLabel: []*policymodel.Policy_Label{{Key: "app", Value: "db"}},
+		},
+		IngressRule: []*policymodel.Policy_IngressRule{
+			{
+				Port: []*policymodel.Policy_Port{
+					{
+						Protocol: policymodel.Policy_Port_TCP,
+						Port: &policymodel.Policy_Port_PortNameOrNumber{
+							Type:   policymodel.Policy_Port_PortNameOrNumber_NUMBER,
+							Number: 80,
+						},
+					},
+				},
+				From: []*policymodel.Policy_Peer{
+					{
+						Pods: &policymodel.Policy_LabelSelector{
+							MatchLabel: []*policymodel.Policy_Label{{Key: "app", Value: "web"}},
+						},
+					},
+				},
+			},
+		},
+	}
+	gomega.Expect(policyCache.Update(dataSync.Put(policymodel.Key(policy.Name, policy.Namespace),
+		policy))).To(gomega.BeNil())
+
+	// IPv4 traffic is allowed only from the peer.
+	for _, pod := range pods {
+		aclEngine.RegisterPod(pod.id, pod.ip, false)
+	}
+	gomega.Expect(aclEngine.ConnectionPodToPod(webPod, dbPod, aclengine.TCP, 500, 80)).To(gomega.Equal(aclengine.ConnActionAllow))
+	gomega.Expect(aclEngine.ConnectionPodToPod(otherPod, dbPod, aclengine.TCP, 500, 80)).ToNot(gomega.Equal(aclengine.ConnActionAllow))
+
+	// Without the IPv6 addresses reflected, the IPv6 traffic of the peer is denied as well.
+	for _, pod := range pods {
+		aclEngine.RegisterPod(pod.id, pod.ipv6, false)
+	}
+	gomega.Expect(aclEngine.ConnectionPodToPod(webPod, dbPod, aclengine.TCP, 500, 80)).ToNot(gomega.Equal(aclengine.ConnActionAllow))
+
+	// Reflect the IPv6 addresses assigned by the Contiv agent.
+	for _, pod := range pods {
+		gomega.Expect(policyCache.Update(dataSync.Put(podmodel.IPv6Key(pod.id.Name, pod.id.Namespace), &podmodel.Pod{
+			Name:        pod.id.Name,
+			Namespace:   pod.id.Namespace,
+			Ipv6Address: pod.ipv6,
+		}))).To(gomega.BeNil())
+	}
+	found, webData := policyCache.LookupPod(webPod)
+	gomega.Expect(found).To(gomega.BeTrue())
+	gomega.Expect(webData.Ipv6Address).To(gomega.Equal("fd00:1::2"))
+
+	// IPv6 traffic is allowed only from the peer.
+	gomega.Expect(aclEngine.ConnectionPodToPod(webPod, dbPod, aclengine.TCP, 500, 80)).To(gomega.Equal(aclengine.ConnActionAllow))
+	gomega.Expect(aclEngine.ConnectionPodToPod(webPod, dbPod, aclengine.TCP, 500, 81)).ToNot(gomega.Equal(aclengine.ConnActionAllow))
+	gomega.Expect(aclEngine.ConnectionPodToPod(otherPod, dbPod, aclengine.TCP, 500, 80)).ToNot(gomega.Equal(aclengine.ConnActionAllow))
+
+	// The reflected address survives updates of the pod data by KSR.
+	gomega.Expect(policyCache.Update(dataSync.Put(podmodel.Key(webPod.Name, webPod.Namespace), &podmodel.Pod{
+		Name:      webPod.Name,
+		Namespace: webPod.Namespace,
+		IpAddress: "10.1.1.2",
+		Label:     []*podmodel.Pod_Label{{Key: "app", Value: "web"}, {Key: "tier", Value: "frontend"}},
+	}))).To(gomega.BeNil())
+	gomega.Expect(aclEngine.ConnectionPodToPod(webPod, dbPod, aclengine.TCP, 500, 80)).To(gomega.Equal(aclengine.ConnActionAllow))
+
+	// Withdrawn address is no longer allowed.
+	gomega.Expect(policyCache.Update(dataSync.Delete(podmodel.IPv6Key(webPod.Name, webPod.Namespace)))).To(gomega.BeNil())
+	gomega.Expect(aclEngine.ConnectionPodToPod(webPod, dbPod, aclengine.TCP, 500, 80)).ToNot(gomega.Equal(aclengine.ConnActionAllow))
+}
@@ -19,10 +19,13 @@ package acl
 import (
 	"net"
 	"strings"
+	"sync"
 
 	"github.com/golang/protobuf/proto"
 
 	"github.com/ligato/cn-infra/logging"
+	prometheusplugin "github.com/ligato/cn-infra/rpc/prometheus"
+	"github.com/ligato/cn-infra/servicelabel"
 	"github.com/ligato/vpp-agent/clientv1/linux"
 	"github.com/ligato/vpp-agent/plugins/defaultplugins"
 	vpp_acl "github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/acl"
@@ -31,6 +34,7 @@ import (
 	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
 	"github.com/contiv/vpp/plugins/policy/renderer"
 	"github.com/contiv/vpp/plugins/policy/renderer/cache"
+	"github.com/contiv/vpp/plugins/policy/utils"
 )
 
 const (
@@ -41,6 +45,18 @@ const (
 	// ACLNamePrefix). Reflective ACL is used to allow responses of accepted sessions
 	// regardless of installed policies on the way back.
 	ReflectiveACLName = "REFLECTION"
+
+	// sctpProtocolNumber is the IP protocol number of SCTP.
+	// ACL plugin cannot match SCTP ports, SCTP is therefore matched as "Other" protocol.
+	// Note: the vendored aclplugin does not yet program the protocol number of "Other"
+	// rules into VPP, making them match any protocol not matched by preceding rules.
+	sctpProtocolNumber = 132
+
+	// ipv6AnyNetwork is used in ACL rules that match all IPv6 addresses.
+	// ACL rule with undefined source and destination network matches only
+	// IPv4 traffic, rules matching all IP addresses are therefore rendered
+	// with an IPv6 counterpart which has ipv6AnyNetwork on both sides.
+	ipv6AnyNetwork = "::/0"
 )
 
 // Renderer renders Contiv Rules into VPP ACLs.
@@ -49,17 +65,27 @@ const (
 type Renderer struct {
 	Deps
 
+	// the lock guards the cache against the collection of the ACL hit counters
+	sync.Mutex
+
 	cache         *cache.RendererCache
 	podInterfaces PodInterfaces
+
+	stats   *aclStats
+	denyLog logging.Logger
 }
 
 // Deps lists dependencies of Renderer.
 type Deps struct {
 	Log           logging.Logger
 	LogFactory    logging.LogFactory /* optional */
-	Contiv        contiv.API         /* for GetIfName() */
+	Contiv        contiv.API         /* for GetIfName(), ExecuteVppCLI() */
 	VPP           defaultplugins.API /* for DumpACLs() */
 	ACLTxnFactory func() (dsl linux.DataChangeDSL)
+
+	Stats        *StatsConfig           /* optional, enables the collection of the ACL hit counters */
+	Prometheus   prometheusplugin.API   /* optional, exports the ACL hit counters */
+	ServiceLabel servicelabel.ReaderAPI /* optional, node label of the exported ACL hit counters */
 }
 
 // RendererTxn represents a single transaction of Renderer.
@@ -84,9 +110,81 @@ func (r *Renderer) Init() error {
 	}
 	r.cache.Init(cache.EgressOrientation)
 	r.podInterfaces = make(PodInterfaces)
+	if r.Stats != nil {
+		if r.LogFactory != nil {
+			r.denyLog = r.LogFactory.NewLogger("-aclDenyLog")
+		} else {
+			r.denyLog = r.Log
+		}
+		return r.initStats()
+	}
 	return nil
 }
 
+// UpdateNodeOutputInterfaces re-applies the global and the reflective ACL on the interfaces connecting this node
+// with the outside world, which change at runtime with the IP-in-IP overlay (the tunnels towards the other nodes).
+// Nothing is done if the global table is empty, the ACLs are then not applied on these interfaces.
+func (r *Renderer) UpdateNodeOutputInterfaces() error {
+	r.Lock()
+	defer r.Unlock()
+
+	globalTable := r.cache.GetGlobalTable()
+	if globalTable.NumOfRules == 0 {
+		return nil
+	}
+	art := &RendererTxn{
+		Log:      r.Log,
+		cacheTxn: r.cache.NewTxn(),
+		vpp:      r.VPP,
+		renderer: r,
+	}
+	globalACL := art.renderACL(globalTable)
+	globalACL.Interfaces.Egress = art.getNodeOutputInterfaces()
+	reflectiveACL := art.reflectiveACL()
+	r.Log.WithFields(logging.Fields{
+		"egress": globalACL.Interfaces.Egress,
+	}).Debug("Updating interfaces of the Global and the Reflective ACL")
+	return r.ACLTxnFactory().Put().ACL(globalACL).ACL(reflectiveACL).Send().ReceiveReply()
+}
+
+// EvaluateTraffic returns the rendered rule matching the given connection.
+// With the egress orientation of the cache, the connection is matched against
+// the local table of the destination pod if it is deployed on this node,
+// otherwise against the global table if the source pod is deployed on this node.
+// ACLs deny the traffic not matched by any of their rules.
+func (r *Renderer) EvaluateTraffic(srcIP, destIP net.IP, protocol renderer.ProtocolType, srcPort, destPort uint16) (rule *renderer.ContivRule, applied bool) {
+	r.Lock()
+	defer r.Unlock()
+
+	var table *cache.ContivRuleTable
+	if destPod, isLocal := r.lookupPodByIP(destIP); isLocal {
+		table = r.cache.GetLocalTableByPod(destPod)
+	} else if _, isLocal := r.lookupPodByIP(srcIP); isLocal {
+		table = r.cache.GetGlobalTable()
+	}
+	if table == nil || table.NumOfRules == 0 {
+		return nil, false
+	}
+	for i := 0; i < table.NumOfRules; i++ {
+		rule := table.Rules[i]
+		if isRenderable(rule) && rule.Matches(srcIP, destIP, protocol, srcPort, destPort) {
+			return rule, true
+		}
+	}
+	return nil, true
+}
+
+// lookupPodByIP returns ID of the pod with the given IP address tracked by the cache.
+func (r *Renderer) lookupPodByIP(ip net.IP) (pod podmodel.ID, found bool) {
+	for pod := range r.cache.GetAllPods() {
+		podConfig := r.cache.GetPodConfig(pod)
+		if podConfig != nil && podConfig.PodIP != nil && podConfig.PodIP.IP.Equal(ip) {
+			return pod, true
+		}
+	}
+	return pod, false
+}
+
 // NewTxn starts a new transaction. The rendering executes only after Commit()
 // is called. Rollback is not yet supported however.
 // If <resync> is enabled, the supplied configuration will completely
@@ -122,6 +220,9 @@ func (art *RendererTxn) Render(pod podmodel.ID, podIP *net.IPNet, ingress []*ren
 // calculated using RendererCache and applied as one transaction via the
 // localclient.
 func (art *RendererTxn) Commit() error {
+	art.renderer.Lock()
+	defer art.renderer.Unlock()
+
 	var (
 		aclDump          []*cache.ContivRuleTable
 		globalTable      *cache.ContivRuleTable
@@ -266,25 +367,18 @@ func (art *RendererTxn) Commit() error {
 // reflectiveACL returns the configuration of the reflective ACL.
 func (art *RendererTxn) reflectiveACL() *vpp_acl.AccessLists_Acl {
 	// Prepare table to render the ACL from.
-	ruleTCPAny := &renderer.ContivRule{
-		Action:      renderer.ActionPermit,
-		SrcNetwork:  &net.IPNet{},
-		DestNetwork: &net.IPNet{},
-		Protocol:    renderer.TCP,
-		SrcPort:     0,
-		DestPort:    0,
-	}
-	ruleUDPAny := &renderer.ContivRule{
-		Action:      renderer.ActionPermit,
-		SrcNetwork:  &net.IPNet{},
-		DestNetwork: &net.IPNet{},
-		Protocol:    renderer.UDP,
-		SrcPort:     0,
-		DestPort:    0,
-	}
 	table := cache.NewContivRuleTable(ReflectiveACLName)
-	table.Rules = []*renderer.ContivRule{ruleTCPAny, ruleUDPAny}
-	table.NumOfRules = 2
+	for _, protocol := range renderer.Protocols {
+		table.Rules = append(table.Rules, &renderer.ContivRule{
+			Action:      renderer.ActionPermit,
+			SrcNetwork:  &net.IPNet{},
+			DestNetwork: &net.IPNet{},
+			Protocol:    protocol,
+			SrcPort:     0,
+			DestPort:    0,
+		})
+	}
+	table.NumOfRules = len(table.Rules)
 	table.Pods = art.cacheTxn.GetIsolatedPods()
 	// Render the ACL.
 	acl := art.renderACL(table)
@@ -305,15 +399,16 @@ func (art *RendererTxn) getNodeOutputInterfaces() []string {
 	if vxlanBVI != "" {
 		interfaces = append(interfaces, vxlanBVI)
 	}
+	// with the IP-in-IP overlay the traffic of the other nodes is received directly on the tunnels
+	interfaces = append(interfaces, art.renderer.Contiv.GetOverlayTunnelIfNames()...)
 	return interfaces
 }
 
 // renderACL renders ContivRuleTable into the equivalent ACL configuration.
 func (art *RendererTxn) renderACL(table *cache.ContivRuleTable) *vpp_acl.AccessLists_Acl {
 	const (
-		maxPortNum  = ^uint16(0)
-		maxICMPCode = 5
-		maxICMPType = 16
+		maxPortNum   = ^uint16(0)
+		maxICMPValue = ^uint8(0)
 	)
 
 	acl := &vpp_acl.AccessLists_Acl{}
@@ -321,7 +416,13 @@ func (art *RendererTxn) renderACL(table *cache.ContivRuleTable) *vpp_acl.AccessL
 	acl.Interfaces = art.renderInterfaces(table.Pods, table.ID == ReflectiveACLName)
 	for i := 0; i < table.NumOfRules; i++ {
 		rule := table.Rules[i]
+		if utils.HaveDifferentIPVersions(rule.SrcNetwork, rule.DestNetwork) {
+			// Rule between IPv4 and IPv6 network cannot match any traffic.
+			art.Log.WithField("rule", rule).Debug("Skipping rule with mixed IP versions")
+			continue
+		}
 		aclRule := &vpp_acl.AccessLists_Acl_Rule{}
+		aclRule.RuleName = RuleID(rule)
 		aclRule.Actions = &vpp_acl.AccessLists_Acl_Rule_Actions{}
 		if rule.Action == renderer.ActionDeny {
 			aclRule.Actions.AclAction = vpp_acl.AclAction_DENY
@@ -339,7 +440,8 @@ func (art *RendererTxn) renderACL(table *cache.ContivRuleTable) *vpp_acl.AccessL
 		if len(rule.DestNetwork.IP) > 0 {
 			aclRule.Matches.IpRule.Ip.DestinationNetwork = rule.DestNetwork.String()
 		}
-		if rule.Protocol == renderer.TCP {
+		switch rule.Protocol {
+		case renderer.TCP:
 			aclRule.Matches.IpRule.Tcp = &vpp_acl.AccessLists_Acl_Rule_Matches_IpRule_Tcp{}
 			aclRule.Matches.IpRule.Tcp.SourcePortRange = &vpp_acl.AccessLists_Acl_Rule_Matches_IpRule_Tcp_SourcePortRange{}
 			aclRule.Matches.IpRule.Tcp.SourcePortRange.LowerPort = uint32(rule.SrcPort)
@@ -352,10 +454,12 @@ func (art *RendererTxn) renderACL(table *cache.ContivRuleTable) *vpp_acl.AccessL
 			aclRule.Matches.IpRule.Tcp.DestinationPortRange.LowerPort = uint32(rule.DestPort)
 			if rule.DestPort == 0 {
 				aclRule.Matches.IpRule.Tcp.DestinationPortRange.UpperPort = uint32(maxPortNum)
+			} else if rule.HasDestPortRange() {
+				aclRule.Matches.IpRule.Tcp.DestinationPortRange.UpperPort = uint32(rule.DestPortEnd)
 			} else {
 				aclRule.Matches.IpRule.Tcp.DestinationPortRange.UpperPort = uint32(rule.DestPort)
 			}
-		} else {
+		case renderer.UDP:
 			aclRule.Matches.IpRule.Udp = &vpp_acl.AccessLists_Acl_Rule_Matches_IpRule_Udp{}
 			aclRule.Matches.IpRule.Udp.SourcePortRange = &vpp_acl.AccessLists_Acl_Rule_Matches_IpRule_Udp_SourcePortRange{}
 			aclRule.Matches.IpRule.Udp.SourcePortRange.LowerPort = uint32(rule.SrcPort)
@@ -368,39 +472,62 @@ func (art *RendererTxn) renderACL(table *cache.ContivRuleTable) *vpp_acl.AccessL
 			aclRule.Matches.IpRule.Udp.DestinationPortRange.LowerPort = uint32(rule.DestPort)
 			if rule.DestPort == 0 {
 				aclRule.Matches.IpRule.Udp.DestinationPortRange.UpperPort = uint32(maxPortNum)
+			} else if rule.HasDestPortRange() {
+				aclRule.Matches.IpRule.Udp.DestinationPortRange.UpperPort = uint32(rule.DestPortEnd)
 			} else {
 				aclRule.Matches.IpRule.Udp.DestinationPortRange.UpperPort = uint32(rule.DestPort)
 			}
+		case renderer.ICMP:
+			aclRule.Matches.IpRule.Icmp = &vpp_acl.AccessLists_Acl_Rule_Matches_IpRule_Icmp{}
+			aclRule.Matches.IpRule.Icmp.IcmpTypeRange = &vpp_acl.AccessLists_Acl_Rule_Matches_IpRule_Icmp_IcmpTypeRange{}
+			aclRule.Matches.IpRule.Icmp.IcmpTypeRange.First = 0
+			aclRule.Matches.IpRule.Icmp.IcmpTypeRange.Last = uint32(maxICMPValue)
+			aclRule.Matches.IpRule.Icmp.IcmpCodeRange = &vpp_acl.AccessLists_Acl_Rule_Matches_IpRule_Icmp_IcmpCodeRange{}
+			aclRule.Matches.IpRule.Icmp.IcmpCodeRange.First = 0
+			aclRule.Matches.IpRule.Icmp.IcmpCodeRange.Last = uint32(maxICMPValue)
+			aclRule.Matches.IpRule.Icmp.Icmpv6 = utils.IsIPv6Net(rule.SrcNetwork) || utils.IsIPv6Net(rule.DestNetwork)
+			if rule.ICMP != nil {
+				aclRule.Matches.IpRule.Icmp.IcmpTypeRange.First = uint32(rule.ICMP.Type)
+				aclRule.Matches.IpRule.Icmp.IcmpTypeRange.Last = uint32(rule.ICMP.Type)
+				if !rule.ICMP.AnyCode {
+					aclRule.Matches.IpRule.Icmp.IcmpCodeRange.First = uint32(rule.ICMP.Code)
+					aclRule.Matches.IpRule.Icmp.IcmpCodeRange.Last = uint32(rule.ICMP.Code)
+				}
+			}
+		case renderer.SCTP:
+			if !isRenderable(rule) {
+				// ACL plugin cannot match SCTP ports, rather leave the traffic
+				// to the subsequent (deny) rules than allow more than requested
+				// (the policy is reported by the processor).
+				art.Log.WithField("rule", rule).Error("Skipping SCTP rule with port - not supported by ACLs")
+				continue
+			}
+			aclRule.Matches.IpRule.Other = &vpp_acl.AccessLists_Acl_Rule_Matches_IpRule_Other{}
+			aclRule.Matches.IpRule.Other.Protocol = sctpProtocolNumber
 		}
 		acl.Rules = append(acl.Rules, aclRule)
-	}
-
-	// Allow all ICMP traffic
-	if table.NumOfRules > 0 {
-		aclRule := &vpp_acl.AccessLists_Acl_Rule{}
-		aclRule.Actions = &vpp_acl.AccessLists_Acl_Rule_Actions{}
-		if table.ID == ReflectiveACLName {
-			aclRule.Actions.AclAction = vpp_acl.AclAction_REFLECT
-		} else {
-			aclRule.Actions.AclAction = vpp_acl.AclAction_PERMIT
+		if len(rule.SrcNetwork.IP) == 0 && len(rule.DestNetwork.IP) == 0 {
+			// Add IPv6 counterpart of the rule matching all IP addresses.
+			aclRule6 := proto.Clone(aclRule).(*vpp_acl.AccessLists_Acl_Rule)
+			aclRule6.Matches.IpRule.Ip.SourceNetwork = ipv6AnyNetwork
+			aclRule6.Matches.IpRule.Ip.DestinationNetwork = ipv6AnyNetwork
+			if aclRule6.Matches.IpRule.Icmp != nil {
+				aclRule6.Matches.IpRule.Icmp.Icmpv6 = true
+			}
+			acl.Rules = append(acl.Rules, aclRule6)
 		}
-		aclRule.Matches = &vpp_acl.AccessLists_Acl_Rule_Matches{}
-		aclRule.Matches.IpRule = &vpp_acl.AccessLists_Acl_Rule_Matches_IpRule{}
-		aclRule.Matches.IpRule.Ip = &vpp_acl.AccessLists_Acl_Rule_Matches_IpRule_Ip{}
-		aclRule.Matches.IpRule.Icmp = &vpp_acl.AccessLists_Acl_Rule_Matches_IpRule_Icmp{}
-		aclRule.Matches.IpRule.Icmp.IcmpTypeRange = &vpp_acl.AccessLists_Acl_Rule_Matches_IpRule_Icmp_IcmpTypeRange{}
-		aclRule.Matches.IpRule.Icmp.IcmpTypeRange.First = 0
-		aclRule.Matches.IpRule.Icmp.IcmpTypeRange.Last = maxICMPType
-		aclRule.Matches.IpRule.Icmp.IcmpCodeRange = &vpp_acl.AccessLists_Acl_Rule_Matches_IpRule_Icmp_IcmpCodeRange{}
-		aclRule.Matches.IpRule.Icmp.IcmpCodeRange.First = 0
-		aclRule.Matches.IpRule.Icmp.IcmpCodeRange.Last = maxICMPCode
-		acl.Rules = append(acl.Rules, aclRule)
 	}
 
 	table.Private = acl
 	return acl
 }
 
+// isRenderable returns false for rules that cannot be expressed with ACLs
+// and are therefore skipped by renderACL.
+func isRenderable(rule *renderer.ContivRule) bool {
+	return rule.Protocol != renderer.SCTP || (rule.SrcPort == 0 && rule.DestPort == 0)
+}
+
 // renderInterfaces renders a set of Interface names into the corresponding
 // instance of AccessLists_Acl_Interfaces.
 func (art *RendererTxn) renderInterfaces(pods cache.PodSet, ingress bool) *vpp_acl.AccessLists_Acl_Interfaces {
@@ -428,7 +555,10 @@ func (art *RendererTxn) renderInterfaces(pods cache.PodSet, ingress bool) *vpp_a
 // dumpVppACLConfig dumps current ACL config in the format suitable for the resync
 // of the cache.
 func (art *RendererTxn) dumpVppACLConfig() (tables []*cache.ContivRuleTable, hasReflectiveACL bool, err error) {
-	const maxPortNum = uint32(^uint16(0))
+	const (
+		maxPortNum   = uint32(^uint16(0))
+		maxICMPValue = uint32(^uint8(0))
+	)
 	tables = []*cache.ContivRuleTable{}
 
 	aclDump, err := art.vpp.DumpACL()
@@ -510,6 +640,11 @@ func (art *RendererTxn) dumpVppACLConfig() (tables []*cache.ContivRuleTable, has
 			rule.SrcNetwork = &net.IPNet{}
 			rule.DestNetwork = &net.IPNet{}
 			if aclRule.Matches.IpRule.Ip != nil {
+				if aclRule.Matches.IpRule.Ip.SourceNetwork == ipv6AnyNetwork &&
+					aclRule.Matches.IpRule.Ip.DestinationNetwork == ipv6AnyNetwork {
+					// IPv6 counterpart of the preceding rule matching all IP addresses.
+					continue
+				}
 				if aclRule.Matches.IpRule.Ip.SourceNetwork != "" {
 					_, rule.SrcNetwork, err = net.ParseCIDR(aclRule.Matches.IpRule.Ip.SourceNetwork)
 					if err != nil {
@@ -527,13 +662,33 @@ func (art *RendererTxn) dumpVppACLConfig() (tables []*cache.ContivRuleTable, has
 			}
 			// L4
 			if aclRule.Matches.IpRule.Other != nil {
-				// unhandled, skip
-				art.Log.WithField("rule", aclRule).Warn("Skipping Other ACL rule")
-				continue
-			}
-			if aclRule.Matches.IpRule.Icmp != nil {
-				// skip ICMP rule
-				continue
+				if aclRule.Matches.IpRule.Other.Protocol != sctpProtocolNumber {
+					// unhandled, skip
+					art.Log.WithField("rule", aclRule).Warn("Skipping Other ACL rule")
+					continue
+				}
+				rule.Protocol = renderer.SCTP
+			} else if aclRule.Matches.IpRule.Icmp != nil {
+				rule.Protocol = renderer.ICMP
+				typeRange := aclRule.Matches.IpRule.Icmp.IcmpTypeRange
+				codeRange := aclRule.Matches.IpRule.Icmp.IcmpCodeRange
+				anyType := typeRange == nil || (typeRange.First == 0 && typeRange.Last == maxICMPValue)
+				anyCode := codeRange == nil || (codeRange.First == 0 && codeRange.Last == maxICMPValue)
+				if !anyType {
+					if typeRange.First != typeRange.Last || (!anyCode && codeRange.First != codeRange.Last) {
+						// unhandled, skip
+						art.Log.WithField("rule", aclRule).Warn("Skipping ACL rule with ICMP type/code range")
+						continue
+					}
+					rule.ICMP = &renderer.ICMPMatch{Type: uint8(typeRange.First), AnyCode: anyCode}
+					if !anyCode {
+						rule.ICMP.Code = uint8(codeRange.First)
+					}
+				} else if !anyCode {
+					// unhandled, skip
+					art.Log.WithField("rule", aclRule).Warn("Skipping ACL rule with ICMP code but any type")
+					continue
+				}
 			} else if aclRule.Matches.IpRule.Tcp != nil {
 				rule.Protocol = renderer.TCP
 				if aclRule.Matches.IpRule.Tcp.SourcePortRange != nil {
@@ -548,15 +703,17 @@ func (art *RendererTxn) dumpVppACLConfig() (tables []*cache.ContivRuleTable, has
 					rule.SrcPort = uint16(aclRule.Matches.IpRule.Tcp.SourcePortRange.LowerPort)
 				}
 				if aclRule.Matches.IpRule.Tcp.DestinationPortRange != nil {
-					if aclRule.Matches.IpRule.Tcp.DestinationPortRange.LowerPort != aclRule.Matches.IpRule.Tcp.DestinationPortRange.UpperPort {
-						if aclRule.Matches.IpRule.Tcp.DestinationPortRange.LowerPort != 0 ||
-							aclRule.Matches.IpRule.Tcp.DestinationPortRange.UpperPort != maxPortNum {
-							// unhandled, skip
-							art.Log.WithField("rule", aclRule).Warn("Skipping ACL rule with TCP port range")
-							continue
-						}
+					if aclRule.Matches.IpRule.Tcp.DestinationPortRange.LowerPort == 0 &&
+						aclRule.Matches.IpRule.Tcp.DestinationPortRange.UpperPort != maxPortNum {
+						// unhandled, skip
+						art.Log.WithField("rule", aclRule).Warn("Skipping ACL rule with TCP port range")
+						continue
 					}
 					rule.DestPort = uint16(aclRule.Matches.IpRule.Tcp.DestinationPortRange.LowerPort)
+					if rule.DestPort != 0 &&
+						aclRule.Matches.IpRule.Tcp.DestinationPortRange.UpperPort > aclRule.Matches.IpRule.Tcp.DestinationPortRange.LowerPort {
+						rule.DestPortEnd = uint16(aclRule.Matches.IpRule.Tcp.DestinationPortRange.UpperPort)
+					}
 				}
 			} else if aclRule.Matches.IpRule.Udp != nil {
 				rule.Protocol = renderer.UDP
@@ -572,15 +729,17 @@ func (art *RendererTxn) dumpVppACLConfig() (tables []*cache.ContivRuleTable, has
 					rule.SrcPort = uint16(aclRule.Matches.IpRule.Udp.SourcePortRange.LowerPort)
 				}
 				if aclRule.Matches.IpRule.Udp.DestinationPortRange != nil {
-					if aclRule.Matches.IpRule.Udp.DestinationPortRange.LowerPort != aclRule.Matches.IpRule.Udp.DestinationPortRange.UpperPort {
-						if aclRule.Matches.IpRule.Udp.DestinationPortRange.LowerPort != 0 ||
-							aclRule.Matches.IpRule.Udp.DestinationPortRange.UpperPort != maxPortNum {
-							// unhandled, skip
-							art.Log.WithField("rule", aclRule).Warn("Skipping ACL rule with UDP port range")
-							continue
-						}
+					if aclRule.Matches.IpRule.Udp.DestinationPortRange.LowerPort == 0 &&
+						aclRule.Matches.IpRule.Udp.DestinationPortRange.UpperPort != maxPortNum {
+						// unhandled, skip
+						art.Log.WithField("rule", aclRule).Warn("Skipping ACL rule with UDP port range")
+						continue
 					}
 					rule.DestPort = uint16(aclRule.Matches.IpRule.Udp.DestinationPortRange.LowerPort)
+					if rule.DestPort != 0 &&
+						aclRule.Matches.IpRule.Udp.DestinationPortRange.UpperPort > aclRule.Matches.IpRule.Udp.DestinationPortRange.LowerPort {
+						rule.DestPortEnd = uint16(aclRule.Matches.IpRule.Udp.DestinationPortRange.UpperPort)
+					}
 				}
 			}
 			// Add rule to the list.
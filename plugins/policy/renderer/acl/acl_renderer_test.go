@@ -18,8 +18,11 @@ package acl
 
 import (
 	"github.com/onsi/gomega"
+	"net"
 	"testing"
 
+	"github.com/golang/protobuf/proto"
+
 	"github.com/ligato/cn-infra/logging"
 	"github.com/ligato/cn-infra/logging/logrus"
 	vpp_acl "github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/acl"
@@ -29,6 +32,7 @@ import (
 	. "github.com/contiv/vpp/mock/defaultplugins"
 	"github.com/contiv/vpp/mock/localclient"
 	"github.com/contiv/vpp/plugins/contiv"
+	policymodel "github.com/contiv/vpp/plugins/ksr/model/policy"
 	"github.com/contiv/vpp/plugins/policy/renderer"
 	"github.com/contiv/vpp/plugins/policy/renderer/cache"
 	. "github.com/contiv/vpp/plugins/policy/renderer/testdata"
@@ -40,21 +44,17 @@ const (
 	vxlanIfName     = "VXLAN-BVI"
 	hostInterIfName = "VPP-Host"
 
-	maxPortNum  = uint32(^uint16(0))
-	maxICMPCode = 5
-	maxICMPType = 16
-	googleDNS   = "8.8.8.8" /* just random IP from the Internet */
-	somePort    = 500       /* some port number to use as the source port */
-	somePort2   = 600       /* some port number to use as the source port */
+	maxPortNum   = uint32(^uint16(0))
+	maxICMPValue = uint32(^uint8(0))
+	googleDNS    = "8.8.8.8" /* just random IP from the Internet */
+	somePort     = 500       /* some port number to use as the source port */
+	somePort2    = 600       /* some port number to use as the source port */
 )
 
 func verifyReflectiveACL(engine *MockACLEngine, contiv contiv.API, ifName string, onOutputIfs bool, expectedToHave bool) {
 	ifs := []string{}
 	if onOutputIfs {
-		ifs = contiv.GetOtherPhysicalIfNames()
-		ifs = append(ifs, contiv.GetVxlanBVIIfName())
-		ifs = append(ifs, contiv.GetMainPhysicalIfName())
-		ifs = append(ifs, contiv.GetHostInterconnectIfName())
+		ifs = nodeOutputIfNames(contiv)
 	}
 	ifs = append(ifs, ifName)
 
@@ -65,10 +65,25 @@ func verifyReflectiveACL(engine *MockACLEngine, contiv contiv.API, ifName string
 	}
 	gomega.Expect(acl).ToNot(gomega.BeNil())
 	gomega.Expect(acl.AclName).To(gomega.BeEquivalentTo(ACLNamePrefix + ReflectiveACLName))
-	gomega.Expect(acl.Rules).To(gomega.HaveLen(3))
+	gomega.Expect(acl.Rules).To(gomega.HaveLen(8))
 	rule1 := acl.Rules[0]
-	rule2 := acl.Rules[1]
-	rule3 := acl.Rules[2]
+	rule2 := acl.Rules[2]
+	rule3 := acl.Rules[4]
+	rule4 := acl.Rules[6]
+
+	// Every rule is followed by its IPv6 counterpart.
+	for i := 0; i < len(acl.Rules); i += 2 {
+		rule6 := proto.Clone(acl.Rules[i+1]).(*vpp_acl.AccessLists_Acl_Rule)
+		gomega.Expect(rule6.Matches.IpRule.Ip.SourceNetwork).To(gomega.BeEquivalentTo("::/0"))
+		gomega.Expect(rule6.Matches.IpRule.Ip.DestinationNetwork).To(gomega.BeEquivalentTo("::/0"))
+		rule6.Matches.IpRule.Ip.SourceNetwork = ""
+		rule6.Matches.IpRule.Ip.DestinationNetwork = ""
+		if rule6.Matches.IpRule.Icmp != nil {
+			gomega.Expect(rule6.Matches.IpRule.Icmp.Icmpv6).To(gomega.BeTrue())
+			rule6.Matches.IpRule.Icmp.Icmpv6 = false
+		}
+		gomega.Expect(proto.Equal(rule6, acl.Rules[i])).To(gomega.BeTrue())
+	}
 	gomega.Expect(acl.Interfaces).ToNot(gomega.BeNil())
 	for _, ifName := range ifs {
 		gomega.Expect(acl.Interfaces.Ingress).To(gomega.ContainElement(ifName))
@@ -137,16 +152,40 @@ func verifyReflectiveACL(engine *MockACLEngine, contiv contiv.API, ifName string
 	gomega.Expect(ipRule.Icmp.IcmpTypeRange).ToNot(gomega.BeNil())
 	gomega.Expect(ipRule.Icmp.Icmpv6).To(gomega.BeFalse())
 	gomega.Expect(ipRule.Icmp.IcmpCodeRange.First).To(gomega.BeEquivalentTo(0))
-	gomega.Expect(ipRule.Icmp.IcmpCodeRange.Last).To(gomega.BeEquivalentTo(maxICMPCode))
+	gomega.Expect(ipRule.Icmp.IcmpCodeRange.Last).To(gomega.BeEquivalentTo(maxICMPValue))
 	gomega.Expect(ipRule.Icmp.IcmpTypeRange.First).To(gomega.BeEquivalentTo(0))
-	gomega.Expect(ipRule.Icmp.IcmpTypeRange.Last).To(gomega.BeEquivalentTo(maxICMPType))
+	gomega.Expect(ipRule.Icmp.IcmpTypeRange.Last).To(gomega.BeEquivalentTo(maxICMPValue))
+
+	// SCTP any
+	gomega.Expect(rule4.Actions).ToNot(gomega.BeNil())
+	gomega.Expect(rule4.Actions.AclAction).To(gomega.BeEquivalentTo(vpp_acl.AclAction_REFLECT))
+	gomega.Expect(rule4.Matches).ToNot(gomega.BeNil())
+	gomega.Expect(rule4.Matches.MacipRule).To(gomega.BeNil())
+	gomega.Expect(rule4.Matches.IpRule).ToNot(gomega.BeNil())
+	ipRule = rule4.Matches.IpRule
+	gomega.Expect(ipRule.Tcp).To(gomega.BeNil())
+	gomega.Expect(ipRule.Udp).To(gomega.BeNil())
+	gomega.Expect(ipRule.Icmp).To(gomega.BeNil())
+	gomega.Expect(ipRule.Ip).ToNot(gomega.BeNil())
+	gomega.Expect(ipRule.Other).ToNot(gomega.BeNil())
+	gomega.Expect(ipRule.Ip.SourceNetwork).To(gomega.BeEmpty())
+	gomega.Expect(ipRule.Ip.DestinationNetwork).To(gomega.BeEmpty())
+	gomega.Expect(ipRule.Other.Protocol).To(gomega.BeEquivalentTo(sctpProtocolNumber))
 }
 
-func verifyGlobalTable(engine *MockACLEngine, contiv contiv.API, expectedToHave bool) {
-	ifs := contiv.GetOtherPhysicalIfNames()
-	ifs = append(ifs, contiv.GetVxlanBVIIfName())
+func nodeOutputIfNames(contiv contiv.API) []string {
+	ifs := append([]string{}, contiv.GetOtherPhysicalIfNames()...)
+	if contiv.GetVxlanBVIIfName() != "" {
+		ifs = append(ifs, contiv.GetVxlanBVIIfName())
+	}
+	ifs = append(ifs, contiv.GetOverlayTunnelIfNames()...)
 	ifs = append(ifs, contiv.GetMainPhysicalIfName())
 	ifs = append(ifs, contiv.GetHostInterconnectIfName())
+	return ifs
+}
+
+func verifyGlobalTable(engine *MockACLEngine, contiv contiv.API, expectedToHave bool) {
+	ifs := nodeOutputIfNames(contiv)
 
 	acl := engine.GetACLByName(ACLNamePrefix + cache.GlobalTableID)
 	if !expectedToHave {
@@ -578,6 +617,78 @@ func TestCombinedRules(t *testing.T) {
 
 }
 
+func TestOverlayTunnels(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestOverlayTunnels")
+
+	// Prepare test data
+	pod1Cfg := &cache.PodConfig{
+		PodIP:   GetOneHostSubnet(Pod1IP),
+		Ingress: Ts5.Pod1Ingress,
+		Egress:  Ts5.Pod1Egress,
+	}
+
+	// Prepare mocks.
+	//  -> Contiv plugin with the IP-in-IP overlay (no VXLAN BVI)
+	contiv := NewMockContiv()
+	contiv.SetMainPhysicalIfName(mainIfName)
+	contiv.SetHostInterconnectIfName(hostInterIfName)
+	contiv.SetOverlayTunnelIfNames("ipip2")
+	contiv.SetPodIfName(Pod1, Pod1IfName)
+
+	// -> ACL engine
+	aclEngine := NewMockACLEngine(logger, contiv)
+	aclEngine.RegisterPod(Pod1, Pod1IP, false)
+
+	// -> localclient
+	txnTracker := localclient.NewTxnTracker(aclEngine.ApplyTxn)
+
+	// -> default VPP plugins
+	vppPlugins := NewMockVppPlugin()
+
+	// Prepare ACL Renderer.
+	aclRenderer := &Renderer{
+		Deps: Deps{
+			Log:           logger,
+			Contiv:        contiv,
+			VPP:           vppPlugins,
+			ACLTxnFactory: txnTracker.NewLinuxDataChangeTxn,
+		},
+	}
+	aclRenderer.Init()
+
+	// Without the global table the ACLs are not applied on the tunnels.
+	gomega.Expect(aclRenderer.UpdateNodeOutputInterfaces()).To(gomega.Succeed())
+	gomega.Expect(txnTracker.CommittedTxns).To(gomega.HaveLen(0))
+
+	// Execute Renderer transaction.
+	txn := aclRenderer.NewTxn(true)
+	txn.Render(Pod1, pod1Cfg.PodIP, pod1Cfg.Ingress, pod1Cfg.Egress, false)
+	err := txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(txnTracker.CommittedTxns).To(gomega.HaveLen(1))
+
+	// The global table and the reflective ACL are applied on the tunnel.
+	verifyReflectiveACL(aclEngine, contiv, Pod1IfName, true, true)
+	verifyGlobalTable(aclEngine, contiv, true)
+
+	// Another node joins the cluster.
+	contiv.SetOverlayTunnelIfNames("ipip2", "ipip3")
+	gomega.Expect(aclRenderer.UpdateNodeOutputInterfaces()).To(gomega.Succeed())
+	gomega.Expect(txnTracker.CommittedTxns).To(gomega.HaveLen(2))
+	verifyReflectiveACL(aclEngine, contiv, Pod1IfName, true, true)
+	verifyGlobalTable(aclEngine, contiv, true)
+
+	// The first node leaves the cluster.
+	contiv.SetOverlayTunnelIfNames("ipip3")
+	gomega.Expect(aclRenderer.UpdateNodeOutputInterfaces()).To(gomega.Succeed())
+	verifyGlobalTable(aclEngine, contiv, true)
+	gomega.Expect(aclEngine.GetACLByName(ACLNamePrefix + cache.GlobalTableID).Interfaces.Egress).
+		ToNot(gomega.ContainElement("ipip2"))
+}
+
 func TestCombinedRulesWithResync(t *testing.T) {
 	gomega.RegisterTestingT(t)
 	logger := logrus.DefaultLogger()
@@ -959,3 +1070,474 @@ func TestCombinedRulesWithRemovedPods(t *testing.T) {
 	verifyReflectiveACL(aclEngine, contiv, "", false, false)
 	verifyGlobalTable(aclEngine, contiv, false)
 }
+
+func TestICMPAndSCTPRules(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestICMPAndSCTPRules")
+
+	// Prepare input data
+	echoRequest := &renderer.ContivRule{
+		Action:      renderer.ActionPermit,
+		SrcNetwork:  IpNetwork("10.10.0.0/16"),
+		DestNetwork: IpNetwork(""),
+		Protocol:    renderer.ICMP,
+		ICMP:        &renderer.ICMPMatch{Type: 8, AnyCode: true},
+	}
+	sctpAny := &renderer.ContivRule{
+		Action:      renderer.ActionPermit,
+		SrcNetwork:  IpNetwork("10.10.0.0/16"),
+		DestNetwork: IpNetwork(""),
+		Protocol:    renderer.SCTP,
+	}
+	sctpPort := &renderer.ContivRule{
+		Action:      renderer.ActionPermit,
+		SrcNetwork:  IpNetwork("192.168.0.0/16"),
+		DestNetwork: IpNetwork(""),
+		Protocol:    renderer.SCTP,
+		DestPort:    9999, /* not supported by ACLs */
+	}
+	ingress := []*renderer.ContivRule{}
+	egress := []*renderer.ContivRule{echoRequest, sctpAny, sctpPort, DenyAllTCP(), DenyAllUDP(), DenyAllICMP(), DenyAllSCTP()}
+
+	// Prepare mocks.
+	//  -> Contiv plugin
+	contiv := NewMockContiv()
+	contiv.SetMainPhysicalIfName(mainIfName)
+	contiv.SetVxlanBVIIfName(vxlanIfName)
+	contiv.SetHostInterconnectIfName(hostInterIfName)
+	contiv.SetPodIfName(Pod1, Pod1IfName)
+
+	// -> ACL engine
+	aclEngine := NewMockACLEngine(logger, contiv)
+	aclEngine.RegisterPod(Pod1, Pod1IP, false)
+
+	// -> localclient
+	txnTracker := localclient.NewTxnTracker(aclEngine.ApplyTxn)
+
+	// -> default VPP plugins
+	vppPlugins := NewMockVppPlugin()
+
+	// Prepare ACL Renderer.
+	aclRenderer := &Renderer{
+		Deps: Deps{
+			Log:           logger,
+			Contiv:        contiv,
+			VPP:           vppPlugins,
+			ACLTxnFactory: txnTracker.NewLinuxDataChangeTxn,
+		},
+	}
+	aclRenderer.Init()
+
+	// Execute Renderer transaction.
+	err := aclRenderer.NewTxn(true).Render(Pod1, GetOneHostSubnet(Pod1IP), ingress, egress, false).Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(txnTracker.CommittedTxns).To(gomega.HaveLen(1))
+
+	// Test ACLs.
+	gomega.Expect(aclEngine.GetNumOfACLs()).To(gomega.Equal(2))
+	verifyReflectiveACL(aclEngine, contiv, Pod1IfName, false, true)
+	verifyGlobalTable(aclEngine, contiv, false)
+
+	// Test connections (Pod1 can receive only ping and SCTP from 10.10.0.0/16).
+	gomega.Expect(aclEngine.ConnectionInternetToPod("10.10.50.1", Pod1, ICMP, 8, 0)).To(gomega.Equal(ConnActionAllow))
+	gomega.Expect(aclEngine.ConnectionInternetToPod("10.10.50.1", Pod1, ICMP, 13, 0)).To(gomega.Equal(ConnActionDenySyn))
+	gomega.Expect(aclEngine.ConnectionInternetToPod("10.10.50.1", Pod1, SCTP, somePort, 9999)).To(gomega.Equal(ConnActionAllow))
+	gomega.Expect(aclEngine.ConnectionInternetToPod("10.10.50.1", Pod1, TCP, somePort, 80)).To(gomega.Equal(ConnActionDenySyn))
+	gomega.Expect(aclEngine.ConnectionInternetToPod(googleDNS, Pod1, ICMP, 8, 0)).To(gomega.Equal(ConnActionDenySyn))
+	gomega.Expect(aclEngine.ConnectionInternetToPod(googleDNS, Pod1, SCTP, somePort, 9999)).To(gomega.Equal(ConnActionDenySyn))
+	gomega.Expect(aclEngine.ConnectionInternetToPod("192.168.1.1", Pod1, SCTP, somePort, 9999)).To(gomega.Equal(ConnActionDenySyn))
+	gomega.Expect(aclEngine.ConnectionPodToInternet(Pod1, googleDNS, ICMP, 8, 0)).To(gomega.Equal(ConnActionAllow))
+	gomega.Expect(aclEngine.ConnectionPodToInternet(Pod1, googleDNS, SCTP, somePort, 9999)).To(gomega.Equal(ConnActionAllow))
+
+	// Evaluate the same connections against the rendered rules (the SCTP rule with port is not rendered).
+	rule, applied := aclRenderer.EvaluateTraffic(net.ParseIP("10.10.50.1"), net.ParseIP(Pod1IP), renderer.SCTP, somePort, 9999)
+	gomega.Expect(applied).To(gomega.BeTrue())
+	gomega.Expect(rule).To(gomega.Equal(sctpAny))
+	rule, applied = aclRenderer.EvaluateTraffic(net.ParseIP("192.168.1.1"), net.ParseIP(Pod1IP), renderer.SCTP, somePort, 9999)
+	gomega.Expect(applied).To(gomega.BeTrue())
+	gomega.Expect(rule).ToNot(gomega.BeNil())
+	gomega.Expect(rule.Action).To(gomega.Equal(renderer.ActionDeny))
+	rule, applied = aclRenderer.EvaluateTraffic(net.ParseIP(Pod1IP), net.ParseIP(googleDNS), renderer.SCTP, somePort, 9999)
+	gomega.Expect(applied).To(gomega.BeFalse())
+	gomega.Expect(rule).To(gomega.BeNil())
+
+	// Dump ACLs and put them to mock defaultplugins.
+	acls := aclEngine.DumpACLs()
+	vppPlugins.AddACL(acls...)
+
+	// Simulate restart of ACL Renderer.
+	txnTracker = localclient.NewTxnTracker(aclEngine.ApplyTxn)
+	aclRenderer = &Renderer{
+		Deps: Deps{
+			Log:           logger,
+			Contiv:        contiv,
+			VPP:           vppPlugins,
+			ACLTxnFactory: txnTracker.NewLinuxDataChangeTxn,
+		},
+	}
+	aclRenderer.Init()
+
+	// Execute RESYNC transaction without the SCTP rule that could not be rendered.
+	egress = []*renderer.ContivRule{echoRequest, sctpAny, DenyAllTCP(), DenyAllUDP(), DenyAllICMP(), DenyAllSCTP()}
+	err = aclRenderer.NewTxn(true).Render(Pod1, GetOneHostSubnet(Pod1IP), ingress, egress, false).Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(txnTracker.PendingTxns).To(gomega.HaveLen(0))
+	gomega.Expect(txnTracker.CommittedTxns).To(gomega.HaveLen(1))
+
+	// Verify that ACL with ICMP and SCTP rules was correctly dumped and re-used
+	// (only the reflective ACL is re-applied).
+	gomega.Expect(txnTracker.CommittedTxns[0].LinuxDataChangeTxn.Ops).To(gomega.HaveLen(1))
+	gomega.Expect(aclEngine.GetNumOfACLs()).To(gomega.Equal(2))
+	gomega.Expect(aclEngine.ConnectionInternetToPod("10.10.50.1", Pod1, ICMP, 8, 0)).To(gomega.Equal(ConnActionAllow))
+	gomega.Expect(aclEngine.ConnectionInternetToPod("10.10.50.1", Pod1, ICMP, 13, 0)).To(gomega.Equal(ConnActionDenySyn))
+}
+
+func TestPortRangeRules(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestPortRangeRules")
+
+	// Prepare input data
+	nodePorts := &renderer.ContivRule{
+		Action:      renderer.ActionPermit,
+		SrcNetwork:  IpNetwork("10.10.0.0/16"),
+		DestNetwork: IpNetwork(""),
+		Protocol:    renderer.TCP,
+		DestPort:    30000,
+		DestPortEnd: 32767,
+	}
+	udpRange := &renderer.ContivRule{
+		Action:      renderer.ActionPermit,
+		SrcNetwork:  IpNetwork(""),
+		DestNetwork: IpNetwork(""),
+		Protocol:    renderer.UDP,
+		DestPort:    5000,
+		DestPortEnd: 5010,
+	}
+	ingress := []*renderer.ContivRule{}
+	egress := []*renderer.ContivRule{nodePorts, udpRange, DenyAllTCP(), DenyAllUDP(), DenyAllICMP(), DenyAllSCTP()}
+
+	// Prepare mocks.
+	//  -> Contiv plugin
+	contiv := NewMockContiv()
+	contiv.SetMainPhysicalIfName(mainIfName)
+	contiv.SetVxlanBVIIfName(vxlanIfName)
+	contiv.SetHostInterconnectIfName(hostInterIfName)
+	contiv.SetPodIfName(Pod1, Pod1IfName)
+
+	// -> ACL engine
+	aclEngine := NewMockACLEngine(logger, contiv)
+	aclEngine.RegisterPod(Pod1, Pod1IP, false)
+
+	// -> localclient
+	txnTracker := localclient.NewTxnTracker(aclEngine.ApplyTxn)
+
+	// -> default VPP plugins
+	vppPlugins := NewMockVppPlugin()
+
+	// Prepare ACL Renderer.
+	aclRenderer := &Renderer{
+		Deps: Deps{
+			Log:           logger,
+			Contiv:        contiv,
+			VPP:           vppPlugins,
+			ACLTxnFactory: txnTracker.NewLinuxDataChangeTxn,
+		},
+	}
+	aclRenderer.Init()
+
+	// Execute Renderer transaction.
+	err := aclRenderer.NewTxn(true).Render(Pod1, GetOneHostSubnet(Pod1IP), ingress, egress, false).Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(txnTracker.CommittedTxns).To(gomega.HaveLen(1))
+
+	// Test ACLs.
+	gomega.Expect(aclEngine.GetNumOfACLs()).To(gomega.Equal(2))
+	verifyReflectiveACL(aclEngine, contiv, Pod1IfName, false, true)
+	verifyGlobalTable(aclEngine, contiv, false)
+
+	// Test connections.
+	gomega.Expect(aclEngine.ConnectionInternetToPod("10.10.50.1", Pod1, TCP, somePort, 30000)).To(gomega.Equal(ConnActionAllow))
+	gomega.Expect(aclEngine.ConnectionInternetToPod("10.10.50.1", Pod1, TCP, somePort, 31000)).To(gomega.Equal(ConnActionAllow))
+	gomega.Expect(aclEngine.ConnectionInternetToPod("10.10.50.1", Pod1, TCP, somePort, 32767)).To(gomega.Equal(ConnActionAllow))
+	gomega.Expect(aclEngine.ConnectionInternetToPod("10.10.50.1", Pod1, TCP, somePort, 29999)).To(gomega.Equal(ConnActionDenySyn))
+	gomega.Expect(aclEngine.ConnectionInternetToPod("10.10.50.1", Pod1, TCP, somePort, 32768)).To(gomega.Equal(ConnActionDenySyn))
+	gomega.Expect(aclEngine.ConnectionInternetToPod(googleDNS, Pod1, TCP, somePort, 31000)).To(gomega.Equal(ConnActionDenySyn))
+	gomega.Expect(aclEngine.ConnectionInternetToPod(googleDNS, Pod1, UDP, somePort, 5000)).To(gomega.Equal(ConnActionAllow))
+	gomega.Expect(aclEngine.ConnectionInternetToPod(googleDNS, Pod1, UDP, somePort, 5010)).To(gomega.Equal(ConnActionAllow))
+	gomega.Expect(aclEngine.ConnectionInternetToPod(googleDNS, Pod1, UDP, somePort, 5011)).To(gomega.Equal(ConnActionDenySyn))
+	gomega.Expect(aclEngine.ConnectionInternetToPod(googleDNS, Pod1, UDP, somePort, 31000)).To(gomega.Equal(ConnActionDenySyn))
+
+	// Dump ACLs and put them to mock defaultplugins.
+	acls := aclEngine.DumpACLs()
+	vppPlugins.AddACL(acls...)
+
+	// Simulate restart of ACL Renderer.
+	txnTracker = localclient.NewTxnTracker(aclEngine.ApplyTxn)
+	aclRenderer = &Renderer{
+		Deps: Deps{
+			Log:           logger,
+			Contiv:        contiv,
+			VPP:           vppPlugins,
+			ACLTxnFactory: txnTracker.NewLinuxDataChangeTxn,
+		},
+	}
+	aclRenderer.Init()
+
+	// Execute RESYNC transaction.
+	err = aclRenderer.NewTxn(true).Render(Pod1, GetOneHostSubnet(Pod1IP), ingress, egress, false).Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(txnTracker.PendingTxns).To(gomega.HaveLen(0))
+	gomega.Expect(txnTracker.CommittedTxns).To(gomega.HaveLen(1))
+
+	// Verify that ACL with port ranges was correctly dumped and re-used
+	// (only the reflective ACL is re-applied).
+	gomega.Expect(txnTracker.CommittedTxns[0].LinuxDataChangeTxn.Ops).To(gomega.HaveLen(1))
+	gomega.Expect(aclEngine.GetNumOfACLs()).To(gomega.Equal(2))
+	gomega.Expect(aclEngine.ConnectionInternetToPod("10.10.50.1", Pod1, TCP, somePort, 31000)).To(gomega.Equal(ConnActionAllow))
+	gomega.Expect(aclEngine.ConnectionInternetToPod("10.10.50.1", Pod1, TCP, somePort, 32768)).To(gomega.Equal(ConnActionDenySyn))
+}
+
+func TestIPv6Rules(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestIPv6Rules")
+
+	// Prepare input data
+	const (
+		pod1IPv6   = "2001:db8::1"
+		googleDNS6 = "2001:4860:4860::8888"
+	)
+	httpFromBlock := &renderer.ContivRule{
+		Action:      renderer.ActionPermit,
+		SrcNetwork:  IpNetwork("2001:db8:a::/48"),
+		DestNetwork: IpNetwork(""),
+		Protocol:    renderer.TCP,
+		DestPort:    80,
+	}
+	echoRequest := &renderer.ContivRule{
+		Action:      renderer.ActionPermit,
+		SrcNetwork:  IpNetwork(""),
+		DestNetwork: IpNetwork(""),
+		Protocol:    renderer.ICMP,
+		ICMP:        &renderer.ICMPMatch{Type: 128, AnyCode: true},
+	}
+	dnsFromIPv4Block := &renderer.ContivRule{
+		Action:      renderer.ActionPermit,
+		SrcNetwork:  IpNetwork("10.10.0.0/16"),
+		DestNetwork: IpNetwork(""),
+		Protocol:    renderer.UDP,
+		DestPort:    53,
+	}
+	ingress := []*renderer.ContivRule{}
+	egress := []*renderer.ContivRule{httpFromBlock, echoRequest, dnsFromIPv4Block, DenyAllTCP(), DenyAllUDP(), DenyAllICMP(), DenyAllSCTP()}
+
+	// Prepare mocks.
+	//  -> Contiv plugin
+	contiv := NewMockContiv()
+	contiv.SetMainPhysicalIfName(mainIfName)
+	contiv.SetVxlanBVIIfName(vxlanIfName)
+	contiv.SetHostInterconnectIfName(hostInterIfName)
+	contiv.SetPodIfName(Pod1, Pod1IfName)
+
+	// -> ACL engine
+	aclEngine := NewMockACLEngine(logger, contiv)
+	aclEngine.RegisterPod(Pod1, pod1IPv6, false)
+
+	// -> localclient
+	txnTracker := localclient.NewTxnTracker(aclEngine.ApplyTxn)
+
+	// -> default VPP plugins
+	vppPlugins := NewMockVppPlugin()
+
+	// Prepare ACL Renderer.
+	aclRenderer := &Renderer{
+		Deps: Deps{
+			Log:           logger,
+			Contiv:        contiv,
+			VPP:           vppPlugins,
+			ACLTxnFactory: txnTracker.NewLinuxDataChangeTxn,
+		},
+	}
+	aclRenderer.Init()
+
+	// Execute Renderer transaction.
+	err := aclRenderer.NewTxn(true).Render(Pod1, GetOneHostSubnet(pod1IPv6), ingress, egress, false).Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(txnTracker.CommittedTxns).To(gomega.HaveLen(1))
+
+	// Test ACLs.
+	gomega.Expect(aclEngine.GetNumOfACLs()).To(gomega.Equal(2))
+	verifyReflectiveACL(aclEngine, contiv, Pod1IfName, false, true)
+	verifyGlobalTable(aclEngine, contiv, false)
+
+	// Test connections.
+	gomega.Expect(aclEngine.ConnectionInternetToPod("2001:db8:a::5", Pod1, TCP, somePort, 80)).To(gomega.Equal(ConnActionAllow))
+	gomega.Expect(aclEngine.ConnectionInternetToPod("2001:db8:a::5", Pod1, TCP, somePort, 81)).To(gomega.Equal(ConnActionDenySyn))
+	gomega.Expect(aclEngine.ConnectionInternetToPod("2001:db8:b::5", Pod1, TCP, somePort, 80)).To(gomega.Equal(ConnActionDenySyn))
+	gomega.Expect(aclEngine.ConnectionInternetToPod("2001:db8:b::5", Pod1, UDP, somePort, 53)).To(gomega.Equal(ConnActionDenySyn))
+	gomega.Expect(aclEngine.ConnectionInternetToPod(googleDNS6, Pod1, ICMP, 128, 0)).To(gomega.Equal(ConnActionAllow))
+	gomega.Expect(aclEngine.ConnectionInternetToPod(googleDNS6, Pod1, ICMP, 8, 0)).To(gomega.Equal(ConnActionDenySyn))
+	gomega.Expect(aclEngine.ConnectionInternetToPod(googleDNS6, Pod1, SCTP, somePort, 9999)).To(gomega.Equal(ConnActionDenySyn))
+	gomega.Expect(aclEngine.ConnectionPodToInternet(Pod1, googleDNS6, TCP, somePort, 443)).To(gomega.Equal(ConnActionAllow))
+	gomega.Expect(aclEngine.ConnectionPodToInternet(Pod1, googleDNS6, ICMP, 128, 0)).To(gomega.Equal(ConnActionAllow))
+
+	// Dump ACLs and put them to mock defaultplugins.
+	acls := aclEngine.DumpACLs()
+	vppPlugins.AddACL(acls...)
+
+	// Simulate restart of ACL Renderer.
+	txnTracker = localclient.NewTxnTracker(aclEngine.ApplyTxn)
+	aclRenderer = &Renderer{
+		Deps: Deps{
+			Log:           logger,
+			Contiv:        contiv,
+			VPP:           vppPlugins,
+			ACLTxnFactory: txnTracker.NewLinuxDataChangeTxn,
+		},
+	}
+	aclRenderer.Init()
+
+	// Execute RESYNC transaction.
+	err = aclRenderer.NewTxn(true).Render(Pod1, GetOneHostSubnet(pod1IPv6), ingress, egress, false).Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(txnTracker.PendingTxns).To(gomega.HaveLen(0))
+	gomega.Expect(txnTracker.CommittedTxns).To(gomega.HaveLen(1))
+
+	// Verify that ACL with IPv6 rules was correctly dumped and re-used
+	// (only the reflective ACL is re-applied).
+	gomega.Expect(txnTracker.CommittedTxns[0].LinuxDataChangeTxn.Ops).To(gomega.HaveLen(1))
+	gomega.Expect(aclEngine.GetNumOfACLs()).To(gomega.Equal(2))
+	gomega.Expect(aclEngine.ConnectionInternetToPod("2001:db8:a::5", Pod1, TCP, somePort, 80)).To(gomega.Equal(ConnActionAllow))
+	gomega.Expect(aclEngine.ConnectionInternetToPod(googleDNS6, Pod1, ICMP, 128, 0)).To(gomega.Equal(ConnActionAllow))
+}
+
+func TestRuleIDs(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestRuleIDs")
+
+	// Prepare input data
+	policy1 := policymodel.ID{Name: "policy1", Namespace: "default"}
+	policy2 := policymodel.ID{Name: "policy2", Namespace: "default"}
+	allowHTTP := &renderer.ContivRule{
+		Action:      renderer.ActionPermit,
+		SrcNetwork:  IpNetwork("10.10.0.0/16"),
+		DestNetwork: IpNetwork(""),
+		Protocol:    renderer.TCP,
+		DestPort:    80,
+		Policies:    []policymodel.ID{policy1},
+	}
+	denyTCP := DenyAllTCP()
+	denyTCP.Policies = []policymodel.ID{policy1}
+	ingress := []*renderer.ContivRule{}
+	egress1 := []*renderer.ContivRule{allowHTTP, denyTCP}
+
+	// The same rules originating from a different policy.
+	allowHTTP2 := allowHTTP.Copy()
+	allowHTTP2.Policies = []policymodel.ID{policy2}
+	denyTCP2 := denyTCP.Copy()
+	denyTCP2.Policies = []policymodel.ID{policy2}
+	egress2 := []*renderer.ContivRule{allowHTTP2, denyTCP2}
+
+	// Prepare mocks.
+	//  -> Contiv plugin
+	contiv := NewMockContiv()
+	contiv.SetMainPhysicalIfName(mainIfName)
+	contiv.SetVxlanBVIIfName(vxlanIfName)
+	contiv.SetHostInterconnectIfName(hostInterIfName)
+	contiv.SetPodIfName(Pod1, Pod1IfName)
+	contiv.SetPodIfName(Pod2, Pod2IfName)
+
+	// -> ACL engine
+	aclEngine := NewMockACLEngine(logger, contiv)
+	aclEngine.RegisterPod(Pod1, Pod1IP, false)
+	aclEngine.RegisterPod(Pod2, Pod2IP, false)
+
+	// -> localclient
+	txnTracker := localclient.NewTxnTracker(aclEngine.ApplyTxn)
+
+	// -> default VPP plugins
+	vppPlugins := NewMockVppPlugin()
+
+	// Prepare ACL Renderer.
+	aclRenderer := &Renderer{
+		Deps: Deps{
+			Log:           logger,
+			Contiv:        contiv,
+			VPP:           vppPlugins,
+			ACLTxnFactory: txnTracker.NewLinuxDataChangeTxn,
+		},
+	}
+	aclRenderer.Init()
+
+	// Execute Renderer transaction.
+	txn := aclRenderer.NewTxn(true)
+	txn.Render(Pod1, GetOneHostSubnet(Pod1IP), ingress, egress1, false)
+	txn.Render(Pod2, GetOneHostSubnet(Pod2IP), ingress, egress2, false)
+	err := txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(txnTracker.CommittedTxns).To(gomega.HaveLen(1))
+
+	// Test that the pods share the table and the rules are identified by their content.
+	acl := aclEngine.GetOutboundACL(Pod1IfName)
+	gomega.Expect(acl).ToNot(gomega.BeNil())
+	gomega.Expect(aclEngine.GetOutboundACL(Pod2IfName)).To(gomega.Equal(acl))
+	gomega.Expect(len(acl.Rules)).To(gomega.BeNumerically(">", 3))
+	gomega.Expect(acl.Rules[0].RuleName).To(gomega.BeEquivalentTo(RuleID(allowHTTP)))
+	gomega.Expect(acl.Rules[0].RuleName).To(gomega.BeEquivalentTo(RuleID(allowHTTP2)))
+	gomega.Expect(acl.Rules[1].RuleName).To(gomega.BeEquivalentTo(RuleID(denyTCP)))
+	gomega.Expect(acl.Rules[2].RuleName).To(gomega.BeEquivalentTo(RuleID(denyTCP))) /* IPv6 counterpart */
+	gomega.Expect(acl.Rules[0].RuleName).ToNot(gomega.Equal(acl.Rules[1].RuleName))
+
+	// Test that the rules of the table are listed in the order of the rendered ACL rules.
+	table := aclRenderer.cache.GetLocalTableByPod(Pod1)
+	gomega.Expect(table).ToNot(gomega.BeNil())
+	rules := renderedRules(table)
+	gomega.Expect(rules).To(gomega.HaveLen(len(acl.Rules)))
+	for i, rule := range rules {
+		gomega.Expect(acl.Rules[i].RuleName).To(gomega.BeEquivalentTo(RuleID(rule)))
+	}
+
+	// Dump ACLs (VPP does not preserve the rule names) and put them to mock defaultplugins.
+	for _, acl := range aclEngine.DumpACLs() {
+		aclCopy := proto.Clone(acl).(*vpp_acl.AccessLists_Acl)
+		for _, rule := range aclCopy.Rules {
+			rule.RuleName = ""
+		}
+		vppPlugins.AddACL(aclCopy)
+	}
+
+	// Simulate restart of ACL Renderer.
+	txnTracker = localclient.NewTxnTracker(aclEngine.ApplyTxn)
+	aclRenderer = &Renderer{
+		Deps: Deps{
+			Log:           logger,
+			Contiv:        contiv,
+			VPP:           vppPlugins,
+			ACLTxnFactory: txnTracker.NewLinuxDataChangeTxn,
+		},
+	}
+	aclRenderer.Init()
+
+	txn = aclRenderer.NewTxn(true)
+	txn.Render(Pod1, GetOneHostSubnet(Pod1IP), ingress, egress1, false)
+	txn.Render(Pod2, GetOneHostSubnet(Pod2IP), ingress, egress2, false)
+	err = txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Test that the table restored from the dump yields the same rule IDs.
+	table = aclRenderer.cache.GetLocalTableByPod(Pod1)
+	gomega.Expect(table).ToNot(gomega.BeNil())
+	gomega.Expect(ACLNamePrefix + table.ID).To(gomega.Equal(acl.AclName))
+	rules = renderedRules(table)
+	gomega.Expect(rules).To(gomega.HaveLen(len(acl.Rules)))
+	for i, rule := range rules {
+		gomega.Expect(acl.Rules[i].RuleName).To(gomega.BeEquivalentTo(RuleID(rule)))
+	}
+}
@@ -0,0 +1,682 @@
+/*
+ * // Copyright (c) 2018 Cisco and/or its affiliates.
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at:
+ * //
+ * //     http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package acl
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/ligato/cn-infra/logging"
+
+	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
+	policymodel "github.com/contiv/vpp/plugins/ksr/model/policy"
+	"github.com/contiv/vpp/plugins/policy/renderer"
+	"github.com/contiv/vpp/plugins/policy/renderer/cache"
+	"github.com/contiv/vpp/plugins/policy/utils"
+)
+
+const (
+	// ACLStatsPath is the URL path of the Prometheus registry with the hit counters of the policy ACLs.
+	ACLStatsPath = "/metrics/policy"
+
+	// DefaultStatsInterval is the default period of the collection of the ACL hit counters.
+	DefaultStatsInterval = 10 * time.Second
+
+	// aclShowCmd is the VPP CLI command listing the ACLs with their indexes and tags (ACL names).
+	aclShowCmd = "show acl-plugin acl"
+
+	// aclAppliedCmd is the VPP CLI command listing the ACL rules applied in the hash lookup
+	// of the ACL plugin together with their hit counters.
+	aclAppliedCmd = "show acl-plugin tables applied"
+
+	// traceAddCmd, traceShowCmd and traceClearCmd control the VPP packet trace sampling the denied traffic.
+	traceAddCmd   = "trace add %s %d"
+	traceShowCmd  = "show trace max %d"
+	traceClearCmd = "clear trace"
+
+	// aclActionDeny is the action of the ACL plugin printed in the packet trace for the denied packets.
+	aclActionDeny = 0
+
+	metricsNamespace = "contiv"
+	metricsSubsystem = "policy"
+	nodeLabel        = "node"
+	aclLabel         = "acl"
+	ruleLabel        = "rule"
+	actionLabel      = "action"
+	policiesLabel    = "policies"
+	namespaceLabel   = "namespace"
+	podLabel         = "pod"
+)
+
+var (
+	// aclHeaderRegexp matches the header of an ACL in the output of aclShowCmd, e.g.:
+	//   acl-index 1 count 4 tag {contiv/vpp-policy-GLOBAL}
+	aclHeaderRegexp = regexp.MustCompile(`^acl-index\s+(\d+)\s+count\s+(\d+).*tag\s+\{([^}]*)\}`)
+
+	// aclAppliedRuleRegexp matches a rule applied in a lookup context in the output of aclAppliedCmd, e.g.:
+	//   0: acl 1 rule 0 action 0 bitmask-ready rule 0 next 0 prev 0 tail 0 hitcount 20
+	aclAppliedRuleRegexp = regexp.MustCompile(`\bacl\s+(\d+)\s+rule\s+(\d+)\s.*\bhitcount\s+(\d+)`)
+
+	// tracePacketRegexp matches the first line of a packet in the output of traceShowCmd.
+	tracePacketRegexp = regexp.MustCompile(`^Packet\s+\d+`)
+
+	// traceACLRegexp matches the trace of the ACL plugin node, e.g.:
+	//   acl-plugin: sw_if_index 3, next index 0, action: 0, match: acl 1 rule 0 trace_bits 00000000
+	traceACLRegexp = regexp.MustCompile(`acl-plugin:.*\baction:\s*(\d+),\s*match:\s*acl\s+(-?\d+)\s+rule\s+(-?\d+)`)
+
+	// tracePktInfoRegexp matches the 5-tuple of the packet printed by the ACL plugin node, e.g.:
+	//   pkt info 0000000000000000 0301010a00000000 0000000000000000 0808080800000000 00000011003504d2 ...
+	tracePktInfoRegexp = regexp.MustCompile(`pkt info((?:\s+[0-9a-f]{16}){6})`)
+
+	// traceInputNodes are the VPP input nodes traced to sample the denied traffic, one per type
+	// of the interfaces used by Contiv (physical, TAP v2, TAP, AF_PACKET and memif).
+	traceInputNodes = []string{"dpdk-input", "virtio-input", "tapcli-rx", "af-packet-input", "memif-input"}
+)
+
+// StatsConfig configures the collection of the ACL hit counters.
+type StatsConfig struct {
+	// Interval is the period of the collection.
+	Interval time.Duration
+
+	// DenyLogSampling enables the sampled logging of the denied traffic: VPP traces up to N packets
+	// received by each input node in every collection period and the traced packets denied by a policy ACL
+	// are logged. Zero disables the deny log.
+	DenyLogSampling uint64
+}
+
+// aclRuleOrigin is the pod and the policies an ACL rule was rendered for.
+type aclRuleOrigin struct {
+	pod      podmodel.ID
+	policies []policymodel.ID
+}
+
+// aclRuleStats describes a single rule of the rendered ACL together with its origins.
+// The rules matching all IP addresses are rendered as two ACL rules (IPv4 and IPv6),
+// their hits are summed together.
+type aclRuleStats struct {
+	id      string
+	rule    *renderer.ContivRule
+	origins []aclRuleOrigin
+}
+
+// aclTableStats describes the ACL rendered from a table: its rules in the order of the rules
+// of the ACL in VPP and the pods the ACL is applied to (none for the global table).
+type aclTableStats struct {
+	rules []*aclRuleStats
+	pods  []podmodel.ID
+}
+
+// vppACL is an ACL installed in VPP as listed by aclShowCmd.
+type vppACL struct {
+	name  string
+	rules int
+}
+
+// deniedPacket is a packet denied by an ACL rule as printed in the packet trace.
+// <aclIndex> and <ruleIndex> are -1 if the packet was denied without matching any rule.
+type deniedPacket struct {
+	aclIndex  int
+	ruleIndex int
+	srcIP     net.IP
+	dstIP     net.IP
+	protocol  uint8
+	srcPort   uint16
+	dstPort   uint16
+}
+
+// aclStats is the state of the collection of the ACL hit counters.
+type aclStats struct {
+	hits *prometheus.GaugeVec
+	pods *prometheus.GaugeVec
+
+	warned     bool // true once missing hit counters were reported
+	traceArmed bool // true once the packet trace sampling the denied traffic was started
+}
+
+// RuleID returns the identity of the ACL rule rendered from the given Contiv rule.
+// The identity is derived from the content of the rule (not from the policies,
+// the table or the pods the rule was rendered for), it is therefore the same
+// for every table sharing the rule and it survives the resync, which re-creates
+// the rules from the ACL dump. The policies the rule originates from are looked
+// up by the stats collection in the configuration of the pods the ACL is applied to.
+func RuleID(rule *renderer.ContivRule) string {
+	hash := fnv.New32a()
+	hash.Write([]byte(rule.String()))
+	return fmt.Sprintf("rule-%08x", hash.Sum32())
+}
+
+// renderedRules returns the rules of the table in the order of the rules of
+// the ACL rendered by renderACL, i.e. without the skipped rules and with the IPv6
+// counterpart following each rule matching all IP addresses.
+func renderedRules(table *cache.ContivRuleTable) []*renderer.ContivRule {
+	var rules []*renderer.ContivRule
+	for i := 0; i < table.NumOfRules; i++ {
+		rule := table.Rules[i]
+		if utils.HaveDifferentIPVersions(rule.SrcNetwork, rule.DestNetwork) || !isRenderable(rule) {
+			continue
+		}
+		rules = append(rules, rule)
+		if len(rule.SrcNetwork.IP) == 0 && len(rule.DestNetwork.IP) == 0 {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// initStats registers the gauges of the ACL hit counters into a new registry of the Prometheus plugin.
+func (r *Renderer) initStats() error {
+	var nodeName string
+	if r.ServiceLabel != nil {
+		nodeName = r.ServiceLabel.GetAgentLabel()
+	}
+	r.stats = &aclStats{}
+	r.stats.hits = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace:   metricsNamespace,
+		Subsystem:   metricsSubsystem,
+		Name:        "acl_rule_hits",
+		Help:        "Number of packets matched by the rule of the ACL rendered for the policies",
+		ConstLabels: prometheus.Labels{nodeLabel: nodeName},
+	}, []string{aclLabel, ruleLabel, actionLabel, policiesLabel})
+	r.stats.pods = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace:   metricsNamespace,
+		Subsystem:   metricsSubsystem,
+		Name:        "acl_pods",
+		Help:        "Pods the ACL rendered for the policies is applied to (always 1)",
+		ConstLabels: prometheus.Labels{nodeLabel: nodeName},
+	}, []string{aclLabel, namespaceLabel, podLabel})
+
+	if r.Prometheus == nil {
+		return nil
+	}
+	err := r.Prometheus.NewRegistry(ACLStatsPath, promhttp.HandlerOpts{ErrorHandling: promhttp.ContinueOnError})
+	if err != nil {
+		return err
+	}
+	err = r.Prometheus.Register(ACLStatsPath, r.stats.hits)
+	if err != nil {
+		return err
+	}
+	return r.Prometheus.Register(ACLStatsPath, r.stats.pods)
+}
+
+// CollectStats periodically reads the hit counters of the ACLs rendered by the renderer,
+// exports them via the Prometheus plugin and logs the sampled denied traffic.
+// The function returns when the context is cancelled.
+func (r *Renderer) CollectStats(ctx context.Context) {
+	ticker := time.NewTicker(r.Stats.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			err := r.collectStats()
+			if err != nil {
+				r.Log.WithField("err", err).Warn("Failed to collect the ACL hit counters")
+			}
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// collectStats reads the hit counters of the rendered ACLs through the VPP CLI. The binary API
+// of the ACL plugin has no counters, the hash lookup of the plugin however counts the hits of each rule
+// applied in a lookup context (interface and direction). The hits of a rule are summed over the lookup
+// contexts, i.e. over all interfaces the ACL is applied to.
+func (r *Renderer) collectStats() error {
+	tables := r.tableStats()
+
+	output, err := r.Contiv.ExecuteVppCLI(aclShowCmd)
+	if err != nil {
+		return err
+	}
+	acls := parseACLs(output)
+	output, err = r.Contiv.ExecuteVppCLI(aclAppliedCmd)
+	if err != nil {
+		return err
+	}
+	hits := parseACLHits(output)
+
+	r.stats.hits.Reset()
+	r.stats.pods.Reset()
+	if len(hits) == 0 && len(tables) > 0 && !r.stats.warned {
+		r.Log.Warnf("VPP does not report any ACL rules in the output of '%s' (hash matching of the ACL plugin "+
+			"disabled?), the hits of the policy rules cannot be collected", aclAppliedCmd)
+		r.stats.warned = true
+	}
+	for aclIndex, acl := range acls {
+		table, found := tables[acl.name]
+		if !found || len(table.rules) != acl.rules {
+			// ACL not yet installed or changed since the output was read
+			continue
+		}
+		tableID := strings.TrimPrefix(acl.name, ACLNamePrefix)
+		ruleHits := make(map[string]uint64)
+		for index, rule := range table.rules {
+			ruleHits[rule.id] += hits[aclIndex][index]
+		}
+		for index, rule := range table.rules {
+			if index > 0 && table.rules[index-1] == rule {
+				// IPv6 counterpart of the preceding rule
+				continue
+			}
+			r.stats.hits.WithLabelValues(tableID, rule.id, rule.rule.Action.String(), rule.policies()).
+				Set(float64(ruleHits[rule.id]))
+		}
+		for _, pod := range table.pods {
+			r.stats.pods.WithLabelValues(tableID, pod.Namespace, pod.Name).Set(1)
+		}
+	}
+
+	if r.Stats.DenyLogSampling > 0 {
+		return r.logDeniedTraffic(acls, tables)
+	}
+	return nil
+}
+
+// logDeniedTraffic logs the packets denied by the policy ACLs among the packets sampled by the VPP
+// packet trace since the previous collection and restarts the trace. Note that the trace is cleared
+// by every collection, the deny log should be disabled while VPP is debugged with the packet trace.
+func (r *Renderer) logDeniedTraffic(acls map[int]vppACL, tables map[string]*aclTableStats) error {
+	sampling := r.Stats.DenyLogSampling
+	if r.stats.traceArmed {
+		output, err := r.Contiv.ExecuteVppCLI(fmt.Sprintf(traceShowCmd, sampling*uint64(len(traceInputNodes))))
+		if err != nil {
+			return err
+		}
+		for _, packet := range parseDeniedPackets(output) {
+			acl, found := acls[packet.aclIndex]
+			if !found || !strings.HasPrefix(acl.name, ACLNamePrefix) {
+				// denied by an ACL not rendered for the policies
+				continue
+			}
+			r.logDeniedPacket(packet, strings.TrimPrefix(acl.name, ACLNamePrefix), tables[acl.name])
+		}
+	}
+
+	if _, err := r.Contiv.ExecuteVppCLI(traceClearCmd); err != nil {
+		return err
+	}
+	for _, node := range traceInputNodes {
+		// the nodes of the interface types not used by this VPP may be missing, the errors are therefore ignored
+		r.Contiv.ExecuteVppCLI(fmt.Sprintf(traceAddCmd, node, sampling))
+	}
+	r.stats.traceArmed = true
+	return nil
+}
+
+// logDeniedPacket logs the packet denied by the ACL rendered from the given table with the pod and the policies
+// of the rule which denied it. The pod is the destination pod if it is deployed on this node (local table),
+// otherwise the source pod (global table).
+func (r *Renderer) logDeniedPacket(packet *deniedPacket, tableID string, table *aclTableStats) {
+	var rule *aclRuleStats
+	if table != nil && packet.ruleIndex >= 0 && packet.ruleIndex < len(table.rules) {
+		rule = table.rules[packet.ruleIndex]
+	}
+
+	r.Lock()
+	pod, found := r.lookupPodByIP(packet.dstIP)
+	if !found {
+		pod, found = r.lookupPodByIP(packet.srcIP)
+	}
+	r.Unlock()
+
+	fields := logging.Fields{
+		"acl":      tableID,
+		"src":      packet.srcIP.String(),
+		"dst":      packet.dstIP.String(),
+		"protocol": protocolName(packet.protocol),
+		"srcPort":  packet.srcPort,
+		"dstPort":  packet.dstPort,
+	}
+	if found {
+		fields["pod"] = pod.String()
+	}
+	if rule != nil {
+		fields["rule"] = rule.id
+		for _, origin := range rule.origins {
+			if found && origin.pod == pod {
+				fields["policies"] = joinPolicies(origin.policies)
+			}
+		}
+	}
+	r.denyLog.WithFields(fields).Info("Denied traffic")
+}
+
+// policies returns the policies the rule originates from (for any pod), sorted and separated by commas.
+func (rs *aclRuleStats) policies() string {
+	var policies []policymodel.ID
+	for _, origin := range rs.origins {
+		for _, policy := range origin.policies {
+			listed := false
+			for _, listedPolicy := range policies {
+				if listedPolicy == policy {
+					listed = true
+					break
+				}
+			}
+			if !listed {
+				policies = append(policies, policy)
+			}
+		}
+	}
+	return joinPolicies(policies)
+}
+
+// tableStats returns the rendered ACLs (by the ACL name) with their rules and the pods and the policies
+// the rules originate from. The tables are shared between the pods with the same rules, the policies
+// are therefore looked up for each pod in its own configuration. VPP counts the hits per ACL rule,
+// not per interface - the hits are therefore reported per table, not per pod.
+func (r *Renderer) tableStats() map[string]*aclTableStats {
+	r.Lock()
+	defer r.Unlock()
+
+	stats := make(map[string]*aclTableStats)
+	for pod := range r.cache.GetAllPods() {
+		table := r.cache.GetLocalTableByPod(pod)
+		if table == nil || table.NumOfRules == 0 {
+			continue
+		}
+		aclName := ACLNamePrefix + table.ID
+		if _, done := stats[aclName]; done {
+			continue
+		}
+		tableStats := &aclTableStats{}
+		for tablePod := range table.Pods {
+			tableStats.pods = append(tableStats.pods, tablePod)
+		}
+		sort.Slice(tableStats.pods, func(i, j int) bool {
+			return tableStats.pods[i].String() < tableStats.pods[j].String()
+		})
+		tableStats.rules = r.tableRuleStats(table, func(rule *renderer.ContivRule) (origins []aclRuleOrigin) {
+			for _, tablePod := range tableStats.pods {
+				origins = append(origins, aclRuleOrigin{
+					pod:      tablePod,
+					policies: r.localRulePolicies(tablePod, rule),
+				})
+			}
+			return origins
+		})
+		stats[aclName] = tableStats
+	}
+
+	globalTable := r.cache.GetGlobalTable()
+	if globalTable.NumOfRules > 0 {
+		stats[ACLNamePrefix+globalTable.ID] = &aclTableStats{
+			rules: r.tableRuleStats(globalTable, r.globalRuleOrigins),
+		}
+	}
+	return stats
+}
+
+// tableRuleStats returns the rules of the ACL rendered from the given table with their origins.
+func (r *Renderer) tableRuleStats(table *cache.ContivRuleTable,
+	origins func(rule *renderer.ContivRule) []aclRuleOrigin) (stats []*aclRuleStats) {
+
+	for _, rule := range renderedRules(table) {
+		if len(stats) > 0 && stats[len(stats)-1].rule == rule {
+			// IPv6 counterpart of the preceding rule
+			stats = append(stats, stats[len(stats)-1])
+			continue
+		}
+		stats = append(stats, &aclRuleStats{
+			id:      RuleID(rule),
+			rule:    rule,
+			origins: origins(rule),
+		})
+	}
+	return stats
+}
+
+// localRulePolicies returns the policies of the rule from the local table of the given pod.
+// The table contains the rules of the pod and the rules combining them with the rules
+// of the other pods on the node (with the other pod as the source).
+func (r *Renderer) localRulePolicies(pod podmodel.ID, rule *renderer.ContivRule) []policymodel.ID {
+	podConfig := r.cache.GetPodConfig(pod)
+	if podConfig == nil {
+		return nil
+	}
+	for _, podRule := range podConfig.Egress {
+		if podRule.Compare(rule) == 0 {
+			return podRule.Policies
+		}
+	}
+	srcPod, found := r.lookupPodByNetwork(rule.SrcNetwork)
+	if !found {
+		return nil
+	}
+	var policies []policymodel.ID
+	policies = appendPolicies(policies, podConfig.Egress, rule.Protocol)
+	policies = appendPolicies(policies, r.cache.GetPodConfig(srcPod).Ingress, rule.Protocol)
+	return policies
+}
+
+// globalRuleOrigins returns the origin of the rule from the global table,
+// i.e. the pod the rule was installed for (as the source) and its policies.
+func (r *Renderer) globalRuleOrigins(rule *renderer.ContivRule) []aclRuleOrigin {
+	pod, found := r.lookupPodByNetwork(rule.SrcNetwork)
+	if !found {
+		// rule allowing the traffic not matched by the policies
+		return nil
+	}
+	podConfig := r.cache.GetPodConfig(pod)
+	for _, podRule := range podConfig.Ingress {
+		ruleCopy := podRule.Copy()
+		ruleCopy.SrcNetwork = podConfig.PodIP
+		if ruleCopy.Compare(rule) == 0 {
+			return []aclRuleOrigin{{pod: pod, policies: podRule.Policies}}
+		}
+	}
+	return []aclRuleOrigin{{pod: pod}}
+}
+
+// lookupPodByNetwork returns ID of the pod with the given host network tracked by the cache.
+func (r *Renderer) lookupPodByNetwork(network *net.IPNet) (pod podmodel.ID, found bool) {
+	if network == nil || len(network.IP) == 0 {
+		return pod, false
+	}
+	ones, bits := network.Mask.Size()
+	if ones != bits {
+		return pod, false
+	}
+	return r.lookupPodByIP(network.IP)
+}
+
+// appendPolicies adds the policies of the given rules of the given protocol which are not yet listed.
+func appendPolicies(policies []policymodel.ID, rules []*renderer.ContivRule, protocol renderer.ProtocolType) []policymodel.ID {
+	for _, rule := range rules {
+		if rule.Protocol != protocol {
+			continue
+		}
+		for _, policy := range rule.Policies {
+			listed := false
+			for _, listedPolicy := range policies {
+				if listedPolicy == policy {
+					listed = true
+					break
+				}
+			}
+			if !listed {
+				policies = append(policies, policy)
+			}
+		}
+	}
+	return policies
+}
+
+// joinPolicies returns the IDs of the given policies sorted and separated by commas.
+func joinPolicies(policies []policymodel.ID) string {
+	names := []string{}
+	for _, policy := range policies {
+		names = append(names, policy.String())
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// parseACLs parses the ACLs (by the ACL index) from the output of aclShowCmd.
+func parseACLs(output string) map[int]vppACL {
+	acls := make(map[int]vppACL)
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		match := aclHeaderRegexp.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+		index, err := strconv.Atoi(match[1])
+		if err != nil {
+			continue
+		}
+		rules, err := strconv.Atoi(match[2])
+		if err != nil {
+			continue
+		}
+		acls[index] = vppACL{name: match[3], rules: rules}
+	}
+	return acls
+}
+
+// parseACLHits parses the hit counters of the ACL rules (by the ACL index and the rule index) from the output
+// of aclAppliedCmd. The counters of a rule applied in several lookup contexts are summed.
+func parseACLHits(output string) map[int]map[int]uint64 {
+	hits := make(map[int]map[int]uint64)
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		match := aclAppliedRuleRegexp.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+		aclIndex, err := strconv.Atoi(match[1])
+		if err != nil {
+			continue
+		}
+		ruleIndex, err := strconv.Atoi(match[2])
+		if err != nil {
+			continue
+		}
+		count, err := strconv.ParseUint(match[3], 10, 64)
+		if err != nil {
+			continue
+		}
+		if hits[aclIndex] == nil {
+			hits[aclIndex] = make(map[int]uint64)
+		}
+		hits[aclIndex][ruleIndex] += count
+	}
+	return hits
+}
+
+// parseDeniedPackets parses the packets denied by the ACL plugin from the output of traceShowCmd.
+func parseDeniedPackets(output string) (packets []*deniedPacket) {
+	var packet *deniedPacket
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if tracePacketRegexp.MatchString(line) {
+			packet = nil
+			continue
+		}
+		if match := traceACLRegexp.FindStringSubmatch(line); match != nil {
+			packet = nil
+			if action, err := strconv.Atoi(match[1]); err != nil || action != aclActionDeny {
+				continue
+			}
+			aclIndex, err := strconv.Atoi(match[2])
+			if err != nil {
+				continue
+			}
+			ruleIndex, err := strconv.Atoi(match[3])
+			if err != nil {
+				continue
+			}
+			packet = &deniedPacket{aclIndex: aclIndex, ruleIndex: ruleIndex}
+			continue
+		}
+		if match := tracePktInfoRegexp.FindStringSubmatch(line); match != nil && packet != nil {
+			if parseFiveTuple(strings.Fields(match[1]), packet) {
+				packets = append(packets, packet)
+			}
+			packet = nil
+		}
+	}
+	return packets
+}
+
+// parseFiveTuple decodes the 5-tuple of the packet from the "pkt info" printed by the ACL plugin, i.e. from
+// the words of its fa_5tuple_t printed as 64-bit integers of the host (little-endian) byte order:
+// the source and the destination address as ip46_address_t (IPv4 address in the last 4 bytes with zeroes
+// before), followed by the source and the destination port and the protocol.
+func parseFiveTuple(words []string, packet *deniedPacket) bool {
+	raw := make([]byte, 8*len(words))
+	for i, word := range words {
+		value, err := strconv.ParseUint(word, 16, 64)
+		if err != nil {
+			return false
+		}
+		binary.LittleEndian.PutUint64(raw[8*i:], value)
+	}
+	src, dst := raw[0:16], raw[16:32]
+	if isIP4InIP46(src) && isIP4InIP46(dst) {
+		src, dst = src[12:], dst[12:]
+	}
+	packet.srcIP = append(net.IP{}, src...)
+	packet.dstIP = append(net.IP{}, dst...)
+	packet.srcPort = binary.LittleEndian.Uint16(raw[32:34])
+	packet.dstPort = binary.LittleEndian.Uint16(raw[34:36])
+	packet.protocol = raw[36]
+	return true
+}
+
+// isIP4InIP46 returns true if the given ip46_address_t holds an IPv4 address.
+func isIP4InIP46(addr []byte) bool {
+	for _, b := range addr[:12] {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// protocolName returns the name of the given IP protocol, its number if it is not known.
+func protocolName(protocol uint8) string {
+	switch protocol {
+	case 1:
+		return "ICMP"
+	case 6:
+		return "TCP"
+	case 17:
+		return "UDP"
+	case 58:
+		return "ICMPv6"
+	case sctpProtocolNumber:
+		return "SCTP"
+	}
+	return strconv.Itoa(int(protocol))
+}
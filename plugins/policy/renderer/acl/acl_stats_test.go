@@ -0,0 +1,407 @@
+/*
+ * // Copyright (c) 2018 Cisco and/or its affiliates.
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at:
+ * //
+ * //     http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package acl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/onsi/gomega"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/cn-infra/logging/logrus"
+	vpp_acl "github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/acl"
+
+	. "github.com/contiv/vpp/mock/aclengine"
+	. "github.com/contiv/vpp/mock/contiv"
+	. "github.com/contiv/vpp/mock/defaultplugins"
+	"github.com/contiv/vpp/mock/localclient"
+	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
+	policymodel "github.com/contiv/vpp/plugins/ksr/model/policy"
+	"github.com/contiv/vpp/plugins/policy/renderer"
+	"github.com/contiv/vpp/plugins/policy/renderer/cache"
+	. "github.com/contiv/vpp/plugins/policy/renderer/testdata"
+	. "github.com/contiv/vpp/plugins/policy/utils"
+)
+
+// vppACLs returns the ACLs installed by the engine, sorted by name; the ACL index in VPP is the position.
+func vppACLs(engine *MockACLEngine) []*vpp_acl.AccessLists_Acl {
+	acls := engine.DumpACLs()
+	sort.Slice(acls, func(i, j int) bool { return acls[i].AclName < acls[j].AclName })
+	return acls
+}
+
+// vppACLIndex returns the index of the ACL with the given name in VPP.
+func vppACLIndex(engine *MockACLEngine, aclName string) int {
+	for index, acl := range vppACLs(engine) {
+		if acl.AclName == aclName {
+			return index
+		}
+	}
+	return -1
+}
+
+// aclShowOutput returns the output of aclShowCmd listing the given ACLs.
+func aclShowOutput(acls []*vpp_acl.AccessLists_Acl) string {
+	output := &bytes.Buffer{}
+	for aclIndex, acl := range acls {
+		fmt.Fprintf(output, "acl-index %d count %d tag {%s}\n", aclIndex, len(acl.Rules), acl.AclName)
+		for index, rule := range acl.Rules {
+			fmt.Fprintf(output, "  %9d: ipv4 %s src 0.0.0.0/0 dst 0.0.0.0/0 proto 0 sport 0-65535 dport 0-65535\n",
+				index, rule.Actions.AclAction)
+		}
+		fmt.Fprintf(output, "  applied inbound on sw_if_index: \n  applied outbound on sw_if_index: 1\n")
+	}
+	return output.String()
+}
+
+// aclAppliedOutput returns the output of aclAppliedCmd with the rules of the given ACLs applied
+// in one lookup context each with the hit counters returned by <hits> for the ACL name and the rule index.
+func aclAppliedOutput(acls []*vpp_acl.AccessLists_Acl, hits func(aclName string, index int) uint64) string {
+	output := &bytes.Buffer{}
+	fmt.Fprintln(output, "Applied lookup entries for lookup contexts")
+	for aclIndex, acl := range acls {
+		fmt.Fprintf(output, "lc_index %d:\n  applied acls: %d\n  applied hash entries: %d\n",
+			aclIndex, aclIndex, len(acl.Rules))
+		for index := range acl.Rules {
+			fmt.Fprintf(output, "    %d: acl %d rule %d action 0 bitmask-ready rule %d next 0 prev 0 tail 0 hitcount %d\n",
+				index, aclIndex, index, index, hits(acl.AclName, index))
+		}
+	}
+	return output.String()
+}
+
+// tracePacket returns a packet in the output of traceShowCmd passing the ACL plugin node
+// with the given action and match.
+func tracePacket(number int, action, aclIndex, ruleIndex int, src, dst string, protocol uint8,
+	srcPort, dstPort uint16) string {
+
+	raw := make([]byte, 48)
+	copy(raw[12:16], net.ParseIP(src).To4())
+	copy(raw[28:32], net.ParseIP(dst).To4())
+	binary.LittleEndian.PutUint16(raw[32:], srcPort)
+	binary.LittleEndian.PutUint16(raw[34:], dstPort)
+	raw[36] = protocol
+	raw[40] = 3 /* sw_if_index */
+	words := []string{}
+	for i := 0; i < len(raw); i += 8 {
+		words = append(words, fmt.Sprintf("%016x", binary.LittleEndian.Uint64(raw[i:])))
+	}
+	return fmt.Sprintf("Packet %d\n\n"+
+		"00:01:02:345678: virtio-input\n  virtio: hw_if_index 3 next-index 4 vring 0 len 98\n"+
+		"00:01:02:345690: ip4-input\n  %s -> %s\n"+
+		"00:01:02:345700: acl-plugin-out-ip4-fa\n"+
+		"  acl-plugin: sw_if_index 3, next index 0, action: %d, match: acl %d rule %d trace_bits 00000000\n"+
+		"  pkt info %s\n"+
+		"00:01:02:345710: error-drop\n  acl-plugin-out-ip4-fa: ACL deny packets\n\n",
+		number, src, dst, action, aclIndex, ruleIndex, strings.Join(words, " "))
+}
+
+// ruleHits returns the exported hits of the rule of the given ACL.
+func ruleHits(aclRenderer *Renderer, acl *vpp_acl.AccessLists_Acl, rule *renderer.ContivRule, policies string) float64 {
+	metric := &dto.Metric{}
+	aclRenderer.stats.hits.WithLabelValues(acl.AclName[len(ACLNamePrefix):], RuleID(rule), rule.Action.String(),
+		policies).Write(metric)
+	return metric.GetGauge().GetValue()
+}
+
+// aclPod returns the exported value of the pod of the given ACL (1 if the ACL is applied to the pod).
+func aclPod(aclRenderer *Renderer, acl *vpp_acl.AccessLists_Acl, pod podmodel.ID) float64 {
+	metric := &dto.Metric{}
+	aclRenderer.stats.pods.WithLabelValues(acl.AclName[len(ACLNamePrefix):], pod.Namespace, pod.Name).Write(metric)
+	return metric.GetGauge().GetValue()
+}
+
+func TestACLStats(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestACLStats")
+
+	// Prepare input data
+	policy1 := policymodel.ID{Name: "policy1", Namespace: "default"}
+	policy2 := policymodel.ID{Name: "policy2", Namespace: "default"}
+	policy3 := policymodel.ID{Name: "policy3", Namespace: "default"}
+	allowHTTP := &renderer.ContivRule{
+		Action:      renderer.ActionPermit,
+		SrcNetwork:  IpNetwork("10.10.0.0/16"),
+		DestNetwork: IpNetwork(""),
+		Protocol:    renderer.TCP,
+		DestPort:    80,
+		Policies:    []policymodel.ID{policy1},
+	}
+	denyTCP := DenyAllTCP()
+	denyTCP.Policies = []policymodel.ID{policy1}
+	denyDNS := &renderer.ContivRule{
+		Action:      renderer.ActionDeny,
+		SrcNetwork:  IpNetwork(""),
+		DestNetwork: IpNetwork(googleDNS + "/32"),
+		Protocol:    renderer.UDP,
+		DestPort:    53,
+		Policies:    []policymodel.ID{policy3},
+	}
+	ingress1 := []*renderer.ContivRule{denyDNS}
+	egress1 := []*renderer.ContivRule{allowHTTP, denyTCP}
+
+	// The same rules originating from a different policy - the table is shared.
+	allowHTTP2 := allowHTTP.Copy()
+	allowHTTP2.Policies = []policymodel.ID{policy2}
+	denyTCP2 := denyTCP.Copy()
+	denyTCP2.Policies = []policymodel.ID{policy2}
+	ingress2 := []*renderer.ContivRule{}
+	egress2 := []*renderer.ContivRule{allowHTTP2, denyTCP2}
+
+	// Rule of the global table rendered from the ingress of pod1.
+	globalDenyDNS := denyDNS.Copy()
+	globalDenyDNS.SrcNetwork = GetOneHostSubnet(Pod1IP)
+
+	// Prepare mocks.
+	//  -> Contiv plugin
+	contiv := NewMockContiv()
+	contiv.SetMainPhysicalIfName(mainIfName)
+	contiv.SetVxlanBVIIfName(vxlanIfName)
+	contiv.SetHostInterconnectIfName(hostInterIfName)
+	contiv.SetPodIfName(Pod1, Pod1IfName)
+	contiv.SetPodIfName(Pod2, Pod2IfName)
+
+	// -> ACL engine
+	aclEngine := NewMockACLEngine(logger, contiv)
+	aclEngine.RegisterPod(Pod1, Pod1IP, false)
+	aclEngine.RegisterPod(Pod2, Pod2IP, false)
+
+	// -> localclient
+	txnTracker := localclient.NewTxnTracker(aclEngine.ApplyTxn)
+
+	// -> default VPP plugins
+	vppPlugins := NewMockVppPlugin()
+
+	// -> deny log
+	denyLogOutput := &bytes.Buffer{}
+	denyLog := logrus.NewLogger("denyLog")
+	denyLog.SetOutput(denyLogOutput)
+
+	// Prepare ACL Renderer.
+	newRenderer := func() *Renderer {
+		aclRenderer := &Renderer{
+			Deps: Deps{
+				Log:           logger,
+				Contiv:        contiv,
+				VPP:           vppPlugins,
+				ACLTxnFactory: txnTracker.NewLinuxDataChangeTxn,
+				Stats:         &StatsConfig{Interval: time.Second, DenyLogSampling: 10},
+			},
+		}
+		gomega.Expect(aclRenderer.Init()).To(gomega.BeNil())
+		aclRenderer.denyLog = denyLog
+		return aclRenderer
+	}
+	aclRenderer := newRenderer()
+
+	// Execute Renderer transaction.
+	txn := aclRenderer.NewTxn(true)
+	txn.Render(Pod1, GetOneHostSubnet(Pod1IP), ingress1, egress1, false)
+	txn.Render(Pod2, GetOneHostSubnet(Pod2IP), ingress2, egress2, false)
+	err := txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	localACL := aclEngine.GetOutboundACL(Pod1IfName)
+	gomega.Expect(localACL).ToNot(gomega.BeNil())
+	gomega.Expect(aclEngine.GetOutboundACL(Pod2IfName)).To(gomega.Equal(localACL))
+	globalACL := aclEngine.GetACLByName(ACLNamePrefix + cache.GlobalTableID)
+	gomega.Expect(globalACL).ToNot(gomega.BeNil())
+	globalDenyDNSIndex := -1
+	for index, rule := range globalACL.Rules {
+		if rule.RuleName == RuleID(globalDenyDNS) {
+			globalDenyDNSIndex = index
+		}
+	}
+	gomega.Expect(globalDenyDNSIndex).ToNot(gomega.BeEquivalentTo(-1))
+
+	localACLIndex := vppACLIndex(aclEngine, localACL.AclName)
+	globalACLIndex := vppACLIndex(aclEngine, globalACL.AclName)
+	traceShow := fmt.Sprintf(traceShowCmd, 10*len(traceInputNodes))
+	contiv.SetVppCLIOutput(traceClearCmd, "")
+	contiv.SetVppCLIOutput(traceShow, "")
+
+	// No rules applied in the hash lookup.
+	contiv.SetVppCLIOutput(aclShowCmd, aclShowOutput(vppACLs(aclEngine)))
+	contiv.SetVppCLIOutput(aclAppliedCmd, "Applied lookup entries for lookup contexts\n")
+	err = aclRenderer.collectStats()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(aclRenderer.stats.warned).To(gomega.BeTrue())
+
+	// Output with the hit counters.
+	localHits := map[int]uint64{0: 3, 1: 4, 2: 1} /* IPv4 and IPv6 rule denying TCP */
+	globalHits := map[int]uint64{globalDenyDNSIndex: 20}
+	hits := func(aclName string, index int) uint64 {
+		switch aclName {
+		case localACL.AclName:
+			return localHits[index]
+		case globalACL.AclName:
+			return globalHits[index]
+		}
+		return 100
+	}
+	applied := aclAppliedOutput(vppACLs(aclEngine), hits)
+	// the local table is applied to the interfaces of both pods
+	applied += fmt.Sprintf("lc_index 9:\n  applied acls: %d\n  applied hash entries: 1\n"+
+		"    0: acl %d rule 1 action 0 bitmask-ready rule 1 next 0 prev 0 tail 0 hitcount 2\n",
+		localACLIndex, localACLIndex)
+	contiv.SetVppCLIOutput(aclAppliedCmd, applied)
+	err = aclRenderer.collectStats()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Test that the hits of the shared table are reported once with the policies of all its pods.
+	verifyHits := func(aclRenderer *Renderer) {
+		policies12 := policy1.String() + "," + policy2.String()
+		gomega.Expect(ruleHits(aclRenderer, localACL, allowHTTP, policies12)).To(gomega.BeEquivalentTo(3))
+		gomega.Expect(ruleHits(aclRenderer, localACL, denyTCP, policies12)).To(gomega.BeEquivalentTo(7))
+		gomega.Expect(ruleHits(aclRenderer, globalACL, globalDenyDNS, policy3.String())).To(gomega.BeEquivalentTo(20))
+		gomega.Expect(aclPod(aclRenderer, localACL, Pod1)).To(gomega.BeEquivalentTo(1))
+		gomega.Expect(aclPod(aclRenderer, localACL, Pod2)).To(gomega.BeEquivalentTo(1))
+		gomega.Expect(aclPod(aclRenderer, globalACL, Pod1)).To(gomega.BeEquivalentTo(0))
+	}
+	verifyHits(aclRenderer)
+
+	// The trace sampling the denied traffic was started by the first collection.
+	gomega.Expect(aclRenderer.stats.traceArmed).To(gomega.BeTrue())
+	gomega.Expect(denyLogOutput.String()).To(gomega.BeEmpty())
+
+	// Test that the traced denied packets are logged with their 5-tuple, pod and policies.
+	contiv.SetVppCLIOutput(traceShow, tracePacket(1, aclActionDeny, localACLIndex, 1, "10.20.0.1", Pod2IP, 6, 4321, 8080)+
+		tracePacket(2, aclActionDeny, globalACLIndex, globalDenyDNSIndex, Pod1IP, googleDNS, 17, 1234, 53)+
+		tracePacket(3, 1 /* permit */, localACLIndex, 0, "10.10.50.1", Pod1IP, 6, 1111, 80)+
+		tracePacket(4, aclActionDeny, 99 /* not a policy ACL */, 0, "10.30.0.1", Pod1IP, 6, 2222, 80))
+	err = aclRenderer.collectStats()
+	gomega.Expect(err).To(gomega.BeNil())
+	denyLogLines := strings.Split(strings.TrimSpace(denyLogOutput.String()), "\n")
+	gomega.Expect(denyLogLines).To(gomega.HaveLen(2))
+	gomega.Expect(denyLogLines[0]).To(gomega.ContainSubstring("Denied traffic"))
+	gomega.Expect(denyLogLines[0]).To(gomega.ContainSubstring("10.20.0.1"))
+	gomega.Expect(denyLogLines[0]).To(gomega.ContainSubstring("dstPort=8080"))
+	gomega.Expect(denyLogLines[0]).To(gomega.ContainSubstring(Pod2.String()))
+	gomega.Expect(denyLogLines[0]).To(gomega.ContainSubstring(policy2.String()))
+	gomega.Expect(denyLogLines[0]).ToNot(gomega.ContainSubstring(policy1.String()))
+	gomega.Expect(denyLogLines[0]).To(gomega.ContainSubstring(RuleID(denyTCP)))
+	gomega.Expect(denyLogLines[1]).To(gomega.ContainSubstring(googleDNS))
+	gomega.Expect(denyLogLines[1]).To(gomega.ContainSubstring("UDP"))
+	gomega.Expect(denyLogLines[1]).To(gomega.ContainSubstring(Pod1.String()))
+	gomega.Expect(denyLogLines[1]).To(gomega.ContainSubstring(policy3.String()))
+
+	// Dump ACLs (VPP does not preserve the rule names) and put them to mock defaultplugins.
+	for _, acl := range aclEngine.DumpACLs() {
+		aclCopy := proto.Clone(acl).(*vpp_acl.AccessLists_Acl)
+		for _, rule := range aclCopy.Rules {
+			rule.RuleName = ""
+		}
+		vppPlugins.AddACL(aclCopy)
+	}
+
+	// Simulate restart of ACL Renderer.
+	aclRenderer = newRenderer()
+	txn = aclRenderer.NewTxn(true)
+	txn.Render(Pod1, GetOneHostSubnet(Pod1IP), ingress1, egress1, false)
+	txn.Render(Pod2, GetOneHostSubnet(Pod2IP), ingress2, egress2, false)
+	err = txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Test that the hits are reported with the same rule IDs and policies after the resync.
+	err = aclRenderer.collectStats()
+	gomega.Expect(err).To(gomega.BeNil())
+	verifyHits(aclRenderer)
+}
+
+func TestParseACLHits(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	acls := parseACLs("acl-index 0 count 2 tag {contiv/vpp-policy-REFLECTION}\n" +
+		"          0: ipv4 permit+reflect src 0.0.0.0/0 dst 0.0.0.0/0 proto 0 sport 0-65535 dport 0-65535\n" +
+		"          1: ipv6 permit+reflect src ::/0 dst ::/0 proto 0 sport 0-65535 dport 0-65535\n" +
+		"  applied inbound on sw_if_index: 1,2\n" +
+		"  applied outbound on sw_if_index: \n" +
+		"acl-index 3 count 1 tag {contiv/vpp-policy-NODE-GLOBAL}\n" +
+		"          0: ipv4 deny src 10.1.1.3/32 dst 0.0.0.0/0 proto 6 sport 0-65535 dport 0-65535\n" +
+		"  applied outbound on sw_if_index: 3\n")
+	gomega.Expect(acls).To(gomega.Equal(map[int]vppACL{
+		0: {name: "contiv/vpp-policy-REFLECTION", rules: 2},
+		3: {name: "contiv/vpp-policy-NODE-GLOBAL", rules: 1},
+	}))
+
+	hits := parseACLHits("Applied lookup entries for lookup contexts\n" +
+		"lc_index 0:\n" +
+		"  applied acls: 0\n" +
+		"  applied hash entries: 2\n" +
+		"     0: acl 0 rule 0 action 2 bitmask-ready rule 0 next 0 prev 0 tail 0 hitcount 15\n" +
+		"     1: acl 0 rule 1 action 2 bitmask-ready rule 1 next 0 prev 0 tail 0 hitcount 0\n" +
+		"lc_index 1:\n" +
+		"  applied acls: 0,3\n" +
+		"  applied hash entries: 3\n" +
+		"     0: acl 0 rule 0 action 2 bitmask-ready rule 0 next 0 prev 0 tail 0 hitcount 5\n" +
+		"     1: acl 0 rule 1 action 2 bitmask-ready rule 1 next 0 prev 0 tail 0 hitcount 1\n" +
+		"     2: acl 3 rule 0 action 0 bitmask-ready rule 2 next 0 prev 0 tail 0 hitcount 7\n")
+	gomega.Expect(hits).To(gomega.Equal(map[int]map[int]uint64{
+		0: {0: 20, 1: 1},
+		3: {0: 7},
+	}))
+
+	// No rules applied.
+	gomega.Expect(parseACLHits("")).To(gomega.BeEmpty())
+}
+
+func TestParseDeniedPackets(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	packets := parseDeniedPackets("------------------- Start of thread 0 vpp_main -------------------\n" +
+		"Packet 1\n\n" +
+		"00:01:02:345700: acl-plugin-out-ip4-fa\n" +
+		"  acl-plugin: sw_if_index 3, next index 0, action: 0, match: acl 1 rule 2 trace_bits 00000000\n" +
+		"  pkt info 0000000000000000 0301010a00000000 0000000000000000 0808080800000000 00000011003504d2 0000000300000000\n" +
+		"00:01:02:345710: error-drop\n" +
+		"  acl-plugin-out-ip4-fa: ACL deny packets\n\n" +
+		"Packet 2\n\n" +
+		"00:01:02:345800: acl-plugin-in-ip6-fa\n" +
+		"  acl-plugin: sw_if_index 4, next index 1, action: 1, match: acl 0 rule 0 trace_bits 00000000\n" +
+		"  pkt info 000000000000fefe 0100000000000000 000000000000fefe 0200000000000000 0000000600500457 0000000400000000\n" +
+		"Packet 3\n\n" +
+		"00:01:02:345900: acl-plugin-in-ip6-fa\n" +
+		"  acl-plugin: lc_index: 1, sw_if_index 4, next index 0, action: 0, match: acl -1 rule -1 trace_bits 00000000\n" +
+		"  pkt info 000000000000fefe 0100000000000000 000000000000fefe 0200000000000000 0000000600500457 0000000400000000\n")
+	gomega.Expect(packets).To(gomega.HaveLen(2))
+	gomega.Expect(*packets[0]).To(gomega.Equal(deniedPacket{
+		aclIndex:  1,
+		ruleIndex: 2,
+		srcIP:     net.IP{10, 1, 1, 3},
+		dstIP:     net.IP{8, 8, 8, 8},
+		protocol:  17,
+		srcPort:   1234,
+		dstPort:   53,
+	}))
+	gomega.Expect(packets[1].aclIndex).To(gomega.BeEquivalentTo(-1))
+	gomega.Expect(packets[1].srcIP.String()).To(gomega.Equal("fefe::1"))
+	gomega.Expect(packets[1].dstIP.String()).To(gomega.Equal("fefe::2"))
+	gomega.Expect(protocolName(packets[1].protocol)).To(gomega.Equal("TCP"))
+	gomega.Expect(packets[1].srcPort).To(gomega.BeEquivalentTo(1111))
+	gomega.Expect(packets[1].dstPort).To(gomega.BeEquivalentTo(80))
+}
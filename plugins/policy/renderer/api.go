@@ -22,6 +22,7 @@ import (
 	"strconv"
 
 	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
+	policymodel "github.com/contiv/vpp/plugins/ksr/model/policy"
 	"github.com/contiv/vpp/plugins/policy/utils"
 )
 
@@ -40,6 +41,19 @@ type PolicyRendererAPI interface {
 	NewTxn(resync bool) Txn
 }
 
+// TrafficEvaluator is an optional interface of Policy Renderer, implemented
+// by renderers able to evaluate a connection against the rules actually rendered
+// into the destination network stack (i.e. including all the transformations
+// and omissions done by the renderer and its cache).
+type TrafficEvaluator interface {
+	// EvaluateTraffic returns the rendered rule matching the given connection.
+	// For ICMP, srcPort and destPort carry the ICMP type and code, respectively.
+	// <applied> is false if no rendered rules apply to the connection (i.e. it is
+	// allowed), nil <rule> with <applied> true means that the connection is denied
+	// by not matching any of the rendered rules.
+	EvaluateTraffic(srcIP, destIP net.IP, protocol ProtocolType, srcPort, destPort uint16) (rule *ContivRule, applied bool)
+}
+
 // Txn defines API of PolicyRenderer transaction.
 type Txn interface {
 	// Render applies the set of ingress & egress rules for a given pod.
@@ -51,6 +65,8 @@ type Txn interface {
 	// The renderer may use the provided pod IP to make the rules fully specific
 	// in case they are installed globally and not assigned to interfaces.
 	// Empty set of rules should allow any traffic in that direction.
+	// Undefined network matches both IPv4 and IPv6 addresses - renderers are
+	// expected to install the rule for both IP versions.
 	// The flag *removed* is set to true if the pod was just removed - in such
 	// case *podIP* may be nil and both list of rules are empty.
 	Render(pod podmodel.ID, podIP *net.IPNet /* one host subnet */, ingress []*ContivRule, egress []*ContivRule, removed bool) Txn
@@ -67,13 +83,131 @@ type ContivRule struct {
 	Action ActionType
 
 	// L3
-	SrcNetwork  *net.IPNet // empty = match all
-	DestNetwork *net.IPNet // empty = match all
+	SrcNetwork  *net.IPNet // empty = match all (IPv4 and IPv6)
+	DestNetwork *net.IPNet // empty = match all (IPv4 and IPv6)
 
 	// L4
-	Protocol ProtocolType
-	SrcPort  uint16 // 0 = match all
-	DestPort uint16 // 0 = match all
+	Protocol    ProtocolType
+	SrcPort     uint16     // 0 = match all, not used with ICMP
+	DestPort    uint16     // 0 = match all, not used with ICMP
+	DestPortEnd uint16     // > DestPort = match range <DestPort, DestPortEnd>, not used with ICMP
+	ICMP        *ICMPMatch // nil = match all, used only with ICMP
+
+	// Policies lists the policies the rule originates from. Rules denying
+	// the rest of the traffic originate from all policies isolating the pod.
+	// The origin is informational only - it is not considered by Compare().
+	Policies []policymodel.ID
+}
+
+// HasPolicy returns true if the rule originates (also) from the given policy.
+func (cr *ContivRule) HasPolicy(policy policymodel.ID) bool {
+	for _, origin := range cr.Policies {
+		if origin == policy {
+			return true
+		}
+	}
+	return false
+}
+
+// HasDestPortRange returns true if the rule matches a range of destination
+// ports (as opposed to a single port or all ports).
+func (cr *ContivRule) HasDestPortRange() bool {
+	return cr.DestPort != 0 && cr.DestPortEnd > cr.DestPort
+}
+
+// MatchesDestPort returns true if the given destination port is matched by the rule.
+func (cr *ContivRule) MatchesDestPort(port uint16) bool {
+	if cr.DestPort == 0 {
+		return true
+	}
+	if cr.HasDestPortRange() {
+		return port >= cr.DestPort && port <= cr.DestPortEnd
+	}
+	return port == cr.DestPort
+}
+
+// Matches returns true if the given packet is matched by the rule.
+// For ICMP, srcPort and destPort carry the ICMP type and code, respectively.
+func (cr *ContivRule) Matches(srcIP, destIP net.IP, protocol ProtocolType, srcPort, destPort uint16) bool {
+	if len(cr.SrcNetwork.IP) > 0 && !cr.SrcNetwork.Contains(srcIP) {
+		return false
+	}
+	if len(cr.DestNetwork.IP) > 0 && !cr.DestNetwork.Contains(destIP) {
+		return false
+	}
+	if cr.Protocol != protocol {
+		return false
+	}
+	if protocol == ICMP {
+		return cr.ICMP.Covers(&ICMPMatch{Type: uint8(srcPort), Code: uint8(destPort)})
+	}
+	if cr.SrcPort != 0 && cr.SrcPort != srcPort {
+		return false
+	}
+	return cr.MatchesDestPort(destPort)
+}
+
+// ICMPMatch selects ICMP messages by the type and optionally also by the code.
+type ICMPMatch struct {
+	Type uint8
+	Code uint8
+
+	// AnyCode is true if the messages of the given type should be matched
+	// regardless of the code.
+	AnyCode bool
+}
+
+// String converts ICMPMatch (pointer) into a human-readable string
+// representation.
+func (im *ICMPMatch) String() string {
+	const any = "ANY"
+	if im == nil {
+		return any
+	}
+	if im.AnyCode {
+		return strconv.Itoa(int(im.Type)) + "/" + any
+	}
+	return strconv.Itoa(int(im.Type)) + "/" + strconv.Itoa(int(im.Code))
+}
+
+// Covers returns true if all the messages selected by <im2> are also selected
+// by this match.
+func (im *ICMPMatch) Covers(im2 *ICMPMatch) bool {
+	if im == nil {
+		return true
+	}
+	if im2 == nil || im.Type != im2.Type {
+		return false
+	}
+	return im.AnyCode || (!im2.AnyCode && im.Code == im2.Code)
+}
+
+// Compare returns -1, 0, 1 if this<im2 or this==im2 or this>im2, respectively.
+// ICMP matches selecting more messages are higher in the order.
+func (im *ICMPMatch) Compare(im2 *ICMPMatch) int {
+	if im == nil || im2 == nil {
+		if im == im2 {
+			return 0
+		}
+		if im == nil {
+			return 1
+		}
+		return -1
+	}
+	typeOrder := utils.CompareInts(int(im.Type), int(im2.Type))
+	if typeOrder != 0 {
+		return typeOrder
+	}
+	if im.AnyCode || im2.AnyCode {
+		if im.AnyCode == im2.AnyCode {
+			return 0
+		}
+		if im.AnyCode {
+			return 1
+		}
+		return -1
+	}
+	return utils.CompareInts(int(im.Code), int(im2.Code))
 }
 
 // String converts Contiv Rule (pointer) into a human-readable string
@@ -96,6 +230,13 @@ func (cr *ContivRule) String() string {
 	if cr.DestPort != 0 {
 		dstPort = strconv.Itoa(int(cr.DestPort))
 	}
+	if cr.HasDestPortRange() {
+		dstPort += "-" + strconv.Itoa(int(cr.DestPortEnd))
+	}
+	if cr.Protocol == ICMP {
+		return fmt.Sprintf("Rule <%s %s -> %s[%s:%s]>",
+			cr.Action, srcNet, dstNet, cr.Protocol, cr.ICMP)
+	}
 	return fmt.Sprintf("Rule <%s %s[%s:%s] -> %s[%s:%s]>",
 		cr.Action, srcNet, cr.Protocol, srcPort, dstNet, cr.Protocol, dstPort)
 }
@@ -104,6 +245,13 @@ func (cr *ContivRule) String() string {
 func (cr *ContivRule) Copy() *ContivRule {
 	crCopy := &ContivRule{}
 	*(crCopy) = *cr
+	if cr.ICMP != nil {
+		icmpCopy := *cr.ICMP
+		crCopy.ICMP = &icmpCopy
+	}
+	if cr.Policies != nil {
+		crCopy.Policies = append([]policymodel.ID{}, cr.Policies...)
+	}
 	return crCopy
 }
 
@@ -128,10 +276,14 @@ func (cr *ContivRule) Compare(cr2 *ContivRule) int {
 	if srcPortOrder != 0 {
 		return srcPortOrder
 	}
-	dstPortOrder := utils.ComparePorts(cr.DestPort, cr2.DestPort)
+	dstPortOrder := utils.ComparePortRanges(cr.DestPort, cr.DestPortEnd, cr2.DestPort, cr2.DestPortEnd)
 	if dstPortOrder != 0 {
 		return dstPortOrder
 	}
+	icmpOrder := cr.ICMP.Compare(cr2.ICMP)
+	if icmpOrder != 0 {
+		return icmpOrder
+	}
 	return utils.CompareInts(int(cr.Action), int(cr2.Action))
 }
 
@@ -157,7 +309,7 @@ func (at ActionType) String() string {
 	return "INVALID"
 }
 
-// ProtocolType is either TCP or UDP.
+// ProtocolType is one of TCP, UDP, ICMP, SCTP.
 type ProtocolType int
 
 const (
@@ -166,8 +318,17 @@ const (
 
 	// UDP protocol.
 	UDP
+
+	// ICMP protocol.
+	ICMP
+
+	// SCTP protocol.
+	SCTP
 )
 
+// Protocols lists all protocols supported by Contiv rules.
+var Protocols = []ProtocolType{TCP, UDP, ICMP, SCTP}
+
 // String converts ProtocolType into a human-readable string.
 func (at ProtocolType) String() string {
 	switch at {
@@ -175,6 +336,10 @@ func (at ProtocolType) String() string {
 		return "TCP"
 	case UDP:
 		return "UDP"
+	case ICMP:
+		return "ICMP"
+	case SCTP:
+		return "SCTP"
 	}
 	return "INVALID"
 }
@@ -512,22 +512,17 @@ func (rct *RendererCacheTxn) buildLocalTable(dstPodID podmodel.ID, dstPodCfg *Po
 
 	// Add explicit rules to allow traffic not matched by any rule.
 	if len(table.Rules) > 0 {
-		var allTCP, allUDP bool
+		allMatched := make(map[renderer.ProtocolType]bool)
 		for i := 0; i < table.NumOfRules; i++ {
-			if table.Rules[i].DestPort == 0 &&
+			if table.Rules[i].DestPort == 0 && table.Rules[i].ICMP == nil &&
 				len(table.Rules[i].SrcNetwork.IP) == 0 && len(table.Rules[i].DestNetwork.IP) == 0 {
-				if table.Rules[i].Protocol == renderer.TCP {
-					allTCP = true
-				} else {
-					allUDP = true
-				}
+				allMatched[table.Rules[i].Protocol] = true
 			}
 		}
-		if !allTCP {
-			table.InsertRule(rct.allowAllTCP())
-		}
-		if !allUDP {
-			table.InsertRule(rct.allowAllUDP())
+		for _, protocol := range renderer.Protocols {
+			if !allMatched[protocol] {
+				table.InsertRule(rct.allowAll(protocol))
+			}
 		}
 	}
 
@@ -542,31 +537,35 @@ func (rct *RendererCacheTxn) buildLocalTable(dstPodID podmodel.ID, dstPodCfg *Po
 // and the destination pod is maintained.
 func (rct *RendererCacheTxn) installLocalRules(dstTable *ContivRuleTable, dstPodCfg *PodConfig, srcPodCfg *PodConfig) {
 	// Determine the set of accessible ports from the source pod point of view.
-	var srcTCP, srcUDP Ports
+	var src allowedTraffic
 	if rct.cache.orientation == EgressOrientation {
-		srcTCP, srcUDP = getAllowedIngressPorts(dstPodCfg.PodIP, srcPodCfg.Ingress)
+		src = getAllowedIngressTraffic(dstPodCfg.PodIP, srcPodCfg.Ingress)
 	} else {
-		srcTCP, srcUDP = getAllowedEgressPorts(dstPodCfg.PodIP, srcPodCfg.Egress)
+		src = getAllowedEgressTraffic(dstPodCfg.PodIP, srcPodCfg.Egress)
 	}
 
 	// Determine the set of accessible ports from the destination pod point of view.
-	var dstTCP, dstUDP Ports
+	var dst allowedTraffic
 	if rct.cache.orientation == EgressOrientation {
-		dstTCP, dstUDP = getAllowedEgressPorts(srcPodCfg.PodIP, dstPodCfg.Egress)
+		dst = getAllowedEgressTraffic(srcPodCfg.PodIP, dstPodCfg.Egress)
 	} else {
-		dstTCP, dstUDP = getAllowedIngressPorts(srcPodCfg.PodIP, dstPodCfg.Ingress)
+		dst = getAllowedIngressTraffic(srcPodCfg.PodIP, dstPodCfg.Ingress)
 	}
 
-	// Intersect TCP ports
-	if !dstTCP.IsSubsetOf(srcTCP) {
-		allowedTCP := dstTCP.Intersection(srcTCP) /* intersection is certainly not AnyPort */
-		rct.installAllowedPorts(dstTable, srcPodCfg.PodIP, allowedTCP, renderer.TCP)
-	}
-
-	// Intersect UDP ports
-	if !dstUDP.IsSubsetOf(srcUDP) {
-		allowedUDP := dstUDP.Intersection(srcUDP) /* intersection is certainly not AnyPort */
-		rct.installAllowedPorts(dstTable, srcPodCfg.PodIP, allowedUDP, renderer.UDP)
+	for _, protocol := range renderer.Protocols {
+		if protocol == renderer.ICMP {
+			// Intersect ICMP messages
+			if !dst.icmp.IsSubsetOf(src.icmp) {
+				allowedICMP := dst.icmp.Intersection(src.icmp) /* intersection is certainly not all messages */
+				rct.installAllowedICMP(dstTable, srcPodCfg.PodIP, allowedICMP)
+			}
+			continue
+		}
+		// Intersect TCP/UDP/SCTP ports
+		if !dst.ports[protocol].IsSubsetOf(src.ports[protocol]) {
+			allowedPorts := dst.ports[protocol].Intersection(src.ports[protocol]) /* intersection is certainly not AnyPort */
+			rct.installAllowedPorts(dstTable, srcPodCfg.PodIP, allowedPorts, protocol)
+		}
 	}
 }
 
@@ -574,7 +573,42 @@ func (rct *RendererCacheTxn) installLocalRules(dstTable *ContivRuleTable, dstPod
 // be able to communicate with the table owner only on the selected allowed ports
 // of a given protocol with the rest being blocked.
 func (rct *RendererCacheTxn) installAllowedPorts(dstTable *ContivRuleTable, srcPodIP *net.IPNet, allowedPorts Ports, protocol renderer.ProtocolType) {
-	// cleanup TCP rule subtree with the root node:
+	var allowed []*renderer.ContivRule
+	for _, portRange := range allowedPorts.Ranges() {
+		rule := &renderer.ContivRule{
+			Action:   renderer.ActionPermit,
+			SrcPort:  AnyPort,
+			DestPort: portRange.First,
+			Protocol: protocol,
+		}
+		if portRange.Last > portRange.First && !portRange.IsAny() {
+			rule.DestPortEnd = portRange.Last
+		}
+		allowed = append(allowed, rule)
+	}
+	rct.installAllowedRules(dstTable, srcPodIP, protocol, allowed)
+}
+
+// installAllowedICMP modifies the table content such that the source pod will
+// be able to send only the selected ICMP messages to the table owner with
+// the rest being blocked.
+func (rct *RendererCacheTxn) installAllowedICMP(dstTable *ContivRuleTable, srcPodIP *net.IPNet, allowedICMP *ICMPMessages) {
+	var allowed []*renderer.ContivRule
+	for _, icmp := range allowedICMP.List() {
+		allowed = append(allowed, &renderer.ContivRule{
+			Action:   renderer.ActionPermit,
+			Protocol: renderer.ICMP,
+			ICMP:     icmp,
+		})
+	}
+	rct.installAllowedRules(dstTable, srcPodIP, renderer.ICMP, allowed)
+}
+
+// installAllowedRules replaces the rules of the given protocol between the source
+// pod and the table owner with the given permit rules followed by the
+// "deny-the-rest" rule. The L3 part of the allowed rules is filled in by this method.
+func (rct *RendererCacheTxn) installAllowedRules(dstTable *ContivRuleTable, srcPodIP *net.IPNet, protocol renderer.ProtocolType, allowed []*renderer.ContivRule) {
+	// cleanup rule subtree of the protocol with the root node:
 	// 	(egress orientation)  srcIP:0 -> 0/0:0
 	// 	(ingress orientation) 0/0:0   -> srcIP:0
 	dstTable.RemoveByPredicate(func(rule *renderer.ContivRule) bool {
@@ -597,17 +631,11 @@ func (rct *RendererCacheTxn) installAllowedPorts(dstTable *ContivRuleTable, srcP
 		return true
 	})
 
-	// Add explicit rule for each allowed port from the intersection
-	// of ingress with egress.
-	for port := range allowedPorts {
-		newRule := &renderer.ContivRule{
-			Action:      renderer.ActionPermit,
-			SrcNetwork:  &net.IPNet{},
-			DestNetwork: &net.IPNet{},
-			SrcPort:     AnyPort,
-			DestPort:    port,
-			Protocol:    protocol,
-		}
+	// Add explicit rule for each allowed port (or ICMP message) from
+	// the intersection of ingress with egress.
+	for _, newRule := range allowed {
+		newRule.SrcNetwork = &net.IPNet{}
+		newRule.DestNetwork = &net.IPNet{}
 		if rct.cache.orientation == EgressOrientation {
 			newRule.SrcNetwork = srcPodIP
 		} else {
@@ -647,8 +675,9 @@ func (rct *RendererCacheTxn) rebuildGlobalTable() {
 
 	if rct.globalTable.NumOfRules > 0 {
 		// Default action is to allow everything.
-		rct.globalTable.InsertRule(rct.allowAllTCP())
-		rct.globalTable.InsertRule(rct.allowAllUDP())
+		for _, protocol := range renderer.Protocols {
+			rct.globalTable.InsertRule(rct.allowAll(protocol))
+		}
 	}
 }
 
@@ -688,26 +717,15 @@ func (rct *RendererCacheTxn) generateTableID() string {
 	return id
 }
 
-func (rct *RendererCacheTxn) allowAllTCP() *renderer.ContivRule {
-	ruleTCPAny := &renderer.ContivRule{
+// allowAll returns rule allowing all the traffic of the given protocol.
+func (rct *RendererCacheTxn) allowAll(protocol renderer.ProtocolType) *renderer.ContivRule {
+	ruleAny := &renderer.ContivRule{
 		Action:      renderer.ActionPermit,
 		SrcNetwork:  &net.IPNet{},
 		DestNetwork: &net.IPNet{},
-		Protocol:    renderer.TCP,
-		SrcPort:     0,
-		DestPort:    0,
-	}
-	return ruleTCPAny
-}
-
-func (rct *RendererCacheTxn) allowAllUDP() *renderer.ContivRule {
-	ruleUDPAny := &renderer.ContivRule{
-		Action:      renderer.ActionPermit,
-		SrcNetwork:  &net.IPNet{},
-		DestNetwork: &net.IPNet{},
-		Protocol:    renderer.UDP,
+		Protocol:    protocol,
 		SrcPort:     0,
 		DestPort:    0,
 	}
-	return ruleUDPAny
+	return ruleAny
 }
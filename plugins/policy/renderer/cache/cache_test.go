@@ -191,7 +191,7 @@ func TestSingleEgressRuleOnePodEgressOrientation(t *testing.T) {
 
 	ingress := []*renderer.ContivRule{}
 	egress := []*renderer.ContivRule{Ts1.Rule}
-	localRules := []*renderer.ContivRule{Ts1.Rule, AllowAllTCP(), AllowAllUDP()}
+	localRules := []*renderer.ContivRule{Ts1.Rule, AllowAllTCP(), AllowAllUDP(), AllowAllICMP(), AllowAllSCTP()}
 	podCfg := &PodConfig{
 		PodIP:   GetOneHostSubnet(PodIPs[0]),
 		Ingress: ingress,
@@ -286,7 +286,7 @@ func TestSingleEgressRuleOnePodIngressOrientation(t *testing.T) {
 
 	// Expected global table content.
 	globalRules := modifyDst(Ts1.Rule, PodIPs[0])
-	globalRules = append(globalRules, AllowAllTCP(), AllowAllUDP())
+	globalRules = append(globalRules, AllowAllTCP(), AllowAllUDP(), AllowAllICMP(), AllowAllSCTP())
 
 	// Check initial cache content
 	globalTable := ruleCache.GetGlobalTable()
@@ -367,7 +367,7 @@ func TestSingleIngressRuleOnePodEgressOrientation(t *testing.T) {
 
 	// Expected global table content.
 	globalRules := modifySrc(PodIPs[0], Ts2.Rule)
-	globalRules = append(globalRules, AllowAllTCP(), AllowAllUDP()) /* order matters */
+	globalRules = append(globalRules, AllowAllTCP(), AllowAllUDP(), AllowAllICMP(), AllowAllSCTP()) /* order matters */
 
 	// Check initial cache content
 	globalTable := ruleCache.GetGlobalTable()
@@ -431,7 +431,7 @@ func TestSingleIngressRuleOnePodIngressOrientation(t *testing.T) {
 	pods := NewPodSet(Pod1)
 	ingress := []*renderer.ContivRule{Ts2.Rule}
 	egress := []*renderer.ContivRule{}
-	localRules := []*renderer.ContivRule{Ts2.Rule, AllowAllTCP(), AllowAllUDP()}
+	localRules := []*renderer.ContivRule{Ts2.Rule, AllowAllTCP(), AllowAllUDP(), AllowAllICMP(), AllowAllSCTP()}
 	podCfg := &PodConfig{
 		PodIP:   GetOneHostSubnet(PodIPs[0]),
 		Ingress: ingress,
@@ -510,7 +510,7 @@ func TestMultipleEgressRulesMultiplePodsEgressOrientation(t *testing.T) {
 
 	ingress := []*renderer.ContivRule{}
 	egress := []*renderer.ContivRule{Ts3.Rule1, Ts3.Rule2, Ts3.Rule3, Ts3.Rule4}
-	orderedEgress := []*renderer.ContivRule{Ts3.Rule1, Ts3.Rule3, Ts3.Rule2, Ts3.Rule4}
+	orderedEgress := []*renderer.ContivRule{Ts3.Rule1, Ts3.Rule3, Ts3.Rule2, Ts3.Rule4, AllowAllICMP(), AllowAllSCTP()}
 
 	podCfg := []*PodConfig{}
 	for i := range PodIDs {
@@ -639,7 +639,7 @@ func TestMultipleEgressRulesMultiplePodsIngressOrientation(t *testing.T) {
 	globalRules = append(globalRules, AllowAllTCP())
 	globalRules = append(globalRules, modifyDst(Ts3.Rule2, PodIPs[:3]...)...)
 	globalRules = append(globalRules, modifyDst(Ts3.Rule4, PodIPs[:3]...)...)
-	globalRules = append(globalRules, AllowAllUDP())
+	globalRules = append(globalRules, AllowAllUDP(), AllowAllICMP(), AllowAllSCTP())
 
 	// Run first transaction.
 	txn := ruleCache.NewTxn()
@@ -697,7 +697,7 @@ func TestMultipleEgressRulesMultiplePodsIngressOrientation(t *testing.T) {
 	globalRules = append(globalRules, AllowAllTCP())
 	globalRules = append(globalRules, modifyDst(Ts3.Rule2, PodIPs...)...)
 	globalRules = append(globalRules, modifyDst(Ts3.Rule4, PodIPs...)...)
-	globalRules = append(globalRules, AllowAllUDP())
+	globalRules = append(globalRules, AllowAllUDP(), AllowAllICMP(), AllowAllSCTP())
 
 	// Verify changes to be committed.
 	changes = txn.GetChanges()
@@ -768,7 +768,7 @@ func TestMultipleIngressRulesMultiplePodsEgressOrientation(t *testing.T) {
 	for i := 0; i < len(pods1); i++ {
 		globalRules = append(globalRules, modifySrc(PodIPs[i], Ts4.Rule2, Ts4.Rule4)...)
 	}
-	globalRules = append(globalRules, AllowAllUDP())
+	globalRules = append(globalRules, AllowAllUDP(), AllowAllICMP(), AllowAllSCTP())
 
 	// Run first transaction.
 	txn := ruleCache.NewTxn()
@@ -828,7 +828,7 @@ func TestMultipleIngressRulesMultiplePodsEgressOrientation(t *testing.T) {
 	for i := 0; i < len(pods2); i++ {
 		globalRules = append(globalRules, modifySrc(PodIPs[i], Ts4.Rule2, Ts4.Rule4)...)
 	}
-	globalRules = append(globalRules, AllowAllUDP())
+	globalRules = append(globalRules, AllowAllUDP(), AllowAllICMP(), AllowAllSCTP())
 
 	// Verify changes to be committed.
 	changes = txn.GetChanges()
@@ -862,7 +862,7 @@ func TestMultipleIngressRulesMultiplePodsIngressOrientation(t *testing.T) {
 	pods2 := NewPodSet(PodIDs...)     /* second TXN contains all pods */
 	ingress := []*renderer.ContivRule{Ts4.Rule1, Ts4.Rule2, Ts4.Rule3, Ts4.Rule4}
 	egress := []*renderer.ContivRule{}
-	orderedIngress := []*renderer.ContivRule{Ts4.Rule1, Ts4.Rule3, Ts4.Rule2, Ts4.Rule4}
+	orderedIngress := []*renderer.ContivRule{Ts4.Rule1, Ts4.Rule3, Ts4.Rule2, Ts4.Rule4, AllowAllICMP(), AllowAllSCTP()}
 
 	podCfg := []*PodConfig{}
 	for i := range PodIDs {
@@ -986,13 +986,15 @@ func TestCombinedRulesEgressOrientation(t *testing.T) {
 		AllowAllTCP(),
 		/* UDP: */ allowPodEgress(Pod1IP, 161, renderer.UDP), blockPodEgress(Pod1IP, renderer.UDP),
 		pod1Txn1Cfg.Egress[1] /* smaller subnet */, pod1Txn1Cfg.Egress[0],
-		AllowAllUDP(),
+		AllowAllUDP(), AllowAllICMP(), AllowAllSCTP(),
 	}
 	pod3LocalRules := []*renderer.ContivRule{
 		/* TCP: */ blockPodEgress(Pod1IP, renderer.TCP),
 		blockPodEgress(Pod3IP, renderer.TCP), Ts5.Pod3Egress[0], Ts5.Pod3Egress[1], Ts5.Pod3Egress[3],
 		/* UDP: */ blockPodEgress(Pod1IP, renderer.UDP), blockPodEgress(Pod3IP, renderer.UDP),
 		Ts5.Pod3Egress[2], Ts5.Pod3Egress[4],
+		/* ICMP: */ AllowAllICMP(),
+		/* SCTP: */ AllowAllSCTP(),
 	}
 	globalRules := []*renderer.ContivRule{}
 	/* TCP: */
@@ -1002,7 +1004,7 @@ func TestCombinedRulesEgressOrientation(t *testing.T) {
 	/* UDP: */
 	globalRules = append(globalRules, modifySrc(Pod1IP, pod1Txn1Cfg.Ingress[0], pod1Txn1Cfg.Ingress[2])...)
 	globalRules = append(globalRules, modifySrc(Pod3IP, pod3Cfg.Ingress[0], pod3Cfg.Ingress[3])...)
-	globalRules = append(globalRules, AllowAllUDP())
+	globalRules = append(globalRules, AllowAllUDP(), AllowAllICMP(), AllowAllSCTP())
 
 	// Create an instance of RendererCache
 	ruleCache := &RendererCache{
@@ -1062,6 +1064,8 @@ func TestCombinedRulesEgressOrientation(t *testing.T) {
 		/* TCP: */ pod1Txn2Cfg.Egress[2],
 		/* UDP: */ blockPodEgress(Pod1IP, renderer.UDP),
 		pod1Txn2Cfg.Egress[1] /* smaller subnet */, pod1Txn2Cfg.Egress[0], pod1Txn2Cfg.Egress[3],
+		/* ICMP: */ AllowAllICMP(),
+		/* SCTP: */ AllowAllSCTP(),
 	}
 	pod3LocalRulesTxn2 := []*renderer.ContivRule{
 		/* TCP: */ allowPodEgress(Pod1IP, 80, renderer.TCP), blockPodEgress(Pod1IP, renderer.TCP),
@@ -1069,6 +1073,8 @@ func TestCombinedRulesEgressOrientation(t *testing.T) {
 		Ts5.Pod3Egress[0], Ts5.Pod3Egress[1], Ts5.Pod3Egress[3],
 		/* UDP: */ blockPodEgress(Pod1IP, renderer.UDP), blockPodEgress(Pod3IP, renderer.UDP),
 		Ts5.Pod3Egress[2], Ts5.Pod3Egress[4],
+		/* ICMP: */ AllowAllICMP(),
+		/* SCTP: */ AllowAllSCTP(),
 	}
 	globalRulesTxn2 := []*renderer.ContivRule{}
 	/* TCP: */
@@ -1078,7 +1084,7 @@ func TestCombinedRulesEgressOrientation(t *testing.T) {
 	/* UDP: */
 	globalRulesTxn2 = append(globalRulesTxn2, modifySrc(Pod1IP, pod1Txn2Cfg.Ingress[1], pod1Txn2Cfg.Ingress[3])...)
 	globalRulesTxn2 = append(globalRulesTxn2, modifySrc(Pod3IP, pod3Cfg.Ingress[0], pod3Cfg.Ingress[3])...)
-	globalRulesTxn2 = append(globalRulesTxn2, AllowAllUDP())
+	globalRulesTxn2 = append(globalRulesTxn2, AllowAllUDP(), AllowAllICMP(), AllowAllSCTP())
 
 	// Verify changes to be committed.
 	changes = txn.GetChanges()
@@ -1165,11 +1171,15 @@ func TestCombinedRulesIngressOrientation(t *testing.T) {
 		/* TCP: */ pod1Txn1Cfg.Ingress[1],
 		/* UDP: */ blockPodIngress(Pod3IP, renderer.UDP),
 		pod1Txn1Cfg.Ingress[0], pod1Txn1Cfg.Ingress[2],
+		/* ICMP: */ AllowAllICMP(),
+		/* SCTP: */ AllowAllSCTP(),
 	}
 	pod3LocalRules := []*renderer.ContivRule{
 		/* TCP: */ blockPodIngress(Pod3IP, renderer.TCP),
 		pod3Cfg.Ingress[1], pod3Cfg.Ingress[2],
 		/* UDP: */ pod3Cfg.Ingress[0], pod3Cfg.Ingress[3],
+		/* ICMP: */ AllowAllICMP(),
+		/* SCTP: */ AllowAllSCTP(),
 	}
 	globalRules := []*renderer.ContivRule{}
 	/* TCP: */
@@ -1182,7 +1192,7 @@ func TestCombinedRulesIngressOrientation(t *testing.T) {
 	globalRules = append(globalRules, modifyDst(pod1Txn1Cfg.Egress[0], Pod1IP)...)
 	globalRules = append(globalRules, modifyDst(pod3Cfg.Egress[2], Pod3IP)...)
 	globalRules = append(globalRules, modifyDst(pod3Cfg.Egress[4], Pod3IP)...)
-	globalRules = append(globalRules, AllowAllUDP())
+	globalRules = append(globalRules, AllowAllUDP(), AllowAllICMP(), AllowAllSCTP())
 
 	// Create an instance of RendererCache
 	ruleCache := &RendererCache{
@@ -1243,12 +1253,16 @@ func TestCombinedRulesIngressOrientation(t *testing.T) {
 		pod1Txn2Cfg.Ingress[0], pod1Txn2Cfg.Ingress[2],
 		/* UDP: */ blockPodIngress(Pod1IP, renderer.UDP), blockPodIngress(Pod3IP, renderer.UDP),
 		pod1Txn2Cfg.Ingress[1], pod1Txn2Cfg.Ingress[3],
+		/* ICMP: */ AllowAllICMP(),
+		/* SCTP: */ AllowAllSCTP(),
 	}
 	pod3LocalRulesTxn2 := []*renderer.ContivRule{
 		/* TCP: */ blockPodIngress(Pod1IP, renderer.TCP), blockPodIngress(Pod3IP, renderer.TCP),
 		pod3Cfg.Ingress[1], pod3Cfg.Ingress[2],
 		/* UDP: */ allowPodIngress(Pod1IP, 53, renderer.UDP), blockPodIngress(Pod1IP, renderer.UDP),
 		/* removed: pod3Cfg.Ingress[0],*/ pod3Cfg.Ingress[3],
+		/* ICMP: */ AllowAllICMP(),
+		/* SCTP: */ AllowAllSCTP(),
 	}
 	globalRulesTxn2 := []*renderer.ContivRule{}
 	/* TCP: */
@@ -1263,7 +1277,7 @@ func TestCombinedRulesIngressOrientation(t *testing.T) {
 	globalRulesTxn2 = append(globalRulesTxn2, modifyDst(pod1Txn2Cfg.Egress[3], Pod1IP)...)
 	globalRulesTxn2 = append(globalRulesTxn2, modifyDst(pod3Cfg.Egress[2], Pod3IP)...)
 	globalRulesTxn2 = append(globalRulesTxn2, modifyDst(pod3Cfg.Egress[4], Pod3IP)...)
-	globalRulesTxn2 = append(globalRulesTxn2, AllowAllUDP())
+	globalRulesTxn2 = append(globalRulesTxn2, AllowAllUDP(), AllowAllICMP(), AllowAllSCTP())
 
 	// Verify changes to be committed.
 	changes = txn.GetChanges()
@@ -1330,7 +1344,7 @@ func TestRemovedPodsEgressOrientation(t *testing.T) {
 
 	ingress := []*renderer.ContivRule{}
 	egress := []*renderer.ContivRule{Ts3.Rule1, Ts3.Rule2, Ts3.Rule3, Ts3.Rule4}
-	orderedEgress := []*renderer.ContivRule{Ts3.Rule1, Ts3.Rule3, Ts3.Rule2, Ts3.Rule4}
+	orderedEgress := []*renderer.ContivRule{Ts3.Rule1, Ts3.Rule3, Ts3.Rule2, Ts3.Rule4, AllowAllICMP(), AllowAllSCTP()}
 
 	podCfg := []*PodConfig{}
 	for i := range PodIDs {
@@ -1476,7 +1490,7 @@ func TestRemovedPodsIngressOrientation(t *testing.T) {
 	globalRules = append(globalRules, AllowAllTCP())
 	globalRules = append(globalRules, modifyDst(Ts3.Rule2, PodIPs[:3]...)...)
 	globalRules = append(globalRules, modifyDst(Ts3.Rule4, PodIPs[:3]...)...)
-	globalRules = append(globalRules, AllowAllUDP())
+	globalRules = append(globalRules, AllowAllUDP(), AllowAllICMP(), AllowAllSCTP())
 
 	// Run first transaction.
 	txn := ruleCache.NewTxn()
@@ -1528,7 +1542,7 @@ func TestRemovedPodsIngressOrientation(t *testing.T) {
 	globalRules = append(globalRules, AllowAllTCP())
 	globalRules = append(globalRules, modifyDst(Ts3.Rule2, PodIPs[:2]...)...)
 	globalRules = append(globalRules, modifyDst(Ts3.Rule4, PodIPs[:2]...)...)
-	globalRules = append(globalRules, AllowAllUDP())
+	globalRules = append(globalRules, AllowAllUDP(), AllowAllICMP(), AllowAllSCTP())
 
 	// Verify changes to be committed.
 	changes = txn.GetChanges()
@@ -1595,6 +1609,8 @@ func TestResyncEgressOrientation(t *testing.T) {
 		allowPodEgress(Pod3IP, 22, renderer.TCP), blockPodEgress(Pod3IP, renderer.TCP),
 		/* UDP: */ allowPodEgress(Pod1IP, 161, renderer.UDP), blockPodEgress(Pod1IP, renderer.UDP),
 		pod1ResyncCfg.Egress[1] /* smaller subnet */, pod1ResyncCfg.Egress[0],
+		/* ICMP: */ AllowAllICMP(),
+		/* SCTP: */ AllowAllSCTP(),
 	}
 	pod1LocalTable := NewContivRuleTable("pod1-local")
 	pod1LocalTable.Pods.Add(Pod1)
@@ -1608,6 +1624,8 @@ func TestResyncEgressOrientation(t *testing.T) {
 		blockPodEgress(Pod3IP, renderer.TCP), Ts5.Pod3Egress[0], Ts5.Pod3Egress[1], Ts5.Pod3Egress[3],
 		/* UDP: */ blockPodEgress(Pod1IP, renderer.UDP), blockPodEgress(Pod3IP, renderer.UDP),
 		Ts5.Pod3Egress[2], Ts5.Pod3Egress[4],
+		/* ICMP: */ AllowAllICMP(),
+		/* SCTP: */ AllowAllSCTP(),
 	}
 	pod3LocalTable := NewContivRuleTable("pod3-local")
 	pod3LocalTable.Pods.Add(Pod3)
@@ -1624,7 +1642,7 @@ func TestResyncEgressOrientation(t *testing.T) {
 	/* UDP: */
 	globalRules = append(globalRules, modifySrc(Pod1IP, pod1ResyncCfg.Ingress[0], pod1ResyncCfg.Ingress[2])...)
 	globalRules = append(globalRules, modifySrc(Pod3IP, pod3Cfg.Ingress[0], pod3Cfg.Ingress[3])...)
-	globalRules = append(globalRules, AllowAllUDP())
+	globalRules = append(globalRules, AllowAllUDP(), AllowAllICMP(), AllowAllSCTP())
 	globalTable := NewContivRuleTable(GlobalTableID)
 	for _, rule := range globalRules {
 		globalTable.InsertRule(rule)
@@ -1654,6 +1672,8 @@ func TestResyncEgressOrientation(t *testing.T) {
 		/* TCP: */ pod1TxnCfg.Egress[2],
 		/* UDP: */ blockPodEgress(Pod1IP, renderer.UDP),
 		pod1TxnCfg.Egress[1] /* smaller subnet */, pod1TxnCfg.Egress[0], pod1TxnCfg.Egress[3],
+		/* ICMP: */ AllowAllICMP(),
+		/* SCTP: */ AllowAllSCTP(),
 	}
 	pod3LocalRulesTxn2 := []*renderer.ContivRule{
 		/* TCP: */ allowPodEgress(Pod1IP, 80, renderer.TCP), blockPodEgress(Pod1IP, renderer.TCP),
@@ -1661,6 +1681,8 @@ func TestResyncEgressOrientation(t *testing.T) {
 		Ts5.Pod3Egress[0], Ts5.Pod3Egress[1], Ts5.Pod3Egress[3],
 		/* UDP: */ blockPodEgress(Pod1IP, renderer.UDP), blockPodEgress(Pod3IP, renderer.UDP),
 		Ts5.Pod3Egress[2], Ts5.Pod3Egress[4],
+		/* ICMP: */ AllowAllICMP(),
+		/* SCTP: */ AllowAllSCTP(),
 	}
 	globalRulesTxn2 := []*renderer.ContivRule{}
 	/* TCP: */
@@ -1670,7 +1692,7 @@ func TestResyncEgressOrientation(t *testing.T) {
 	/* UDP: */
 	globalRulesTxn2 = append(globalRulesTxn2, modifySrc(Pod1IP, pod1TxnCfg.Ingress[1], pod1TxnCfg.Ingress[3])...)
 	globalRulesTxn2 = append(globalRulesTxn2, modifySrc(Pod3IP, pod3Cfg.Ingress[0], pod3Cfg.Ingress[3])...)
-	globalRulesTxn2 = append(globalRulesTxn2, AllowAllUDP())
+	globalRulesTxn2 = append(globalRulesTxn2, AllowAllUDP(), AllowAllICMP(), AllowAllSCTP())
 
 	// Verify changes to be committed.
 	changes := txn.GetChanges()
@@ -1758,6 +1780,8 @@ func TestResyncIngressOrientation(t *testing.T) {
 		/* TCP: */ pod1ResyncCfg.Ingress[1],
 		/* UDP: */ blockPodIngress(Pod3IP, renderer.UDP),
 		pod1ResyncCfg.Ingress[0], pod1ResyncCfg.Ingress[2],
+		/* ICMP: */ AllowAllICMP(),
+		/* SCTP: */ AllowAllSCTP(),
 	}
 	pod1LocalTable := NewContivRuleTable("pod1-local")
 	pod1LocalTable.Pods.Add(Pod1)
@@ -1770,6 +1794,8 @@ func TestResyncIngressOrientation(t *testing.T) {
 		/* TCP: */ blockPodIngress(Pod3IP, renderer.TCP),
 		pod3Cfg.Ingress[1], pod3Cfg.Ingress[2],
 		/* UDP: */ pod3Cfg.Ingress[0], pod3Cfg.Ingress[3],
+		/* ICMP: */ AllowAllICMP(),
+		/* SCTP: */ AllowAllSCTP(),
 	}
 	pod3LocalTable := NewContivRuleTable("pod3-local")
 	pod3LocalTable.Pods.Add(Pod3)
@@ -1789,7 +1815,7 @@ func TestResyncIngressOrientation(t *testing.T) {
 	globalRules = append(globalRules, modifyDst(pod1ResyncCfg.Egress[0], Pod1IP)...)
 	globalRules = append(globalRules, modifyDst(pod3Cfg.Egress[2], Pod3IP)...)
 	globalRules = append(globalRules, modifyDst(pod3Cfg.Egress[4], Pod3IP)...)
-	globalRules = append(globalRules, AllowAllUDP())
+	globalRules = append(globalRules, AllowAllUDP(), AllowAllICMP(), AllowAllSCTP())
 	globalTable := NewContivRuleTable(GlobalTableID)
 	for _, rule := range globalRules {
 		globalTable.InsertRule(rule)
@@ -1820,12 +1846,16 @@ func TestResyncIngressOrientation(t *testing.T) {
 		pod1TxnCfg.Ingress[0], pod1TxnCfg.Ingress[2],
 		/* UDP: */ blockPodIngress(Pod1IP, renderer.UDP), blockPodIngress(Pod3IP, renderer.UDP),
 		pod1TxnCfg.Ingress[1], pod1TxnCfg.Ingress[3],
+		/* ICMP: */ AllowAllICMP(),
+		/* SCTP: */ AllowAllSCTP(),
 	}
 	pod3LocalRulesTxn2 := []*renderer.ContivRule{
 		/* TCP: */ blockPodIngress(Pod1IP, renderer.TCP), blockPodIngress(Pod3IP, renderer.TCP),
 		pod3Cfg.Ingress[1], pod3Cfg.Ingress[2],
 		/* UDP: */ allowPodIngress(Pod1IP, 53, renderer.UDP), blockPodIngress(Pod1IP, renderer.UDP),
 		/* removed: pod3Cfg.Ingress[0],*/ pod3Cfg.Ingress[3],
+		/* ICMP: */ AllowAllICMP(),
+		/* SCTP: */ AllowAllSCTP(),
 	}
 	globalRulesTxn2 := []*renderer.ContivRule{}
 	/* TCP: */
@@ -1840,7 +1870,7 @@ func TestResyncIngressOrientation(t *testing.T) {
 	globalRulesTxn2 = append(globalRulesTxn2, modifyDst(pod1TxnCfg.Egress[3], Pod1IP)...)
 	globalRulesTxn2 = append(globalRulesTxn2, modifyDst(pod3Cfg.Egress[2], Pod3IP)...)
 	globalRulesTxn2 = append(globalRulesTxn2, modifyDst(pod3Cfg.Egress[4], Pod3IP)...)
-	globalRulesTxn2 = append(globalRulesTxn2, AllowAllUDP())
+	globalRulesTxn2 = append(globalRulesTxn2, AllowAllUDP(), AllowAllICMP(), AllowAllSCTP())
 
 	// Verify changes to be committed.
 	changes := txn.GetChanges()
@@ -16,17 +16,27 @@ package cache
 
 import (
 	"net"
+	"sort"
 
 	"fmt"
 	"github.com/contiv/vpp/plugins/policy/renderer"
 )
 
-// Ports is a set of port numbers.
-type Ports map[uint16]struct{}
+// PortRange is a range of port numbers <First, Last> (both included).
+type PortRange struct {
+	First uint16
+	Last  uint16
+}
+
+// Ports is a set of port numbers, stored as port ranges.
+type Ports map[PortRange]struct{}
 
 // AnyPort is a constant that represents any port.
 const AnyPort uint16 = 0
 
+// maxPortNum is the maximum possible port number.
+const maxPortNum = ^uint16(0)
+
 // NewPorts is a constructor for Ports.
 func NewPorts(portNums ...uint16) Ports {
 	ports := make(Ports)
@@ -36,33 +46,39 @@ func NewPorts(portNums ...uint16) Ports {
 	return ports
 }
 
-// Add port number into the set
+// Add port number into the set.
 func (p Ports) Add(port uint16) {
-	p[port] = struct{}{}
+	p.AddRange(port, port)
+}
+
+// AddRange adds all ports from the range <first, last> into the set.
+// <first> equal to AnyPort adds all ports, <last> lower than <first>
+// adds just the single port <first>.
+func (p Ports) AddRange(first, last uint16) {
+	p[newPortRange(first, last)] = struct{}{}
 }
 
 // Has returns true if the given port is in the set.
+// For AnyPort it is checked if all ports are in the set.
 func (p Ports) Has(port uint16) bool {
-	return p.HasExplicit(0) || p.HasExplicit(port)
+	return p.HasRange(port, port)
 }
 
-// HasExplicit returns true if the given port is in the set regardless of AnyPort
-// presence.
-func (p Ports) HasExplicit(port uint16) bool {
-	_, has := p[port]
-	return has
+// HasRange returns true if all ports from the range <first, last> are in the set.
+func (p Ports) HasRange(first, last uint16) bool {
+	pr := newPortRange(first, last)
+	for _, r := range p.Ranges() {
+		if r.First <= pr.First && pr.Last <= r.Last {
+			return true
+		}
+	}
+	return false
 }
 
 // IsSubsetOf returns true if this set is a subset of <p2>.
 func (p Ports) IsSubsetOf(p2 Ports) bool {
-	if p2.Has(AnyPort) {
-		return true
-	}
-	if p.Has(AnyPort) {
-		return false
-	}
-	for port := range p {
-		if !p2.Has(port) {
+	for _, r := range p.Ranges() {
+		if !p2.HasRange(r.First, r.Last) {
 			return false
 		}
 	}
@@ -71,91 +87,292 @@ func (p Ports) IsSubsetOf(p2 Ports) bool {
 
 // Intersection returns the set of ports which are both in this set and in <p2>.
 func (p Ports) Intersection(p2 Ports) Ports {
-	if p.Has(AnyPort) {
-		return p2
-	}
-	if p2.Has(AnyPort) {
-		return p
-	}
 	intersection := NewPorts()
-	for port := range p {
-		if p2.Has(port) {
-			intersection.Add(port)
+	ranges2 := p2.Ranges()
+	for _, r := range p.Ranges() {
+		for _, r2 := range ranges2 {
+			first, last := r.First, r.Last
+			if r2.First > first {
+				first = r2.First
+			}
+			if r2.Last < last {
+				last = r2.Last
+			}
+			if first <= last {
+				intersection[PortRange{First: first, Last: last}] = struct{}{}
+			}
 		}
 	}
 	return intersection
 }
 
+// Ranges returns the port ranges of the set, sorted and with overlapping
+// and adjacent ranges merged.
+func (p Ports) Ranges() []PortRange {
+	var ranges []PortRange
+	for r := range p {
+		ranges = append(ranges, r)
+	}
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].First < ranges[j].First
+	})
+	var merged []PortRange
+	for _, r := range ranges {
+		if len(merged) > 0 {
+			last := &merged[len(merged)-1]
+			if int(r.First) <= int(last.Last)+1 {
+				if r.Last > last.Last {
+					last.Last = r.Last
+				}
+				continue
+			}
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
 // String converts Ports into a human-readable string
 // representation.
 func (p Ports) String() string {
 	ports := "{"
-	count := 0
-	for port := range p {
-		ports += fmt.Sprintf("%d", port)
-		count++
-		if count < len(p) {
+	for idx, r := range p.Ranges() {
+		if idx > 0 {
 			ports += ","
 		}
+		ports += r.String()
 	}
 	ports += "}"
 	return ports
 }
 
-// getAllowedEgressPorts returns allowed destination UDP and TCP ports for a given
-// source pod IP wrt. egress rules.
-func getAllowedEgressPorts(srcIP *net.IPNet, egress []*renderer.ContivRule) (tcp, udp Ports) {
-	tcp = NewPorts()
-	udp = NewPorts()
-	hasDeny := false
+// newPortRange returns the range of ports <first, last>, where
+// <first> equal to AnyPort represents all ports and <last> lower than
+// <first> represents the single port <first>.
+func newPortRange(first, last uint16) PortRange {
+	if first == AnyPort {
+		return PortRange{First: AnyPort, Last: maxPortNum}
+	}
+	if last < first {
+		last = first
+	}
+	return PortRange{First: first, Last: last}
+}
+
+// IsAny returns true if the range covers all ports.
+func (pr PortRange) IsAny() bool {
+	return pr.First == AnyPort && pr.Last == maxPortNum
+}
+
+// String converts PortRange into a human-readable string
+// representation.
+func (pr PortRange) String() string {
+	if pr.IsAny() {
+		return "ANY"
+	}
+	if pr.First == pr.Last {
+		return fmt.Sprintf("%d", pr.First)
+	}
+	return fmt.Sprintf("%d-%d", pr.First, pr.Last)
+}
+
+// ICMPMessages is a set of ICMP message selectors.
+type ICMPMessages struct {
+	any     bool
+	matches map[renderer.ICMPMatch]struct{}
+}
+
+// NewICMPMessages is a constructor for ICMPMessages.
+// nil selects all ICMP messages.
+func NewICMPMessages(matches ...*renderer.ICMPMatch) *ICMPMessages {
+	messages := &ICMPMessages{matches: make(map[renderer.ICMPMatch]struct{})}
+	for _, match := range matches {
+		messages.Add(match)
+	}
+	return messages
+}
+
+// Add ICMP message selector into the set, nil selects all ICMP messages.
+func (m *ICMPMessages) Add(match *renderer.ICMPMatch) {
+	if match == nil {
+		m.any = true
+		return
+	}
+	key := *match
+	if key.AnyCode {
+		key.Code = 0
+	}
+	m.matches[key] = struct{}{}
+}
+
+// Has returns true if all the messages selected by <match> are in the set.
+func (m *ICMPMessages) Has(match *renderer.ICMPMatch) bool {
+	if m.any {
+		return true
+	}
+	for selected := range m.matches {
+		if selected.Covers(match) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSubsetOf returns true if this set is a subset of <m2>.
+func (m *ICMPMessages) IsSubsetOf(m2 *ICMPMessages) bool {
+	if m2.any {
+		return true
+	}
+	if m.any {
+		return false
+	}
+	for match := range m.matches {
+		if !m2.Has(&match) {
+			return false
+		}
+	}
+	return true
+}
+
+// Intersection returns the set of ICMP messages which are both in this set and in <m2>.
+func (m *ICMPMessages) Intersection(m2 *ICMPMessages) *ICMPMessages {
+	if m.any {
+		return m2
+	}
+	if m2.any {
+		return m
+	}
+	intersection := NewICMPMessages()
+	for match := range m.matches {
+		if m2.Has(&match) {
+			intersection.Add(&match)
+		}
+	}
+	for match := range m2.matches {
+		if m.Has(&match) {
+			intersection.Add(&match)
+		}
+	}
+	return intersection
+}
+
+// List returns the ICMP message selectors from the set.
+// All ICMP messages are represented by a single nil selector.
+func (m *ICMPMessages) List() (matches []*renderer.ICMPMatch) {
+	if m.any {
+		return []*renderer.ICMPMatch{nil}
+	}
+	for match := range m.matches {
+		matchCopy := match
+		matches = append(matches, &matchCopy)
+	}
+	return matches
+}
+
+// String converts ICMPMessages into a human-readable string
+// representation.
+func (m *ICMPMessages) String() string {
+	messages := "{"
+	for idx, match := range m.List() {
+		if idx > 0 {
+			messages += ","
+		}
+		messages += match.String()
+	}
+	messages += "}"
+	return messages
+}
+
+// allowedTraffic is the L4 traffic allowed by a list of rules.
+type allowedTraffic struct {
+	// Ports allowed for TCP, UDP and SCTP.
+	ports map[renderer.ProtocolType]Ports
+
+	// ICMP messages allowed.
+	icmp *ICMPMessages
+}
+
+// newAllowedTraffic returns an empty allowedTraffic (nothing allowed).
+func newAllowedTraffic() allowedTraffic {
+	allowed := allowedTraffic{
+		ports: make(map[renderer.ProtocolType]Ports),
+		icmp:  NewICMPMessages(),
+	}
+	for _, protocol := range renderer.Protocols {
+		if protocol == renderer.ICMP {
+			continue
+		}
+		allowed.ports[protocol] = NewPorts()
+	}
+	return allowed
+}
+
+// add adds the traffic matched by the given rule.
+func (at allowedTraffic) add(rule *renderer.ContivRule) {
+	if rule.Protocol == renderer.ICMP {
+		at.icmp.Add(rule.ICMP)
+	} else {
+		at.ports[rule.Protocol].AddRange(rule.DestPort, rule.DestPortEnd)
+	}
+}
+
+// allowAll marks all the traffic of the given protocol as allowed.
+func (at allowedTraffic) allowAll(protocol renderer.ProtocolType) {
+	if protocol == renderer.ICMP {
+		at.icmp.Add(nil)
+	} else {
+		at.ports[protocol].Add(AnyPort)
+	}
+}
+
+// getAllowedEgressTraffic returns allowed destination ports and ICMP messages
+// for a given source pod IP wrt. egress rules.
+func getAllowedEgressTraffic(srcIP *net.IPNet, egress []*renderer.ContivRule) allowedTraffic {
+	allowed := newAllowedTraffic()
+	hasDeny := make(map[renderer.ProtocolType]bool)
 	for _, rule := range egress {
 		if rule.Action == renderer.ActionDeny {
-			// This implementation assumes there is only the single default deny-all rule (for UDP&TCP),
+			// This implementation assumes there is only the single default deny-all rule (for each protocol),
 			// or no deny rule at all.
-			hasDeny = true
+			hasDeny[rule.Protocol] = true
 			continue
 		}
 		if len(rule.SrcNetwork.IP) > 0 && !rule.SrcNetwork.Contains(srcIP.IP) {
 			continue
 		}
 		/* matching ALLOW rule */
-		if rule.Protocol == renderer.TCP {
-			tcp.Add(rule.DestPort)
-		} else {
-			udp.Add(rule.DestPort)
-		}
+		allowed.add(rule)
 	}
-	if !hasDeny {
-		return NewPorts(AnyPort), NewPorts(AnyPort)
+	for _, protocol := range renderer.Protocols {
+		if !hasDeny[protocol] {
+			allowed.allowAll(protocol)
+		}
 	}
-	return tcp, udp
+	return allowed
 }
 
-// getAllowedIngressPorts returns allowed destination UDP and TCP ports for a given
-// destination pod IP wrt. ingress rules.
-func getAllowedIngressPorts(dstIP *net.IPNet, ingress []*renderer.ContivRule) (tcp, udp Ports) {
-	tcp = NewPorts()
-	udp = NewPorts()
-	hasDeny := false
+// getAllowedIngressTraffic returns allowed destination ports and ICMP messages
+// for a given destination pod IP wrt. ingress rules.
+func getAllowedIngressTraffic(dstIP *net.IPNet, ingress []*renderer.ContivRule) allowedTraffic {
+	allowed := newAllowedTraffic()
+	hasDeny := make(map[renderer.ProtocolType]bool)
 	for _, rule := range ingress {
 		if rule.Action == renderer.ActionDeny {
-			// This implementation assumes there is only the single default deny-all rule (for UDP&TCP),
+			// This implementation assumes there is only the single default deny-all rule (for each protocol),
 			// or no deny rule at all.
-			hasDeny = true
+			hasDeny[rule.Protocol] = true
 			continue
 		}
 		if len(rule.DestNetwork.IP) > 0 && !rule.DestNetwork.Contains(dstIP.IP) {
 			continue
 		}
 		/* matching ALLOW rule */
-		if rule.Protocol == renderer.TCP {
-			tcp.Add(rule.DestPort)
-		} else {
-			udp.Add(rule.DestPort)
-		}
+		allowed.add(rule)
 	}
-	if !hasDeny {
-		return NewPorts(AnyPort), NewPorts(AnyPort)
+	for _, protocol := range renderer.Protocols {
+		if !hasDeny[protocol] {
+			allowed.allowAll(protocol)
+		}
 	}
-	return tcp, udp
+	return allowed
 }
@@ -286,6 +286,30 @@ func AllowAllUDP() *renderer.ContivRule {
 	return ruleUDPAny
 }
 
+func AllowAllICMP() *renderer.ContivRule {
+	ruleICMPAny := &renderer.ContivRule{
+		Action:      renderer.ActionPermit,
+		SrcNetwork:  &net.IPNet{},
+		DestNetwork: &net.IPNet{},
+		Protocol:    renderer.ICMP,
+		SrcPort:     0,
+		DestPort:    0,
+	}
+	return ruleICMPAny
+}
+
+func AllowAllSCTP() *renderer.ContivRule {
+	ruleSCTPAny := &renderer.ContivRule{
+		Action:      renderer.ActionPermit,
+		SrcNetwork:  &net.IPNet{},
+		DestNetwork: &net.IPNet{},
+		Protocol:    renderer.SCTP,
+		SrcPort:     0,
+		DestPort:    0,
+	}
+	return ruleSCTPAny
+}
+
 func DenyAllTCP() *renderer.ContivRule {
 	ruleTCPNone := &renderer.ContivRule{
 		Action:      renderer.ActionDeny,
@@ -309,3 +333,27 @@ func DenyAllUDP() *renderer.ContivRule {
 	}
 	return ruleUDPNone
 }
+
+func DenyAllICMP() *renderer.ContivRule {
+	ruleICMPNone := &renderer.ContivRule{
+		Action:      renderer.ActionDeny,
+		SrcNetwork:  &net.IPNet{},
+		DestNetwork: &net.IPNet{},
+		Protocol:    renderer.ICMP,
+		SrcPort:     0,
+		DestPort:    0,
+	}
+	return ruleICMPNone
+}
+
+func DenyAllSCTP() *renderer.ContivRule {
+	ruleSCTPNone := &renderer.ContivRule{
+		Action:      renderer.ActionDeny,
+		SrcNetwork:  &net.IPNet{},
+		DestNetwork: &net.IPNet{},
+		Protocol:    renderer.SCTP,
+		SrcPort:     0,
+		DestPort:    0,
+	}
+	return ruleSCTPNone
+}
@@ -64,6 +64,11 @@ const (
 
 	// ProtoUDP is a constant used to set UDP protocol for a session rule.
 	ProtoUDP = 1
+
+	// MaxExpandedPortRange is the largest range of destination ports expanded
+	// into one session rule per port. Wider ranges cannot be allowed by session
+	// rules, the traffic is left to the subsequent deny rules.
+	MaxExpandedPortRange = 64
 )
 
 // SessionRule defines and groups the fields of a VPP session rule.
@@ -214,9 +219,14 @@ func ExportSessionRules(rules []*renderer.ContivRule, podID *podmodel.ID, podIP
 		}
 	}
 
-	for _, rule := range rules {
+	for _, rule := range expandPortRanges(rules, log) {
 		sessionRule := &SessionRule{}
 
+		if rule.Protocol != renderer.TCP && rule.Protocol != renderer.UDP {
+			/* session rules apply only to TCP and UDP */
+			continue
+		}
+
 		if rule.DestPort == 0 && rule.Action == renderer.ActionPermit &&
 			((global && len(rule.SrcNetwork.IP) == 0) || (!global && len(rule.DestNetwork.IP) == 0)) {
 			/* do not install allow-all destination rules - it is the default behaviour in the stack */
@@ -237,18 +247,34 @@ func ExportSessionRules(rules []*renderer.ContivRule, podID *podmodel.ID, podIP
 			sessionRule.TransportProto = ProtoTCP
 		case renderer.UDP:
 			sessionRule.TransportProto = ProtoUDP
-		default:
-			sessionRule.TransportProto = ProtoTCP
 		}
 
-		// Is IPv4?
-		if global &&
-			(len(rule.SrcNetwork.IP) == 0 || rule.SrcNetwork.IP.To4() != nil) {
-			sessionRule.IsIP4 = 1
+		if utils.HaveDifferentIPVersions(rule.SrcNetwork, rule.DestNetwork) {
+			/* rule between IPv4 and IPv6 network cannot match any traffic */
+			continue
 		}
-		if !global &&
-			(len(rule.DestNetwork.IP) == 0 || rule.DestNetwork.IP.To4() != nil) {
-			sessionRule.IsIP4 = 1
+
+		// Is IPv4?
+		// For rules without any IP address the version is undefined - such rules
+		// are installed for both IPv4 and IPv6.
+		var ipVerUndefined bool
+		if global {
+			ipNet := rule.SrcNetwork
+			if len(ipNet.IP) == 0 {
+				ipNet = rule.DestNetwork
+			}
+			ipVerUndefined = len(ipNet.IP) == 0
+			if ipVerUndefined || ipNet.IP.To4() != nil {
+				sessionRule.IsIP4 = 1
+			}
+		} else {
+			if len(rule.DestNetwork.IP) > 0 {
+				if rule.DestNetwork.IP.To4() != nil {
+					sessionRule.IsIP4 = 1
+				}
+			} else if podIP == nil || podIP.To4() != nil {
+				sessionRule.IsIP4 = 1
+			}
 		}
 
 		// Local IP
@@ -308,6 +334,7 @@ func ExportSessionRules(rules []*renderer.ContivRule, podID *podmodel.ID, podIP
 			sessionRule.Scope = ScopeLocal
 		}
 
+		ruleSessionRules := []*SessionRule{}
 		if (global && len(rule.SrcNetwork.IP) == 0) || (!global && len(rule.DestNetwork.IP) == 0) {
 			// Install deny-all as two rules with the all-IPs subnet split in half
 			// to avoid collisions with proxy rules.
@@ -315,21 +342,71 @@ func ExportSessionRules(rules []*renderer.ContivRule, podID *podmodel.ID, podIP
 			sessionRule2 := sessionRule.Copy()
 			// 1/1
 			copy(sessionRule.Tag[:], SessionRuleTagPrefix+SplitSessionRuleTag)
-			sessionRules = append(sessionRules, sessionRule)
+			ruleSessionRules = append(ruleSessionRules, sessionRule)
 			// 1/2
 			sessionRule2.RmtIP[0] = 1 << 7
 			copy(sessionRule2.Tag[:], SessionRuleTagPrefix+SplitSessionRuleTag)
-			sessionRules = append(sessionRules, sessionRule2)
+			ruleSessionRules = append(ruleSessionRules, sessionRule2)
 		} else {
 			// Tag
 			copy(sessionRule.Tag[:], SessionRuleTagPrefix)
 			// Add single rule into the list.
-			sessionRules = append(sessionRules, sessionRule)
+			ruleSessionRules = append(ruleSessionRules, sessionRule)
+		}
+		sessionRules = append(sessionRules, ruleSessionRules...)
+		if ipVerUndefined {
+			// Add IPv6 counterparts.
+			for _, sessionRule4 := range ruleSessionRules {
+				sessionRule6 := sessionRule4.Copy()
+				sessionRule6.IsIP4 = 0
+				sessionRules = append(sessionRules, sessionRule6)
+			}
 		}
 	}
 	return sessionRules
 }
 
+// expandPortRanges replaces every rule matching a range of destination ports
+// with one rule for each port from the range - session rules cannot match
+// port ranges. A range wider than MaxExpandedPortRange would explode into
+// thousands of rules, such rule fails closed: a permit rule is skipped (the traffic
+// is left to the subsequent deny rules), a deny rule is replaced with a single
+// rule matching all ports.
+func expandPortRanges(rules []*renderer.ContivRule, log logging.Logger) []*renderer.ContivRule {
+	expanded := []*renderer.ContivRule{}
+	for _, rule := range rules {
+		if !rule.HasDestPortRange() {
+			expanded = append(expanded, rule)
+			continue
+		}
+		if !IsExpandablePortRange(rule.DestPort, rule.DestPortEnd) {
+			if rule.Action == renderer.ActionPermit {
+				log.WithField("rule", rule).Errorf("Skipping rule with port range wider than %d ports "+
+					"- cannot be expanded into session rules, the traffic is denied", MaxExpandedPortRange)
+				continue
+			}
+			allPortsRule := rule.Copy()
+			allPortsRule.DestPort = 0
+			allPortsRule.DestPortEnd = 0
+			expanded = append(expanded, allPortsRule)
+			continue
+		}
+		for port := int(rule.DestPort); port <= int(rule.DestPortEnd); port++ {
+			portRule := rule.Copy()
+			portRule.DestPort = uint16(port)
+			portRule.DestPortEnd = 0
+			expanded = append(expanded, portRule)
+		}
+	}
+	return expanded
+}
+
+// IsExpandablePortRange returns true if the range of ports <first, last> is narrow enough
+// to be expanded into one session rule per port.
+func IsExpandablePortRange(first, last uint16) bool {
+	return int(last)-int(first)+1 <= MaxExpandedPortRange
+}
+
 // ImportSessionRules imports a list of session rules into a newly created
 // list of ContivRule tables, suitable for Resync with the cache.
 func ImportSessionRules(rules []*SessionRule, contiv contiv.API, log logging.Logger) (tables []*cache.ContivRuleTable) {
@@ -0,0 +1,136 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rule
+
+import (
+	"net"
+	"testing"
+
+	"github.com/onsi/gomega"
+
+	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/cn-infra/logging/logrus"
+
+	"github.com/contiv/vpp/plugins/policy/renderer"
+)
+
+func ipNetwork(addr string) *net.IPNet {
+	if addr == "" {
+		return &net.IPNet{}
+	}
+	_, network, err := net.ParseCIDR(addr)
+	gomega.Expect(err).To(gomega.BeNil())
+	return network
+}
+
+func TestExpandPortRanges(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestExpandPortRanges")
+
+	singlePort := &renderer.ContivRule{
+		Action:      renderer.ActionPermit,
+		SrcNetwork:  ipNetwork("192.168.1.0/24"),
+		DestNetwork: ipNetwork(""),
+		Protocol:    renderer.TCP,
+		DestPort:    80,
+	}
+	narrowRange := &renderer.ContivRule{
+		Action:      renderer.ActionPermit,
+		SrcNetwork:  ipNetwork("192.168.2.0/24"),
+		DestNetwork: ipNetwork(""),
+		Protocol:    renderer.TCP,
+		DestPort:    8080,
+		DestPortEnd: 8080 + MaxExpandedPortRange - 1,
+	}
+	widePermit := &renderer.ContivRule{
+		Action:      renderer.ActionPermit,
+		SrcNetwork:  ipNetwork("192.168.3.0/24"),
+		DestNetwork: ipNetwork(""),
+		Protocol:    renderer.TCP,
+		DestPort:    1000,
+		DestPortEnd: 2000,
+	}
+	wideDeny := &renderer.ContivRule{
+		Action:      renderer.ActionDeny,
+		SrcNetwork:  ipNetwork("192.168.4.0/24"),
+		DestNetwork: ipNetwork(""),
+		Protocol:    renderer.UDP,
+		DestPort:    1000,
+		DestPortEnd: 2000,
+	}
+
+	expanded := expandPortRanges([]*renderer.ContivRule{singlePort, narrowRange, widePermit, wideDeny}, logger)
+	gomega.Expect(expanded).To(gomega.HaveLen(1 + MaxExpandedPortRange + 1))
+
+	// rule without range is left as is
+	gomega.Expect(expanded[0]).To(gomega.Equal(singlePort))
+
+	// narrow range is expanded into one rule per port
+	for i := 0; i < MaxExpandedPortRange; i++ {
+		rule := expanded[1+i]
+		gomega.Expect(rule.Action).To(gomega.Equal(renderer.ActionPermit))
+		gomega.Expect(rule.SrcNetwork).To(gomega.Equal(narrowRange.SrcNetwork))
+		gomega.Expect(rule.DestPort).To(gomega.BeEquivalentTo(8080 + i))
+		gomega.Expect(rule.DestPortEnd).To(gomega.BeEquivalentTo(0))
+	}
+
+	// wide permit range fails closed - it is not allowed at all,
+	// wide deny range denies all ports
+	denyAll := expanded[len(expanded)-1]
+	gomega.Expect(denyAll.Action).To(gomega.Equal(renderer.ActionDeny))
+	gomega.Expect(denyAll.SrcNetwork).To(gomega.Equal(wideDeny.SrcNetwork))
+	gomega.Expect(denyAll.DestPort).To(gomega.BeEquivalentTo(0))
+	gomega.Expect(denyAll.DestPortEnd).To(gomega.BeEquivalentTo(0))
+	for _, rule := range expanded {
+		gomega.Expect(rule.SrcNetwork).ToNot(gomega.Equal(widePermit.SrcNetwork))
+	}
+}
+
+func TestExportWidePortRange(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestExportWidePortRange")
+
+	widePermit := &renderer.ContivRule{
+		Action:      renderer.ActionPermit,
+		SrcNetwork:  ipNetwork("192.168.3.0/24"),
+		DestNetwork: ipNetwork("10.1.1.1/32"),
+		Protocol:    renderer.TCP,
+		DestPort:    1000,
+		DestPortEnd: 2000,
+	}
+	denyAll := &renderer.ContivRule{
+		Action:      renderer.ActionDeny,
+		SrcNetwork:  ipNetwork("192.168.0.0/16"),
+		DestNetwork: ipNetwork("10.1.1.1/32"),
+		Protocol:    renderer.TCP,
+	}
+
+	// global table: only the deny rule is installed, the permit for the wide range
+	// must not turn into a rule allowing all ports
+	sessionRules := ExportSessionRules([]*renderer.ContivRule{widePermit, denyAll}, nil, nil, nil, logger)
+	gomega.Expect(sessionRules).To(gomega.HaveLen(1))
+	gomega.Expect(sessionRules[0].ActionIndex).To(gomega.BeEquivalentTo(ActionDeny))
+	gomega.Expect(sessionRules[0].LclPort).To(gomega.BeEquivalentTo(0))
+	for _, sessionRule := range sessionRules {
+		gomega.Expect(sessionRule.ActionIndex).ToNot(gomega.BeEquivalentTo(ActionAllow))
+	}
+
+	gomega.Expect(IsExpandablePortRange(1, MaxExpandedPortRange)).To(gomega.BeTrue())
+	gomega.Expect(IsExpandablePortRange(1, MaxExpandedPortRange+1)).To(gomega.BeFalse())
+}
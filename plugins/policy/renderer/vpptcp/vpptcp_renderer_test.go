@@ -357,12 +357,14 @@ func TestMultipleRulesMultiplePodsWithDataChange(t *testing.T) {
 
 	// Verify output
 	gomega.Expect(mockSessionRules.GetErrCount()).To(gomega.BeEquivalentTo(0))
-	gomega.Expect(mockSessionRules.GetReqCount()).To(gomega.BeEquivalentTo(8))
-	gomega.Expect(mockSessionRules.LocalTable(pod1VPPNsIndex).NumOfRules()).To(gomega.BeEquivalentTo(2))
+	gomega.Expect(mockSessionRules.GetReqCount()).To(gomega.BeEquivalentTo(10))
+	gomega.Expect(mockSessionRules.LocalTable(pod1VPPNsIndex).NumOfRules()).To(gomega.BeEquivalentTo(3))
 	gomega.Expect(mockSessionRules.LocalTable(pod1VPPNsIndex).HasRule("", 0, "10.0.0.0/8", 22, "TCP", "DENY")).To(gomega.BeTrue())
 	gomega.Expect(mockSessionRules.LocalTable(pod1VPPNsIndex).HasRule("", 0, "10.1.0.0/16", 80, "TCP", "DENY")).To(gomega.BeTrue())
-	gomega.Expect(mockSessionRules.LocalTable(pod2VPPNsIndex).NumOfRules()).To(gomega.BeEquivalentTo(1))
+	gomega.Expect(mockSessionRules.LocalTable(pod1VPPNsIndex).HasRule("", 0, pod2IP+"/32", 0, "UDP", "DENY")).To(gomega.BeTrue())
+	gomega.Expect(mockSessionRules.LocalTable(pod2VPPNsIndex).NumOfRules()).To(gomega.BeEquivalentTo(2))
 	gomega.Expect(mockSessionRules.LocalTable(pod2VPPNsIndex).HasRule("", 0, "10.0.0.0/8", 22, "TCP", "DENY")).To(gomega.BeTrue())
+	gomega.Expect(mockSessionRules.LocalTable(pod2VPPNsIndex).HasRule("", 0, pod1IP+"/32", 0, "UDP", "DENY")).To(gomega.BeTrue())
 	gomega.Expect(mockSessionRules.GlobalTable().NumOfRules()).To(gomega.BeEquivalentTo(5))
 	gomega.Expect(mockSessionRules.GlobalTable().HasRule(pod1IP, 23, "192.168.2.0/24", 0, "TCP", "ALLOW")).To(gomega.BeTrue())
 	gomega.Expect(mockSessionRules.GlobalTable().HasRule(pod1IP, 0, "0.0.0.0/1", 0, "UDP", "DENY")).To(gomega.BeTrue())
@@ -394,13 +396,14 @@ func TestMultipleRulesMultiplePodsWithDataChange(t *testing.T) {
 
 	// Verify output
 	gomega.Expect(mockSessionRules.GetErrCount()).To(gomega.BeEquivalentTo(0))
-	gomega.Expect(mockSessionRules.GetReqCount()).To(gomega.BeEquivalentTo(14))
+	gomega.Expect(mockSessionRules.GetReqCount()).To(gomega.BeEquivalentTo(17))
 	gomega.Expect(mockSessionRules.LocalTable(pod1VPPNsIndex).NumOfRules()).To(gomega.BeEquivalentTo(1))
 	gomega.Expect(mockSessionRules.LocalTable(pod1VPPNsIndex).HasRule("", 0, "10.0.0.0/8", 22, "TCP", "DENY")).To(gomega.BeTrue())
-	gomega.Expect(mockSessionRules.LocalTable(pod2VPPNsIndex).NumOfRules()).To(gomega.BeEquivalentTo(3))
+	gomega.Expect(mockSessionRules.LocalTable(pod2VPPNsIndex).NumOfRules()).To(gomega.BeEquivalentTo(4))
 	gomega.Expect(mockSessionRules.LocalTable(pod2VPPNsIndex).HasRule("", 0, "10.0.0.0/8", 22, "TCP", "DENY")).To(gomega.BeTrue())
 	gomega.Expect(mockSessionRules.LocalTable(pod2VPPNsIndex).HasRule("", 0, "0.0.0.0/1", 0, "TCP", "DENY")).To(gomega.BeTrue())
 	gomega.Expect(mockSessionRules.LocalTable(pod2VPPNsIndex).HasRule("", 0, "128.0.0.0/1", 0, "TCP", "DENY")).To(gomega.BeTrue())
+	gomega.Expect(mockSessionRules.LocalTable(pod2VPPNsIndex).HasRule("", 0, pod1IP+"/32", 0, "UDP", "DENY")).To(gomega.BeTrue())
 	gomega.Expect(mockSessionRules.GlobalTable().NumOfRules()).To(gomega.BeEquivalentTo(2))
 	gomega.Expect(mockSessionRules.GlobalTable().HasRule(pod1IP, 0, "0.0.0.0/1", 0, "UDP", "DENY")).To(gomega.BeTrue())
 	gomega.Expect(mockSessionRules.GlobalTable().HasRule(pod1IP, 0, "128.0.0.0/1", 0, "UDP", "DENY")).To(gomega.BeTrue())
@@ -491,12 +494,14 @@ func TestMultipleRulesMultiplePodsWithResync(t *testing.T) {
 
 	// Verify output
 	gomega.Expect(mockSessionRules.GetErrCount()).To(gomega.BeEquivalentTo(0))
-	gomega.Expect(mockSessionRules.GetReqCount()).To(gomega.BeEquivalentTo(8))
-	gomega.Expect(mockSessionRules.LocalTable(pod1VPPNsIndex).NumOfRules()).To(gomega.BeEquivalentTo(2))
+	gomega.Expect(mockSessionRules.GetReqCount()).To(gomega.BeEquivalentTo(10))
+	gomega.Expect(mockSessionRules.LocalTable(pod1VPPNsIndex).NumOfRules()).To(gomega.BeEquivalentTo(3))
 	gomega.Expect(mockSessionRules.LocalTable(pod1VPPNsIndex).HasRule("", 0, "10.0.0.0/8", 22, "TCP", "DENY")).To(gomega.BeTrue())
 	gomega.Expect(mockSessionRules.LocalTable(pod1VPPNsIndex).HasRule("", 0, "10.1.0.0/16", 80, "TCP", "DENY")).To(gomega.BeTrue())
-	gomega.Expect(mockSessionRules.LocalTable(pod2VPPNsIndex).NumOfRules()).To(gomega.BeEquivalentTo(1))
+	gomega.Expect(mockSessionRules.LocalTable(pod1VPPNsIndex).HasRule("", 0, pod2IP+"/32", 0, "UDP", "DENY")).To(gomega.BeTrue())
+	gomega.Expect(mockSessionRules.LocalTable(pod2VPPNsIndex).NumOfRules()).To(gomega.BeEquivalentTo(2))
 	gomega.Expect(mockSessionRules.LocalTable(pod2VPPNsIndex).HasRule("", 0, "10.0.0.0/8", 22, "TCP", "DENY")).To(gomega.BeTrue())
+	gomega.Expect(mockSessionRules.LocalTable(pod2VPPNsIndex).HasRule("", 0, pod1IP+"/32", 0, "UDP", "DENY")).To(gomega.BeTrue())
 	gomega.Expect(mockSessionRules.GlobalTable().NumOfRules()).To(gomega.BeEquivalentTo(5))
 	gomega.Expect(mockSessionRules.GlobalTable().HasRule(pod1IP, 23, "192.168.2.0/24", 0, "TCP", "ALLOW")).To(gomega.BeTrue())
 	gomega.Expect(mockSessionRules.GlobalTable().HasRule(pod1IP, 0, "0.0.0.0/1", 0, "UDP", "DENY")).To(gomega.BeTrue())
@@ -538,13 +543,14 @@ func TestMultipleRulesMultiplePodsWithResync(t *testing.T) {
 
 	// Verify output
 	gomega.Expect(mockSessionRules.GetErrCount()).To(gomega.BeEquivalentTo(0))
-	gomega.Expect(mockSessionRules.GetReqCount()).To(gomega.BeEquivalentTo(16))
+	gomega.Expect(mockSessionRules.GetReqCount()).To(gomega.BeEquivalentTo(19))
 	gomega.Expect(mockSessionRules.LocalTable(pod1VPPNsIndex).NumOfRules()).To(gomega.BeEquivalentTo(1))
 	gomega.Expect(mockSessionRules.LocalTable(pod1VPPNsIndex).HasRule("", 0, "10.0.0.0/8", 22, "TCP", "DENY")).To(gomega.BeTrue())
-	gomega.Expect(mockSessionRules.LocalTable(pod2VPPNsIndex).NumOfRules()).To(gomega.BeEquivalentTo(3))
+	gomega.Expect(mockSessionRules.LocalTable(pod2VPPNsIndex).NumOfRules()).To(gomega.BeEquivalentTo(4))
 	gomega.Expect(mockSessionRules.LocalTable(pod2VPPNsIndex).HasRule("", 0, "10.0.0.0/8", 22, "TCP", "DENY")).To(gomega.BeTrue())
 	gomega.Expect(mockSessionRules.LocalTable(pod2VPPNsIndex).HasRule("", 0, "0.0.0.0/1", 0, "TCP", "DENY")).To(gomega.BeTrue())
 	gomega.Expect(mockSessionRules.LocalTable(pod2VPPNsIndex).HasRule("", 0, "128.0.0.0/1", 0, "TCP", "DENY")).To(gomega.BeTrue())
+	gomega.Expect(mockSessionRules.LocalTable(pod2VPPNsIndex).HasRule("", 0, pod1IP+"/32", 0, "UDP", "DENY")).To(gomega.BeTrue())
 	gomega.Expect(mockSessionRules.GlobalTable().NumOfRules()).To(gomega.BeEquivalentTo(2))
 	gomega.Expect(mockSessionRules.GlobalTable().HasRule(pod1IP, 0, "0.0.0.0/1", 0, "UDP", "DENY")).To(gomega.BeTrue())
 	gomega.Expect(mockSessionRules.GlobalTable().HasRule(pod1IP, 0, "128.0.0.0/1", 0, "UDP", "DENY")).To(gomega.BeTrue())
@@ -679,3 +685,166 @@ func TestSinglePodWithResync(t *testing.T) {
 	gomega.Expect(mockSessionRules.GlobalTable().HasRule(pod1IP, 80, "192.168.2.0/24", 0, "TCP", "DENY")).To(gomega.BeTrue())
 	gomega.Expect(mockSessionRules.GlobalTable().HasRule(pod1IP, 0, "192.168.3.0/24", 0, "UDP", "ALLOW")).To(gomega.BeTrue())
 }
+
+func TestIPv6RulesSinglePod(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestIPv6RulesSinglePod")
+
+	// Prepare input data.
+	const (
+		namespace      = "default"
+		pod1Name       = "pod1"
+		pod1IP         = "2001:db8::1"
+		pod1VPPNsIndex = 10
+	)
+	pod1 := podmodel.ID{Name: pod1Name, Namespace: namespace}
+
+	inRule1 := &renderer.ContivRule{
+		Action:      renderer.ActionDeny,
+		SrcNetwork:  ipNetwork(""),
+		DestNetwork: ipNetwork("2001:db8:1::/48"),
+		Protocol:    renderer.TCP,
+		SrcPort:     0,
+		DestPort:    22,
+	}
+	inRule2 := &renderer.ContivRule{
+		Action:      renderer.ActionDeny,
+		SrcNetwork:  ipNetwork(""),
+		DestNetwork: ipNetwork(""),
+		Protocol:    renderer.TCP,
+		SrcPort:     0,
+		DestPort:    0,
+	}
+	egRule1 := &renderer.ContivRule{
+		Action:      renderer.ActionPermit,
+		SrcNetwork:  ipNetwork("2001:db8:2::/64"),
+		DestNetwork: ipNetwork(""),
+		Protocol:    renderer.TCP,
+		SrcPort:     0,
+		DestPort:    80,
+	}
+	egRule2 := &renderer.ContivRule{
+		Action:      renderer.ActionDeny,
+		SrcNetwork:  ipNetwork("192.168.2.0/24"), /* IPv4 cannot be matched with IPv6 pod */
+		DestNetwork: ipNetwork(""),
+		Protocol:    renderer.TCP,
+		SrcPort:     0,
+		DestPort:    8080,
+	}
+	egRule3 := &renderer.ContivRule{
+		Action:      renderer.ActionDeny,
+		SrcNetwork:  ipNetwork(""),
+		DestNetwork: ipNetwork(""),
+		Protocol:    renderer.UDP,
+		SrcPort:     0,
+		DestPort:    0,
+	}
+	ingress := []*renderer.ContivRule{inRule1, inRule2}
+	egress := []*renderer.ContivRule{egRule1, egRule2, egRule3}
+
+	// Prepare mocks.
+	contiv := NewMockContiv()
+	contiv.SetPodAppNsIndex(pod1, pod1VPPNsIndex)
+	mockSessionRules.Clear()
+	vppChan := mockSessionRules.NewVPPChan()
+	gomega.Expect(vppChan).ToNot(gomega.BeNil())
+
+	// Prepare VPPTCP Renderer.
+	vppTCPRenderer := &Renderer{
+		Deps: Deps{
+			Log:              logger,
+			Contiv:           contiv,
+			GoVPPChan:        vppChan,
+			GoVPPChanBufSize: 20,
+		},
+	}
+	vppTCPRenderer.Init()
+
+	// Execute Renderer transaction.
+	vppTCPRenderer.NewTxn(false).Render(pod1, GetOneHostSubnet(pod1IP), ingress, egress, false).Commit()
+
+	// Verify output
+	gomega.Expect(mockSessionRules.GetErrCount()).To(gomega.BeEquivalentTo(0))
+	gomega.Expect(mockSessionRules.LocalTable(pod1VPPNsIndex).NumOfRules()).To(gomega.BeEquivalentTo(3))
+	gomega.Expect(mockSessionRules.LocalTable(pod1VPPNsIndex).HasRule("", 0, "2001:db8:1::/48", 22, "TCP", "DENY")).To(gomega.BeTrue())
+	gomega.Expect(mockSessionRules.LocalTable(pod1VPPNsIndex).HasRule("", 0, "::/1", 0, "TCP", "DENY")).To(gomega.BeTrue())
+	gomega.Expect(mockSessionRules.LocalTable(pod1VPPNsIndex).HasRule("", 0, "8000::/1", 0, "TCP", "DENY")).To(gomega.BeTrue())
+	gomega.Expect(mockSessionRules.GlobalTable().NumOfRules()).To(gomega.BeEquivalentTo(3))
+	gomega.Expect(mockSessionRules.GlobalTable().HasRule(pod1IP, 80, "2001:db8:2::/64", 0, "TCP", "ALLOW")).To(gomega.BeTrue())
+	gomega.Expect(mockSessionRules.GlobalTable().HasRule(pod1IP, 0, "::/1", 0, "UDP", "DENY")).To(gomega.BeTrue())
+	gomega.Expect(mockSessionRules.GlobalTable().HasRule(pod1IP, 0, "8000::/1", 0, "UDP", "DENY")).To(gomega.BeTrue())
+}
+
+func TestPortRangesSinglePod(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestPortRangesSinglePod")
+
+	// Prepare input data.
+	const (
+		namespace      = "default"
+		pod1Name       = "pod1"
+		pod1IP         = "192.168.1.1"
+		pod1VPPNsIndex = 10
+	)
+	pod1 := podmodel.ID{Name: pod1Name, Namespace: namespace}
+
+	narrowRange := &renderer.ContivRule{
+		Action:      renderer.ActionPermit,
+		SrcNetwork:  ipNetwork("192.168.2.0/24"),
+		DestNetwork: ipNetwork(""),
+		Protocol:    renderer.TCP,
+		DestPort:    8080,
+		DestPortEnd: 8082,
+	}
+	wideRange := &renderer.ContivRule{
+		Action:      renderer.ActionPermit,
+		SrcNetwork:  ipNetwork("192.168.3.0/24"),
+		DestNetwork: ipNetwork(""),
+		Protocol:    renderer.TCP,
+		DestPort:    30000,
+		DestPortEnd: 32767,
+	}
+	denyAll := &renderer.ContivRule{
+		Action:      renderer.ActionDeny,
+		SrcNetwork:  ipNetwork("192.168.0.0/16"),
+		DestNetwork: ipNetwork(""),
+		Protocol:    renderer.TCP,
+	}
+	ingress := []*renderer.ContivRule{}
+	egress := []*renderer.ContivRule{narrowRange, wideRange, denyAll}
+
+	// Prepare mocks.
+	contiv := NewMockContiv()
+	contiv.SetPodAppNsIndex(pod1, pod1VPPNsIndex)
+	mockSessionRules.Clear()
+	vppChan := mockSessionRules.NewVPPChan()
+	gomega.Expect(vppChan).ToNot(gomega.BeNil())
+
+	// Prepare VPPTCP Renderer.
+	vppTCPRenderer := &Renderer{
+		Deps: Deps{
+			Log:              logger,
+			Contiv:           contiv,
+			GoVPPChan:        vppChan,
+			GoVPPChanBufSize: 20,
+		},
+	}
+	vppTCPRenderer.Init()
+
+	// Execute Renderer transaction.
+	vppTCPRenderer.NewTxn(false).Render(pod1, GetOneHostSubnet(pod1IP), ingress, egress, false).Commit()
+
+	// Verify output: the narrow range is expanded, the wide range is not allowed.
+	gomega.Expect(mockSessionRules.GetErrCount()).To(gomega.BeEquivalentTo(0))
+	gomega.Expect(mockSessionRules.LocalTable(pod1VPPNsIndex).NumOfRules()).To(gomega.BeEquivalentTo(0))
+	gomega.Expect(mockSessionRules.GlobalTable().NumOfRules()).To(gomega.BeEquivalentTo(4))
+	for port := uint16(8080); port <= 8082; port++ {
+		gomega.Expect(mockSessionRules.GlobalTable().HasRule(pod1IP, port, "192.168.2.0/24", 0, "TCP", "ALLOW")).To(gomega.BeTrue())
+	}
+	gomega.Expect(mockSessionRules.GlobalTable().HasRule(pod1IP, 0, "192.168.3.0/24", 0, "TCP", "ALLOW")).To(gomega.BeFalse())
+	gomega.Expect(mockSessionRules.GlobalTable().HasRule(pod1IP, 0, "192.168.0.0/16", 0, "TCP", "DENY")).To(gomega.BeTrue())
+}
@@ -184,6 +184,9 @@ func CompareInts(a, b int) int {
 
 // CompareIPNets returns -1, 0, 1 if a<b or a==b or a>b, respectively.
 // It hold that if *a* is subset of *b*, then a<b (and vice-versa).
+// Networks of different IP versions are ordered deterministically: IPv4
+// networks come before IPv6 networks, followed by 0/0 (undefined network)
+// which includes both.
 func CompareIPNets(a, b *net.IPNet) int {
 	// Handle 0/0
 	if len(a.IP) == 0 {
@@ -198,22 +201,13 @@ func CompareIPNets(a, b *net.IPNet) int {
 
 	// Normalize IP addresses.
 	// Order IPv4 before IPv6.
-	var aNorm, bNorm *net.IPNet
-	if a.IP.To4() != nil {
-		if b.IP.To4() == nil {
+	aNorm, aIsIPv4 := normalizeIPNet(a)
+	bNorm, bIsIPv4 := normalizeIPNet(b)
+	if aIsIPv4 != bIsIPv4 {
+		if aIsIPv4 {
 			return -1
 		}
-		aNorm = &net.IPNet{IP: a.IP.To4(), Mask: a.Mask}
-	} else {
-		aNorm = &net.IPNet{IP: a.IP.To16(), Mask: a.Mask}
-	}
-	if b.IP.To4() != nil {
-		if a.IP.To4() == nil {
-			return 1
-		}
-		bNorm = &net.IPNet{IP: b.IP.To4(), Mask: b.Mask}
-	} else {
-		bNorm = &net.IPNet{IP: b.IP.To16(), Mask: b.Mask}
+		return 1
 	}
 
 	// Compare common prefix
@@ -256,6 +250,30 @@ func ComparePorts(a, b uint16) int {
 	return 1
 }
 
+// ComparePortRanges is a comparison function for two port ranges <first, last>.
+// First=0 means "all-ports", last<=first means single port <first>.
+// Narrower ranges are lower in the order than wider ranges and ranges of the
+// same size are ordered by the first port. For single ports the order is
+// therefore the same as with ComparePorts.
+func ComparePortRanges(aFirst, aLast, bFirst, bLast uint16) int {
+	sizeOrder := CompareInts(portRangeSize(aFirst, aLast), portRangeSize(bFirst, bLast))
+	if sizeOrder != 0 {
+		return sizeOrder
+	}
+	return CompareInts(int(aFirst), int(bFirst))
+}
+
+// portRangeSize returns the number of ports in the range <first, last>.
+func portRangeSize(first, last uint16) int {
+	if first == 0 {
+		return int(^uint16(0)) + 1
+	}
+	if last <= first {
+		return 1
+	}
+	return int(last-first) + 1
+}
+
 // CompareIPNetsBytes returns an integer comparing two IP network addresses
 // represented as raw bytes lexicographically.
 func CompareIPNetsBytes(aPrefixLen uint8, aIP [16]byte, bPrefixLen uint8, bIP [16]byte) int {
@@ -266,6 +284,35 @@ func CompareIPNetsBytes(aPrefixLen uint8, aIP [16]byte, bPrefixLen uint8, bIP [1
 	return bytes.Compare(aIP[:], bIP[:])
 }
 
+// normalizeIPNet returns a copy of the given IP network with IP address and mask
+// represented with 4 bytes for IPv4 and 16 bytes for IPv6.
+// The second returned value is true for IPv4 networks.
+func normalizeIPNet(ipNet *net.IPNet) (norm *net.IPNet, isIPv4 bool) {
+	if ip4 := ipNet.IP.To4(); ip4 != nil {
+		mask := ipNet.Mask
+		if len(mask) == net.IPv6len {
+			mask = mask[net.IPv6len-net.IPv4len:]
+		}
+		return &net.IPNet{IP: ip4, Mask: mask}, true
+	}
+	return &net.IPNet{IP: ipNet.IP.To16(), Mask: ipNet.Mask}, false
+}
+
+// IsIPv6Net returns true if the given IP network is defined and it is
+// an IPv6 network.
+func IsIPv6Net(ipNet *net.IPNet) bool {
+	return ipNet != nil && len(ipNet.IP) > 0 && ipNet.IP.To4() == nil
+}
+
+// HaveDifferentIPVersions returns true if both IP networks are defined and one
+// of them is IPv4 while the other is IPv6 (i.e. they cannot be matched together).
+func HaveDifferentIPVersions(a, b *net.IPNet) bool {
+	if len(a.IP) == 0 || len(b.IP) == 0 {
+		return false
+	}
+	return IsIPv6Net(a) != IsIPv6Net(b)
+}
+
 // GetOneHostSubnet returns the IP subnet that contains only the given host
 // (i.e. /32 for IPv4, /128 for IPv6).
 func GetOneHostSubnet(hostAddr string) *net.IPNet {
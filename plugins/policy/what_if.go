@@ -0,0 +1,170 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/unrolled/render"
+
+	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
+	"github.com/contiv/vpp/plugins/policy/configurator"
+	"github.com/contiv/vpp/plugins/policy/model/whatif"
+	"github.com/contiv/vpp/plugins/policy/renderer"
+)
+
+// whatIfHandler returns the HTTP handler of the what-if REST API, evaluating
+// the installed policies against the connection described by the query parameters.
+func (p *Plugin) whatIfHandler(formatter *render.Render) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		// Policy Cache and Policy Configurator are accessed only with the lock
+		// held by the event processing.
+		p.resyncLock.Lock()
+		reply, err := p.whatIf(req.URL.Query())
+		p.resyncLock.Unlock()
+
+		if err != nil {
+			formatter.JSON(w, http.StatusBadRequest, &whatif.Reply{Error: err.Error()})
+			return
+		}
+		formatter.JSON(w, http.StatusOK, reply)
+	}
+}
+
+// whatIf evaluates the installed policies against the connection described
+// by the given query parameters.
+func (p *Plugin) whatIf(params url.Values) (*whatif.Reply, error) {
+	var err error
+	conn := configurator.Connection{}
+
+	// Source and destination.
+	conn.SrcIP, err = p.resolveEndpoint(params.Get(whatif.SrcParam))
+	if err != nil {
+		return nil, fmt.Errorf("invalid source: %v", err)
+	}
+	conn.DestIP, err = p.resolveEndpoint(params.Get(whatif.DstParam))
+	if err != nil {
+		return nil, fmt.Errorf("invalid destination: %v", err)
+	}
+
+	// Protocol.
+	switch strings.ToUpper(params.Get(whatif.ProtocolParam)) {
+	case "", "TCP":
+		conn.Protocol = renderer.TCP
+	case "UDP":
+		conn.Protocol = renderer.UDP
+	case "SCTP":
+		conn.Protocol = renderer.SCTP
+	case "ICMP":
+		conn.Protocol = renderer.ICMP
+	default:
+		return nil, fmt.Errorf("unsupported protocol: %s", params.Get(whatif.ProtocolParam))
+	}
+
+	// Port or ICMP type and code.
+	if conn.Protocol == renderer.ICMP {
+		icmpType, err := parseUint(params.Get(whatif.PortParam), 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ICMP type: %v", err)
+		}
+		icmpCode, err := parseUint(params.Get(whatif.CodeParam), 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ICMP code: %v", err)
+		}
+		conn.SrcPort, conn.DestPort = icmpType, icmpCode
+	} else {
+		conn.DestPort, err = parseUint(params.Get(whatif.PortParam), 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port: %v", err)
+		}
+	}
+
+	// Evaluate the policies.
+	evaluation := p.configurator.EvaluateTraffic(conn)
+	reply := &whatif.Reply{
+		Connection: conn.String(),
+		Verdict:    evaluation.Verdict.String(),
+	}
+	for _, podEvaluation := range evaluation.Pods {
+		podReply := whatif.PodEvaluation{
+			Pod:       podEvaluation.Pod.String(),
+			Direction: podEvaluation.Direction.String(),
+			Verdict:   podEvaluation.Verdict.String(),
+		}
+		if podEvaluation.Rule != nil {
+			podReply.Rule = podEvaluation.Rule.String()
+		}
+		for _, policy := range podEvaluation.Policies {
+			podReply.Policies = append(podReply.Policies, policy.String())
+		}
+		reply.Pods = append(reply.Pods, podReply)
+	}
+	if evaluation.Rendered != nil {
+		reply.Rendered = true
+		if evaluation.Rendered.Rule != nil {
+			reply.RenderedRule = evaluation.Rendered.Rule.String()
+		}
+		policyVerdict := configurator.TrafficAllowed
+		for _, podEvaluation := range evaluation.Pods {
+			if podEvaluation.Verdict == configurator.TrafficDenied {
+				policyVerdict = configurator.TrafficDenied
+			}
+		}
+		if policyVerdict != evaluation.Verdict {
+			reply.Warning = fmt.Sprintf("the policies evaluate the connection as %s, but the rendered rules "+
+				"as %s (some rules could not be rendered)", policyVerdict, evaluation.Verdict)
+		}
+	}
+	return reply, nil
+}
+
+// resolveEndpoint returns the IP address of the given endpoint of a connection,
+// selected either as pod (<namespace>/<name>) or directly by the IP address.
+func (p *Plugin) resolveEndpoint(endpoint string) (net.IP, error) {
+	if endpoint == "" {
+		return nil, fmt.Errorf("missing pod or IP address")
+	}
+	if ip := net.ParseIP(endpoint); ip != nil {
+		return ip, nil
+	}
+	podName := strings.Split(endpoint, "/")
+	if len(podName) != 2 {
+		return nil, fmt.Errorf("%s is neither IP address nor pod as <namespace>/<name>", endpoint)
+	}
+	podID := podmodel.ID{Namespace: podName[0], Name: podName[1]}
+	found, podData := p.policyCache.LookupPod(podID)
+	if !found {
+		return nil, fmt.Errorf("pod %s not found", podID)
+	}
+	ip := net.ParseIP(podData.IpAddress)
+	if ip == nil {
+		return nil, fmt.Errorf("pod %s has no IP address assigned", podID)
+	}
+	return ip, nil
+}
+
+// parseUint parses unsigned integer of the given bit size, empty string is parsed as 0.
+func parseUint(value string, bitSize int) (uint16, error) {
+	if value == "" {
+		return 0, nil
+	}
+	number, err := strconv.ParseUint(value, 10, bitSize)
+	return uint16(number), err
+}
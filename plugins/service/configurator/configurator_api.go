@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"net"
 
+	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
 	svcmodel "github.com/contiv/vpp/plugins/ksr/model/service"
 )
 
@@ -48,6 +49,17 @@ type ServiceConfiguratorAPI interface {
 	// changed list of all node IPs in the cluster.
 	UpdateNodePortServices(nodeIPs []net.IP, npServices []*ContivService) error
 
+	// AddPodHostPorts installs NAT rules exposing ports of a newly deployed pod
+	// on the IP addresses of this node.
+	AddPodHostPorts(hostPorts *PodHostPorts) error
+
+	// UpdatePodHostPorts reflects a change in the set of IP addresses on which
+	// the ports of a pod are exposed.
+	UpdatePodHostPorts(oldHostPorts, newHostPorts *PodHostPorts) error
+
+	// DeletePodHostPorts removes NAT rules exposing ports of a removed pod.
+	DeletePodHostPorts(hostPorts *PodHostPorts) error
+
 	// UpdateLocalFrontendIfs updates the list of interfaces connecting clients
 	// with VPP (enabled out2in VPP/NAT feature).
 	UpdateLocalFrontendIfs(oldIfNames, newIfNames Interfaces) error
@@ -56,6 +68,10 @@ type ServiceConfiguratorAPI interface {
 	// backends with VPP (enabled in2out VPP/NAT feature).
 	UpdateLocalBackendIfs(oldIfNames, newIfNames Interfaces) error
 
+	// UpdateExternalSNAT updates the configuration of SNAT, installed to allow
+	// access outside the cluster network.
+	UpdateExternalSNAT(externalSNAT ExternalSNATConfig) error
+
 	// Resync completely replaces the current NAT configuration with the provided
 	// full state of K8s services.
 	Resync(resyncEv *ResyncEventData) error
@@ -193,6 +209,56 @@ func (pt ProtocolType) String() string {
 	return "INVALID"
 }
 
+// PodHostPorts is a representation of K8s pod container ports with hostPort
+// defined. Each host port is exposed on the given IP addresses of this node
+// using NAT static mapping to the pod IP address and container port.
+// It is produced in this form and passed to Configurator by Service Processor.
+type PodHostPorts struct {
+	// ID uniquely identifies pod across all namespaces.
+	ID podmodel.ID
+
+	// IP address of the pod.
+	IP net.IP
+
+	// Ports is a list of all pod ports exposed on the host.
+	Ports []*HostPort
+}
+
+// HostPortLabelPrefix is the prefix of labels used for DNATs exposing host ports
+// of pods (labels of DNATs exposing services are service IDs, which never contain
+// more than one slash).
+const HostPortLabelPrefix = "hostport/"
+
+// Label returns label of the DNAT configuration exposing the host ports of the pod.
+func (php PodHostPorts) Label() string {
+	return HostPortLabelPrefix + php.ID.String()
+}
+
+// String converts PodHostPorts into a human-readable string.
+func (php PodHostPorts) String() string {
+	ports := ""
+	for idx, port := range php.Ports {
+		ports += port.String()
+		if idx < len(php.Ports)-1 {
+			ports += ", "
+		}
+	}
+	return fmt.Sprintf("PodHostPorts %s <IP:%s Ports:[%s]>", php.ID.String(), php.IP, ports)
+}
+
+// HostPort represents a single container port exposed on the host.
+type HostPort struct {
+	Protocol      ProtocolType /* protocol type */
+	HostIPs       *IPAddresses /* IP addresses of this node on which the port is exposed */
+	HostPort      uint16       /* port exposed on the host */
+	ContainerPort uint16       /* port on which the container listens */
+}
+
+// String converts HostPort into a human-readable string.
+func (hp HostPort) String() string {
+	return fmt.Sprintf("%s:%d->%d/%s", hp.HostIPs.String(), hp.HostPort, hp.ContainerPort, hp.Protocol.String())
+}
+
 // ServiceBackend represents a single service backend.
 type ServiceBackend struct {
 	IP    net.IP /* internal IP address of the backend */
@@ -341,6 +407,9 @@ type ResyncEventData struct {
 	// Services is a list of all currently deployed services.
 	Services []*ContivService
 
+	// HostPorts is a list of host ports of all pods deployed on this node.
+	HostPorts []*PodHostPorts
+
 	// FrontendIfs is a set of all interfaces connecting clients with VPP.
 	FrontendIfs Interfaces
 
@@ -353,6 +422,7 @@ func NewResyncEventData() *ResyncEventData {
 	return &ResyncEventData{
 		NodeIPs:     []net.IP{},
 		Services:    []*ContivService{},
+		HostPorts:   []*PodHostPorts{},
 		FrontendIfs: NewInterfaces(),
 		BackendIfs:  NewInterfaces(),
 	}
@@ -374,8 +444,15 @@ func (red ResyncEventData) String() string {
 			services += ", "
 		}
 	}
-	return fmt.Sprintf("ResyncEventData <NodeIPs:[%s] %s Services:[%s], FrontendIfs:%s BackendIfs:%s>",
-		nodeIPs, red.ExternalSNAT.String(), services, red.FrontendIfs.String(),
+	hostPorts := ""
+	for idx, podHostPorts := range red.HostPorts {
+		hostPorts += podHostPorts.String()
+		if idx < len(red.HostPorts)-1 {
+			hostPorts += ", "
+		}
+	}
+	return fmt.Sprintf("ResyncEventData <NodeIPs:[%s] %s Services:[%s], HostPorts:[%s], FrontendIfs:%s BackendIfs:%s>",
+		nodeIPs, red.ExternalSNAT.String(), services, hostPorts, red.FrontendIfs.String(),
 		red.BackendIfs.String())
 }
 
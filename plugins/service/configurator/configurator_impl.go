@@ -132,6 +132,55 @@ func (sc *ServiceConfigurator) UpdateNodePortServices(nodeIPs []net.IP, npServic
 	return nil
 }
 
+// AddPodHostPorts installs NAT rules exposing ports of a newly deployed pod
+// on the IP addresses of this node.
+func (sc *ServiceConfigurator) AddPodHostPorts(hostPorts *PodHostPorts) error {
+	dnat := sc.podHostPortsToDNat(hostPorts)
+	sc.Log.WithFields(logging.Fields{
+		"hostPorts": hostPorts,
+		"DNAT":      dnat,
+	}).Debug("ServiceConfigurator - AddPodHostPorts()")
+
+	// Configure DNAT via ligato/vpp-agent.
+	dsl := sc.NATTxnFactory()
+	putDsl := dsl.Put()
+	putDsl.NAT44DNat(dnat)
+
+	return dsl.Send().ReceiveReply()
+}
+
+// UpdatePodHostPorts reflects a change in the set of IP addresses on which
+// the ports of a pod are exposed.
+func (sc *ServiceConfigurator) UpdatePodHostPorts(oldHostPorts, newHostPorts *PodHostPorts) error {
+	newDNAT := sc.podHostPortsToDNat(newHostPorts)
+	sc.Log.WithFields(logging.Fields{
+		"oldHostPorts": oldHostPorts,
+		"newHostPorts": newHostPorts,
+		"newDNAT":      newDNAT,
+	}).Debug("ServiceConfigurator - UpdatePodHostPorts()")
+
+	// Update DNAT via ligato/vpp-agent.
+	dsl := sc.NATTxnFactory()
+	putDsl := dsl.Put()
+	putDsl.NAT44DNat(newDNAT)
+
+	return dsl.Send().ReceiveReply()
+}
+
+// DeletePodHostPorts removes NAT rules exposing ports of a removed pod.
+func (sc *ServiceConfigurator) DeletePodHostPorts(hostPorts *PodHostPorts) error {
+	sc.Log.WithFields(logging.Fields{
+		"hostPorts": hostPorts,
+	}).Debug("ServiceConfigurator - DeletePodHostPorts()")
+
+	// Delete DNAT via ligato/vpp-agent.
+	dsl := sc.NATTxnFactory()
+	deleteDsl := dsl.Delete()
+	deleteDsl.NAT44DNat(hostPorts.Label())
+
+	return dsl.Send().ReceiveReply()
+}
+
 // UpdateLocalFrontendIfs updates the list of interfaces connecting clients
 // with VPP (enabled out2in VPP/NAT feature).
 func (sc *ServiceConfigurator) UpdateLocalFrontendIfs(oldIfNames, newIfNames Interfaces) error {
@@ -206,6 +255,49 @@ func (sc *ServiceConfigurator) UpdateLocalBackendIfs(oldIfNames, newIfNames Inte
 	return dsl.Send().ReceiveReply()
 }
 
+// UpdateExternalSNAT updates the configuration of SNAT, installed to allow
+// access outside the cluster network.
+func (sc *ServiceConfigurator) UpdateExternalSNAT(externalSNAT ExternalSNATConfig) error {
+	sc.Log.WithFields(logging.Fields{
+		"externalSNAT": externalSNAT,
+	}).Debug("ServiceConfigurator - UpdateExternalSNAT()")
+
+	// Re-build the address pool and the post-routing interface.
+	sc.natGlobalCfg = proto.Clone(sc.natGlobalCfg).(*nat.Nat44Global)
+	sc.natGlobalCfg.AddressPools = nil
+	if externalSNAT.ExternalIP != nil {
+		sc.natGlobalCfg.AddressPools = append(sc.natGlobalCfg.AddressPools,
+			&nat.Nat44Global_AddressPools{
+				FirstSrcAddress: externalSNAT.ExternalIP.String(),
+				VrfId:           ^uint32(0),
+			})
+	}
+	// - keep non-post-routing interfaces unchanged
+	newNatIfs := []*nat.Nat44Global_NatInterfaces{}
+	for _, natIf := range sc.natGlobalCfg.NatInterfaces {
+		if !natIf.OutputFeature {
+			newNatIfs = append(newNatIfs, natIf)
+		}
+	}
+	if externalSNAT.ExternalIfName != "" {
+		newNatIfs = append(newNatIfs,
+			&nat.Nat44Global_NatInterfaces{
+				Name:          externalSNAT.ExternalIfName,
+				IsInside:      false,
+				OutputFeature: true,
+			})
+	}
+	// - re-write the cached list
+	sc.natGlobalCfg.NatInterfaces = newNatIfs
+
+	// Update global NAT config via ligato/vpp-agent.
+	dsl := sc.NATTxnFactory()
+	putDsl := dsl.Put()
+	putDsl.NAT44Global(sc.natGlobalCfg)
+
+	return dsl.Send().ReceiveReply()
+}
+
 // Resync completely replaces the current NAT configuration with the provided
 // full state of K8s services.
 func (sc *ServiceConfigurator) Resync(resyncEv *ResyncEventData) error {
@@ -238,6 +330,12 @@ func (sc *ServiceConfigurator) Resync(resyncEv *ResyncEventData) error {
 				break
 			}
 		}
+		for _, hostPorts := range resyncEv.HostPorts {
+			if hostPorts.Label() == dnatConfig.Label {
+				removed = false
+				break
+			}
+		}
 		if removed {
 			deleteDsl.NAT44DNat(dnatConfig.Label)
 		}
@@ -247,6 +345,10 @@ func (sc *ServiceConfigurator) Resync(resyncEv *ResyncEventData) error {
 		dnat := sc.contivServiceToDNat(service)
 		putDsl.NAT44DNat(dnat)
 	}
+	for _, hostPorts := range resyncEv.HostPorts {
+		dnat := sc.podHostPortsToDNat(hostPorts)
+		putDsl.NAT44DNat(dnat)
+	}
 
 	// Re-build the global NAT config.
 	sc.natGlobalCfg = &nat.Nat44Global{
@@ -401,6 +503,48 @@ func (sc *ServiceConfigurator) exportDNATMappings(service *ContivService) []*nat
 	return mappings
 }
 
+// podHostPortsToDNat returns DNAT configuration exposing host ports of a given pod.
+func (sc *ServiceConfigurator) podHostPortsToDNat(hostPorts *PodHostPorts) *nat.Nat44DNat_DNatConfig {
+	dnat := &nat.Nat44DNat_DNatConfig{}
+	dnat.Label = hostPorts.Label()
+	dnat.StMappings = sc.exportHostPortMappings(hostPorts)
+	return dnat
+}
+
+// exportHostPortMappings exports the corresponding list of D-NAT mappings from pod host ports.
+func (sc *ServiceConfigurator) exportHostPortMappings(hostPorts *PodHostPorts) []*nat.Nat44DNat_DNatConfig_StaticMappings {
+	mappings := []*nat.Nat44DNat_DNatConfig_StaticMappings{}
+
+	// Add one mapping for each host IP of each port.
+	for _, port := range hostPorts.Ports {
+		for _, hostIP := range port.HostIPs.List() {
+			if hostIP.To4() != nil {
+				hostIP = hostIP.To4()
+			}
+			mapping := &nat.Nat44DNat_DNatConfig_StaticMappings{}
+			mapping.ExternalIP = hostIP.String()
+			mapping.ExternalPort = uint32(port.HostPort)
+			switch port.Protocol {
+			case TCP:
+				mapping.Protocol = nat.Protocol_TCP
+			case UDP:
+				mapping.Protocol = nat.Protocol_UDP
+			}
+			// Single "backend" - use "1" to represent the probability (not really configured).
+			mapping.LocalIps = []*nat.Nat44DNat_DNatConfig_StaticMappings_LocalIPs{
+				{
+					LocalIP:     hostPorts.IP.String(),
+					LocalPort:   uint32(port.ContainerPort),
+					Probability: 1,
+				},
+			}
+			mappings = append(mappings, mapping)
+		}
+	}
+
+	return mappings
+}
+
 // Close deallocates resources held by the configurator.
 func (sc *ServiceConfigurator) Close() error {
 	return nil
@@ -54,11 +54,17 @@
 //         * the set of physical interfaces is learned from the Contiv plugin
 //         * Contiv plugin is also used to convert pod IDs to their associated
 //           interfaces
+//     - collects host ports of the pods deployed on this node (container ports
+//       with hostPort set) and exposes them on the IP addresses of the node
+//       (or on the hostIP, if specified); pods with host ports are treated
+//       as backends
 //
 //  3. Service Configurator
 //     - until we have NAT44 supported in the vpp-agent, the configurator
 //       installs the configuration directly via VPP/NAT plugin binary API
 //     - translates ContivService into the corresponding NAT configuration
+//     - translates host ports of pods into NAT static mappings, labeled
+//       with "hostport/<namespace>/<pod-name>"
 //     - applies out2in and in2out VPP/NAT's features on interfaces connecting
 //       frontends and backends, respectivelly
 //     - for each change, calculates the minimal diff, i.e. the smallest set
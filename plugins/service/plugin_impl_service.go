@@ -46,6 +46,9 @@ type Plugin struct {
 	resyncChan chan datasync.ResyncEvent
 	changeChan chan datasync.ChangeEvent
 
+	// nodeConfigChan receives notifications about the changes of the node configuration
+	nodeConfigChan chan struct{}
+
 	watchConfigReg datasync.WatchRegistration
 
 	resyncLock sync.Mutex
@@ -57,6 +60,9 @@ type Plugin struct {
 	pendingResync  datasync.ResyncEvent
 	pendingChanges []datasync.ChangeEvent
 
+	// resynced is set once the first resync has been applied.
+	resynced bool
+
 	processor    *processor.ServiceProcessor
 	configurator *configurator.ServiceConfigurator
 }
@@ -77,6 +83,7 @@ func (p *Plugin) Init() error {
 
 	p.resyncChan = make(chan datasync.ResyncEvent)
 	p.changeChan = make(chan datasync.ChangeEvent)
+	p.nodeConfigChan = make(chan struct{}, 1)
 
 	p.configurator = &configurator.ServiceConfigurator{
 		Deps: configurator.Deps{
@@ -106,6 +113,7 @@ func (p *Plugin) Init() error {
 	p.ctx, p.cancel = context.WithCancel(context.Background())
 
 	go p.watchEvents()
+	p.Contiv.WatchNodeConfig(p.nodeConfigChan)
 	err = p.subscribeWatcher()
 	if err != nil {
 		return err
@@ -158,6 +166,17 @@ func (p *Plugin) watchEvents() {
 			}
 			p.resyncLock.Unlock()
 
+		case <-p.nodeConfigChan:
+			p.resyncLock.Lock()
+			// delayed resync reads the new node configuration
+			if p.resynced && p.pendingResync == nil {
+				err := p.processor.ReconfigureNodeConfig()
+				if err != nil {
+					p.Log.Error(err)
+				}
+			}
+			p.resyncLock.Unlock()
+
 		case <-p.ctx.Done():
 			p.Log.Debug("Stop watching events")
 			return
@@ -191,6 +210,7 @@ func (p *Plugin) handleResync(resyncChan chan resync.StatusEvent) {
 					}
 					p.pendingResync = nil
 					p.pendingChanges = []datasync.ChangeEvent{}
+					p.resynced = true
 				}
 				p.resyncLock.Unlock()
 			}
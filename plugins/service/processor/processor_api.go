@@ -44,4 +44,9 @@ type ServiceProcessorAPI interface {
 	// The cache content is fully replaced and the configurator receives a full
 	// snapshot of Contiv Services at the present state to be (re)installed.
 	Resync(resyncEv datasync.ResyncEvent) error
+
+	// ReconfigureNodeConfig reconfigures everything that depends on the configuration
+	// of this node (SNAT of the traffic leaving the cluster network and the set of
+	// physical interfaces connecting clients with VPP) once it has changed at runtime.
+	ReconfigureNodeConfig() error
 }
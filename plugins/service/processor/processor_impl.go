@@ -45,9 +45,17 @@ type ServiceProcessor struct {
 	services map[svcmodel.ID]*Service
 	localEps map[podmodel.ID]*LocalEndpoint
 
+	/* local pods with host ports */
+	hostPortPods map[podmodel.ID]*podmodel.Pod
+	hostPorts    map[podmodel.ID]*configurator.PodHostPorts
+
 	/* local frontend and backend interfaces */
 	frontendIfs configurator.Interfaces
 	backendIfs  configurator.Interfaces
+
+	/* physical frontend interfaces and SNAT of the traffic leaving the cluster */
+	physFrontendIfs configurator.Interfaces
+	externalSNAT    configurator.ExternalSNATConfig
 }
 
 // Deps lists dependencies of ServiceProcessor.
@@ -62,7 +70,7 @@ type Deps struct {
 // LocalEndpoint represents a node-local endpoint.
 type LocalEndpoint struct {
 	ifName   string
-	svcCount int /* number of services running on this endpoint. */
+	svcCount int /* number of services running on this endpoint (pod with host ports counts as one). */
 }
 
 // Init initializes service processor.
@@ -77,8 +85,12 @@ func (sp *ServiceProcessor) reset() error {
 	sp.nodes = make(map[int]*nodemodel.NodeInfo)
 	sp.services = make(map[svcmodel.ID]*Service)
 	sp.localEps = make(map[podmodel.ID]*LocalEndpoint)
+	sp.hostPortPods = make(map[podmodel.ID]*podmodel.Pod)
+	sp.hostPorts = make(map[podmodel.ID]*configurator.PodHostPorts)
 	sp.frontendIfs = configurator.NewInterfaces()
 	sp.backendIfs = configurator.NewInterfaces()
+	sp.physFrontendIfs = configurator.NewInterfaces()
+	sp.externalSNAT = configurator.ExternalSNATConfig{}
 	return nil
 }
 
@@ -109,7 +121,7 @@ func (sp *ServiceProcessor) processUpdatedPod(pod *podmodel.Pod) error {
 		return nil
 	}
 	podIPAddress := net.ParseIP(pod.IpAddress)
-	if podIPAddress == nil || !sp.Contiv.GetPodNetwork().Contains(podIPAddress) {
+	if podIPAddress == nil || !sp.Contiv.IsLocalPodIP(podIPAddress) {
 		/* ignore pods deployed on other nodes */
 		return nil
 	}
@@ -130,6 +142,11 @@ func (sp *ServiceProcessor) processUpdatedPod(pod *podmodel.Pod) error {
 	}
 
 	localEp.ifName = ifName
+	hostPorts := sp.getPodHostPorts(pod)
+	if hostPorts != nil {
+		/* pod is a backend for its host ports */
+		localEp.svcCount++
+	}
 	if localEp.svcCount > 0 {
 		newBackendIfs := sp.backendIfs.Copy()
 		newBackendIfs.Add(ifName)
@@ -140,6 +157,12 @@ func (sp *ServiceProcessor) processUpdatedPod(pod *podmodel.Pod) error {
 	newFrontendIfs.Add(ifName)
 	sp.Configurator.UpdateLocalFrontendIfs(sp.frontendIfs, newFrontendIfs)
 	sp.frontendIfs = newFrontendIfs
+
+	if hostPorts != nil {
+		sp.hostPortPods[podID] = pod
+		sp.hostPorts[podID] = hostPorts
+		return sp.Configurator.AddPodHostPorts(hostPorts)
+	}
 	return nil
 }
 
@@ -169,6 +192,12 @@ func (sp *ServiceProcessor) processDeletingPod(podNamespace string, podName stri
 	sp.Configurator.UpdateLocalFrontendIfs(sp.frontendIfs, newFrontendIfs)
 	sp.frontendIfs = newFrontendIfs
 	delete(sp.localEps, podID)
+
+	if hostPorts, hasHostPorts := sp.hostPorts[podID]; hasHostPorts {
+		delete(sp.hostPortPods, podID)
+		delete(sp.hostPorts, podID)
+		return sp.Configurator.DeletePodHostPorts(hostPorts)
+	}
 	return nil
 }
 
@@ -252,7 +281,7 @@ func (sp *ServiceProcessor) processNewNode(node *nodemodel.NodeInfo) error {
 	}).Debug("ServiceProcessor - processNewNode()")
 
 	sp.nodes[int(node.Id)] = node
-	return sp.reconfigureNodePorts()
+	return sp.reconfigureNodeIPs()
 }
 
 func (sp *ServiceProcessor) processUpdatedNode(node *nodemodel.NodeInfo) error {
@@ -261,7 +290,7 @@ func (sp *ServiceProcessor) processUpdatedNode(node *nodemodel.NodeInfo) error {
 	}).Debug("ServiceProcessor - processUpdatedNode()")
 
 	sp.nodes[int(node.Id)] = node
-	return sp.reconfigureNodePorts()
+	return sp.reconfigureNodeIPs()
 }
 
 func (sp *ServiceProcessor) processDeletedNode(nodeID int) error {
@@ -271,7 +300,7 @@ func (sp *ServiceProcessor) processDeletedNode(nodeID int) error {
 
 	if _, hasNode := sp.nodes[nodeID]; hasNode {
 		delete(sp.nodes, nodeID)
-		return sp.reconfigureNodePorts()
+		return sp.reconfigureNodeIPs()
 	}
 	return nil
 }
@@ -353,6 +382,33 @@ func (sp *ServiceProcessor) configureService(svc *Service, oldContivSvc *configu
 	return err
 }
 
+// reconfigureNodeIPs reconfigures everything that depends on IP addresses
+// of nodes, i.e. services with a node port and host ports of local pods.
+func (sp *ServiceProcessor) reconfigureNodeIPs() error {
+	err := sp.reconfigureNodePorts()
+	if err != nil {
+		return err
+	}
+	return sp.reconfigureHostPorts()
+}
+
+// reconfigureHostPorts re-exposes host ports of all local pods on the current
+// IP addresses of this node.
+func (sp *ServiceProcessor) reconfigureHostPorts() error {
+	sp.Log.Debug("ServiceProcessor - reconfigureHostPorts()")
+
+	for podID, pod := range sp.hostPortPods {
+		oldHostPorts := sp.hostPorts[podID]
+		newHostPorts := sp.getPodHostPorts(pod)
+		err := sp.Configurator.UpdatePodHostPorts(oldHostPorts, newHostPorts)
+		if err != nil {
+			return err
+		}
+		sp.hostPorts[podID] = newHostPorts
+	}
+	return nil
+}
+
 // reconfigureNodePorts reconfigures all services with a node port.
 func (sp *ServiceProcessor) reconfigureNodePorts() error {
 	sp.Log.Debug("ServiceProcessor - reconfigureNodePorts()")
@@ -402,6 +458,72 @@ func (sp *ServiceProcessor) getNodeIPs() []net.IP {
 	return nodeIPs
 }
 
+// getLocalNodeIPs returns IP addresses of this node on which the host ports
+// of local pods are exposed by default.
+func (sp *ServiceProcessor) getLocalNodeIPs() *configurator.IPAddresses {
+	nodeIPs := configurator.NewIPAddresses()
+
+	// Node IP (VPP)
+	nodeIP, _ := sp.Contiv.GetNodeIP()
+	if nodeIP != nil {
+		nodeIPs.Add(nodeIP)
+	}
+	// Node management IP (K8s, host)
+	for _, node := range sp.nodes {
+		if node.Name != sp.ServiceLabel.GetAgentLabel() {
+			continue
+		}
+		nodeMgmtIP := net.ParseIP(sp.trimIPAddrPrefix(node.ManagementIpAddress))
+		if nodeMgmtIP != nil {
+			nodeIPs.Add(nodeMgmtIP)
+		}
+	}
+	// IPs of the other physical interfaces
+	for _, physIf := range sp.Contiv.GetOtherPhysicalIfNames() {
+		for _, ipAddr := range sp.getInterfaceIPs(physIf) {
+			nodeIPs.Add(ipAddr.IP)
+		}
+	}
+	return nodeIPs
+}
+
+// getPodHostPorts returns host ports of the given (local) pod, or nil if
+// the pod does not expose any port on the host.
+func (sp *ServiceProcessor) getPodHostPorts(pod *podmodel.Pod) *configurator.PodHostPorts {
+	hostPorts := &configurator.PodHostPorts{
+		ID: podmodel.GetID(pod),
+		IP: net.ParseIP(pod.IpAddress),
+	}
+	for _, container := range pod.Container {
+		for _, port := range container.Port {
+			if port.HostPort == 0 || port.ContainerPort == 0 {
+				continue
+			}
+			hostPort := &configurator.HostPort{
+				HostPort:      uint16(port.HostPort),
+				ContainerPort: uint16(port.ContainerPort),
+			}
+			switch port.Protocol {
+			case podmodel.Pod_Container_Port_TCP:
+				hostPort.Protocol = configurator.TCP
+			case podmodel.Pod_Container_Port_UDP:
+				hostPort.Protocol = configurator.UDP
+			}
+			hostIP := net.ParseIP(port.HostIpAddress)
+			if hostIP != nil && !hostIP.IsUnspecified() {
+				hostPort.HostIPs = configurator.NewIPAddresses(hostIP)
+			} else {
+				hostPort.HostIPs = sp.getLocalNodeIPs()
+			}
+			hostPorts.Ports = append(hostPorts.Ports, hostPort)
+		}
+	}
+	if len(hostPorts.Ports) == 0 {
+		return nil
+	}
+	return hostPorts
+}
+
 func (sp *ServiceProcessor) trimIPAddrPrefix(ip string) string {
 	if strings.Contains(ip, "/") {
 		return ip[:strings.Index(ip, "/")]
@@ -428,9 +550,6 @@ func (sp *ServiceProcessor) processResyncEvent(resyncEv *ResyncEventData) error
 		return errors.New("failed to get Node IP")
 	}
 
-	// Get default gateway IP address.
-	gwIP := sp.Contiv.GetDefaultGatewayIP()
-
 	// Fill up the set of frontend/backend interfaces and local endpoints.
 	// With physical interfaces also build SNAT configuration.
 	// -> VXLAN BVI interface
@@ -441,39 +560,15 @@ func (sp *ServiceProcessor) processResyncEvent(resyncEv *ResyncEventData) error
 	}
 	// -> main physical interfaces
 	mainPhysIf := sp.Contiv.GetMainPhysicalIfName()
-	if mainPhysIf != "" {
-		if vxlanBVIIf == "" {
-			sp.backendIfs.Add(mainPhysIf)
-		}
-		if sp.Contiv.NatExternalTraffic() && vxlanBVIIf != "" && gwIP != nil {
-			// If the interface connects node with the default GW, SNAT all egress traffic.
-			// For main interface this is supported only with VXLANs enabled.
-			if nodeNet.Contains(gwIP) {
-				confResyncEv.ExternalSNAT.ExternalIfName = mainPhysIf
-				confResyncEv.ExternalSNAT.ExternalIP = nodeIP
-			}
-		}
-		if confResyncEv.ExternalSNAT.ExternalIfName != mainPhysIf {
-			sp.frontendIfs.Add(mainPhysIf)
-		}
+	if mainPhysIf != "" && vxlanBVIIf == "" {
+		sp.backendIfs.Add(mainPhysIf)
 	}
-	// -> other physical interfaces
-	for _, physIf := range sp.Contiv.GetOtherPhysicalIfNames() {
-		ipAddresses := sp.getInterfaceIPs(physIf)
-		// If the interface connects node with the default GW, SNAT all egress traffic.
-		if sp.Contiv.NatExternalTraffic() && gwIP != nil {
-			for _, ipAddr := range ipAddresses {
-				if ipAddr.Network.Contains(gwIP) {
-					confResyncEv.ExternalSNAT.ExternalIfName = physIf
-					confResyncEv.ExternalSNAT.ExternalIP = ipAddr.IP
-					break
-				}
-			}
-		}
-		if confResyncEv.ExternalSNAT.ExternalIfName != physIf {
-			sp.frontendIfs.Add(physIf)
-		}
+	// -> physical interfaces
+	sp.physFrontendIfs, sp.externalSNAT = sp.getPhysicalFrontends(nodeIP, nodeNet)
+	for physIf := range sp.physFrontendIfs {
+		sp.frontendIfs.Add(physIf)
 	}
+	confResyncEv.ExternalSNAT = sp.externalSNAT
 	// -> host interconnect
 	hostInterconnect := sp.Contiv.GetHostInterconnectIfName()
 	if hostInterconnect != "" {
@@ -487,7 +582,7 @@ func (sp *ServiceProcessor) processResyncEvent(resyncEv *ResyncEventData) error
 			continue
 		}
 		podIPAddress := net.ParseIP(pod.IpAddress)
-		if podIPAddress == nil || !sp.Contiv.GetPodNetwork().Contains(podIPAddress) {
+		if podIPAddress == nil || !sp.Contiv.IsLocalPodIP(podIPAddress) {
 			continue
 		}
 		ifName, ifExists := sp.Contiv.GetIfName(podID.Namespace, podID.Name)
@@ -501,6 +596,14 @@ func (sp *ServiceProcessor) processResyncEvent(resyncEv *ResyncEventData) error
 		localEp := sp.getLocalEndpoint(podID)
 		localEp.ifName = ifName
 		sp.frontendIfs.Add(ifName)
+		if hostPorts := sp.getPodHostPorts(pod); hostPorts != nil {
+			/* pod is a backend for its host ports */
+			localEp.svcCount++
+			sp.backendIfs.Add(ifName)
+			sp.hostPortPods[podID] = pod
+			sp.hostPorts[podID] = hostPorts
+			confResyncEv.HostPorts = append(confResyncEv.HostPorts, hostPorts)
+		}
 	}
 
 	// Combine the service metadata with endpoints.
@@ -537,6 +640,120 @@ func (sp *ServiceProcessor) processResyncEvent(resyncEv *ResyncEventData) error
 	return sp.Configurator.Resync(confResyncEv)
 }
 
+// getPhysicalFrontends returns the physical interfaces connecting clients with VPP
+// and the configuration of SNAT of the traffic leaving the cluster network.
+func (sp *ServiceProcessor) getPhysicalFrontends(nodeIP net.IP, nodeNet *net.IPNet) (
+	frontendIfs configurator.Interfaces, externalSNAT configurator.ExternalSNATConfig) {
+
+	frontendIfs = configurator.NewInterfaces()
+
+	// Get default gateway IP address.
+	gwIP := sp.Contiv.GetDefaultGatewayIP()
+
+	// -> main physical interfaces
+	vxlanBVIIf := sp.Contiv.GetVxlanBVIIfName()
+	mainPhysIf := sp.Contiv.GetMainPhysicalIfName()
+	if mainPhysIf != "" {
+		if sp.Contiv.NatExternalTraffic() && vxlanBVIIf != "" && gwIP != nil {
+			// If the interface connects node with the default GW, SNAT all egress traffic.
+			// For main interface this is supported only with VXLANs enabled.
+			if nodeNet.Contains(gwIP) {
+				externalSNAT.ExternalIfName = mainPhysIf
+				externalSNAT.ExternalIP = nodeIP
+			}
+		}
+		if externalSNAT.ExternalIfName != mainPhysIf {
+			frontendIfs.Add(mainPhysIf)
+		}
+	}
+	// -> other physical interfaces
+	for _, physIf := range sp.Contiv.GetOtherPhysicalIfNames() {
+		ipAddresses := sp.getInterfaceIPs(physIf)
+		// If the interface connects node with the default GW, SNAT all egress traffic.
+		if sp.Contiv.NatExternalTraffic() && gwIP != nil {
+			for _, ipAddr := range ipAddresses {
+				if ipAddr.Network.Contains(gwIP) {
+					externalSNAT.ExternalIfName = physIf
+					externalSNAT.ExternalIP = ipAddr.IP
+					break
+				}
+			}
+		}
+		if externalSNAT.ExternalIfName != physIf {
+			frontendIfs.Add(physIf)
+		}
+	}
+	return frontendIfs, externalSNAT
+}
+
+// ReconfigureNodeConfig reconfigures everything that depends on the configuration
+// of this node, i.e. SNAT of the traffic leaving the cluster network and the set
+// of physical interfaces connecting clients with VPP.
+func (sp *ServiceProcessor) ReconfigureNodeConfig() error {
+	sp.Log.Debug("ServiceProcessor - ReconfigureNodeConfig()")
+
+	nodeIP, nodeNet := sp.Contiv.GetNodeIP()
+	if nodeIP == nil {
+		return errors.New("failed to get Node IP")
+	}
+	newPhysFrontendIfs, newExternalSNAT := sp.getPhysicalFrontends(nodeIP, nodeNet)
+
+	// An interface cannot be a frontend and the SNAT interface at the same time,
+	// therefore the removed frontends are updated first and the added ones last.
+	// -> remove frontends
+	newFrontendIfs := sp.frontendIfs.Copy()
+	for physIf := range sp.physFrontendIfs {
+		if !newPhysFrontendIfs.Has(physIf) {
+			newFrontendIfs.Del(physIf)
+		}
+	}
+	if !sameInterfaces(newFrontendIfs, sp.frontendIfs) {
+		err := sp.Configurator.UpdateLocalFrontendIfs(sp.frontendIfs, newFrontendIfs)
+		if err != nil {
+			return err
+		}
+		sp.frontendIfs = newFrontendIfs
+	}
+
+	// -> update SNAT
+	if newExternalSNAT.ExternalIfName != sp.externalSNAT.ExternalIfName ||
+		!newExternalSNAT.ExternalIP.Equal(sp.externalSNAT.ExternalIP) {
+		err := sp.Configurator.UpdateExternalSNAT(newExternalSNAT)
+		if err != nil {
+			return err
+		}
+		sp.externalSNAT = newExternalSNAT
+	}
+
+	// -> add frontends
+	newFrontendIfs = sp.frontendIfs.Copy()
+	for physIf := range newPhysFrontendIfs {
+		newFrontendIfs.Add(physIf)
+	}
+	if !sameInterfaces(newFrontendIfs, sp.frontendIfs) {
+		err := sp.Configurator.UpdateLocalFrontendIfs(sp.frontendIfs, newFrontendIfs)
+		if err != nil {
+			return err
+		}
+		sp.frontendIfs = newFrontendIfs
+	}
+	sp.physFrontendIfs = newPhysFrontendIfs
+	return nil
+}
+
+// sameInterfaces returns true if both sets contain the same interfaces.
+func sameInterfaces(ifs1, ifs2 configurator.Interfaces) bool {
+	if len(ifs1) != len(ifs2) {
+		return false
+	}
+	for ifName := range ifs1 {
+		if !ifs2.Has(ifName) {
+			return false
+		}
+	}
+	return true
+}
+
 // Close deallocates resource held by the processor.
 func (sp *ServiceProcessor) Close() error {
 	return nil
@@ -1100,6 +1100,158 @@ func TestWithoutVXLAN(t *testing.T) {
 	Expect(configurator.Close()).To(BeNil())
 }
 
+func TestPodInExtraPodCIDRBlock(t *testing.T) {
+	RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestPodInExtraPodCIDRBlock")
+
+	const pod3If = "master-tap3"
+
+	// Prepare mocks.
+	//  -> Contiv plugin
+	contiv := NewMockContiv()
+	contiv.SetNatExternalTraffic(true)
+	contiv.SetNodeIP(nodeIP + nodePrefix)
+	contiv.SetDefaultGatewayIP(net.ParseIP(defaultGwIP))
+	contiv.SetMainPhysicalIfName(mainIfName)
+	contiv.SetHostInterconnectIfName(hostInterIfName)
+	contiv.SetPodNetwork(podNetwork)
+	contiv.AddPodNetwork("10.2.1.0/24") // pod CIDR block allocated when the primary block was exhausted
+	contiv.SetPodIfName(pod1, pod1If)
+	contiv.SetPodIfName(pod3, pod3If)
+
+	// -> NAT plugin
+	natPlugin := NewMockNatPlugin(logger)
+
+	// -> localclient
+	txnTracker := localclient.NewTxnTracker(natPlugin.ApplyTxn)
+
+	// -> default VPP plugins
+	vppPlugins := NewMockVppPlugin()
+	vppPlugins.SetNat44Dnat(&nat.Nat44DNat{})
+
+	// -> service label
+	serviceLabel := NewMockServiceLabel()
+	serviceLabel.SetAgentLabel(masterLabel)
+
+	// -> datasync
+	datasync := NewMockDataSync()
+
+	// Prepare configurator.
+	configurator := &svc_configurator.ServiceConfigurator{
+		Deps: svc_configurator.Deps{
+			Log:           logger,
+			VPP:           vppPlugins,
+			NATTxnFactory: txnTracker.NewLinuxDataChangeTxn,
+		},
+	}
+
+	// Prepare processor.
+	processor := &svc_processor.ServiceProcessor{
+		Deps: svc_processor.Deps{
+			Log:          logger,
+			VPP:          vppPlugins,
+			ServiceLabel: serviceLabel,
+			Contiv:       contiv,
+			Configurator: configurator,
+		},
+	}
+
+	Expect(configurator.Init()).To(BeNil())
+	Expect(processor.Init()).To(BeNil())
+
+	// Resync from empty VPP.
+	resyncEv := datasync.Resync(keyPrefixes...)
+	Expect(processor.Resync(resyncEv)).To(BeNil())
+	Expect(natPlugin.NumOfIfsWithFeatures()).To(Equal(2))
+
+	// Pods from both the primary and the extra block are local.
+	dataChange1 := datasync.Put(podmodel.Key(pod1.Name, pod1.Namespace), pod1Model)
+	Expect(processor.Update(dataChange1)).To(BeNil())
+	dataChange2 := datasync.Put(podmodel.Key(pod3.Name, pod3.Namespace), pod3Model)
+	Expect(processor.Update(dataChange2)).To(BeNil())
+
+	Expect(natPlugin.NumOfIfsWithFeatures()).To(Equal(4))
+	Expect(natPlugin.GetInterfaceFeatures(pod1If)).To(Equal(NewNatFeatures(OUT)))
+	Expect(natPlugin.GetInterfaceFeatures(pod3If)).To(Equal(NewNatFeatures(OUT)))
+
+	// Add service with the pod from the extra block as the only endpoint.
+	service1 := &svcmodel.Service{
+		Name:                  "service1",
+		Namespace:             namespace2,
+		ServiceType:           "ClusterIP",
+		ExternalTrafficPolicy: "Cluster",
+		ClusterIp:             "10.96.0.1",
+		Port: []*svcmodel.Service_ServicePort{
+			{
+				Name:     "http",
+				Protocol: "TCP",
+				Port:     80,
+			},
+		},
+	}
+	dataChange3 := datasync.Put(svcmodel.Key(service1.Name, service1.Namespace), service1)
+	Expect(processor.Update(dataChange3)).To(BeNil())
+
+	eps1 := &epmodel.Endpoints{
+		Name:      "service1",
+		Namespace: namespace2,
+		EndpointSubsets: []*epmodel.EndpointSubset{
+			{
+				Addresses: []*epmodel.EndpointSubset_EndpointAddress{
+					{
+						Ip:       pod3IP,
+						NodeName: masterLabel,
+						TargetRef: &epmodel.ObjectReference{
+							Kind:      "Pod",
+							Namespace: pod3.Namespace,
+							Name:      pod3.Name,
+						},
+					},
+				},
+				Ports: []*epmodel.EndpointSubset_EndpointPort{
+					{
+						Name:     "http",
+						Port:     8080,
+						Protocol: "TCP",
+					},
+				},
+			},
+		},
+	}
+	dataChange4 := datasync.Put(epmodel.Key(eps1.Name, eps1.Namespace), eps1)
+	Expect(processor.Update(dataChange4)).To(BeNil())
+
+	// The pod is a local backend of the service.
+	Expect(natPlugin.NumOfStaticMappings()).To(Equal(1))
+	staticMapping := &StaticMapping{
+		ExternalIP:   net.ParseIP("10.96.0.1"),
+		ExternalPort: 80,
+		Protocol:     svc_configurator.TCP,
+		Locals: []*Local{
+			{
+				IP:          net.ParseIP(pod3IP),
+				Port:        8080,
+				Probability: 1,
+			},
+		},
+	}
+	Expect(natPlugin.HasStaticMapping(staticMapping)).To(BeTrue())
+	Expect(natPlugin.GetInterfaceFeatures(pod3If)).To(Equal(NewNatFeatures(OUT, IN)))
+
+	// The pod from the extra block is recognized as local also by resync.
+	resyncEv = datasync.Resync(keyPrefixes...)
+	Expect(processor.Resync(resyncEv)).To(BeNil())
+	Expect(natPlugin.NumOfIfsWithFeatures()).To(Equal(4))
+	Expect(natPlugin.GetInterfaceFeatures(pod3If)).To(Equal(NewNatFeatures(OUT, IN)))
+	Expect(natPlugin.HasStaticMapping(staticMapping)).To(BeTrue())
+
+	// Cleanup
+	Expect(processor.Close()).To(BeNil())
+	Expect(configurator.Close()).To(BeNil())
+}
+
 func TestWithOtherInterfaces(t *testing.T) {
 	RegisterTestingT(t)
 	logger := logrus.DefaultLogger()
@@ -1187,6 +1339,99 @@ func TestWithOtherInterfaces(t *testing.T) {
 	Expect(configurator.Close()).To(BeNil())
 }
 
+func TestNodeConfigChange(t *testing.T) {
+	RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestNodeConfigChange")
+
+	// Prepare mocks.
+	//  -> Contiv plugin
+	contiv := NewMockContiv()
+	contiv.SetNatExternalTraffic(false)
+	contiv.SetNodeIP(nodeIP + nodePrefix)
+	contiv.SetMainPhysicalIfName(mainIfName)
+	contiv.SetVxlanBVIIfName(vxlanIfName)
+	contiv.SetHostInterconnectIfName(hostInterIfName)
+	contiv.SetPodNetwork(podNetwork)
+
+	// -> NAT plugin
+	natPlugin := NewMockNatPlugin(logger)
+
+	// -> localclient
+	txnTracker := localclient.NewTxnTracker(natPlugin.ApplyTxn)
+
+	// -> default VPP plugins
+	vppPlugins := NewMockVppPlugin()
+	vppPlugins.SetNat44Dnat(&nat.Nat44DNat{})
+
+	// -> service label
+	serviceLabel := NewMockServiceLabel()
+	serviceLabel.SetAgentLabel(masterLabel)
+
+	// -> datasync
+	datasync := NewMockDataSync()
+
+	// Prepare configurator.
+	configurator := &svc_configurator.ServiceConfigurator{
+		Deps: svc_configurator.Deps{
+			Log:           logger,
+			VPP:           vppPlugins,
+			NATTxnFactory: txnTracker.NewLinuxDataChangeTxn,
+		},
+	}
+
+	// Prepare processor.
+	processor := &svc_processor.ServiceProcessor{
+		Deps: svc_processor.Deps{
+			Log:          logger,
+			VPP:          vppPlugins,
+			ServiceLabel: serviceLabel,
+			Contiv:       contiv,
+			Configurator: configurator,
+		},
+	}
+
+	Expect(configurator.Init()).To(BeNil())
+	Expect(processor.Init()).To(BeNil())
+
+	// Resync from empty VPP.
+	resyncEv := datasync.Resync(keyPrefixes...)
+	Expect(processor.Resync(resyncEv)).To(BeNil())
+
+	// Check that SNAT is NOT configured.
+	Expect(natPlugin.AddressPoolSize()).To(Equal(0))
+	Expect(natPlugin.NumOfIfsWithFeatures()).To(Equal(3))
+	Expect(natPlugin.GetInterfaceFeatures(mainIfName)).To(Equal(NewNatFeatures(OUT)))
+
+	// Configure the default gateway and enable SNAT at runtime.
+	contiv.SetDefaultGatewayIP(net.ParseIP(defaultGwIP))
+	contiv.SetNatExternalTraffic(true)
+	Expect(processor.ReconfigureNodeConfig()).To(BeNil())
+
+	Expect(natPlugin.IsForwardingEnabled()).To(BeTrue())
+	Expect(natPlugin.AddressPoolSize()).To(Equal(1))
+	Expect(natPlugin.PoolContainsAddress(nodeIP)).To(BeTrue())
+	Expect(natPlugin.NumOfIfsWithFeatures()).To(Equal(3))
+	Expect(natPlugin.GetInterfaceFeatures(mainIfName)).To(Equal(NewNatFeatures(OUTPUT_OUT)))
+	Expect(natPlugin.GetInterfaceFeatures(vxlanIfName)).To(Equal(NewNatFeatures(IN, OUT)))
+	Expect(natPlugin.GetInterfaceFeatures(hostInterIfName)).To(Equal(NewNatFeatures(IN, OUT)))
+
+	// Disable SNAT again.
+	contiv.SetNatExternalTraffic(false)
+	Expect(processor.ReconfigureNodeConfig()).To(BeNil())
+
+	Expect(natPlugin.AddressPoolSize()).To(Equal(0))
+	Expect(natPlugin.NumOfIfsWithFeatures()).To(Equal(3))
+	Expect(natPlugin.GetInterfaceFeatures(mainIfName)).To(Equal(NewNatFeatures(OUT)))
+	Expect(natPlugin.GetInterfaceFeatures(vxlanIfName)).To(Equal(NewNatFeatures(IN, OUT)))
+	Expect(natPlugin.GetInterfaceFeatures(hostInterIfName)).To(Equal(NewNatFeatures(IN, OUT)))
+
+	// Cleanup
+	Expect(processor.Close()).To(BeNil())
+	Expect(configurator.Close()).To(BeNil())
+}
+
 func TestServiceUpdates(t *testing.T) {
 	RegisterTestingT(t)
 	logger := logrus.DefaultLogger()
@@ -1553,3 +1798,176 @@ func TestServiceUpdates(t *testing.T) {
 	Expect(natPlugin.NumOfStaticMappings()).To(Equal(0))
 	Expect(natPlugin.NumOfIdentityMappings()).To(Equal(0))
 }
+
+func TestHostPorts(t *testing.T) {
+	RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestHostPorts")
+
+	// Prepare mocks.
+	//  -> Contiv plugin
+	contiv := NewMockContiv()
+	contiv.SetNatExternalTraffic(true)
+	contiv.SetNodeIP(nodeIP + nodePrefix)
+	contiv.SetDefaultGatewayIP(net.ParseIP(defaultGwIP))
+	contiv.SetMainPhysicalIfName(mainIfName)
+	contiv.SetVxlanBVIIfName(vxlanIfName)
+	contiv.SetHostInterconnectIfName(hostInterIfName)
+	contiv.SetPodNetwork(podNetwork)
+	contiv.SetPodIfName(pod1, pod1If)
+	contiv.SetPodIfName(pod2, pod2If)
+
+	// -> NAT plugin
+	natPlugin := NewMockNatPlugin(logger)
+
+	// -> localclient
+	txnTracker := localclient.NewTxnTracker(natPlugin.ApplyTxn)
+
+	// -> default VPP plugins
+	vppPlugins := NewMockVppPlugin()
+	vppPlugins.SetNat44Dnat(&nat.Nat44DNat{})
+
+	// -> service label
+	serviceLabel := NewMockServiceLabel()
+	serviceLabel.SetAgentLabel(masterLabel)
+
+	// -> datasync
+	datasync := NewMockDataSync()
+
+	// Prepare configurator.
+	configurator := &svc_configurator.ServiceConfigurator{
+		Deps: svc_configurator.Deps{
+			Log:           logger,
+			VPP:           vppPlugins,
+			NATTxnFactory: txnTracker.NewLinuxDataChangeTxn,
+		},
+	}
+
+	// Prepare processor.
+	processor := &svc_processor.ServiceProcessor{
+		Deps: svc_processor.Deps{
+			Log:          logger,
+			VPP:          vppPlugins,
+			ServiceLabel: serviceLabel,
+			Contiv:       contiv,
+			Configurator: configurator,
+		},
+	}
+
+	// Initialize and resync.
+	Expect(configurator.Init()).To(BeNil())
+	Expect(processor.Init()).To(BeNil())
+	resyncEv := datasync.Resync(keyPrefixes...)
+	Expect(processor.Resync(resyncEv)).To(BeNil())
+
+	// Add pod with host ports.
+	pod1WithHostPorts := &podmodel.Pod{
+		Name:      pod1.Name,
+		Namespace: pod1.Namespace,
+		IpAddress: pod1IP,
+		Container: []*podmodel.Pod_Container{
+			{
+				Name: "web",
+				Port: []*podmodel.Pod_Container_Port{
+					{
+						Name:          "http",
+						HostPort:      8080,
+						ContainerPort: 80,
+						Protocol:      podmodel.Pod_Container_Port_TCP,
+					},
+					{
+						Name:          "dns",
+						HostPort:      5353,
+						ContainerPort: 53,
+						Protocol:      podmodel.Pod_Container_Port_UDP,
+						HostIpAddress: otherIfIP,
+					},
+					{
+						Name:          "internal",
+						ContainerPort: 9090,
+						Protocol:      podmodel.Pod_Container_Port_TCP,
+					},
+				},
+			},
+		},
+	}
+	dataChange1 := datasync.Put(podmodel.Key(pod1.Name, pod1.Namespace), pod1WithHostPorts)
+	Expect(processor.Update(dataChange1)).To(BeNil())
+	dataChange2 := datasync.Put(podmodel.Key(pod2.Name, pod2.Namespace), pod2Model)
+	Expect(processor.Update(dataChange2)).To(BeNil())
+
+	// Pod with host ports is a backend, the other pod only a frontend.
+	Expect(natPlugin.NumOfIfsWithFeatures()).To(Equal(5))
+	Expect(natPlugin.GetInterfaceFeatures(pod1If)).To(Equal(NewNatFeatures(IN, OUT)))
+	Expect(natPlugin.GetInterfaceFeatures(pod2If)).To(Equal(NewNatFeatures(OUT)))
+
+	// Static mappings for the host ports.
+	staticMappingHTTP := &StaticMapping{
+		ExternalIP:   net.ParseIP(nodeIP),
+		ExternalPort: 8080,
+		Protocol:     svc_configurator.TCP,
+		Locals: []*Local{
+			{
+				IP:          net.ParseIP(pod1IP),
+				Port:        80,
+				Probability: 1,
+			},
+		},
+	}
+	staticMappingDNS := &StaticMapping{
+		ExternalIP:   net.ParseIP(otherIfIP),
+		ExternalPort: 5353,
+		Protocol:     svc_configurator.UDP,
+		Locals: []*Local{
+			{
+				IP:          net.ParseIP(pod1IP),
+				Port:        53,
+				Probability: 1,
+			},
+		},
+	}
+	Expect(natPlugin.NumOfStaticMappings()).To(Equal(2))
+	Expect(natPlugin.HasStaticMapping(staticMappingHTTP)).To(BeTrue())
+	Expect(natPlugin.HasStaticMapping(staticMappingDNS)).To(BeTrue())
+
+	// Propagate Node Mgmt IP of the master - host ports get exposed on it as well.
+	masterNode := &nodemodel.NodeInfo{
+		Id:                  1,
+		Name:                masterLabel,
+		IpAddress:           nodeIP + nodePrefix,
+		ManagementIpAddress: mgmtIP,
+	}
+	dataChange3 := datasync.Put(contivplugin.AllocatedIDsKeyPrefix+strconv.FormatUint(uint64(masterNode.Id), 10), masterNode)
+	Expect(processor.Update(dataChange3)).To(BeNil())
+
+	staticMappingHTTPMgmtIP := staticMappingHTTP.Copy()
+	staticMappingHTTPMgmtIP.ExternalIP = net.ParseIP(mgmtIP)
+	Expect(natPlugin.NumOfStaticMappings()).To(Equal(3))
+	Expect(natPlugin.HasStaticMapping(staticMappingHTTP)).To(BeTrue())
+	Expect(natPlugin.HasStaticMapping(staticMappingHTTPMgmtIP)).To(BeTrue())
+	Expect(natPlugin.HasStaticMapping(staticMappingDNS)).To(BeTrue())
+
+	// Resync - the host ports remain exposed.
+	resyncEv2 := datasync.Resync(keyPrefixes...)
+	Expect(processor.Resync(resyncEv2)).To(BeNil())
+
+	Expect(natPlugin.NumOfIfsWithFeatures()).To(Equal(5))
+	Expect(natPlugin.GetInterfaceFeatures(pod1If)).To(Equal(NewNatFeatures(IN, OUT)))
+	Expect(natPlugin.GetInterfaceFeatures(pod2If)).To(Equal(NewNatFeatures(OUT)))
+	Expect(natPlugin.NumOfStaticMappings()).To(Equal(3))
+	Expect(natPlugin.HasStaticMapping(staticMappingHTTP)).To(BeTrue())
+	Expect(natPlugin.HasStaticMapping(staticMappingHTTPMgmtIP)).To(BeTrue())
+	Expect(natPlugin.HasStaticMapping(staticMappingDNS)).To(BeTrue())
+
+	// Remove the pod with host ports.
+	contiv.DeletingPod(pod1)
+
+	Expect(natPlugin.NumOfStaticMappings()).To(Equal(0))
+	Expect(natPlugin.NumOfIfsWithFeatures()).To(Equal(4))
+	Expect(natPlugin.GetInterfaceFeatures(pod2If)).To(Equal(NewNatFeatures(OUT)))
+
+	// Cleanup
+	Expect(processor.Close()).To(BeNil())
+	Expect(configurator.Close()).To(BeNil())
+}